@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/clickhouse"
+	"project/pkg/logger"
+	"project/pkg/util/random"
+	"strconv"
+	"time"
+)
+
+// access日志同时批量写入clickhouse供sql分析流量，表结构由accessTable维护，启动时自动建表和补齐新增列
+
+type AccessLogConfig struct {
+	ClickHouse clickhouse.Config  `mapstructure:"clickhouse"`
+	Writer     clickhouse.Options // 批量、缓冲和重试
+	Table      string             // 默认access_log
+	TTLDays    int                `mapstructure:"ttlDays"` // 保留天数，默认30，仅建表时生效
+}
+
+// AccessRecord access_log表的一行，字段变更需同步accessTable
+type AccessRecord struct {
+	Time       string  `json:"time"` // 带时区的毫秒时间，按best_effort解析
+	TraceID    string  `json:"trace_id"`
+	Method     string  `json:"method"`
+	Route      string  `json:"route"` // 路由模板，如/wechat/order/:no，未匹配时为空
+	Path       string  `json:"path"`
+	Status     int     `json:"status"`
+	ErrCode    string  `json:"err_code"` // 失败响应的业务错误码
+	Latency    float64 `json:"latency"`  // 毫秒
+	UserID     int     `json:"user_id"`
+	Subject    string  `json:"subject"` // 合作方为key:id
+	ClientIP   string  `json:"client_ip"`
+	Platform   string  `json:"platform"`
+	AppVersion string  `json:"app_version"`
+	ReqBytes   int     `json:"req_bytes"`
+	RespBytes  int     `json:"resp_bytes"`
+}
+
+func accessTable(name string, ttlDays int) *clickhouse.Table {
+	return &clickhouse.Table{
+		Name: name,
+		Columns: []*clickhouse.Column{
+			{Name: "time", Type: "DateTime64(3)"},
+			{Name: "trace_id", Type: "String"},
+			{Name: "method", Type: "LowCardinality(String)"},
+			{Name: "route", Type: "LowCardinality(String)", Comment: "路由模板"},
+			{Name: "path", Type: "String"},
+			{Name: "status", Type: "UInt16"},
+			{Name: "err_code", Type: "LowCardinality(String)", Comment: "业务错误码"},
+			{Name: "latency", Type: "Float32", Comment: "毫秒"},
+			{Name: "user_id", Type: "UInt64"},
+			{Name: "subject", Type: "LowCardinality(String)", Comment: "合作方key:id"},
+			{Name: "client_ip", Type: "String"},
+			{Name: "platform", Type: "LowCardinality(String)"},
+			{Name: "app_version", Type: "LowCardinality(String)"},
+			{Name: "req_bytes", Type: "UInt32"},
+			{Name: "resp_bytes", Type: "UInt32"},
+		},
+		PartitionBy: "toYYYYMMDD(time)",
+		OrderBy:     "route, time",
+		TTL:         "toDateTime(time) + INTERVAL " + strconv.Itoa(ttlDays) + " DAY",
+	}
+}
+
+var accessSink *clickhouse.Writer
+
+func initAccessLog(cfg *AccessLogConfig) {
+	if cfg == nil || cfg.ClickHouse.URL == "" {
+		return
+	}
+	if cfg.Table == "" {
+		cfg.Table = "access_log"
+	}
+	if cfg.TTLDays <= 0 {
+		cfg.TTLDays = 30
+	}
+	client := clickhouse.New(&cfg.ClickHouse, &http.Client{Timeout: 30 * time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Migrate(ctx, accessTable(cfg.Table, cfg.TTLDays)); err != nil {
+		// clickhouse不可用不影响启动，写入失败按重试后丢弃处理
+		_, l := logger.NewCtxLog(random.UUID(), "AccessLog", "Migrate", "")
+		l.Error("clickhouse.Migrate error", cfg.Table, err)
+	}
+	accessSink = clickhouse.NewWriter(client, cfg.Table, &cfg.Writer)
+}
+
+func sinkAccess(c *gin.Context, begin time.Time, status, reqBytes, respBytes int) {
+	if accessSink == nil {
+		return
+	}
+	r := &AccessRecord{
+		Time:       begin.Format("2006-01-02T15:04:05.000Z07:00"),
+		TraceID:    c.GetString("trace_id"),
+		Method:     c.Request.Method,
+		Route:      c.FullPath(),
+		Path:       c.Request.URL.Path,
+		Status:     status,
+		ErrCode:    c.GetString(errCodeKey),
+		Latency:    float64(time.Since(begin).Microseconds()) / 1000,
+		ClientIP:   c.ClientIP(),
+		Platform:   GetClient(c).Platform,
+		AppVersion: c.GetHeader("X-App-Version"),
+		ReqBytes:   reqBytes,
+		RespBytes:  respBytes,
+	}
+	if v, ok := c.Get("user"); ok {
+		r.UserID = v.(*proto.UserToken).ID
+	}
+	if v, ok := c.Get("apikey"); ok {
+		r.Subject = model.KeySubject(v.(*model.APIKey).ID)
+	}
+	accessSink.Write(r)
+}
+
+// Close 退出前写入缓冲中的access日志
+func Close() {
+	if accessSink != nil {
+		accessSink.Close()
+	}
+}
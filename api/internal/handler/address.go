@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+)
+
+/*
+收货地址：省市区按服务端的行政区划数据集选择和校验，客户端缓存region/tree并按ETag(数据集版本)校验
+数据集更新后已撤销的省市区在列表中标记outdated，下单前需重新选择
+*/
+
+// RegionTree 完整的省市区数据，数据集未变更时返回304
+func (h *Handler) RegionTree(c *gin.Context) {
+	ds := h.service.Regions()
+	etag := `"region-` + ds.Version + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=0, s-maxage=3600")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(NotModified)
+		return
+	}
+	h.List(c, ds.Tree(), nil)
+}
+
+// RegionChildren 下级区划，parent为空时返回省份
+func (h *Handler) RegionChildren(c *gin.Context) {
+	r, ok := BindQuery[proto.RegionArgs](c)
+	if !ok {
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	h.List(c, h.service.Regions().Children(r.Parent), nil)
+}
+
+func (h *Handler) AddressList(c *gin.Context) {
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	list, err := h.service.FindAddresses(c, user.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindAddresses error", user.ID, err)
+		h.Err(c, err)
+		return
+	}
+	h.List(c, list, nil)
+}
+
+// AddressDetail 不传id时返回默认地址，下单页使用
+func (h *Handler) AddressDetail(c *gin.Context) {
+	r, ok := BindQuery[proto.AddressDetailArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	addr, err := h.service.FindAddress(c, user.ID, r.ID)
+	if err != nil {
+		if err != model.ErrAddressNotFound {
+			logger.FromContext(c).Error("service.FindAddress error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, addr)
+}
+
+// AddressCreate 第一个地址自动设为默认
+func (h *Handler) AddressCreate(c *gin.Context) {
+	r, ok := Bind[proto.AddressArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	addr := addressFromArgs(user.ID, &r)
+	addr.ID = 0
+	if err := h.service.CreateAddress(c, addr); err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.CreateAddress error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, addr)
+}
+
+func (h *Handler) AddressUpdate(c *gin.Context) {
+	r, ok := Bind[proto.AddressArgs](c)
+	if !ok {
+		return
+	}
+	if r.ID == 0 {
+		h.Fail(c, InvalidParam, "缺少地址id")
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	addr := addressFromArgs(user.ID, &r)
+	if err := h.service.UpdateAddress(c, addr); err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.UpdateAddress error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, addr)
+}
+
+// AddressDelete 删除默认地址时最近修改的地址成为默认
+func (h *Handler) AddressDelete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if id <= 0 {
+		h.Err(c, model.ErrAddressNotFound)
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	if err := h.service.DeleteAddress(c, user.ID, id); err != nil {
+		if err != model.ErrAddressNotFound {
+			logger.FromContext(c).Error("service.DeleteAddress error", id, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, Empty)
+}
+
+func (h *Handler) AddressSetDefault(c *gin.Context) {
+	r, ok := Bind[proto.AddressIDArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	if err := h.service.SetDefaultAddress(c, user.ID, r.ID); err != nil {
+		if err != model.ErrAddressNotFound {
+			logger.FromContext(c).Error("service.SetDefaultAddress error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, Empty)
+}
+
+func addressFromArgs(uid int, r *proto.AddressArgs) *model.UserAddress {
+	return &model.UserAddress{
+		ID:           r.ID,
+		UserID:       uid,
+		Name:         r.Name,
+		Phone:        r.Phone,
+		ProvinceCode: r.ProvinceCode,
+		CityCode:     r.CityCode,
+		DistrictCode: r.DistrictCode,
+		Detail:       r.Detail,
+		Lng:          r.Lng,
+		Lat:          r.Lat,
+		IsDefault:    r.IsDefault,
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/geoip"
+	"project/pkg/logger"
+	"strings"
+)
+
+// authEvent 补充客户端信息后投递登录凭证事件
+func (h *Handler) authEvent(c *gin.Context, typ string, uid int, token, reason string) {
+	h.service.PublishAuthEvent(c, &model.AuthEvent{
+		Type:      typ,
+		UserID:    uid,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Reason:    reason,
+	}, token)
+}
+
+// TokenRefresh 换发新token，旧token立即失效
+func (h *Handler) TokenRefresh(c *gin.Context) {
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	token, err := h.service.RefreshUserToken(c, c.GetHeader("Authorization"), user)
+	if err != nil {
+		logger.FromContext(c).Error("service.RefreshUserToken error", user.ID, err)
+		h.Err(c, err)
+		return
+	}
+	h.authEvent(c, model.AuthTokenRefreshed, user.ID, token, "")
+	h.OK(c, &proto.TokenRefreshResp{Token: token})
+}
+
+func (h *Handler) Logout(c *gin.Context) {
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	token := c.GetHeader("Authorization")
+	if err := h.service.RevokeUserToken(c, token); err != nil {
+		logger.FromContext(c).Error("service.RevokeUserToken error", user.ID, err)
+		h.Err(c, err)
+		return
+	}
+	h.authEvent(c, model.AuthTokenRevoked, user.ID, token, "logout")
+	h.OK(c, Empty)
+}
+
+// AuthHistory 当前用户最近的登录、换发、退出记录
+func (h *Handler) AuthHistory(c *gin.Context) {
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	list, err := h.service.FindAuthEvents(c, user.ID, 50)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindAuthEvents error", user.ID, err)
+		h.Err(c, err)
+		return
+	}
+	if list == nil {
+		list = make([]*model.AuthEvent, 0)
+	}
+	h.OK(c, &proto.AuthHistoryResp{List: list})
+}
+
+// deviceFingerprint 客户端传了设备ID时按设备ID和平台区分，否则按User-Agent和语言，微信升级等会改变User-Agent
+func deviceFingerprint(c *gin.Context, deviceID string) string {
+	parts := []string{GetClient(c).Platform, deviceID}
+	if deviceID == "" {
+		parts = append(parts, c.Request.UserAgent(), c.GetHeader("Accept-Language"))
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// loginDevice 记录登录设备，新设备或新地区登录时投递审计事件并推送提醒，失败不影响登录
+func (h *Handler) loginDevice(c *gin.Context, uid int, token, deviceID string) {
+	d := &model.UserDevice{
+		UserID:      uid,
+		Fingerprint: deviceFingerprint(c, deviceID),
+		DeviceID:    deviceID,
+		Platform:    GetClient(c).Platform,
+		IP:          c.ClientIP(),
+		UserAgent:   c.Request.UserAgent(),
+	}
+	if v, ok := c.Get("geo"); ok {
+		loc := v.(*geoip.Location)
+		d.Region = loc.Country
+		if loc.Province != "" {
+			d.Region += "/" + loc.Province
+		}
+	}
+	newDevice, newRegion, err := h.service.RecordLoginDevice(c, d)
+	if err != nil {
+		logger.FromContext(c).Warn("service.RecordLoginDevice fail", d, err)
+		return
+	}
+	if !newDevice && !newRegion {
+		return
+	}
+	reason, body := "region", "您的账号在新的地区("+d.Region+")登录"
+	if newDevice {
+		reason, body = "device", "您的账号在新设备("+d.Platform+")登录"
+	}
+	h.authEvent(c, model.AuthNewDevice, uid, token, reason)
+	_, err = h.service.Push(c, &model.MsgPush{
+		UserID:   uid,
+		Category: model.PushCategoryTransactional,
+		Title:    "新设备登录提醒",
+		Body:     body + "，如非本人操作请及时退出并联系客服",
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.Push error", uid, err)
+	}
+}
+
+// UserDevices 当前用户登录过的设备
+func (h *Handler) UserDevices(c *gin.Context) {
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	list, err := h.service.FindUserDevices(c, user.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindUserDevices error", user.ID, err)
+		h.Err(c, err)
+		return
+	}
+	if list == nil {
+		list = make([]*model.UserDevice, 0)
+	}
+	h.OK(c, &proto.UserDevicesResp{List: list})
+}
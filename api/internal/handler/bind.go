@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enT "github.com/go-playground/validator/v10/translations/en"
+	zhT "github.com/go-playground/validator/v10/translations/zh"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// 请求绑定：Bind、BindQuery绑定并校验，失败时直接写入400响应(字段错误按Accept-Language翻译)，handler只需return
+//	r, ok := Bind[proto.OrderPayArgs](c)
+//	if !ok {
+//		return
+//	}
+
+var transZh, transEn ut.Translator
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	// 错误中使用json或form中的字段名，与客户端提交的一致
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "uri"} {
+			name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return f.Name
+	})
+	uni := ut.New(zh.New(), zh.New(), en.New())
+	transZh, _ = uni.GetTranslator("zh")
+	transEn, _ = uni.GetTranslator("en")
+	_ = zhT.RegisterDefaultTranslations(v, transZh)
+	_ = enT.RegisterDefaultTranslations(v, transEn)
+}
+
+// Bind 绑定json请求体，启用严格模式的路由使用StrictJSON
+func Bind[T any](c *gin.Context) (T, bool) {
+	var v T
+	return v, bindOK(c, bindJSON(c, &v))
+}
+
+// BindQuery 绑定queryString
+func BindQuery[T any](c *gin.Context) (T, bool) {
+	var v T
+	return v, bindOK(c, c.ShouldBindQuery(&v))
+}
+
+func bindOK(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+	status, e := bindErr(c, err)
+	writeErr(c, status, e)
+	return false
+}
+
+// bindErr 绑定阶段的错误都是客户端的问题，json解析错误不能按RespWithErr归为上游响应错误
+func bindErr(c *gin.Context, err error) (int, *RespErr) {
+	english := isEnglish(c)
+	msg := "参数错误"
+	if english {
+		msg = "Invalid Parameter"
+	}
+	var ve validator.ValidationErrors
+	var me *http.MaxBytesError
+	var se *json.SyntaxError
+	switch {
+	case errors.As(err, &ve):
+		trans, sep := transZh, "；"
+		if english {
+			trans, sep = transEn, "; "
+		}
+		list := make([]string, len(ve))
+		for i, e := range ve {
+			list[i] = e.Translate(trans)
+		}
+		return InvalidParam, &RespErr{Msg: msg, Detail: strings.Join(list, sep)}
+	case errors.As(err, &me):
+		return RespWithErr(me)
+	case errors.Is(err, io.EOF):
+		return InvalidParam, &RespErr{Msg: msg, Detail: "empty body"}
+	case errors.As(err, &se):
+		return InvalidParam, &RespErr{Msg: msg, Detail: "invalid json: " + se.Error()}
+	}
+	return InvalidParam, &RespErr{Msg: msg, Detail: err.Error()}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// 请求体大小限制，Content-Length超出时直接返回413，未声明长度的请求在AccessLog或bindJSON读取超出时返回413
+
+type BodyConfig struct {
+	Limit  int64        // 全局上限字节数，0不限制
+	Routes []*BodyRoute // 上传等接口单独设置
+}
+
+type BodyRoute struct {
+	Route string // method+path，如 POST/wechat/ocr/idcard
+	Limit int64
+}
+
+func (h *Handler) initBody(cfg *BodyConfig) {
+	if cfg == nil {
+		return
+	}
+	h.bodyLimit = cfg.Limit
+	h.bodyRoutes = make(map[string]int64, len(cfg.Routes))
+	for _, v := range cfg.Routes {
+		h.bodyRoutes[v.Route] = v.Limit
+	}
+}
+
+// MaxBodySize 按路由限制请求体，使用http.MaxBytesReader避免读入超限内容
+func (h *Handler) MaxBodySize(c *gin.Context) {
+	limit, ok := h.bodyRoutes[routeKey(c)]
+	if !ok {
+		limit = h.bodyLimit
+	}
+	if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		c.Next()
+		return
+	}
+	if c.Request.ContentLength > limit {
+		c.AbortWithStatusJSON(OverSize, &RespErr{Msg: "提交内容过大", Detail: "limit " + strconv.FormatInt(limit, 10)})
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	c.Next()
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"time"
+)
+
+/*
+预约：选择资源和日期后按booking/slots展示时段，booking预约后保留hold_minutes分钟，
+在保留期内调用booking/confirm确认(如填写资料、支付之后)，到期未确认名额自动释放
+*/
+
+func (h *Handler) BookingResources(c *gin.Context) {
+	list, err := h.service.FindBookingResources(c)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindBookingResources error", nil, err)
+		h.Err(c, err)
+		return
+	}
+	h.List(c, list, nil)
+}
+
+// BookingSlots 当天未开始的时段和剩余名额
+func (h *Handler) BookingSlots(c *gin.Context) {
+	r, ok := BindQuery[proto.BookingSlotArgs](c)
+	if !ok {
+		return
+	}
+	date, _ := time.ParseInLocation("2006-01-02", r.Date, time.Local)
+	list, err := h.service.FindBookingSlots(c, r.ResourceID, date)
+	if err != nil {
+		if err != model.ErrBookingResource {
+			logger.FromContext(c).Error("service.FindBookingSlots error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.List(c, list, nil)
+}
+
+// BookingReserve 占用名额，返回的hold_expire之前需确认
+func (h *Handler) BookingReserve(c *gin.Context) {
+	r, ok := Bind[proto.BookingArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	date, _ := time.ParseInLocation("2006-01-02", r.Date, time.Local)
+	b := &model.Booking{UserID: user.ID, ResourceID: r.ResourceID, TemplateID: r.TemplateID, Remark: r.Remark}
+	if err := h.service.ReserveBooking(c, b, date); err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.ReserveBooking error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, b)
+}
+
+func (h *Handler) BookingConfirm(c *gin.Context) {
+	r, ok := Bind[proto.BookingIDArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	b, err := h.service.ConfirmBooking(c, user.ID, r.ID)
+	if err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.ConfirmBooking error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, b)
+}
+
+// BookingCancel 已确认的预约需在资源设置的截止时间之前取消
+func (h *Handler) BookingCancel(c *gin.Context) {
+	r, ok := Bind[proto.BookingCancelArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	if err := h.service.CancelBooking(c, user.ID, r.ID, r.Reason); err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.CancelBooking error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, Empty)
+}
+
+func (h *Handler) BookingList(c *gin.Context) {
+	r, ok := BindQuery[proto.BookingListArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	list, err := h.service.FindBookings(c, user.ID, r.Page, r.Size)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindBookings error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.List(c, list, nil)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"hash/fnv"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"project/api/internal/proto"
+	"project/pkg/logger"
+	"project/pkg/metrics"
+	"project/pkg/reqctx"
+	"strconv"
+	"time"
+)
+
+const (
+	VariantStable = "stable"
+	VariantCanary = "canary"
+)
+
+type CanaryConfig struct {
+	Percent  uint32 // 灰度流量比例 0~100
+	Header   string // 强制指定版本的请求头，值为1进灰度，0进稳定版
+	Users    []int  // 指定进灰度的用户ID
+	Upstream string // 灰度部署的地址，为空时在本进程内按variant切换实现
+}
+
+var (
+	canaryTotal = metrics.NewCounter("canary_requests_total", "按版本统计的请求数",
+		"variant", "route", "status")
+	canaryDuration = metrics.NewHistogram("canary_request_duration_seconds", "按版本统计的请求耗时",
+		nil, "variant")
+)
+
+type canary struct {
+	cfg   *CanaryConfig
+	users map[int]struct{}
+	proxy *httputil.ReverseProxy
+}
+
+func newCanary(cfg *CanaryConfig) *canary {
+	cn := &canary{
+		cfg:   cfg,
+		users: make(map[int]struct{}, len(cfg.Users)),
+	}
+	for _, id := range cfg.Users {
+		cn.users[id] = struct{}{}
+	}
+	if cfg.Upstream != "" {
+		u, err := url.Parse(cfg.Upstream)
+		if err != nil {
+			panic(err)
+		}
+		cn.proxy = httputil.NewSingleHostReverseProxy(u)
+		cn.proxy.Transport = logger.NewTransport(http.DefaultTransport)
+	}
+	return cn
+}
+
+// variant 同一用户(未登录时为token、设备号或IP)总是落在同一个版本
+func (cn *canary) variant(c *gin.Context) string {
+	if cn.cfg.Header != "" {
+		switch c.GetHeader(cn.cfg.Header) {
+		case "1":
+			return VariantCanary
+		case "0":
+			return VariantStable
+		}
+	}
+	var key string
+	if u, ok := c.Get("user"); ok {
+		id := u.(*proto.UserToken).ID
+		if _, ok := cn.users[id]; ok {
+			return VariantCanary
+		}
+		key = strconv.Itoa(id)
+	} else if key = c.GetHeader("Authorization"); key == "" {
+		if key = c.GetHeader("X-Device-Id"); key == "" {
+			key = c.ClientIP()
+		}
+	}
+	if cn.cfg.Percent == 0 {
+		return VariantStable
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	if h.Sum32()%100 < cn.cfg.Percent {
+		return VariantCanary
+	}
+	return VariantStable
+}
+
+// Canary 灰度分流中间件，配置了upstream时灰度流量转发到灰度部署
+func (h *Handler) Canary(c *gin.Context) {
+	if h.canary == nil {
+		c.Next()
+		return
+	}
+	begin := time.Now()
+	v := h.canary.variant(c)
+	c.Set("variant", v)
+	c.Header("X-Variant", v)
+	if v == VariantCanary && h.canary.proxy != nil {
+		if h.canary.cfg.Header != "" {
+			c.Request.Header.Set(h.canary.cfg.Header, "0") // 防止灰度部署再次转发
+		}
+		reqctx.SetHeader(c, c.Request.Header) // 灰度部署可直接使用已鉴权的用户身份
+		h.canary.proxy.ServeHTTP(c.Writer, c.Request)
+		c.Abort()
+	} else {
+		c.Next()
+	}
+	canaryTotal.Inc(v, c.FullPath(), strconv.Itoa(c.Writer.Status()))
+	canaryDuration.Observe(time.Since(begin).Seconds(), v)
+}
+
+// Variant 按灰度结果选择不同的handler实现
+func Variant(stable, canary gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("variant") == VariantCanary {
+			canary(c)
+		} else {
+			stable(c)
+		}
+	}
+}
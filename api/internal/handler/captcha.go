@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/pkg/captcha"
+	"project/pkg/logger"
+	"project/pkg/metrics"
+	"strconv"
+	"time"
+)
+
+// 风险操作的验证码：配置的路由在始终要求、主体被风控标记或IP请求过于频繁时，需在X-Captcha-Ticket请求头提交票据
+// 客户端收到428 CAPTCHA_REQUIRED后调用captcha获取挑战，verify通过后携带票据重试原请求
+
+type CaptchaConfig struct {
+	Routes []*CaptchaRoute
+}
+
+type CaptchaRoute struct {
+	Route  string // method+path，如 POST/wechat/login
+	Always bool   // 每次请求都需要验证码
+	Limit  int64  // 同一IP在窗口内超过次数后需要验证码，0只在被风控标记时需要
+	Window int    // 计数窗口秒数，默认60
+}
+
+var captchaChallenged = metrics.NewCounter("captcha_challenged_total", "要求验证码的请求数", "route", "reason")
+
+func (h *Handler) initCaptcha(cfg *CaptchaConfig) {
+	if cfg == nil {
+		return
+	}
+	h.captchaRoutes = make(map[string]*CaptchaRoute, len(cfg.Routes))
+	for _, v := range cfg.Routes {
+		if v.Window <= 0 {
+			v.Window = 60
+		}
+		h.captchaRoutes[v.Route] = v
+	}
+}
+
+// NewCaptcha 获取滑块或图片验证码
+func (h *Handler) NewCaptcha(c *gin.Context) {
+	r, ok := BindQuery[proto.CaptchaArgs](c)
+	if !ok {
+		return
+	}
+	if r.Kind == "" {
+		r.Kind = captcha.KindSlider
+	}
+	ch, err := h.service.NewCaptcha(c, r.Kind)
+	if err != nil {
+		logger.FromContext(c).Error("service.NewCaptcha error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, ch)
+}
+
+// VerifyCaptcha 每个挑战只能提交一次，失败需重新获取
+func (h *Handler) VerifyCaptcha(c *gin.Context) {
+	r, ok := Bind[proto.CaptchaVerifyArgs](c)
+	if !ok {
+		return
+	}
+	ticket, err := h.service.VerifyCaptcha(c, r.ID, r.Answer)
+	if err != nil {
+		logger.FromContext(c).Warn("service.VerifyCaptcha fail", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, &proto.CaptchaVerifyResp{Ticket: ticket})
+}
+
+// FlagCaptcha 内部接口，供风控服务标记需要验证码的用户或IP
+func (h *Handler) FlagCaptcha(c *gin.Context) {
+	r, ok := Bind[proto.CaptchaFlagArgs](c)
+	if !ok {
+		return
+	}
+	if err := h.service.FlagCaptcha(c, r.Subject, time.Duration(r.TTL)*time.Second); err != nil {
+		logger.FromContext(c).Error("service.FlagCaptcha error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, Empty)
+}
+
+// Captcha 按路由要求验证码，需在AuthCheck/SessionCheck之后使用以识别用户；redis异常时放行
+func (h *Handler) Captcha(c *gin.Context) {
+	rule, ok := h.captchaRoutes[routeKey(c)]
+	if !ok {
+		c.Next()
+		return
+	}
+	reason := "always"
+	if !rule.Always {
+		reason = h.captchaReason(c, rule)
+		if reason == "" {
+			c.Next()
+			return
+		}
+	}
+	ticket := c.GetHeader("X-Captcha-Ticket")
+	valid, err := h.service.RedeemCaptcha(c, ticket)
+	if err != nil {
+		logger.FromContext(c).Warn("service.RedeemCaptcha fail", ticket, err)
+		c.Next()
+		return
+	}
+	if valid {
+		c.Next()
+		return
+	}
+	captchaChallenged.Inc(routeKey(c), reason)
+	detail := "CAPTCHA_REQUIRED"
+	if ticket != "" {
+		detail = "CAPTCHA_INVALID"
+	}
+	c.AbortWithStatusJSON(PreconditionRequired, &RespErr{Msg: "请完成安全验证", Detail: detail})
+}
+
+// captchaReason 返回需要验证码的原因，flagged为风控标记，limit为请求过于频繁，空为不需要
+func (h *Handler) captchaReason(c *gin.Context, rule *CaptchaRoute) string {
+	window := time.Duration(rule.Window) * time.Second
+	if v, ok := c.Get("user"); ok {
+		flagged, err := h.service.CaptchaRequired(c, "user:"+strconv.Itoa(v.(*proto.UserToken).ID), 0, window)
+		if err != nil {
+			logger.FromContext(c).Warn("service.CaptchaRequired fail", rule.Route, err)
+		}
+		if flagged {
+			return "flagged"
+		}
+	}
+	required, err := h.service.CaptchaRequired(c, "ip:"+c.ClientIP(), rule.Limit, window)
+	if err != nil {
+		logger.FromContext(c).Warn("service.CaptchaRequired fail", rule.Route, err)
+		return ""
+	}
+	if !required {
+		return ""
+	}
+	if rule.Limit > 0 {
+		return "limit"
+	}
+	return "flagged"
+}
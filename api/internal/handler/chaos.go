@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"math/rand"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/util/random"
+	"time"
+)
+
+// 故障注入中间件，仅在非生产环境且开启handler.chaos配置时生效，规则由cms后台写入redis
+
+func (h *Handler) loadChaosRules() error {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Chaos", "loadChaosRules", "")
+	rules, err := h.service.GetChaosRules(ctx)
+	if err != nil {
+		l.Error("service.GetChaosRules error", nil, err)
+		return err
+	}
+	h.chaos.Store(rules)
+	return nil
+}
+
+func (h *Handler) watchChaosRules() {
+	h.loadChaosRules()
+	for range time.Tick(10 * time.Second) {
+		h.loadChaosRules()
+	}
+}
+
+func (h *Handler) Chaos(c *gin.Context) {
+	rules, _ := h.chaos.Load().(map[string]*model.ChaosRule)
+	rule, ok := rules[routeKey(c)]
+	if !ok || rand.Intn(100) >= rule.Percent {
+		c.Next()
+		return
+	}
+	logger.FromContext(c).Warn("chaos", rule.Route, rule)
+	if rule.Latency > 0 {
+		time.Sleep(time.Duration(rule.Latency) * time.Millisecond)
+	}
+	if rule.Drop {
+		if conn, _, err := c.Writer.Hijack(); err == nil {
+			conn.Close() //nolint
+		}
+		c.Abort()
+		return
+	}
+	if rule.Status > 0 {
+		c.AbortWithStatusJSON(rule.Status, &RespErr{
+			Msg:    "系统繁忙",
+			Detail: "CHAOS",
+		})
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+)
+
+/*
+即时消息：消息经本接口保存后由WebSocket网关投递给在线成员(chat主题)，离线成员收到推送
+客户端收到的消息seq不连续或重连后，按最后的seq调用chat/messages?after=补齐
+*/
+
+// ChatConversationCreate 发起单聊(已存在时返回原会话)或创建群聊
+func (h *Handler) ChatConversationCreate(c *gin.Context) {
+	r, ok := Bind[proto.ChatConversationArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	var conv *model.ChatConversation
+	var err error
+	if r.Type == "single" {
+		if len(r.UserIDs) != 1 || r.UserIDs[0] == user.ID {
+			h.Fail(c, InvalidParam, "单聊需指定一个其他用户")
+			return
+		}
+		conv, err = h.service.OpenSingleChat(c, user.ID, r.UserIDs[0])
+	} else {
+		conv, err = h.service.CreateGroupChat(c, user.ID, r.Name, r.UserIDs)
+	}
+	if err != nil {
+		logger.FromContext(c).Error("service.CreateChat error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, conv)
+}
+
+// ChatConversations 会话列表，按最后一条消息时间倒序
+func (h *Handler) ChatConversations(c *gin.Context) {
+	r, ok := BindQuery[proto.ChatConversationListArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	list, err := h.service.FindChatConversations(c, user.ID, r.Page, r.Size)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindChatConversations error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.List(c, list, nil)
+}
+
+// ChatMembers 成员和各自的已读位置，用于显示已读人数
+func (h *Handler) ChatMembers(c *gin.Context) {
+	r, ok := BindQuery[proto.ChatIDArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	list, err := h.service.FindChatMembers(c, user.ID, r.ConversationID)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindChatMembers error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.List(c, list, nil)
+}
+
+// ChatSend 发送消息，client_msg_id重复时返回已保存的消息，客户端超时重发不会产生重复消息
+func (h *Handler) ChatSend(c *gin.Context) {
+	r, ok := Bind[proto.ChatMessageArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	msg, err := h.service.SendChatMessage(c, &model.ChatMessage{
+		ConversationID: r.ConversationID,
+		SenderID:       user.ID,
+		ClientMsgID:    r.ClientMsgID,
+		Type:           r.Type,
+		Content:        r.Content,
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.SendChatMessage error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, msg)
+}
+
+func (h *Handler) ChatMessages(c *gin.Context) {
+	r, ok := BindQuery[proto.ChatHistoryArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	list, err := h.service.FindChatMessages(c, user.ID, r.ConversationID, r.Before, r.After, r.Size)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindChatMessages error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	if list == nil {
+		list = make([]*model.ChatMessage, 0)
+	}
+	h.List(c, list, nil)
+}
+
+// ChatRead 已读回执，seq只前移，超过最新消息时不更新
+func (h *Handler) ChatRead(c *gin.Context) {
+	r, ok := Bind[proto.ChatReadArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	if _, err := h.service.ReadChat(c, user.ID, r.ConversationID, r.Seq); err != nil {
+		logger.FromContext(c).Error("service.ReadChat error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, Empty)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/pkg/logger"
+)
+
+// Checkin 签到，今天已签到时new为false并返回当天的记录
+func (h *Handler) Checkin(c *gin.Context) {
+	r, ok := Bind[proto.CheckinArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	data, err := h.service.Checkin(c, user.ID, r.Timezone)
+	if err != nil {
+		logger.FromContext(c).Error("service.Checkin error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, data)
+}
+
+func (h *Handler) CheckinStatus(c *gin.Context) {
+	r, ok := BindQuery[proto.CheckinStatusArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	data, err := h.service.FindCheckinStatus(c, user.ID, r.Timezone)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindCheckinStatus error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, data)
+}
+
+// CheckinCalendar 按月的签到日历，日期为签到时所在时区的日期
+func (h *Handler) CheckinCalendar(c *gin.Context) {
+	r, ok := BindQuery[proto.CheckinCalendarArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	list, err := h.service.FindCheckinCalendar(c, user.ID, r.Month)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindCheckinCalendar error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.List(c, list, nil)
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/pkg/util/useragent"
+)
+
+// ClientInfo 解析User-Agent和Referer得到平台、微信版本、运行环境，写入上下文并由AccessLog记录
+func ClientInfo(c *gin.Context) {
+	c.Set("client", useragent.Parse(c.Request.UserAgent(), c.Request.Referer()))
+	c.Next()
+}
+
+// GetClient 获取当前请求的客户端信息，供handler按平台或微信版本做功能开关
+func GetClient(c *gin.Context) *useragent.Client {
+	if v, ok := c.Get("client"); ok {
+		return v.(*useragent.Client)
+	}
+	return useragent.Parse(c.Request.UserAgent(), c.Request.Referer())
+}
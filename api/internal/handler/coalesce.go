@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+	"net/http"
+	"project/api/internal/proto"
+	"project/pkg/metrics"
+	"strconv"
+)
+
+// 请求合并：配置的GET路由在同一时刻收到相同的请求(路由、path参数、query、用户、语言)时只执行一次，其余请求等待并复用响应
+// 用于客户端重试风暴时保护db；响应体中的trace_id为实际执行的请求的，响应头各自保留
+
+type CoalesceConfig struct {
+	Routes []*CoalesceRoute
+}
+
+type CoalesceRoute struct {
+	Route  string // GET路由模板，如 GET/wechat/coupon/templates
+	Public bool   // 响应与用户无关，不同用户的请求也合并
+}
+
+type coalescedResp struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+var coalesced = metrics.NewCounter("coalesced_total", "复用其他请求响应的请求数", "route")
+
+func (h *Handler) initCoalesce(cfg *CoalesceConfig) {
+	if cfg == nil || len(cfg.Routes) == 0 {
+		return
+	}
+	h.coalesce = &singleflight.Group{}
+	h.coalesceRoutes = make(map[string]*CoalesceRoute, len(cfg.Routes))
+	for _, v := range cfg.Routes {
+		h.coalesceRoutes[v.Route] = v
+	}
+}
+
+// Coalesce 需在AuthCheck/SessionCheck之后使用以区分用户；执行请求的处理函数在当前协程中运行，其余请求等待其结束
+func (h *Handler) Coalesce(c *gin.Context) {
+	rule, ok := h.coalesceRoutes[routeKey(c)]
+	if !ok || c.Request.Method != http.MethodGet {
+		c.Next()
+		return
+	}
+	leader := false
+	v, _, _ := h.coalesce.Do(coalesceKey(c, rule), func() (any, error) {
+		leader = true
+		w := &BodyLogWriter{ResponseWriter: c.Writer, body: bytes.NewBuffer(nil)}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+		return &coalescedResp{status: w.Status(), header: w.Header().Clone(), body: w.body.Bytes()}, nil
+	})
+	if leader {
+		return
+	}
+	coalesced.Inc(rule.Route)
+	resp := v.(*coalescedResp)
+	header := c.Writer.Header()
+	for k, v := range resp.header {
+		if _, exists := header[k]; !exists {
+			header[k] = v
+		}
+	}
+	c.Writer.WriteHeader(resp.status)
+	_, _ = c.Writer.Write(resp.body)
+	c.Abort()
+}
+
+func coalesceKey(c *gin.Context, rule *CoalesceRoute) string {
+	key := c.Request.URL.Path + "?" + c.Request.URL.Query().Encode() + "|" + c.GetHeader("Accept-Language")
+	if rule.Public {
+		return key
+	}
+	if v, ok := c.Get("user"); ok {
+		return key + "|user:" + strconv.Itoa(v.(*proto.UserToken).ID)
+	}
+	if v, ok := c.Get("guest"); ok {
+		return key + "|guest:" + v.(*proto.GuestToken).ID
+	}
+	return key + "|" + c.GetHeader("Authorization")
+}
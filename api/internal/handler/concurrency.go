@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/pkg/logger"
+	"project/pkg/metrics"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 并发上限：按路由组限制同时处理的请求数(如导出、报表等重查询)，保护db不被大量并行的慢查询拖垮
+// 与priority不同，这里是固定的硬上限；组满时返回429，全局满时返回503，可配置排队等待的毫秒数
+
+type ConcurrencyConfig struct {
+	Global int                 // 全部路由同时处理的上限，0不限制
+	Wait   int                 // 全局满时排队等待的毫秒数，0直接拒绝
+	Groups []*ConcurrencyGroup // 一个路由只属于一个组，精确匹配优先，其次按配置顺序的第一个前缀
+}
+
+type ConcurrencyGroup struct {
+	Name   string   // 指标中的名称
+	Routes []string // method+path，如 GET/wechat/ledger/statement，以*结尾时按前缀匹配
+	Max    int      // 同时处理的上限
+	Wait   int      // 组满时排队等待的毫秒数，0直接拒绝
+}
+
+var (
+	concurrencyCurrent  = metrics.NewGauge("concurrency_current", "处理中的请求数", "group")
+	concurrencyPeak     = metrics.NewGauge("concurrency_peak", "进程启动以来处理中请求数的峰值", "group")
+	concurrencyRejected = metrics.NewCounter("concurrency_rejected_total", "超出并发上限被拒绝的请求数", "group")
+)
+
+const globalGroup = "global"
+
+type limiter struct {
+	name string
+	sem  chan struct{}
+	wait time.Duration
+
+	mu   sync.Mutex
+	cur  int
+	peak int
+}
+
+func newLimiter(name string, max, wait int) *limiter {
+	return &limiter{name: name, sem: make(chan struct{}, max), wait: time.Duration(wait) * time.Millisecond}
+}
+
+// acquire 等待空位，超过wait或请求已取消返回false
+func (l *limiter) acquire(c *gin.Context) bool {
+	select {
+	case l.sem <- struct{}{}:
+	default:
+		if l.wait <= 0 {
+			return false
+		}
+		timer := time.NewTimer(l.wait)
+		defer timer.Stop()
+		select {
+		case l.sem <- struct{}{}:
+		case <-timer.C:
+			return false
+		case <-c.Done():
+			return false
+		}
+	}
+	l.mu.Lock()
+	l.cur++
+	if l.cur > l.peak {
+		l.peak = l.cur
+		concurrencyPeak.Set(float64(l.peak), l.name)
+	}
+	concurrencyCurrent.Set(float64(l.cur), l.name)
+	l.mu.Unlock()
+	return true
+}
+
+func (l *limiter) release() {
+	l.mu.Lock()
+	l.cur--
+	concurrencyCurrent.Set(float64(l.cur), l.name)
+	l.mu.Unlock()
+	<-l.sem
+}
+
+type concurrency struct {
+	global    *limiter
+	routes    map[string]*limiter
+	prefixes  []string // 与prefixLim按下标对应，保持配置顺序
+	prefixLim []*limiter
+}
+
+func newConcurrency(cfg *ConcurrencyConfig) *concurrency {
+	cc := &concurrency{routes: make(map[string]*limiter)}
+	if cfg.Global > 0 {
+		cc.global = newLimiter(globalGroup, cfg.Global, cfg.Wait)
+	}
+	for _, g := range cfg.Groups {
+		if g.Max <= 0 {
+			continue
+		}
+		l := newLimiter(g.Name, g.Max, g.Wait)
+		for _, r := range g.Routes {
+			if strings.HasSuffix(r, "*") {
+				cc.prefixes = append(cc.prefixes, strings.TrimSuffix(r, "*"))
+				cc.prefixLim = append(cc.prefixLim, l)
+			} else if _, exists := cc.routes[r]; !exists {
+				cc.routes[r] = l
+			}
+		}
+	}
+	return cc
+}
+
+func (cc *concurrency) group(route string) *limiter {
+	if l, ok := cc.routes[route]; ok {
+		return l
+	}
+	for i, p := range cc.prefixes {
+		if strings.HasPrefix(route, p) {
+			return cc.prefixLim[i]
+		}
+	}
+	return nil
+}
+
+// Concurrency 先占路由组名额再占全局名额，避免组内排队的请求占用全局名额；任一不足即拒绝
+func (h *Handler) Concurrency(c *gin.Context) {
+	if l := h.concurrency.group(routeKey(c)); l != nil {
+		if !l.acquire(c) {
+			concurrencyRejected.Inc(l.name)
+			logger.FromContext(c).Warn("concurrency rejected", l.name, routeKey(c))
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(RateLimit, &RespErr{Msg: "当前请求人数较多，请稍后再试", Detail: "CONCURRENCY_LIMIT"})
+			return
+		}
+		defer l.release()
+	}
+	if l := h.concurrency.global; l != nil {
+		if !l.acquire(c) {
+			concurrencyRejected.Inc(l.name)
+			logger.FromContext(c).Warn("concurrency rejected", l.name, routeKey(c))
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(ServiceUnavailable, &RespErr{Msg: "系统繁忙，请稍后再试", Detail: "OVERLOADED"})
+			return
+		}
+		defer l.release()
+	}
+	c.Next()
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"regexp"
+)
+
+// 运营内容(首页布局、公告等)：cdn缓存7天，cms发布后按标签或url刷新；客户端不缓存，每次带If-None-Match校验，未变更返回304
+
+const contentCDNAge = "604800"
+
+var contentKeyReg = regexp.MustCompile(`^\w{1,32}$`)
+
+func (h *Handler) GetContent(c *gin.Context) {
+	key := c.Param("key")
+	if !contentKeyReg.MatchString(key) {
+		c.Header("Cache-Control", "public, max-age=60")
+		h.Err(c, model.ErrContentNotFound)
+		return
+	}
+	data, err := h.service.GetContent(c, key)
+	if err == model.ErrContentNotFound {
+		c.Header("Cache-Control", "public, max-age=60")
+		h.Err(c, err)
+		return
+	}
+	if err != nil {
+		logger.FromContext(c).Error("service.GetContent error", key, err)
+		c.Header("Cache-Control", "no-store")
+		h.Err(c, err)
+		return
+	}
+	etag := data.ETag()
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=0, s-maxage="+contentCDNAge)
+	c.Header("Cache-Tag", model.CdnTagContent(key))
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(NotModified)
+		return
+	}
+	h.OK(c, &proto.ContentResp{
+		Key:         data.Key,
+		Version:     data.Version,
+		PublishTime: data.PublishTime,
+		Data:        data.Body,
+	})
+}
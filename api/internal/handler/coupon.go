@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+)
+
+func (h *Handler) CouponTemplates(c *gin.Context) {
+	list, err := h.service.ClaimableCoupons(c)
+	if err != nil {
+		logger.FromContext(c).Error("service.ClaimableCoupons error", nil, err)
+		h.Err(c, err)
+		return
+	}
+	if list == nil {
+		list = make([]*model.CouponTemplate, 0)
+	}
+	h.List(c, list, nil)
+}
+
+func (h *Handler) CouponClaim(c *gin.Context) {
+	r, ok := Bind[proto.CouponClaimArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	coupon, err := h.service.ClaimCoupon(c, user.ID, r.TemplateID)
+	if err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.ClaimCoupon error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, coupon)
+}
+
+func (h *Handler) CouponList(c *gin.Context) {
+	r, ok := BindQuery[proto.CouponListArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	list, err := h.service.FindUserCoupons(c, user.ID, r.Status)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindUserCoupons error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	if list == nil {
+		list = make([]*model.Coupon, 0)
+	}
+	h.List(c, list, nil)
+}
+
+// CouponPrice 下单前试算，与下单时核销使用相同的校验
+func (h *Handler) CouponPrice(c *gin.Context) {
+	r, ok := Bind[proto.CouponPriceArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	d, err := h.service.PriceCoupon(c, user.ID, r.CouponID, r.Amount)
+	if err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.PriceCoupon error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, &proto.CouponPriceResp{
+		Amount:    r.Amount,
+		Discount:  d,
+		PayAmount: r.Amount - d,
+	})
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"project/pkg/metrics"
+	"project/pkg/reqctx"
+	"time"
+)
+
+// 请求预算：按客户端的X-Request-Timeout或Grpc-Timeout设置请求context的截止时间，不超过服务端上限
+// engine开启ContextWithFallback，service、db、redis和上游http调用使用c作为context即可在超时或客户端断开后停止
+
+type DeadlineConfig struct {
+	Max     int // 预算上限毫秒，默认为server.writeTimeout
+	Default int // 客户端未传时的预算毫秒，0不设置截止时间
+}
+
+var deadlineExceeded = metrics.NewCounter("deadline_exceeded_total", "超出客户端预算的请求数", "route")
+
+func (h *Handler) initDeadline(cfg *DeadlineConfig, writeTimeout int) {
+	if writeTimeout <= 0 {
+		writeTimeout = 60
+	}
+	h.deadlineMax = time.Duration(writeTimeout) * time.Second
+	if cfg == nil {
+		return
+	}
+	if cfg.Max > 0 {
+		h.deadlineMax = time.Duration(cfg.Max) * time.Millisecond
+	}
+	h.deadlineDefault = time.Duration(cfg.Default) * time.Millisecond
+}
+
+// Deadline 未传预算且未配置默认值时不设置截止时间
+func (h *Handler) Deadline(c *gin.Context) {
+	d, ok := reqctx.ParseTimeout(c.GetHeader)
+	if !ok {
+		d = h.deadlineDefault
+	}
+	if d <= 0 {
+		c.Next()
+		return
+	}
+	if d > h.deadlineMax {
+		d = h.deadlineMax
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		deadlineExceeded.Inc(routeKey(c))
+	}
+}
@@ -3,27 +3,30 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"project/api/internal/proto"
+	"project/model"
 	"project/pkg/logger"
-	"strings"
 	"time"
 )
 
 func (h *Handler) GetBanners(c *gin.Context) {
 	city := c.Query("city")
+	if c.Query("updated_since") != "" {
+		h.syncBanners(c, city)
+		return
+	}
 	data, err := h.service.GetBannersByCity(c, city)
 	if err != nil {
 		logger.FromContext(c).Error("service.GetBannersByCity error", nil, err)
-		c.JSON(RespWithErr(err))
+		h.Err(c, err)
 		return
 	}
 	list := make([]*proto.BannerItem, 0, len(data))
 	now := time.Now().Unix()
 	for _, d := range data {
 		if d.BeginTime <= now && now < d.EndTime {
-			if !strings.HasPrefix(d.Img, "http") { //相对路径拼上cdn域名
-				d.Img = h.cdn + d.Img
-			}
+			d.Img = h.cdn.URL(d.Img) //相对路径拼上cdn域名
 			list = append(list, &proto.BannerItem{
+				ID:    d.ID,
 				Title: d.Title,
 				Img:   d.Img,
 				Type:  d.Type,
@@ -31,8 +34,51 @@ func (h *Handler) GetBanners(c *gin.Context) {
 			})
 		}
 	}
-	c.JSON(OK, &proto.BannersResp{
-		List: list,
+	c.Header("Cache-Tag", model.CdnTagBanner)
+	h.List(c, list, nil)
+}
+
+func (h *Handler) syncBanners(c *gin.Context, city string) {
+	r, ok := BindQuery[proto.SyncArgs](c)
+	if !ok {
+		return
+	}
+	// 游标早于墓碑保留期时期间删除的记录可能已清理，从头同步；全量重建的后续分页不再重置
+	full := !r.FullSync && r.UpdatedSince < time.Now().AddDate(0, 0, -model.TombstoneDays).Unix()
+	if full {
+		r.UpdatedSince, r.SinceID = 0, 0
+	}
+	data, syncTime, syncID, hasMore, err := h.service.SyncBannersByCity(c, city, time.Unix(r.UpdatedSince, 0), r.SinceID)
+	if err != nil {
+		logger.FromContext(c).Error("service.SyncBannersByCity error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	list := make([]*proto.BannerItem, 0, len(data))
+	deleted := make([]int, 0)
+	for _, d := range data {
+		if d.DeleteTime.Valid || d.Status != model.StatusOn {
+			deleted = append(deleted, d.ID)
+			continue
+		}
+		d.Img = h.cdn.URL(d.Img)
+		list = append(list, &proto.BannerItem{
+			ID:        d.ID,
+			Title:     d.Title,
+			Img:       d.Img,
+			Type:      d.Type,
+			Link:      d.Link,
+			BeginTime: d.BeginTime,
+			EndTime:   d.EndTime,
+		})
+	}
+	h.OK(c, &proto.SyncResp{
+		List:       list,
+		DeletedIDs: deleted,
+		SyncTime:   syncTime.Unix(),
+		SyncID:     syncID,
+		HasMore:    hasMore,
+		FullSync:   full,
 	})
 }
 
@@ -43,8 +89,8 @@ func (h *Handler) PushMessage(c *gin.Context) {
 	//})
 	//if err != nil {
 	//	logger.FromContext(c).Error("service.PushMessage error", nil, err)
-	//	c.JSON(RespWithErr(err))
+	//	h.Err(c, err)
 	//	return
 	//}
-	c.JSON(OK, Empty)
+	h.OK(c, Empty)
 }
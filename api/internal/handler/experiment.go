@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/api/internal/service"
+	"project/model"
+	"project/pkg/logger"
+	"time"
+)
+
+// GetExperiments 返回当前用户命中的实验分组，并投递曝光消息
+func (h *Handler) GetExperiments(c *gin.Context) {
+	data, err := h.service.AllExperiments(c)
+	if err != nil {
+		logger.FromContext(c).Error("service.AllExperiments error", nil, err)
+		h.Err(c, err)
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	now := time.Now().Unix()
+	var segments map[string]bool
+	list := make([]*proto.ExperimentItem, 0, len(data))
+	exposure := make([]*model.MsgExposure, 0, len(data))
+	for _, d := range data {
+		if d.BeginTime > now || (d.EndTime > 0 && now >= d.EndTime) {
+			continue
+		}
+		if d.Segment != "" {
+			if segments == nil { // 有限定分群的实验时才查询
+				if segments, err = h.service.UserSegments(c, user.ID); err != nil {
+					logger.FromContext(c).Error("service.UserSegments error", user.ID, err)
+				}
+			}
+			if !segments[d.Segment] {
+				continue
+			}
+		}
+		v := service.AssignVariant(d, user.Openid)
+		if v == "" {
+			continue
+		}
+		list = append(list, &proto.ExperimentItem{
+			Key:     d.Key,
+			Variant: v,
+		})
+		exposure = append(exposure, &model.MsgExposure{
+			Experiment: d.Key,
+			Variant:    v,
+			UserID:     user.ID,
+			Openid:     user.Openid,
+			TraceID:    c.GetString("trace_id"),
+			Time:       now,
+		})
+	}
+	if len(exposure) > 0 {
+		if err := h.service.PushExposure(c, exposure); err != nil {
+			logger.FromContext(c).Error("service.PushExposure error", exposure, err)
+		}
+	}
+	h.List(c, list, nil)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"project/pkg/logger"
+	"project/pkg/util/fields"
+	"strings"
+)
+
+type bufferWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bufferWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// Fields 按?fields=参数裁剪json响应，减少小程序弱网页面的传输量
+func Fields(c *gin.Context) {
+	paths := fields.Split(c.Query("fields"))
+	if len(paths) == 0 {
+		c.Next()
+		return
+	}
+	w := &bufferWriter{
+		ResponseWriter: c.Writer,
+		body:           bytes.NewBuffer(nil),
+	}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	b := w.body.Bytes()
+	if w.Status() == OK && len(b) > 0 &&
+		strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		if c.GetBool(envelopeKey) { // 统一响应结构只裁剪data
+			for i := range paths {
+				paths[i] = "data." + paths[i]
+			}
+			paths = append(paths, "code", "msg", "meta")
+		}
+		if pruned, err := fields.Prune(b, paths); err == nil {
+			b = pruned
+		} else {
+			logger.FromContext(c).Warn("fields.Prune fail", paths, err)
+		}
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	_, _ = w.ResponseWriter.Write(b)
+}
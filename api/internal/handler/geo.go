@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"project/pkg/geoip"
+	"project/pkg/logger"
+	"strings"
+	"time"
+)
+
+type GeoConfig struct {
+	DB    string     // GeoLite2-City格式的mmdb文件路径，为空不启用
+	Rules []*GeoRule // 按路由前缀限制访问地区，按顺序匹配第一条
+}
+
+type GeoRule struct {
+	Prefix       string
+	Allow        []string // 国家ISO代码(如CN，不含港澳台)或省份名(如广东省)
+	AllowUnknown bool     `mapstructure:"allowUnknown"` // 内网或库中不存在的IP是否放行
+}
+
+func (h *Handler) initGeo(cfg *GeoConfig) {
+	if cfg == nil || cfg.DB == "" {
+		return
+	}
+	db, err := geoip.Open(cfg.DB)
+	if err != nil {
+		log.Fatal("geoip.Open error: ", err)
+	}
+	h.geo = db
+	h.geoRules = cfg.Rules
+	go db.Watch(time.Minute, func(err error) {
+		log.Println("geoip reload error: ", err)
+	})
+}
+
+// Geo 解析客户端IP所在地区写入上下文(AccessLog记录)，并按路由前缀限制访问地区
+func (h *Handler) Geo(c *gin.Context) {
+	loc := h.geo.Lookup(c.ClientIP())
+	if loc != nil {
+		c.Set("geo", loc)
+	}
+	path := c.Request.URL.Path
+	for _, rule := range h.geoRules {
+		if !strings.HasPrefix(path, rule.Prefix) {
+			continue
+		}
+		if !geoAllowed(rule, loc) {
+			logger.FromContext(c).Warn("geo forbidden", c.ClientIP(), loc)
+			c.AbortWithStatusJSON(RespWithMsg(Forbidden, "当前地区暂不支持访问"))
+			return
+		}
+		break
+	}
+	c.Next()
+}
+
+func geoAllowed(rule *GeoRule, loc *geoip.Location) bool {
+	if loc == nil {
+		return rule.AllowUnknown
+	}
+	for _, v := range rule.Allow {
+		if v == loc.Country || v == loc.Province {
+			return true
+		}
+	}
+	return false
+}
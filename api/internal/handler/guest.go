@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/api/internal/service"
+	"project/model"
+	"project/pkg/logger"
+)
+
+// 游客会话：未登录设备领取游客凭证后可访问SessionCheck下的浏览和购物车接口，AuthCheck下的接口仍需登录
+
+// GuestSession 请求头带有同一设备仍有效的游客凭证时顺延并返回原凭证，否则签发新凭证；按IP和设备限制频率
+func (h *Handler) GuestSession(c *gin.Context) {
+	r, ok := Bind[proto.GuestSessionArgs](c)
+	if !ok {
+		return
+	}
+	old := c.GetHeader("Authorization")
+	if !service.IsGuestToken(old) {
+		old = ""
+	}
+	token, err := h.service.SetGuestToken(c, r.DeviceID, c.ClientIP(), old)
+	if err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.SetGuestToken error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, &proto.GuestSessionResp{
+		Token:     token,
+		ExpiresIn: int(service.GuestTTL.Seconds()),
+	})
+}
+
+// SessionCheck 同时接受登录凭证和游客凭证，登录用户写入上下文user，游客写入guest
+func (h *Handler) SessionCheck(c *gin.Context) {
+	token := c.GetHeader("Authorization")
+	if !service.IsGuestToken(token) {
+		h.AuthCheck(c)
+		return
+	}
+	guest, err := h.service.GetGuestToken(c, token)
+	if err != nil {
+		logger.FromContext(c).Error("service.GetGuestToken error", token, err)
+		c.AbortWithStatusJSON(RespWithErr(err))
+		return
+	}
+	if guest == nil {
+		c.AbortWithStatusJSON(RespWithMsg(Unauthorized, "Authorization Expired"))
+		return
+	}
+	c.Set("guest", guest)
+	c.Set("v2", "guest:"+guest.ID)
+	c.Next()
+}
+
+// cartOwner 登录用户和游客的购物车分开存放，登录时合并
+func cartOwner(c *gin.Context) string {
+	if u, ok := c.Get("user"); ok {
+		return service.UserCartOwner(u.(*proto.UserToken).ID)
+	}
+	g, _ := c.Get("guest")
+	return service.GuestCartOwner(g.(*proto.GuestToken).ID)
+}
+
+func (h *Handler) GetCart(c *gin.Context) {
+	list, err := h.service.GetCart(c, cartOwner(c))
+	if err != nil {
+		logger.FromContext(c).Error("service.GetCart error", cartOwner(c), err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, &proto.CartResp{List: list})
+}
+
+func (h *Handler) SetCartItem(c *gin.Context) {
+	r, ok := Bind[proto.CartArgs](c)
+	if !ok {
+		return
+	}
+	if err := h.service.SetCartItem(c, cartOwner(c), r.SkuID, r.Quantity); err != nil {
+		logger.FromContext(c).Error("service.SetCartItem error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, Empty)
+}
+
+func (h *Handler) ClearCart(c *gin.Context) {
+	if err := h.service.ClearCart(c, cartOwner(c)); err != nil {
+		logger.FromContext(c).Error("service.ClearCart error", cartOwner(c), err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, Empty)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/url"
+	"project/pkg/h5"
+	"project/pkg/logger"
+	"project/pkg/wechat"
+	"strings"
+)
+
+// 服务端渲染的H5页面：微信内打开的活动页由api渲染，配置jssdk后注入按页面url签名的wx.config
+
+type H5Config struct {
+	h5.Options `mapstructure:",squash"`
+	BaseURL    string   `mapstructure:"baseUrl"` // 页面的对外地址，如 https://m.domain.cn ，签名的url需与微信内的地址一致
+	JSAPIList  []string `mapstructure:"jsApiList"`
+	Debug      bool     // wx.config开启调试
+}
+
+// H5Data 页面模板的数据
+type H5Data struct {
+	Page      string
+	Query     url.Values
+	WX        *wechat.JSConfig // 未配置jssdk或获取ticket失败时为空，页面需能降级
+	JSAPIList []string
+	Debug     bool
+}
+
+func (h *Handler) initH5(cfg *H5Config) {
+	if cfg == nil || cfg.Dir == "" {
+		return
+	}
+	r, err := h5.New(h.cdn.Domain(), &cfg.Options)
+	if err != nil {
+		log.Fatal("h5.New error: ", err)
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	h.h5 = r
+	h.h5Cfg = cfg
+}
+
+// H5Page 签名与请求的url相关，页面不可被cdn缓存
+func (h *Handler) H5Page(c *gin.Context) {
+	page := c.Param("page")
+	data := &H5Data{
+		Page:      page,
+		Query:     c.Request.URL.Query(),
+		WX:        h.jsConfig(c, h.h5URL(c)+c.Request.URL.RequestURI()),
+		JSAPIList: h.h5Cfg.JSAPIList,
+		Debug:     h.h5Cfg.Debug,
+	}
+	var buf bytes.Buffer
+	err := h.h5.Render(&buf, page, data)
+	if err == h5.ErrNotFound {
+		h.Fail(c, NotFound, "页面不存在")
+		return
+	}
+	if err != nil {
+		logger.FromContext(c).Error("h5.Render error", page, err)
+		h.Err(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-cache")
+	c.Data(OK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// h5URL 页面的scheme和host，未配置baseUrl时按请求推断
+func (h *Handler) h5URL(c *gin.Context) string {
+	if h.h5Cfg.BaseURL != "" {
+		return h.h5Cfg.BaseURL
+	}
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// jsConfig ticket不可用时返回nil，页面仍可打开
+func (h *Handler) jsConfig(c *gin.Context, pageURL string) *wechat.JSConfig {
+	if h.jssdk == nil {
+		return nil
+	}
+	cfg, err := h.jssdk.Config(c, pageURL)
+	if err != nil {
+		logger.FromContext(c).Warn("jssdk.Config fail", pageURL, err)
+		return nil
+	}
+	return cfg
+}
@@ -2,71 +2,239 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
+	"errors"
 	"github.com/gin-gonic/gin"
 	uuid "github.com/satori/go.uuid"
+	"golang.org/x/sync/singleflight"
 	"io"
+	"log"
 	"net/http"
+	"net/url"
 	"project/api/internal/service"
+	"project/model"
+	"project/pkg/cdn"
+	"project/pkg/geoip"
+	"project/pkg/h5"
 	"project/pkg/logger"
+	"project/pkg/metrics"
+	"project/pkg/payment"
+	"project/pkg/reqctx"
+	"project/pkg/server"
 	"project/pkg/wechat"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Config struct {
-	Cdn    string
-	Wechat struct {
-		Appid  string
-		Secret string
+	Server   server.Config // 监听地址和TLS
+	Cdn      string        // 当前环境的cdn地址，末尾带/
+	CdnAuth  *cdn.Options  // 私有内容的url签名和旧域名替换，为空只拼接cdn地址
+	Chaos    bool          // 开启故障注入(生产环境无效)
+	Canary   *CanaryConfig
+	Envelope []string // 使用统一响应结构的路由前缀，如 /wechat/
+	Strict   []string `mapstructure:"strictJson"` // 严格解析json请求体的路由前缀
+	Wechat   struct {
+		Appid   string
+		Secret  string
+		Token   string           // 消息推送令牌
+		Welcome string           // 进入客服会话的欢迎语
+		Quota   map[string]int64 // 接口每日额度，key为path，用完后直接返回503不再请求微信
 	}
+	OAuth           map[string]*OAuthApp         `mapstructure:"oauth"` // 开放平台登录，key为渠道如web、app
+	Payment         payment.Config               // 未配置商户号/应用ID的渠道不启用
+	ShortLinkMaxAge int64                        `mapstructure:"shortLinkMaxAge"` // 短链跳转的缓存秒数
+	Geo             *GeoConfig                   // IP地区解析和按路由限制访问地区
+	Upgrade         map[string]*model.AppVersion // 各平台客户端最低版本，key为ios|android
+	Meter           *MeterConfig                 // 调用计量和配额
+	Priority        *PriorityConfig              // 按路由优先级限流，过载时优先拒绝低优先级请求
+	Internal        *InternalConfig              // 内部端口，未配置时metrics仍挂在公网端口
+	Body            *BodyConfig                  // 请求体大小限制
+	Deprecated      []*Deprecation               // 已废弃的路由
+	AccessLog       *AccessLogConfig             `mapstructure:"accessLog"` // access日志写入clickhouse，未配置url不启用
+	Captcha         *CaptchaConfig               // 需要验证码的路由
+	Deadline        *DeadlineConfig              // 按客户端传入的等待时间设置请求截止时间
+	Concurrency     *ConcurrencyConfig           // 全局和按路由组的并发上限
+	H5              *H5Config                    // 服务端渲染的H5页面，未配置dir不启用
+	JSSDK           *JSSDKConfig                 `mapstructure:"jssdk"` // 公众号网页JS-SDK签名
+	Record          *RecordConfig                // 外部调用录制和重放，用于排查，未配置clients不录制
+	Warmup          *WarmupConfig                // 启动预热时请求的路由
+	VHosts          []*VHostConfig               `mapstructure:"vhosts"` // 按域名或监听地址拆分路由组
+	Coalesce        *CoalesceConfig              // 合并同时到达的相同GET请求
 }
 
 type Handler struct {
-	service *service.Service
-	cdn     string
-	wechat  wechat.FullAPI
+	service  service.Interface
+	cdn      *cdn.CDN
+	wechat   wechat.FullAPI
+	chaosOn  bool
+	chaos    atomic.Value // map[string]*model.ChaosRule
+	canary   *canary
+	envelope []string
+	strict   []string
+	kf       *wechat.Router
+	oauth    map[string]*oauthApp
+	payment  payment.Registry
+	appid    string
+	linkAge  int64
+	geo      *geoip.DB
+	geoRules []*GeoRule
+
+	upgrade        atomic.Value // map[string]*model.AppVersion
+	upgradeDefault map[string]*model.AppVersion
+
+	apiKeys     atomic.Value // map[string]*model.APIKey
+	meterRoutes map[string]int64
+
+	scheduler *scheduler
+
+	bodyLimit  int64
+	bodyRoutes map[string]int64
+
+	captchaRoutes map[string]*CaptchaRoute
+
+	coalesce       *singleflight.Group
+	coalesceRoutes map[string]*CoalesceRoute
+
+	deadlineMax     time.Duration
+	deadlineDefault time.Duration
+
+	concurrency *concurrency
+
+	h5    *h5.Renderer
+	h5Cfg *H5Config
+
+	jssdk        *wechat.JSSDK
+	jssdkDomains map[string]bool
+
+	recordStaging map[string]*url.URL
+	replay        *http.Client
+
+	public     *gin.Engine
+	listeners  []*Listener
+	deprecated map[string]*Deprecation
+
+	internalMTLS bool
+	identities   []*server.Identity
 }
 
-func Initialize(cfg *Config, srv *service.Service) *gin.Engine {
+// Initialize 返回公网和内部两个engine及单独监听的虚拟主机，未配置handler.internal时内部engine为nil
+func Initialize(cfg *Config, srv service.Interface) (*gin.Engine, *gin.Engine, []*Listener) {
 	s := &Handler{
-		service: srv,
-		cdn:     cfg.Cdn,
+		service:  srv,
+		envelope: cfg.Envelope,
+		strict:   cfg.Strict,
+		appid:    cfg.Wechat.Appid,
+		linkAge:  cfg.ShortLinkMaxAge,
 	}
 	s.wechat = wechat.NewFullAPI(
 		cfg.Wechat.Appid,
 		cfg.Wechat.Secret,
-		logger.NewHttpClient(8*time.Second),
-		srv.WechatToken)
+		logger.NewClient("wechat", 8*time.Second),
+		srv.TokenStore(),
+		model.KeyWechatToken,
+		wechat.WithQuota(srv.WechatQuota(cfg.Wechat.Appid, cfg.Wechat.Quota)))
+	s.initKf(cfg.Wechat.Token, cfg.Wechat.Welcome)
+	s.initOAuth(cfg.OAuth)
+	s.initGeo(cfg.Geo)
+	for k, v := range cfg.Upgrade {
+		v.Platform = k
+	}
+	s.upgradeDefault = cfg.Upgrade
+	go s.watchAppVersions()
+	s.initMeter(cfg.Meter)
+	s.initBody(cfg.Body)
+	s.initDeprecated(cfg.Deprecated)
+	s.initCaptcha(cfg.Captcha)
+	s.initCoalesce(cfg.Coalesce)
+	s.initDeadline(cfg.Deadline, cfg.Server.WriteTimeout)
+	initAccessLog(cfg.AccessLog)
+	go s.watchAPIKeys()
+	cdnURL, err := cdn.New(cfg.Cdn, cfg.CdnAuth)
+	if err != nil {
+		log.Fatal("cdn.New error: ", err)
+	}
+	s.cdn = cdnURL
+	s.initJSSDK(cfg.JSSDK)
+	s.initH5(cfg.H5)
+	s.initRecord(cfg.Record)
+	pay, err := payment.New(&cfg.Payment, logger.NewClient("payment", 10*time.Second))
+	if err != nil {
+		log.Fatal("payment.New error: ", err)
+	}
+	s.payment = pay
+	if cfg.Concurrency != nil && (cfg.Concurrency.Global > 0 || len(cfg.Concurrency.Groups) > 0) {
+		s.concurrency = newConcurrency(cfg.Concurrency)
+	}
+	if cfg.Priority != nil && cfg.Priority.MaxInflight > 0 {
+		s.scheduler = newScheduler(cfg.Priority)
+	}
+	if cfg.Canary != nil && (cfg.Canary.Percent > 0 || cfg.Canary.Header != "" || len(cfg.Canary.Users) > 0) {
+		s.canary = newCanary(cfg.Canary)
+	}
+	if cfg.Chaos && gin.Mode() != gin.ReleaseMode {
+		s.chaosOn = true
+		go s.watchChaosRules()
+	}
+	r := newEngine(&cfg.Server)
+	s.public = r
+	var internal *gin.Engine
+	if cfg.Internal != nil && cfg.Internal.Addr != "" {
+		s.internalMTLS = cfg.Internal.TLS != nil && cfg.Internal.TLS.ClientCA != ""
+		s.identities = cfg.Internal.Identities
+		internal = gin.New()
+		s.registerInternal(internal)
+	} else {
+		r.GET("metrics", gin.WrapH(metrics.Handler()))
+		r.GET("ready", Ready)
+	}
+	listeners := s.mount(cfg, r)
+	s.initWarmup(cfg.Warmup)
+	return r, internal, listeners
+}
+
+func newEngine(cfg *server.Config) *gin.Engine {
 	r := gin.New()
-	s.register(r)
+	r.ContextWithFallback = true // c作为context时带上请求的截止时间和取消
+	r.MaxMultipartMemory = cfg.MultipartMemory()
 	return r
 }
 
-//alias short for HttpStatusCode
+// alias short for HttpStatusCode
 const (
-	OK                 = http.StatusOK                    //200: 成功
-	InvalidParam       = http.StatusBadRequest            //400: 参数错误
-	Unauthorized       = http.StatusUnauthorized          //401: 登录失效
-	Forbidden          = http.StatusForbidden             //403: 禁止操作
-	NotFound           = http.StatusNotFound              //404: 目标不存在
-	Conflict           = http.StatusConflict              //409: 数据已存在
-	OverSize           = http.StatusRequestEntityTooLarge //413: 提交内容过大
-	UnsupportedType    = http.StatusUnsupportedMediaType  //415: 错误的文件类型
-	Unprocessable      = http.StatusUnprocessableEntity   //422: 数据格式错误或已过期
-	Locked             = http.StatusLocked                //423: 资源被锁定
-	RateLimit          = http.StatusTooManyRequests       //429: 请求频率限制
-	ServerError        = http.StatusInternalServerError   //500: 服务端通用错误
-	WrongResponse      = http.StatusBadGateway            //502: 响应错误
-	ServiceUnavailable = http.StatusServiceUnavailable    //503: 服务不可用
-	GatewayTimeout     = http.StatusGatewayTimeout        //504: 请求错误
+	OK                   = http.StatusOK                    //200: 成功
+	NotModified          = http.StatusNotModified           //304: 内容未变更
+	InvalidParam         = http.StatusBadRequest            //400: 参数错误
+	Unauthorized         = http.StatusUnauthorized          //401: 登录失效
+	Forbidden            = http.StatusForbidden             //403: 禁止操作
+	NotFound             = http.StatusNotFound              //404: 目标不存在
+	MethodNotAllowed     = http.StatusMethodNotAllowed      //405: 请求方法错误
+	Conflict             = http.StatusConflict              //409: 数据已存在
+	PreconditionFailed   = http.StatusPreconditionFailed    //412: 数据版本已变更
+	PreconditionRequired = http.StatusPreconditionRequired  //428: 需要完成验证码
+	OverSize             = http.StatusRequestEntityTooLarge //413: 提交内容过大
+	UnsupportedType      = http.StatusUnsupportedMediaType  //415: 错误的文件类型
+	Unprocessable        = http.StatusUnprocessableEntity   //422: 数据格式错误或已过期
+	Locked               = http.StatusLocked                //423: 资源被锁定
+	UpgradeRequired      = http.StatusUpgradeRequired       //426: 客户端版本过低
+	RateLimit            = http.StatusTooManyRequests       //429: 请求频率限制
+	ServerError          = http.StatusInternalServerError   //500: 服务端通用错误
+	WrongResponse        = http.StatusBadGateway            //502: 响应错误
+	ClientClosed         = 499                              //499: 客户端已断开(nginx约定)
+	ServiceUnavailable   = http.StatusServiceUnavailable    //503: 服务不可用
+	GatewayTimeout       = http.StatusGatewayTimeout        //504: 请求错误
 )
 
 type RespErr struct {
-	Msg    string `json:"msg"`
-	Detail string `json:"detail,omitempty"`
+	Msg     string `json:"msg"`
+	Detail  string `json:"detail,omitempty"`
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 var Empty = struct{}{}
@@ -79,21 +247,45 @@ func RespWithMsg(code int, msg string) (int, *RespErr) {
 
 func RespWithErr(err error) (int, *RespErr) {
 	code, msg, detail := ServerError, "系统繁忙", ""
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return GatewayTimeout, &RespErr{Msg: "请求超时", Detail: "DEADLINE"}
+	case errors.Is(err, context.Canceled):
+		return ClientClosed, &RespErr{Msg: "请求已取消", Detail: "CANCELED"}
+	}
 	e := reflect.TypeOf(err).String()
 	switch e {
-	case "validator.ValidationErrors":
+	case "validator.ValidationErrors", "handler.StrictError", "*listquery.Error":
 		code = InvalidParam
 		msg = "参数错误"
 		detail = err.Error()
+	case "*model.BizError":
+		e := err.(*model.BizError)
+		return e.Status, &RespErr{Msg: e.Msg, Detail: e.Code}
+	case "*wechat.QuotaError":
+		return model.ErrWechatLimit.Status, &RespErr{Msg: model.ErrWechatLimit.Msg, Detail: model.ErrWechatLimit.Code}
+	case "*wechat.APIError":
+		e := err.(*wechat.APIError)
+		if b, ok := model.WechatErrs[e.Code]; ok {
+			return b.Status, &RespErr{Msg: b.Msg, Detail: b.Code}
+		}
+		code = WrongResponse
+		detail = "WECHAT_" + strconv.Itoa(e.Code)
 	case "proto.RedisError":
 		detail = "REDIS"
 	case "nsq.ErrProtocol":
 		detail = "NSQ"
+	case "kafka.Error", "kafka.WriteErrors":
+		detail = "KAFKA"
 	case "*errors.errorString":
 		detail = "ERRORS"
 	case "*url.Error":
 		code = GatewayTimeout
 		detail = "REQUEST"
+	case "*http.MaxBytesError":
+		code = OverSize
+		msg = "提交内容过大"
+		detail = err.Error()
 	default:
 		if strings.HasPrefix(e, "*json.") {
 			code = WrongResponse
@@ -103,6 +295,30 @@ func RespWithErr(err error) (int, *RespErr) {
 	return code, &RespErr{Msg: msg, Detail: detail}
 }
 
+// NoRoute 未匹配的路由，info日志用于发现客户端拼错的路径
+func NoRoute(c *gin.Context) {
+	logger.FromContext(c).Info("route miss", c.Request.Method+" "+c.Request.URL.Path, c.GetHeader("Referer"))
+	msg := "接口不存在"
+	if isEnglish(c) {
+		msg = "Not Found"
+	}
+	c.AbortWithStatusJSON(NotFound, &RespErr{Msg: msg, Detail: "ROUTE", TraceID: c.GetString("trace_id")})
+}
+
+// NoMethod 路由存在但请求方法不匹配，Allow头由gin设置
+func NoMethod(c *gin.Context) {
+	logger.FromContext(c).Info("method miss", c.Request.Method+" "+c.Request.URL.Path, c.GetHeader("Referer"))
+	msg := "不支持的请求方法"
+	if isEnglish(c) {
+		msg = "Method Not Allowed"
+	}
+	c.AbortWithStatusJSON(MethodNotAllowed, &RespErr{Msg: msg, Detail: "METHOD", TraceID: c.GetString("trace_id")})
+}
+
+func isEnglish(c *gin.Context) bool {
+	return strings.HasPrefix(strings.ToLower(c.GetHeader("Accept-Language")), "en")
+}
+
 func Recover(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -120,8 +336,9 @@ func Recover(c *gin.Context) {
 
 func Cors(c *gin.Context) {
 	c.Header("Access-Control-Allow-Origin", "*")
-	c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Trace-Id")
-	c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
+	c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Trace-Id, X-Request-Timeout, If-Match, X-App-Version, X-App-Platform, X-Api-Key")
+	c.Header("Access-Control-Expose-Headers", "ETag, X-Quota-Limit, X-Quota-Remaining, X-Quota-Reset")
+	c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
 	if c.Request.Method == http.MethodOptions {
 		c.AbortWithStatus(http.StatusNoContent)
 		return
@@ -129,16 +346,36 @@ func Cors(c *gin.Context) {
 	c.Next()
 }
 
+// unmatchedRoute 未匹配路由的请求统一记为该模板，避免扫描等请求产生大量日志key和指标序列
+const unmatchedRoute = "/*"
+
+var (
+	httpRequests = metrics.NewCounter("http_requests_total", "按路由模板统计的请求数", "route", "status")
+	httpDuration = metrics.NewHistogram("http_request_duration_seconds", "按路由模板统计的请求耗时", nil, "route")
+	httpErrors   = metrics.NewCounter("http_errors_total", "按路由模板和业务错误码统计的失败响应数", "route", "code")
+)
+
+// SetContext 写入trace_id和路由模板，日志v1、指标标签、计量和限流均按method+路由模板(如GET/wechat/order/:no)区分
 func SetContext(c *gin.Context) {
 	tid := c.GetHeader("X-Trace-Id")
 	if tid == "" {
 		tid = base64.RawURLEncoding.EncodeToString(uuid.NewV4().Bytes())
 	}
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
 	c.Set("trace_id", tid)
-	c.Set("v1", c.Request.Method+c.Request.URL.Path)
+	c.Set("route", c.Request.Method+route)
+	c.Set("v1", c.Request.Method+route)
 	c.Next()
 }
 
+// routeKey 当前请求的method+路由模板，需在SetContext之后使用
+func routeKey(c *gin.Context) string {
+	return c.GetString("route")
+}
+
 type BodyLogWriter struct {
 	gin.ResponseWriter
 	body *bytes.Buffer
@@ -149,32 +386,79 @@ func (w *BodyLogWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// accessBufMaxCap 超过该容量的缓冲不放回池中，避免个别大请求长期占用内存
+const accessBufMaxCap = 64 << 10
+
+// accessBufs 请求体和响应体的缓冲，按常见的json大小预分配4KB
+var accessBufs = sync.Pool{New: func() any {
+	return bytes.NewBuffer(make([]byte, 0, 4<<10))
+}}
+
+func putAccessBuf(b *bytes.Buffer) {
+	if b.Cap() <= accessBufMaxCap {
+		b.Reset()
+		accessBufs.Put(b)
+	}
+}
+
+// AccessLog 请求体和响应体的缓冲在写完日志后放回池中，处理函数不能在返回后继续读取c.Request.Body
 func AccessLog(c *gin.Context) {
 	begin := time.Now()
-	body, _ := io.ReadAll(c.Request.Body)
+	reqBuf := accessBufs.Get().(*bytes.Buffer)
+	defer putAccessBuf(reqBuf)
+	if n := c.Request.ContentLength; n > 0 && n <= accessBufMaxCap {
+		reqBuf.Grow(int(n))
+	}
+	_, err := reqBuf.ReadFrom(c.Request.Body)
+	var e *http.MaxBytesError
+	if errors.As(err, &e) { // MaxBodySize限制的分块请求在此读取时超出
+		c.AbortWithStatusJSON(RespWithErr(e))
+		return
+	}
+	body := reqBuf.Bytes()
 	if len(body) > 0 {
 		c.Request.Body = io.NopCloser(bytes.NewReader(body))
 	}
 
 	w := &BodyLogWriter{
 		ResponseWriter: c.Writer,
-		body:           bytes.NewBuffer(nil),
+		body:           accessBufs.Get().(*bytes.Buffer),
 	}
 	c.Writer = w
+	defer func() { // panic时Recover在外层写响应，需先换回原writer再放回缓冲
+		c.Writer = w.ResponseWriter
+		putAccessBuf(w.body)
+	}()
 
 	c.Next()
+	live.Observe(time.Since(begin), w.Status() >= 500)
+	httpRequests.Inc(routeKey(c), strconv.Itoa(w.Status()))
+	httpDuration.Observe(time.Since(begin).Seconds(), routeKey(c))
+	errCode := c.GetString(errCodeKey)
+	if errCode != "" {
+		httpErrors.Inc(routeKey(c), errCode)
+	}
+	sinkAccess(c, begin, w.Status(), len(body), w.body.Len())
 
-	logger.FromContext(c).Trace("access",
-		gin.H{
-			"query":     logger.SpreadMaps(c.Request.URL.Query()),
-			"headers":   logger.SpreadMaps(c.Request.Header),
-			"body":      logger.Compress(body),
-			"client_ip": c.ClientIP(),
-		},
-		gin.H{
-			"body":   logger.Compress(w.body.Bytes()),
-			"status": w.Status(),
-		}, begin)
+	input := gin.H{
+		"path":      c.Request.URL.Path,
+		"query":     logger.SpreadMaps(c.Request.URL.Query()),
+		"headers":   logger.SpreadMaps(c.Request.Header),
+		"body":      logger.Compress(body),
+		"client_ip": c.ClientIP(),
+		"client":    GetClient(c),
+	}
+	if geo, ok := c.Get("geo"); ok {
+		input["geo"] = geo
+	}
+	output := gin.H{
+		"body":   logger.Compress(w.body.Bytes()),
+		"status": w.Status(),
+	}
+	if errCode != "" {
+		output["err_code"] = errCode
+	}
+	logger.FromContext(c).Trace("access", input, output, begin)
 }
 
 func (h *Handler) AuthCheck(c *gin.Context) {
@@ -183,6 +467,10 @@ func (h *Handler) AuthCheck(c *gin.Context) {
 		c.AbortWithStatusJSON(RespWithMsg(Unauthorized, "Authorization Missing"))
 		return
 	}
+	if service.IsGuestToken(token) {
+		c.AbortWithStatusJSON(Unauthorized, &RespErr{Msg: "请先登录", Detail: "LOGIN_REQUIRED"})
+		return
+	}
 	user, err := h.service.GetUserToken(c, token)
 	if err != nil {
 		logger.FromContext(c).Error("service.GetUserToken error", token, err)
@@ -193,8 +481,17 @@ func (h *Handler) AuthCheck(c *gin.Context) {
 		c.AbortWithStatusJSON(RespWithMsg(Unauthorized, "Authorization Expired"))
 		return
 	}
+	if !h.checkStatus(c, user.ID) {
+		return
+	}
 	c.Set("user", user)
 	c.Set("v2", user.Openid)
 	c.Set("v3", user.Unionid)
+	c.Set(reqctx.Key, &reqctx.Info{
+		TraceID: c.GetString("trace_id"),
+		UserID:  user.ID,
+		Openid:  user.Openid,
+		Unionid: user.Unionid,
+	})
 	c.Next()
 }
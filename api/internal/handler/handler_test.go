@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"project/api/internal/proto"
+	"project/api/internal/service"
+	"project/api/internal/service/mock"
+	"project/model"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestHandler service使用mockgen生成的MockInterface，handler只依赖service.Interface
+func newTestHandler(t *testing.T) (*Handler, *mock.MockInterface) {
+	gin.SetMode(gin.TestMode)
+	m := mock.NewMockInterface(gomock.NewController(t))
+	return &Handler{service: m}, m
+}
+
+func serve(r *gin.Engine, method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGuestSession(t *testing.T) {
+	h, m := newTestHandler(t)
+	r := gin.New()
+	r.POST("/guest", SetContext, h.GuestSession)
+
+	m.EXPECT().SetGuestToken(gomock.Any(), "device-1", gomock.Any(), "").Return("g.token", nil)
+	w := serve(r, http.MethodPost, "/guest", `{"device_id":"device-1"}`, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var resp proto.GuestSessionResp
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Token != "g.token" ||
+		resp.ExpiresIn != int(service.GuestTTL.Seconds()) {
+		t.Fatalf("unexpected response %s", w.Body)
+	}
+
+	// 请求头的游客凭证交给service校验，登录凭证忽略
+	m.EXPECT().SetGuestToken(gomock.Any(), "device-1", gomock.Any(), "g.token").Return("g.token", nil)
+	if w = serve(r, http.MethodPost, "/guest", `{"device_id":"device-1"}`,
+		map[string]string{"Authorization": "g.token"}); w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	m.EXPECT().SetGuestToken(gomock.Any(), "device-1", gomock.Any(), "").Return("g.other", nil)
+	if w = serve(r, http.MethodPost, "/guest", `{"device_id":"device-1"}`,
+		map[string]string{"Authorization": "USERTOKEN"}); w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+
+	m.EXPECT().SetGuestToken(gomock.Any(), "device-2", gomock.Any(), "").Return("", model.ErrGuestLimit)
+	w = serve(r, http.MethodPost, "/guest", `{"device_id":"device-2"}`, nil)
+	if w.Code != http.StatusTooManyRequests || !strings.Contains(w.Body.String(), model.ErrGuestLimit.Code) {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+
+	// 参数校验失败时不调用service
+	w = serve(r, http.MethodPost, "/guest", `{}`, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestSessionCheckGuest(t *testing.T) {
+	h, m := newTestHandler(t)
+	r := gin.New()
+	r.GET("/cart", SetContext, h.SessionCheck, func(c *gin.Context) {
+		c.String(http.StatusOK, cartOwner(c))
+	})
+
+	guest := &proto.GuestToken{ID: "guest-1", DeviceID: "device-1"}
+	m.EXPECT().GetGuestToken(gomock.Any(), "g.valid").Return(guest, nil)
+	w := serve(r, http.MethodGet, "/cart", "", map[string]string{"Authorization": "g.valid"})
+	if w.Code != http.StatusOK || w.Body.String() != service.GuestCartOwner(guest.ID) {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+
+	m.EXPECT().GetGuestToken(gomock.Any(), "g.expired").Return(nil, nil)
+	w = serve(r, http.MethodGet, "/cart", "", map[string]string{"Authorization": "g.expired"})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestSyncBannersFull(t *testing.T) {
+	h, m := newTestHandler(t)
+	r := gin.New()
+	r.GET("/banners", SetContext, h.GetBanners)
+	sync := func(query string) *httptest.ResponseRecorder {
+		w := serve(r, http.MethodGet, "/banners?city=440100&"+query, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body)
+		}
+		return w
+	}
+	recent := time.Now().AddDate(0, 0, -1).Truncate(time.Second)
+	stale := time.Now().AddDate(0, 0, -model.TombstoneDays-1).Truncate(time.Second)
+
+	m.EXPECT().SyncBannersByCity(gomock.Any(), "440100", recent, 7).Return(nil, recent, 0, false, nil)
+	if w := sync("updated_since=" + strconv.FormatInt(recent.Unix(), 10) + "&since_id=7"); strings.Contains(w.Body.String(), `"full_sync":true`) {
+		t.Fatalf("unexpected full sync %s", w.Body)
+	}
+
+	// 游标早于墓碑保留期，从头同步并要求客户端重建
+	m.EXPECT().SyncBannersByCity(gomock.Any(), "440100", time.Unix(0, 0), 0).Return(nil, stale, 9, true, nil)
+	if w := sync("updated_since=" + strconv.FormatInt(stale.Unix(), 10) + "&since_id=7"); !strings.Contains(w.Body.String(), `"full_sync":true`) {
+		t.Fatalf("expect full sync %s", w.Body)
+	}
+
+	// 全量重建的后续分页按游标继续
+	m.EXPECT().SyncBannersByCity(gomock.Any(), "440100", stale, 9).Return(nil, recent, 0, false, nil)
+	if w := sync("updated_since=" + strconv.FormatInt(stale.Unix(), 10) + "&since_id=9&full_sync=true"); strings.Contains(w.Body.String(), `"full_sync":true`) {
+		t.Fatalf("unexpected full sync %s", w.Body)
+	}
+}
+
+// BenchmarkAccessLog 请求体720B、响应体约2.8KB，日志输出未设置时只统计中间件本身的开销
+func BenchmarkAccessLog(b *testing.B) {
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetContext, AccessLog)
+	resp := []byte(`{"code":0,"data":[` + strings.Repeat(`{"id":1001,"name":"name","price":990},`, 73) + `{}]}`)
+	r.POST("/api/v1/wx/order", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", resp)
+	})
+	body := []byte(`{"items":[` + strings.Repeat(`{"sku_id":1001,"qty":2},`, 29) + `{}],"remark":"x"}`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wx/order?from=bench", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
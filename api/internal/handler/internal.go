@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http/pprof"
+	"project/pkg/metrics"
+	"project/pkg/server"
+)
+
+// 内部路由：指标、pprof、健康检查和运维接口，配置handler.internal后单独监听，不经过公网入口
+
+type InternalConfig struct {
+	Addr       string             // 如127.0.0.1:8001或unix:/run/api.sock
+	TLS        *server.TLS        // 配置clientCa后启用mTLS
+	Identities []*server.Identity // 按客户端证书SAN映射调用方服务和可访问的路由，为空时接受任意有效证书
+}
+
+func (h *Handler) registerInternal(r *gin.Engine) {
+	r.Use(Recover)
+	r.GET("health", func(c *gin.Context) {
+		c.String(OK, "ok")
+	})
+	r.GET("ready", Ready)
+	if h.internalMTLS {
+		r.Use(h.ServiceAuth)
+	}
+	r.GET("metrics", gin.WrapH(metrics.Handler()))
+
+	debug := r.Group("debug/pprof")
+	debug.GET("", gin.WrapF(pprof.Index))
+	debug.GET("cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("profile", gin.WrapF(pprof.Profile))
+	debug.GET("symbol", gin.WrapF(pprof.Symbol))
+	debug.POST("symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("trace", gin.WrapF(pprof.Trace))
+	debug.GET(":name", func(c *gin.Context) {
+		pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+	})
+
+	admin := r.Group("admin")
+	admin.POST("reload", h.Reload)
+	r.GET("internal/routes", h.GetRoutes)
+	r.GET("internal/live", h.LiveStats)
+	r.POST("internal/push", h.Push)
+	r.GET("internal/push/:id", h.PushDeliveries)
+	r.POST("internal/captcha/flag", h.FlagCaptcha)
+	r.POST("internal/presence/heartbeat", h.PresenceHeartbeat)
+	r.POST("internal/presence/leave", h.PresenceLeave)
+	r.GET("internal/presence", h.GetPresence)
+	if h.replay != nil {
+		r.GET("internal/record/:trace", h.GetRecords)
+		r.POST("internal/record/:trace/replay", h.ReplayRecord)
+	}
+}
+
+// ServiceAuth 校验客户端证书并按SAN识别调用方服务，写入上下文service
+func (h *Handler) ServiceAuth(c *gin.Context) {
+	if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+		c.AbortWithStatusJSON(Unauthorized, &RespErr{Msg: "需要客户端证书"})
+		return
+	}
+	if len(h.identities) == 0 {
+		c.Next()
+		return
+	}
+	id := server.Identify(c.Request.TLS, h.identities)
+	if id == nil {
+		c.AbortWithStatusJSON(Forbidden, &RespErr{Msg: "未授权的客户端证书"})
+		return
+	}
+	if !id.Allow(c.Request.URL.Path) {
+		c.AbortWithStatusJSON(Forbidden, &RespErr{Msg: "无权访问", Detail: id.Name})
+		return
+	}
+	c.Set("service", id.Name)
+	c.Next()
+}
+
+// Reload 立即重新加载redis中的客户端版本、合作方密钥和故障注入规则，不必等待轮询
+func (h *Handler) Reload(c *gin.Context) {
+	h.loadAppVersions()
+	h.loadAPIKeys()
+	if h.chaosOn {
+		h.loadChaosRules()
+	}
+	h.OK(c, Empty)
+}
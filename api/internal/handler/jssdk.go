@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/url"
+	"project/api/internal/proto"
+	"project/pkg/logger"
+	"project/pkg/wechat"
+	"time"
+)
+
+// 公众号网页的JS-SDK签名：jsapi_ticket由script的refresh:token刷新到redis，这里只读取并按页面url签名
+
+type JSSDKConfig struct {
+	Appid   string   // 公众号appid，为空不启用
+	Domains []string // 允许签名的页面域名，与公众号后台的JS接口安全域名一致
+	TTL     int      `mapstructure:"ttl"` // 进程内缓存ticket的秒数，默认60
+}
+
+func (h *Handler) initJSSDK(cfg *JSSDKConfig) {
+	if cfg == nil || cfg.Appid == "" {
+		return
+	}
+	h.jssdk = wechat.NewJSSDK(cfg.Appid, h.service.JSAPITicket, time.Duration(cfg.TTL)*time.Second)
+	h.jssdkDomains = make(map[string]bool, len(cfg.Domains))
+	for _, v := range cfg.Domains {
+		h.jssdkDomains[v] = true
+	}
+}
+
+// JSSDKSign 返回wx.config的appId、timestamp、nonceStr、signature，只签名允许的域名下的页面
+func (h *Handler) JSSDKSign(c *gin.Context) {
+	r, ok := BindQuery[proto.JSSDKArgs](c)
+	if !ok {
+		return
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !h.jssdkDomains[u.Hostname()] {
+		h.Fail(c, InvalidParam, "页面域名不允许")
+		return
+	}
+	cfg, err := h.jssdk.Config(c, r.URL)
+	if err != nil {
+		logger.FromContext(c).Error("jssdk.Config error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, cfg)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+	"project/pkg/logger"
+	"project/pkg/wechat"
+)
+
+/*
+小程序客服消息：
+GET  /callback/wechat 配置消息推送时的服务器校验
+POST /callback/wechat 接收消息推送，按消息类型分发到 kfRouter 注册的处理函数
+未配置handler.wechat.token时不注册以上路由：空令牌的签名任何人都可以计算
+*/
+
+func (h *Handler) initKf(token, welcome string) {
+	if token == "" {
+		if welcome != "" {
+			log.Fatal("handler.wechat.welcome requires handler.wechat.token")
+		}
+		return
+	}
+	h.kf = wechat.NewRouter(token)
+	// 进入客服会话时发送欢迎语
+	h.kf.Handle(wechat.EventEnterChat, func(ctx context.Context, msg *wechat.Message) (any, error) {
+		if welcome == "" {
+			return nil, nil
+		}
+		resp, err := h.wechat.SendCustomMessage(ctx, &wechat.CustomMessage{
+			ToUser:  msg.FromUserName,
+			MsgType: wechat.CustomText,
+			Text:    &wechat.CustomTextItem{Content: welcome},
+		})
+		if wechat.Code(err) != 0 {
+			logger.FromContext(ctx).Warn("wechat.SendCustomMessage fail", msg.FromUserName, resp)
+			return nil, nil
+		}
+		return nil, err
+	})
+	// 用户消息转人工客服
+	transfer := func(_ context.Context, msg *wechat.Message) (any, error) {
+		return wechat.TransferCustomerService(msg), nil
+	}
+	h.kf.Handle(wechat.MsgText, transfer)
+	h.kf.Handle(wechat.MsgImage, transfer)
+	h.kf.Handle(wechat.MsgMiniPage, transfer)
+}
+
+func (h *Handler) WechatCallbackVerify(c *gin.Context) {
+	if !h.kf.CheckSignature(c.Query("signature"), c.Query("timestamp"), c.Query("nonce")) {
+		c.AbortWithStatus(Forbidden)
+		return
+	}
+	c.String(OK, c.Query("echostr"))
+}
+
+func (h *Handler) WechatCallback(c *gin.Context) {
+	if !h.kf.CheckSignature(c.Query("signature"), c.Query("timestamp"), c.Query("nonce")) {
+		c.AbortWithStatus(Forbidden)
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatus(InvalidParam)
+		return
+	}
+	msg, reply, err := h.kf.Dispatch(c, body)
+	if err != nil {
+		logger.FromContext(c).Error("wechat.Dispatch error", string(body), err)
+	}
+	if reply == nil || msg == nil {
+		c.String(OK, "success") // 微信要求处理失败也返回success，否则会重试并提示服务故障
+		return
+	}
+	c.JSON(OK, reply)
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+)
+
+// LedgerAccounts 当前用户各资产余额
+func (h *Handler) LedgerAccounts(c *gin.Context) {
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	data, err := h.service.FindLedgerAccounts(c, user.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindLedgerAccounts error", user.ID, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, data)
+}
+
+// LedgerStatement 当前用户资产流水分页
+func (h *Handler) LedgerStatement(c *gin.Context) {
+	r, ok := BindQuery[proto.LedgerStatementArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	total, list, err := h.service.PaginateLedgerEntry(c, user.ID, r.Asset, r.Page, r.Size)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateLedgerEntry error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	if list == nil {
+		list = make([]*model.LedgerEntry, 0)
+	}
+	h.List(c, list, &Meta{
+		Page:  r.Page,
+		Size:  r.Size,
+		Total: total,
+	})
+}
+
+// PointsExchange 积分兑换商品，预扣库存、扣积分、创建订单任一步失败时自动回补
+func (h *Handler) PointsExchange(c *gin.Context) {
+	r, ok := Bind[proto.PointsExchangeArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	orderNo, err := h.service.ExchangePoints(c, user.ID, r.SkuID, r.Quantity)
+	if err != nil {
+		logger.FromContext(c).Error("service.ExchangePoints error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, &proto.PointsExchangeResp{OrderNo: orderNo})
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"project/api/internal/proto"
+	"project/pkg/metrics"
+	"time"
+)
+
+// 进程内实时QPS、错误率和耗时分位数，由AccessLog采样，内部路由以SSE推送给值班人员观察发布
+
+// liveMaxDuration 单次连接的最长推送时间，需小于server.writeTimeout，EventSource断开后按retry自动重连
+const liveMaxDuration = 50 * time.Second
+
+var live = metrics.NewLive()
+
+func (h *Handler) LiveStats(c *gin.Context) {
+	r, ok := BindQuery[proto.LiveArgs](c)
+	if !ok {
+		return
+	}
+	if r.Window == 0 {
+		r.Window = 10
+	}
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	window := time.Duration(r.Window) * time.Second
+	tick := time.NewTicker(time.Duration(r.Interval) * time.Second)
+	defer tick.Stop()
+	deadline := time.After(liveMaxDuration)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // 关闭nginx缓冲
+	_, _ = c.Writer.WriteString("retry: 1000\n\n")
+	c.SSEvent("stats", live.Snapshot(window))
+	c.Writer.Flush()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-deadline:
+			return false
+		case <-tick.C:
+			c.SSEvent("stats", live.Snapshot(window))
+			return true
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+)
+
+// Lottery 活动和奖品，rate为万分比中奖概率
+func (h *Handler) Lottery(c *gin.Context) {
+	r, ok := BindQuery[proto.LotteryArgs](c)
+	if !ok {
+		return
+	}
+	data, err := h.service.FindLottery(c, r.ID)
+	if err != nil {
+		if err != model.ErrLotteryNotFound {
+			logger.FromContext(c).Error("service.FindLottery error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, data)
+}
+
+// LotteryDraw 抽奖，未中奖时prize_id为0
+func (h *Handler) LotteryDraw(c *gin.Context) {
+	r, ok := Bind[proto.LotteryDrawArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	data, err := h.service.DrawLottery(c, user.ID, r.ID)
+	if err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.DrawLottery error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, data)
+}
+
+func (h *Handler) LotteryChances(c *gin.Context) {
+	r, ok := BindQuery[proto.LotteryArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	data, err := h.service.FindLotteryChances(c, user.ID, r.ID)
+	if err != nil {
+		if err != model.ErrLotteryNotFound {
+			logger.FromContext(c).Error("service.FindLotteryChances error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, data)
+}
+
+func (h *Handler) LotteryWins(c *gin.Context) {
+	r, ok := BindQuery[proto.LotteryWinArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	list, err := h.service.FindLotteryWins(c, user.ID, r.LotteryID, r.Page, r.Size)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindLotteryWins error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.List(c, list, nil)
+}
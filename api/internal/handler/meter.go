@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/util/random"
+	"strconv"
+	"time"
+)
+
+// 调用计量，计数写入redis由script每5分钟回写db；超出配额返回429，剩余不足10%时响应头带Warning
+// 合作方(X-Api-Key)按api_key.daily_quota限制全部路由，登录用户按handler.meter.routes限制单个路由
+
+type MeterConfig struct {
+	Routes []*MeterRoute
+}
+
+type MeterRoute struct {
+	Route string // method+path，如 GET/wechat/userinfo
+	Daily int64  // 每个调用方每日上限
+}
+
+func (h *Handler) initMeter(cfg *MeterConfig) {
+	h.meterRoutes = make(map[string]int64)
+	if cfg != nil {
+		for _, v := range cfg.Routes {
+			h.meterRoutes[v.Route] = v.Daily
+		}
+	}
+}
+
+func (h *Handler) loadAPIKeys() error {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Meter", "loadAPIKeys", "")
+	list, err := h.service.AllAPIKeys(ctx)
+	if err != nil {
+		l.Error("service.AllAPIKeys error", nil, err)
+		return err
+	}
+	keys := make(map[string]*model.APIKey, len(list))
+	for _, v := range list {
+		keys[v.Key] = v
+	}
+	h.apiKeys.Store(keys)
+	return nil
+}
+
+func (h *Handler) watchAPIKeys() {
+	h.loadAPIKeys()
+	for range time.Tick(30 * time.Second) {
+		h.loadAPIKeys()
+	}
+}
+
+// APIKeyAuth 合作方接口鉴权，停用的key 30秒内失效
+func (h *Handler) APIKeyAuth(c *gin.Context) {
+	keys, _ := h.apiKeys.Load().(map[string]*model.APIKey)
+	key, ok := keys[c.GetHeader("X-Api-Key")]
+	if !ok {
+		c.AbortWithStatusJSON(RespWithMsg(Unauthorized, "Invalid Api Key"))
+		return
+	}
+	c.Set("apikey", key)
+	c.Set("v2", key.Name)
+	c.Next()
+}
+
+// Meter 需在鉴权之后使用，未识别调用方时不计量
+func (h *Handler) Meter(c *gin.Context) {
+	route := routeKey(c)
+	var subject string
+	var quota int64 // 调用方全部路由的上限
+	if v, ok := c.Get("apikey"); ok {
+		key := v.(*model.APIKey)
+		subject, quota = model.KeySubject(key.ID), key.DailyQuota
+	} else if v, ok := c.Get("user"); ok {
+		subject = model.UserSubject(v.(*proto.UserToken).ID)
+	} else {
+		c.Next()
+		return
+	}
+	now := time.Now()
+	counts, err := h.service.IncrUsage(c, now.Format("20060102"),
+		subject+"|"+route, subject+"|"+model.UsageAllRoutes)
+	if err != nil {
+		logger.FromContext(c).Warn("service.IncrUsage fail", subject, err) // 计量失败不影响请求
+		c.Next()
+		return
+	}
+	limit, used := h.meterRoutes[route], counts[0]
+	if quota > 0 && (limit == 0 || quota-counts[1] < limit-used) {
+		limit, used = quota, counts[1]
+	}
+	if limit == 0 {
+		c.Next()
+		return
+	}
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	reset := strconv.Itoa(int(tomorrow.Sub(now).Seconds()))
+	remain := limit - used
+	if remain < 0 {
+		remain = 0
+	}
+	c.Header("X-Quota-Limit", strconv.FormatInt(limit, 10))
+	c.Header("X-Quota-Remaining", strconv.FormatInt(remain, 10))
+	c.Header("X-Quota-Reset", reset)
+	if used > limit {
+		c.Header("Retry-After", reset)
+		c.AbortWithStatusJSON(RateLimit, &RespErr{Msg: "今日调用次数已用完", Detail: "QUOTA_EXCEEDED"})
+		return
+	}
+	if remain*10 < limit {
+		c.Header("Warning", `199 - "quota nearly exhausted"`)
+	}
+	c.Next()
+}
+
+// HTTPStat 按状态码类别统计当日请求数，供管理后台计算错误率
+func (h *Handler) HTTPStat(c *gin.Context) {
+	c.Next()
+	err := h.service.IncrHTTPStat(c, time.Now().Format("20060102"), c.Writer.Status())
+	if err != nil {
+		logger.FromContext(c).Warn("service.IncrHTTPStat fail", nil, err)
+	}
+}
+
+// PartnerUsage 合作方查询自己的调用量
+func (h *Handler) PartnerUsage(c *gin.Context) {
+	r, ok := BindQuery[proto.UsageArgs](c)
+	if !ok {
+		return
+	}
+	v, _ := c.Get("apikey")
+	key := v.(*model.APIKey)
+	list, err := h.service.FindAPIUsage(c, model.KeySubject(key.ID), r.Begin, r.End)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindAPIUsage error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	if list == nil {
+		list = make([]*model.APIUsage, 0)
+	}
+	h.List(c, list, nil)
+}
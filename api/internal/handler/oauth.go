@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/wechat"
+	"time"
+)
+
+// 微信开放平台登录：网站应用扫码(authorize获取扫码页，回调页提交code和state)、移动应用SDK登录(提交code)
+// 同一开放平台下按unionid与小程序用户为同一身份；登录凭证中的openid为该渠道的，不能用于小程序支付
+
+type OAuthApp struct {
+	Appid    string
+	Secret   string
+	Redirect string // 网站应用授权后跳转的前端页面，配置后登录需校验state；移动应用为空
+}
+
+type oauthApp struct {
+	*OAuthApp
+	api wechat.BasicAPI
+}
+
+func (h *Handler) initOAuth(apps map[string]*OAuthApp) {
+	h.oauth = make(map[string]*oauthApp, len(apps))
+	client := logger.NewClient("wechat", 8*time.Second)
+	for k, v := range apps {
+		if v.Appid == "" {
+			continue
+		}
+		h.oauth[k] = &oauthApp{OAuthApp: v, api: wechat.NewBasicAPI(v.Appid, v.Secret, client)}
+	}
+}
+
+// OAuthAuthorize 网站应用的扫码登录页地址和state
+func (h *Handler) OAuthAuthorize(c *gin.Context) {
+	r, ok := BindQuery[proto.OAuthAuthorizeArgs](c)
+	if !ok {
+		return
+	}
+	app, ok := h.oauth[r.App]
+	if !ok || app.Redirect == "" {
+		h.Fail(c, InvalidParam, "不支持的登录方式")
+		return
+	}
+	state, err := h.service.NewOAuthState(c, r.App)
+	if err != nil {
+		logger.FromContext(c).Error("service.NewOAuthState error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, &proto.OAuthAuthorizeResp{URL: wechat.QRConnectURL(app.Appid, app.Redirect, state), State: state})
+}
+
+func (h *Handler) OAuthLogin(c *gin.Context) {
+	r, ok := Bind[proto.OAuthLoginArgs](c)
+	if !ok {
+		return
+	}
+	app, ok := h.oauth[r.App]
+	if !ok {
+		h.Fail(c, InvalidParam, "不支持的登录方式")
+		return
+	}
+	if app.Redirect != "" {
+		valid, err := h.service.RedeemOAuthState(c, r.App, r.State)
+		if err != nil {
+			logger.FromContext(c).Error("service.RedeemOAuthState error", &r, err)
+			h.Err(c, err)
+			return
+		}
+		if !valid {
+			h.authEvent(c, model.AuthLoginFailed, 0, "", "invalid state")
+			h.Fail(c, Unprocessable, "Invalid Or Expired")
+			return
+		}
+	}
+	resp, err := app.api.OAuthAccessToken(c, r.Code)
+	if errors.Is(err, wechat.ErrInvalidCode) || err == nil && resp.Openid == "" {
+		logger.FromContext(c).Warn("wechat.OAuthAccessToken fail", &r, resp)
+		h.authEvent(c, model.AuthLoginFailed, 0, "", "invalid code")
+		h.Fail(c, Unprocessable, "Invalid Or Expired")
+		return
+	}
+	if err != nil {
+		logger.FromContext(c).Error("wechat.OAuthAccessToken error", &r, err)
+		h.authEvent(c, model.AuthLoginFailed, 0, "", "wechat error")
+		h.Err(c, err)
+		return
+	}
+	c.Set("v2", resp.Openid)
+	c.Set("v3", resp.Unionid)
+	uid, err := h.service.SaveWechatUser(c, &model.UserWechat{
+		Appid:   app.Appid,
+		Openid:  resp.Openid,
+		Unionid: resp.Unionid,
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.SaveWechatUser error", r.App, err)
+		h.Err(c, err)
+		return
+	}
+	h.issueLogin(c, &proto.UserToken{ID: uid, Openid: resp.Openid, Unionid: resp.Unionid}, r.DeviceID, r.GuestToken)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"project/api/internal/proto"
+	"project/pkg/logger"
+	"project/pkg/util/files"
+	"project/pkg/wechat"
+	"strings"
+)
+
+// OCRIDCard 实名认证流程中识别身份证正反面
+func (h *Handler) OCRIDCard(c *gin.Context) {
+	f, err := c.FormFile("img")
+	if err != nil {
+		h.Fail(c, InvalidParam, "")
+		return
+	}
+	if f.Size > 10<<20 {
+		h.Fail(c, OverSize, "图片限制10M以内")
+		return
+	}
+	file, _ := f.Open()
+	defer file.Close()
+	b, _ := io.ReadAll(file)
+	if ext, ok := files.CheckImage(b); !ok || ext == "gif" {
+		h.Fail(c, UnsupportedType, "无效的图片类型，仅支持jpg/png格式")
+		return
+	}
+	resp, err := h.wechat.OCRIDCard(c, b)
+	switch {
+	case errors.Is(err, wechat.ErrImage):
+		h.Fail(c, Unprocessable, "未识别到身份证，请重新拍摄")
+		return
+	case errors.Is(err, wechat.ErrQuota):
+		logger.FromContext(c).Warn("wechat.OCRIDCard quota", nil, resp)
+		h.Fail(c, ServiceUnavailable, "识别服务繁忙，请稍后再试")
+		return
+	case err != nil:
+		logger.FromContext(c).Error("wechat.OCRIDCard error", nil, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, &proto.IDCardResp{
+		Side:        strings.ToLower(resp.Type),
+		Name:        resp.Name,
+		IDNumber:    resp.ID,
+		Gender:      resp.Gender,
+		Nationality: resp.Nationality,
+		Addr:        resp.Addr,
+		ValidDate:   resp.ValidDate,
+	})
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+)
+
+// OrderCancel 用户取消待支付订单
+func (h *Handler) OrderCancel(c *gin.Context) {
+	r, ok := Bind[proto.OrderCancelArgs](c)
+	if !ok {
+		return
+	}
+	order, ok := h.userOrder(c, r.OrderNo)
+	if !ok {
+		return
+	}
+	ok, err := h.service.CancelOrder(c, order, r.Reason)
+	if err != nil {
+		logger.FromContext(c).Error("service.CancelOrder error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	if !ok {
+		h.Fail(c, Conflict, "订单已支付或已关闭")
+		return
+	}
+	h.OK(c, Empty)
+}
+
+// OrderComplete 用户确认收货
+func (h *Handler) OrderComplete(c *gin.Context) {
+	r, ok := Bind[proto.OrderCompleteArgs](c)
+	if !ok {
+		return
+	}
+	order, ok := h.userOrder(c, r.OrderNo)
+	if !ok {
+		return
+	}
+	ok, err := h.service.CompleteOrder(c, order)
+	if err != nil {
+		logger.FromContext(c).Error("service.CompleteOrder error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	if !ok {
+		h.Fail(c, Conflict, "订单未发货或已完成")
+		return
+	}
+	h.OK(c, Empty)
+}
+
+// userOrder 查询当前用户的订单，不存在时已写入响应
+func (h *Handler) userOrder(c *gin.Context, orderNo string) (*model.Order, bool) {
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	order, err := h.service.FindOrderByNo(c, orderNo)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindOrderByNo error", orderNo, err)
+		h.Err(c, err)
+		return nil, false
+	}
+	if order.ID == 0 || order.UserID != user.ID {
+		h.Fail(c, NotFound, "订单不存在")
+		return nil, false
+	}
+	return order, true
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/payment"
+)
+
+// OrderPay 对待支付订单发起支付，返回客户端调起支付所需参数
+func (h *Handler) OrderPay(c *gin.Context) {
+	r, ok := Bind[proto.OrderPayArgs](c)
+	if !ok {
+		return
+	}
+	p, err := h.payment.Get(r.Provider)
+	if err != nil {
+		h.Fail(c, InvalidParam, "不支持的支付方式")
+		return
+	}
+	order, ok := h.userOrder(c, r.OrderNo)
+	if !ok {
+		return
+	}
+	ok, err = h.service.SetOrderProvider(c, order, p.Name(), r.CouponID)
+	if err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.SetOrderProvider error", &r, err)
+		}
+		h.Err(c, err)
+		return
+	}
+	if !ok {
+		h.Fail(c, Conflict, "订单已支付或已关闭")
+		return
+	}
+	if err = h.service.ScheduleOrderTimeout(c, order.OrderNo); err != nil { // 重复发起支付时多次投递，到期按状态判断
+		logger.FromContext(c).Warn("service.ScheduleOrderTimeout fail", order.OrderNo, err)
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	resp, err := p.CreateOrder(c, &payment.OrderArgs{
+		OrderNo:   order.OrderNo,
+		Subject:   order.Subject,
+		Amount:    order.Amount,
+		Scene:     r.Scene,
+		Openid:    user.Openid,
+		ClientIP:  c.ClientIP(),
+		ReturnURL: r.ReturnURL,
+	})
+	if err == payment.ErrScene {
+		h.Fail(c, InvalidParam, "该支付方式不支持当前场景")
+		return
+	}
+	if err != nil {
+		logger.FromContext(c).Error("payment.CreateOrder error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.service.Track(c, user.ID, &model.TrackOrderCreated{
+		OrderNo:  order.OrderNo,
+		Amount:   order.Amount,
+		Provider: p.Name(),
+		Scene:    r.Scene,
+	})
+	h.OK(c, resp)
+}
+
+// PayNotify 支付渠道回调，按路径中的渠道名验签，响应格式由渠道决定
+func (h *Handler) PayNotify(c *gin.Context) {
+	p, err := h.payment.Get(c.Param("provider"))
+	if err != nil {
+		c.AbortWithStatus(NotFound)
+		return
+	}
+	cb, err := p.VerifyCallback(c.Request)
+	if err != nil {
+		logger.FromContext(c).Warn("payment.VerifyCallback fail", p.Name(), err)
+		p.Reply(c.Writer, err)
+		return
+	}
+	if cb == nil {
+		p.Reply(c.Writer, nil)
+		return
+	}
+	switch cb.Type {
+	case payment.CallbackPaid:
+		var ok bool
+		ok, err = h.service.PayOrder(c, cb.OrderNo, cb.TradeNo, cb.Amount, cb.Time)
+		if err == nil && !ok {
+			logger.FromContext(c).Warn("service.PayOrder mismatch", cb, nil) // 订单不存在或金额不一致，由对账任务告警
+		}
+	case payment.CallbackRefunded, payment.CallbackRefundNG:
+		err = h.service.FinishRefund(c, cb.RefundNo, cb.RefundID, cb.Type == payment.CallbackRefunded, cb.Time)
+	}
+	if err != nil {
+		logger.FromContext(c).Error("service.FinishPayment error", cb, err)
+	}
+	p.Reply(c.Writer, err)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+	"strings"
+)
+
+// 在线状态：WebSocket网关建立连接后按TTL/2以内的间隔批量上报心跳，连接断开时上报leave，内部路由调用
+
+// PresenceHeartbeat redis错误时整批失败，网关下次心跳时重试
+func (h *Handler) PresenceHeartbeat(c *gin.Context) {
+	r, ok := Bind[proto.PresenceHeartbeatArgs](c)
+	if !ok {
+		return
+	}
+	resp := &proto.PresenceHeartbeatResp{Joined: []int{}}
+	for _, v := range r.List {
+		joined, err := h.service.PresenceHeartbeat(c, v.UserID, v.ConnID)
+		if err != nil {
+			logger.FromContext(c).Error("service.PresenceHeartbeat error", v, err)
+			h.Err(c, err)
+			return
+		}
+		if joined {
+			resp.Joined = append(resp.Joined, v.UserID)
+		}
+	}
+	h.OK(c, resp)
+}
+
+func (h *Handler) PresenceLeave(c *gin.Context) {
+	r, ok := Bind[proto.PresenceConn](c)
+	if !ok {
+		return
+	}
+	if err := h.service.PresenceLeave(c, r.UserID, r.ConnID); err != nil {
+		logger.FromContext(c).Error("service.PresenceLeave error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, Empty)
+}
+
+// GetPresence 批量查询在线状态和离线用户的最后在线时间
+func (h *Handler) GetPresence(c *gin.Context) {
+	r, ok := BindQuery[proto.PresenceArgs](c)
+	if !ok {
+		return
+	}
+	parts := strings.Split(r.UserIDs, ",")
+	if len(parts) > 200 {
+		h.Fail(c, InvalidParam, "user_ids最多200个")
+		return
+	}
+	uids := make([]int, 0, len(parts))
+	for _, v := range parts {
+		id, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || id <= 0 {
+			h.Fail(c, InvalidParam, "user_ids格式错误")
+			return
+		}
+		uids = append(uids, id)
+	}
+	presence, err := h.service.GetPresence(c, uids)
+	if err != nil {
+		logger.FromContext(c).Error("service.GetPresence error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	list := make([]*model.UserPresence, 0, len(uids))
+	for _, uid := range uids {
+		list = append(list, presence[uid])
+	}
+	h.List(c, list, nil)
+}
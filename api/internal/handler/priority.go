@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/pkg/logger"
+	"project/pkg/metrics"
+	"sync"
+	"time"
+)
+
+// 请求优先级调度，按路由组标记优先级；处理中的请求接近上限时先拒绝低优先级，保护登录、支付等关键路径
+// critical可使用全部容量，interactive最多90%并在满载时排队等待，background、batch分别在70%、50%后直接拒绝
+
+const (
+	PriorityCritical    = iota // 登录、下单支付
+	PriorityInteractive        // 用户交互请求
+	PriorityBackground         // 客户端后台刷新、预加载
+	PriorityBatch              // 合作方批量调用
+)
+
+var priorityNames = []string{"critical", "interactive", "background", "batch"}
+
+// priorityShare 各优先级可使用的容量比例
+var priorityShare = []float64{1, 0.9, 0.7, 0.5}
+
+type PriorityConfig struct {
+	MaxInflight  int `mapstructure:"maxInflight"`  // 同时处理的请求上限，0不启用
+	QueueTimeout int `mapstructure:"queueTimeout"` // 排队等待毫秒，超时返回503
+}
+
+var (
+	priorityInflight = metrics.NewGauge("priority_inflight", "处理中的请求数")
+	priorityRejected = metrics.NewCounter("priority_rejected_total", "按优先级统计的拒绝请求数", "priority")
+)
+
+type scheduler struct {
+	mu       sync.Mutex
+	inflight int
+	limits   []int
+	waiters  [][]chan struct{} // 按优先级排队，先进先出
+	timeout  time.Duration
+}
+
+func newScheduler(cfg *PriorityConfig) *scheduler {
+	s := &scheduler{
+		limits:  make([]int, len(priorityShare)),
+		waiters: make([][]chan struct{}, len(priorityShare)),
+		timeout: time.Duration(cfg.QueueTimeout) * time.Millisecond,
+	}
+	for i, v := range priorityShare {
+		s.limits[i] = int(float64(cfg.MaxInflight) * v)
+		if s.limits[i] < 1 {
+			s.limits[i] = 1
+		}
+	}
+	return s
+}
+
+// acquire 获取处理名额，返回false表示被拒绝
+func (s *scheduler) acquire(p int) bool {
+	s.mu.Lock()
+	if s.inflight < s.limits[p] && !s.queued(p) {
+		s.inflight++
+		s.mu.Unlock()
+		return true
+	}
+	if p > PriorityInteractive || s.timeout == 0 {
+		s.mu.Unlock()
+		return false
+	}
+	ch := make(chan struct{})
+	s.waiters[p] = append(s.waiters[p], ch)
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+	select {
+	case <-ch:
+		return true
+	case <-timer.C:
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.waiters[p] {
+		if w == ch {
+			s.waiters[p] = append(s.waiters[p][:i], s.waiters[p][i+1:]...)
+			return false
+		}
+	}
+	return true // 超时的同时已被唤醒
+}
+
+// queued 是否有同级或更高优先级的请求在排队，避免插队
+func (s *scheduler) queued(p int) bool {
+	for i := 0; i <= p; i++ {
+		if len(s.waiters[i]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// release 释放名额后按优先级唤醒排队的请求
+func (s *scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inflight--
+	for p := range s.waiters {
+		if len(s.waiters[p]) > 0 && s.inflight < s.limits[p] {
+			ch := s.waiters[p][0]
+			s.waiters[p] = s.waiters[p][1:]
+			s.inflight++
+			close(ch)
+			return
+		}
+	}
+}
+
+// Priority 标记路由组优先级，未配置handler.priority时不生效
+func (h *Handler) Priority(p int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.scheduler == nil {
+			c.Next()
+			return
+		}
+		if !h.scheduler.acquire(p) {
+			priorityRejected.Inc(priorityNames[p])
+			logger.FromContext(c).Warn("priority rejected", priorityNames[p], nil)
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(ServiceUnavailable, &RespErr{Msg: "系统繁忙，请稍后再试", Detail: "OVERLOADED"})
+			return
+		}
+		priorityInflight.Add(1)
+		defer func() {
+			priorityInflight.Dec()
+			h.scheduler.release()
+		}()
+		c.Next()
+	}
+}
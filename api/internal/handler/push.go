@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"sort"
+)
+
+// PushDeviceRegister app启动或token刷新后登记设备
+func (h *Handler) PushDeviceRegister(c *gin.Context) {
+	r, ok := Bind[proto.PushDeviceArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	err := h.service.RegisterPushDevice(c, &model.PushDevice{
+		UserID:     user.ID,
+		DeviceID:   r.DeviceID,
+		Platform:   r.Platform,
+		Provider:   r.Provider,
+		Token:      r.Token,
+		AppVersion: r.AppVersion,
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.RegisterPushDevice error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, Empty)
+}
+
+func (h *Handler) PushDeviceDelete(c *gin.Context) {
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	if err := h.service.UnregisterPushDevice(c, user.ID, c.Param("id")); err != nil {
+		logger.FromContext(c).Error("service.UnregisterPushDevice error", c.Param("id"), err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, Empty)
+}
+
+// GetPushPreference 推送偏好和已登记的设备
+func (h *Handler) GetPushPreference(c *gin.Context) {
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	p, err := h.service.GetPushPreference(c, user.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.GetPushPreference error", user.ID, err)
+		h.Err(c, err)
+		return
+	}
+	devices, err := h.service.FindPushDevices(c, user.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindPushDevices error", user.ID, err)
+		h.Err(c, err)
+		return
+	}
+	if devices == nil {
+		devices = make([]*model.PushDevice, 0)
+	}
+	categories := make(map[string]map[string]bool, len(model.PushCategories))
+	for _, v := range model.PushCategories {
+		categories[v] = map[string]bool{
+			model.PushChannelApp:    p.Allow(v, model.PushChannelApp),
+			model.PushChannelWechat: p.Allow(v, model.PushChannelWechat),
+		}
+	}
+	h.OK(c, &proto.PushPreferenceResp{
+		Channel:    p.Channel,
+		Categories: categories,
+		QuietStart: p.QuietStart,
+		QuietEnd:   p.QuietEnd,
+		Devices:    devices,
+	})
+}
+
+// SetPushPreference 整体覆盖偏好，免打扰时段按服务器时区，交易类通知不受限制
+func (h *Handler) SetPushPreference(c *gin.Context) {
+	r, ok := Bind[proto.PushPreferenceArgs](c)
+	if !ok {
+		return
+	}
+	if (r.QuietStart == "") != (r.QuietEnd == "") {
+		h.Fail(c, InvalidParam, "quiet_start和quiet_end需同时设置")
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	p := model.DefaultPushPreference(user.ID)
+	p.Channel, p.QuietStart, p.QuietEnd = r.Channel, r.QuietStart, r.QuietEnd
+	for category, channels := range r.Categories {
+		for channel, on := range channels {
+			if !on {
+				p.Muted = append(p.Muted, category+":"+channel)
+			}
+		}
+	}
+	sort.Strings(p.Muted)
+	if err := h.service.SetPushPreference(c, p); err != nil {
+		logger.FromContext(c).Error("service.SetPushPreference error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, Empty)
+}
+
+// Push 内部服务发起推送，异步发送，返回的msg_id用于查询各渠道结果
+func (h *Handler) Push(c *gin.Context) {
+	r, ok := Bind[proto.PushArgs](c)
+	if !ok {
+		return
+	}
+	id, err := h.service.Push(c, &model.MsgPush{
+		UserID:   r.UserID,
+		Category: r.Category,
+		Title:    r.Title,
+		Body:     r.Body,
+		Data:     r.Data,
+		TTL:      r.TTL,
+		Wechat:   r.Wechat,
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.Push error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, &proto.PushResp{MsgID: id})
+}
+
+// PushDeliveries 推送任务在各渠道的发送结果，尚未消费时为空
+func (h *Handler) PushDeliveries(c *gin.Context) {
+	list, err := h.service.FindPushDeliveries(c, c.Param("id"))
+	if err != nil {
+		logger.FromContext(c).Error("service.FindPushDeliveries error", c.Param("id"), err)
+		h.Err(c, err)
+		return
+	}
+	if list == nil {
+		list = make([]*model.PushDelivery, 0)
+	}
+	h.OK(c, list)
+}
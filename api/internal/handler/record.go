@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"project/api/internal/proto"
+	"project/api/internal/service"
+	"project/pkg/logger"
+	"strings"
+	"time"
+)
+
+// 外部调用录制：调试时按client名称把微信、合作方的请求和响应脱敏后按trace_id写入redis
+// 内部路由internal/record/:trace查看一次请求的全部外部调用，replay将其中一次发到预发环境的对应地址，对比响应
+
+type RecordConfig struct {
+	Clients []string         // 录制的client名称，如 wechat、payment、partner，为空不录制
+	TTL     int              // 保留秒数，默认86400
+	Staging []*RecordStaging // 重放的目标地址，未配置的host不可重放，避免请求生产环境
+}
+
+type RecordStaging struct {
+	Host string // 原host，如 api.weixin.qq.com
+	URL  string // 预发地址，如 https://wx-mock.staging.domain.cn
+}
+
+type recorder struct {
+	service service.Interface
+	ttl     time.Duration
+}
+
+// Record 异步写入，不阻塞外部调用
+func (r *recorder) Record(_ context.Context, ex *logger.Exchange) {
+	if ex.TraceID == "" {
+		return
+	}
+	go func() {
+		ctx, l := logger.NewCtxLog(ex.TraceID, "Record", ex.Client, "")
+		ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		defer cancel()
+		if err := r.service.SaveRecord(ctx, ex, r.ttl); err != nil {
+			l.Warn("service.SaveRecord fail", ex.URL, err)
+		}
+	}()
+}
+
+func (h *Handler) initRecord(cfg *RecordConfig) {
+	if cfg == nil || len(cfg.Clients) == 0 {
+		return
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 86400
+	}
+	h.recordStaging = make(map[string]*url.URL, len(cfg.Staging))
+	for _, v := range cfg.Staging {
+		u, err := url.Parse(v.URL)
+		if err != nil || u.Host == "" {
+			log.Fatal("handler.record.staging invalid url: ", v.URL)
+		}
+		h.recordStaging[v.Host] = u
+	}
+	h.replay = logger.NewClient("replay", 10*time.Second)
+	logger.SetRecorder(&recorder{service: h.service, ttl: time.Duration(cfg.TTL) * time.Second}, cfg.Clients)
+}
+
+// GetRecords 一次请求录制的外部调用，按调用顺序
+func (h *Handler) GetRecords(c *gin.Context) {
+	list, err := h.service.GetRecords(c, c.Param("trace"))
+	if err != nil {
+		logger.FromContext(c).Error("service.GetRecords error", c.Param("trace"), err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, list)
+}
+
+// ReplayRecord 将录制的请求发到预发环境，脱敏的参数和请求头不发送，由预发环境自行补全
+func (h *Handler) ReplayRecord(c *gin.Context) {
+	r, ok := Bind[proto.RecordReplayArgs](c)
+	if !ok {
+		return
+	}
+	list, err := h.service.GetRecords(c, c.Param("trace"))
+	if err != nil {
+		logger.FromContext(c).Error("service.GetRecords error", c.Param("trace"), err)
+		h.Err(c, err)
+		return
+	}
+	if r.Index >= len(list) {
+		h.Fail(c, NotFound, "记录不存在或已过期")
+		return
+	}
+	ex := list[r.Index]
+	if strings.HasPrefix(ex.Body, "[binary ") {
+		h.Fail(c, Unprocessable, "文件上传不支持重放")
+		return
+	}
+	u, err := url.Parse(ex.URL)
+	if err != nil {
+		h.Fail(c, Unprocessable, "录制的地址无效")
+		return
+	}
+	base, ok := h.recordStaging[u.Host]
+	if !ok {
+		h.Fail(c, Forbidden, "未配置该host的预发地址")
+		return
+	}
+	q := u.Query()
+	for k, v := range q {
+		if len(v) > 0 && v[0] == logger.Redacted {
+			q.Del(k)
+		}
+	}
+	u.Scheme, u.Host, u.Path, u.RawQuery = base.Scheme, base.Host, strings.TrimSuffix(base.Path, "/")+u.Path, q.Encode()
+	req, err := http.NewRequestWithContext(c, ex.Method, u.String(), strings.NewReader(ex.Body))
+	if err != nil {
+		h.Err(c, err)
+		return
+	}
+	for k, v := range ex.Header {
+		if v != logger.Redacted && k != "Content-Length" {
+			req.Header.Set(k, v)
+		}
+	}
+	resp, err := h.replay.Do(req)
+	if err != nil {
+		logger.FromContext(c).Warn("replay error", u.String(), err)
+		h.Err(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	h.OK(c, &proto.RecordReplayResp{
+		URL:              u.String(),
+		Status:           resp.StatusCode,
+		Response:         string(b),
+		RecordedStatus:   ex.Status,
+		RecordedResponse: ex.Response,
+	})
+}
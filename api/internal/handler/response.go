@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Envelope 统一响应结构，通过handler.envelope配置按路径前缀启用，未启用的路由保持原有响应
+type Envelope struct {
+	Code   int    `json:"code"`
+	Msg    string `json:"msg"`
+	Detail string `json:"detail,omitempty"` // 失败时的业务错误码
+	Data   any    `json:"data,omitempty"`
+	Meta   *Meta  `json:"meta,omitempty"`
+}
+
+type Meta struct {
+	Page    int    `json:"page,omitempty"`
+	Size    int    `json:"size,omitempty"`
+	Total   int64  `json:"total"`
+	TraceID string `json:"trace_id,omitempty"`
+}
+
+type listResp struct {
+	Total *int64 `json:"total,omitempty"`
+	List  any    `json:"list"`
+}
+
+var errCodeReg = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+const (
+	envelopeKey = "envelope"
+	errCodeKey  = "err_code" // 失败响应的业务错误码，AccessLog写入日志和指标
+)
+
+// Envelope 标记当前请求使用统一响应结构
+func (h *Handler) Envelope(c *gin.Context) {
+	path := c.FullPath()
+	for _, p := range h.envelope {
+		if strings.HasPrefix(path, p) {
+			c.Set(envelopeKey, true)
+			break
+		}
+	}
+	c.Next()
+}
+
+func RespOK(c *gin.Context, data any) (int, any) {
+	if !c.GetBool(envelopeKey) {
+		return OK, data
+	}
+	return OK, &Envelope{
+		Msg:  "ok",
+		Data: data,
+	}
+}
+
+// RespList 列表响应，meta为nil时不返回分页信息
+func RespList(c *gin.Context, list any, meta *Meta) (int, any) {
+	if !c.GetBool(envelopeKey) {
+		resp := &listResp{List: list}
+		if meta != nil {
+			resp.Total = &meta.Total
+		}
+		return OK, resp
+	}
+	if meta == nil {
+		meta = &Meta{}
+	}
+	meta.TraceID = c.GetString("trace_id")
+	return OK, &Envelope{
+		Msg:  "ok",
+		Data: list,
+		Meta: meta,
+	}
+}
+
+// OK 成功响应，按配置包装统一响应结构
+func (h *Handler) OK(c *gin.Context, data any) {
+	writeOK(c, OK, data)
+}
+
+// List 列表响应，meta为nil时不返回分页信息
+func (h *Handler) List(c *gin.Context, list any, meta *Meta) {
+	c.Header("X-Trace-Id", c.GetString("trace_id"))
+	c.JSON(RespList(c, list, meta))
+}
+
+// Created 创建资源成功，返回201
+func (h *Handler) Created(c *gin.Context, data any) {
+	writeOK(c, http.StatusCreated, data)
+}
+
+// Err 按错误类型返回状态码和业务错误码，见RespWithErr
+func (h *Handler) Err(c *gin.Context, err error) {
+	status, e := RespWithErr(err)
+	writeErr(c, status, e)
+}
+
+// Fail 返回指定状态码和提示信息
+func (h *Handler) Fail(c *gin.Context, status int, msg string) {
+	writeErr(c, status, &RespErr{Msg: msg})
+}
+
+func writeOK(c *gin.Context, status int, data any) {
+	c.Header("X-Trace-Id", c.GetString("trace_id"))
+	_, data = RespOK(c, data)
+	c.JSON(status, data)
+}
+
+// writeErr 失败响应统一在此写入trace_id和业务错误码，未指定错误码时使用状态码
+func writeErr(c *gin.Context, status int, e *RespErr) {
+	tid := c.GetString("trace_id")
+	c.Header("X-Trace-Id", tid)
+	e.TraceID = tid
+	code := e.Detail
+	if !errCodeReg.MatchString(code) { // 参数错误等detail为描述而不是错误码
+		code = strconv.Itoa(status)
+	}
+	c.Set(errCodeKey, code)
+	if c.GetBool(envelopeKey) {
+		c.JSON(status, &Envelope{Code: status, Msg: e.Msg, Detail: e.Detail})
+		return
+	}
+	c.JSON(status, e)
+}
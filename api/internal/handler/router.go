@@ -1,29 +1,150 @@
 package handler
 
-import (
-	"github.com/gin-gonic/gin"
-)
+import "github.com/gin-gonic/gin"
 
-func (h *Handler) register(r *gin.Engine) {
+// register 注册全局中间件和mounts中的路由组，路由组的中间件由虚拟主机决定(域名、IP限制)
+func (h *Handler) register(r *gin.Engine, mounts map[string]gin.HandlersChain) {
 	r.GET("ping", func(c *gin.Context) {
 		c.String(OK, "pong")
 	})
-	r.NoRoute(func(c *gin.Context) {
-		c.AbortWithStatus(NotFound)
-	})
-	r.Use(Recover, SetContext) // 如nginx未添加跨域头，则此处应添加Cors中间件
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(NoRoute)
+	r.NoMethod(NoMethod)
+	r.Use(Recover, SetContext, ClientInfo) // 如nginx未添加跨域头，则此处应添加Cors中间件
+	r.Use(h.Deadline)
+	if h.concurrency != nil {
+		r.Use(h.Concurrency)
+	}
+	if h.bodyLimit > 0 || len(h.bodyRoutes) > 0 {
+		r.Use(h.MaxBodySize)
+	}
+	if h.geo != nil {
+		r.Use(h.Geo)
+	}
+	r.Use(h.VersionGate)
+	if len(h.deprecated) > 0 {
+		r.Use(h.Deprecated)
+	}
+	if len(h.envelope) > 0 {
+		r.Use(h.Envelope)
+	}
+	if len(h.strict) > 0 {
+		r.Use(h.StrictJSONMiddleware)
+	}
+	if h.chaosOn {
+		r.Use(h.Chaos)
+	}
+	r.Use(Fields)
+
+	api := r.Group("", AccessLog, h.HTTPStat)
+	if m, ok := mounts[GroupApp]; ok {
+		h.registerApp(api.Group("", m...))
+	}
+	if m, ok := mounts[GroupCallback]; ok {
+		callback := api.Group("callback", m...)
+		callback.Use(h.Priority(PriorityCritical))
+		if h.kf != nil {
+			callback.GET("wechat", h.WechatCallbackVerify) // 微信消息推送，不参与灰度
+			callback.POST("wechat", h.WechatCallback)
+		}
+		callback.POST("pay/:provider", h.PayNotify)
+	}
+	if m, ok := mounts[GroupPartner]; ok {
+		partner := api.Group("partner", m...)
+		partner.Use(h.APIKeyAuth, h.Meter, h.Priority(PriorityBatch)) // 合作方接口，按api_key计量和限额
+		partner.GET("usage", h.PartnerUsage)
+	}
+}
 
-	api := r.Group("", AccessLog)
+func (h *Handler) registerApp(api *gin.RouterGroup) {
 	{
-		api.POST("wechat/login", h.WechatLogin)
-		api.GET("example/banners", h.GetBanners)
-		api.POST("example/message", h.PushMessage)
+		pub := api.Group("", h.Canary, h.Captcha, h.Coalesce)
+		pub.POST("wechat/login", h.Priority(PriorityCritical), h.WechatLogin)
+		pub.POST("wechat/guest", h.Priority(PriorityCritical), h.GuestSession)
+		if len(h.oauth) > 0 {
+			pub.GET("wechat/oauth/authorize", h.Priority(PriorityInteractive), h.OAuthAuthorize)
+			pub.POST("wechat/oauth/login", h.Priority(PriorityCritical), h.OAuthLogin)
+		}
+		pub.GET("wechat/captcha", h.Priority(PriorityInteractive), h.NewCaptcha)
+		pub.POST("wechat/captcha/verify", h.Priority(PriorityInteractive), h.VerifyCaptcha)
+		if h.jssdk != nil {
+			pub.GET("wechat/jssdk", h.Priority(PriorityInteractive), h.JSSDKSign)
+		}
+		pub.GET("content/:key", h.Priority(PriorityInteractive), h.GetContent)
+		pub.GET("store/nearby", h.Priority(PriorityInteractive), h.NearbyStores)
+		pub.GET("store/:id", h.Priority(PriorityInteractive), h.GetStore)
+		pub.GET("region/tree", h.Priority(PriorityInteractive), h.RegionTree)
+		pub.GET("region/children", h.Priority(PriorityInteractive), h.RegionChildren)
+		pub.GET("booking/resources", h.Priority(PriorityInteractive), h.BookingResources)
+		pub.GET("booking/slots", h.Priority(PriorityInteractive), h.BookingSlots)
+		pub.GET("lottery", h.Priority(PriorityInteractive), h.Lottery)
+		pub.GET("example/banners", h.Priority(PriorityInteractive), h.GetBanners)
+		pub.POST("example/message", h.Priority(PriorityBackground), h.PushMessage)
+		pub.GET("example/stock", h.Priority(PriorityInteractive), h.GetStock)
+	}
+	api.GET("s/:code", h.Priority(PriorityInteractive), h.ShortLinkRedirect)
+	if h.h5 != nil {
+		api.GET("h5/:page", h.Priority(PriorityInteractive), h.H5Page)
 	}
 
 	{
-		wx := api.Group("wechat", h.AuthCheck)
+		guest := api.Group("wechat", h.SessionCheck, h.Meter, h.Canary, h.Captcha, h.Coalesce, h.Priority(PriorityInteractive)) // 游客可访问的浏览和购物车接口，只计量登录用户
+		guest.GET("coupon/templates", h.CouponTemplates)
+		guest.GET("cart", h.GetCart)
+		guest.PUT("cart", h.SetCartItem)
+		guest.DELETE("cart", h.ClearCart)
+	}
+
+	{
+		wx := api.Group("wechat", h.AuthCheck, h.Meter, h.Canary, h.Captcha, h.Coalesce) // 登录后按用户ID分流
+		core := wx.Group("", h.Priority(PriorityCritical))                               // 下单支付链路
+		core.POST("order/pay", h.OrderPay)
+		core.POST("order/cancel", h.OrderCancel)
+		core.POST("order/complete", h.OrderComplete)
+		core.POST("points/exchange", h.PointsExchange)
+
+		wx.Use(h.Priority(PriorityInteractive))
 		wx.POST("phone", h.WechatPhone)
-		wx.PUT("userinfo", h.SaveUserInfo)
+		wx.POST("token/refresh", h.TokenRefresh)
+		wx.DELETE("logout", h.Logout)
+		wx.GET("auth/history", h.AuthHistory)
+		wx.GET("devices", h.UserDevices)
+		wx.PUT("push/device", h.PushDeviceRegister)
+		wx.DELETE("push/device/:id", h.PushDeviceDelete)
+		wx.GET("push/preference", h.GetPushPreference)
+		wx.PUT("push/preference", h.SetPushPreference)
+		wx.PUT("userinfo", h.Unmuted, h.SaveUserInfo)
+		wx.PATCH("userinfo", h.Unmuted, h.PatchUserInfo)
 		wx.GET("userinfo", h.GetUserInfo)
+		wx.GET("experiments", h.GetExperiments)
+		wx.POST("ocr/idcard", h.OCRIDCard)
+		wx.POST("coupon/claim", h.CouponClaim)
+		wx.GET("coupons", h.CouponList)
+		wx.POST("coupon/price", h.CouponPrice)
+		wx.GET("ledger/accounts", h.LedgerAccounts)
+		wx.GET("ledger/statement", h.LedgerStatement)
+		wx.POST("track/share", h.Priority(PriorityBackground), h.Unmuted, h.TrackShare)
+		wx.POST("chat/conversation", h.ChatConversationCreate)
+		wx.GET("chat/conversations", h.ChatConversations)
+		wx.GET("chat/members", h.ChatMembers)
+		wx.POST("chat/message", h.Unmuted, h.ChatSend)
+		wx.GET("chat/messages", h.ChatMessages)
+		wx.PUT("chat/read", h.ChatRead)
+		wx.GET("addresses", h.AddressList)
+		wx.GET("address", h.AddressDetail)
+		wx.POST("address", h.AddressCreate)
+		wx.PUT("address", h.AddressUpdate)
+		wx.DELETE("address/:id", h.AddressDelete)
+		wx.PUT("address/default", h.AddressSetDefault)
+		wx.POST("booking", h.BookingReserve)
+		wx.PUT("booking/confirm", h.BookingConfirm)
+		wx.PUT("booking/cancel", h.BookingCancel)
+		wx.GET("bookings", h.BookingList)
+		wx.POST("checkin", h.Checkin)
+		wx.GET("checkin", h.CheckinStatus)
+		wx.GET("checkin/calendar", h.CheckinCalendar)
+		wx.POST("lottery/draw", h.LotteryDraw)
+		wx.GET("lottery/chances", h.LotteryChances)
+		wx.GET("lottery/wins", h.LotteryWins)
 	}
 }
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 路由清单：请求方法、处理函数、中间件链和废弃状态，用于审计实际暴露的接口
+
+type RouteInfo struct {
+	Method     string       `json:"method"`
+	Path       string       `json:"path"`
+	Handler    string       `json:"handler"`
+	Middleware []string     `json:"middleware"`
+	Version    string       `json:"version,omitempty"`  // 路径中的/vN/
+	Listener   string       `json:"listener,omitempty"` // 单独监听的虚拟主机，公网端口为空
+	Deprecated *Deprecation `json:"deprecated,omitempty"`
+}
+
+// Deprecation 已废弃的路由，响应头返回Deprecation、Sunset和successor-version链接
+type Deprecation struct {
+	Route     string `json:"-"`                   // method+path，如 GET/example/banners
+	Sunset    string `json:"sunset"`              // 下线日期，如 2026-12-31
+	Successor string `json:"successor,omitempty"` // 替代接口
+}
+
+func (h *Handler) initDeprecated(list []*Deprecation) {
+	h.deprecated = make(map[string]*Deprecation, len(list))
+	for _, v := range list {
+		h.deprecated[v.Route] = v
+	}
+}
+
+// Deprecated 废弃的路由在响应头中提示客户端迁移(RFC 8594)
+func (h *Handler) Deprecated(c *gin.Context) {
+	if d, ok := h.deprecated[routeKey(c)]; ok {
+		c.Header("Deprecation", "true")
+		if t, err := time.ParseInLocation("2006-01-02", d.Sunset, time.Local); err == nil {
+			c.Header("Sunset", t.UTC().Format(http.TimeFormat))
+		}
+		if d.Successor != "" {
+			c.Header("Link", "<"+d.Successor+`>; rel="successor-version"`)
+		}
+	}
+	c.Next()
+}
+
+// GetRoutes 返回公网端口和单独监听的虚拟主机的路由清单
+func (h *Handler) GetRoutes(c *gin.Context) {
+	h.OK(c, gin.H{"list": allRoutes(h.public, h.listeners, h.deprecated)})
+}
+
+// Routes 供命令行输出路由清单
+func Routes(cfg *Config, r *gin.Engine, listeners []*Listener) []*RouteInfo {
+	h := &Handler{}
+	h.initDeprecated(cfg.Deprecated)
+	return allRoutes(r, listeners, h.deprecated)
+}
+
+func allRoutes(r *gin.Engine, listeners []*Listener, deprecated map[string]*Deprecation) []*RouteInfo {
+	list := routes(r, deprecated)
+	for _, l := range listeners {
+		for _, v := range routes(l.Engine, deprecated) {
+			v.Listener = l.Name
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+var versionReg = regexp.MustCompile(`/(v\d+)/`)
+
+func routes(r *gin.Engine, deprecated map[string]*Deprecation) []*RouteInfo {
+	chains := handlerChains(r)
+	var list []*RouteInfo
+	for _, v := range r.Routes() {
+		names := chains[v.Method+v.Path]
+		info := &RouteInfo{
+			Method:     v.Method,
+			Path:       v.Path,
+			Handler:    funcName(v.Handler),
+			Middleware: []string{},
+			Deprecated: deprecated[v.Method+v.Path],
+		}
+		if len(names) > 0 {
+			info.Middleware = names[:len(names)-1]
+		}
+		if m := versionReg.FindStringSubmatch(v.Path + "/"); m != nil {
+			info.Version = m[1]
+		}
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Path == list[j].Path {
+			return list[i].Method < list[j].Method
+		}
+		return list[i].Path < list[j].Path
+	})
+	return list
+}
+
+// handlerChains gin未导出完整的处理链，通过反射读取路由树(基于gin v1.8的engine.trees结构)
+func handlerChains(r *gin.Engine) map[string][]string {
+	chains := make(map[string][]string)
+	trees := reflect.ValueOf(r).Elem().FieldByName("trees")
+	if !trees.IsValid() {
+		return chains
+	}
+	for i := 0; i < trees.Len(); i++ {
+		tree := trees.Index(i)
+		walkNode(tree.FieldByName("method").String(), tree.FieldByName("root"), chains)
+	}
+	return chains
+}
+
+func walkNode(method string, n reflect.Value, chains map[string][]string) {
+	if n.IsNil() {
+		return
+	}
+	n = n.Elem()
+	if handlers := n.FieldByName("handlers"); handlers.Len() > 0 {
+		names := make([]string, handlers.Len())
+		for i := range names {
+			names[i] = funcName(runtime.FuncForPC(handlers.Index(i).Pointer()).Name())
+		}
+		chains[method+n.FieldByName("fullPath").String()] = names
+	}
+	children := n.FieldByName("children")
+	for i := 0; i < children.Len(); i++ {
+		walkNode(method, children.Index(i), chains)
+	}
+}
+
+var closureReg = regexp.MustCompile(`(\.func\d+)+$`)
+
+// funcName 去掉包路径、方法值后缀和闭包后缀，本包的函数只保留名称，如 Priority、gin.WrapH
+func funcName(name string) string {
+	name = name[strings.LastIndex(name, "/")+1:]
+	name = strings.TrimSuffix(name, "-fm")
+	name = closureReg.ReplaceAllString(name, "")
+	name = strings.TrimPrefix(name, "handler.(*Handler).")
+	return strings.TrimPrefix(name, "handler.")
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"project/pkg/logger"
+	"project/pkg/shortlink"
+	"strconv"
+	"time"
+)
+
+// ShortLinkRedirect 短链跳转，响应按linkAge可被CDN缓存，缓存命中的点击不会回源统计
+func (h *Handler) ShortLinkRedirect(c *gin.Context) {
+	l, err := h.service.ResolveShortLink(c, c.Param("code"))
+	switch err {
+	case nil:
+	case shortlink.ErrNotFound, shortlink.ErrDisabled, shortlink.ErrExpired:
+		c.Header("Cache-Control", "public, max-age=60")
+		c.AbortWithStatus(NotFound)
+		return
+	default:
+		logger.FromContext(c).Error("service.ResolveShortLink error", c.Param("code"), err)
+		c.Header("Cache-Control", "no-store")
+		h.Err(c, err)
+		return
+	}
+	err = h.service.PushShortLinkClick(c, &shortlink.Click{
+		Code:      l.Code,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Referer:   c.Request.Referer(),
+		Time:      time.Now().Unix(),
+	})
+	if err != nil {
+		logger.FromContext(c).Warn("service.PushShortLinkClick fail", l.Code, err)
+	}
+	if age := l.MaxAge(h.linkAge); age > 0 {
+		c.Header("Cache-Control", "public, max-age="+strconv.FormatInt(age, 10))
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+	c.Redirect(http.StatusFound, l.Location(h.appid))
+}
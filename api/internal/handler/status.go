@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"project/model"
+	"project/pkg/logger"
+	"time"
+)
+
+// 账号处罚：封禁拒绝登录和全部接口，冻结只能读取，禁言不能修改公开资料和分享；状态由cms设置，到期自动恢复
+
+// suspendedAllow 冻结期间仍可调用的写接口
+var suspendedAllow = map[string]bool{
+	"POST/wechat/token/refresh": true,
+	"DELETE/wechat/logout":      true,
+}
+
+var statusErrors = map[int8]struct{ msg, code string }{
+	model.UserMuted:     {"账号已被禁言", "ACCOUNT_MUTED"},
+	model.UserSuspended: {"账号已被冻结，仅可浏览", "ACCOUNT_SUSPENDED"},
+	model.UserBanned:    {"账号已被封禁", "ACCOUNT_BANNED"},
+}
+
+func statusErr(st *model.UserStatus) *RespErr {
+	e := statusErrors[st.Status]
+	msg := e.msg
+	if st.ExpireTime > 0 {
+		msg += "，" + time.Unix(st.ExpireTime, 0).Format("2006-01-02 15:04") + "解除"
+	}
+	if st.Reason != "" {
+		msg += "，原因：" + st.Reason
+	}
+	return &RespErr{Msg: msg, Detail: e.code}
+}
+
+// checkStatus AuthCheck中调用，状态查询失败时放行；拒绝时已写入响应并返回false
+func (h *Handler) checkStatus(c *gin.Context, uid int) bool {
+	st, err := h.service.GetUserStatus(c, uid)
+	if err != nil {
+		logger.FromContext(c).Warn("service.GetUserStatus fail", uid, err)
+		return true
+	}
+	switch st.Effective(time.Now()) {
+	case model.UserActive:
+		return true
+	case model.UserMuted:
+		c.Set("muted", st)
+		return true
+	case model.UserMerged:
+		c.AbortWithStatusJSON(Unauthorized, &RespErr{Msg: "账号已合并，请重新登录", Detail: "ACCOUNT_MERGED"})
+		return false
+	case model.UserSuspended:
+		m := c.Request.Method
+		if m == http.MethodGet || m == http.MethodHead || suspendedAllow[routeKey(c)] {
+			return true
+		}
+	}
+	c.AbortWithStatusJSON(Forbidden, statusErr(st))
+	return false
+}
+
+// Unmuted 禁言用户不能访问的接口，需在AuthCheck之后使用
+func (h *Handler) Unmuted(c *gin.Context) {
+	if v, ok := c.Get("muted"); ok {
+		c.AbortWithStatusJSON(Forbidden, statusErr(v.(*model.UserStatus)))
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/pkg/logger"
+	"strconv"
+	"strings"
+)
+
+func (h *Handler) GetStock(c *gin.Context) {
+	r, ok := BindQuery[proto.StockArgs](c)
+	if !ok {
+		return
+	}
+	parts := strings.Split(r.SkuIDs, ",")
+	if len(parts) > 50 {
+		h.Fail(c, InvalidParam, "sku_ids最多50个")
+		return
+	}
+	skus := make([]int, 0, len(parts))
+	for _, v := range parts {
+		id, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || id <= 0 {
+			h.Fail(c, InvalidParam, "sku_ids格式错误")
+			return
+		}
+		skus = append(skus, id)
+	}
+	stock, err := h.service.GetStock(c, skus)
+	if err != nil {
+		logger.FromContext(c).Error("service.GetStock error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	list := make([]*proto.StockItem, 0, len(skus))
+	for _, sku := range skus {
+		list = append(list, &proto.StockItem{SkuID: sku, Stock: stock[sku]})
+	}
+	h.List(c, list, nil)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+)
+
+// NearbyStores 附近营业中的门店，按距离升序，distance为米；小程序按最近门店的城市切换城市内容
+func (h *Handler) NearbyStores(c *gin.Context) {
+	r, ok := BindQuery[proto.NearbyArgs](c)
+	if !ok {
+		return
+	}
+	if r.Radius == 0 {
+		r.Radius = 5000
+	}
+	if r.Limit == 0 {
+		r.Limit = 20
+	}
+	list, err := h.service.NearbyStores(c, r.Lng, r.Lat, r.Radius, r.Limit)
+	if err != nil {
+		logger.FromContext(c).Error("service.NearbyStores error", &r, err)
+		h.Err(c, err)
+		return
+	}
+	h.List(c, list, nil)
+}
+
+func (h *Handler) GetStore(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if id <= 0 {
+		h.Err(c, model.ErrStoreNotFound)
+		return
+	}
+	data, err := h.service.FindStore(c, id)
+	if err == model.ErrStoreNotFound {
+		h.Err(c, err)
+		return
+	}
+	if err != nil {
+		logger.FromContext(c).Error("service.FindStore error", id, err)
+		h.Err(c, err)
+		return
+	}
+	h.OK(c, data)
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StrictError 严格模式下的字段错误，未定义的字段和类型不匹配的字段全部列出
+type StrictError []string
+
+func (e StrictError) Error() string {
+	return strings.Join(e, "; ")
+}
+
+const (
+	strictKey   = "strict_json"
+	maxSafeInt  = 1<<53 - 1 // js Number可精确表示的最大整数
+	maxBodySize = 1 << 20
+)
+
+type strictJSON struct{}
+
+// StrictJSON 拒绝未定义字段，校验字段类型和整数精度
+var StrictJSON binding.BindingBody = strictJSON{}
+
+func (strictJSON) Name() string {
+	return "strict_json"
+}
+
+func (b strictJSON) Bind(req *http.Request, obj any) error {
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+	return b.BindBody(body, obj)
+}
+
+func (strictJSON) BindBody(body []byte, obj any) error {
+	var errs StrictError
+	checkFields(body, reflect.TypeOf(obj), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(obj); err != nil {
+		return StrictError{err.Error()}
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	res := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for k, v := range jsonFields(f.Type) {
+				res[k] = v
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		res[name] = f
+	}
+	return res
+}
+
+func checkFields(raw []byte, t reflect.Type, prefix string, errs *StrictError) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		var m map[string]json.RawMessage
+		if json.Unmarshal(raw, &m) != nil {
+			return // 类型错误由上层报告
+		}
+		fields := jsonFields(t)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			f, ok := fields[k]
+			if !ok {
+				*errs = append(*errs, prefix+k+": unknown field")
+				continue
+			}
+			checkValue(m[k], f.Type, prefix+k, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		var list []json.RawMessage
+		if json.Unmarshal(raw, &list) != nil {
+			return
+		}
+		for i, v := range list {
+			checkValue(v, t.Elem(), prefix+"["+strconv.Itoa(i)+"]", errs)
+		}
+	}
+}
+
+func checkValue(raw json.RawMessage, t reflect.Type, path string, errs *StrictError) {
+	if string(raw) == "null" {
+		return
+	}
+	et := t
+	for et.Kind() == reflect.Pointer {
+		et = et.Elem()
+	}
+	switch et.Kind() {
+	case reflect.Struct:
+		if len(raw) > 0 && raw[0] == '{' {
+			checkFields(raw, et, path+".", errs)
+			return
+		}
+	case reflect.Slice, reflect.Array:
+		if len(raw) > 0 && raw[0] == '[' {
+			checkFields(raw, et, path, errs)
+			return
+		}
+	}
+	if err := json.Unmarshal(raw, reflect.New(t).Interface()); err != nil {
+		if _, ok := err.(*json.UnmarshalTypeError); ok {
+			*errs = append(*errs, path+": invalid type, expect "+et.Kind().String())
+		}
+		return
+	}
+	switch et.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		if f, err := strconv.ParseFloat(string(raw), 64); err == nil && math.Abs(f) > maxSafeInt {
+			*errs = append(*errs, path+": number exceeds precision, use string")
+		}
+	}
+}
+
+// StrictJSONMiddleware 按handler.strictJson配置的路由前缀启用严格解析
+func (h *Handler) StrictJSONMiddleware(c *gin.Context) {
+	path := c.FullPath()
+	for _, p := range h.strict {
+		if strings.HasPrefix(path, p) {
+			c.Set(strictKey, true)
+			break
+		}
+	}
+	c.Next()
+}
+
+// bindJSON handler统一使用的json绑定，启用严格模式时使用StrictJSON
+func bindJSON(c *gin.Context, obj any) error {
+	if c.GetBool(strictKey) {
+		return c.ShouldBindWith(obj, StrictJSON)
+	}
+	return c.ShouldBindJSON(obj)
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/api/internal/proto"
+	"project/model"
+)
+
+// TrackShare 小程序onShareAppMessage/onShareTimeline触发时上报，分享行为服务端无法感知
+func (h *Handler) TrackShare(c *gin.Context) {
+	r, ok := Bind[proto.TrackShareArgs](c)
+	if !ok {
+		return
+	}
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	h.service.Track(c, user.ID, &model.TrackShare{
+		Page:    r.Page,
+		Channel: r.Channel,
+		Target:  r.Target,
+	})
+	h.OK(c, Empty)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/util/random"
+	"project/pkg/util/useragent"
+	"time"
+)
+
+// 客户端版本控制，请求头X-App-Version低于平台最低版本时返回426，小程序和网页不带该请求头不受影响
+// 最低版本以配置文件为默认值，cms后台写入redis的同平台版本优先
+
+type UpgradeResp struct {
+	Msg        string `json:"msg"`
+	Detail     string `json:"detail"`
+	MinVersion string `json:"min_version"`
+	URL        string `json:"url"` // 应用商店下载地址
+}
+
+func (h *Handler) loadAppVersions() error {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Upgrade", "loadAppVersions", "")
+	list, err := h.service.GetAppVersions(ctx)
+	if err != nil {
+		l.Error("service.GetAppVersions error", nil, err)
+		return err
+	}
+	for k, v := range h.upgradeDefault {
+		if _, ok := list[k]; !ok {
+			list[k] = v
+		}
+	}
+	h.upgrade.Store(list)
+	return nil
+}
+
+func (h *Handler) watchAppVersions() {
+	h.loadAppVersions()
+	for range time.Tick(10 * time.Second) {
+		h.loadAppVersions()
+	}
+}
+
+func (h *Handler) VersionGate(c *gin.Context) {
+	version := c.GetHeader("X-App-Version")
+	if version == "" {
+		c.Next()
+		return
+	}
+	platform := c.GetHeader("X-App-Platform")
+	if platform == "" {
+		platform = GetClient(c).Platform
+	}
+	list, _ := h.upgrade.Load().(map[string]*model.AppVersion)
+	if v, ok := list[platform]; ok && useragent.Compare(version, v.Min) < 0 {
+		c.AbortWithStatusJSON(UpgradeRequired, &UpgradeResp{
+			Msg:        "当前版本过低，请升级后使用",
+			Detail:     "UPGRADE_REQUIRED",
+			MinVersion: v.Min,
+			URL:        v.URL,
+		})
+		return
+	}
+	c.Next()
+}
@@ -1,27 +1,37 @@
 package handler
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"io"
 	"project/api/internal/proto"
 	"project/model"
+	"project/pkg/json"
 	"project/pkg/logger"
+	"project/pkg/util/patch"
+	"project/pkg/wechat"
+	"time"
 )
 
 func (h *Handler) WechatLogin(c *gin.Context) {
-	var r proto.LoginArgs
-	if err := c.ShouldBindJSON(&r); err != nil {
-		c.JSON(RespWithMsg(InvalidParam, err.Error()))
+	r, ok := Bind[proto.LoginArgs](c)
+	if !ok {
 		return
 	}
 	resp, err := h.wechat.JsCode2Session(c, r.JsCode)
-	if err != nil {
-		logger.FromContext(c).Error("wechat.JsCode2Session error", r.JsCode, err)
-		c.JSON(RespWithErr(err))
+	if errors.Is(err, wechat.ErrInvalidCode) || err == nil && resp.Openid == "" {
+		logger.FromContext(c).Warn("wechat.JsCode2Session fail", r.JsCode, resp)
+		h.authEvent(c, model.AuthLoginFailed, 0, "", "invalid code")
+		h.Fail(c, Unprocessable, "Invalid Or Expired")
 		return
 	}
-	if resp.Openid == "" {
-		logger.FromContext(c).Warn("wechat.JsCode2Session fail", r.JsCode, resp)
-		c.JSON(RespWithMsg(Unprocessable, "Invalid Or Expired"))
+	if err != nil {
+		logger.FromContext(c).Error("wechat.JsCode2Session error", r.JsCode, err)
+		h.authEvent(c, model.AuthLoginFailed, 0, "", "wechat error")
+		h.Err(c, err)
 		return
 	}
 	c.Set("v2", resp.Openid)
@@ -32,42 +42,64 @@ func (h *Handler) WechatLogin(c *gin.Context) {
 	})
 	if err != nil {
 		logger.FromContext(c).Error("service.SaveUser error", nil, err)
-		c.JSON(RespWithErr(err))
+		h.Err(c, err)
 		return
 	}
-	token, err := h.service.SetUserToken(c, &proto.UserToken{
+	h.issueLogin(c, &proto.UserToken{
 		ID:         uid,
 		Openid:     resp.Openid,
 		Unionid:    resp.Unionid,
 		SessionKey: resp.SessionKey,
-	})
+	}, r.DeviceID, r.GuestToken)
+}
+
+// issueLogin 校验账号状态后签发登录凭证，合并游客购物车
+func (h *Handler) issueLogin(c *gin.Context, user *proto.UserToken, deviceID, guestToken string) {
+	uid := user.ID
+	if st, err := h.service.GetUserStatus(c, uid); err == nil && st.Effective(time.Now()) == model.UserBanned {
+		h.authEvent(c, model.AuthLoginFailed, uid, "", "banned")
+		writeErr(c, Forbidden, statusErr(st))
+		return
+	}
+	token, err := h.service.SetUserToken(c, user)
 	if err != nil {
 		logger.FromContext(c).Error("service.SetUserToken error", uid, err)
-		c.JSON(RespWithErr(err))
+		h.Err(c, err)
 		return
 	}
-	c.JSON(OK, &proto.LoginResp{
+	h.authEvent(c, model.AuthTokenIssued, uid, token, "")
+	h.loginDevice(c, uid, token, deviceID)
+	if user.Unionid != "" {
+		h.service.DetectUserMerge(c, uid, user.Unionid)
+	}
+	if guestToken != "" {
+		if _, err = h.service.MergeGuest(c, guestToken, uid); err != nil { // 合并失败不影响登录
+			logger.FromContext(c).Warn("service.MergeGuest fail", uid, err)
+		}
+	}
+	cl := GetClient(c)
+	h.service.Track(c, uid, &model.TrackLogin{Openid: user.Openid, Platform: cl.Platform, Env: cl.Env})
+	h.OK(c, &proto.LoginResp{
 		Token:   token,
-		Openid:  resp.Openid,
-		Unionid: resp.Unionid,
+		Openid:  user.Openid,
+		Unionid: user.Unionid,
 	})
 }
 
 func (h *Handler) WechatPhone(c *gin.Context) {
-	var r proto.WechatPhoneArgs
-	if err := c.ShouldBindJSON(&r); err != nil {
-		c.JSON(RespWithMsg(InvalidParam, err.Error()))
+	r, ok := Bind[proto.WechatPhoneArgs](c)
+	if !ok {
 		return
 	}
 	resp, err := h.wechat.GetUserPhoneNumber(c, r.Code)
-	if err != nil {
+	if err != nil && !errors.Is(err, wechat.ErrInvalidCode) {
 		logger.FromContext(c).Error("wechat.GetUserPhoneNumber error", r.Code, err)
-		c.JSON(RespWithErr(err))
+		h.Err(c, err)
 		return
 	}
-	if resp.PhoneInfo == nil || resp.PhoneInfo.PhoneNumber == "" {
+	if err != nil || resp.PhoneInfo == nil || resp.PhoneInfo.PhoneNumber == "" {
 		logger.FromContext(c).Warn("wechat.GetUserPhoneNumber fail", r.Code, resp)
-		c.JSON(RespWithMsg(Unprocessable, "Invalid Or Expired"))
+		h.Fail(c, Unprocessable, "Invalid Or Expired")
 		return
 	}
 	u, _ := c.Get("user")
@@ -78,18 +110,17 @@ func (h *Handler) WechatPhone(c *gin.Context) {
 	})
 	if err != nil {
 		logger.FromContext(c).Error("service.SaveUserPhone error", resp.PhoneInfo, err)
-		c.JSON(RespWithErr(err))
+		h.Err(c, err)
 		return
 	}
-	c.JSON(OK, &proto.WechatPhoneResp{
+	h.OK(c, &proto.WechatPhoneResp{
 		PhoneNumber: resp.PhoneInfo.PhoneNumber,
 	})
 }
 
 func (h *Handler) SaveUserInfo(c *gin.Context) {
-	var r proto.SaveUserInfoArgs
-	if err := c.ShouldBindJSON(&r); err != nil {
-		c.JSON(RespWithMsg(InvalidParam, err.Error()))
+	r, ok := Bind[proto.SaveUserInfoArgs](c)
+	if !ok {
 		return
 	}
 	u, _ := c.Get("user")
@@ -101,10 +132,10 @@ func (h *Handler) SaveUserInfo(c *gin.Context) {
 	})
 	if err != nil {
 		logger.FromContext(c).Error("service.SaveUserInfo error", &r, err)
-		c.JSON(RespWithErr(err))
+		h.Err(c, err)
 		return
 	}
-	c.JSON(OK, Empty)
+	h.OK(c, Empty)
 }
 
 func (h *Handler) GetUserInfo(c *gin.Context) {
@@ -113,12 +144,109 @@ func (h *Handler) GetUserInfo(c *gin.Context) {
 	info, err := h.service.FindUserByID(c, user.ID)
 	if err != nil {
 		logger.FromContext(c).Error("service.FindUserByID error", user.ID, err)
-		c.JSON(RespWithErr(err))
+		h.Err(c, err)
+		return
+	}
+	resp := &proto.GetUserInfoResp{
+		PhoneNumber: info.PhoneNumber,
+		Nickname:    info.Nickname,
+		AvatarURL:   info.AvatarURL,
+	}
+	b, _ := json.Marshal(resp)
+	c.Header("ETag", userInfoETag(b))
+	h.OK(c, resp)
+}
+
+func userInfoETag(b []byte) string {
+	sum := sha1.Sum(b)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// PatchUserInfo 部分更新头像昵称，仅允许修改nickname和avatar_url
+func (h *Handler) PatchUserInfo(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 8<<10))
+	if err != nil || len(body) == 0 {
+		h.Fail(c, InvalidParam, "Invalid Body")
+		return
+	}
+	var paths []string
+	var ops []*patch.Operation
+	switch c.ContentType() {
+	case patch.MediaTypeJSONPatch:
+		if ops, err = patch.ParseOperations(body); err == nil {
+			paths = patch.OperationPaths(ops)
+		}
+	case patch.MediaTypeMergePatch:
+		paths, err = patch.MergePaths(body)
+	default:
+		h.Fail(c, UnsupportedType, "Unsupported Content-Type")
+		return
+	}
+	if err != nil {
+		h.Fail(c, InvalidParam, err.Error())
+		return
+	}
+	if err = patch.CheckPaths(paths, "/nickname", "/avatar_url"); err != nil {
+		h.Fail(c, Unprocessable, err.Error())
+		return
+	}
+
+	u, _ := c.Get("user")
+	user := u.(*proto.UserToken)
+	info, err := h.service.FindUserByID(c, user.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindUserByID error", user.ID, err)
+		h.Err(c, err)
 		return
 	}
-	c.JSON(OK, &proto.GetUserInfoResp{
+	doc, _ := json.Marshal(&proto.GetUserInfoResp{
 		PhoneNumber: info.PhoneNumber,
 		Nickname:    info.Nickname,
 		AvatarURL:   info.AvatarURL,
 	})
+	if match := c.GetHeader("If-Match"); match != "" && match != userInfoETag(doc) {
+		h.Fail(c, PreconditionFailed, "数据已变更，请刷新后重试")
+		return
+	}
+	if ops != nil {
+		doc, err = patch.Apply(doc, ops)
+	} else {
+		doc, err = patch.Merge(doc, body)
+	}
+	if err == patch.ErrTest {
+		h.Fail(c, Conflict, "数据已变更，请刷新后重试")
+		return
+	}
+	if err != nil {
+		h.Fail(c, Unprocessable, err.Error())
+		return
+	}
+	var r proto.SaveUserInfoArgs
+	if err = json.Unmarshal(doc, &r); err != nil {
+		h.Fail(c, Unprocessable, "Invalid Patch Result")
+		return
+	}
+	if !bindOK(c, binding.Validator.ValidateStruct(&r)) {
+		return
+	}
+	if r.Nickname != info.Nickname || r.AvatarURL != info.AvatarURL {
+		err = h.service.UpdateUser(c, &model.User{
+			ID:        user.ID,
+			Nickname:  r.Nickname,
+			AvatarURL: r.AvatarURL,
+		})
+		if err != nil {
+			logger.FromContext(c).Error("service.UpdateUser error", &r, err)
+			h.Err(c, err)
+			return
+		}
+	}
+	resp := &proto.GetUserInfoResp{
+		PhoneNumber: info.PhoneNumber,
+		Nickname:    r.Nickname,
+		AvatarURL:   r.AvatarURL,
+	}
+	b, _ := json.Marshal(resp)
+	c.Header("ETag", userInfoETag(b))
+	h.OK(c, resp)
 }
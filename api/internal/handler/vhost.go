@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"net"
+	"project/pkg/server"
+	"strings"
+)
+
+// 虚拟主机：按域名或单独的监听地址拆分路由组，如 api.domain.cn、callback.domain.cn、admin.domain.cn 由同一进程提供
+// 每个路由组最多属于一个虚拟主机，未分配的路由组挂在公网端口且不限制域名
+
+const (
+	GroupApp      = "app"      // 小程序、App、H5使用的接口和短链
+	GroupCallback = "callback" // 微信消息推送和支付回调，不鉴权
+	GroupPartner  = "partner"  // 合作方接口
+)
+
+var routeGroups = []string{GroupApp, GroupCallback, GroupPartner}
+
+type VHostConfig struct {
+	Name     string      // 路由清单中的名称
+	Hosts    []string    // 允许的请求域名，为空不限制，其他域名访问返回404
+	Groups   []string    // 挂载的路由组，app|callback|partner
+	Addr     string      // 单独监听的地址，为空时与公网端口共用
+	TLS      *server.TLS // 单独监听时的证书，其他参数同handler.server
+	AllowIPs []string    `mapstructure:"allowIPs"` // 允许访问的IP或CIDR，按直连的对端地址判断，为空不限制
+}
+
+// Listener 单独监听的虚拟主机
+type Listener struct {
+	Name   string
+	Config *server.Config
+	Engine *gin.Engine
+}
+
+type vhost struct {
+	name   string
+	hosts  map[string]bool
+	allow  []*net.IPNet
+	engine *gin.Engine // 与公网端口共用时为nil
+}
+
+// mount 在公网engine上注册未单独监听的路由组，返回单独监听的虚拟主机
+func (h *Handler) mount(cfg *Config, r *gin.Engine) []*Listener {
+	groups := vhostGroups(cfg.VHosts)
+	public := make(map[string]gin.HandlersChain)
+	separate := make(map[*VHostConfig]map[string]gin.HandlersChain)
+	vhosts := make(map[*VHostConfig]*vhost)
+	for _, g := range routeGroups {
+		v, ok := groups[g]
+		if !ok {
+			public[g] = nil
+			continue
+		}
+		vh, ok := vhosts[v]
+		if !ok {
+			vh = newVHost(v)
+			vhosts[v] = vh
+		}
+		if v.Addr == "" {
+			public[g] = vh.middleware()
+			continue
+		}
+		if separate[v] == nil {
+			separate[v] = make(map[string]gin.HandlersChain)
+		}
+		separate[v][g] = vh.middleware()
+	}
+	h.register(r, public)
+	for _, v := range cfg.VHosts {
+		mounts, ok := separate[v]
+		if !ok {
+			continue
+		}
+		e := newEngine(&cfg.Server)
+		h.register(e, mounts)
+		sc := cfg.Server
+		sc.Addr, sc.TLS = v.Addr, v.TLS
+		h.listeners = append(h.listeners, &Listener{Name: v.Name, Config: &sc, Engine: e})
+	}
+	return h.listeners
+}
+
+// vhostGroups 返回各路由组所属的虚拟主机，未分配的路由组不在其中
+func vhostGroups(list []*VHostConfig) map[string]*VHostConfig {
+	known := make(map[string]bool, len(routeGroups))
+	for _, g := range routeGroups {
+		known[g] = true
+	}
+	groups := make(map[string]*VHostConfig)
+	for _, v := range list {
+		for _, g := range v.Groups {
+			if !known[g] {
+				log.Fatalf("handler.vhosts %s: unknown group %s", v.Name, g)
+			}
+			if exist, ok := groups[g]; ok {
+				log.Fatalf("handler.vhosts: group %s mounted by both %s and %s", g, exist.Name, v.Name)
+			}
+			groups[g] = v
+		}
+	}
+	return groups
+}
+
+func newVHost(cfg *VHostConfig) *vhost {
+	vh := &vhost{name: cfg.Name, hosts: make(map[string]bool, len(cfg.Hosts))}
+	for _, v := range cfg.Hosts {
+		vh.hosts[strings.ToLower(v)] = true
+	}
+	for _, v := range cfg.AllowIPs {
+		if !strings.Contains(v, "/") {
+			if strings.Contains(v, ":") {
+				v += "/128"
+			} else {
+				v += "/32"
+			}
+		}
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			log.Fatalf("handler.vhosts %s: invalid allowIPs %s", cfg.Name, v)
+		}
+		vh.allow = append(vh.allow, n)
+	}
+	return vh
+}
+
+// middleware 域名不符时按路由不存在处理，不暴露其他虚拟主机的接口
+func (vh *vhost) middleware() gin.HandlersChain {
+	var chain gin.HandlersChain
+	if len(vh.hosts) > 0 {
+		chain = append(chain, vh.Host)
+	}
+	if len(vh.allow) > 0 {
+		chain = append(chain, vh.AllowIP)
+	}
+	return chain
+}
+
+func (vh *vhost) Host(c *gin.Context) {
+	host := c.Request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if !vh.hosts[strings.ToLower(host)] {
+		NoRoute(c)
+		return
+	}
+	c.Next()
+}
+
+// AllowIP 不读取X-Forwarded-For，经nginx转发时应由nginx限制来源
+func (vh *vhost) AllowIP(c *gin.Context) {
+	ip := net.ParseIP(c.RemoteIP())
+	for _, n := range vh.allow {
+		if ip != nil && n.Contains(ip) {
+			c.Next()
+			return
+		}
+	}
+	c.AbortWithStatusJSON(Forbidden, &RespErr{Msg: "禁止访问", Detail: "IP_DENIED", TraceID: c.GetString("trace_id")})
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"project/model"
+	"project/pkg/boot"
+	"strings"
+)
+
+// 启动预热：加载redis中的热点配置、预取access_token和jsapi_ticket、请求常用路由(填充本地缓存和路由树)
+// 预热结束前ready返回503，负载均衡和k8s readiness探针据此判断是否转发流量
+
+type WarmupConfig struct {
+	Routes []string // 预热时请求的GET路由，如 /wechat/config，不应有副作用
+}
+
+func (h *Handler) initWarmup(cfg *WarmupConfig) {
+	boot.OnWarmup("config", func(ctx context.Context) error {
+		loads := []func() error{h.loadAppVersions, h.loadAPIKeys}
+		if h.chaosOn {
+			loads = append(loads, h.loadChaosRules)
+		}
+		var err error
+		for _, load := range loads {
+			if e := load(); e != nil && err == nil {
+				err = e
+			}
+		}
+		return err
+	})
+	boot.OnWarmup("wechat", func(ctx context.Context) error {
+		if _, _, err := h.service.TokenStore().Get(ctx, model.KeyWechatToken); err != nil {
+			return err
+		}
+		if h.jssdk != nil {
+			return h.jssdk.Prime(ctx)
+		}
+		return nil
+	})
+	if cfg == nil || len(cfg.Routes) == 0 {
+		return
+	}
+	boot.OnWarmup("routes", func(ctx context.Context) error {
+		var failed []string
+		for _, v := range cfg.Routes {
+			req := httptest.NewRequest(http.MethodGet, v, nil).WithContext(ctx)
+			req.Header.Set("X-Warmup", "1")
+			w := httptest.NewRecorder()
+			h.public.ServeHTTP(w, req)
+			if w.Code >= http.StatusInternalServerError {
+				failed = append(failed, fmt.Sprintf("%s %d", v, w.Code))
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("routes failed: %s", strings.Join(failed, ", "))
+		}
+		return nil
+	})
+}
+
+// Ready readiness探针，预热结束前返回503
+func Ready(c *gin.Context) {
+	if !boot.Ready() {
+		c.String(ServiceUnavailable, "warming")
+		return
+	}
+	c.String(OK, "ok")
+}
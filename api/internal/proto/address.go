@@ -0,0 +1,27 @@
+package proto
+
+type AddressArgs struct {
+	ID           int     `json:"id" binding:"min=0"` // 修改时必填
+	Name         string  `json:"name" binding:"required,max=30"`
+	Phone        string  `json:"phone" binding:"required,max=20,numeric"`
+	ProvinceCode string  `json:"province_code" binding:"len=6,numeric"`
+	CityCode     string  `json:"city_code" binding:"len=6,numeric"`
+	DistrictCode string  `json:"district_code" binding:"omitempty,len=6,numeric"` // 城市没有下级区县时为空
+	Detail       string  `json:"detail" binding:"required,max=200"`
+	Lng          float64 `json:"lng" binding:"min=-180,max=180"` // 地图选点(wx.chooseLocation)的gcj02坐标，未传时按地址解析
+	Lat          float64 `json:"lat" binding:"min=-90,max=90"`
+	IsDefault    bool    `json:"is_default"`
+}
+
+type AddressIDArgs struct {
+	ID int `form:"id" json:"id" binding:"min=1"`
+}
+
+// AddressDetailArgs id为0时返回默认地址
+type AddressDetailArgs struct {
+	ID int `form:"id" binding:"min=0"`
+}
+
+type RegionArgs struct {
+	Parent string `form:"parent" binding:"omitempty,len=6,numeric"` // 为空返回省份
+}
@@ -0,0 +1,47 @@
+package proto
+
+import (
+	"project/model"
+	"time"
+)
+
+type BookingSlotArgs struct {
+	ResourceID int    `form:"resource_id" binding:"min=1"`
+	Date       string `form:"date" binding:"datetime=2006-01-02"`
+}
+
+// BookingSlot 时段和剩余名额，剩余名额仅供展示，以预约结果为准
+type BookingSlot struct {
+	TemplateID int       `json:"template_id"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Capacity   int       `json:"capacity"`
+	Remaining  int       `json:"remaining"`
+}
+
+type BookingArgs struct {
+	ResourceID int    `json:"resource_id" binding:"min=1"`
+	TemplateID int    `json:"template_id" binding:"min=1"`
+	Date       string `json:"date" binding:"datetime=2006-01-02"`
+	Remark     string `json:"remark" binding:"max=200"`
+}
+
+type BookingIDArgs struct {
+	ID int `json:"id" binding:"min=1"`
+}
+
+type BookingCancelArgs struct {
+	ID     int    `json:"id" binding:"min=1"`
+	Reason string `json:"reason" binding:"max=200"`
+}
+
+type BookingListArgs struct {
+	Page int `form:"page" binding:"min=1"`
+	Size int `form:"size" binding:"min=10,max=50"`
+}
+
+// BookingItem 保留到期未确认的预约status为已过期
+type BookingItem struct {
+	*model.Booking
+	ResourceName string `json:"resource_name"`
+}
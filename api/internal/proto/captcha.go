@@ -0,0 +1,20 @@
+package proto
+
+type CaptchaArgs struct {
+	Kind string `form:"kind" binding:"omitempty,oneof=slider image"` // 默认slider
+}
+
+type CaptchaVerifyArgs struct {
+	ID     string `json:"id" binding:"required,max=64"`
+	Answer string `json:"answer" binding:"required,max=16"` // 滑块为缺口x坐标，图片为字符
+}
+
+type CaptchaVerifyResp struct {
+	Ticket string `json:"ticket"`
+}
+
+// CaptchaFlagArgs 风控标记需要验证码的主体
+type CaptchaFlagArgs struct {
+	Subject string `json:"subject" binding:"required,max=128"` // 如ip:1.2.3.4、user:1
+	TTL     int    `json:"ttl" binding:"min=1,max=604800"`     // 秒
+}
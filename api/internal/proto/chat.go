@@ -0,0 +1,46 @@
+package proto
+
+import "project/model"
+
+type ChatConversationArgs struct {
+	Type    string `json:"type" binding:"oneof=single group"`
+	UserIDs []int  `json:"user_ids" binding:"min=1,max=199,dive,min=1"` // 单聊为对方，群聊为其他成员
+	Name    string `json:"name" binding:"max=30"`                       // 群聊名称
+}
+
+type ChatConversationListArgs struct {
+	Page int `form:"page" binding:"min=1"`
+	Size int `form:"size" binding:"min=10,max=50"`
+}
+
+// ChatConversationItem 会话列表项，unread为未读条数
+type ChatConversationItem struct {
+	*model.ChatConversation
+	ReadSeq     int64              `json:"read_seq"`
+	Unread      int64              `json:"unread"`
+	LastMessage *model.ChatMessage `json:"last_message"`
+}
+
+type ChatIDArgs struct {
+	ConversationID int `form:"conversation_id" binding:"min=1"`
+}
+
+type ChatMessageArgs struct {
+	ConversationID int    `json:"conversation_id" binding:"min=1"`
+	ClientMsgID    string `json:"client_msg_id" binding:"required,max=40,printascii"`
+	Type           string `json:"type" binding:"oneof=text image"`
+	Content        string `json:"content" binding:"required,max=2000"`
+}
+
+// ChatHistoryArgs after>0时返回之后的消息(正序)，否则返回before之前的消息(倒序)，都为0时为最新的消息
+type ChatHistoryArgs struct {
+	ConversationID int   `form:"conversation_id" binding:"min=1"`
+	Before         int64 `form:"before" binding:"min=0"`
+	After          int64 `form:"after" binding:"min=0"`
+	Size           int   `form:"size" binding:"min=1,max=100"`
+}
+
+type ChatReadArgs struct {
+	ConversationID int   `json:"conversation_id" binding:"min=1"`
+	Seq            int64 `json:"seq" binding:"min=1"`
+}
@@ -0,0 +1,37 @@
+package proto
+
+import "project/model"
+
+// CheckinArgs timezone为IANA时区名(如Asia/Shanghai)，为空使用默认时区
+type CheckinArgs struct {
+	Timezone string `json:"timezone" binding:"omitempty,timezone"`
+}
+
+type CheckinStatusArgs struct {
+	Timezone string `form:"timezone" binding:"omitempty,timezone"`
+}
+
+type CheckinCalendarArgs struct {
+	Month string `form:"month" binding:"datetime=2006-01"`
+}
+
+// CheckinResult New为false时今天已签到，返回当天的签到记录
+type CheckinResult struct {
+	*model.CheckinLog
+	New bool `json:"new"`
+}
+
+// CheckinStatus 当前时区的签到日、是否已签到和连续天数，已中断时streak为0
+type CheckinStatus struct {
+	Date    string           `json:"date"`
+	Checked bool             `json:"checked"`
+	Streak  int              `json:"streak"`
+	Total   int              `json:"total"`
+	Rewards []*CheckinReward `json:"rewards"` // 接下来连续签到每天的奖励
+}
+
+type CheckinReward struct {
+	Date   string `json:"date"`
+	Streak int    `json:"streak"`
+	Points int64  `json:"points"`
+}
@@ -0,0 +1,10 @@
+package proto
+
+import "encoding/json"
+
+type ContentResp struct {
+	Key         string          `json:"key"`
+	Version     int             `json:"version"`
+	PublishTime int64           `json:"publish_time"`
+	Data        json.RawMessage `json:"data"`
+}
@@ -0,0 +1,20 @@
+package proto
+
+type CouponClaimArgs struct {
+	TemplateID int `json:"template_id" binding:"min=1"`
+}
+
+type CouponListArgs struct {
+	Status int8 `form:"status" binding:"omitempty,oneof=-1 1 2"`
+}
+
+type CouponPriceArgs struct {
+	CouponID int   `json:"coupon_id" binding:"min=1"`
+	Amount   int64 `json:"amount" binding:"min=1"` // 订单原价(分)
+}
+
+type CouponPriceResp struct {
+	Amount    int64 `json:"amount"`
+	Discount  int64 `json:"discount"`
+	PayAmount int64 `json:"pay_amount"`
+}
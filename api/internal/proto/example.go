@@ -1,12 +1,12 @@
 package proto
 
 type BannerItem struct {
+	ID    int    `json:"id"`
 	Title string `json:"title"`
 	Img   string `json:"img"`
 	Type  int8   `json:"type"`
 	Link  string `json:"link"`
-}
-
-type BannersResp struct {
-	List []*BannerItem `json:"list"`
+	// 以下字段仅增量同步返回，由客户端按时间窗口过滤
+	BeginTime int64 `json:"begin_time,omitempty"`
+	EndTime   int64 `json:"end_time,omitempty"`
 }
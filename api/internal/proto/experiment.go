@@ -0,0 +1,6 @@
+package proto
+
+type ExperimentItem struct {
+	Key     string `json:"key"`
+	Variant string `json:"variant"`
+}
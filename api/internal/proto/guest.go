@@ -0,0 +1,30 @@
+package proto
+
+// GuestToken 游客凭证，只能访问浏览和购物车接口
+type GuestToken struct {
+	ID       string `json:"i"`
+	DeviceID string `json:"d"`
+}
+
+type GuestSessionArgs struct {
+	DeviceID string `json:"device_id" binding:"required,max=64"`
+}
+
+type GuestSessionResp struct {
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expires_in"` // 秒，每次使用后顺延
+}
+
+type CartItem struct {
+	SkuID    int `json:"sku_id"`
+	Quantity int `json:"quantity"`
+}
+
+type CartArgs struct {
+	SkuID    int `json:"sku_id" binding:"min=1"`
+	Quantity int `json:"quantity" binding:"min=0,max=99"` // 0为移除
+}
+
+type CartResp struct {
+	List []*CartItem `json:"list"`
+}
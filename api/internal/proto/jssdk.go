@@ -0,0 +1,5 @@
+package proto
+
+type JSSDKArgs struct {
+	URL string `form:"url" binding:"required,url,max=1024"` // 当前页面的完整url，#及其后部分不参与签名
+}
@@ -0,0 +1,16 @@
+package proto
+
+type LedgerStatementArgs struct {
+	Asset string `form:"asset" binding:"oneof=points balance"`
+	Page  int    `form:"page" binding:"min=1"`
+	Size  int    `form:"size" binding:"min=10,max=50"`
+}
+
+type PointsExchangeArgs struct {
+	SkuID    int `json:"sku_id" binding:"required"`
+	Quantity int `json:"quantity" binding:"min=1,max=10"`
+}
+
+type PointsExchangeResp struct {
+	OrderNo string `json:"order_no"`
+}
@@ -0,0 +1,6 @@
+package proto
+
+type LiveArgs struct {
+	Window   int `form:"window" binding:"omitempty,min=1,max=59"`   // 统计窗口秒数，默认10
+	Interval int `form:"interval" binding:"omitempty,min=1,max=10"` // 推送间隔秒数，默认1
+}
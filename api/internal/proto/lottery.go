@@ -0,0 +1,22 @@
+package proto
+
+type LotteryArgs struct {
+	ID int `form:"id" binding:"min=1"`
+}
+
+type LotteryDrawArgs struct {
+	ID int `json:"id" binding:"min=1"`
+}
+
+type LotteryWinArgs struct {
+	LotteryID int `form:"lottery_id" binding:"min=0"` // 0为全部活动
+	Page      int `form:"page" binding:"min=1"`
+	Size      int `form:"size" binding:"min=10,max=50"`
+}
+
+// LotteryChances 已抽次数和剩余次数，remain为-1时不限次数
+type LotteryChances struct {
+	DailyUsed int `json:"daily_used"`
+	TotalUsed int `json:"total_used"`
+	Remain    int `json:"remain"`
+}
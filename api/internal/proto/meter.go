@@ -0,0 +1,6 @@
+package proto
+
+type UsageArgs struct {
+	Begin int `form:"begin" binding:"min=20000101,max=30000101"` // 20060102
+	End   int `form:"end" binding:"gtefield=Begin,max=30000101"`
+}
@@ -0,0 +1,11 @@
+package proto
+
+type IDCardResp struct {
+	Side        string `json:"side"` // front|back
+	Name        string `json:"name,omitempty"`
+	IDNumber    string `json:"id_number,omitempty"`
+	Gender      string `json:"gender,omitempty"`
+	Nationality string `json:"nationality,omitempty"`
+	Addr        string `json:"addr,omitempty"`
+	ValidDate   string `json:"valid_date,omitempty"`
+}
@@ -0,0 +1,18 @@
+package proto
+
+type OrderPayArgs struct {
+	OrderNo   string `json:"order_no" binding:"required,max=32"`
+	Provider  string `json:"provider" binding:"oneof=wxpay alipay"`
+	Scene     string `json:"scene" binding:"oneof=mini h5 app web"`
+	ReturnURL string `json:"return_url" binding:"omitempty,url"`
+	CouponID  int    `json:"coupon_id" binding:"min=0"` // 首次发起支付时可使用优惠券，按减免后的金额支付
+}
+
+type OrderCancelArgs struct {
+	OrderNo string `json:"order_no" binding:"required,max=32"`
+	Reason  string `json:"reason" binding:"max=100"`
+}
+
+type OrderCompleteArgs struct {
+	OrderNo string `json:"order_no" binding:"required,max=32"`
+}
@@ -0,0 +1,19 @@
+package proto
+
+type PresenceConn struct {
+	UserID int    `json:"user_id" binding:"min=1"`
+	ConnID string `json:"conn_id" binding:"required,max=64"`
+}
+
+// PresenceHeartbeatArgs 网关按连接批量上报心跳
+type PresenceHeartbeatArgs struct {
+	List []*PresenceConn `json:"list" binding:"required,max=1000,dive"`
+}
+
+type PresenceHeartbeatResp struct {
+	Joined []int `json:"joined"` // 由离线变为在线的用户
+}
+
+type PresenceArgs struct {
+	UserIDs string `form:"user_ids" binding:"required"` // 逗号分隔，最多200个
+}
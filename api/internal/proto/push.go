@@ -0,0 +1,42 @@
+package proto
+
+import "project/model"
+
+type PushDeviceArgs struct {
+	DeviceID   string `json:"device_id" binding:"required,max=64"`
+	Platform   string `json:"platform" binding:"oneof=ios android"`
+	Provider   string `json:"provider" binding:"required,max=20"` // apns|fcm|厂商通道名
+	Token      string `json:"token" binding:"required,max=255"`
+	AppVersion string `json:"app_version" binding:"max=20"`
+}
+
+// PushPreferenceArgs categories为分类到各渠道是否接收，未列出的分类和渠道为接收
+type PushPreferenceArgs struct {
+	Channel    string                     `json:"channel" binding:"oneof=auto app wechat off"`
+	Categories map[string]map[string]bool `json:"categories" binding:"dive,keys,oneof=transactional reminder marketing,endkeys,dive,keys,oneof=app wechat,endkeys"`
+	QuietStart string                     `json:"quiet_start" binding:"omitempty,datetime=15:04"`
+	QuietEnd   string                     `json:"quiet_end" binding:"omitempty,datetime=15:04"`
+}
+
+type PushPreferenceResp struct {
+	Channel    string                     `json:"channel"`
+	Categories map[string]map[string]bool `json:"categories"` // 全部分类和渠道
+	QuietStart string                     `json:"quiet_start"`
+	QuietEnd   string                     `json:"quiet_end"`
+	Devices    []*model.PushDevice        `json:"devices"`
+}
+
+// PushArgs 内部服务发起推送，wechat为空时只发送app推送
+type PushArgs struct {
+	UserID   int               `json:"user_id" binding:"required"`
+	Category string            `json:"category" binding:"oneof=transactional reminder marketing"`
+	Title    string            `json:"title" binding:"required,max=50"`
+	Body     string            `json:"body" binding:"required,max=200"`
+	Data     map[string]string `json:"data"`
+	TTL      int               `json:"ttl" binding:"min=0,max=2419200"`
+	Wechat   *model.PushWechat `json:"wechat"`
+}
+
+type PushResp struct {
+	MsgID string `json:"msg_id"`
+}
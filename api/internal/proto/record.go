@@ -0,0 +1,13 @@
+package proto
+
+type RecordReplayArgs struct {
+	Index int `json:"index" binding:"min=0"` // internal/record/:trace返回列表中的下标
+}
+
+type RecordReplayResp struct {
+	URL              string `json:"url"`
+	Status           int    `json:"status"`
+	Response         string `json:"response"`
+	RecordedStatus   int    `json:"recorded_status"`
+	RecordedResponse string `json:"recorded_response"`
+}
@@ -0,0 +1,10 @@
+package proto
+
+type StockArgs struct {
+	SkuIDs string `form:"sku_ids" binding:"required"` // 逗号分隔，最多50个
+}
+
+type StockItem struct {
+	SkuID int `json:"sku_id"`
+	Stock int `json:"stock"` // -1未开售
+}
@@ -0,0 +1,20 @@
+package proto
+
+type NearbyArgs struct {
+	Lng    float64 `form:"lng" binding:"min=-180,max=180"` // gcj02，小程序wx.getLocation的type需为gcj02
+	Lat    float64 `form:"lat" binding:"min=-85,max=85"`
+	Radius int     `form:"radius" binding:"omitempty,min=100,max=50000"` // 米，默认5000
+	Limit  int     `form:"limit" binding:"omitempty,min=1,max=50"`       // 默认20
+}
+
+type StoreItem struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	City     string  `json:"city"`
+	Address  string  `json:"address"`
+	Phone    string  `json:"phone"`
+	Hours    string  `json:"hours"`
+	Lng      float64 `json:"lng"`
+	Lat      float64 `json:"lat"`
+	Distance int     `json:"distance,omitempty"` // 米，附近搜索时返回
+}
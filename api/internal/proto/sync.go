@@ -0,0 +1,19 @@
+package proto
+
+// SyncArgs 增量同步参数，updated_since、since_id为上次响应返回的sync_time、sync_id
+// 响应full_sync为true后，has_more的后续请求需带上full_sync=true，否则会再次从头开始
+type SyncArgs struct {
+	UpdatedSince int64 `form:"updated_since" binding:"min=0"`
+	SinceID      int   `form:"since_id" binding:"min=0"`
+	FullSync     bool  `form:"full_sync"`
+}
+
+// SyncResp 增量同步响应，deleted_ids为已删除或下线的记录，客户端需从本地移除
+type SyncResp struct {
+	List       any   `json:"list"`
+	DeletedIDs []int `json:"deleted_ids"`
+	SyncTime   int64 `json:"sync_time"`
+	SyncID     int   `json:"sync_id"` // 与sync_time组成游标，同一秒内更新的记录超过单次条数时按id继续
+	HasMore    bool  `json:"has_more"`
+	FullSync   bool  `json:"full_sync"` // 游标早于墓碑保留期，已删除的记录可能已清理，客户端需清空本地数据后按list重建
+}
@@ -0,0 +1,7 @@
+package proto
+
+type TrackShareArgs struct {
+	Page    string `json:"page" binding:"required"`
+	Channel string `json:"channel" binding:"required,oneof=friend timeline"`
+	Target  string `json:"target"`
+}
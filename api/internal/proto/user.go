@@ -1,5 +1,7 @@
 package proto
 
+import "project/model"
+
 type UserToken struct {
 	ID         int    `json:"i"`
 	Openid     string `json:"o"`
@@ -8,7 +10,27 @@ type UserToken struct {
 }
 
 type LoginArgs struct {
-	JsCode string `json:"js_code" binding:"required"`
+	JsCode     string `json:"js_code" binding:"required"`
+	GuestToken string `json:"guest_token"`                // 登录前的游客凭证，登录后合并购物车并作废
+	DeviceID   string `json:"device_id" binding:"max=64"` // 客户端生成并持久保存的设备ID，用于识别新设备登录
+}
+
+// OAuthAuthorizeArgs app为handler.oauth中配置的渠道
+type OAuthAuthorizeArgs struct {
+	App string `form:"app" binding:"required,max=16"`
+}
+
+type OAuthAuthorizeResp struct {
+	URL   string `json:"url"` // 网站应用的扫码登录页，授权后跳转到配置的redirect并带上code和state
+	State string `json:"state"`
+}
+
+type OAuthLoginArgs struct {
+	App        string `json:"app" binding:"required,max=16"`
+	Code       string `json:"code" binding:"required"`
+	State      string `json:"state" binding:"max=64"` // 网站应用必填，移动应用不校验
+	GuestToken string `json:"guest_token"`
+	DeviceID   string `json:"device_id" binding:"max=64"`
 }
 
 type LoginResp struct {
@@ -35,3 +57,15 @@ type GetUserInfoResp struct {
 	Nickname    string `json:"nickname"`
 	AvatarURL   string `json:"avatar_url"`
 }
+
+type TokenRefreshResp struct {
+	Token string `json:"token"`
+}
+
+type AuthHistoryResp struct {
+	List []*model.AuthEvent `json:"list"`
+}
+
+type UserDevicesResp struct {
+	List []*model.UserDevice `json:"list"`
+}
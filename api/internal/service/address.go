@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"project/pkg/logger"
+	"time"
+)
+
+// FindAddresses 默认地址在前，其余按最后修改时间倒序；省市区已失效的标记outdated
+func (s *Service) FindAddresses(ctx context.Context, uid int) ([]*model.UserAddress, error) {
+	var list []*model.UserAddress
+	err := s.mysql.WithContext(ctx).Where("user_id = ?", uid).
+		Order("is_default DESC, update_time DESC, id DESC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range list {
+		s.markOutdated(v)
+	}
+	return list, nil
+}
+
+// FindAddress 用户的收货地址，id为0时返回默认地址
+func (s *Service) FindAddress(ctx context.Context, uid, id int) (*model.UserAddress, error) {
+	query := s.mysql.WithContext(ctx).Where("user_id = ?", uid)
+	if id > 0 {
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("is_default = ?", true)
+	}
+	var list []*model.UserAddress
+	if err := query.Limit(1).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, model.ErrAddressNotFound
+	}
+	s.markOutdated(list[0])
+	return list[0], nil
+}
+
+func (s *Service) markOutdated(addr *model.UserAddress) {
+	_, err := s.Regions().Resolve(addr.ProvinceCode, addr.CityCode, addr.DistrictCode)
+	addr.Outdated = err != nil
+}
+
+// resolveAddress 按当前数据集校验省市区并填充名称快照
+func (s *Service) resolveAddress(addr *model.UserAddress) error {
+	chain, err := s.Regions().Resolve(addr.ProvinceCode, addr.CityCode, addr.DistrictCode)
+	if err != nil {
+		return model.ErrAddressRegion
+	}
+	addr.Province, addr.City, addr.District = chain[0].Name, chain[1].Name, ""
+	if len(chain) == 3 {
+		addr.District = chain[2].Name
+	}
+	return nil
+}
+
+// CreateAddress 新增收货地址，第一个地址自动设为默认；锁定用户的地址行，并发新增时数量上限和默认地址仍然正确
+func (s *Service) CreateAddress(ctx context.Context, addr *model.UserAddress) error {
+	if err := s.resolveAddress(addr); err != nil {
+		return err
+	}
+	err := s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids, err := lockAddresses(tx, addr.UserID)
+		if err != nil {
+			return err
+		}
+		if len(ids) >= model.AddressMax {
+			return model.ErrAddressLimit
+		}
+		if len(ids) == 0 {
+			addr.IsDefault = true
+		} else if addr.IsDefault {
+			if err = clearDefaultAddress(tx, addr.UserID); err != nil {
+				return err
+			}
+		}
+		addr.ID = 0
+		return tx.Create(addr).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.geocodeAddress(ctx, addr)
+	return nil
+}
+
+// UpdateAddress 修改收货地址，取消默认时不自动指定其他地址；省市区或详细地址变更且客户端未传坐标时重新解析
+func (s *Service) UpdateAddress(ctx context.Context, addr *model.UserAddress) error {
+	if err := s.resolveAddress(addr); err != nil {
+		return err
+	}
+	var old model.UserAddress
+	err := s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockAddresses(tx, addr.UserID); err != nil {
+			return err
+		}
+		err := tx.Where("user_id = ?", addr.UserID).Take(&old, addr.ID).Error
+		if err == gorm.ErrRecordNotFound {
+			return model.ErrAddressNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if addr.IsDefault && !old.IsDefault {
+			if err = clearDefaultAddress(tx, addr.UserID); err != nil {
+				return err
+			}
+		}
+		if addr.Lng == 0 && addr.Lat == 0 && addr.FullText() == old.FullText() {
+			addr.Lng, addr.Lat = old.Lng, old.Lat
+		}
+		return tx.Select("name", "phone", "province_code", "city_code", "district_code", "province", "city",
+			"district", "detail", "lng", "lat", "is_default", "update_time").Updates(addr).Error
+	})
+	if err != nil {
+		return err
+	}
+	addr.CreateTime = old.CreateTime
+	s.geocodeAddress(ctx, addr)
+	return nil
+}
+
+// DeleteAddress 删除默认地址时，最近修改的地址成为默认
+func (s *Service) DeleteAddress(ctx context.Context, uid, id int) error {
+	return s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockAddresses(tx, uid); err != nil {
+			return err
+		}
+		var old model.UserAddress
+		err := tx.Where("user_id = ?", uid).Take(&old, id).Error
+		if err == gorm.ErrRecordNotFound {
+			return model.ErrAddressNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err = tx.Delete(&old).Error; err != nil || !old.IsDefault {
+			return err
+		}
+		var next []*model.UserAddress
+		err = tx.Where("user_id = ?", uid).Order("update_time DESC, id DESC").Limit(1).Find(&next).Error
+		if err != nil || len(next) == 0 {
+			return err
+		}
+		return tx.Model(next[0]).UpdateColumn("is_default", true).Error
+	})
+}
+
+// SetDefaultAddress 设为默认，同时取消原默认地址
+func (s *Service) SetDefaultAddress(ctx context.Context, uid, id int) error {
+	return s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids, err := lockAddresses(tx, uid)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, v := range ids {
+			found = found || v == id
+		}
+		if !found {
+			return model.ErrAddressNotFound
+		}
+		if err = clearDefaultAddress(tx, uid); err != nil {
+			return err
+		}
+		return tx.Model(&model.UserAddress{}).Where("id = ?", id).UpdateColumn("is_default", true).Error
+	})
+}
+
+// lockAddresses 锁定用户的全部地址，同一用户的地址修改串行执行
+func lockAddresses(tx *gorm.DB, uid int) ([]int, error) {
+	var ids []int
+	err := tx.Model(&model.UserAddress{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", uid).Pluck("id", &ids).Error
+	return ids, err
+}
+
+func clearDefaultAddress(tx *gorm.DB, uid int) error {
+	return tx.Model(&model.UserAddress{}).Where("user_id = ? AND is_default = ?", uid, true).
+		UpdateColumn("is_default", false).Error
+}
+
+// geocodeAddress 客户端未传坐标(未在地图选点)时解析地址，失败只记录日志，坐标为0
+func (s *Service) geocodeAddress(ctx context.Context, addr *model.UserAddress) {
+	if s.geocoder == nil || addr.Lng != 0 || addr.Lat != 0 {
+		return
+	}
+	c, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	lng, lat, err := s.geocoder.Geocode(c, addr.FullText(), addr.City)
+	if err != nil {
+		logger.FromContext(ctx).Warn("geocoder.Geocode fail", addr.FullText(), err)
+		return
+	}
+	err = s.mysql.WithContext(ctx).Model(addr).UpdateColumns(map[string]any{"lng": lng, "lat": lat}).Error
+	if err != nil {
+		logger.FromContext(ctx).Warn("save address location fail", addr.ID, err)
+		return
+	}
+	addr.Lng, addr.Lat = lng, lat
+}
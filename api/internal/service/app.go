@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"project/model"
+)
+
+func (s *Service) GetAppVersions(ctx context.Context) (map[string]*model.AppVersion, error) {
+	data, err := s.redis.HGetAll(ctx, model.KeyAppVersions).Result()
+	if err != nil {
+		return nil, err
+	}
+	list := make(map[string]*model.AppVersion, len(data))
+	for k, v := range data {
+		var r model.AppVersion
+		if json.Unmarshal([]byte(v), &r) == nil && r.Min != "" {
+			list[k] = &r
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm/clause"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/reqctx"
+	"time"
+)
+
+// RefreshUserToken 签发新token后删除旧token，旧token已失效时由调用方重新登录
+func (s *Service) RefreshUserToken(ctx context.Context, token string, data *proto.UserToken) (string, error) {
+	next, err := s.SetUserToken(ctx, data)
+	if err != nil {
+		return "", err
+	}
+	return next, s.redis.Del(ctx, model.UserTokenKey(token)).Err()
+}
+
+func (s *Service) RevokeUserToken(ctx context.Context, token string) error {
+	return s.redis.Del(ctx, model.UserTokenKey(token)).Err()
+}
+
+// PublishAuthEvent 投递登录凭证事件，失败只记录日志；安全审计需要完整记录，不走可丢弃的埋点
+func (s *Service) PublishAuthEvent(ctx context.Context, e *model.AuthEvent, token string) {
+	e.EventID = uuid.NewV4().String()
+	e.EventTime = time.Now().UnixMilli()
+	e.TraceID, _ = ctx.Value("trace_id").(string)
+	if len(e.UserAgent) > 255 {
+		e.UserAgent = e.UserAgent[:255]
+	}
+	if token != "" {
+		e.TokenHash = TokenHash(token)
+	}
+	b, _ := json.Marshal(e)
+	if err := s.producer.PublishMeta(model.TopicAuth, reqctx.Meta(ctx), b); err != nil {
+		logger.FromContext(ctx).Error("producer.Publish error", e, err)
+	}
+}
+
+// FindAuthEvents 用户最近的登录凭证事件，按时间倒序
+func (s *Service) FindAuthEvents(ctx context.Context, uid, limit int) (list []*model.AuthEvent, err error) {
+	err = s.shards.Read(uid).WithContext(ctx).Where("user_id = ?", uid).
+		Order("event_time DESC").Limit(limit).Find(&list).Error
+	return
+}
+
+// TokenHash 用于关联同一token的事件，不可逆推token
+func TokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// RecordLoginDevice 记录登录设备，返回是否为新设备、新地区；用户首次登录不算新设备
+func (s *Service) RecordLoginDevice(ctx context.Context, d *model.UserDevice) (newDevice, newRegion bool, err error) {
+	db, err := s.shards.Write(d.UserID)
+	if err != nil {
+		return
+	}
+	var known []*model.UserDevice
+	err = db.WithContext(ctx).Select("fingerprint", "region").Where("user_id = ?", d.UserID).
+		Order("last_time DESC").Limit(100).Find(&known).Error
+	if err != nil {
+		return
+	}
+	if len(known) > 0 {
+		newDevice, newRegion = true, d.Region != ""
+		for _, v := range known {
+			if v.Fingerprint == d.Fingerprint {
+				newDevice = false
+			}
+			if v.Region == d.Region {
+				newRegion = false
+			}
+		}
+	}
+	if len(d.UserAgent) > 255 {
+		d.UserAgent = d.UserAgent[:255]
+	}
+	d.LastTime = time.Now()
+	err = db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"device_id", "platform", "region", "ip", "user_agent", "last_time"}),
+	}).Create(d).Error
+	return
+}
+
+// FindUserDevices 用户登录过的设备，按最近登录时间倒序
+func (s *Service) FindUserDevices(ctx context.Context, uid int) (list []*model.UserDevice, err error) {
+	err = s.shards.Read(uid).WithContext(ctx).Where("user_id = ?", uid).Order("last_time DESC").Limit(100).Find(&list).Error
+	return
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+	"time"
+)
+
+// FindBookingResources 开放预约的资源
+func (s *Service) FindBookingResources(ctx context.Context) ([]*model.BookingResource, error) {
+	var list []*model.BookingResource
+	err := s.mysql.WithContext(ctx).Where("status = ?", model.BookingResourceOpen).Order("id").Find(&list).Error
+	return list, err
+}
+
+func (s *Service) findBookingResource(ctx context.Context, id int, open bool) (*model.BookingResource, error) {
+	query := s.mysql.WithContext(ctx).Where("id = ?", id)
+	if open {
+		query = query.Where("status = ?", model.BookingResourceOpen)
+	}
+	var list []*model.BookingResource
+	if err := query.Limit(1).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, model.ErrBookingResource
+	}
+	return list[0], nil
+}
+
+// FindBookingSlots date当天未开始的时段，剩余名额按数据库中有效的预约计算
+func (s *Service) FindBookingSlots(ctx context.Context, resourceID int, date time.Time) ([]*proto.BookingSlot, error) {
+	if _, err := s.findBookingResource(ctx, resourceID, true); err != nil {
+		return nil, err
+	}
+	var tpls []*model.BookingTemplate
+	err := s.mysql.WithContext(ctx).Where("resource_id = ? AND weekday = ?", resourceID, int8(date.Weekday())).
+		Order("start").Find(&tpls).Error
+	if err != nil || len(tpls) == 0 {
+		return []*proto.BookingSlot{}, err
+	}
+	now := time.Now()
+	var rows []*struct {
+		SlotStart time.Time
+		N         int
+	}
+	err = s.mysql.WithContext(ctx).Model(&model.Booking{}).Select("slot_start, COUNT(*) AS n").
+		Where("resource_id = ? AND slot_start >= ? AND slot_start < ?", resourceID, date, date.AddDate(0, 0, 1)).
+		Where("status = ? OR status = ? AND hold_expire > ?", model.BookingConfirmed, model.BookingHeld, now).
+		Group("slot_start").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[int64]int, len(rows))
+	for _, v := range rows {
+		used[v.SlotStart.Unix()] = v.N
+	}
+	list := make([]*proto.BookingSlot, 0, len(tpls))
+	for _, v := range tpls {
+		start, end := v.Slot(date)
+		if !start.After(now) {
+			continue
+		}
+		slot := &proto.BookingSlot{TemplateID: v.ID, Start: start, End: end, Capacity: v.Capacity}
+		if slot.Remaining = v.Capacity - used[start.Unix()]; slot.Remaining < 0 {
+			slot.Remaining = 0
+		}
+		list = append(list, slot)
+	}
+	return list, nil
+}
+
+// ReserveBooking 占用名额并保存为保留状态，保留到期未确认时由script的booking:expire关闭
+// b需设置UserID、ResourceID、TemplateID，date为当天零点
+func (s *Service) ReserveBooking(ctx context.Context, b *model.Booking, date time.Time) error {
+	res, err := s.findBookingResource(ctx, b.ResourceID, true)
+	if err != nil {
+		return err
+	}
+	var tpl model.BookingTemplate
+	err = s.mysql.WithContext(ctx).Where("resource_id = ?", b.ResourceID).Take(&tpl, b.TemplateID).Error
+	if err == gorm.ErrRecordNotFound || err == nil && tpl.Weekday != int8(date.Weekday()) {
+		return model.ErrBookingSlot
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, date.Location())
+	start, end := tpl.Slot(date)
+	if date.Before(today) || !date.Before(today.AddDate(0, 0, res.Days)) || !start.After(now) {
+		return model.ErrBookingSlot
+	}
+	b.ID = 0
+	b.SlotStart, b.SlotEnd = start, end
+	b.Status = model.BookingHeld
+	b.HoldExpire = now.Add(time.Duration(res.HoldMinutes) * time.Minute).Truncate(time.Second)
+	if b.HoldExpire.After(start) {
+		b.HoldExpire = start
+	}
+	key := model.BookingSlotKey(b.ResourceID, start.Unix())
+	if err = s.reserveSlot(ctx, key, b, tpl.Capacity); err != nil {
+		return err
+	}
+	if err = s.mysql.WithContext(ctx).Create(b).Error; err != nil {
+		s.releaseSlot(ctx, b)
+		return err
+	}
+	s.publishBooking(ctx, model.TopicBookingExpire, b, time.Until(b.HoldExpire)+time.Second)
+	return nil
+}
+
+func (s *Service) reserveSlot(ctx context.Context, key string, b *model.Booking, capacity int) error {
+	run := func() (int, error) {
+		return model.ScriptBookingReserve.Run(ctx, s.redis, []string{key},
+			b.UserID, capacity, time.Now().UnixMilli(), b.HoldExpire.UnixMilli()).Int()
+	}
+	ret, err := run()
+	if err == nil && ret == -2 {
+		if err = s.initBookingSlot(ctx, key, b); err == nil {
+			ret, err = run()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	switch ret {
+	case 0:
+		return model.ErrBookingFull
+	case -1:
+		return model.ErrBookingDuplicate
+	case -2:
+		return errors.New("booking slot not initialized: " + key)
+	}
+	return nil
+}
+
+// initBookingSlot 名额key不存在(首次预约或redis数据丢失)时按数据库中有效的预约初始化，slot结束1天后过期
+func (s *Service) initBookingSlot(ctx context.Context, key string, b *model.Booking) error {
+	var list []*model.Booking
+	err := s.mysql.WithContext(ctx).Select("user_id", "status", "hold_expire").
+		Where("resource_id = ? AND slot_start = ?", b.ResourceID, b.SlotStart).
+		Where("status = ? OR status = ? AND hold_expire > ?", model.BookingConfirmed, model.BookingHeld, time.Now()).
+		Find(&list).Error
+	if err != nil {
+		return err
+	}
+	args := make([]any, 0, 2+len(list)*2)
+	args = append(args, b.SlotEnd.Add(24*time.Hour).UnixMilli(), model.BookingConfirmedScore)
+	for _, v := range list {
+		args = append(args, v.UserID, v.SlotScore())
+	}
+	return model.ScriptBookingInit.Run(ctx, s.redis, []string{key}, args...).Err()
+}
+
+// releaseSlot 释放名额，失败只记录日志：保留到期后名额自动失效，已确认的需人工处理
+func (s *Service) releaseSlot(ctx context.Context, b *model.Booking) {
+	key := model.BookingSlotKey(b.ResourceID, b.SlotStart.Unix())
+	if err := model.ScriptBookingRelease.Run(ctx, s.redis, []string{key}, b.UserID, b.SlotScore()).Err(); err != nil {
+		logger.FromContext(ctx).Error("redis.ScriptBookingRelease error", key, err)
+	}
+}
+
+func (s *Service) publishBooking(ctx context.Context, topic string, b *model.Booking, delay time.Duration) {
+	body, _ := json.Marshal(&model.MsgBooking{BookingID: b.ID, SlotStart: b.SlotStart.Unix()})
+	if err := s.delayer.Publish(ctx, topic, body, delay); err != nil {
+		logger.FromContext(ctx).Warn("delayer.Publish fail", topic+" "+strconv.Itoa(b.ID), err)
+	}
+}
+
+func (s *Service) findBooking(ctx context.Context, uid, id int) (*model.Booking, error) {
+	var b model.Booking
+	err := s.mysql.WithContext(ctx).Where("user_id = ?", uid).Take(&b, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, model.ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ConfirmBooking 保留到期前确认，重复确认直接返回；确认后按资源设置延迟投递开始前的提醒
+func (s *Service) ConfirmBooking(ctx context.Context, uid, id int) (*model.Booking, error) {
+	b, err := s.findBooking(ctx, uid, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	switch b.Effective(now) {
+	case model.BookingConfirmed:
+		return b, nil
+	case model.BookingExpired:
+		return nil, model.ErrBookingExpired
+	case model.BookingHeld:
+	default:
+		return nil, model.ErrBookingStatus
+	}
+	key := model.BookingSlotKey(b.ResourceID, b.SlotStart.Unix())
+	hold := b.HoldExpire.UnixMilli()
+	ret, err := model.ScriptBookingConfirm.Run(ctx, s.redis, []string{key},
+		uid, now.UnixMilli(), model.BookingConfirmedScore, hold).Int()
+	if err != nil {
+		return nil, err
+	}
+	if ret == 0 {
+		return nil, model.ErrBookingExpired
+	}
+	opt := s.mysql.WithContext(ctx).Model(b).Where("status = ?", model.BookingHeld).
+		Update("status", model.BookingConfirmed)
+	if opt.Error != nil || opt.RowsAffected == 0 { // 恢复为保留，不占用已取消预约的名额
+		if e := s.redis.ZAddXX(ctx, key, &redis.Z{Score: float64(hold), Member: uid}).Err(); e != nil {
+			logger.FromContext(ctx).Error("redis.ZAddXX error", key, e)
+		}
+		if opt.Error != nil {
+			return nil, opt.Error
+		}
+		return nil, model.ErrBookingStatus
+	}
+	b.Status = model.BookingConfirmed
+	res, err := s.findBookingResource(ctx, b.ResourceID, false)
+	if err != nil {
+		logger.FromContext(ctx).Warn("findBookingResource fail", b.ResourceID, err)
+		return b, nil
+	}
+	if res.RemindBefore > 0 {
+		if at := b.SlotStart.Add(-time.Duration(res.RemindBefore) * time.Minute); at.After(now) {
+			s.publishBooking(ctx, model.TopicBookingRemind, b, time.Until(at))
+		}
+	}
+	return b, nil
+}
+
+// CancelBooking 保留中的预约随时可取消，已确认的需在开始前cancelBefore分钟之前取消
+func (s *Service) CancelBooking(ctx context.Context, uid, id int, reason string) error {
+	b, err := s.findBooking(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	switch b.Effective(now) {
+	case model.BookingHeld:
+	case model.BookingConfirmed:
+		res, err := s.findBookingResource(ctx, b.ResourceID, false)
+		if err != nil {
+			return err
+		}
+		if !now.Before(b.SlotStart.Add(-time.Duration(res.CancelBefore) * time.Minute)) {
+			return model.ErrBookingCancel
+		}
+	default:
+		return model.ErrBookingStatus
+	}
+	opt := s.mysql.WithContext(ctx).Model(b).Where("status = ?", b.Status).Updates(map[string]any{
+		"status":        model.BookingCancelled,
+		"cancel_reason": reason,
+		"operator":      "user:" + strconv.Itoa(uid),
+	})
+	if opt.Error != nil {
+		return opt.Error
+	}
+	if opt.RowsAffected == 0 {
+		return model.ErrBookingStatus
+	}
+	s.releaseSlot(ctx, b)
+	return nil
+}
+
+// FindBookings 用户的预约，按创建时间倒序
+func (s *Service) FindBookings(ctx context.Context, uid, page, size int) ([]*proto.BookingItem, error) {
+	var list []*model.Booking
+	err := s.mysql.WithContext(ctx).Where("user_id = ?", uid).Order("id DESC").
+		Limit(size).Offset(size * (page - 1)).Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return []*proto.BookingItem{}, err
+	}
+	ids := make([]int, 0, len(list))
+	for _, v := range list {
+		ids = append(ids, v.ResourceID)
+	}
+	var resources []*model.BookingResource
+	if err = s.mysql.WithContext(ctx).Select("id", "name").Where("id IN ?", ids).Find(&resources).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(resources))
+	for _, v := range resources {
+		names[v.ID] = v.Name
+	}
+	now := time.Now()
+	res := make([]*proto.BookingItem, len(list))
+	for i, v := range list {
+		v.Status = v.Effective(now)
+		res[i] = &proto.BookingItem{Booking: v, ResourceName: names[v.ResourceID]}
+	}
+	return res, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"project/model"
+	"project/pkg/captcha"
+	"time"
+)
+
+func (s *Service) NewCaptcha(ctx context.Context, kind string) (*captcha.Challenge, error) {
+	ch, err := s.captcha.Issue(ctx, kind)
+	if errors.Is(err, captcha.ErrKind) {
+		return nil, model.ErrCaptchaKind
+	}
+	return ch, err
+}
+
+// VerifyCaptcha 通过后返回一次性票据，受保护的接口在X-Captcha-Ticket请求头中提交
+func (s *Service) VerifyCaptcha(ctx context.Context, id, answer string) (string, error) {
+	ticket, err := s.captcha.Verify(ctx, id, answer)
+	switch {
+	case errors.Is(err, captcha.ErrNotFound):
+		return "", model.ErrCaptchaExpired
+	case errors.Is(err, captcha.ErrMismatch):
+		return "", model.ErrCaptchaWrong
+	}
+	return ticket, err
+}
+
+func (s *Service) RedeemCaptcha(ctx context.Context, ticket string) (bool, error) {
+	return s.captcha.Redeem(ctx, ticket)
+}
+
+// FlagCaptcha 风控标记主体，如ip:1.2.3.4、user:1，ttl内受保护的接口均需验证码
+func (s *Service) FlagCaptcha(ctx context.Context, subject string, ttl time.Duration) error {
+	return s.captcha.Flag(ctx, subject, ttl)
+}
+
+// CaptchaRequired 主体已被风控标记，或limit大于0且窗口内请求次数超过limit时需要验证码
+func (s *Service) CaptchaRequired(ctx context.Context, subject string, limit int64, window time.Duration) (bool, error) {
+	flagged, err := s.captcha.Flagged(ctx, subject)
+	if err != nil || flagged || limit <= 0 {
+		return flagged, err
+	}
+	return s.captcha.Hit(ctx, subject, limit, window)
+}
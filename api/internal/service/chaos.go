@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"project/model"
+)
+
+func (s *Service) GetChaosRules(ctx context.Context) (map[string]*model.ChaosRule, error) {
+	data, err := s.redis.HGetAll(ctx, model.KeyChaosRules).Result()
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[string]*model.ChaosRule, len(data))
+	for k, v := range data {
+		var r model.ChaosRule
+		if json.Unmarshal([]byte(v), &r) == nil && r.Percent > 0 {
+			rules[k] = &r
+		}
+	}
+	return rules, nil
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/db"
+	"project/pkg/logger"
+	"project/pkg/reqctx"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+var errChatDup = errors.New("chat: duplicate client_msg_id")
+
+// OpenSingleChat 两人之间的单聊，已存在时直接返回
+func (s *Service) OpenSingleChat(ctx context.Context, uid, peer int) (*model.ChatConversation, error) {
+	a, b := uid, peer
+	if a > b {
+		a, b = b, a
+	}
+	key := strconv.Itoa(a) + ":" + strconv.Itoa(b)
+	var list []*model.ChatConversation
+	err := s.mysql.WithContext(ctx).Where("pair_key = ?", key).Limit(1).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	if err = s.checkChatUsers(ctx, []int{peer}); err != nil {
+		return nil, err
+	}
+	conv := &model.ChatConversation{Type: model.ChatSingle, PairKey: &key, OwnerID: uid}
+	err = s.createChat(ctx, conv, []int{a, b})
+	if db.IsDuplicateKey(err) { // 双方同时发起
+		err = s.mysql.WithContext(ctx).Take(conv, "pair_key = ?", key).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return conv, nil
+}
+
+// CreateGroupChat 创建群聊，创建者自动加入
+func (s *Service) CreateGroupChat(ctx context.Context, uid int, name string, members []int) (*model.ChatConversation, error) {
+	uids := []int{uid}
+	seen := map[int]bool{uid: true}
+	for _, v := range members {
+		if !seen[v] {
+			seen[v] = true
+			uids = append(uids, v)
+		}
+	}
+	if len(uids) > model.ChatGroupMax {
+		return nil, model.ErrChatUser
+	}
+	if err := s.checkChatUsers(ctx, uids[1:]); err != nil {
+		return nil, err
+	}
+	conv := &model.ChatConversation{Type: model.ChatGroup, Name: name, OwnerID: uid}
+	if err := s.createChat(ctx, conv, uids); err != nil {
+		return nil, err
+	}
+	return conv, nil
+}
+
+func (s *Service) checkChatUsers(ctx context.Context, uids []int) error {
+	var n int64
+	err := s.mysql.WithContext(ctx).Model(&model.User{}).Where("id IN ?", uids).Count(&n).Error
+	if err == nil && n != int64(len(uids)) {
+		err = model.ErrChatUser
+	}
+	return err
+}
+
+func (s *Service) createChat(ctx context.Context, conv *model.ChatConversation, uids []int) error {
+	return s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(conv).Error; err != nil {
+			return err
+		}
+		members := make([]*model.ChatMember, len(uids))
+		for i, v := range uids {
+			members[i] = &model.ChatMember{ConversationID: conv.ID, UserID: v}
+		}
+		return tx.Create(members).Error
+	})
+}
+
+// FindChatConversations 用户参与的会话，按最后一条消息时间倒序
+func (s *Service) FindChatConversations(ctx context.Context, uid, page, size int) ([]*proto.ChatConversationItem, error) {
+	var rows []*struct {
+		model.ChatConversation
+		ReadSeq int64
+	}
+	err := s.mysql.WithContext(ctx).Table("chat_member m").
+		Select("c.*, m.read_seq").
+		Joins("JOIN chat_conversation c ON c.id = m.conversation_id").
+		Where("m.user_id = ?", uid).
+		Order("c.update_time DESC, c.id DESC").Limit(size).Offset(size * (page - 1)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*proto.ChatConversationItem, len(rows))
+	last := make([][]any, 0, len(rows))
+	index := make(map[int]*proto.ChatConversationItem, len(rows))
+	for i, v := range rows {
+		conv := v.ChatConversation
+		list[i] = &proto.ChatConversationItem{ChatConversation: &conv, ReadSeq: v.ReadSeq, Unread: conv.LastSeq - v.ReadSeq}
+		index[conv.ID] = list[i]
+		if conv.LastSeq > 0 {
+			last = append(last, []any{conv.ID, conv.LastSeq})
+		}
+	}
+	if len(last) == 0 {
+		return list, nil
+	}
+	var msgs []*model.ChatMessage
+	err = s.mysql.WithContext(ctx).Where("(conversation_id, seq) IN ?", last).Find(&msgs).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range msgs {
+		index[v.ConversationID].LastMessage = v
+	}
+	return list, nil
+}
+
+// FindChatMembers 会话成员和各自的已读位置，非成员返回ErrChatNotMember
+func (s *Service) FindChatMembers(ctx context.Context, uid, cid int) ([]*model.ChatMember, error) {
+	var list []*model.ChatMember
+	err := s.mysql.WithContext(ctx).Where("conversation_id = ?", cid).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range list {
+		if v.UserID == uid {
+			return list, nil
+		}
+	}
+	return nil, model.ErrChatNotMember
+}
+
+// SendChatMessage 加行锁递增会话的seq后保存消息，发送者的已读位置同时前移；client_msg_id重复时返回已保存的消息且不再投递
+func (s *Service) SendChatMessage(ctx context.Context, msg *model.ChatMessage) (*model.ChatMessage, error) {
+	if old, err := s.findChatMessage(ctx, msg.SenderID, msg.ClientMsgID); err != nil || old != nil {
+		return old, err
+	}
+	members, err := s.FindChatMembers(ctx, msg.SenderID, msg.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	var conv model.ChatConversation
+	err = s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.ChatConversation{}).Where("id = ?", msg.ConversationID).
+			Update("last_seq", gorm.Expr("last_seq + 1")).Error
+		if err != nil {
+			return err
+		}
+		if err = tx.Take(&conv, msg.ConversationID).Error; err != nil {
+			return err
+		}
+		msg.ID = 0
+		msg.Seq = conv.LastSeq
+		err = tx.Create(msg).Error
+		if db.IsDuplicateKey(err) {
+			return errChatDup // 回滚seq，并发重发时返回先保存的消息
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.ChatMember{}).
+			Where("conversation_id = ? AND user_id = ?", msg.ConversationID, msg.SenderID).
+			Update("read_seq", msg.Seq).Error
+	})
+	if err == errChatDup {
+		return s.findChatMessage(ctx, msg.SenderID, msg.ClientMsgID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.deliverChat(ctx, &conv, members, msg)
+	return msg, nil
+}
+
+func (s *Service) findChatMessage(ctx context.Context, sender int, clientMsgID string) (*model.ChatMessage, error) {
+	var list []*model.ChatMessage
+	err := s.mysql.WithContext(ctx).Where("sender_id = ? AND client_msg_id = ?", sender, clientMsgID).
+		Limit(1).Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// deliverChat 在线成员经网关投递，离线成员发送推送；消息已保存，投递失败只记录日志，客户端重连后按seq拉取
+func (s *Service) deliverChat(ctx context.Context, conv *model.ChatConversation, members []*model.ChatMember, msg *model.ChatMessage) {
+	uids := make([]int, len(members))
+	for i, v := range members {
+		uids[i] = v.UserID
+	}
+	online, err := s.presence.Online(ctx, uids)
+	if err != nil {
+		logger.FromContext(ctx).Warn("presence.Online fail", msg.ConversationID, err)
+	}
+	var recipients, offline []int
+	for _, uid := range uids {
+		if _, ok := online[uid]; ok {
+			recipients = append(recipients, uid)
+		} else if uid != msg.SenderID {
+			offline = append(offline, uid)
+		}
+	}
+	if len(recipients) > 0 {
+		s.publishChat(ctx, &model.MsgChat{
+			Event:          model.ChatEventMessage,
+			UserIDs:        recipients,
+			ConversationID: msg.ConversationID,
+			Message:        msg,
+		})
+	}
+	if len(offline) == 0 {
+		return
+	}
+	title := conv.Name
+	if sender, err := s.FindUserByID(ctx, msg.SenderID); err == nil && sender.Nickname != "" {
+		if conv.Type == model.ChatSingle {
+			title = sender.Nickname
+		} else {
+			title += " " + sender.Nickname
+		}
+	}
+	if title == "" {
+		title = "新消息"
+	}
+	push := &model.MsgPush{
+		Category: model.PushCategoryReminder,
+		Title:    title,
+		Body:     chatPreview(msg),
+		Data:     map[string]string{"conversation_id": strconv.Itoa(msg.ConversationID), "seq": strconv.FormatInt(msg.Seq, 10)},
+	}
+	for _, uid := range offline {
+		p := *push
+		p.UserID = uid
+		if _, err = s.Push(ctx, &p); err != nil {
+			logger.FromContext(ctx).Warn("service.Push fail", &p, err)
+		}
+	}
+}
+
+// chatPreview 推送正文，文本最多显示50个字
+func chatPreview(msg *model.ChatMessage) string {
+	if msg.Type == model.ChatMsgImage {
+		return "[图片]"
+	}
+	if utf8.RuneCountInString(msg.Content) <= 50 {
+		return msg.Content
+	}
+	return string([]rune(msg.Content)[:50]) + "…"
+}
+
+func (s *Service) publishChat(ctx context.Context, msg *model.MsgChat) {
+	msg.Time = time.Now().Unix()
+	b, _ := json.Marshal(msg)
+	if err := s.producer.PublishMeta(model.TopicChat, reqctx.Meta(ctx), b); err != nil {
+		logger.FromContext(ctx).Warn("producer.PublishMeta fail", msg, err)
+	}
+}
+
+// FindChatMessages 历史消息：after>0时按seq正序返回之后的消息(重连后补齐)，否则按seq倒序返回before之前(为0时最新)的消息
+func (s *Service) FindChatMessages(ctx context.Context, uid, cid int, before, after int64, size int) ([]*model.ChatMessage, error) {
+	if _, err := s.FindChatMembers(ctx, uid, cid); err != nil {
+		return nil, err
+	}
+	query := s.mysql.WithContext(ctx).Where("conversation_id = ?", cid).Limit(size)
+	if after > 0 {
+		query = query.Where("seq > ?", after).Order("seq")
+	} else {
+		if before > 0 {
+			query = query.Where("seq < ?", before)
+		}
+		query = query.Order("seq DESC")
+	}
+	var list []*model.ChatMessage
+	err := query.Find(&list).Error
+	return list, err
+}
+
+// ReadChat 已读回执，seq不超过会话的最新消息且只前移；前移后通知在线的其他成员
+func (s *Service) ReadChat(ctx context.Context, uid, cid int, seq int64) (bool, error) {
+	members, err := s.FindChatMembers(ctx, uid, cid)
+	if err != nil {
+		return false, err
+	}
+	opt := s.mysql.WithContext(ctx).Model(&model.ChatMember{}).
+		Where("conversation_id = ? AND user_id = ? AND read_seq < ?", cid, uid, seq).
+		Where("? <= (SELECT last_seq FROM chat_conversation WHERE id = ?)", seq, cid).
+		Update("read_seq", seq)
+	if opt.Error != nil || opt.RowsAffected == 0 {
+		return false, opt.Error
+	}
+	uids := make([]int, 0, len(members))
+	for _, v := range members {
+		uids = append(uids, v.UserID)
+	}
+	online, err := s.presence.Online(ctx, uids)
+	if err != nil {
+		logger.FromContext(ctx).Warn("presence.Online fail", cid, err)
+		return true, nil
+	}
+	recipients := make([]int, 0, len(online))
+	for v := range online {
+		recipients = append(recipients, v)
+	}
+	if len(recipients) > 0 {
+		sort.Ints(recipients)
+		s.publishChat(ctx, &model.MsgChat{
+			Event:          model.ChatEventRead,
+			UserIDs:        recipients,
+			ConversationID: cid,
+			ReaderID:       uid,
+			Seq:            seq,
+		})
+	}
+	return true, nil
+}
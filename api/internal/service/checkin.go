@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+	"sync"
+	"time"
+	_ "time/tzdata" // 部署环境没有时区数据库时使用内嵌的数据
+)
+
+// CheckinConfig 签到奖励：每天points积分，连续签到第day天额外奖励bonus；cycle大于0时连续天数按周期循环计算奖励(如7天一轮)
+type CheckinConfig struct {
+	Timezone string // 客户端未传时区时使用，为空使用服务器时区
+	Points   int64
+	Cycle    int
+	Bonus    []*CheckinBonus
+}
+
+type CheckinBonus struct {
+	Day    int
+	Points int64
+}
+
+// Reward 连续签到第streak天的奖励积分
+func (c *CheckinConfig) Reward(streak int) int64 {
+	day := streak
+	if c.Cycle > 0 {
+		day = (streak-1)%c.Cycle + 1
+	}
+	points := c.Points
+	for _, v := range c.Bonus {
+		if v.Day == day {
+			points += v.Points
+		}
+	}
+	return points
+}
+
+// checkinPreview 签到状态中展示接下来多少天的奖励
+const checkinPreview = 7
+
+var locations sync.Map // 时区名 -> *time.Location
+
+// checkinLocation tz为空时使用默认时区，返回时区和保存到签到记录的时区名
+func (s *Service) checkinLocation(tz string) (*time.Location, string) {
+	if tz == "" {
+		tz = s.checkin.Timezone
+	}
+	if v, ok := locations.Load(tz); ok {
+		return v.(*time.Location), tz
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.Local, "Local"
+	}
+	locations.Store(tz, loc)
+	return loc, tz
+}
+
+// Checkin 签到并发放积分，今天已签到时返回当天的记录；积分记账失败时返回错误，重复签到时补发
+func (s *Service) Checkin(ctx context.Context, uid int, tz string) (*proto.CheckinResult, error) {
+	loc, tz := s.checkinLocation(tz)
+	now := time.Now()
+	date := model.CheckinDate(now, loc)
+	var data *model.CheckinLog
+	isNew := false
+	err := s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		st := &model.UserCheckin{UserID: uid, LastTime: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(st).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(st, uid).Error; err != nil {
+			return err
+		}
+		last, _ := s.checkinLocation(st.Timezone)
+		if !st.Due(date, now, last) {
+			data = &model.CheckinLog{}
+			return tx.Take(data, "user_id = ? AND date = ?", uid, st.LastDate).Error
+		}
+		streak := 1
+		if st.Continued(date, now, last) {
+			streak = st.Streak + 1
+		}
+		data = &model.CheckinLog{UserID: uid, Date: date, Timezone: tz, Streak: streak, Points: s.checkin.Reward(streak)}
+		data.Rewarded = data.Points <= 0
+		if err := tx.Create(data).Error; err != nil {
+			return err
+		}
+		isNew = true
+		return tx.Model(st).Updates(map[string]any{
+			"last_date": date,
+			"last_time": now,
+			"timezone":  tz,
+			"streak":    streak,
+			"total":     gorm.Expr("total + 1"),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !data.Rewarded {
+		remark := "连续签到" + strconv.Itoa(data.Streak) + "天"
+		_, err = s.Credit(ctx, uid, model.AssetPoints, data.Points, model.CheckinRefNo(uid, data.Date), "checkin", remark)
+		if err != nil {
+			return nil, err
+		}
+		data.Rewarded = true
+		if err = s.mysql.WithContext(ctx).Model(data).Update("rewarded", true).Error; err != nil {
+			// 已记账，重复签到时按幂等号返回原分录
+			logger.FromContext(ctx).Warn("update checkin rewarded fail", data.ID, err)
+		}
+	}
+	return &proto.CheckinResult{CheckinLog: data, New: isNew}, nil
+}
+
+// FindCheckinStatus tz时区的签到状态和接下来连续签到的奖励
+func (s *Service) FindCheckinStatus(ctx context.Context, uid int, tz string) (*proto.CheckinStatus, error) {
+	loc, _ := s.checkinLocation(tz)
+	now := time.Now()
+	var list []*model.UserCheckin
+	if err := s.mysql.WithContext(ctx).Where("user_id = ?", uid).Limit(1).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	data := &proto.CheckinStatus{Date: model.CheckinDate(now, loc)}
+	next := data.Date
+	if len(list) > 0 && list[0].LastDate != "" {
+		st := list[0]
+		last, _ := s.checkinLocation(st.Timezone)
+		data.Total = st.Total
+		data.Checked = !st.Due(data.Date, now, last)
+		if data.Checked || st.Continued(data.Date, now, last) {
+			data.Streak = st.Streak
+		}
+		if data.Checked {
+			next = model.CheckinNextDate(st.LastDate)
+		}
+	}
+	data.Rewards = make([]*proto.CheckinReward, checkinPreview)
+	for i := range data.Rewards {
+		streak := data.Streak + i + 1
+		data.Rewards[i] = &proto.CheckinReward{Date: next, Streak: streak, Points: s.checkin.Reward(streak)}
+		next = model.CheckinNextDate(next)
+	}
+	return data, nil
+}
+
+// FindCheckinCalendar month(2006-01)的签到记录，按日期升序
+func (s *Service) FindCheckinCalendar(ctx context.Context, uid int, month string) ([]*model.CheckinLog, error) {
+	var list []*model.CheckinLog
+	err := s.mysql.WithContext(ctx).Where("user_id = ? AND date >= ? AND date <= ?", uid, month+"-01", month+"-31").
+		Order("date").Find(&list).Error
+	return list, err
+}
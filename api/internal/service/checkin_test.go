@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"project/model"
+	"project/pkg/db/dbtest"
+	"testing"
+)
+
+// 同一天多个用户签到，各自的积分分录(含系统账户的对手分录)不能冲突
+func TestCheckinSameDay(t *testing.T) {
+	s := &Service{
+		mysql:   dbtest.Mysql(t, "user_checkin", "user_checkin_log", "ledger_account", "ledger_entry"),
+		checkin: CheckinConfig{Timezone: "Asia/Shanghai", Points: 10},
+	}
+	ctx := context.Background()
+	for _, uid := range []int{1, 2} {
+		r, err := s.Checkin(ctx, uid, "")
+		if err != nil {
+			t.Fatalf("user %d: %v", uid, err)
+		}
+		if !r.New || !r.Rewarded || r.Points != 10 {
+			t.Fatalf("user %d: unexpected result %+v", uid, r.CheckinLog)
+		}
+		// 重复签到返回当天的记录，不重复记账
+		if r, err = s.Checkin(ctx, uid, ""); err != nil || r.New {
+			t.Fatalf("user %d: repeat checkin %+v, %v", uid, r, err)
+		}
+		accounts, err := s.FindLedgerAccounts(ctx, uid)
+		if err != nil || accounts[model.AssetPoints] != 10 {
+			t.Fatalf("user %d: points %v, %v", uid, accounts, err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"project/model"
+	"project/pkg/logger"
+	"time"
+)
+
+// GetContent 已发布的运营内容，redis缓存1小时，cms发布后删除缓存；未发布的缓存1分钟防止穿透
+func (s *Service) GetContent(ctx context.Context, key string) (*model.Content, error) {
+	cacheKey := model.ContentKey(key)
+	val, err, _ := s.single.Do(cacheKey, func() (any, error) {
+		b, err := s.redis.Get(ctx, cacheKey).Bytes()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		var res model.Content
+		if len(b) > 0 {
+			return &res, json.Unmarshal(b, &res)
+		}
+		err = s.mysql.WithContext(ctx).Select("key", "version", "body", "publish_time").
+			Where("`key` = ? AND version > 0", key).Take(&res).Error
+		ttl := time.Hour
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res = model.Content{Key: key}
+			ttl, err = time.Minute, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		b, _ = json.Marshal(&res)
+		if err := s.redis.Set(ctx, cacheKey, b, ttl).Err(); err != nil {
+			logger.FromContext(ctx).Error("redis.Set error", cacheKey, err)
+		}
+		return &res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := val.(*model.Content)
+	if res.Version == 0 {
+		return nil, model.ErrContentNotFound
+	}
+	return res, nil
+}
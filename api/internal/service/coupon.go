@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"project/pkg/logger"
+	"time"
+)
+
+// ClaimableCoupons 领取时间内的券模板
+func (s *Service) ClaimableCoupons(ctx context.Context) (list []*model.CouponTemplate, err error) {
+	now := time.Now().Unix()
+	err = s.mysql.WithContext(ctx).
+		Where("status = ? AND begin_time <= ? AND end_time > ?", model.StatusOn, now, now).
+		Order("id DESC").Find(&list).Error
+	return
+}
+
+// ClaimCoupon 在redis中原子扣减库存和校验限领，入库失败时归还
+func (s *Service) ClaimCoupon(ctx context.Context, uid, templateID int) (*model.Coupon, error) {
+	var tpl model.CouponTemplate
+	err := s.mysql.WithContext(ctx).Take(&tpl, "id = ? AND status = ?", templateID, model.StatusOn).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, model.ErrCouponNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if now.Unix() < tpl.BeginTime || now.Unix() >= tpl.EndTime {
+		return nil, model.ErrCouponNotStart
+	}
+
+	keys := []string{model.CouponStockKey(tpl.ID), model.CouponUserKey(tpl.ID)}
+	ret, err := model.ScriptCouponClaim.Run(ctx, s.redis, keys, uid, tpl.PerUser).Int()
+	if err != nil {
+		return nil, err
+	}
+	switch ret {
+	case 0:
+		return nil, model.ErrCouponSoldOut
+	case -1:
+		return nil, model.ErrCouponLimit
+	case -2:
+		return nil, model.ErrCouponNotStart
+	}
+
+	coupon := &model.Coupon{
+		TemplateID: tpl.ID,
+		UserID:     uid,
+		Status:     model.CouponUnused,
+	}
+	coupon.BeginTime, coupon.EndTime = tpl.Validity(now)
+	if err = s.mysql.WithContext(ctx).Create(coupon).Error; err != nil {
+		if e := model.ScriptCouponRollback.Run(ctx, s.redis, keys, uid).Err(); e != nil {
+			logger.FromContext(ctx).Error("redis.ScriptCouponRollback error", keys, e)
+		}
+		return nil, err
+	}
+	coupon.Template = &tpl
+	return coupon, nil
+}
+
+func (s *Service) FindUserCoupons(ctx context.Context, uid int, status int8) (list []*model.Coupon, err error) {
+	query := s.mysql.WithContext(ctx).Preload("Template").Where("user_id = ?", uid)
+	if status != 0 {
+		query = query.Where("status = ?", status)
+	}
+	err = query.Order("id DESC").Limit(200).Find(&list).Error
+	return
+}
+
+// PriceCoupon 校验券归属、状态、有效期和门槛，返回可减免金额
+func (s *Service) PriceCoupon(ctx context.Context, uid, couponID int, amount int64) (int64, error) {
+	var coupon model.Coupon
+	err := s.mysql.WithContext(ctx).Preload("Template").
+		Take(&coupon, "id = ? AND user_id = ?", couponID, uid).Error
+	if err == gorm.ErrRecordNotFound {
+		return 0, model.ErrCouponNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !coupon.Usable(time.Now().Unix()) {
+		return 0, model.ErrCouponInvalid
+	}
+	d := coupon.Template.Discount(amount)
+	if d == 0 {
+		return 0, model.ErrCouponUnmet
+	}
+	return d, nil
+}
+
+// redeemCoupon 在发起支付的事务中锁定并核销券，返回减免金额
+func redeemCoupon(tx *gorm.DB, order *model.Order, couponID int) (int64, error) {
+	var coupon model.Coupon
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Template").
+		Take(&coupon, "id = ? AND user_id = ?", couponID, order.UserID).Error
+	if err == gorm.ErrRecordNotFound {
+		return 0, model.ErrCouponNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().Unix()
+	if !coupon.Usable(now) {
+		return 0, model.ErrCouponInvalid
+	}
+	d := coupon.Template.Discount(order.Amount)
+	if d == 0 {
+		return 0, model.ErrCouponUnmet
+	}
+	err = tx.Model(&coupon).Updates(map[string]any{
+		"status":   model.CouponUsed,
+		"order_no": order.OrderNo,
+		"use_time": now,
+	}).Error
+	return d, err
+}
+
+// ReleaseCoupon 订单取消后退回已核销的券
+func (s *Service) ReleaseCoupon(ctx context.Context, orderNo string) error {
+	return s.mysql.WithContext(ctx).Model(&model.Coupon{}).
+		Where("order_no = ? AND status = ?", orderNo, model.CouponUsed).
+		Updates(map[string]any{
+			"status":   model.CouponUnused,
+			"order_no": "",
+			"use_time": 0,
+		}).Error
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"project/model"
+	"project/pkg/db/dbtest"
+	"testing"
+	"time"
+)
+
+// 发起支付时核销优惠券，取消后退回
+func TestSetOrderProviderCoupon(t *testing.T) {
+	s := &Service{mysql: dbtest.Mysql(t, "order_info", "coupon", "coupon_template")}
+	ctx := context.Background()
+	now := time.Now().Unix()
+	tpl := &model.CouponTemplate{Name: "满100减20", Type: model.CouponFixed, Value: 2000, Threshold: 10000, Status: model.StatusOn}
+	if err := s.mysql.Create(tpl).Error; err != nil {
+		t.Fatal(err)
+	}
+	coupons := []*model.Coupon{
+		{TemplateID: tpl.ID, UserID: 1, Status: model.CouponUnused, BeginTime: now - 60, EndTime: now + 3600},
+		{TemplateID: tpl.ID, UserID: 1, Status: model.CouponUnused, BeginTime: now - 60, EndTime: now + 3600},
+		{TemplateID: tpl.ID, UserID: 2, Status: model.CouponUnused, BeginTime: now - 60, EndTime: now + 3600},
+	}
+	if err := s.mysql.Create(coupons).Error; err != nil {
+		t.Fatal(err)
+	}
+	order := &model.Order{OrderNo: "T1", UserID: 1, Amount: 15000, Status: model.OrderCreated}
+	if err := s.mysql.Create(order).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 其他用户的券
+	if _, err := s.SetOrderProvider(ctx, order, "wxpay", coupons[2].ID); err != model.ErrCouponNotFound {
+		t.Fatalf("other user's coupon: %v", err)
+	}
+	ok, err := s.SetOrderProvider(ctx, order, "wxpay", coupons[0].ID)
+	if err != nil || !ok || order.Amount != 13000 || order.Discount != 2000 {
+		t.Fatalf("redeem: %v, %v, %+v", ok, err, order)
+	}
+	saved, _ := s.FindOrderByNo(ctx, "T1")
+	if saved.Amount != 13000 || saved.CouponID != coupons[0].ID {
+		t.Fatalf("saved order %+v", saved)
+	}
+	var c model.Coupon
+	s.mysql.Take(&c, coupons[0].ID)
+	if c.Status != model.CouponUsed || c.OrderNo != "T1" {
+		t.Fatalf("coupon not redeemed %+v", c)
+	}
+	// 已发起支付后不能再换券
+	if _, err = s.SetOrderProvider(ctx, saved, "alipay", coupons[1].ID); err != model.ErrCouponOrder {
+		t.Fatalf("second coupon: %v", err)
+	}
+
+	if err = s.ReleaseCoupon(ctx, "T1"); err != nil {
+		t.Fatal(err)
+	}
+	s.mysql.Take(&c, coupons[0].ID)
+	if c.Status != model.CouponUnused || c.OrderNo != "" {
+		t.Fatalf("coupon not released %+v", c)
+	}
+}
@@ -47,5 +47,27 @@ func (s *Service) GetBannersByCity(ctx context.Context, city string) ([]*model.B
 
 //func (s *Service) PushMessage(_ context.Context, data *model.MsgExample) error {
 //	b, _ := json.Marshal(data)
-//	return s.nsq.Publish(model.TopicExample, b)
+//	return s.producer.Publish(model.TopicExample, b)
 //}
+
+const syncLimit = 500
+
+// SyncBannersByCity 按(update_time, id)游标增量查询，包含已软删除的记录，返回本次同步的游标
+// 游标id为0时包含since同一秒内的全部记录
+func (s *Service) SyncBannersByCity(ctx context.Context, city string, since time.Time,
+	sinceID int) (list []*model.Banner, syncTime time.Time, syncID int, hasMore bool, err error) {
+	if _, ok := model.Cities[city]; !ok {
+		city = model.DefaultCity
+	}
+	syncTime = time.Now().Add(-time.Second) // 预留1秒，避免同一秒内的更新被跳过
+	err = s.mysql.WithContext(ctx).Unscoped().
+		Where("city = ? AND (update_time > ? OR update_time = ? AND id > ?) AND update_time < ?",
+			city, since, since, sinceID, syncTime).
+		Order("update_time, id").Limit(syncLimit).Find(&list).Error
+	if err == nil && len(list) == syncLimit {
+		hasMore = true
+		syncTime = list[len(list)-1].UpdateTime
+		syncID = list[len(list)-1].ID
+	}
+	return
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/saga"
+	"time"
+)
+
+// ExchangeItem 可用积分兑换的商品，库存沿用sku_stock
+type ExchangeItem struct {
+	SkuID   int `mapstructure:"skuId"`
+	Points  int64
+	Subject string
+}
+
+// exchangeData 积分兑换的saga数据，以订单号作为各步骤的幂等号
+type exchangeData struct {
+	UserID   int    `json:"user_id"`
+	SkuID    int    `json:"sku_id"`
+	Quantity int    `json:"quantity"`
+	Points   int64  `json:"points"`
+	Subject  string `json:"subject"`
+}
+
+// newExchangeSaga 预扣库存 → 扣积分 → 创建已支付订单，失败时倒序回补积分和库存
+func (s *Service) newExchangeSaga() *saga.Saga[exchangeData] {
+	return saga.New[exchangeData](saga.NewStore(s.mysql), "points_exchange",
+		&saga.Step[exchangeData]{
+			Name: "stock",
+			Do: func(ctx context.Context, orderNo string, d *exchangeData) error {
+				return s.DeductStock(ctx, orderNo, map[int]int{d.SkuID: d.Quantity})
+			},
+			Compensate: func(ctx context.Context, orderNo string, d *exchangeData) error {
+				return s.RestockOrder(ctx, orderNo, []int{d.SkuID})
+			},
+		},
+		&saga.Step[exchangeData]{
+			Name: "charge",
+			Do: func(ctx context.Context, orderNo string, d *exchangeData) error {
+				_, err := s.Debit(ctx, d.UserID, model.AssetPoints, d.Points, orderNo, "exchange", d.Subject)
+				return err
+			},
+			Compensate: func(ctx context.Context, orderNo string, d *exchangeData) error {
+				_, err := s.Credit(ctx, d.UserID, model.AssetPoints, d.Points, orderNo+":refund", "exchange_refund", d.Subject)
+				return err
+			},
+		},
+		&saga.Step[exchangeData]{
+			Name: "order",
+			Do: func(ctx context.Context, orderNo string, d *exchangeData) error {
+				err := s.mysql.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&model.Order{
+					OrderNo:  orderNo,
+					UserID:   d.UserID,
+					Subject:  d.Subject,
+					Provider: model.OrderProviderPoints,
+					Status:   model.OrderPaid,
+					PayTime:  time.Now().Unix(),
+				}).Error
+				if err == nil {
+					s.clearStockHold(ctx, orderNo)
+				}
+				return err
+			},
+		},
+	)
+}
+
+// ExchangePoints 积分兑换商品，返回订单号；失败时已完成的步骤已补偿或由ResumeSagas继续补偿
+func (s *Service) ExchangePoints(ctx context.Context, uid, skuID, quantity int) (string, error) {
+	item, ok := s.exchangeItems[skuID]
+	if !ok {
+		return "", model.ErrExchangeNotFound
+	}
+	orderNo, err := s.ids.NextString("E")
+	if err != nil {
+		return "", err
+	}
+	err = s.exchange.Start(ctx, orderNo, &exchangeData{
+		UserID:   uid,
+		SkuID:    skuID,
+		Quantity: quantity,
+		Points:   item.Points * int64(quantity),
+		Subject:  item.Subject,
+	})
+	var biz *model.BizError
+	if errors.As(err, &biz) {
+		return "", biz
+	}
+	return orderNo, err
+}
+
+// ResumeSagas 定期继续执行中断(进程退出)或补偿失败的saga，多实例运行时按更新时间抢占
+func (s *Service) ResumeSagas(ctx context.Context) {
+	tick := time.NewTicker(time.Minute)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+		n, err := s.exchange.Resume(ctx, time.Minute, 100)
+		if err != nil {
+			logger.FromContext(ctx).Error("saga.Resume error", "points_exchange", err)
+		} else if n > 0 {
+			logger.FromContext(ctx).Info("saga.Resume", "points_exchange", n)
+		}
+	}
+}
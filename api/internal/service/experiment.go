@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"hash/fnv"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+	"time"
+)
+
+func (s *Service) AllExperiments(ctx context.Context) ([]*model.Experiment, error) {
+	val, err, _ := s.single.Do(model.KeyExperiments, func() (any, error) {
+		b, err := s.redis.Get(ctx, model.KeyExperiments).Bytes()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		var res []*model.Experiment
+		if len(b) > 0 {
+			err = json.Unmarshal(b, &res)
+		} else {
+			err = s.mysql.WithContext(ctx).Where("status = ?", model.StatusOn).Find(&res).Error
+			if err == nil {
+				b, _ = json.Marshal(res)
+				if err := s.redis.Set(ctx, model.KeyExperiments, b, 5*time.Minute).Err(); err != nil {
+					logger.FromContext(ctx).Error("redis.Set error", model.KeyExperiments, err)
+				}
+			}
+		}
+		return res, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return val.([]*model.Experiment), nil
+}
+
+// AssignVariant 按实验key和用户标识确定性分桶，未进入实验返回空字符串
+func AssignVariant(e *model.Experiment, unit string) string {
+	h := fnv.New32a()
+	h.Write([]byte(e.Key))
+	h.Write([]byte{':'})
+	h.Write([]byte(unit))
+	sum := h.Sum32()
+	if int(sum%10000) >= e.Traffic {
+		return ""
+	}
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return ""
+	}
+	h.Write([]byte(strconv.Itoa(int(sum)))) // 二次哈希，避免流量比例与组内分布相关
+	n := int(h.Sum32() % uint32(total))
+	for _, v := range e.Variants {
+		if n < v.Weight {
+			return v.Name
+		}
+		n -= v.Weight
+	}
+	return ""
+}
+
+func (s *Service) PushExposure(_ context.Context, data []*model.MsgExposure) error {
+	body := make([][]byte, 0, len(data))
+	for _, d := range data {
+		b, _ := json.Marshal(d)
+		body = append(body, b)
+	}
+	return s.producer.MultiPublish(model.TopicExposure, body)
+}
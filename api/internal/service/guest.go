@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	uuid "github.com/satori/go.uuid"
+	"project/api/internal/proto"
+	"project/model"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 游客会话：未登录设备领取游客凭证浏览和加购，微信登录时带上游客凭证合并购物车
+
+const (
+	GuestTokenPrefix = "g." // 与登录凭证区分，AuthCheck据此提示需要登录
+	GuestTTL         = 30 * 24 * time.Hour
+	GuestIPLimit     = 60 // 每个IP每小时领取凭证的次数，同一出口IP下可能有多台设备
+	GuestDeviceLimit = 10 // 每台设备每小时领取凭证的次数
+	CartMaxItems     = 50
+	CartMaxQuantity  = 99
+	cartTTL          = 30 * 24 * time.Hour
+)
+
+// SetGuestToken 按IP和设备限制每小时的领取次数；调用方持有的凭证old仍有效且属于同一设备时顺延并返回，否则签发新凭证
+// device_id由客户端生成，不能只凭device_id返回已有的凭证
+func (s *Service) SetGuestToken(ctx context.Context, deviceID, ip, old string) (string, error) {
+	if err := s.limitGuest(ctx, deviceID, ip); err != nil {
+		return "", err
+	}
+	if old != "" {
+		guest, err := s.GetGuestToken(ctx, old)
+		if err != nil {
+			return "", err
+		}
+		if guest != nil && guest.DeviceID == deviceID {
+			return old, nil
+		}
+	}
+
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := GuestTokenPrefix + base32.StdEncoding.EncodeToString(b)
+	data, _ := json.Marshal(&proto.GuestToken{ID: uuid.NewV4().String(), DeviceID: deviceID})
+	err := s.redis.Set(ctx, model.GuestTokenKey(token), data, GuestTTL).Err()
+	return token, err
+}
+
+// limitGuest 按小时计数，超出GuestIPLimit或GuestDeviceLimit时返回ErrGuestLimit
+func (s *Service) limitGuest(ctx context.Context, deviceID, ip string) error {
+	hour := time.Now().Format("2006010215")
+	ipKey, devKey := model.GuestRateKey("ip:"+ip, hour), model.GuestRateKey("d:"+deviceID, hour)
+	pipe := s.redis.Pipeline()
+	ipCount := pipe.Incr(ctx, ipKey)
+	pipe.Expire(ctx, ipKey, time.Hour)
+	devCount := pipe.Incr(ctx, devKey)
+	pipe.Expire(ctx, devKey, time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	if ipCount.Val() > GuestIPLimit || devCount.Val() > GuestDeviceLimit {
+		return model.ErrGuestLimit
+	}
+	return nil
+}
+
+// GetGuestToken 凭证不存在或已过期时返回nil，有效时顺延过期时间
+func (s *Service) GetGuestToken(ctx context.Context, token string) (*proto.GuestToken, error) {
+	key := model.GuestTokenKey(token)
+	pipe := s.redis.Pipeline()
+	pipe.Expire(ctx, key, GuestTTL)
+	get := pipe.Get(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	b, err := get.Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var guest proto.GuestToken
+	return &guest, json.Unmarshal(b, &guest)
+}
+
+// MergeGuest 将游客购物车合并到用户购物车(同一商品数量相加，超出上限截断)，并作废游客凭证；凭证无效时不处理
+func (s *Service) MergeGuest(ctx context.Context, token string, uid int) (int, error) {
+	guest, err := s.GetGuestToken(ctx, token)
+	if err != nil || guest == nil {
+		return 0, err
+	}
+	from, to := model.CartKey(GuestCartOwner(guest.ID)), model.CartKey(UserCartOwner(uid))
+	items, err := s.redis.HGetAll(ctx, from).Result()
+	if err != nil {
+		return 0, err
+	}
+	if len(items) > 0 {
+		cur, err := s.redis.HGetAll(ctx, to).Result()
+		if err != nil {
+			return 0, err
+		}
+		merged := make(map[string]any, len(items))
+		for k, v := range items {
+			n, _ := strconv.Atoi(v)
+			m, ok := cur[k]
+			if !ok && len(cur)+len(merged) >= CartMaxItems {
+				continue
+			}
+			if ok {
+				old, _ := strconv.Atoi(m)
+				n += old
+			}
+			if n > CartMaxQuantity {
+				n = CartMaxQuantity
+			}
+			merged[k] = n
+		}
+		pipe := s.redis.TxPipeline()
+		if len(merged) > 0 {
+			pipe.HSet(ctx, to, merged)
+			pipe.Expire(ctx, to, cartTTL)
+		}
+		pipe.Del(ctx, from)
+		if _, err = pipe.Exec(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return len(items), s.redis.Del(ctx, model.GuestTokenKey(token)).Err()
+}
+
+func UserCartOwner(uid int) string {
+	return "u:" + strconv.Itoa(uid)
+}
+
+func GuestCartOwner(id string) string {
+	return "g:" + id
+}
+
+func (s *Service) GetCart(ctx context.Context, owner string) ([]*proto.CartItem, error) {
+	data, err := s.redis.HGetAll(ctx, model.CartKey(owner)).Result()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*proto.CartItem, 0, len(data))
+	for k, v := range data {
+		sku, _ := strconv.Atoi(k)
+		n, _ := strconv.Atoi(v)
+		list = append(list, &proto.CartItem{SkuID: sku, Quantity: n})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].SkuID < list[j].SkuID })
+	return list, nil
+}
+
+// SetCartItem 设置商品数量，0为移除；商品种类超过上限时返回ErrCartFull
+func (s *Service) SetCartItem(ctx context.Context, owner string, skuID, quantity int) error {
+	key, field := model.CartKey(owner), strconv.Itoa(skuID)
+	if quantity == 0 {
+		return s.redis.HDel(ctx, key, field).Err()
+	}
+	n, err := s.redis.HLen(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if n >= CartMaxItems {
+		ok, err := s.redis.HExists(ctx, key, field).Result()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return model.ErrCartFull
+		}
+	}
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, key, field, quantity)
+	pipe.Expire(ctx, key, cartTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *Service) ClearCart(ctx context.Context, owner string) error {
+	return s.redis.Del(ctx, model.CartKey(owner)).Err()
+}
+
+// IsGuestToken 游客凭证访问需登录的接口时提示登录而不是凭证过期
+func IsGuestToken(token string) bool {
+	return strings.HasPrefix(token, GuestTokenPrefix)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"project/model"
+	"project/pkg/db/dbtest"
+	"strconv"
+	"testing"
+)
+
+func TestSetGuestToken(t *testing.T) {
+	rdb, _ := dbtest.Redis(t)
+	s := &Service{redis: rdb}
+	ctx := context.Background()
+
+	token, err := s.SetGuestToken(ctx, "device-1", "1.1.1.1", "")
+	if err != nil || !IsGuestToken(token) {
+		t.Fatalf("token %q, %v", token, err)
+	}
+	// 只凭device_id不能取得已有的凭证
+	other, err := s.SetGuestToken(ctx, "device-1", "2.2.2.2", "")
+	if err != nil || other == token {
+		t.Fatalf("token %q reused without presenting it, %v", other, err)
+	}
+	// 持有凭证时顺延并返回原凭证
+	if got, err := s.SetGuestToken(ctx, "device-1", "1.1.1.1", token); err != nil || got != token {
+		t.Fatalf("renew got %q, %v", got, err)
+	}
+	// 其他设备的凭证不能续用
+	if got, err := s.SetGuestToken(ctx, "device-2", "1.1.1.1", token); err != nil || got == token {
+		t.Fatalf("token of device-1 reused by device-2: %q, %v", got, err)
+	}
+	if got, err := s.SetGuestToken(ctx, "device-2", "1.1.1.1", "g.expired"); err != nil || got == "g.expired" {
+		t.Fatalf("expired token reused: %q, %v", got, err)
+	}
+}
+
+func TestSetGuestTokenLimit(t *testing.T) {
+	rdb, _ := dbtest.Redis(t)
+	s := &Service{redis: rdb}
+	ctx := context.Background()
+	for i := 0; i < GuestDeviceLimit; i++ {
+		if _, err := s.SetGuestToken(ctx, "device-1", "1.1.1.1", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.SetGuestToken(ctx, "device-1", "1.1.1.2", ""); err != model.ErrGuestLimit {
+		t.Fatalf("device limit: %v", err)
+	}
+	for i := GuestDeviceLimit; i < GuestIPLimit; i++ { // 其他设备用满同一IP的次数
+		if _, err := s.SetGuestToken(ctx, "device-"+strconv.Itoa(i), "1.1.1.1", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.SetGuestToken(ctx, "device-new", "1.1.1.1", ""); err != model.ErrGuestLimit {
+		t.Fatalf("ip limit: %v", err)
+	}
+}
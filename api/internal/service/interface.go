@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/captcha"
+	"project/pkg/logger"
+	"project/pkg/region"
+	"project/pkg/shortlink"
+	"project/pkg/track"
+	"project/pkg/wechat"
+	"time"
+)
+
+//go:generate mockgen -source=interface.go -destination=mock/service.go -package=mock
+
+// 按业务域拆分的接口，handler只依赖接口，单测时可替换为mock目录下生成的实现
+
+type UserService interface {
+	SaveUser(ctx context.Context, data *model.User) (int, error)
+	SaveWechatUser(ctx context.Context, data *model.UserWechat) (int, error)
+	DetectUserMerge(ctx context.Context, uid int, unionid string)
+	FindUserByID(ctx context.Context, id int) (*model.User, error)
+	UpdateUser(ctx context.Context, data *model.User) error
+	GetUserStatus(ctx context.Context, uid int) (*model.UserStatus, error)
+}
+
+type TokenService interface {
+	SetUserToken(ctx context.Context, data *proto.UserToken) (string, error)
+	GetUserToken(ctx context.Context, token string) (*proto.UserToken, error)
+	RefreshUserToken(ctx context.Context, token string, data *proto.UserToken) (string, error)
+	RevokeUserToken(ctx context.Context, token string) error
+	NewOAuthState(ctx context.Context, app string) (string, error)
+	RedeemOAuthState(ctx context.Context, app, state string) (bool, error)
+	PublishAuthEvent(ctx context.Context, e *model.AuthEvent, token string)
+	FindAuthEvents(ctx context.Context, uid, limit int) ([]*model.AuthEvent, error)
+	RecordLoginDevice(ctx context.Context, d *model.UserDevice) (newDevice, newRegion bool, err error)
+	FindUserDevices(ctx context.Context, uid int) ([]*model.UserDevice, error)
+}
+
+type GuestService interface {
+	SetGuestToken(ctx context.Context, deviceID, ip, old string) (string, error)
+	GetGuestToken(ctx context.Context, token string) (*proto.GuestToken, error)
+	MergeGuest(ctx context.Context, token string, uid int) (int, error)
+	GetCart(ctx context.Context, owner string) ([]*proto.CartItem, error)
+	SetCartItem(ctx context.Context, owner string, skuID, quantity int) error
+	ClearCart(ctx context.Context, owner string) error
+}
+
+type WechatTokenStore interface {
+	TokenStore() wechat.TokenStore
+	WechatQuota(appid string, limits map[string]int64) *wechat.Quota
+	JSAPITicket(ctx context.Context) (string, error)
+}
+
+type BannerService interface {
+	GetBannersByCity(ctx context.Context, city string) ([]*model.Banner, error)
+	SyncBannersByCity(ctx context.Context, city string,
+		since time.Time, sinceID int) (list []*model.Banner, syncTime time.Time, syncID int, hasMore bool, err error)
+}
+
+type ContentService interface {
+	GetContent(ctx context.Context, key string) (*model.Content, error)
+}
+
+type ChaosService interface {
+	GetChaosRules(ctx context.Context) (map[string]*model.ChaosRule, error)
+}
+
+type ExperimentService interface {
+	AllExperiments(ctx context.Context) ([]*model.Experiment, error)
+	PushExposure(ctx context.Context, data []*model.MsgExposure) error
+}
+
+type SegmentService interface {
+	UserSegments(ctx context.Context, uid int) (map[string]bool, error)
+}
+
+type OrderService interface {
+	FindOrderByNo(ctx context.Context, orderNo string) (*model.Order, error)
+	SetOrderProvider(ctx context.Context, order *model.Order, provider string, couponID int) (bool, error)
+	PayOrder(ctx context.Context, orderNo, transactionID string, amount, payTime int64) (bool, error)
+	FinishRefund(ctx context.Context, refundNo, refundID string, success bool, successTime int64) error
+	CancelOrder(ctx context.Context, order *model.Order, remark string) (bool, error)
+	CompleteOrder(ctx context.Context, order *model.Order) (bool, error)
+	ScheduleOrderTimeout(ctx context.Context, orderNo string) error
+}
+
+type CouponService interface {
+	ClaimableCoupons(ctx context.Context) ([]*model.CouponTemplate, error)
+	ClaimCoupon(ctx context.Context, uid, templateID int) (*model.Coupon, error)
+	FindUserCoupons(ctx context.Context, uid int, status int8) ([]*model.Coupon, error)
+	PriceCoupon(ctx context.Context, uid, couponID int, amount int64) (int64, error)
+}
+
+type StockService interface {
+	DeductStock(ctx context.Context, orderNo string, items map[int]int) error
+	RestockOrder(ctx context.Context, orderNo string, skus []int) error
+	GetStock(ctx context.Context, skus []int) (map[int]int, error)
+}
+
+type LedgerService interface {
+	Credit(ctx context.Context, uid int, asset string, amount int64, refNo, bizType, remark string) (*model.LedgerEntry, error)
+	Debit(ctx context.Context, uid int, asset string, amount int64, refNo, bizType, remark string) (*model.LedgerEntry, error)
+	FindLedgerAccounts(ctx context.Context, uid int) (map[string]int64, error)
+	PaginateLedgerEntry(ctx context.Context, uid int, asset string, page, size int) (int64, []*model.LedgerEntry, error)
+}
+
+type PushService interface {
+	RegisterPushDevice(ctx context.Context, d *model.PushDevice) error
+	UnregisterPushDevice(ctx context.Context, uid int, deviceID string) error
+	FindPushDevices(ctx context.Context, uid int) ([]*model.PushDevice, error)
+	GetPushPreference(ctx context.Context, uid int) (*model.PushPreference, error)
+	SetPushPreference(ctx context.Context, p *model.PushPreference) error
+	Push(ctx context.Context, msg *model.MsgPush) (string, error)
+	FindPushDeliveries(ctx context.Context, msgID string) ([]*model.PushDelivery, error)
+}
+
+type ShortLinkService interface {
+	ResolveShortLink(ctx context.Context, code string) (*shortlink.Link, error)
+	PushShortLinkClick(ctx context.Context, click *shortlink.Click) error
+}
+
+type AppService interface {
+	GetAppVersions(ctx context.Context) (map[string]*model.AppVersion, error)
+}
+
+type MeterService interface {
+	AllAPIKeys(ctx context.Context) ([]*model.APIKey, error)
+	IncrUsage(ctx context.Context, date string, fields ...string) ([]int64, error)
+	IncrHTTPStat(ctx context.Context, date string, status int) error
+	FindAPIUsage(ctx context.Context, subject string, begin, end int) ([]*model.APIUsage, error)
+}
+
+type CaptchaService interface {
+	NewCaptcha(ctx context.Context, kind string) (*captcha.Challenge, error)
+	VerifyCaptcha(ctx context.Context, id, answer string) (string, error)
+	RedeemCaptcha(ctx context.Context, ticket string) (bool, error)
+	FlagCaptcha(ctx context.Context, subject string, ttl time.Duration) error
+	CaptchaRequired(ctx context.Context, subject string, limit int64, window time.Duration) (bool, error)
+}
+
+type ExchangeService interface {
+	ExchangePoints(ctx context.Context, uid, skuID, quantity int) (string, error)
+}
+
+type TrackService interface {
+	Track(ctx context.Context, uid int, p track.Props)
+}
+
+// PresenceService 在线状态，供客服会话、聊天等判断用户是否在线
+type PresenceService interface {
+	PresenceHeartbeat(ctx context.Context, uid int, connID string) (bool, error)
+	PresenceLeave(ctx context.Context, uid int, connID string) error
+	IsOnline(ctx context.Context, uid int) (bool, error)
+	GetPresence(ctx context.Context, uids []int) (map[int]*model.UserPresence, error)
+}
+
+type ChatService interface {
+	OpenSingleChat(ctx context.Context, uid, peer int) (*model.ChatConversation, error)
+	CreateGroupChat(ctx context.Context, uid int, name string, members []int) (*model.ChatConversation, error)
+	FindChatConversations(ctx context.Context, uid, page, size int) ([]*proto.ChatConversationItem, error)
+	FindChatMembers(ctx context.Context, uid, cid int) ([]*model.ChatMember, error)
+	SendChatMessage(ctx context.Context, msg *model.ChatMessage) (*model.ChatMessage, error)
+	FindChatMessages(ctx context.Context, uid, cid int, before, after int64, size int) ([]*model.ChatMessage, error)
+	ReadChat(ctx context.Context, uid, cid int, seq int64) (bool, error)
+}
+
+type StoreService interface {
+	NearbyStores(ctx context.Context, lng, lat float64, radius, limit int) ([]*proto.StoreItem, error)
+	FindStore(ctx context.Context, id int) (*proto.StoreItem, error)
+}
+
+type AddressService interface {
+	Regions() *region.Dataset
+	FindAddresses(ctx context.Context, uid int) ([]*model.UserAddress, error)
+	FindAddress(ctx context.Context, uid, id int) (*model.UserAddress, error)
+	CreateAddress(ctx context.Context, addr *model.UserAddress) error
+	UpdateAddress(ctx context.Context, addr *model.UserAddress) error
+	DeleteAddress(ctx context.Context, uid, id int) error
+	SetDefaultAddress(ctx context.Context, uid, id int) error
+}
+
+type BookingService interface {
+	FindBookingResources(ctx context.Context) ([]*model.BookingResource, error)
+	FindBookingSlots(ctx context.Context, resourceID int, date time.Time) ([]*proto.BookingSlot, error)
+	ReserveBooking(ctx context.Context, b *model.Booking, date time.Time) error
+	ConfirmBooking(ctx context.Context, uid, id int) (*model.Booking, error)
+	CancelBooking(ctx context.Context, uid, id int, reason string) error
+	FindBookings(ctx context.Context, uid, page, size int) ([]*proto.BookingItem, error)
+}
+
+type CheckinService interface {
+	Checkin(ctx context.Context, uid int, tz string) (*proto.CheckinResult, error)
+	FindCheckinStatus(ctx context.Context, uid int, tz string) (*proto.CheckinStatus, error)
+	FindCheckinCalendar(ctx context.Context, uid int, month string) ([]*model.CheckinLog, error)
+}
+
+type LotteryService interface {
+	FindLottery(ctx context.Context, id int) (*model.Lottery, error)
+	DrawLottery(ctx context.Context, uid, id int) (*model.LotteryDraw, error)
+	FindLotteryChances(ctx context.Context, uid, id int) (*proto.LotteryChances, error)
+	FindLotteryWins(ctx context.Context, uid, lotteryID, page, size int) ([]*model.LotteryDraw, error)
+}
+
+type RecordService interface {
+	SaveRecord(ctx context.Context, ex *logger.Exchange, ttl time.Duration) error
+	GetRecords(ctx context.Context, traceID string) ([]*logger.Exchange, error)
+}
+
+// Interface handler依赖的全部接口
+type Interface interface {
+	UserService
+	TokenService
+	GuestService
+	WechatTokenStore
+	BannerService
+	ContentService
+	ChaosService
+	ExperimentService
+	SegmentService
+	OrderService
+	CouponService
+	StockService
+	LedgerService
+	ShortLinkService
+	AppService
+	MeterService
+	CaptchaService
+	ExchangeService
+	TrackService
+	PushService
+	PresenceService
+	ChatService
+	StoreService
+	AddressService
+	BookingService
+	CheckinService
+	LotteryService
+	RecordService
+}
+
+var _ Interface = (*Service)(nil)
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"project/pkg/db"
+)
+
+// Credit 入账(系统账户->用户)，refNo在同一资产下幂等，重复请求返回首次记账的分录
+func (s *Service) Credit(ctx context.Context, uid int, asset string, amount int64,
+	refNo, bizType, remark string) (*model.LedgerEntry, error) {
+	return s.postLedger(ctx, &model.LedgerEntry{
+		RefNo:   refNo,
+		UserID:  uid,
+		Asset:   asset,
+		Amount:  amount,
+		BizType: bizType,
+		Remark:  remark,
+	})
+}
+
+// Debit 出账(用户->系统账户)，余额不足返回ErrBalanceNotEnough
+func (s *Service) Debit(ctx context.Context, uid int, asset string, amount int64,
+	refNo, bizType, remark string) (*model.LedgerEntry, error) {
+	return s.postLedger(ctx, &model.LedgerEntry{
+		RefNo:   refNo,
+		UserID:  uid,
+		Asset:   asset,
+		Amount:  -amount,
+		BizType: bizType,
+		Remark:  remark,
+	})
+}
+
+// CreditTx 在调用方的事务内入账，用于入账需与业务记录同时提交的场景，refNo重复时不再入账
+func (s *Service) CreditTx(ctx context.Context, tx *gorm.DB, uid int, asset string, amount int64,
+	refNo, bizType, remark string) error {
+	_, err := postLedgerTx(tx.WithContext(ctx), &model.LedgerEntry{
+		RefNo:   refNo,
+		UserID:  uid,
+		Asset:   asset,
+		Amount:  amount,
+		BizType: bizType,
+		Remark:  remark,
+	})
+	return err
+}
+
+// postLedger 锁定用户账户后写入用户和系统账户两条分录，分录唯一键冲突视为重复请求
+func (s *Service) postLedger(ctx context.Context, entry *model.LedgerEntry) (*model.LedgerEntry, error) {
+	dup := false
+	err := s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		dup, err = postLedgerTx(tx, entry)
+		return
+	})
+	if err == model.ErrBalanceNotEnough {
+		// 已记账的出账请求重试时余额可能已不足，优先按幂等返回
+		if e, _ := s.findLedgerEntry(ctx, entry); e != nil {
+			return e, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dup {
+		return s.findLedgerEntry(ctx, entry)
+	}
+	return entry, nil
+}
+
+// postLedgerTx 在事务内记账，返回分录是否已存在
+func postLedgerTx(tx *gorm.DB, entry *model.LedgerEntry) (bool, error) {
+	account := &model.LedgerAccount{UserID: entry.UserID, Asset: entry.Asset}
+	if entry.Amount > 0 {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(account).Error
+		if err != nil {
+			return false, err
+		}
+	}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Take(account, "user_id = ? AND asset = ?", entry.UserID, entry.Asset).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+	if account.Balance+entry.Amount < 0 {
+		return false, model.ErrBalanceNotEnough
+	}
+	entry.Balance = account.Balance + entry.Amount
+	err = tx.Create([]*model.LedgerEntry{entry, {
+		RefNo:   entry.RefNo,
+		UserID:  model.LedgerSystemUser,
+		Asset:   entry.Asset,
+		Amount:  -entry.Amount,
+		BizType: entry.BizType,
+		Remark:  entry.Remark,
+	}}).Error
+	if db.IsDuplicateKey(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, tx.Model(account).Update("balance", entry.Balance).Error
+}
+
+func (s *Service) findLedgerEntry(ctx context.Context, entry *model.LedgerEntry) (*model.LedgerEntry, error) {
+	var data model.LedgerEntry
+	err := s.mysql.WithContext(ctx).Take(&data, "ref_no = ? AND asset = ? AND user_id = ?",
+		entry.RefNo, entry.Asset, entry.UserID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// FindLedgerAccounts 用户全部资产余额，未开户的资产返回0
+func (s *Service) FindLedgerAccounts(ctx context.Context, uid int) (map[string]int64, error) {
+	var list []*model.LedgerAccount
+	err := s.mysql.WithContext(ctx).Where("user_id = ?", uid).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]int64{model.AssetPoints: 0, model.AssetBalance: 0}
+	for _, v := range list {
+		data[v.Asset] = v.Balance
+	}
+	return data, nil
+}
+
+// PaginateLedgerEntry 用户资产流水，按时间倒序
+func (s *Service) PaginateLedgerEntry(ctx context.Context, uid int, asset string,
+	page, size int) (total int64, list []*model.LedgerEntry, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.LedgerEntry{}).
+		Where("user_id = ? AND asset = ?", uid, asset)
+	err = query.Count(&total).Error
+	offset := size * (page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(size).Offset(offset).Find(&list).Error
+	return
+}
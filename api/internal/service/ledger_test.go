@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"project/model"
+	"project/pkg/db/dbtest"
+	"testing"
+)
+
+func newLedgerService(t *testing.T) *Service {
+	return &Service{mysql: dbtest.Mysql(t, "ledger_account", "ledger_entry")}
+}
+
+func checkBalance(t *testing.T, s *Service, uid int, want int64) {
+	t.Helper()
+	accounts, err := s.FindLedgerAccounts(context.Background(), uid)
+	if err != nil || accounts[model.AssetPoints] != want {
+		t.Fatalf("user %d: points %v, want %d, %v", uid, accounts, want, err)
+	}
+}
+
+// 重复的ref_no返回首次记账的分录，不重复入账
+func TestLedgerDuplicateRef(t *testing.T) {
+	s := newLedgerService(t)
+	ctx := context.Background()
+	first, err := s.Credit(ctx, 1, model.AssetPoints, 100, "r1", "test", "")
+	if err != nil || first.Balance != 100 {
+		t.Fatalf("credit %+v, %v", first, err)
+	}
+	again, err := s.Credit(ctx, 1, model.AssetPoints, 100, "r1", "test", "")
+	if err != nil || again.ID != first.ID {
+		t.Fatalf("repeat credit %+v, %v", again, err)
+	}
+	err = s.mysql.Transaction(func(tx *gorm.DB) error {
+		return s.CreditTx(ctx, tx, 1, model.AssetPoints, 100, "r1", "test", "")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBalance(t, s, 1, 100)
+
+	// 同一ref_no不同资产、不同用户各自入账
+	if _, err = s.Credit(ctx, 1, model.AssetBalance, 5, "r1", "test", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Credit(ctx, 2, model.AssetPoints, 5, "r1:2", "test", ""); err != nil {
+		t.Fatal(err)
+	}
+	checkBalance(t, s, 1, 100)
+	checkBalance(t, s, 2, 5)
+}
+
+// 每笔记账都有系统账户的对手分录，借贷平衡
+func TestLedgerCounterEntry(t *testing.T) {
+	s := newLedgerService(t)
+	ctx := context.Background()
+	if _, err := s.Credit(ctx, 1, model.AssetPoints, 100, "c1", "test", "in"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Debit(ctx, 1, model.AssetPoints, 40, "d1", "test", "out"); err != nil {
+		t.Fatal(err)
+	}
+	for ref, amount := range map[string]int64{"c1": 100, "d1": -40} {
+		var list []*model.LedgerEntry
+		if err := s.mysql.Order("user_id").Find(&list, "ref_no = ?", ref).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("%s: %d entries, want 2", ref, len(list))
+		}
+		sys, user := list[0], list[1]
+		if sys.UserID != model.LedgerSystemUser || sys.Amount != -amount || user.UserID != 1 || user.Amount != amount ||
+			sys.BizType != user.BizType || sys.Remark != user.Remark {
+			t.Fatalf("%s: unexpected entries %+v %+v", ref, sys, user)
+		}
+	}
+	var sum int64
+	if err := s.mysql.Model(&model.LedgerEntry{}).Select("COALESCE(SUM(amount), 0)").Scan(&sum).Error; err != nil || sum != 0 {
+		t.Fatalf("sum %d, %v", sum, err)
+	}
+	checkBalance(t, s, 1, 60)
+}
+
+// 余额不足时拒绝出账且不写入分录，已记账的出账重试按幂等返回
+func TestLedgerOverdraft(t *testing.T) {
+	s := newLedgerService(t)
+	ctx := context.Background()
+	if _, err := s.Debit(ctx, 1, model.AssetPoints, 1, "d0", "test", ""); err != model.ErrBalanceNotEnough {
+		t.Fatalf("debit without account: %v", err)
+	}
+	if _, err := s.Credit(ctx, 1, model.AssetPoints, 50, "c1", "test", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Debit(ctx, 1, model.AssetPoints, 51, "d1", "test", ""); err != model.ErrBalanceNotEnough {
+		t.Fatalf("overdraft: %v", err)
+	}
+	var n int64
+	if err := s.mysql.Model(&model.LedgerEntry{}).Where("ref_no IN ?", []string{"d0", "d1"}).Count(&n).Error; err != nil || n != 0 {
+		t.Fatalf("rejected entries %d, %v", n, err)
+	}
+	checkBalance(t, s, 1, 50)
+
+	first, err := s.Debit(ctx, 1, model.AssetPoints, 50, "d2", "test", "")
+	if err != nil || first.Balance != 0 {
+		t.Fatalf("debit %+v, %v", first, err)
+	}
+	again, err := s.Debit(ctx, 1, model.AssetPoints, 50, "d2", "test", "")
+	if err != nil || again.ID != first.ID {
+		t.Fatalf("repeat debit %+v, %v", again, err)
+	}
+	checkBalance(t, s, 1, 0)
+}
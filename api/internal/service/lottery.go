@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"math/big"
+	"project/api/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+	"time"
+)
+
+// lotteryDailyTTL 每人当天次数的hash过期秒数
+const lotteryDailyTTL = 2 * 86400
+
+// FindLottery 上线的活动和奖品，不在抽奖时间内也返回
+func (s *Service) FindLottery(ctx context.Context, id int) (*model.Lottery, error) {
+	var l model.Lottery
+	err := s.mysql.WithContext(ctx).Preload("Prizes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Take(&l, "id = ? AND status = ?", id, model.StatusOn).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, model.ErrLotteryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func lotteryKeys(id int, now time.Time) []string {
+	return []string{model.LotteryStockKey(id), model.LotteryUserKey(id), model.LotteryDailyKey(id, now.Format("20060102"))}
+}
+
+// DrawLottery 在redis中原子校验次数、抽取奖品并扣减库存，抽奖记录入库失败时归还；积分奖品在同一事务内记账
+func (s *Service) DrawLottery(ctx context.Context, uid, id int) (*model.LotteryDraw, error) {
+	l, err := s.FindLottery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !l.Open(now.Unix()) {
+		return nil, model.ErrLotteryClosed
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(model.LotteryRateBase))
+	if err != nil {
+		return nil, err
+	}
+	keys := lotteryKeys(l.ID, now)
+	args := make([]any, 0, 5+len(l.Prizes)*2)
+	args = append(args, uid, l.DailyLimit, l.TotalLimit, lotteryDailyTTL, n.Int64())
+	upper := 0
+	for _, v := range l.Prizes {
+		upper += v.Rate
+		args = append(args, v.ID, upper)
+	}
+	run := func() (int, error) {
+		return model.ScriptLotteryDraw.Run(ctx, s.redis, keys, args...).Int()
+	}
+	ret, err := run()
+	if err == nil && ret == -3 {
+		if err = s.initLotteryStock(ctx, l); err == nil {
+			ret, err = run()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch ret {
+	case -1:
+		return nil, model.ErrLotteryDaily
+	case -2:
+		return nil, model.ErrLotteryLimit
+	case -3:
+		return nil, errors.New("lottery stock not initialized: " + keys[0])
+	}
+
+	draw := &model.LotteryDraw{LotteryID: l.ID, UserID: uid, PrizeID: ret, Status: model.LotteryDrawMissed}
+	for _, v := range l.Prizes {
+		if v.ID == ret {
+			draw.PrizeName, draw.PrizeType, draw.Value = v.Name, v.Type, v.Value
+			if draw.Status = model.LotteryDrawPending; v.Type == model.LotteryPrizePoints {
+				draw.Status = model.LotteryDrawIssued
+			}
+		}
+	}
+	err = s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(draw).Error; err != nil {
+			return err
+		}
+		if draw.PrizeType != model.LotteryPrizePoints || draw.Value <= 0 {
+			return nil
+		}
+		return s.CreditTx(ctx, tx, uid, model.AssetPoints, draw.Value, model.LotteryRefNo(draw.ID),
+			"lottery", "抽奖:"+draw.PrizeName)
+	})
+	if err != nil {
+		if e := model.ScriptLotteryRollback.Run(ctx, s.redis, keys, uid, ret).Err(); e != nil {
+			logger.FromContext(ctx).Error("redis.ScriptLotteryRollback error", keys, e)
+		}
+		return nil, err
+	}
+	return draw, nil
+}
+
+// initLotteryStock 库存key不存在时按奖品库存减去已中奖的记录初始化
+func (s *Service) initLotteryStock(ctx context.Context, l *model.Lottery) error {
+	var rows []*struct {
+		PrizeID int
+		N       int
+	}
+	err := s.mysql.WithContext(ctx).Model(&model.LotteryDraw{}).Select("prize_id, COUNT(*) AS n").
+		Where("lottery_id = ? AND prize_id > 0", l.ID).Group("prize_id").Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	won := make(map[int]int, len(rows))
+	for _, v := range rows {
+		won[v.PrizeID] = v.N
+	}
+	args := make([]any, 0, len(l.Prizes)*2)
+	for _, v := range l.Prizes {
+		remain := v.Stock - won[v.ID]
+		if remain < 0 {
+			remain = 0
+		}
+		args = append(args, v.ID, remain)
+	}
+	return model.ScriptLotteryInit.Run(ctx, s.redis, []string{model.LotteryStockKey(l.ID)}, args...).Err()
+}
+
+// FindLotteryChances 用户已抽次数和剩余次数
+func (s *Service) FindLotteryChances(ctx context.Context, uid, id int) (*proto.LotteryChances, error) {
+	l, err := s.FindLottery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	keys := lotteryKeys(l.ID, time.Now())
+	field := strconv.Itoa(uid)
+	pipe := s.redis.Pipeline()
+	total := pipe.HGet(ctx, keys[1], field)
+	daily := pipe.HGet(ctx, keys[2], field)
+	if _, err = pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	data := &proto.LotteryChances{Remain: -1}
+	data.TotalUsed, _ = total.Int()
+	data.DailyUsed, _ = daily.Int()
+	if l.TotalLimit > 0 {
+		data.Remain = l.TotalLimit - data.TotalUsed
+	}
+	if left := l.DailyLimit - data.DailyUsed; l.DailyLimit > 0 && (l.TotalLimit == 0 || left < data.Remain) {
+		data.Remain = left
+	}
+	if data.Remain < 0 && (l.TotalLimit > 0 || l.DailyLimit > 0) {
+		data.Remain = 0
+	}
+	return data, nil
+}
+
+// FindLotteryWins 用户的中奖记录，lotteryID为0时查询全部活动，按时间倒序
+func (s *Service) FindLotteryWins(ctx context.Context, uid, lotteryID, page, size int) ([]*model.LotteryDraw, error) {
+	query := s.mysql.WithContext(ctx).Where("user_id = ? AND prize_id > 0", uid)
+	if lotteryID > 0 {
+		query = query.Where("lottery_id = ?", lotteryID)
+	}
+	list := make([]*model.LotteryDraw, 0)
+	err := query.Order("id DESC").Limit(size).Offset(size * (page - 1)).Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"project/model"
+	"strconv"
+	"time"
+)
+
+func (s *Service) AllAPIKeys(ctx context.Context) (list []*model.APIKey, err error) {
+	err = s.mysql.WithContext(ctx).Where("status = ?", model.StatusOn).Find(&list).Error
+	return
+}
+
+// IncrUsage 当日调用计数，按fields顺序返回累加后的次数，计数保留3天供script回写
+func (s *Service) IncrUsage(ctx context.Context, date string, fields ...string) ([]int64, error) {
+	key := model.UsageKey(date)
+	pipe := s.redis.TxPipeline()
+	cmds := make([]*redis.IntCmd, len(fields))
+	for i, f := range fields {
+		cmds[i] = pipe.HIncrBy(ctx, key, f, 1)
+	}
+	pipe.Expire(ctx, key, 72*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	counts := make([]int64, len(fields))
+	for i, c := range cmds {
+		counts[i] = c.Val()
+	}
+	return counts, nil
+}
+
+// IncrHTTPStat 当日api请求计数，按总数和状态码类别累加，由script汇总到stat_daily
+func (s *Service) IncrHTTPStat(ctx context.Context, date string, status int) error {
+	key := model.HTTPStatKey(date)
+	pipe := s.redis.Pipeline()
+	pipe.HIncrBy(ctx, key, model.HTTPStatAll, 1)
+	pipe.HIncrBy(ctx, key, strconv.Itoa(status/100)+"xx", 1)
+	pipe.Expire(ctx, key, 72*time.Hour)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// FindAPIUsage 按日期范围查询调用方已回写的调用量，当日数据有5分钟延迟
+func (s *Service) FindAPIUsage(ctx context.Context, subject string, begin, end int) (list []*model.APIUsage, err error) {
+	err = s.mysql.WithContext(ctx).
+		Where("subject = ? AND date >= ? AND date <= ?", subject, begin, end).
+		Order("date DESC, route").Find(&list).Error
+	return
+}
@@ -0,0 +1,3818 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interface.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	proto "project/api/internal/proto"
+	model "project/model"
+	captcha "project/pkg/captcha"
+	logger "project/pkg/logger"
+	region "project/pkg/region"
+	shortlink "project/pkg/shortlink"
+	track "project/pkg/track"
+	wechat "project/pkg/wechat"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUserService is a mock of UserService interface.
+type MockUserService struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserServiceMockRecorder
+}
+
+// MockUserServiceMockRecorder is the mock recorder for MockUserService.
+type MockUserServiceMockRecorder struct {
+	mock *MockUserService
+}
+
+// NewMockUserService creates a new mock instance.
+func NewMockUserService(ctrl *gomock.Controller) *MockUserService {
+	mock := &MockUserService{ctrl: ctrl}
+	mock.recorder = &MockUserServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
+	return m.recorder
+}
+
+// DetectUserMerge mocks base method.
+func (m *MockUserService) DetectUserMerge(ctx context.Context, uid int, unionid string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DetectUserMerge", ctx, uid, unionid)
+}
+
+// DetectUserMerge indicates an expected call of DetectUserMerge.
+func (mr *MockUserServiceMockRecorder) DetectUserMerge(ctx, uid, unionid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetectUserMerge", reflect.TypeOf((*MockUserService)(nil).DetectUserMerge), ctx, uid, unionid)
+}
+
+// FindUserByID mocks base method.
+func (m *MockUserService) FindUserByID(ctx context.Context, id int) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByID", ctx, id)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByID indicates an expected call of FindUserByID.
+func (mr *MockUserServiceMockRecorder) FindUserByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByID", reflect.TypeOf((*MockUserService)(nil).FindUserByID), ctx, id)
+}
+
+// GetUserStatus mocks base method.
+func (m *MockUserService) GetUserStatus(ctx context.Context, uid int) (*model.UserStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserStatus", ctx, uid)
+	ret0, _ := ret[0].(*model.UserStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserStatus indicates an expected call of GetUserStatus.
+func (mr *MockUserServiceMockRecorder) GetUserStatus(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserStatus", reflect.TypeOf((*MockUserService)(nil).GetUserStatus), ctx, uid)
+}
+
+// SaveUser mocks base method.
+func (m *MockUserService) SaveUser(ctx context.Context, data *model.User) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUser", ctx, data)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveUser indicates an expected call of SaveUser.
+func (mr *MockUserServiceMockRecorder) SaveUser(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockUserService)(nil).SaveUser), ctx, data)
+}
+
+// SaveWechatUser mocks base method.
+func (m *MockUserService) SaveWechatUser(ctx context.Context, data *model.UserWechat) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWechatUser", ctx, data)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveWechatUser indicates an expected call of SaveWechatUser.
+func (mr *MockUserServiceMockRecorder) SaveWechatUser(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWechatUser", reflect.TypeOf((*MockUserService)(nil).SaveWechatUser), ctx, data)
+}
+
+// UpdateUser mocks base method.
+func (m *MockUserService) UpdateUser(ctx context.Context, data *model.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockUserServiceMockRecorder) UpdateUser(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserService)(nil).UpdateUser), ctx, data)
+}
+
+// MockTokenService is a mock of TokenService interface.
+type MockTokenService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenServiceMockRecorder
+}
+
+// MockTokenServiceMockRecorder is the mock recorder for MockTokenService.
+type MockTokenServiceMockRecorder struct {
+	mock *MockTokenService
+}
+
+// NewMockTokenService creates a new mock instance.
+func NewMockTokenService(ctrl *gomock.Controller) *MockTokenService {
+	mock := &MockTokenService{ctrl: ctrl}
+	mock.recorder = &MockTokenServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenService) EXPECT() *MockTokenServiceMockRecorder {
+	return m.recorder
+}
+
+// FindAuthEvents mocks base method.
+func (m *MockTokenService) FindAuthEvents(ctx context.Context, uid, limit int) ([]*model.AuthEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAuthEvents", ctx, uid, limit)
+	ret0, _ := ret[0].([]*model.AuthEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAuthEvents indicates an expected call of FindAuthEvents.
+func (mr *MockTokenServiceMockRecorder) FindAuthEvents(ctx, uid, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAuthEvents", reflect.TypeOf((*MockTokenService)(nil).FindAuthEvents), ctx, uid, limit)
+}
+
+// FindUserDevices mocks base method.
+func (m *MockTokenService) FindUserDevices(ctx context.Context, uid int) ([]*model.UserDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserDevices", ctx, uid)
+	ret0, _ := ret[0].([]*model.UserDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserDevices indicates an expected call of FindUserDevices.
+func (mr *MockTokenServiceMockRecorder) FindUserDevices(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserDevices", reflect.TypeOf((*MockTokenService)(nil).FindUserDevices), ctx, uid)
+}
+
+// GetUserToken mocks base method.
+func (m *MockTokenService) GetUserToken(ctx context.Context, token string) (*proto.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserToken", ctx, token)
+	ret0, _ := ret[0].(*proto.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserToken indicates an expected call of GetUserToken.
+func (mr *MockTokenServiceMockRecorder) GetUserToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserToken", reflect.TypeOf((*MockTokenService)(nil).GetUserToken), ctx, token)
+}
+
+// NewOAuthState mocks base method.
+func (m *MockTokenService) NewOAuthState(ctx context.Context, app string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewOAuthState", ctx, app)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewOAuthState indicates an expected call of NewOAuthState.
+func (mr *MockTokenServiceMockRecorder) NewOAuthState(ctx, app interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewOAuthState", reflect.TypeOf((*MockTokenService)(nil).NewOAuthState), ctx, app)
+}
+
+// PublishAuthEvent mocks base method.
+func (m *MockTokenService) PublishAuthEvent(ctx context.Context, e *model.AuthEvent, token string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PublishAuthEvent", ctx, e, token)
+}
+
+// PublishAuthEvent indicates an expected call of PublishAuthEvent.
+func (mr *MockTokenServiceMockRecorder) PublishAuthEvent(ctx, e, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishAuthEvent", reflect.TypeOf((*MockTokenService)(nil).PublishAuthEvent), ctx, e, token)
+}
+
+// RecordLoginDevice mocks base method.
+func (m *MockTokenService) RecordLoginDevice(ctx context.Context, d *model.UserDevice) (bool, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginDevice", ctx, d)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RecordLoginDevice indicates an expected call of RecordLoginDevice.
+func (mr *MockTokenServiceMockRecorder) RecordLoginDevice(ctx, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginDevice", reflect.TypeOf((*MockTokenService)(nil).RecordLoginDevice), ctx, d)
+}
+
+// RedeemOAuthState mocks base method.
+func (m *MockTokenService) RedeemOAuthState(ctx context.Context, app, state string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemOAuthState", ctx, app, state)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemOAuthState indicates an expected call of RedeemOAuthState.
+func (mr *MockTokenServiceMockRecorder) RedeemOAuthState(ctx, app, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemOAuthState", reflect.TypeOf((*MockTokenService)(nil).RedeemOAuthState), ctx, app, state)
+}
+
+// RefreshUserToken mocks base method.
+func (m *MockTokenService) RefreshUserToken(ctx context.Context, token string, data *proto.UserToken) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshUserToken", ctx, token, data)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshUserToken indicates an expected call of RefreshUserToken.
+func (mr *MockTokenServiceMockRecorder) RefreshUserToken(ctx, token, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshUserToken", reflect.TypeOf((*MockTokenService)(nil).RefreshUserToken), ctx, token, data)
+}
+
+// RevokeUserToken mocks base method.
+func (m *MockTokenService) RevokeUserToken(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserToken indicates an expected call of RevokeUserToken.
+func (mr *MockTokenServiceMockRecorder) RevokeUserToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserToken", reflect.TypeOf((*MockTokenService)(nil).RevokeUserToken), ctx, token)
+}
+
+// SetUserToken mocks base method.
+func (m *MockTokenService) SetUserToken(ctx context.Context, data *proto.UserToken) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserToken", ctx, data)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserToken indicates an expected call of SetUserToken.
+func (mr *MockTokenServiceMockRecorder) SetUserToken(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserToken", reflect.TypeOf((*MockTokenService)(nil).SetUserToken), ctx, data)
+}
+
+// MockGuestService is a mock of GuestService interface.
+type MockGuestService struct {
+	ctrl     *gomock.Controller
+	recorder *MockGuestServiceMockRecorder
+}
+
+// MockGuestServiceMockRecorder is the mock recorder for MockGuestService.
+type MockGuestServiceMockRecorder struct {
+	mock *MockGuestService
+}
+
+// NewMockGuestService creates a new mock instance.
+func NewMockGuestService(ctrl *gomock.Controller) *MockGuestService {
+	mock := &MockGuestService{ctrl: ctrl}
+	mock.recorder = &MockGuestServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGuestService) EXPECT() *MockGuestServiceMockRecorder {
+	return m.recorder
+}
+
+// ClearCart mocks base method.
+func (m *MockGuestService) ClearCart(ctx context.Context, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCart", ctx, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCart indicates an expected call of ClearCart.
+func (mr *MockGuestServiceMockRecorder) ClearCart(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCart", reflect.TypeOf((*MockGuestService)(nil).ClearCart), ctx, owner)
+}
+
+// GetCart mocks base method.
+func (m *MockGuestService) GetCart(ctx context.Context, owner string) ([]*proto.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCart", ctx, owner)
+	ret0, _ := ret[0].([]*proto.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCart indicates an expected call of GetCart.
+func (mr *MockGuestServiceMockRecorder) GetCart(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCart", reflect.TypeOf((*MockGuestService)(nil).GetCart), ctx, owner)
+}
+
+// GetGuestToken mocks base method.
+func (m *MockGuestService) GetGuestToken(ctx context.Context, token string) (*proto.GuestToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGuestToken", ctx, token)
+	ret0, _ := ret[0].(*proto.GuestToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGuestToken indicates an expected call of GetGuestToken.
+func (mr *MockGuestServiceMockRecorder) GetGuestToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGuestToken", reflect.TypeOf((*MockGuestService)(nil).GetGuestToken), ctx, token)
+}
+
+// MergeGuest mocks base method.
+func (m *MockGuestService) MergeGuest(ctx context.Context, token string, uid int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeGuest", ctx, token, uid)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeGuest indicates an expected call of MergeGuest.
+func (mr *MockGuestServiceMockRecorder) MergeGuest(ctx, token, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeGuest", reflect.TypeOf((*MockGuestService)(nil).MergeGuest), ctx, token, uid)
+}
+
+// SetCartItem mocks base method.
+func (m *MockGuestService) SetCartItem(ctx context.Context, owner string, skuID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCartItem", ctx, owner, skuID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCartItem indicates an expected call of SetCartItem.
+func (mr *MockGuestServiceMockRecorder) SetCartItem(ctx, owner, skuID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCartItem", reflect.TypeOf((*MockGuestService)(nil).SetCartItem), ctx, owner, skuID, quantity)
+}
+
+// SetGuestToken mocks base method.
+func (m *MockGuestService) SetGuestToken(ctx context.Context, deviceID, ip, old string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetGuestToken", ctx, deviceID, ip, old)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetGuestToken indicates an expected call of SetGuestToken.
+func (mr *MockGuestServiceMockRecorder) SetGuestToken(ctx, deviceID, ip, old interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGuestToken", reflect.TypeOf((*MockGuestService)(nil).SetGuestToken), ctx, deviceID, ip, old)
+}
+
+// MockWechatTokenStore is a mock of WechatTokenStore interface.
+type MockWechatTokenStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWechatTokenStoreMockRecorder
+}
+
+// MockWechatTokenStoreMockRecorder is the mock recorder for MockWechatTokenStore.
+type MockWechatTokenStoreMockRecorder struct {
+	mock *MockWechatTokenStore
+}
+
+// NewMockWechatTokenStore creates a new mock instance.
+func NewMockWechatTokenStore(ctrl *gomock.Controller) *MockWechatTokenStore {
+	mock := &MockWechatTokenStore{ctrl: ctrl}
+	mock.recorder = &MockWechatTokenStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWechatTokenStore) EXPECT() *MockWechatTokenStoreMockRecorder {
+	return m.recorder
+}
+
+// JSAPITicket mocks base method.
+func (m *MockWechatTokenStore) JSAPITicket(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JSAPITicket", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JSAPITicket indicates an expected call of JSAPITicket.
+func (mr *MockWechatTokenStoreMockRecorder) JSAPITicket(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JSAPITicket", reflect.TypeOf((*MockWechatTokenStore)(nil).JSAPITicket), ctx)
+}
+
+// TokenStore mocks base method.
+func (m *MockWechatTokenStore) TokenStore() wechat.TokenStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TokenStore")
+	ret0, _ := ret[0].(wechat.TokenStore)
+	return ret0
+}
+
+// TokenStore indicates an expected call of TokenStore.
+func (mr *MockWechatTokenStoreMockRecorder) TokenStore() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TokenStore", reflect.TypeOf((*MockWechatTokenStore)(nil).TokenStore))
+}
+
+// WechatQuota mocks base method.
+func (m *MockWechatTokenStore) WechatQuota(appid string, limits map[string]int64) *wechat.Quota {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WechatQuota", appid, limits)
+	ret0, _ := ret[0].(*wechat.Quota)
+	return ret0
+}
+
+// WechatQuota indicates an expected call of WechatQuota.
+func (mr *MockWechatTokenStoreMockRecorder) WechatQuota(appid, limits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WechatQuota", reflect.TypeOf((*MockWechatTokenStore)(nil).WechatQuota), appid, limits)
+}
+
+// MockBannerService is a mock of BannerService interface.
+type MockBannerService struct {
+	ctrl     *gomock.Controller
+	recorder *MockBannerServiceMockRecorder
+}
+
+// MockBannerServiceMockRecorder is the mock recorder for MockBannerService.
+type MockBannerServiceMockRecorder struct {
+	mock *MockBannerService
+}
+
+// NewMockBannerService creates a new mock instance.
+func NewMockBannerService(ctrl *gomock.Controller) *MockBannerService {
+	mock := &MockBannerService{ctrl: ctrl}
+	mock.recorder = &MockBannerServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBannerService) EXPECT() *MockBannerServiceMockRecorder {
+	return m.recorder
+}
+
+// GetBannersByCity mocks base method.
+func (m *MockBannerService) GetBannersByCity(ctx context.Context, city string) ([]*model.Banner, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBannersByCity", ctx, city)
+	ret0, _ := ret[0].([]*model.Banner)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBannersByCity indicates an expected call of GetBannersByCity.
+func (mr *MockBannerServiceMockRecorder) GetBannersByCity(ctx, city interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBannersByCity", reflect.TypeOf((*MockBannerService)(nil).GetBannersByCity), ctx, city)
+}
+
+// SyncBannersByCity mocks base method.
+func (m *MockBannerService) SyncBannersByCity(ctx context.Context, city string, since time.Time, sinceID int) ([]*model.Banner, time.Time, int, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncBannersByCity", ctx, city, since, sinceID)
+	ret0, _ := ret[0].([]*model.Banner)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(int)
+	ret3, _ := ret[3].(bool)
+	ret4, _ := ret[4].(error)
+	return ret0, ret1, ret2, ret3, ret4
+}
+
+// SyncBannersByCity indicates an expected call of SyncBannersByCity.
+func (mr *MockBannerServiceMockRecorder) SyncBannersByCity(ctx, city, since, sinceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncBannersByCity", reflect.TypeOf((*MockBannerService)(nil).SyncBannersByCity), ctx, city, since, sinceID)
+}
+
+// MockContentService is a mock of ContentService interface.
+type MockContentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockContentServiceMockRecorder
+}
+
+// MockContentServiceMockRecorder is the mock recorder for MockContentService.
+type MockContentServiceMockRecorder struct {
+	mock *MockContentService
+}
+
+// NewMockContentService creates a new mock instance.
+func NewMockContentService(ctrl *gomock.Controller) *MockContentService {
+	mock := &MockContentService{ctrl: ctrl}
+	mock.recorder = &MockContentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContentService) EXPECT() *MockContentServiceMockRecorder {
+	return m.recorder
+}
+
+// GetContent mocks base method.
+func (m *MockContentService) GetContent(ctx context.Context, key string) (*model.Content, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContent", ctx, key)
+	ret0, _ := ret[0].(*model.Content)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContent indicates an expected call of GetContent.
+func (mr *MockContentServiceMockRecorder) GetContent(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContent", reflect.TypeOf((*MockContentService)(nil).GetContent), ctx, key)
+}
+
+// MockChaosService is a mock of ChaosService interface.
+type MockChaosService struct {
+	ctrl     *gomock.Controller
+	recorder *MockChaosServiceMockRecorder
+}
+
+// MockChaosServiceMockRecorder is the mock recorder for MockChaosService.
+type MockChaosServiceMockRecorder struct {
+	mock *MockChaosService
+}
+
+// NewMockChaosService creates a new mock instance.
+func NewMockChaosService(ctrl *gomock.Controller) *MockChaosService {
+	mock := &MockChaosService{ctrl: ctrl}
+	mock.recorder = &MockChaosServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChaosService) EXPECT() *MockChaosServiceMockRecorder {
+	return m.recorder
+}
+
+// GetChaosRules mocks base method.
+func (m *MockChaosService) GetChaosRules(ctx context.Context) (map[string]*model.ChaosRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChaosRules", ctx)
+	ret0, _ := ret[0].(map[string]*model.ChaosRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChaosRules indicates an expected call of GetChaosRules.
+func (mr *MockChaosServiceMockRecorder) GetChaosRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChaosRules", reflect.TypeOf((*MockChaosService)(nil).GetChaosRules), ctx)
+}
+
+// MockExperimentService is a mock of ExperimentService interface.
+type MockExperimentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockExperimentServiceMockRecorder
+}
+
+// MockExperimentServiceMockRecorder is the mock recorder for MockExperimentService.
+type MockExperimentServiceMockRecorder struct {
+	mock *MockExperimentService
+}
+
+// NewMockExperimentService creates a new mock instance.
+func NewMockExperimentService(ctrl *gomock.Controller) *MockExperimentService {
+	mock := &MockExperimentService{ctrl: ctrl}
+	mock.recorder = &MockExperimentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExperimentService) EXPECT() *MockExperimentServiceMockRecorder {
+	return m.recorder
+}
+
+// AllExperiments mocks base method.
+func (m *MockExperimentService) AllExperiments(ctx context.Context) ([]*model.Experiment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllExperiments", ctx)
+	ret0, _ := ret[0].([]*model.Experiment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllExperiments indicates an expected call of AllExperiments.
+func (mr *MockExperimentServiceMockRecorder) AllExperiments(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllExperiments", reflect.TypeOf((*MockExperimentService)(nil).AllExperiments), ctx)
+}
+
+// PushExposure mocks base method.
+func (m *MockExperimentService) PushExposure(ctx context.Context, data []*model.MsgExposure) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushExposure", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PushExposure indicates an expected call of PushExposure.
+func (mr *MockExperimentServiceMockRecorder) PushExposure(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushExposure", reflect.TypeOf((*MockExperimentService)(nil).PushExposure), ctx, data)
+}
+
+// MockSegmentService is a mock of SegmentService interface.
+type MockSegmentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSegmentServiceMockRecorder
+}
+
+// MockSegmentServiceMockRecorder is the mock recorder for MockSegmentService.
+type MockSegmentServiceMockRecorder struct {
+	mock *MockSegmentService
+}
+
+// NewMockSegmentService creates a new mock instance.
+func NewMockSegmentService(ctrl *gomock.Controller) *MockSegmentService {
+	mock := &MockSegmentService{ctrl: ctrl}
+	mock.recorder = &MockSegmentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSegmentService) EXPECT() *MockSegmentServiceMockRecorder {
+	return m.recorder
+}
+
+// UserSegments mocks base method.
+func (m *MockSegmentService) UserSegments(ctx context.Context, uid int) (map[string]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserSegments", ctx, uid)
+	ret0, _ := ret[0].(map[string]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserSegments indicates an expected call of UserSegments.
+func (mr *MockSegmentServiceMockRecorder) UserSegments(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserSegments", reflect.TypeOf((*MockSegmentService)(nil).UserSegments), ctx, uid)
+}
+
+// MockOrderService is a mock of OrderService interface.
+type MockOrderService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderServiceMockRecorder
+}
+
+// MockOrderServiceMockRecorder is the mock recorder for MockOrderService.
+type MockOrderServiceMockRecorder struct {
+	mock *MockOrderService
+}
+
+// NewMockOrderService creates a new mock instance.
+func NewMockOrderService(ctrl *gomock.Controller) *MockOrderService {
+	mock := &MockOrderService{ctrl: ctrl}
+	mock.recorder = &MockOrderServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderService) EXPECT() *MockOrderServiceMockRecorder {
+	return m.recorder
+}
+
+// CancelOrder mocks base method.
+func (m *MockOrderService) CancelOrder(ctx context.Context, order *model.Order, remark string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", ctx, order, remark)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockOrderServiceMockRecorder) CancelOrder(ctx, order, remark interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockOrderService)(nil).CancelOrder), ctx, order, remark)
+}
+
+// CompleteOrder mocks base method.
+func (m *MockOrderService) CompleteOrder(ctx context.Context, order *model.Order) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteOrder", ctx, order)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteOrder indicates an expected call of CompleteOrder.
+func (mr *MockOrderServiceMockRecorder) CompleteOrder(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteOrder", reflect.TypeOf((*MockOrderService)(nil).CompleteOrder), ctx, order)
+}
+
+// FindOrderByNo mocks base method.
+func (m *MockOrderService) FindOrderByNo(ctx context.Context, orderNo string) (*model.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrderByNo", ctx, orderNo)
+	ret0, _ := ret[0].(*model.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrderByNo indicates an expected call of FindOrderByNo.
+func (mr *MockOrderServiceMockRecorder) FindOrderByNo(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrderByNo", reflect.TypeOf((*MockOrderService)(nil).FindOrderByNo), ctx, orderNo)
+}
+
+// FinishRefund mocks base method.
+func (m *MockOrderService) FinishRefund(ctx context.Context, refundNo, refundID string, success bool, successTime int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishRefund", ctx, refundNo, refundID, success, successTime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishRefund indicates an expected call of FinishRefund.
+func (mr *MockOrderServiceMockRecorder) FinishRefund(ctx, refundNo, refundID, success, successTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishRefund", reflect.TypeOf((*MockOrderService)(nil).FinishRefund), ctx, refundNo, refundID, success, successTime)
+}
+
+// PayOrder mocks base method.
+func (m *MockOrderService) PayOrder(ctx context.Context, orderNo, transactionID string, amount, payTime int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PayOrder", ctx, orderNo, transactionID, amount, payTime)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PayOrder indicates an expected call of PayOrder.
+func (mr *MockOrderServiceMockRecorder) PayOrder(ctx, orderNo, transactionID, amount, payTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayOrder", reflect.TypeOf((*MockOrderService)(nil).PayOrder), ctx, orderNo, transactionID, amount, payTime)
+}
+
+// ScheduleOrderTimeout mocks base method.
+func (m *MockOrderService) ScheduleOrderTimeout(ctx context.Context, orderNo string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleOrderTimeout", ctx, orderNo)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleOrderTimeout indicates an expected call of ScheduleOrderTimeout.
+func (mr *MockOrderServiceMockRecorder) ScheduleOrderTimeout(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleOrderTimeout", reflect.TypeOf((*MockOrderService)(nil).ScheduleOrderTimeout), ctx, orderNo)
+}
+
+// SetOrderProvider mocks base method.
+func (m *MockOrderService) SetOrderProvider(ctx context.Context, order *model.Order, provider string, couponID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOrderProvider", ctx, order, provider, couponID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOrderProvider indicates an expected call of SetOrderProvider.
+func (mr *MockOrderServiceMockRecorder) SetOrderProvider(ctx, order, provider, couponID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrderProvider", reflect.TypeOf((*MockOrderService)(nil).SetOrderProvider), ctx, order, provider, couponID)
+}
+
+// MockCouponService is a mock of CouponService interface.
+type MockCouponService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCouponServiceMockRecorder
+}
+
+// MockCouponServiceMockRecorder is the mock recorder for MockCouponService.
+type MockCouponServiceMockRecorder struct {
+	mock *MockCouponService
+}
+
+// NewMockCouponService creates a new mock instance.
+func NewMockCouponService(ctrl *gomock.Controller) *MockCouponService {
+	mock := &MockCouponService{ctrl: ctrl}
+	mock.recorder = &MockCouponServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCouponService) EXPECT() *MockCouponServiceMockRecorder {
+	return m.recorder
+}
+
+// ClaimCoupon mocks base method.
+func (m *MockCouponService) ClaimCoupon(ctx context.Context, uid, templateID int) (*model.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimCoupon", ctx, uid, templateID)
+	ret0, _ := ret[0].(*model.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimCoupon indicates an expected call of ClaimCoupon.
+func (mr *MockCouponServiceMockRecorder) ClaimCoupon(ctx, uid, templateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimCoupon", reflect.TypeOf((*MockCouponService)(nil).ClaimCoupon), ctx, uid, templateID)
+}
+
+// ClaimableCoupons mocks base method.
+func (m *MockCouponService) ClaimableCoupons(ctx context.Context) ([]*model.CouponTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimableCoupons", ctx)
+	ret0, _ := ret[0].([]*model.CouponTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimableCoupons indicates an expected call of ClaimableCoupons.
+func (mr *MockCouponServiceMockRecorder) ClaimableCoupons(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimableCoupons", reflect.TypeOf((*MockCouponService)(nil).ClaimableCoupons), ctx)
+}
+
+// FindUserCoupons mocks base method.
+func (m *MockCouponService) FindUserCoupons(ctx context.Context, uid int, status int8) ([]*model.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserCoupons", ctx, uid, status)
+	ret0, _ := ret[0].([]*model.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserCoupons indicates an expected call of FindUserCoupons.
+func (mr *MockCouponServiceMockRecorder) FindUserCoupons(ctx, uid, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserCoupons", reflect.TypeOf((*MockCouponService)(nil).FindUserCoupons), ctx, uid, status)
+}
+
+// PriceCoupon mocks base method.
+func (m *MockCouponService) PriceCoupon(ctx context.Context, uid, couponID int, amount int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PriceCoupon", ctx, uid, couponID, amount)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PriceCoupon indicates an expected call of PriceCoupon.
+func (mr *MockCouponServiceMockRecorder) PriceCoupon(ctx, uid, couponID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PriceCoupon", reflect.TypeOf((*MockCouponService)(nil).PriceCoupon), ctx, uid, couponID, amount)
+}
+
+// MockStockService is a mock of StockService interface.
+type MockStockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockServiceMockRecorder
+}
+
+// MockStockServiceMockRecorder is the mock recorder for MockStockService.
+type MockStockServiceMockRecorder struct {
+	mock *MockStockService
+}
+
+// NewMockStockService creates a new mock instance.
+func NewMockStockService(ctrl *gomock.Controller) *MockStockService {
+	mock := &MockStockService{ctrl: ctrl}
+	mock.recorder = &MockStockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStockService) EXPECT() *MockStockServiceMockRecorder {
+	return m.recorder
+}
+
+// DeductStock mocks base method.
+func (m *MockStockService) DeductStock(ctx context.Context, orderNo string, items map[int]int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeductStock", ctx, orderNo, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeductStock indicates an expected call of DeductStock.
+func (mr *MockStockServiceMockRecorder) DeductStock(ctx, orderNo, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductStock", reflect.TypeOf((*MockStockService)(nil).DeductStock), ctx, orderNo, items)
+}
+
+// GetStock mocks base method.
+func (m *MockStockService) GetStock(ctx context.Context, skus []int) (map[int]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStock", ctx, skus)
+	ret0, _ := ret[0].(map[int]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStock indicates an expected call of GetStock.
+func (mr *MockStockServiceMockRecorder) GetStock(ctx, skus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStock", reflect.TypeOf((*MockStockService)(nil).GetStock), ctx, skus)
+}
+
+// RestockOrder mocks base method.
+func (m *MockStockService) RestockOrder(ctx context.Context, orderNo string, skus []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestockOrder", ctx, orderNo, skus)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestockOrder indicates an expected call of RestockOrder.
+func (mr *MockStockServiceMockRecorder) RestockOrder(ctx, orderNo, skus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestockOrder", reflect.TypeOf((*MockStockService)(nil).RestockOrder), ctx, orderNo, skus)
+}
+
+// MockLedgerService is a mock of LedgerService interface.
+type MockLedgerService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerServiceMockRecorder
+}
+
+// MockLedgerServiceMockRecorder is the mock recorder for MockLedgerService.
+type MockLedgerServiceMockRecorder struct {
+	mock *MockLedgerService
+}
+
+// NewMockLedgerService creates a new mock instance.
+func NewMockLedgerService(ctrl *gomock.Controller) *MockLedgerService {
+	mock := &MockLedgerService{ctrl: ctrl}
+	mock.recorder = &MockLedgerServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerService) EXPECT() *MockLedgerServiceMockRecorder {
+	return m.recorder
+}
+
+// Credit mocks base method.
+func (m *MockLedgerService) Credit(ctx context.Context, uid int, asset string, amount int64, refNo, bizType, remark string) (*model.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Credit", ctx, uid, asset, amount, refNo, bizType, remark)
+	ret0, _ := ret[0].(*model.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Credit indicates an expected call of Credit.
+func (mr *MockLedgerServiceMockRecorder) Credit(ctx, uid, asset, amount, refNo, bizType, remark interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Credit", reflect.TypeOf((*MockLedgerService)(nil).Credit), ctx, uid, asset, amount, refNo, bizType, remark)
+}
+
+// Debit mocks base method.
+func (m *MockLedgerService) Debit(ctx context.Context, uid int, asset string, amount int64, refNo, bizType, remark string) (*model.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Debit", ctx, uid, asset, amount, refNo, bizType, remark)
+	ret0, _ := ret[0].(*model.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Debit indicates an expected call of Debit.
+func (mr *MockLedgerServiceMockRecorder) Debit(ctx, uid, asset, amount, refNo, bizType, remark interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debit", reflect.TypeOf((*MockLedgerService)(nil).Debit), ctx, uid, asset, amount, refNo, bizType, remark)
+}
+
+// FindLedgerAccounts mocks base method.
+func (m *MockLedgerService) FindLedgerAccounts(ctx context.Context, uid int) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLedgerAccounts", ctx, uid)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLedgerAccounts indicates an expected call of FindLedgerAccounts.
+func (mr *MockLedgerServiceMockRecorder) FindLedgerAccounts(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLedgerAccounts", reflect.TypeOf((*MockLedgerService)(nil).FindLedgerAccounts), ctx, uid)
+}
+
+// PaginateLedgerEntry mocks base method.
+func (m *MockLedgerService) PaginateLedgerEntry(ctx context.Context, uid int, asset string, page, size int) (int64, []*model.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PaginateLedgerEntry", ctx, uid, asset, page, size)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].([]*model.LedgerEntry)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PaginateLedgerEntry indicates an expected call of PaginateLedgerEntry.
+func (mr *MockLedgerServiceMockRecorder) PaginateLedgerEntry(ctx, uid, asset, page, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaginateLedgerEntry", reflect.TypeOf((*MockLedgerService)(nil).PaginateLedgerEntry), ctx, uid, asset, page, size)
+}
+
+// MockPushService is a mock of PushService interface.
+type MockPushService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushServiceMockRecorder
+}
+
+// MockPushServiceMockRecorder is the mock recorder for MockPushService.
+type MockPushServiceMockRecorder struct {
+	mock *MockPushService
+}
+
+// NewMockPushService creates a new mock instance.
+func NewMockPushService(ctrl *gomock.Controller) *MockPushService {
+	mock := &MockPushService{ctrl: ctrl}
+	mock.recorder = &MockPushServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushService) EXPECT() *MockPushServiceMockRecorder {
+	return m.recorder
+}
+
+// FindPushDeliveries mocks base method.
+func (m *MockPushService) FindPushDeliveries(ctx context.Context, msgID string) ([]*model.PushDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPushDeliveries", ctx, msgID)
+	ret0, _ := ret[0].([]*model.PushDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPushDeliveries indicates an expected call of FindPushDeliveries.
+func (mr *MockPushServiceMockRecorder) FindPushDeliveries(ctx, msgID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPushDeliveries", reflect.TypeOf((*MockPushService)(nil).FindPushDeliveries), ctx, msgID)
+}
+
+// FindPushDevices mocks base method.
+func (m *MockPushService) FindPushDevices(ctx context.Context, uid int) ([]*model.PushDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPushDevices", ctx, uid)
+	ret0, _ := ret[0].([]*model.PushDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPushDevices indicates an expected call of FindPushDevices.
+func (mr *MockPushServiceMockRecorder) FindPushDevices(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPushDevices", reflect.TypeOf((*MockPushService)(nil).FindPushDevices), ctx, uid)
+}
+
+// GetPushPreference mocks base method.
+func (m *MockPushService) GetPushPreference(ctx context.Context, uid int) (*model.PushPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPushPreference", ctx, uid)
+	ret0, _ := ret[0].(*model.PushPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPushPreference indicates an expected call of GetPushPreference.
+func (mr *MockPushServiceMockRecorder) GetPushPreference(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPushPreference", reflect.TypeOf((*MockPushService)(nil).GetPushPreference), ctx, uid)
+}
+
+// Push mocks base method.
+func (m *MockPushService) Push(ctx context.Context, msg *model.MsgPush) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Push", ctx, msg)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Push indicates an expected call of Push.
+func (mr *MockPushServiceMockRecorder) Push(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockPushService)(nil).Push), ctx, msg)
+}
+
+// RegisterPushDevice mocks base method.
+func (m *MockPushService) RegisterPushDevice(ctx context.Context, d *model.PushDevice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterPushDevice", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterPushDevice indicates an expected call of RegisterPushDevice.
+func (mr *MockPushServiceMockRecorder) RegisterPushDevice(ctx, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterPushDevice", reflect.TypeOf((*MockPushService)(nil).RegisterPushDevice), ctx, d)
+}
+
+// SetPushPreference mocks base method.
+func (m *MockPushService) SetPushPreference(ctx context.Context, p *model.PushPreference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPushPreference", ctx, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPushPreference indicates an expected call of SetPushPreference.
+func (mr *MockPushServiceMockRecorder) SetPushPreference(ctx, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPushPreference", reflect.TypeOf((*MockPushService)(nil).SetPushPreference), ctx, p)
+}
+
+// UnregisterPushDevice mocks base method.
+func (m *MockPushService) UnregisterPushDevice(ctx context.Context, uid int, deviceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnregisterPushDevice", ctx, uid, deviceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnregisterPushDevice indicates an expected call of UnregisterPushDevice.
+func (mr *MockPushServiceMockRecorder) UnregisterPushDevice(ctx, uid, deviceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnregisterPushDevice", reflect.TypeOf((*MockPushService)(nil).UnregisterPushDevice), ctx, uid, deviceID)
+}
+
+// MockShortLinkService is a mock of ShortLinkService interface.
+type MockShortLinkService struct {
+	ctrl     *gomock.Controller
+	recorder *MockShortLinkServiceMockRecorder
+}
+
+// MockShortLinkServiceMockRecorder is the mock recorder for MockShortLinkService.
+type MockShortLinkServiceMockRecorder struct {
+	mock *MockShortLinkService
+}
+
+// NewMockShortLinkService creates a new mock instance.
+func NewMockShortLinkService(ctrl *gomock.Controller) *MockShortLinkService {
+	mock := &MockShortLinkService{ctrl: ctrl}
+	mock.recorder = &MockShortLinkServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShortLinkService) EXPECT() *MockShortLinkServiceMockRecorder {
+	return m.recorder
+}
+
+// PushShortLinkClick mocks base method.
+func (m *MockShortLinkService) PushShortLinkClick(ctx context.Context, click *shortlink.Click) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushShortLinkClick", ctx, click)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PushShortLinkClick indicates an expected call of PushShortLinkClick.
+func (mr *MockShortLinkServiceMockRecorder) PushShortLinkClick(ctx, click interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushShortLinkClick", reflect.TypeOf((*MockShortLinkService)(nil).PushShortLinkClick), ctx, click)
+}
+
+// ResolveShortLink mocks base method.
+func (m *MockShortLinkService) ResolveShortLink(ctx context.Context, code string) (*shortlink.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveShortLink", ctx, code)
+	ret0, _ := ret[0].(*shortlink.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveShortLink indicates an expected call of ResolveShortLink.
+func (mr *MockShortLinkServiceMockRecorder) ResolveShortLink(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveShortLink", reflect.TypeOf((*MockShortLinkService)(nil).ResolveShortLink), ctx, code)
+}
+
+// MockAppService is a mock of AppService interface.
+type MockAppService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAppServiceMockRecorder
+}
+
+// MockAppServiceMockRecorder is the mock recorder for MockAppService.
+type MockAppServiceMockRecorder struct {
+	mock *MockAppService
+}
+
+// NewMockAppService creates a new mock instance.
+func NewMockAppService(ctrl *gomock.Controller) *MockAppService {
+	mock := &MockAppService{ctrl: ctrl}
+	mock.recorder = &MockAppServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAppService) EXPECT() *MockAppServiceMockRecorder {
+	return m.recorder
+}
+
+// GetAppVersions mocks base method.
+func (m *MockAppService) GetAppVersions(ctx context.Context) (map[string]*model.AppVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppVersions", ctx)
+	ret0, _ := ret[0].(map[string]*model.AppVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppVersions indicates an expected call of GetAppVersions.
+func (mr *MockAppServiceMockRecorder) GetAppVersions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppVersions", reflect.TypeOf((*MockAppService)(nil).GetAppVersions), ctx)
+}
+
+// MockMeterService is a mock of MeterService interface.
+type MockMeterService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMeterServiceMockRecorder
+}
+
+// MockMeterServiceMockRecorder is the mock recorder for MockMeterService.
+type MockMeterServiceMockRecorder struct {
+	mock *MockMeterService
+}
+
+// NewMockMeterService creates a new mock instance.
+func NewMockMeterService(ctrl *gomock.Controller) *MockMeterService {
+	mock := &MockMeterService{ctrl: ctrl}
+	mock.recorder = &MockMeterServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMeterService) EXPECT() *MockMeterServiceMockRecorder {
+	return m.recorder
+}
+
+// AllAPIKeys mocks base method.
+func (m *MockMeterService) AllAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllAPIKeys", ctx)
+	ret0, _ := ret[0].([]*model.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllAPIKeys indicates an expected call of AllAPIKeys.
+func (mr *MockMeterServiceMockRecorder) AllAPIKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllAPIKeys", reflect.TypeOf((*MockMeterService)(nil).AllAPIKeys), ctx)
+}
+
+// FindAPIUsage mocks base method.
+func (m *MockMeterService) FindAPIUsage(ctx context.Context, subject string, begin, end int) ([]*model.APIUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAPIUsage", ctx, subject, begin, end)
+	ret0, _ := ret[0].([]*model.APIUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAPIUsage indicates an expected call of FindAPIUsage.
+func (mr *MockMeterServiceMockRecorder) FindAPIUsage(ctx, subject, begin, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAPIUsage", reflect.TypeOf((*MockMeterService)(nil).FindAPIUsage), ctx, subject, begin, end)
+}
+
+// IncrHTTPStat mocks base method.
+func (m *MockMeterService) IncrHTTPStat(ctx context.Context, date string, status int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrHTTPStat", ctx, date, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrHTTPStat indicates an expected call of IncrHTTPStat.
+func (mr *MockMeterServiceMockRecorder) IncrHTTPStat(ctx, date, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrHTTPStat", reflect.TypeOf((*MockMeterService)(nil).IncrHTTPStat), ctx, date, status)
+}
+
+// IncrUsage mocks base method.
+func (m *MockMeterService) IncrUsage(ctx context.Context, date string, fields ...string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, date}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "IncrUsage", varargs...)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrUsage indicates an expected call of IncrUsage.
+func (mr *MockMeterServiceMockRecorder) IncrUsage(ctx, date interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, date}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrUsage", reflect.TypeOf((*MockMeterService)(nil).IncrUsage), varargs...)
+}
+
+// MockCaptchaService is a mock of CaptchaService interface.
+type MockCaptchaService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCaptchaServiceMockRecorder
+}
+
+// MockCaptchaServiceMockRecorder is the mock recorder for MockCaptchaService.
+type MockCaptchaServiceMockRecorder struct {
+	mock *MockCaptchaService
+}
+
+// NewMockCaptchaService creates a new mock instance.
+func NewMockCaptchaService(ctrl *gomock.Controller) *MockCaptchaService {
+	mock := &MockCaptchaService{ctrl: ctrl}
+	mock.recorder = &MockCaptchaServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCaptchaService) EXPECT() *MockCaptchaServiceMockRecorder {
+	return m.recorder
+}
+
+// CaptchaRequired mocks base method.
+func (m *MockCaptchaService) CaptchaRequired(ctx context.Context, subject string, limit int64, window time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptchaRequired", ctx, subject, limit, window)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptchaRequired indicates an expected call of CaptchaRequired.
+func (mr *MockCaptchaServiceMockRecorder) CaptchaRequired(ctx, subject, limit, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptchaRequired", reflect.TypeOf((*MockCaptchaService)(nil).CaptchaRequired), ctx, subject, limit, window)
+}
+
+// FlagCaptcha mocks base method.
+func (m *MockCaptchaService) FlagCaptcha(ctx context.Context, subject string, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlagCaptcha", ctx, subject, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FlagCaptcha indicates an expected call of FlagCaptcha.
+func (mr *MockCaptchaServiceMockRecorder) FlagCaptcha(ctx, subject, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagCaptcha", reflect.TypeOf((*MockCaptchaService)(nil).FlagCaptcha), ctx, subject, ttl)
+}
+
+// NewCaptcha mocks base method.
+func (m *MockCaptchaService) NewCaptcha(ctx context.Context, kind string) (*captcha.Challenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewCaptcha", ctx, kind)
+	ret0, _ := ret[0].(*captcha.Challenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewCaptcha indicates an expected call of NewCaptcha.
+func (mr *MockCaptchaServiceMockRecorder) NewCaptcha(ctx, kind interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewCaptcha", reflect.TypeOf((*MockCaptchaService)(nil).NewCaptcha), ctx, kind)
+}
+
+// RedeemCaptcha mocks base method.
+func (m *MockCaptchaService) RedeemCaptcha(ctx context.Context, ticket string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemCaptcha", ctx, ticket)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemCaptcha indicates an expected call of RedeemCaptcha.
+func (mr *MockCaptchaServiceMockRecorder) RedeemCaptcha(ctx, ticket interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemCaptcha", reflect.TypeOf((*MockCaptchaService)(nil).RedeemCaptcha), ctx, ticket)
+}
+
+// VerifyCaptcha mocks base method.
+func (m *MockCaptchaService) VerifyCaptcha(ctx context.Context, id, answer string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyCaptcha", ctx, id, answer)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyCaptcha indicates an expected call of VerifyCaptcha.
+func (mr *MockCaptchaServiceMockRecorder) VerifyCaptcha(ctx, id, answer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyCaptcha", reflect.TypeOf((*MockCaptchaService)(nil).VerifyCaptcha), ctx, id, answer)
+}
+
+// MockExchangeService is a mock of ExchangeService interface.
+type MockExchangeService struct {
+	ctrl     *gomock.Controller
+	recorder *MockExchangeServiceMockRecorder
+}
+
+// MockExchangeServiceMockRecorder is the mock recorder for MockExchangeService.
+type MockExchangeServiceMockRecorder struct {
+	mock *MockExchangeService
+}
+
+// NewMockExchangeService creates a new mock instance.
+func NewMockExchangeService(ctrl *gomock.Controller) *MockExchangeService {
+	mock := &MockExchangeService{ctrl: ctrl}
+	mock.recorder = &MockExchangeServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExchangeService) EXPECT() *MockExchangeServiceMockRecorder {
+	return m.recorder
+}
+
+// ExchangePoints mocks base method.
+func (m *MockExchangeService) ExchangePoints(ctx context.Context, uid, skuID, quantity int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExchangePoints", ctx, uid, skuID, quantity)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExchangePoints indicates an expected call of ExchangePoints.
+func (mr *MockExchangeServiceMockRecorder) ExchangePoints(ctx, uid, skuID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExchangePoints", reflect.TypeOf((*MockExchangeService)(nil).ExchangePoints), ctx, uid, skuID, quantity)
+}
+
+// MockTrackService is a mock of TrackService interface.
+type MockTrackService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrackServiceMockRecorder
+}
+
+// MockTrackServiceMockRecorder is the mock recorder for MockTrackService.
+type MockTrackServiceMockRecorder struct {
+	mock *MockTrackService
+}
+
+// NewMockTrackService creates a new mock instance.
+func NewMockTrackService(ctrl *gomock.Controller) *MockTrackService {
+	mock := &MockTrackService{ctrl: ctrl}
+	mock.recorder = &MockTrackServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrackService) EXPECT() *MockTrackServiceMockRecorder {
+	return m.recorder
+}
+
+// Track mocks base method.
+func (m *MockTrackService) Track(ctx context.Context, uid int, p track.Props) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Track", ctx, uid, p)
+}
+
+// Track indicates an expected call of Track.
+func (mr *MockTrackServiceMockRecorder) Track(ctx, uid, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Track", reflect.TypeOf((*MockTrackService)(nil).Track), ctx, uid, p)
+}
+
+// MockPresenceService is a mock of PresenceService interface.
+type MockPresenceService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPresenceServiceMockRecorder
+}
+
+// MockPresenceServiceMockRecorder is the mock recorder for MockPresenceService.
+type MockPresenceServiceMockRecorder struct {
+	mock *MockPresenceService
+}
+
+// NewMockPresenceService creates a new mock instance.
+func NewMockPresenceService(ctrl *gomock.Controller) *MockPresenceService {
+	mock := &MockPresenceService{ctrl: ctrl}
+	mock.recorder = &MockPresenceServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPresenceService) EXPECT() *MockPresenceServiceMockRecorder {
+	return m.recorder
+}
+
+// GetPresence mocks base method.
+func (m *MockPresenceService) GetPresence(ctx context.Context, uids []int) (map[int]*model.UserPresence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPresence", ctx, uids)
+	ret0, _ := ret[0].(map[int]*model.UserPresence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPresence indicates an expected call of GetPresence.
+func (mr *MockPresenceServiceMockRecorder) GetPresence(ctx, uids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPresence", reflect.TypeOf((*MockPresenceService)(nil).GetPresence), ctx, uids)
+}
+
+// IsOnline mocks base method.
+func (m *MockPresenceService) IsOnline(ctx context.Context, uid int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsOnline", ctx, uid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsOnline indicates an expected call of IsOnline.
+func (mr *MockPresenceServiceMockRecorder) IsOnline(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsOnline", reflect.TypeOf((*MockPresenceService)(nil).IsOnline), ctx, uid)
+}
+
+// PresenceHeartbeat mocks base method.
+func (m *MockPresenceService) PresenceHeartbeat(ctx context.Context, uid int, connID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresenceHeartbeat", ctx, uid, connID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresenceHeartbeat indicates an expected call of PresenceHeartbeat.
+func (mr *MockPresenceServiceMockRecorder) PresenceHeartbeat(ctx, uid, connID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresenceHeartbeat", reflect.TypeOf((*MockPresenceService)(nil).PresenceHeartbeat), ctx, uid, connID)
+}
+
+// PresenceLeave mocks base method.
+func (m *MockPresenceService) PresenceLeave(ctx context.Context, uid int, connID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresenceLeave", ctx, uid, connID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PresenceLeave indicates an expected call of PresenceLeave.
+func (mr *MockPresenceServiceMockRecorder) PresenceLeave(ctx, uid, connID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresenceLeave", reflect.TypeOf((*MockPresenceService)(nil).PresenceLeave), ctx, uid, connID)
+}
+
+// MockChatService is a mock of ChatService interface.
+type MockChatService struct {
+	ctrl     *gomock.Controller
+	recorder *MockChatServiceMockRecorder
+}
+
+// MockChatServiceMockRecorder is the mock recorder for MockChatService.
+type MockChatServiceMockRecorder struct {
+	mock *MockChatService
+}
+
+// NewMockChatService creates a new mock instance.
+func NewMockChatService(ctrl *gomock.Controller) *MockChatService {
+	mock := &MockChatService{ctrl: ctrl}
+	mock.recorder = &MockChatServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChatService) EXPECT() *MockChatServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateGroupChat mocks base method.
+func (m *MockChatService) CreateGroupChat(ctx context.Context, uid int, name string, members []int) (*model.ChatConversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroupChat", ctx, uid, name, members)
+	ret0, _ := ret[0].(*model.ChatConversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroupChat indicates an expected call of CreateGroupChat.
+func (mr *MockChatServiceMockRecorder) CreateGroupChat(ctx, uid, name, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroupChat", reflect.TypeOf((*MockChatService)(nil).CreateGroupChat), ctx, uid, name, members)
+}
+
+// FindChatConversations mocks base method.
+func (m *MockChatService) FindChatConversations(ctx context.Context, uid, page, size int) ([]*proto.ChatConversationItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChatConversations", ctx, uid, page, size)
+	ret0, _ := ret[0].([]*proto.ChatConversationItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChatConversations indicates an expected call of FindChatConversations.
+func (mr *MockChatServiceMockRecorder) FindChatConversations(ctx, uid, page, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChatConversations", reflect.TypeOf((*MockChatService)(nil).FindChatConversations), ctx, uid, page, size)
+}
+
+// FindChatMembers mocks base method.
+func (m *MockChatService) FindChatMembers(ctx context.Context, uid, cid int) ([]*model.ChatMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChatMembers", ctx, uid, cid)
+	ret0, _ := ret[0].([]*model.ChatMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChatMembers indicates an expected call of FindChatMembers.
+func (mr *MockChatServiceMockRecorder) FindChatMembers(ctx, uid, cid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChatMembers", reflect.TypeOf((*MockChatService)(nil).FindChatMembers), ctx, uid, cid)
+}
+
+// FindChatMessages mocks base method.
+func (m *MockChatService) FindChatMessages(ctx context.Context, uid, cid int, before, after int64, size int) ([]*model.ChatMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChatMessages", ctx, uid, cid, before, after, size)
+	ret0, _ := ret[0].([]*model.ChatMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChatMessages indicates an expected call of FindChatMessages.
+func (mr *MockChatServiceMockRecorder) FindChatMessages(ctx, uid, cid, before, after, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChatMessages", reflect.TypeOf((*MockChatService)(nil).FindChatMessages), ctx, uid, cid, before, after, size)
+}
+
+// OpenSingleChat mocks base method.
+func (m *MockChatService) OpenSingleChat(ctx context.Context, uid, peer int) (*model.ChatConversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenSingleChat", ctx, uid, peer)
+	ret0, _ := ret[0].(*model.ChatConversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenSingleChat indicates an expected call of OpenSingleChat.
+func (mr *MockChatServiceMockRecorder) OpenSingleChat(ctx, uid, peer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenSingleChat", reflect.TypeOf((*MockChatService)(nil).OpenSingleChat), ctx, uid, peer)
+}
+
+// ReadChat mocks base method.
+func (m *MockChatService) ReadChat(ctx context.Context, uid, cid int, seq int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadChat", ctx, uid, cid, seq)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadChat indicates an expected call of ReadChat.
+func (mr *MockChatServiceMockRecorder) ReadChat(ctx, uid, cid, seq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadChat", reflect.TypeOf((*MockChatService)(nil).ReadChat), ctx, uid, cid, seq)
+}
+
+// SendChatMessage mocks base method.
+func (m *MockChatService) SendChatMessage(ctx context.Context, msg *model.ChatMessage) (*model.ChatMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendChatMessage", ctx, msg)
+	ret0, _ := ret[0].(*model.ChatMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendChatMessage indicates an expected call of SendChatMessage.
+func (mr *MockChatServiceMockRecorder) SendChatMessage(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendChatMessage", reflect.TypeOf((*MockChatService)(nil).SendChatMessage), ctx, msg)
+}
+
+// MockStoreService is a mock of StoreService interface.
+type MockStoreService struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreServiceMockRecorder
+}
+
+// MockStoreServiceMockRecorder is the mock recorder for MockStoreService.
+type MockStoreServiceMockRecorder struct {
+	mock *MockStoreService
+}
+
+// NewMockStoreService creates a new mock instance.
+func NewMockStoreService(ctrl *gomock.Controller) *MockStoreService {
+	mock := &MockStoreService{ctrl: ctrl}
+	mock.recorder = &MockStoreServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStoreService) EXPECT() *MockStoreServiceMockRecorder {
+	return m.recorder
+}
+
+// FindStore mocks base method.
+func (m *MockStoreService) FindStore(ctx context.Context, id int) (*proto.StoreItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindStore", ctx, id)
+	ret0, _ := ret[0].(*proto.StoreItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindStore indicates an expected call of FindStore.
+func (mr *MockStoreServiceMockRecorder) FindStore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindStore", reflect.TypeOf((*MockStoreService)(nil).FindStore), ctx, id)
+}
+
+// NearbyStores mocks base method.
+func (m *MockStoreService) NearbyStores(ctx context.Context, lng, lat float64, radius, limit int) ([]*proto.StoreItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NearbyStores", ctx, lng, lat, radius, limit)
+	ret0, _ := ret[0].([]*proto.StoreItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NearbyStores indicates an expected call of NearbyStores.
+func (mr *MockStoreServiceMockRecorder) NearbyStores(ctx, lng, lat, radius, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NearbyStores", reflect.TypeOf((*MockStoreService)(nil).NearbyStores), ctx, lng, lat, radius, limit)
+}
+
+// MockAddressService is a mock of AddressService interface.
+type MockAddressService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAddressServiceMockRecorder
+}
+
+// MockAddressServiceMockRecorder is the mock recorder for MockAddressService.
+type MockAddressServiceMockRecorder struct {
+	mock *MockAddressService
+}
+
+// NewMockAddressService creates a new mock instance.
+func NewMockAddressService(ctrl *gomock.Controller) *MockAddressService {
+	mock := &MockAddressService{ctrl: ctrl}
+	mock.recorder = &MockAddressServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAddressService) EXPECT() *MockAddressServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateAddress mocks base method.
+func (m *MockAddressService) CreateAddress(ctx context.Context, addr *model.UserAddress) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAddress", ctx, addr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAddress indicates an expected call of CreateAddress.
+func (mr *MockAddressServiceMockRecorder) CreateAddress(ctx, addr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAddress", reflect.TypeOf((*MockAddressService)(nil).CreateAddress), ctx, addr)
+}
+
+// DeleteAddress mocks base method.
+func (m *MockAddressService) DeleteAddress(ctx context.Context, uid, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAddress", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAddress indicates an expected call of DeleteAddress.
+func (mr *MockAddressServiceMockRecorder) DeleteAddress(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAddress", reflect.TypeOf((*MockAddressService)(nil).DeleteAddress), ctx, uid, id)
+}
+
+// FindAddress mocks base method.
+func (m *MockAddressService) FindAddress(ctx context.Context, uid, id int) (*model.UserAddress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAddress", ctx, uid, id)
+	ret0, _ := ret[0].(*model.UserAddress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAddress indicates an expected call of FindAddress.
+func (mr *MockAddressServiceMockRecorder) FindAddress(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAddress", reflect.TypeOf((*MockAddressService)(nil).FindAddress), ctx, uid, id)
+}
+
+// FindAddresses mocks base method.
+func (m *MockAddressService) FindAddresses(ctx context.Context, uid int) ([]*model.UserAddress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAddresses", ctx, uid)
+	ret0, _ := ret[0].([]*model.UserAddress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAddresses indicates an expected call of FindAddresses.
+func (mr *MockAddressServiceMockRecorder) FindAddresses(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAddresses", reflect.TypeOf((*MockAddressService)(nil).FindAddresses), ctx, uid)
+}
+
+// Regions mocks base method.
+func (m *MockAddressService) Regions() *region.Dataset {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Regions")
+	ret0, _ := ret[0].(*region.Dataset)
+	return ret0
+}
+
+// Regions indicates an expected call of Regions.
+func (mr *MockAddressServiceMockRecorder) Regions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Regions", reflect.TypeOf((*MockAddressService)(nil).Regions))
+}
+
+// SetDefaultAddress mocks base method.
+func (m *MockAddressService) SetDefaultAddress(ctx context.Context, uid, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDefaultAddress", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDefaultAddress indicates an expected call of SetDefaultAddress.
+func (mr *MockAddressServiceMockRecorder) SetDefaultAddress(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultAddress", reflect.TypeOf((*MockAddressService)(nil).SetDefaultAddress), ctx, uid, id)
+}
+
+// UpdateAddress mocks base method.
+func (m *MockAddressService) UpdateAddress(ctx context.Context, addr *model.UserAddress) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAddress", ctx, addr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAddress indicates an expected call of UpdateAddress.
+func (mr *MockAddressServiceMockRecorder) UpdateAddress(ctx, addr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAddress", reflect.TypeOf((*MockAddressService)(nil).UpdateAddress), ctx, addr)
+}
+
+// MockBookingService is a mock of BookingService interface.
+type MockBookingService struct {
+	ctrl     *gomock.Controller
+	recorder *MockBookingServiceMockRecorder
+}
+
+// MockBookingServiceMockRecorder is the mock recorder for MockBookingService.
+type MockBookingServiceMockRecorder struct {
+	mock *MockBookingService
+}
+
+// NewMockBookingService creates a new mock instance.
+func NewMockBookingService(ctrl *gomock.Controller) *MockBookingService {
+	mock := &MockBookingService{ctrl: ctrl}
+	mock.recorder = &MockBookingServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBookingService) EXPECT() *MockBookingServiceMockRecorder {
+	return m.recorder
+}
+
+// CancelBooking mocks base method.
+func (m *MockBookingService) CancelBooking(ctx context.Context, uid, id int, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelBooking", ctx, uid, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelBooking indicates an expected call of CancelBooking.
+func (mr *MockBookingServiceMockRecorder) CancelBooking(ctx, uid, id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelBooking", reflect.TypeOf((*MockBookingService)(nil).CancelBooking), ctx, uid, id, reason)
+}
+
+// ConfirmBooking mocks base method.
+func (m *MockBookingService) ConfirmBooking(ctx context.Context, uid, id int) (*model.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmBooking", ctx, uid, id)
+	ret0, _ := ret[0].(*model.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmBooking indicates an expected call of ConfirmBooking.
+func (mr *MockBookingServiceMockRecorder) ConfirmBooking(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmBooking", reflect.TypeOf((*MockBookingService)(nil).ConfirmBooking), ctx, uid, id)
+}
+
+// FindBookingResources mocks base method.
+func (m *MockBookingService) FindBookingResources(ctx context.Context) ([]*model.BookingResource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBookingResources", ctx)
+	ret0, _ := ret[0].([]*model.BookingResource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBookingResources indicates an expected call of FindBookingResources.
+func (mr *MockBookingServiceMockRecorder) FindBookingResources(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBookingResources", reflect.TypeOf((*MockBookingService)(nil).FindBookingResources), ctx)
+}
+
+// FindBookingSlots mocks base method.
+func (m *MockBookingService) FindBookingSlots(ctx context.Context, resourceID int, date time.Time) ([]*proto.BookingSlot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBookingSlots", ctx, resourceID, date)
+	ret0, _ := ret[0].([]*proto.BookingSlot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBookingSlots indicates an expected call of FindBookingSlots.
+func (mr *MockBookingServiceMockRecorder) FindBookingSlots(ctx, resourceID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBookingSlots", reflect.TypeOf((*MockBookingService)(nil).FindBookingSlots), ctx, resourceID, date)
+}
+
+// FindBookings mocks base method.
+func (m *MockBookingService) FindBookings(ctx context.Context, uid, page, size int) ([]*proto.BookingItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBookings", ctx, uid, page, size)
+	ret0, _ := ret[0].([]*proto.BookingItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBookings indicates an expected call of FindBookings.
+func (mr *MockBookingServiceMockRecorder) FindBookings(ctx, uid, page, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBookings", reflect.TypeOf((*MockBookingService)(nil).FindBookings), ctx, uid, page, size)
+}
+
+// ReserveBooking mocks base method.
+func (m *MockBookingService) ReserveBooking(ctx context.Context, b *model.Booking, date time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveBooking", ctx, b, date)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveBooking indicates an expected call of ReserveBooking.
+func (mr *MockBookingServiceMockRecorder) ReserveBooking(ctx, b, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveBooking", reflect.TypeOf((*MockBookingService)(nil).ReserveBooking), ctx, b, date)
+}
+
+// MockCheckinService is a mock of CheckinService interface.
+type MockCheckinService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCheckinServiceMockRecorder
+}
+
+// MockCheckinServiceMockRecorder is the mock recorder for MockCheckinService.
+type MockCheckinServiceMockRecorder struct {
+	mock *MockCheckinService
+}
+
+// NewMockCheckinService creates a new mock instance.
+func NewMockCheckinService(ctrl *gomock.Controller) *MockCheckinService {
+	mock := &MockCheckinService{ctrl: ctrl}
+	mock.recorder = &MockCheckinServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCheckinService) EXPECT() *MockCheckinServiceMockRecorder {
+	return m.recorder
+}
+
+// Checkin mocks base method.
+func (m *MockCheckinService) Checkin(ctx context.Context, uid int, tz string) (*proto.CheckinResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkin", ctx, uid, tz)
+	ret0, _ := ret[0].(*proto.CheckinResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkin indicates an expected call of Checkin.
+func (mr *MockCheckinServiceMockRecorder) Checkin(ctx, uid, tz interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkin", reflect.TypeOf((*MockCheckinService)(nil).Checkin), ctx, uid, tz)
+}
+
+// FindCheckinCalendar mocks base method.
+func (m *MockCheckinService) FindCheckinCalendar(ctx context.Context, uid int, month string) ([]*model.CheckinLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCheckinCalendar", ctx, uid, month)
+	ret0, _ := ret[0].([]*model.CheckinLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCheckinCalendar indicates an expected call of FindCheckinCalendar.
+func (mr *MockCheckinServiceMockRecorder) FindCheckinCalendar(ctx, uid, month interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCheckinCalendar", reflect.TypeOf((*MockCheckinService)(nil).FindCheckinCalendar), ctx, uid, month)
+}
+
+// FindCheckinStatus mocks base method.
+func (m *MockCheckinService) FindCheckinStatus(ctx context.Context, uid int, tz string) (*proto.CheckinStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCheckinStatus", ctx, uid, tz)
+	ret0, _ := ret[0].(*proto.CheckinStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCheckinStatus indicates an expected call of FindCheckinStatus.
+func (mr *MockCheckinServiceMockRecorder) FindCheckinStatus(ctx, uid, tz interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCheckinStatus", reflect.TypeOf((*MockCheckinService)(nil).FindCheckinStatus), ctx, uid, tz)
+}
+
+// MockLotteryService is a mock of LotteryService interface.
+type MockLotteryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLotteryServiceMockRecorder
+}
+
+// MockLotteryServiceMockRecorder is the mock recorder for MockLotteryService.
+type MockLotteryServiceMockRecorder struct {
+	mock *MockLotteryService
+}
+
+// NewMockLotteryService creates a new mock instance.
+func NewMockLotteryService(ctrl *gomock.Controller) *MockLotteryService {
+	mock := &MockLotteryService{ctrl: ctrl}
+	mock.recorder = &MockLotteryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLotteryService) EXPECT() *MockLotteryServiceMockRecorder {
+	return m.recorder
+}
+
+// DrawLottery mocks base method.
+func (m *MockLotteryService) DrawLottery(ctx context.Context, uid, id int) (*model.LotteryDraw, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DrawLottery", ctx, uid, id)
+	ret0, _ := ret[0].(*model.LotteryDraw)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DrawLottery indicates an expected call of DrawLottery.
+func (mr *MockLotteryServiceMockRecorder) DrawLottery(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DrawLottery", reflect.TypeOf((*MockLotteryService)(nil).DrawLottery), ctx, uid, id)
+}
+
+// FindLottery mocks base method.
+func (m *MockLotteryService) FindLottery(ctx context.Context, id int) (*model.Lottery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLottery", ctx, id)
+	ret0, _ := ret[0].(*model.Lottery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLottery indicates an expected call of FindLottery.
+func (mr *MockLotteryServiceMockRecorder) FindLottery(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLottery", reflect.TypeOf((*MockLotteryService)(nil).FindLottery), ctx, id)
+}
+
+// FindLotteryChances mocks base method.
+func (m *MockLotteryService) FindLotteryChances(ctx context.Context, uid, id int) (*proto.LotteryChances, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLotteryChances", ctx, uid, id)
+	ret0, _ := ret[0].(*proto.LotteryChances)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLotteryChances indicates an expected call of FindLotteryChances.
+func (mr *MockLotteryServiceMockRecorder) FindLotteryChances(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLotteryChances", reflect.TypeOf((*MockLotteryService)(nil).FindLotteryChances), ctx, uid, id)
+}
+
+// FindLotteryWins mocks base method.
+func (m *MockLotteryService) FindLotteryWins(ctx context.Context, uid, lotteryID, page, size int) ([]*model.LotteryDraw, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLotteryWins", ctx, uid, lotteryID, page, size)
+	ret0, _ := ret[0].([]*model.LotteryDraw)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLotteryWins indicates an expected call of FindLotteryWins.
+func (mr *MockLotteryServiceMockRecorder) FindLotteryWins(ctx, uid, lotteryID, page, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLotteryWins", reflect.TypeOf((*MockLotteryService)(nil).FindLotteryWins), ctx, uid, lotteryID, page, size)
+}
+
+// MockRecordService is a mock of RecordService interface.
+type MockRecordService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecordServiceMockRecorder
+}
+
+// MockRecordServiceMockRecorder is the mock recorder for MockRecordService.
+type MockRecordServiceMockRecorder struct {
+	mock *MockRecordService
+}
+
+// NewMockRecordService creates a new mock instance.
+func NewMockRecordService(ctrl *gomock.Controller) *MockRecordService {
+	mock := &MockRecordService{ctrl: ctrl}
+	mock.recorder = &MockRecordServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecordService) EXPECT() *MockRecordServiceMockRecorder {
+	return m.recorder
+}
+
+// GetRecords mocks base method.
+func (m *MockRecordService) GetRecords(ctx context.Context, traceID string) ([]*logger.Exchange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecords", ctx, traceID)
+	ret0, _ := ret[0].([]*logger.Exchange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecords indicates an expected call of GetRecords.
+func (mr *MockRecordServiceMockRecorder) GetRecords(ctx, traceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecords", reflect.TypeOf((*MockRecordService)(nil).GetRecords), ctx, traceID)
+}
+
+// SaveRecord mocks base method.
+func (m *MockRecordService) SaveRecord(ctx context.Context, ex *logger.Exchange, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRecord", ctx, ex, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRecord indicates an expected call of SaveRecord.
+func (mr *MockRecordServiceMockRecorder) SaveRecord(ctx, ex, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRecord", reflect.TypeOf((*MockRecordService)(nil).SaveRecord), ctx, ex, ttl)
+}
+
+// MockInterface is a mock of Interface interface.
+type MockInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterfaceMockRecorder
+}
+
+// MockInterfaceMockRecorder is the mock recorder for MockInterface.
+type MockInterfaceMockRecorder struct {
+	mock *MockInterface
+}
+
+// NewMockInterface creates a new mock instance.
+func NewMockInterface(ctrl *gomock.Controller) *MockInterface {
+	mock := &MockInterface{ctrl: ctrl}
+	mock.recorder = &MockInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
+	return m.recorder
+}
+
+// AllAPIKeys mocks base method.
+func (m *MockInterface) AllAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllAPIKeys", ctx)
+	ret0, _ := ret[0].([]*model.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllAPIKeys indicates an expected call of AllAPIKeys.
+func (mr *MockInterfaceMockRecorder) AllAPIKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllAPIKeys", reflect.TypeOf((*MockInterface)(nil).AllAPIKeys), ctx)
+}
+
+// AllExperiments mocks base method.
+func (m *MockInterface) AllExperiments(ctx context.Context) ([]*model.Experiment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllExperiments", ctx)
+	ret0, _ := ret[0].([]*model.Experiment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllExperiments indicates an expected call of AllExperiments.
+func (mr *MockInterfaceMockRecorder) AllExperiments(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllExperiments", reflect.TypeOf((*MockInterface)(nil).AllExperiments), ctx)
+}
+
+// CancelBooking mocks base method.
+func (m *MockInterface) CancelBooking(ctx context.Context, uid, id int, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelBooking", ctx, uid, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelBooking indicates an expected call of CancelBooking.
+func (mr *MockInterfaceMockRecorder) CancelBooking(ctx, uid, id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelBooking", reflect.TypeOf((*MockInterface)(nil).CancelBooking), ctx, uid, id, reason)
+}
+
+// CancelOrder mocks base method.
+func (m *MockInterface) CancelOrder(ctx context.Context, order *model.Order, remark string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", ctx, order, remark)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockInterfaceMockRecorder) CancelOrder(ctx, order, remark interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockInterface)(nil).CancelOrder), ctx, order, remark)
+}
+
+// CaptchaRequired mocks base method.
+func (m *MockInterface) CaptchaRequired(ctx context.Context, subject string, limit int64, window time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptchaRequired", ctx, subject, limit, window)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptchaRequired indicates an expected call of CaptchaRequired.
+func (mr *MockInterfaceMockRecorder) CaptchaRequired(ctx, subject, limit, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptchaRequired", reflect.TypeOf((*MockInterface)(nil).CaptchaRequired), ctx, subject, limit, window)
+}
+
+// Checkin mocks base method.
+func (m *MockInterface) Checkin(ctx context.Context, uid int, tz string) (*proto.CheckinResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkin", ctx, uid, tz)
+	ret0, _ := ret[0].(*proto.CheckinResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkin indicates an expected call of Checkin.
+func (mr *MockInterfaceMockRecorder) Checkin(ctx, uid, tz interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkin", reflect.TypeOf((*MockInterface)(nil).Checkin), ctx, uid, tz)
+}
+
+// ClaimCoupon mocks base method.
+func (m *MockInterface) ClaimCoupon(ctx context.Context, uid, templateID int) (*model.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimCoupon", ctx, uid, templateID)
+	ret0, _ := ret[0].(*model.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimCoupon indicates an expected call of ClaimCoupon.
+func (mr *MockInterfaceMockRecorder) ClaimCoupon(ctx, uid, templateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimCoupon", reflect.TypeOf((*MockInterface)(nil).ClaimCoupon), ctx, uid, templateID)
+}
+
+// ClaimableCoupons mocks base method.
+func (m *MockInterface) ClaimableCoupons(ctx context.Context) ([]*model.CouponTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimableCoupons", ctx)
+	ret0, _ := ret[0].([]*model.CouponTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimableCoupons indicates an expected call of ClaimableCoupons.
+func (mr *MockInterfaceMockRecorder) ClaimableCoupons(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimableCoupons", reflect.TypeOf((*MockInterface)(nil).ClaimableCoupons), ctx)
+}
+
+// ClearCart mocks base method.
+func (m *MockInterface) ClearCart(ctx context.Context, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCart", ctx, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCart indicates an expected call of ClearCart.
+func (mr *MockInterfaceMockRecorder) ClearCart(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCart", reflect.TypeOf((*MockInterface)(nil).ClearCart), ctx, owner)
+}
+
+// CompleteOrder mocks base method.
+func (m *MockInterface) CompleteOrder(ctx context.Context, order *model.Order) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteOrder", ctx, order)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteOrder indicates an expected call of CompleteOrder.
+func (mr *MockInterfaceMockRecorder) CompleteOrder(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteOrder", reflect.TypeOf((*MockInterface)(nil).CompleteOrder), ctx, order)
+}
+
+// ConfirmBooking mocks base method.
+func (m *MockInterface) ConfirmBooking(ctx context.Context, uid, id int) (*model.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmBooking", ctx, uid, id)
+	ret0, _ := ret[0].(*model.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmBooking indicates an expected call of ConfirmBooking.
+func (mr *MockInterfaceMockRecorder) ConfirmBooking(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmBooking", reflect.TypeOf((*MockInterface)(nil).ConfirmBooking), ctx, uid, id)
+}
+
+// CreateAddress mocks base method.
+func (m *MockInterface) CreateAddress(ctx context.Context, addr *model.UserAddress) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAddress", ctx, addr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAddress indicates an expected call of CreateAddress.
+func (mr *MockInterfaceMockRecorder) CreateAddress(ctx, addr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAddress", reflect.TypeOf((*MockInterface)(nil).CreateAddress), ctx, addr)
+}
+
+// CreateGroupChat mocks base method.
+func (m *MockInterface) CreateGroupChat(ctx context.Context, uid int, name string, members []int) (*model.ChatConversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroupChat", ctx, uid, name, members)
+	ret0, _ := ret[0].(*model.ChatConversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroupChat indicates an expected call of CreateGroupChat.
+func (mr *MockInterfaceMockRecorder) CreateGroupChat(ctx, uid, name, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroupChat", reflect.TypeOf((*MockInterface)(nil).CreateGroupChat), ctx, uid, name, members)
+}
+
+// Credit mocks base method.
+func (m *MockInterface) Credit(ctx context.Context, uid int, asset string, amount int64, refNo, bizType, remark string) (*model.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Credit", ctx, uid, asset, amount, refNo, bizType, remark)
+	ret0, _ := ret[0].(*model.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Credit indicates an expected call of Credit.
+func (mr *MockInterfaceMockRecorder) Credit(ctx, uid, asset, amount, refNo, bizType, remark interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Credit", reflect.TypeOf((*MockInterface)(nil).Credit), ctx, uid, asset, amount, refNo, bizType, remark)
+}
+
+// Debit mocks base method.
+func (m *MockInterface) Debit(ctx context.Context, uid int, asset string, amount int64, refNo, bizType, remark string) (*model.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Debit", ctx, uid, asset, amount, refNo, bizType, remark)
+	ret0, _ := ret[0].(*model.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Debit indicates an expected call of Debit.
+func (mr *MockInterfaceMockRecorder) Debit(ctx, uid, asset, amount, refNo, bizType, remark interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debit", reflect.TypeOf((*MockInterface)(nil).Debit), ctx, uid, asset, amount, refNo, bizType, remark)
+}
+
+// DeductStock mocks base method.
+func (m *MockInterface) DeductStock(ctx context.Context, orderNo string, items map[int]int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeductStock", ctx, orderNo, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeductStock indicates an expected call of DeductStock.
+func (mr *MockInterfaceMockRecorder) DeductStock(ctx, orderNo, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductStock", reflect.TypeOf((*MockInterface)(nil).DeductStock), ctx, orderNo, items)
+}
+
+// DeleteAddress mocks base method.
+func (m *MockInterface) DeleteAddress(ctx context.Context, uid, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAddress", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAddress indicates an expected call of DeleteAddress.
+func (mr *MockInterfaceMockRecorder) DeleteAddress(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAddress", reflect.TypeOf((*MockInterface)(nil).DeleteAddress), ctx, uid, id)
+}
+
+// DetectUserMerge mocks base method.
+func (m *MockInterface) DetectUserMerge(ctx context.Context, uid int, unionid string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DetectUserMerge", ctx, uid, unionid)
+}
+
+// DetectUserMerge indicates an expected call of DetectUserMerge.
+func (mr *MockInterfaceMockRecorder) DetectUserMerge(ctx, uid, unionid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetectUserMerge", reflect.TypeOf((*MockInterface)(nil).DetectUserMerge), ctx, uid, unionid)
+}
+
+// DrawLottery mocks base method.
+func (m *MockInterface) DrawLottery(ctx context.Context, uid, id int) (*model.LotteryDraw, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DrawLottery", ctx, uid, id)
+	ret0, _ := ret[0].(*model.LotteryDraw)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DrawLottery indicates an expected call of DrawLottery.
+func (mr *MockInterfaceMockRecorder) DrawLottery(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DrawLottery", reflect.TypeOf((*MockInterface)(nil).DrawLottery), ctx, uid, id)
+}
+
+// ExchangePoints mocks base method.
+func (m *MockInterface) ExchangePoints(ctx context.Context, uid, skuID, quantity int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExchangePoints", ctx, uid, skuID, quantity)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExchangePoints indicates an expected call of ExchangePoints.
+func (mr *MockInterfaceMockRecorder) ExchangePoints(ctx, uid, skuID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExchangePoints", reflect.TypeOf((*MockInterface)(nil).ExchangePoints), ctx, uid, skuID, quantity)
+}
+
+// FindAPIUsage mocks base method.
+func (m *MockInterface) FindAPIUsage(ctx context.Context, subject string, begin, end int) ([]*model.APIUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAPIUsage", ctx, subject, begin, end)
+	ret0, _ := ret[0].([]*model.APIUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAPIUsage indicates an expected call of FindAPIUsage.
+func (mr *MockInterfaceMockRecorder) FindAPIUsage(ctx, subject, begin, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAPIUsage", reflect.TypeOf((*MockInterface)(nil).FindAPIUsage), ctx, subject, begin, end)
+}
+
+// FindAddress mocks base method.
+func (m *MockInterface) FindAddress(ctx context.Context, uid, id int) (*model.UserAddress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAddress", ctx, uid, id)
+	ret0, _ := ret[0].(*model.UserAddress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAddress indicates an expected call of FindAddress.
+func (mr *MockInterfaceMockRecorder) FindAddress(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAddress", reflect.TypeOf((*MockInterface)(nil).FindAddress), ctx, uid, id)
+}
+
+// FindAddresses mocks base method.
+func (m *MockInterface) FindAddresses(ctx context.Context, uid int) ([]*model.UserAddress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAddresses", ctx, uid)
+	ret0, _ := ret[0].([]*model.UserAddress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAddresses indicates an expected call of FindAddresses.
+func (mr *MockInterfaceMockRecorder) FindAddresses(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAddresses", reflect.TypeOf((*MockInterface)(nil).FindAddresses), ctx, uid)
+}
+
+// FindAuthEvents mocks base method.
+func (m *MockInterface) FindAuthEvents(ctx context.Context, uid, limit int) ([]*model.AuthEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAuthEvents", ctx, uid, limit)
+	ret0, _ := ret[0].([]*model.AuthEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAuthEvents indicates an expected call of FindAuthEvents.
+func (mr *MockInterfaceMockRecorder) FindAuthEvents(ctx, uid, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAuthEvents", reflect.TypeOf((*MockInterface)(nil).FindAuthEvents), ctx, uid, limit)
+}
+
+// FindBookingResources mocks base method.
+func (m *MockInterface) FindBookingResources(ctx context.Context) ([]*model.BookingResource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBookingResources", ctx)
+	ret0, _ := ret[0].([]*model.BookingResource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBookingResources indicates an expected call of FindBookingResources.
+func (mr *MockInterfaceMockRecorder) FindBookingResources(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBookingResources", reflect.TypeOf((*MockInterface)(nil).FindBookingResources), ctx)
+}
+
+// FindBookingSlots mocks base method.
+func (m *MockInterface) FindBookingSlots(ctx context.Context, resourceID int, date time.Time) ([]*proto.BookingSlot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBookingSlots", ctx, resourceID, date)
+	ret0, _ := ret[0].([]*proto.BookingSlot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBookingSlots indicates an expected call of FindBookingSlots.
+func (mr *MockInterfaceMockRecorder) FindBookingSlots(ctx, resourceID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBookingSlots", reflect.TypeOf((*MockInterface)(nil).FindBookingSlots), ctx, resourceID, date)
+}
+
+// FindBookings mocks base method.
+func (m *MockInterface) FindBookings(ctx context.Context, uid, page, size int) ([]*proto.BookingItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBookings", ctx, uid, page, size)
+	ret0, _ := ret[0].([]*proto.BookingItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBookings indicates an expected call of FindBookings.
+func (mr *MockInterfaceMockRecorder) FindBookings(ctx, uid, page, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBookings", reflect.TypeOf((*MockInterface)(nil).FindBookings), ctx, uid, page, size)
+}
+
+// FindChatConversations mocks base method.
+func (m *MockInterface) FindChatConversations(ctx context.Context, uid, page, size int) ([]*proto.ChatConversationItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChatConversations", ctx, uid, page, size)
+	ret0, _ := ret[0].([]*proto.ChatConversationItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChatConversations indicates an expected call of FindChatConversations.
+func (mr *MockInterfaceMockRecorder) FindChatConversations(ctx, uid, page, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChatConversations", reflect.TypeOf((*MockInterface)(nil).FindChatConversations), ctx, uid, page, size)
+}
+
+// FindChatMembers mocks base method.
+func (m *MockInterface) FindChatMembers(ctx context.Context, uid, cid int) ([]*model.ChatMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChatMembers", ctx, uid, cid)
+	ret0, _ := ret[0].([]*model.ChatMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChatMembers indicates an expected call of FindChatMembers.
+func (mr *MockInterfaceMockRecorder) FindChatMembers(ctx, uid, cid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChatMembers", reflect.TypeOf((*MockInterface)(nil).FindChatMembers), ctx, uid, cid)
+}
+
+// FindChatMessages mocks base method.
+func (m *MockInterface) FindChatMessages(ctx context.Context, uid, cid int, before, after int64, size int) ([]*model.ChatMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChatMessages", ctx, uid, cid, before, after, size)
+	ret0, _ := ret[0].([]*model.ChatMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChatMessages indicates an expected call of FindChatMessages.
+func (mr *MockInterfaceMockRecorder) FindChatMessages(ctx, uid, cid, before, after, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChatMessages", reflect.TypeOf((*MockInterface)(nil).FindChatMessages), ctx, uid, cid, before, after, size)
+}
+
+// FindCheckinCalendar mocks base method.
+func (m *MockInterface) FindCheckinCalendar(ctx context.Context, uid int, month string) ([]*model.CheckinLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCheckinCalendar", ctx, uid, month)
+	ret0, _ := ret[0].([]*model.CheckinLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCheckinCalendar indicates an expected call of FindCheckinCalendar.
+func (mr *MockInterfaceMockRecorder) FindCheckinCalendar(ctx, uid, month interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCheckinCalendar", reflect.TypeOf((*MockInterface)(nil).FindCheckinCalendar), ctx, uid, month)
+}
+
+// FindCheckinStatus mocks base method.
+func (m *MockInterface) FindCheckinStatus(ctx context.Context, uid int, tz string) (*proto.CheckinStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCheckinStatus", ctx, uid, tz)
+	ret0, _ := ret[0].(*proto.CheckinStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCheckinStatus indicates an expected call of FindCheckinStatus.
+func (mr *MockInterfaceMockRecorder) FindCheckinStatus(ctx, uid, tz interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCheckinStatus", reflect.TypeOf((*MockInterface)(nil).FindCheckinStatus), ctx, uid, tz)
+}
+
+// FindLedgerAccounts mocks base method.
+func (m *MockInterface) FindLedgerAccounts(ctx context.Context, uid int) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLedgerAccounts", ctx, uid)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLedgerAccounts indicates an expected call of FindLedgerAccounts.
+func (mr *MockInterfaceMockRecorder) FindLedgerAccounts(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLedgerAccounts", reflect.TypeOf((*MockInterface)(nil).FindLedgerAccounts), ctx, uid)
+}
+
+// FindLottery mocks base method.
+func (m *MockInterface) FindLottery(ctx context.Context, id int) (*model.Lottery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLottery", ctx, id)
+	ret0, _ := ret[0].(*model.Lottery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLottery indicates an expected call of FindLottery.
+func (mr *MockInterfaceMockRecorder) FindLottery(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLottery", reflect.TypeOf((*MockInterface)(nil).FindLottery), ctx, id)
+}
+
+// FindLotteryChances mocks base method.
+func (m *MockInterface) FindLotteryChances(ctx context.Context, uid, id int) (*proto.LotteryChances, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLotteryChances", ctx, uid, id)
+	ret0, _ := ret[0].(*proto.LotteryChances)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLotteryChances indicates an expected call of FindLotteryChances.
+func (mr *MockInterfaceMockRecorder) FindLotteryChances(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLotteryChances", reflect.TypeOf((*MockInterface)(nil).FindLotteryChances), ctx, uid, id)
+}
+
+// FindLotteryWins mocks base method.
+func (m *MockInterface) FindLotteryWins(ctx context.Context, uid, lotteryID, page, size int) ([]*model.LotteryDraw, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLotteryWins", ctx, uid, lotteryID, page, size)
+	ret0, _ := ret[0].([]*model.LotteryDraw)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLotteryWins indicates an expected call of FindLotteryWins.
+func (mr *MockInterfaceMockRecorder) FindLotteryWins(ctx, uid, lotteryID, page, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLotteryWins", reflect.TypeOf((*MockInterface)(nil).FindLotteryWins), ctx, uid, lotteryID, page, size)
+}
+
+// FindOrderByNo mocks base method.
+func (m *MockInterface) FindOrderByNo(ctx context.Context, orderNo string) (*model.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrderByNo", ctx, orderNo)
+	ret0, _ := ret[0].(*model.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrderByNo indicates an expected call of FindOrderByNo.
+func (mr *MockInterfaceMockRecorder) FindOrderByNo(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrderByNo", reflect.TypeOf((*MockInterface)(nil).FindOrderByNo), ctx, orderNo)
+}
+
+// FindPushDeliveries mocks base method.
+func (m *MockInterface) FindPushDeliveries(ctx context.Context, msgID string) ([]*model.PushDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPushDeliveries", ctx, msgID)
+	ret0, _ := ret[0].([]*model.PushDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPushDeliveries indicates an expected call of FindPushDeliveries.
+func (mr *MockInterfaceMockRecorder) FindPushDeliveries(ctx, msgID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPushDeliveries", reflect.TypeOf((*MockInterface)(nil).FindPushDeliveries), ctx, msgID)
+}
+
+// FindPushDevices mocks base method.
+func (m *MockInterface) FindPushDevices(ctx context.Context, uid int) ([]*model.PushDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPushDevices", ctx, uid)
+	ret0, _ := ret[0].([]*model.PushDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPushDevices indicates an expected call of FindPushDevices.
+func (mr *MockInterfaceMockRecorder) FindPushDevices(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPushDevices", reflect.TypeOf((*MockInterface)(nil).FindPushDevices), ctx, uid)
+}
+
+// FindStore mocks base method.
+func (m *MockInterface) FindStore(ctx context.Context, id int) (*proto.StoreItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindStore", ctx, id)
+	ret0, _ := ret[0].(*proto.StoreItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindStore indicates an expected call of FindStore.
+func (mr *MockInterfaceMockRecorder) FindStore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindStore", reflect.TypeOf((*MockInterface)(nil).FindStore), ctx, id)
+}
+
+// FindUserByID mocks base method.
+func (m *MockInterface) FindUserByID(ctx context.Context, id int) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByID", ctx, id)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByID indicates an expected call of FindUserByID.
+func (mr *MockInterfaceMockRecorder) FindUserByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByID", reflect.TypeOf((*MockInterface)(nil).FindUserByID), ctx, id)
+}
+
+// FindUserCoupons mocks base method.
+func (m *MockInterface) FindUserCoupons(ctx context.Context, uid int, status int8) ([]*model.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserCoupons", ctx, uid, status)
+	ret0, _ := ret[0].([]*model.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserCoupons indicates an expected call of FindUserCoupons.
+func (mr *MockInterfaceMockRecorder) FindUserCoupons(ctx, uid, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserCoupons", reflect.TypeOf((*MockInterface)(nil).FindUserCoupons), ctx, uid, status)
+}
+
+// FindUserDevices mocks base method.
+func (m *MockInterface) FindUserDevices(ctx context.Context, uid int) ([]*model.UserDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserDevices", ctx, uid)
+	ret0, _ := ret[0].([]*model.UserDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserDevices indicates an expected call of FindUserDevices.
+func (mr *MockInterfaceMockRecorder) FindUserDevices(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserDevices", reflect.TypeOf((*MockInterface)(nil).FindUserDevices), ctx, uid)
+}
+
+// FinishRefund mocks base method.
+func (m *MockInterface) FinishRefund(ctx context.Context, refundNo, refundID string, success bool, successTime int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishRefund", ctx, refundNo, refundID, success, successTime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishRefund indicates an expected call of FinishRefund.
+func (mr *MockInterfaceMockRecorder) FinishRefund(ctx, refundNo, refundID, success, successTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishRefund", reflect.TypeOf((*MockInterface)(nil).FinishRefund), ctx, refundNo, refundID, success, successTime)
+}
+
+// FlagCaptcha mocks base method.
+func (m *MockInterface) FlagCaptcha(ctx context.Context, subject string, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlagCaptcha", ctx, subject, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FlagCaptcha indicates an expected call of FlagCaptcha.
+func (mr *MockInterfaceMockRecorder) FlagCaptcha(ctx, subject, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagCaptcha", reflect.TypeOf((*MockInterface)(nil).FlagCaptcha), ctx, subject, ttl)
+}
+
+// GetAppVersions mocks base method.
+func (m *MockInterface) GetAppVersions(ctx context.Context) (map[string]*model.AppVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppVersions", ctx)
+	ret0, _ := ret[0].(map[string]*model.AppVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppVersions indicates an expected call of GetAppVersions.
+func (mr *MockInterfaceMockRecorder) GetAppVersions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppVersions", reflect.TypeOf((*MockInterface)(nil).GetAppVersions), ctx)
+}
+
+// GetBannersByCity mocks base method.
+func (m *MockInterface) GetBannersByCity(ctx context.Context, city string) ([]*model.Banner, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBannersByCity", ctx, city)
+	ret0, _ := ret[0].([]*model.Banner)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBannersByCity indicates an expected call of GetBannersByCity.
+func (mr *MockInterfaceMockRecorder) GetBannersByCity(ctx, city interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBannersByCity", reflect.TypeOf((*MockInterface)(nil).GetBannersByCity), ctx, city)
+}
+
+// GetCart mocks base method.
+func (m *MockInterface) GetCart(ctx context.Context, owner string) ([]*proto.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCart", ctx, owner)
+	ret0, _ := ret[0].([]*proto.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCart indicates an expected call of GetCart.
+func (mr *MockInterfaceMockRecorder) GetCart(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCart", reflect.TypeOf((*MockInterface)(nil).GetCart), ctx, owner)
+}
+
+// GetChaosRules mocks base method.
+func (m *MockInterface) GetChaosRules(ctx context.Context) (map[string]*model.ChaosRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChaosRules", ctx)
+	ret0, _ := ret[0].(map[string]*model.ChaosRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChaosRules indicates an expected call of GetChaosRules.
+func (mr *MockInterfaceMockRecorder) GetChaosRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChaosRules", reflect.TypeOf((*MockInterface)(nil).GetChaosRules), ctx)
+}
+
+// GetContent mocks base method.
+func (m *MockInterface) GetContent(ctx context.Context, key string) (*model.Content, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContent", ctx, key)
+	ret0, _ := ret[0].(*model.Content)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContent indicates an expected call of GetContent.
+func (mr *MockInterfaceMockRecorder) GetContent(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContent", reflect.TypeOf((*MockInterface)(nil).GetContent), ctx, key)
+}
+
+// GetGuestToken mocks base method.
+func (m *MockInterface) GetGuestToken(ctx context.Context, token string) (*proto.GuestToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGuestToken", ctx, token)
+	ret0, _ := ret[0].(*proto.GuestToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGuestToken indicates an expected call of GetGuestToken.
+func (mr *MockInterfaceMockRecorder) GetGuestToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGuestToken", reflect.TypeOf((*MockInterface)(nil).GetGuestToken), ctx, token)
+}
+
+// GetPresence mocks base method.
+func (m *MockInterface) GetPresence(ctx context.Context, uids []int) (map[int]*model.UserPresence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPresence", ctx, uids)
+	ret0, _ := ret[0].(map[int]*model.UserPresence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPresence indicates an expected call of GetPresence.
+func (mr *MockInterfaceMockRecorder) GetPresence(ctx, uids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPresence", reflect.TypeOf((*MockInterface)(nil).GetPresence), ctx, uids)
+}
+
+// GetPushPreference mocks base method.
+func (m *MockInterface) GetPushPreference(ctx context.Context, uid int) (*model.PushPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPushPreference", ctx, uid)
+	ret0, _ := ret[0].(*model.PushPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPushPreference indicates an expected call of GetPushPreference.
+func (mr *MockInterfaceMockRecorder) GetPushPreference(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPushPreference", reflect.TypeOf((*MockInterface)(nil).GetPushPreference), ctx, uid)
+}
+
+// GetRecords mocks base method.
+func (m *MockInterface) GetRecords(ctx context.Context, traceID string) ([]*logger.Exchange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecords", ctx, traceID)
+	ret0, _ := ret[0].([]*logger.Exchange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecords indicates an expected call of GetRecords.
+func (mr *MockInterfaceMockRecorder) GetRecords(ctx, traceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecords", reflect.TypeOf((*MockInterface)(nil).GetRecords), ctx, traceID)
+}
+
+// GetStock mocks base method.
+func (m *MockInterface) GetStock(ctx context.Context, skus []int) (map[int]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStock", ctx, skus)
+	ret0, _ := ret[0].(map[int]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStock indicates an expected call of GetStock.
+func (mr *MockInterfaceMockRecorder) GetStock(ctx, skus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStock", reflect.TypeOf((*MockInterface)(nil).GetStock), ctx, skus)
+}
+
+// GetUserStatus mocks base method.
+func (m *MockInterface) GetUserStatus(ctx context.Context, uid int) (*model.UserStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserStatus", ctx, uid)
+	ret0, _ := ret[0].(*model.UserStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserStatus indicates an expected call of GetUserStatus.
+func (mr *MockInterfaceMockRecorder) GetUserStatus(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserStatus", reflect.TypeOf((*MockInterface)(nil).GetUserStatus), ctx, uid)
+}
+
+// GetUserToken mocks base method.
+func (m *MockInterface) GetUserToken(ctx context.Context, token string) (*proto.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserToken", ctx, token)
+	ret0, _ := ret[0].(*proto.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserToken indicates an expected call of GetUserToken.
+func (mr *MockInterfaceMockRecorder) GetUserToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserToken", reflect.TypeOf((*MockInterface)(nil).GetUserToken), ctx, token)
+}
+
+// IncrHTTPStat mocks base method.
+func (m *MockInterface) IncrHTTPStat(ctx context.Context, date string, status int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrHTTPStat", ctx, date, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrHTTPStat indicates an expected call of IncrHTTPStat.
+func (mr *MockInterfaceMockRecorder) IncrHTTPStat(ctx, date, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrHTTPStat", reflect.TypeOf((*MockInterface)(nil).IncrHTTPStat), ctx, date, status)
+}
+
+// IncrUsage mocks base method.
+func (m *MockInterface) IncrUsage(ctx context.Context, date string, fields ...string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, date}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "IncrUsage", varargs...)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrUsage indicates an expected call of IncrUsage.
+func (mr *MockInterfaceMockRecorder) IncrUsage(ctx, date interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, date}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrUsage", reflect.TypeOf((*MockInterface)(nil).IncrUsage), varargs...)
+}
+
+// IsOnline mocks base method.
+func (m *MockInterface) IsOnline(ctx context.Context, uid int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsOnline", ctx, uid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsOnline indicates an expected call of IsOnline.
+func (mr *MockInterfaceMockRecorder) IsOnline(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsOnline", reflect.TypeOf((*MockInterface)(nil).IsOnline), ctx, uid)
+}
+
+// JSAPITicket mocks base method.
+func (m *MockInterface) JSAPITicket(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JSAPITicket", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JSAPITicket indicates an expected call of JSAPITicket.
+func (mr *MockInterfaceMockRecorder) JSAPITicket(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JSAPITicket", reflect.TypeOf((*MockInterface)(nil).JSAPITicket), ctx)
+}
+
+// MergeGuest mocks base method.
+func (m *MockInterface) MergeGuest(ctx context.Context, token string, uid int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeGuest", ctx, token, uid)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeGuest indicates an expected call of MergeGuest.
+func (mr *MockInterfaceMockRecorder) MergeGuest(ctx, token, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeGuest", reflect.TypeOf((*MockInterface)(nil).MergeGuest), ctx, token, uid)
+}
+
+// NearbyStores mocks base method.
+func (m *MockInterface) NearbyStores(ctx context.Context, lng, lat float64, radius, limit int) ([]*proto.StoreItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NearbyStores", ctx, lng, lat, radius, limit)
+	ret0, _ := ret[0].([]*proto.StoreItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NearbyStores indicates an expected call of NearbyStores.
+func (mr *MockInterfaceMockRecorder) NearbyStores(ctx, lng, lat, radius, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NearbyStores", reflect.TypeOf((*MockInterface)(nil).NearbyStores), ctx, lng, lat, radius, limit)
+}
+
+// NewCaptcha mocks base method.
+func (m *MockInterface) NewCaptcha(ctx context.Context, kind string) (*captcha.Challenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewCaptcha", ctx, kind)
+	ret0, _ := ret[0].(*captcha.Challenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewCaptcha indicates an expected call of NewCaptcha.
+func (mr *MockInterfaceMockRecorder) NewCaptcha(ctx, kind interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewCaptcha", reflect.TypeOf((*MockInterface)(nil).NewCaptcha), ctx, kind)
+}
+
+// NewOAuthState mocks base method.
+func (m *MockInterface) NewOAuthState(ctx context.Context, app string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewOAuthState", ctx, app)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewOAuthState indicates an expected call of NewOAuthState.
+func (mr *MockInterfaceMockRecorder) NewOAuthState(ctx, app interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewOAuthState", reflect.TypeOf((*MockInterface)(nil).NewOAuthState), ctx, app)
+}
+
+// OpenSingleChat mocks base method.
+func (m *MockInterface) OpenSingleChat(ctx context.Context, uid, peer int) (*model.ChatConversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenSingleChat", ctx, uid, peer)
+	ret0, _ := ret[0].(*model.ChatConversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenSingleChat indicates an expected call of OpenSingleChat.
+func (mr *MockInterfaceMockRecorder) OpenSingleChat(ctx, uid, peer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenSingleChat", reflect.TypeOf((*MockInterface)(nil).OpenSingleChat), ctx, uid, peer)
+}
+
+// PaginateLedgerEntry mocks base method.
+func (m *MockInterface) PaginateLedgerEntry(ctx context.Context, uid int, asset string, page, size int) (int64, []*model.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PaginateLedgerEntry", ctx, uid, asset, page, size)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].([]*model.LedgerEntry)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PaginateLedgerEntry indicates an expected call of PaginateLedgerEntry.
+func (mr *MockInterfaceMockRecorder) PaginateLedgerEntry(ctx, uid, asset, page, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaginateLedgerEntry", reflect.TypeOf((*MockInterface)(nil).PaginateLedgerEntry), ctx, uid, asset, page, size)
+}
+
+// PayOrder mocks base method.
+func (m *MockInterface) PayOrder(ctx context.Context, orderNo, transactionID string, amount, payTime int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PayOrder", ctx, orderNo, transactionID, amount, payTime)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PayOrder indicates an expected call of PayOrder.
+func (mr *MockInterfaceMockRecorder) PayOrder(ctx, orderNo, transactionID, amount, payTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayOrder", reflect.TypeOf((*MockInterface)(nil).PayOrder), ctx, orderNo, transactionID, amount, payTime)
+}
+
+// PresenceHeartbeat mocks base method.
+func (m *MockInterface) PresenceHeartbeat(ctx context.Context, uid int, connID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresenceHeartbeat", ctx, uid, connID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresenceHeartbeat indicates an expected call of PresenceHeartbeat.
+func (mr *MockInterfaceMockRecorder) PresenceHeartbeat(ctx, uid, connID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresenceHeartbeat", reflect.TypeOf((*MockInterface)(nil).PresenceHeartbeat), ctx, uid, connID)
+}
+
+// PresenceLeave mocks base method.
+func (m *MockInterface) PresenceLeave(ctx context.Context, uid int, connID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresenceLeave", ctx, uid, connID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PresenceLeave indicates an expected call of PresenceLeave.
+func (mr *MockInterfaceMockRecorder) PresenceLeave(ctx, uid, connID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresenceLeave", reflect.TypeOf((*MockInterface)(nil).PresenceLeave), ctx, uid, connID)
+}
+
+// PriceCoupon mocks base method.
+func (m *MockInterface) PriceCoupon(ctx context.Context, uid, couponID int, amount int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PriceCoupon", ctx, uid, couponID, amount)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PriceCoupon indicates an expected call of PriceCoupon.
+func (mr *MockInterfaceMockRecorder) PriceCoupon(ctx, uid, couponID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PriceCoupon", reflect.TypeOf((*MockInterface)(nil).PriceCoupon), ctx, uid, couponID, amount)
+}
+
+// PublishAuthEvent mocks base method.
+func (m *MockInterface) PublishAuthEvent(ctx context.Context, e *model.AuthEvent, token string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PublishAuthEvent", ctx, e, token)
+}
+
+// PublishAuthEvent indicates an expected call of PublishAuthEvent.
+func (mr *MockInterfaceMockRecorder) PublishAuthEvent(ctx, e, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishAuthEvent", reflect.TypeOf((*MockInterface)(nil).PublishAuthEvent), ctx, e, token)
+}
+
+// Push mocks base method.
+func (m *MockInterface) Push(ctx context.Context, msg *model.MsgPush) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Push", ctx, msg)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Push indicates an expected call of Push.
+func (mr *MockInterfaceMockRecorder) Push(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockInterface)(nil).Push), ctx, msg)
+}
+
+// PushExposure mocks base method.
+func (m *MockInterface) PushExposure(ctx context.Context, data []*model.MsgExposure) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushExposure", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PushExposure indicates an expected call of PushExposure.
+func (mr *MockInterfaceMockRecorder) PushExposure(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushExposure", reflect.TypeOf((*MockInterface)(nil).PushExposure), ctx, data)
+}
+
+// PushShortLinkClick mocks base method.
+func (m *MockInterface) PushShortLinkClick(ctx context.Context, click *shortlink.Click) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushShortLinkClick", ctx, click)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PushShortLinkClick indicates an expected call of PushShortLinkClick.
+func (mr *MockInterfaceMockRecorder) PushShortLinkClick(ctx, click interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushShortLinkClick", reflect.TypeOf((*MockInterface)(nil).PushShortLinkClick), ctx, click)
+}
+
+// ReadChat mocks base method.
+func (m *MockInterface) ReadChat(ctx context.Context, uid, cid int, seq int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadChat", ctx, uid, cid, seq)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadChat indicates an expected call of ReadChat.
+func (mr *MockInterfaceMockRecorder) ReadChat(ctx, uid, cid, seq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadChat", reflect.TypeOf((*MockInterface)(nil).ReadChat), ctx, uid, cid, seq)
+}
+
+// RecordLoginDevice mocks base method.
+func (m *MockInterface) RecordLoginDevice(ctx context.Context, d *model.UserDevice) (bool, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginDevice", ctx, d)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RecordLoginDevice indicates an expected call of RecordLoginDevice.
+func (mr *MockInterfaceMockRecorder) RecordLoginDevice(ctx, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginDevice", reflect.TypeOf((*MockInterface)(nil).RecordLoginDevice), ctx, d)
+}
+
+// RedeemCaptcha mocks base method.
+func (m *MockInterface) RedeemCaptcha(ctx context.Context, ticket string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemCaptcha", ctx, ticket)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemCaptcha indicates an expected call of RedeemCaptcha.
+func (mr *MockInterfaceMockRecorder) RedeemCaptcha(ctx, ticket interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemCaptcha", reflect.TypeOf((*MockInterface)(nil).RedeemCaptcha), ctx, ticket)
+}
+
+// RedeemOAuthState mocks base method.
+func (m *MockInterface) RedeemOAuthState(ctx context.Context, app, state string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemOAuthState", ctx, app, state)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemOAuthState indicates an expected call of RedeemOAuthState.
+func (mr *MockInterfaceMockRecorder) RedeemOAuthState(ctx, app, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemOAuthState", reflect.TypeOf((*MockInterface)(nil).RedeemOAuthState), ctx, app, state)
+}
+
+// RefreshUserToken mocks base method.
+func (m *MockInterface) RefreshUserToken(ctx context.Context, token string, data *proto.UserToken) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshUserToken", ctx, token, data)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshUserToken indicates an expected call of RefreshUserToken.
+func (mr *MockInterfaceMockRecorder) RefreshUserToken(ctx, token, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshUserToken", reflect.TypeOf((*MockInterface)(nil).RefreshUserToken), ctx, token, data)
+}
+
+// Regions mocks base method.
+func (m *MockInterface) Regions() *region.Dataset {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Regions")
+	ret0, _ := ret[0].(*region.Dataset)
+	return ret0
+}
+
+// Regions indicates an expected call of Regions.
+func (mr *MockInterfaceMockRecorder) Regions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Regions", reflect.TypeOf((*MockInterface)(nil).Regions))
+}
+
+// RegisterPushDevice mocks base method.
+func (m *MockInterface) RegisterPushDevice(ctx context.Context, d *model.PushDevice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterPushDevice", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterPushDevice indicates an expected call of RegisterPushDevice.
+func (mr *MockInterfaceMockRecorder) RegisterPushDevice(ctx, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterPushDevice", reflect.TypeOf((*MockInterface)(nil).RegisterPushDevice), ctx, d)
+}
+
+// ReserveBooking mocks base method.
+func (m *MockInterface) ReserveBooking(ctx context.Context, b *model.Booking, date time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveBooking", ctx, b, date)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveBooking indicates an expected call of ReserveBooking.
+func (mr *MockInterfaceMockRecorder) ReserveBooking(ctx, b, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveBooking", reflect.TypeOf((*MockInterface)(nil).ReserveBooking), ctx, b, date)
+}
+
+// ResolveShortLink mocks base method.
+func (m *MockInterface) ResolveShortLink(ctx context.Context, code string) (*shortlink.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveShortLink", ctx, code)
+	ret0, _ := ret[0].(*shortlink.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveShortLink indicates an expected call of ResolveShortLink.
+func (mr *MockInterfaceMockRecorder) ResolveShortLink(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveShortLink", reflect.TypeOf((*MockInterface)(nil).ResolveShortLink), ctx, code)
+}
+
+// RestockOrder mocks base method.
+func (m *MockInterface) RestockOrder(ctx context.Context, orderNo string, skus []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestockOrder", ctx, orderNo, skus)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestockOrder indicates an expected call of RestockOrder.
+func (mr *MockInterfaceMockRecorder) RestockOrder(ctx, orderNo, skus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestockOrder", reflect.TypeOf((*MockInterface)(nil).RestockOrder), ctx, orderNo, skus)
+}
+
+// RevokeUserToken mocks base method.
+func (m *MockInterface) RevokeUserToken(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserToken indicates an expected call of RevokeUserToken.
+func (mr *MockInterfaceMockRecorder) RevokeUserToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserToken", reflect.TypeOf((*MockInterface)(nil).RevokeUserToken), ctx, token)
+}
+
+// SaveRecord mocks base method.
+func (m *MockInterface) SaveRecord(ctx context.Context, ex *logger.Exchange, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRecord", ctx, ex, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRecord indicates an expected call of SaveRecord.
+func (mr *MockInterfaceMockRecorder) SaveRecord(ctx, ex, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRecord", reflect.TypeOf((*MockInterface)(nil).SaveRecord), ctx, ex, ttl)
+}
+
+// SaveUser mocks base method.
+func (m *MockInterface) SaveUser(ctx context.Context, data *model.User) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUser", ctx, data)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveUser indicates an expected call of SaveUser.
+func (mr *MockInterfaceMockRecorder) SaveUser(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockInterface)(nil).SaveUser), ctx, data)
+}
+
+// SaveWechatUser mocks base method.
+func (m *MockInterface) SaveWechatUser(ctx context.Context, data *model.UserWechat) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWechatUser", ctx, data)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveWechatUser indicates an expected call of SaveWechatUser.
+func (mr *MockInterfaceMockRecorder) SaveWechatUser(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWechatUser", reflect.TypeOf((*MockInterface)(nil).SaveWechatUser), ctx, data)
+}
+
+// ScheduleOrderTimeout mocks base method.
+func (m *MockInterface) ScheduleOrderTimeout(ctx context.Context, orderNo string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleOrderTimeout", ctx, orderNo)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleOrderTimeout indicates an expected call of ScheduleOrderTimeout.
+func (mr *MockInterfaceMockRecorder) ScheduleOrderTimeout(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleOrderTimeout", reflect.TypeOf((*MockInterface)(nil).ScheduleOrderTimeout), ctx, orderNo)
+}
+
+// SendChatMessage mocks base method.
+func (m *MockInterface) SendChatMessage(ctx context.Context, msg *model.ChatMessage) (*model.ChatMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendChatMessage", ctx, msg)
+	ret0, _ := ret[0].(*model.ChatMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendChatMessage indicates an expected call of SendChatMessage.
+func (mr *MockInterfaceMockRecorder) SendChatMessage(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendChatMessage", reflect.TypeOf((*MockInterface)(nil).SendChatMessage), ctx, msg)
+}
+
+// SetCartItem mocks base method.
+func (m *MockInterface) SetCartItem(ctx context.Context, owner string, skuID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCartItem", ctx, owner, skuID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCartItem indicates an expected call of SetCartItem.
+func (mr *MockInterfaceMockRecorder) SetCartItem(ctx, owner, skuID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCartItem", reflect.TypeOf((*MockInterface)(nil).SetCartItem), ctx, owner, skuID, quantity)
+}
+
+// SetDefaultAddress mocks base method.
+func (m *MockInterface) SetDefaultAddress(ctx context.Context, uid, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDefaultAddress", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDefaultAddress indicates an expected call of SetDefaultAddress.
+func (mr *MockInterfaceMockRecorder) SetDefaultAddress(ctx, uid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultAddress", reflect.TypeOf((*MockInterface)(nil).SetDefaultAddress), ctx, uid, id)
+}
+
+// SetGuestToken mocks base method.
+func (m *MockInterface) SetGuestToken(ctx context.Context, deviceID, ip, old string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetGuestToken", ctx, deviceID, ip, old)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetGuestToken indicates an expected call of SetGuestToken.
+func (mr *MockInterfaceMockRecorder) SetGuestToken(ctx, deviceID, ip, old interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGuestToken", reflect.TypeOf((*MockInterface)(nil).SetGuestToken), ctx, deviceID, ip, old)
+}
+
+// SetOrderProvider mocks base method.
+func (m *MockInterface) SetOrderProvider(ctx context.Context, order *model.Order, provider string, couponID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOrderProvider", ctx, order, provider, couponID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOrderProvider indicates an expected call of SetOrderProvider.
+func (mr *MockInterfaceMockRecorder) SetOrderProvider(ctx, order, provider, couponID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrderProvider", reflect.TypeOf((*MockInterface)(nil).SetOrderProvider), ctx, order, provider, couponID)
+}
+
+// SetPushPreference mocks base method.
+func (m *MockInterface) SetPushPreference(ctx context.Context, p *model.PushPreference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPushPreference", ctx, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPushPreference indicates an expected call of SetPushPreference.
+func (mr *MockInterfaceMockRecorder) SetPushPreference(ctx, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPushPreference", reflect.TypeOf((*MockInterface)(nil).SetPushPreference), ctx, p)
+}
+
+// SetUserToken mocks base method.
+func (m *MockInterface) SetUserToken(ctx context.Context, data *proto.UserToken) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserToken", ctx, data)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserToken indicates an expected call of SetUserToken.
+func (mr *MockInterfaceMockRecorder) SetUserToken(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserToken", reflect.TypeOf((*MockInterface)(nil).SetUserToken), ctx, data)
+}
+
+// SyncBannersByCity mocks base method.
+func (m *MockInterface) SyncBannersByCity(ctx context.Context, city string, since time.Time, sinceID int) ([]*model.Banner, time.Time, int, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncBannersByCity", ctx, city, since, sinceID)
+	ret0, _ := ret[0].([]*model.Banner)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(int)
+	ret3, _ := ret[3].(bool)
+	ret4, _ := ret[4].(error)
+	return ret0, ret1, ret2, ret3, ret4
+}
+
+// SyncBannersByCity indicates an expected call of SyncBannersByCity.
+func (mr *MockInterfaceMockRecorder) SyncBannersByCity(ctx, city, since, sinceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncBannersByCity", reflect.TypeOf((*MockInterface)(nil).SyncBannersByCity), ctx, city, since, sinceID)
+}
+
+// TokenStore mocks base method.
+func (m *MockInterface) TokenStore() wechat.TokenStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TokenStore")
+	ret0, _ := ret[0].(wechat.TokenStore)
+	return ret0
+}
+
+// TokenStore indicates an expected call of TokenStore.
+func (mr *MockInterfaceMockRecorder) TokenStore() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TokenStore", reflect.TypeOf((*MockInterface)(nil).TokenStore))
+}
+
+// Track mocks base method.
+func (m *MockInterface) Track(ctx context.Context, uid int, p track.Props) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Track", ctx, uid, p)
+}
+
+// Track indicates an expected call of Track.
+func (mr *MockInterfaceMockRecorder) Track(ctx, uid, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Track", reflect.TypeOf((*MockInterface)(nil).Track), ctx, uid, p)
+}
+
+// UnregisterPushDevice mocks base method.
+func (m *MockInterface) UnregisterPushDevice(ctx context.Context, uid int, deviceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnregisterPushDevice", ctx, uid, deviceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnregisterPushDevice indicates an expected call of UnregisterPushDevice.
+func (mr *MockInterfaceMockRecorder) UnregisterPushDevice(ctx, uid, deviceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnregisterPushDevice", reflect.TypeOf((*MockInterface)(nil).UnregisterPushDevice), ctx, uid, deviceID)
+}
+
+// UpdateAddress mocks base method.
+func (m *MockInterface) UpdateAddress(ctx context.Context, addr *model.UserAddress) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAddress", ctx, addr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAddress indicates an expected call of UpdateAddress.
+func (mr *MockInterfaceMockRecorder) UpdateAddress(ctx, addr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAddress", reflect.TypeOf((*MockInterface)(nil).UpdateAddress), ctx, addr)
+}
+
+// UpdateUser mocks base method.
+func (m *MockInterface) UpdateUser(ctx context.Context, data *model.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockInterfaceMockRecorder) UpdateUser(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockInterface)(nil).UpdateUser), ctx, data)
+}
+
+// UserSegments mocks base method.
+func (m *MockInterface) UserSegments(ctx context.Context, uid int) (map[string]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserSegments", ctx, uid)
+	ret0, _ := ret[0].(map[string]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserSegments indicates an expected call of UserSegments.
+func (mr *MockInterfaceMockRecorder) UserSegments(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserSegments", reflect.TypeOf((*MockInterface)(nil).UserSegments), ctx, uid)
+}
+
+// VerifyCaptcha mocks base method.
+func (m *MockInterface) VerifyCaptcha(ctx context.Context, id, answer string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyCaptcha", ctx, id, answer)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyCaptcha indicates an expected call of VerifyCaptcha.
+func (mr *MockInterfaceMockRecorder) VerifyCaptcha(ctx, id, answer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyCaptcha", reflect.TypeOf((*MockInterface)(nil).VerifyCaptcha), ctx, id, answer)
+}
+
+// WechatQuota mocks base method.
+func (m *MockInterface) WechatQuota(appid string, limits map[string]int64) *wechat.Quota {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WechatQuota", appid, limits)
+	ret0, _ := ret[0].(*wechat.Quota)
+	return ret0
+}
+
+// WechatQuota indicates an expected call of WechatQuota.
+func (mr *MockInterfaceMockRecorder) WechatQuota(appid, limits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WechatQuota", reflect.TypeOf((*MockInterface)(nil).WechatQuota), appid, limits)
+}
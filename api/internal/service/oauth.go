@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"gorm.io/gorm"
+	"project/model"
+	"time"
+)
+
+// 开放平台登录：网站应用扫码、移动应用登录的openid记录在user_wechat，按unionid与小程序等渠道的用户关联
+
+const oauthStateTTL = 10 * time.Minute
+
+// NewOAuthState 网站扫码登录的state，防止登录请求被伪造
+func (s *Service) NewOAuthState(ctx context.Context, app string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	state := hex.EncodeToString(b)
+	err := s.redis.Set(ctx, model.OAuthStateKey(app, state), 1, oauthStateTTL).Err()
+	return state, err
+}
+
+// RedeemOAuthState state只能使用一次
+func (s *Service) RedeemOAuthState(ctx context.Context, app, state string) (bool, error) {
+	n, err := s.redis.Del(ctx, model.OAuthStateKey(app, state)).Result()
+	return n == 1, err
+}
+
+// SaveWechatUser 按appid+openid查找；首次登录时按unionid关联已有用户，没有则注册，user.openid暂为该渠道的openid
+func (s *Service) SaveWechatUser(ctx context.Context, data *model.UserWechat) (int, error) {
+	db := s.mysql.WithContext(ctx)
+	err := db.Take(data, "appid = ? AND openid = ?", data.Appid, data.Openid).Error
+	if err == nil {
+		return data.UserID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if data.Unionid != "" {
+			err := tx.Take(&user, "unionid = ?", data.Unionid).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+		if user.ID == 0 {
+			user = model.User{Openid: data.Openid, Unionid: data.Unionid}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		}
+		data.UserID = user.ID
+		return tx.Create(data).Error
+	})
+	return data.UserID, err
+}
+
+// linkUnionid 小程序首次登录时，其他渠道已按unionid注册的用户改用小程序的openid，避免重复注册
+func (s *Service) linkUnionid(ctx context.Context, data *model.User) error {
+	db := s.mysql.WithContext(ctx)
+	var n int64
+	if err := db.Model(&model.User{}).Where("openid = ?", data.Openid).Count(&n).Error; err != nil || n > 0 {
+		return err
+	}
+	return db.Model(&model.User{}).
+		Where("unionid = ? AND openid IN (?)", data.Unionid,
+			db.Model(&model.UserWechat{}).Select("openid").Where("unionid = ?", data.Unionid)).
+		Update("openid", data.Openid).Error
+}
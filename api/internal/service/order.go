@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"gorm.io/gorm"
+	"project/model"
+	"project/pkg/fsm"
+	"project/pkg/logger"
+	"project/pkg/reqctx"
+	"strconv"
+)
+
+// PayOrder 支付成功回调，金额不一致时不更新，返回false由对账任务告警
+// 已关闭的订单入账为OrderPaidLate，不发货，需人工退款；正常支付后删除库存预扣记录
+func (s *Service) PayOrder(ctx context.Context, orderNo, transactionID string, amount, payTime int64) (bool, error) {
+	order, err := s.FindOrderByNo(ctx, orderNo)
+	if err != nil || order.ID == 0 || order.Amount != amount {
+		return false, err
+	}
+	if order.TransactionID == transactionID {
+		return true, nil // 重复通知视为成功
+	}
+	c := &fsm.Change[int8]{
+		Subject:  orderNo,
+		Event:    model.OrderEventPay,
+		From:     order.Status,
+		Operator: "system",
+		Remark:   order.Provider + ":" + transactionID,
+	}
+	err = model.TransitOrder(ctx, s.orderFSM, s.mysql, c, map[string]any{
+		"transaction_id": transactionID,
+		"pay_time":       payTime,
+	}, nil)
+	if err == nil && c.To == model.OrderPaidLate {
+		logger.FromContext(ctx).Warn("order paid after closed", orderNo, transactionID)
+	}
+	if err == nil && c.To == model.OrderPaid {
+		s.clearStockHold(ctx, orderNo)
+	}
+	return model.TransitResult(err)
+}
+
+// FinishRefund 退款结果回调，成功时更新订单为已退款，失败时释放占用的可退金额
+func (s *Service) FinishRefund(ctx context.Context, refundNo, refundID string, success bool, successTime int64) error {
+	var refund model.OrderRefund
+	err := s.mysql.WithContext(ctx).Take(&refund, "refund_no = ?", refundNo).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil || refund.Status != model.RefundProcessing {
+		return err // 已处理的重复通知
+	}
+	order, err := s.FindOrderByNo(ctx, refund.OrderNo)
+	if err != nil || order.ID == 0 {
+		return err
+	}
+	c := &fsm.Change[int8]{
+		Subject:  order.OrderNo,
+		Event:    model.OrderEventRefundSuccess,
+		From:     order.Status,
+		Operator: "system",
+		Remark:   refundNo,
+		Payload:  order,
+	}
+	status, updates := model.RefundSuccess, map[string]any{}
+	if !success {
+		status = model.RefundFailed
+		c.Event = model.OrderEventRefundFail
+		order.RefundAmount -= refund.Amount
+		updates["refund_amount"] = gorm.Expr("refund_amount - ?", refund.Amount)
+	}
+	err = model.TransitOrder(ctx, s.orderFSM, s.mysql, c, updates, func(tx *gorm.DB) error {
+		opt := tx.Model(&refund).Where("status = ?", model.RefundProcessing).Updates(map[string]any{
+			"status":       status,
+			"refund_id":    refundID,
+			"success_time": successTime,
+		})
+		if opt.Error == nil && opt.RowsAffected == 0 {
+			return fsm.ErrConflict
+		}
+		return opt.Error
+	})
+	_, err = model.TransitResult(err)
+	return err
+}
+
+func (s *Service) FindOrderByNo(ctx context.Context, orderNo string) (*model.Order, error) {
+	var data model.Order
+	err := s.mysql.WithContext(ctx).Take(&data, "order_no = ?", orderNo).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// SetOrderProvider 发起支付时记录渠道，已支付的订单不可更换
+// couponID大于0时在同一事务中核销优惠券并减免order的应付金额；渠道的预支付单金额不可修改，只能在首次发起支付时使用
+func (s *Service) SetOrderProvider(ctx context.Context, order *model.Order, provider string, couponID int) (bool, error) {
+	err := s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]any{"provider": provider}
+		var discount int64
+		if couponID > 0 && couponID != order.CouponID {
+			if order.Provider != "" || order.CouponID > 0 {
+				return model.ErrCouponOrder
+			}
+			d, err := redeemCoupon(tx, order, couponID)
+			if err != nil {
+				return err
+			}
+			discount = d
+			updates["coupon_id"] = couponID
+			updates["discount"] = d
+			updates["amount"] = gorm.Expr("amount - ?", d)
+		}
+		// 以查询时的券和金额为条件，并发发起支付时只有一个请求能核销
+		opt := tx.Model(&model.Order{}).
+			Where("order_no = ? AND status = ? AND coupon_id = ? AND amount = ?",
+				order.OrderNo, model.OrderCreated, order.CouponID, order.Amount).
+			Updates(updates)
+		if opt.Error != nil {
+			return opt.Error
+		}
+		if opt.RowsAffected == 0 {
+			return fsm.ErrConflict // 回滚已核销的券
+		}
+		if discount > 0 {
+			order.CouponID, order.Discount, order.Amount = couponID, discount, order.Amount-discount
+		}
+		return nil
+	})
+	if err == fsm.ErrConflict {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CancelOrder 用户取消待支付订单，同时退回已核销的券和预扣的库存；返回false表示当前状态不可取消
+// 回补失败时由script的order:timeout在到期后对已关闭的订单重试
+func (s *Service) CancelOrder(ctx context.Context, order *model.Order, remark string) (bool, error) {
+	err := model.TransitOrder(ctx, s.orderFSM, s.mysql, &fsm.Change[int8]{
+		Subject:  order.OrderNo,
+		Event:    model.OrderEventCancel,
+		From:     order.Status,
+		Operator: "user:" + strconv.Itoa(order.UserID),
+		Remark:   remark,
+	}, nil, nil)
+	if err != nil {
+		return model.TransitResult(err)
+	}
+	if err = s.ReleaseCoupon(ctx, order.OrderNo); err != nil {
+		return true, err
+	}
+	return true, s.RestockOrder(ctx, order.OrderNo, nil)
+}
+
+// ScheduleOrderTimeout 延迟投递超时关闭消息，script的order:timeout在到期时仍未支付则关闭订单
+func (s *Service) ScheduleOrderTimeout(ctx context.Context, orderNo string) error {
+	b, _ := json.Marshal(&model.MsgOrderTimeout{OrderNo: orderNo})
+	return s.delayer.Publish(ctx, model.TopicOrderTimeout, b, model.OrderPayTimeout)
+}
+
+// CompleteOrder 用户确认收货
+func (s *Service) CompleteOrder(ctx context.Context, order *model.Order) (bool, error) {
+	err := model.TransitOrder(ctx, s.orderFSM, s.mysql, &fsm.Change[int8]{
+		Subject:  order.OrderNo,
+		Event:    model.OrderEventComplete,
+		From:     order.Status,
+		Operator: "user:" + strconv.Itoa(order.UserID),
+	}, nil, nil)
+	return model.TransitResult(err)
+}
+
+func (s *Service) publishOrder(ctx context.Context, c *fsm.Change[int8]) error {
+	return s.producer.PublishMeta(model.TopicOrder, reqctx.Meta(ctx), model.OrderMessage(c))
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"time"
+)
+
+// PresenceHeartbeat WebSocket网关上报的连接心跳，返回用户是否刚上线
+func (s *Service) PresenceHeartbeat(ctx context.Context, uid int, connID string) (bool, error) {
+	return s.presence.Heartbeat(ctx, uid, connID)
+}
+
+// PresenceLeave 连接断开，最后一个连接断开时记录最后在线时间；心跳超时的由script的cronjob记录
+func (s *Service) PresenceLeave(ctx context.Context, uid int, connID string) error {
+	offline, err := s.presence.Leave(ctx, uid, connID)
+	if err != nil || !offline {
+		return err
+	}
+	return s.saveLastSeen(ctx, []*model.UserPresence{{UserID: uid, LastSeen: time.Now().Unix()}})
+}
+
+func (s *Service) IsOnline(ctx context.Context, uid int) (bool, error) {
+	online, err := s.presence.Online(ctx, []int{uid})
+	if err != nil {
+		return false, err
+	}
+	_, ok := online[uid]
+	return ok, nil
+}
+
+// GetPresence 批量查询在线状态，离线用户从user_presence读取最后在线时间
+func (s *Service) GetPresence(ctx context.Context, uids []int) (map[int]*model.UserPresence, error) {
+	online, err := s.presence.Online(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int]*model.UserPresence, len(uids))
+	offline := make([]int, 0, len(uids))
+	for _, uid := range uids {
+		if t, ok := online[uid]; ok {
+			res[uid] = &model.UserPresence{UserID: uid, Online: true, LastSeen: t.Unix()}
+			continue
+		}
+		res[uid] = &model.UserPresence{UserID: uid}
+		offline = append(offline, uid)
+	}
+	if len(offline) == 0 {
+		return res, nil
+	}
+	var list []*model.UserPresence
+	if err = s.mysql.WithContext(ctx).Where("user_id IN ?", offline).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, v := range list {
+		res[v.UserID].LastSeen = v.LastSeen
+	}
+	return res, nil
+}
+
+// saveLastSeen 只保留较晚的时间，断开和超时清理的先后顺序不影响结果
+func (s *Service) saveLastSeen(ctx context.Context, list []*model.UserPresence) error {
+	return s.mysql.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{"last_seen": gorm.Expr("GREATEST(last_seen, VALUES(last_seen))")}),
+	}).Create(list).Error
+}
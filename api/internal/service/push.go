@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"project/pkg/reqctx"
+)
+
+// RegisterPushDevice 按设备ID登记token，换账号登录或token刷新时覆盖
+func (s *Service) RegisterPushDevice(ctx context.Context, d *model.PushDevice) error {
+	return s.mysql.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "device_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "provider", "token", "app_version", "update_time"}),
+	}).Create(d).Error
+}
+
+// UnregisterPushDevice 退出登录或关闭通知时删除，只能删除自己的设备
+func (s *Service) UnregisterPushDevice(ctx context.Context, uid int, deviceID string) error {
+	return s.mysql.WithContext(ctx).
+		Where("device_id = ? AND user_id = ?", deviceID, uid).Delete(&model.PushDevice{}).Error
+}
+
+func (s *Service) FindPushDevices(ctx context.Context, uid int) (list []*model.PushDevice, err error) {
+	err = s.mysql.WithContext(ctx).Where("user_id = ?", uid).Order("update_time DESC").Find(&list).Error
+	return
+}
+
+// GetPushPreference 未设置时为默认偏好(auto，全部接收，不启用免打扰)
+func (s *Service) GetPushPreference(ctx context.Context, uid int) (*model.PushPreference, error) {
+	var list []*model.PushPreference
+	err := s.mysql.WithContext(ctx).Where("user_id = ?", uid).Limit(1).Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return model.DefaultPushPreference(uid), err
+	}
+	return list[0], nil
+}
+
+// SetPushPreference 整体覆盖用户的推送偏好
+func (s *Service) SetPushPreference(ctx context.Context, p *model.PushPreference) error {
+	return s.mysql.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"channel", "muted", "quiet_start", "quiet_end"}),
+	}).Create(p).Error
+}
+
+// Push 投递推送任务，返回消息ID用于查询发送结果；选择渠道和发送由script的push:send完成
+func (s *Service) Push(ctx context.Context, msg *model.MsgPush) (string, error) {
+	if msg.MsgID == "" {
+		id, err := s.ids.NextString("")
+		if err != nil {
+			return "", err
+		}
+		msg.MsgID = id
+	}
+	b, _ := json.Marshal(msg)
+	return msg.MsgID, s.producer.PublishMeta(model.TopicPush, reqctx.Meta(ctx), b)
+}
+
+func (s *Service) FindPushDeliveries(ctx context.Context, msgID string) (list []*model.PushDelivery, err error) {
+	err = s.mysql.WithContext(ctx).Where("msg_id = ?", msgID).Find(&list).Error
+	return
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"project/model"
+	"project/pkg/logger"
+	"time"
+)
+
+// recordMax 同一trace_id最多保留的外部调用数
+const recordMax = 100
+
+// SaveRecord 追加到trace_id对应的列表，过期时间从第一次录制开始计算
+func (s *Service) SaveRecord(ctx context.Context, ex *logger.Exchange, ttl time.Duration) error {
+	b, err := json.Marshal(ex)
+	if err != nil {
+		return err
+	}
+	key := model.KeyRecord + ex.TraceID
+	pipe := s.redis.TxPipeline()
+	push := pipe.RPush(ctx, key, b)
+	pipe.LTrim(ctx, key, -recordMax, -1)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return err
+	}
+	if push.Val() == 1 {
+		err = s.redis.Expire(ctx, key, ttl).Err()
+	}
+	return err
+}
+
+func (s *Service) GetRecords(ctx context.Context, traceID string) ([]*logger.Exchange, error) {
+	list, err := s.redis.LRange(ctx, model.KeyRecord+traceID, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*logger.Exchange, 0, len(list))
+	for _, v := range list {
+		var ex logger.Exchange
+		if json.Unmarshal([]byte(v), &ex) == nil {
+			res = append(res, &ex)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/region"
+	"project/pkg/util/random"
+	"time"
+)
+
+// Regions 当前的行政区划数据集，未导入时为空数据集
+func (s *Service) Regions() *region.Dataset {
+	ds, _ := s.regions.Load().(*region.Dataset)
+	return ds
+}
+
+// loadRegions 最新版本与当前不同时重新加载
+func (s *Service) loadRegions(ctx context.Context) error {
+	var list []*model.RegionVersion
+	err := s.mysql.WithContext(ctx).Order("create_time DESC").Limit(1).Find(&list).Error
+	if err != nil || len(list) == 0 || list[0].Version == s.Regions().Version {
+		return err
+	}
+	var rows []*model.Region
+	err = s.mysql.WithContext(ctx).Where("status = ?", model.RegionValid).Find(&rows).Error
+	if err != nil {
+		return err
+	}
+	regions := make([]*region.Region, len(rows))
+	for i, v := range rows {
+		regions[i] = &region.Region{Code: v.Code, Name: v.Name, Parent: v.Parent, Level: v.Level}
+	}
+	ds, err := region.New(list[0].Version, regions)
+	if err != nil {
+		return err
+	}
+	s.regions.Store(ds)
+	return nil
+}
+
+func (s *Service) watchRegions(ctx context.Context) {
+	tick := time.NewTicker(time.Minute)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+		c, l := logger.NewCtxLog(random.UUID(), "Region", "watchRegions", "")
+		if err := s.loadRegions(c); err != nil {
+			l.Error("loadRegions error", nil, err)
+		}
+	}
+}
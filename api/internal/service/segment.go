@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"project/model"
+)
+
+// UserSegments 用户所在的启用中分群code，成员由script的cronjob定时计算，计算期间为新旧成员的并集
+func (s *Service) UserSegments(ctx context.Context, uid int) (map[string]bool, error) {
+	var codes []string
+	err := s.mysql.WithContext(ctx).Model(&model.SegmentMember{}).
+		Joins("JOIN segment ON segment.id = segment_member.segment_id").
+		Where("segment_member.user_id = ? AND segment.status = ?", uid, model.StatusOn).
+		Pluck("segment.code", &codes).Error
+	res := make(map[string]bool, len(codes))
+	for _, v := range codes {
+		res[v] = true
+	}
+	return res, err
+}
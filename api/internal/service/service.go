@@ -2,19 +2,54 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/go-redis/redis/v8"
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 	"project/model"
+	"project/pkg/boot"
 	"project/pkg/cache"
+	"project/pkg/captcha"
 	"project/pkg/db"
+	"project/pkg/fsm"
+	"project/pkg/geo"
+	"project/pkg/id"
+	"project/pkg/logger"
+	"project/pkg/mq"
+	"project/pkg/presence"
+	"project/pkg/region"
+	"project/pkg/saga"
+	"project/pkg/shortlink"
+	"project/pkg/track"
+	"project/pkg/wechat"
+	"project/pkg/wechat/tokenstore"
+	"sync/atomic"
+	"time"
 )
 
 type Service struct {
-	mysql *gorm.DB
-	redis *redis.Client
-	//nsq    *nsq.Producer
-	single *singleflight.Group
+	mysql    *gorm.DB
+	redis    *redis.Client
+	producer mq.Producer
+	single   *singleflight.Group
+	tokens   *tokenstore.Redis
+
+	orderFSM  *fsm.Machine[int8]
+	shortlink *shortlink.Shortener
+	captcha   *captcha.Captcha
+	tracker   *track.Tracker
+	delayer   *mq.Delayer
+	ids       *id.Generator
+	shards    *db.Cluster
+	presence  *presence.Presence
+	geo       *geo.Index
+	geocoder  region.Geocoder
+	regions   atomic.Value // *region.Dataset
+
+	exchange      *saga.Saga[exchangeData]
+	exchangeItems map[int]*ExchangeItem
+	checkin       CheckinConfig
+	stop          context.CancelFunc
 }
 
 type Config struct {
@@ -23,21 +58,96 @@ type Config struct {
 	Nsq   struct {
 		Producer string
 	}
+	Kafka    *mq.KafkaConfig        // 配置brokers后消息投递到kafka，nsq不生效
+	Track    track.Options          // 业务埋点的批量投递
+	Exchange []*ExchangeItem        // 积分兑换的商品和所需积分
+	Delay    mq.DelayOptions        // 延迟消息，nsq超过1小时或kafka时写入redis，由script的mq:delay投递
+	Captcha  captcha.Options        // 滑块/图片验证码，图片验证码需配置字体
+	ID       id.Options             // 订单号、消息ID的发号器，worker通过redis租约分配
+	Shard    db.ShardConfig         // 按用户分片的表(登录事件、设备)，未配置分片时都在主库
+	Presence presence.Options       // 在线状态的心跳超时
+	Geocoder *region.GeocoderConfig // 收货地址解析坐标，未配置key不解析
+	Checkin  CheckinConfig          // 每日签到的默认时区和奖励积分
 }
 
 func New(cfg *Config) *Service {
 	s := &Service{
-		mysql: db.NewMysqlDB(&cfg.Mysql),
-		redis: cache.NewRedisClient(&cfg.Redis),
-		//nsq:    mq.NewNsqProducer(cfg.Nsq.Producer),
-		single: &singleflight.Group{},
+		mysql:    db.NewMysqlDB(&cfg.Mysql),
+		redis:    cache.NewRedisClient(&cfg.Redis),
+		producer: mq.NewProducer(cfg.Nsq.Producer, cfg.Kafka),
+		single:   &singleflight.Group{},
+	}
+	s.orderFSM = model.NewOrderFSM().OnTransition(s.publishOrder)
+	s.tokens = tokenstore.NewRedis(s.redis)
+	s.shortlink = shortlink.New(s.mysql, s.redis, 0)
+	s.captcha = captcha.New(s.redis, &cfg.Captcha)
+	s.delayer = mq.NewDelayer(s.producer, s.redis, model.KeyDelayQueue, &cfg.Delay)
+	s.shards = db.NewCluster(s.mysql, &cfg.Shard)
+	s.presence = presence.New(s.redis, model.KeyPresence, &cfg.Presence)
+	s.geo = geo.New(s.redis, model.KeyGeo)
+	s.geocoder = region.NewGeocoder(cfg.Geocoder, logger.NewClient("geocoder", 5*time.Second))
+	empty, _ := region.New("", nil)
+	s.regions.Store(empty)
+	s.ids = id.New(id.NewRedisLease(s.redis, model.KeyIDWorker), &cfg.ID)
+	s.tracker = track.New(model.TopicTrack, s.producer.MultiPublish, &cfg.Track)
+	s.exchange = s.newExchangeSaga()
+	s.exchangeItems = make(map[int]*ExchangeItem, len(cfg.Exchange))
+	for _, v := range cfg.Exchange {
+		s.exchangeItems[v.SkuID] = v
+	}
+	if s.checkin = cfg.Checkin; s.checkin.Timezone == "" {
+		s.checkin.Timezone = "Local"
 	}
+	var ctx context.Context
+	ctx, s.stop = context.WithCancel(context.Background())
+	go s.ResumeSagas(ctx)
+	go s.watchRegions(ctx)
+	boot.OnWarmup("mysql", boot.WarmMysql(s.mysql, cfg.Mysql.MaxIdle))
+	boot.OnWarmup("region", s.loadRegions)
 	return s
 }
 
-func (s *Service) WechatToken(ctx context.Context) (string, error) {
-	val, err, _ := s.single.Do("WechatToken", func() (any, error) {
-		return s.redis.Get(ctx, model.KeyWechatToken).Result()
+// Close 退出前投递缓冲中的埋点，再关闭消息队列连接
+func (s *Service) Close() {
+	s.stop()
+	s.tracker.Close()
+	s.producer.Stop()
+	s.ids.Close()
+}
+
+// Checks 启动阶段检查的依赖、加载分片映射并获取发号器的worker，access_token由script的refresh:token写入redis，未就绪仅告警
+func (s *Service) Checks() []boot.Check {
+	return []boot.Check{
+		boot.Mysql(s.mysql),
+		boot.Redis(s.redis),
+		boot.MQ(s.producer),
+		{Name: "shard", Fn: s.shards.Start},
+		{Name: "id", Fn: s.ids.Start},
+		{Name: "wechat", Optional: true, Fn: func(ctx context.Context) error {
+			_, _, err := s.tokens.Get(ctx, model.KeyWechatToken)
+			if errors.Is(err, wechat.ErrNoToken) {
+				return errors.New("access_token not found, is refresh:token running?")
+			}
+			return err
+		}},
+	}
+}
+
+// TokenStore access_token、jsapi_ticket的存储，由script的refresh:token写入
+func (s *Service) TokenStore() wechat.TokenStore {
+	return s.tokens
+}
+
+// WechatQuota 微信接口每日额度，计数在redis中与script共享
+func (s *Service) WechatQuota(appid string, limits map[string]int64) *wechat.Quota {
+	return &wechat.Quota{Counter: s.tokens, Prefix: model.KeyWechatQuota + appid + ":", Limits: limits}
+}
+
+// JSAPITicket 公众号的jsapi_ticket，由script定时刷新
+func (s *Service) JSAPITicket(ctx context.Context) (string, error) {
+	val, err, _ := s.single.Do("JSAPITicket", func() (any, error) {
+		tk, _, err := s.tokens.Get(ctx, model.KeyJSAPITicket)
+		return tk, err
 	})
 	return val.(string), err
 }
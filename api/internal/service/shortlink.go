@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"project/model"
+	"project/pkg/shortlink"
+)
+
+func (s *Service) ResolveShortLink(ctx context.Context, code string) (*shortlink.Link, error) {
+	return s.shortlink.Resolve(ctx, code)
+}
+
+// PushShortLinkClick 异步投递点击事件，不阻塞跳转
+func (s *Service) PushShortLinkClick(_ context.Context, click *shortlink.Click) error {
+	b, _ := json.Marshal(click)
+	return s.producer.PublishAsync(model.TopicClick, b)
+}
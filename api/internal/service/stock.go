@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"project/model"
+	"project/pkg/logger"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DeductStock 下单时在redis中预扣库存(sku_id->数量)，投递回写消息失败时回补，保证不超卖
+func (s *Service) DeductStock(ctx context.Context, orderNo string, items map[int]int) error {
+	skus := make([]int, 0, len(items))
+	for sku := range items {
+		skus = append(skus, sku)
+	}
+	sort.Ints(skus)
+	keys := make([]string, 0, len(skus)*2+1)
+	args := make([]any, 0, len(skus)*2+2)
+	args = append(args, orderNo, int(model.StockOrderTTL.Seconds()))
+	for _, sku := range skus {
+		keys = append(keys, model.StockKey(sku), model.StockHoldKey(sku))
+		args = append(args, items[sku])
+	}
+	keys = append(keys, model.StockOrderKey(orderNo))
+	for _, sku := range skus {
+		args = append(args, sku)
+	}
+	ret, err := model.ScriptStockDeduct.Run(ctx, s.redis, keys, args...).Int()
+	if err != nil {
+		return err
+	}
+	switch ret {
+	case 0:
+		return model.ErrStockNotEnough
+	case -2:
+		return model.ErrStockNotReady
+	}
+
+	now := time.Now().Unix()
+	body := make([][]byte, 0, len(skus))
+	for _, sku := range skus {
+		b, _ := json.Marshal(&model.MsgStock{
+			SkuID:    sku,
+			OrderNo:  orderNo,
+			Quantity: items[sku],
+			Type:     model.StockDeduct,
+			Time:     now,
+		})
+		body = append(body, b)
+	}
+	if err = s.producer.MultiPublish(model.TopicStock, body); err != nil {
+		for _, sku := range skus {
+			keys := []string{model.StockKey(sku), model.StockHoldKey(sku)}
+			if e := model.ScriptStockRestock.Run(ctx, s.redis, keys, orderNo).Err(); e != nil {
+				logger.FromContext(ctx).Error("redis.ScriptStockRestock error", keys, e)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// RestockOrder 订单取消时回补预扣的库存，skus为空时回补订单预扣过的全部sku，重复调用不会多次回补
+func (s *Service) RestockOrder(ctx context.Context, orderNo string, skus []int) error {
+	body, err := model.RestockOrder(ctx, s.redis, orderNo, skus)
+	if len(body) > 0 {
+		if e := s.producer.MultiPublish(model.TopicStock, body); e != nil {
+			return e
+		}
+	}
+	return err
+}
+
+// clearStockHold 订单已支付，删除预扣记录，失败只记录日志
+func (s *Service) clearStockHold(ctx context.Context, orderNo string) {
+	if err := model.ClearStockHold(ctx, s.redis, orderNo); err != nil {
+		logger.FromContext(ctx).Error("model.ClearStockHold error", orderNo, err)
+	}
+}
+
+// GetStock 可售库存，未初始化返回-1
+func (s *Service) GetStock(ctx context.Context, skus []int) (map[int]int, error) {
+	keys := make([]string, 0, len(skus))
+	for _, sku := range skus {
+		keys = append(keys, model.StockKey(sku))
+	}
+	vals, err := s.redis.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int]int, len(skus))
+	for i, sku := range skus {
+		res[sku] = -1
+		if v, ok := vals[i].(string); ok {
+			res[sku], _ = strconv.Atoi(v)
+		}
+	}
+	return res, nil
+}
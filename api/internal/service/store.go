@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"project/api/internal/proto"
+	"project/model"
+)
+
+// NearbyStores radius米内营业中的门店，按距离升序；索引中已停业或删除的门店跳过
+func (s *Service) NearbyStores(ctx context.Context, lng, lat float64, radius, limit int) ([]*proto.StoreItem, error) {
+	hits, err := s.geo.Nearby(ctx, model.GeoStore, lng, lat, float64(radius), limit)
+	if err != nil || len(hits) == 0 {
+		return []*proto.StoreItem{}, err
+	}
+	ids := make([]int, len(hits))
+	for i, v := range hits {
+		ids[i] = v.ID
+	}
+	var list []*model.Store
+	err = s.mysql.WithContext(ctx).Where("id IN ? AND status = ?", ids, model.StoreOpen).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	stores := make(map[int]*model.Store, len(list))
+	for _, v := range list {
+		stores[v.ID] = v
+	}
+	res := make([]*proto.StoreItem, 0, len(list))
+	for _, v := range hits {
+		if st, ok := stores[v.ID]; ok {
+			item := storeItem(st)
+			item.Distance = int(v.Distance + 0.5)
+			res = append(res, item)
+		}
+	}
+	return res, nil
+}
+
+func (s *Service) FindStore(ctx context.Context, id int) (*proto.StoreItem, error) {
+	var list []*model.Store
+	err := s.mysql.WithContext(ctx).Where("id = ? AND status = ?", id, model.StoreOpen).Limit(1).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, model.ErrStoreNotFound
+	}
+	return storeItem(list[0]), nil
+}
+
+func storeItem(v *model.Store) *proto.StoreItem {
+	return &proto.StoreItem{
+		ID:      v.ID,
+		Name:    v.Name,
+		City:    v.City,
+		Address: v.Address,
+		Phone:   v.Phone,
+		Hours:   v.Hours,
+		Lng:     v.Lng,
+		Lat:     v.Lat,
+	}
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+	"project/pkg/track"
+)
+
+// Track 异步上报业务埋点，缓冲区满或投递失败时丢弃，不影响请求
+func (s *Service) Track(ctx context.Context, uid int, p track.Props) {
+	s.tracker.Emit(ctx, uid, p)
+}
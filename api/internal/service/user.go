@@ -5,15 +5,23 @@ import (
 	"crypto/sha1"
 	"encoding/base32"
 	"encoding/json"
+	"errors"
 	"github.com/go-redis/redis/v8"
 	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
 	"project/api/internal/proto"
 	"project/model"
 	"project/pkg/logger"
+	"project/pkg/reqctx"
 	"time"
 )
 
 func (s *Service) SaveUser(ctx context.Context, data *model.User) (int, error) {
+	if data.Unionid != "" {
+		if err := s.linkUnionid(ctx, data); err != nil {
+			return 0, err
+		}
+	}
 	err := s.mysql.WithContext(ctx).FirstOrCreate(data, "openid = ?", data.Openid).Error
 	if err != nil || data.ID == 0 {
 		return 0, err
@@ -25,6 +33,24 @@ func (s *Service) SaveUser(ctx context.Context, data *model.User) (int, error) {
 	return data.ID, nil
 }
 
+// DetectUserMerge 同一unionid存在其他用户(如历史上按不同openid注册)时投递合并任务，由script的user:merge处理
+func (s *Service) DetectUserMerge(ctx context.Context, uid int, unionid string) {
+	var ids []int
+	err := s.mysql.WithContext(ctx).Model(&model.User{}).Where("unionid = ? AND id <> ?", unionid, uid).
+		Limit(1).Pluck("id", &ids).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("mysql.Pluck error", unionid, err)
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+	b, _ := json.Marshal(&model.MsgUserMerge{Unionid: unionid, Time: time.Now().Unix()})
+	if err = s.producer.PublishMeta(model.TopicUserMerge, reqctx.Meta(ctx), b); err != nil {
+		logger.FromContext(ctx).Error("producer.Publish error", unionid, err)
+	}
+}
+
 func (s *Service) SetUserToken(ctx context.Context, data *proto.UserToken) (string, error) {
 	h := sha1.New()
 	h.Write([]byte(data.Openid))
@@ -93,3 +119,29 @@ func (s *Service) UpdateUser(ctx context.Context, data *model.User) error {
 	}
 	return nil
 }
+
+// GetUserStatus 账号处罚状态，缓存10分钟，cms修改时删除缓存；未处罚的用户返回Status为0
+func (s *Service) GetUserStatus(ctx context.Context, uid int) (*model.UserStatus, error) {
+	key := model.UserStatusKey(uid)
+	b, err := s.redis.Get(ctx, key).Bytes()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	var res model.UserStatus
+	if len(b) > 0 {
+		err = json.Unmarshal(b, &res)
+		return &res, err
+	}
+	err = s.mysql.WithContext(ctx).Where("user_id = ?", uid).Take(&res).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res, err = model.UserStatus{UserID: uid}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	b, _ = json.Marshal(res)
+	if err := s.redis.Set(ctx, key, b, 10*time.Minute).Err(); err != nil {
+		logger.FromContext(ctx).Error("redis.Set error", key, err)
+	}
+	return &res, nil
+}
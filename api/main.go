@@ -2,21 +2,26 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
+	"io"
 	"log"
 	"math/rand"
-	"net/http"
 	"os"
 	"os/signal"
 	"project/api/internal/handler"
 	"project/api/internal/service"
+	"project/pkg/boot"
 	"project/pkg/logger"
+	"project/pkg/server"
+	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 )
 
-func setup() *http.Server {
+func setup() (*server.Server, *server.Server, []*server.Server, *service.Service, *boot.Options) {
 	viper.SetConfigName("conf")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
@@ -27,8 +32,11 @@ func setup() *http.Server {
 
 	var cfg struct {
 		App struct {
-			Mode   string
-			Logger string
+			Mode    string
+			Logger  string
+			Boot    boot.Options           // 启动时依赖检查的重试次数、间隔
+			Http    logger.TransportConfig // 外部调用的连接池和超时
+			Upgrade server.UpgradeOptions  // SIGHUP平滑升级
 		}
 		Handler handler.Config
 		Service service.Config
@@ -39,35 +47,105 @@ func setup() *http.Server {
 
 	gin.SetMode(cfg.App.Mode)
 	logger.SetOutput(cfg.App.Logger)
+	logger.SetTransport(&cfg.App.Http)
+	server.SetUpgrade(&cfg.App.Upgrade)
 	rand.Seed(time.Now().UnixNano())
 
 	s := service.New(&cfg.Service)
-	h := handler.Initialize(&cfg.Handler, s)
-	server := &http.Server{
-		Addr:    ":8000",
-		Handler: h,
+	if len(os.Args) > 1 && os.Args[1] == "routes" { // 输出路由清单后退出，不检查依赖
+		gin.DefaultWriter = io.Discard // 不输出debug模式的路由注册日志
+		h, _, listeners := handler.Initialize(&cfg.Handler, s)
+		printRoutes(handler.Routes(&cfg.Handler, h, listeners))
+		os.Exit(0)
 	}
-	return server
+	if err := boot.Wait(&cfg.App.Boot, s.Checks()...); err != nil {
+		log.Fatal(err)
+	}
+	h, in, listeners := handler.Initialize(&cfg.Handler, s)
+	srv, err := server.New(&cfg.Handler.Server, ":8000", h)
+	if err != nil {
+		log.Fatal("server.New error: ", err)
+	}
+	var internal *server.Server
+	if in != nil {
+		internal, err = server.New(&server.Config{Addr: cfg.Handler.Internal.Addr, TLS: cfg.Handler.Internal.TLS}, "", in)
+		if err != nil {
+			log.Fatal("server.New internal error: ", err)
+		}
+	}
+	vhosts := make([]*server.Server, len(listeners))
+	for i, v := range listeners {
+		vhosts[i], err = server.New(v.Config, "", v.Engine)
+		if err != nil {
+			log.Fatalf("server.New vhost %s error: %v", v.Name, err)
+		}
+	}
+	return srv, internal, vhosts, s, &cfg.App.Boot
 }
 
-func main() {
-	server := setup()
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal(err)
+func printRoutes(list []*handler.RouteInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tMIDDLEWARE\tDEPRECATED\tLISTENER")
+	for _, v := range list {
+		deprecated := "-"
+		if v.Deprecated != nil {
+			deprecated = "sunset " + v.Deprecated.Sunset
+		}
+		listener := v.Listener
+		if listener == "" {
+			listener = "-"
 		}
-	}()
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", v.Method, v.Path, v.Handler, strings.Join(v.Middleware, ","), deprecated, listener)
+	}
+	_ = w.Flush()
+}
+
+func main() {
+	srv, internal, vhosts, s, opt := setup()
+	if internal != nil {
+		internal.Run() // 先启动内部端口，预热期间ready返回503
+	}
+	boot.RunWarmup(opt)
+	srv.Run()
+	for _, v := range vhosts {
+		v.Run()
+	}
+	if err := server.Ready(); err != nil {
+		log.Println("server.Ready error: ", err)
+	}
 
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-	c := <-quit
-	log.Println("signal.Notify: ", c.String())
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
+	for {
+		c := <-quit
+		log.Println("signal.Notify: ", c.String())
+		if c != syscall.SIGHUP {
+			break
+		}
+		if err := server.Upgrade(); err != nil { // 新进程未就绪时继续服务
+			log.Println("server.Upgrade error: ", err)
+			continue
+		}
+		break
+	}
 
 	//ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	//defer cancel() // 带超时控制，等待所有协程退出，或10秒强制退出
 	ctx := context.Background() // 不带超时控制，等待所有协程退出
-	if err := server.Shutdown(ctx); err != nil {
+	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server Shutdown: ", err)
 	}
+	for _, v := range vhosts {
+		if err := v.Shutdown(ctx); err != nil {
+			log.Fatal("VHost Server Shutdown: ", err)
+		}
+	}
+	if internal != nil {
+		if err := internal.Shutdown(ctx); err != nil {
+			log.Fatal("Internal Server Shutdown: ", err)
+		}
+	}
+	handler.Close() // 请求处理完后写入剩余access日志
+	s.Close()       // 投递剩余埋点
 	log.Println("Server Exit...")
 }
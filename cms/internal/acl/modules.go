@@ -3,6 +3,8 @@ package acl
 const (
 	ModuleAdmin  = "admin"
 	ModuleApplet = "applet"
+	ModuleOrder  = "order"
+	ModuleMp     = "mp"
 )
 
 const (
@@ -19,6 +21,8 @@ type Module struct {
 var Modules = []*Module{
 	{Key: ModuleAdmin, Name: "账号权限"},
 	{Key: ModuleApplet, Name: "小程序运营"},
+	{Key: ModuleOrder, Name: "订单管理"},
+	{Key: ModuleMp, Name: "公众号运营"},
 }
 
 var AllAuthority = make(Authority)
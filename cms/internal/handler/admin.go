@@ -397,3 +397,47 @@ func (h *Handler) AdminUserStatus(c *gin.Context) {
 	}
 	c.JSON(OK, Empty)
 }
+
+func (h *Handler) AdminUserBatchStatus(c *gin.Context) {
+	items, res, valid, ok := bindBatch[*proto.SwitchStatusArgs](c)
+	if !ok {
+		return
+	}
+	ids := make([]int, 0, len(valid))
+	for _, i := range valid {
+		ids = append(ids, items[i].ID)
+	}
+	users, err := h.service.FindAdminUsersByIDs(c, ids)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindAdminUsersByIDs error", ids, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	group := make(map[int8][]int, 2) // status => 结果下标
+	for _, i := range valid {
+		user, ok := users[items[i].ID]
+		if !ok {
+			res[i].Status = NotFound
+			res[i].Msg = "无效的用户ID"
+			continue
+		}
+		if user.Status != items[i].Status {
+			group[items[i].Status] = append(group[items[i].Status], i)
+		}
+	}
+	for status, idx := range group {
+		ids = ids[:0]
+		for _, i := range idx {
+			ids = append(ids, items[i].ID)
+		}
+		if err := h.service.BatchUpdateAdminUserStatus(c, status, ids); err != nil {
+			logger.FromContext(c).Error("service.BatchUpdateAdminUserStatus error", ids, err)
+			code, resp := RespWithErr(err)
+			for _, i := range idx {
+				res[i].Status = code
+				res[i].Msg = resp.Msg
+			}
+		}
+	}
+	c.JSON(OK, batchResp(res))
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/wechat"
+	"time"
+)
+
+// 日期范围最多查询一年
+const analysisMaxDays = 366
+
+func bindAnalysisRange(c *gin.Context) (*proto.AnalysisRangeArgs, bool) {
+	var r proto.AnalysisRangeArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return nil, false
+	}
+	begin, err1 := time.Parse(wechat.DateFormat, r.BeginDate)
+	end, err2 := time.Parse(wechat.DateFormat, r.EndDate)
+	if err1 != nil || err2 != nil || end.Sub(begin) > analysisMaxDays*24*time.Hour {
+		c.JSON(RespWithMsg(InvalidParam, "日期范围无效"))
+		return nil, false
+	}
+	return &r, true
+}
+
+func (h *Handler) AnalysisTrend(c *gin.Context) {
+	r, ok := bindAnalysisRange(c)
+	if !ok {
+		return
+	}
+	list, err := h.service.FindWechatAnalysis(c, r)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindWechatAnalysis error", r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.WechatAnalysis, 0)
+	}
+	c.JSON(OK, &proto.AnalysisTrendResp{List: list})
+}
+
+func (h *Handler) AnalysisRetain(c *gin.Context) {
+	r, ok := bindAnalysisRange(c)
+	if !ok {
+		return
+	}
+	list, err := h.service.FindWechatRetain(c, r)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindWechatRetain error", r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.WechatRetain, 0)
+	}
+	c.JSON(OK, &proto.AnalysisRetainResp{List: list})
+}
+
+func (h *Handler) AnalysisDistribution(c *gin.Context) {
+	var r proto.AnalysisDistributionArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	list, err := h.service.FindWechatDistribution(c, r.RefDate)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindWechatDistribution error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.WechatDistribution, 0)
+	}
+	c.JSON(OK, &proto.AnalysisDistributionResp{List: list})
+}
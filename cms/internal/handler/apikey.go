@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+)
+
+func (h *Handler) APIKeyList(c *gin.Context) {
+	list, err := h.service.AllAPIKeys(c)
+	if err != nil {
+		logger.FromContext(c).Error("service.AllAPIKeys error", nil, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.APIKey, 0)
+	}
+	c.JSON(OK, &proto.APIKeyListResp{List: list})
+}
+
+func (h *Handler) APIKeyAdd(c *gin.Context) {
+	var r proto.APIKeyAddArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	data := &model.APIKey{
+		Name:       r.Name,
+		DailyQuota: r.DailyQuota,
+	}
+	if err := h.service.CreateAPIKey(c, data); err != nil {
+		logger.FromContext(c).Error("service.CreateAPIKey error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, data)
+}
+
+func (h *Handler) APIKeyStatus(c *gin.Context) {
+	var r proto.SwitchStatusArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if err := h.service.UpdateAPIKeyStatus(c, r.ID, r.Status); err != nil {
+		logger.FromContext(c).Error("service.UpdateAPIKeyStatus error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// APIKeyUsage 合作方按日、路由的调用量，route为*的行是当日总量
+func (h *Handler) APIKeyUsage(c *gin.Context) {
+	var r proto.APIKeyUsageArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	list, err := h.service.FindAPIUsage(c, model.KeySubject(r.ID), r.Begin, r.End)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindAPIUsage error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.APIUsage, 0)
+	}
+	c.JSON(OK, &proto.APIKeyUsageResp{List: list})
+}
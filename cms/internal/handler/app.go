@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"regexp"
+)
+
+var reVersion = regexp.MustCompile(`^\d+(\.\d+){0,3}$`)
+
+func (h *Handler) AppVersionList(c *gin.Context) {
+	list, err := h.service.AllAppVersion(c)
+	if err != nil {
+		logger.FromContext(c).Error("service.AllAppVersion error", nil, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, &proto.AppVersionListResp{List: list})
+}
+
+// AppVersionSave 保存后api服务10秒内生效，覆盖配置文件中的同平台版本
+func (h *Handler) AppVersionSave(c *gin.Context) {
+	var r proto.AppVersionSaveArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !reVersion.MatchString(r.Min) {
+		c.JSON(RespWithMsg(InvalidParam, "版本号格式为数字和点，如2.3.0"))
+		return
+	}
+	err := h.service.SaveAppVersion(c, &model.AppVersion{
+		Platform: r.Platform,
+		Min:      r.Min,
+		URL:      r.URL,
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.SaveAppVersion error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func (h *Handler) AppVersionDelete(c *gin.Context) {
+	var r proto.AppVersionDelArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if err := h.service.DelAppVersion(c, r.Platform); err != nil {
+		logger.FromContext(c).Error("service.DelAppVersion error", r.Platform, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+)
+
+// AuthEventList 登录凭证审计，需指定用户或IP，按IP查询时合并全部分片
+func (h *Handler) AuthEventList(c *gin.Context) {
+	var r proto.AuthEventArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if r.UserID == 0 && r.IP == "" {
+		c.JSON(RespWithMsg(InvalidParam, "请指定用户或IP"))
+		return
+	}
+	list, err := h.service.FindAuthEvents(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindAuthEvents error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.AuthEvent, 0)
+	}
+	c.JSON(OK, &proto.AuthEventResp{List: list})
+}
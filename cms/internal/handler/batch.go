@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"project/cms/internal/proto"
+	"reflect"
+)
+
+// bindBatch 绑定批量请求并逐条校验，返回结果数组(校验失败的条目已填充400)和通过校验的下标
+func bindBatch[T any](c *gin.Context) ([]T, []*proto.BatchResult, []int, bool) {
+	var r proto.BatchArgs[T]
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return nil, nil, nil, false
+	}
+	res := make([]*proto.BatchResult, len(r.Items))
+	valid := make([]int, 0, len(r.Items))
+	for i, v := range r.Items {
+		res[i] = &proto.BatchResult{Index: i, Status: OK}
+		if rv := reflect.ValueOf(v); !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+			res[i].Status = InvalidParam
+			res[i].Msg = "参数错误"
+			continue
+		}
+		if err := binding.Validator.ValidateStruct(v); err != nil {
+			res[i].Status = InvalidParam
+			res[i].Msg = err.Error()
+			continue
+		}
+		valid = append(valid, i)
+	}
+	return r.Items, res, valid, true
+}
+
+func batchResp(res []*proto.BatchResult) *proto.BatchResp {
+	n := 0
+	for _, v := range res {
+		if v.Status == OK {
+			n++
+		}
+	}
+	return &proto.BatchResp{Success: n, List: res}
+}
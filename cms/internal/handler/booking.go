@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/acl"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+)
+
+func (h *Handler) BookingResourceList(c *gin.Context) {
+	var r proto.ListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateBookingResource(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateBookingResource error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.BookingResource, 0)
+	}
+	c.JSON(OK, &proto.BookingResourceListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+func (h *Handler) BookingResourceCreate(c *gin.Context) {
+	var r proto.BookingResourceArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	data := bookingResourceModel(&r)
+	data.Operator = "admin:" + strconv.Itoa(user.ID)
+	if err := h.service.CreateBookingResource(c, data); err != nil {
+		logger.FromContext(c).Error("service.CreateBookingResource error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, data)
+}
+
+func (h *Handler) BookingResourceUpdate(c *gin.Context) {
+	var r proto.BookingResourceUpdateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	data := bookingResourceModel(&r.BookingResourceArgs)
+	data.ID = r.ID
+	data.Operator = "admin:" + strconv.Itoa(user.ID)
+	if err := h.service.UpdateBookingResource(c, data); err != nil {
+		logger.FromContext(c).Error("service.UpdateBookingResource error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// BookingResourceStatus 停止(0)或开放(1)预约，停止后已有的预约不受影响
+func (h *Handler) BookingResourceStatus(c *gin.Context) {
+	var r proto.BookingResourceStatusArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	if err := h.service.UpdateBookingResourceStatus(c, r.ID, r.Status, "admin:"+strconv.Itoa(user.ID)); err != nil {
+		logger.FromContext(c).Error("service.UpdateBookingResourceStatus error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func (h *Handler) BookingTemplates(c *gin.Context) {
+	var r proto.IDArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	list, err := h.service.FindBookingTemplates(c, r.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindBookingTemplates error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, list)
+}
+
+// BookingTemplateSave 整体替换时段模板，只影响之后的预约
+func (h *Handler) BookingTemplateSave(c *gin.Context) {
+	var r proto.BookingTemplateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	list := make([]*model.BookingTemplate, len(r.Templates))
+	for i, v := range r.Templates {
+		if v.End <= v.Start {
+			c.JSON(RespWithMsg(InvalidParam, "结束时间需晚于开始时间"))
+			return
+		}
+		list[i] = &model.BookingTemplate{Weekday: v.Weekday, Start: v.Start, End: v.End, Capacity: v.Capacity}
+	}
+	if err := h.service.SaveBookingTemplates(c, r.ResourceID, list); err != nil {
+		logger.FromContext(c).Error("service.SaveBookingTemplates error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func (h *Handler) BookingList(c *gin.Context) {
+	var r proto.BookingListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateBooking(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateBooking error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.Booking, 0)
+	}
+	c.JSON(OK, &proto.BookingListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+// BookingCancel 后台取消，不受取消截止时间限制，取消原因推送给用户
+func (h *Handler) BookingCancel(c *gin.Context) {
+	var r proto.BookingCancelArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	if err := h.service.CancelBooking(c, r.ID, r.Reason, "admin:"+strconv.Itoa(user.ID)); err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.CancelBooking error", &r, err)
+		}
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func bookingResourceModel(r *proto.BookingResourceArgs) *model.BookingResource {
+	return &model.BookingResource{
+		Name:         r.Name,
+		Description:  r.Description,
+		Days:         r.Days,
+		HoldMinutes:  r.HoldMinutes,
+		CancelBefore: r.CancelBefore,
+		RemindBefore: r.RemindBefore,
+	}
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"bufio"
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/acl"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (h *Handler) CampaignList(c *gin.Context) {
+	var r proto.ListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateCampaign(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateCampaign error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.Campaign, 0)
+	}
+	c.JSON(OK, &proto.CampaignListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+// CampaignCreate 人群为list时创建后通过campaign/audience上传用户ID，发送时间前可继续追加
+func (h *Handler) CampaignCreate(c *gin.Context) {
+	var r proto.CampaignCreateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if r.SendTime < time.Now().Unix() {
+		c.JSON(RespWithMsg(InvalidParam, "发送时间不能早于当前时间"))
+		return
+	}
+	if r.Rate == 0 {
+		r.Rate = 100
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	data := &model.Campaign{
+		Name:     r.Name,
+		Category: r.Category,
+		Audience: r.Audience,
+		Title:    r.Title,
+		Body:     r.Body,
+		Data:     r.Data,
+		Wechat:   r.Wechat,
+		SendTime: r.SendTime,
+		Rate:     r.Rate,
+		Status:   model.CampaignScheduled,
+		Operator: "admin:" + strconv.Itoa(user.ID),
+	}
+	switch r.Audience {
+	case model.CampaignAudienceSegment:
+		data.Segment = r.Segment
+	case model.CampaignAudienceSaved:
+		data.SegmentID = r.SegmentID
+	}
+	if err := h.service.CreateCampaign(c, data); err != nil {
+		logger.FromContext(c).Error("service.CreateCampaign error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, data)
+}
+
+// CampaignAudience 上传用户ID文件，每行一个或逗号分隔，最大10M
+func (h *Handler) CampaignAudience(c *gin.Context) {
+	var r proto.IDArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	f, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(RespWithMsg(InvalidParam, ""))
+		return
+	}
+	if f.Size > 10<<20 {
+		c.JSON(RespWithMsg(OverSize, "文件最大不能超过10M"))
+		return
+	}
+	campaign, err := h.service.FindCampaign(c, r.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindCampaign error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if campaign.ID == 0 {
+		c.JSON(RespWithMsg(NotFound, "活动不存在"))
+		return
+	}
+	if campaign.Audience != model.CampaignAudienceList || campaign.Status != model.CampaignScheduled {
+		c.JSON(RespWithMsg(Conflict, "仅未开始且按名单发送的活动可上传"))
+		return
+	}
+	file, _ := f.Open()
+	defer file.Close()
+	var uids []int
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		for _, v := range strings.Split(scanner.Text(), ",") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			uid, err := strconv.Atoi(v)
+			if err != nil || uid <= 0 {
+				c.JSON(RespWithMsg(InvalidParam, "第"+strconv.Itoa(line)+"行用户ID无效"))
+				return
+			}
+			uids = append(uids, uid)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		c.JSON(RespWithMsg(InvalidParam, err.Error()))
+		return
+	}
+	added, err := h.service.AddCampaignAudience(c, r.ID, uids)
+	if err != nil {
+		logger.FromContext(c).Error("service.AddCampaignAudience error", r.ID, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, &proto.CampaignAudienceResp{Added: added})
+}
+
+func (h *Handler) CampaignCancel(c *gin.Context) {
+	var r proto.CampaignCancelArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	ok, err := h.service.CancelCampaign(c, r.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.CancelCampaign error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !ok {
+		c.JSON(RespWithMsg(Conflict, "活动已结束或已取消"))
+		return
+	}
+	c.JSON(OK, Empty)
+}
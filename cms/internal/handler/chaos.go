@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+)
+
+func (h *Handler) ChaosRuleList(c *gin.Context) {
+	list, err := h.service.AllChaosRule(c)
+	if err != nil {
+		logger.FromContext(c).Error("service.AllChaosRule error", nil, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, &proto.ChaosRuleListResp{List: list})
+}
+
+func (h *Handler) ChaosRuleSave(c *gin.Context) {
+	var r proto.ChaosRuleSaveArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if r.Latency == 0 && r.Status == 0 && !r.Drop {
+		c.JSON(RespWithMsg(InvalidParam, "延迟、错误码、断开连接至少指定一项"))
+		return
+	}
+	err := h.service.SaveChaosRule(c, &model.ChaosRule{
+		Route:   r.Route,
+		Percent: r.Percent,
+		Latency: r.Latency,
+		Status:  r.Status,
+		Drop:    r.Drop,
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.SaveChaosRule error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func (h *Handler) ChaosRuleDelete(c *gin.Context) {
+	var r proto.ChaosRuleDelArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if err := h.service.DelChaosRule(c, r.Route); err != nil {
+		logger.FromContext(c).Error("service.DelChaosRule error", r.Route, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
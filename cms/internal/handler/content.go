@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/acl"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"regexp"
+	"strconv"
+)
+
+var contentKeyReg = regexp.MustCompile(`^\w{1,32}$`)
+
+func (h *Handler) ContentList(c *gin.Context) {
+	var r proto.ListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateContent(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateContent error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.Content, 0)
+	}
+	c.JSON(OK, &proto.ContentListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+// ContentCreate 创建后为草稿，发布后客户端才能获取
+func (h *Handler) ContentCreate(c *gin.Context) {
+	var r proto.ContentCreateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !contentKeyReg.MatchString(r.Key) {
+		c.JSON(RespWithMsg(InvalidParam, "标识只能包含字母、数字和下划线"))
+		return
+	}
+	ok, err := h.service.CreateContent(c, &model.Content{
+		Key:   r.Key,
+		Name:  r.Name,
+		Draft: r.Draft,
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.CreateContent error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !ok {
+		c.JSON(RespWithMsg(Conflict, "内容标识已存在"))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// ContentUpdate 保存草稿，不影响已发布的内容
+func (h *Handler) ContentUpdate(c *gin.Context) {
+	var r proto.ContentUpdateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if err := h.service.SaveContentDraft(c, r.ID, r.Name, r.Draft); err != nil {
+		logger.FromContext(c).Error("service.SaveContentDraft error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// ContentPublish 发布草稿，api缓存立即失效，cdn缓存异步刷新
+func (h *Handler) ContentPublish(c *gin.Context) {
+	var r proto.ContentPublishArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	version, err := h.service.PublishContent(c, r.ID, "admin:"+strconv.Itoa(user.ID))
+	if err != nil {
+		logger.FromContext(c).Error("service.PublishContent error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if version == 0 {
+		c.JSON(RespWithMsg(NotFound, "内容不存在"))
+		return
+	}
+	c.JSON(OK, &proto.ContentPublishResp{Version: version})
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+)
+
+func (h *Handler) CouponList(c *gin.Context) {
+	var r proto.ListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateCoupon(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateCoupon error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*proto.CouponItem, 0)
+	}
+	c.JSON(OK, &proto.CouponListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+func (h *Handler) CouponCreate(c *gin.Context) {
+	var r proto.CouponCreateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if r.Type == model.CouponPercent && r.Value >= 100 {
+		c.JSON(RespWithMsg(InvalidParam, "折扣比例应为1~99"))
+		return
+	}
+	data := &model.CouponTemplate{
+		Name:        r.Name,
+		Type:        r.Type,
+		Value:       r.Value,
+		Threshold:   r.Threshold,
+		MaxDiscount: r.MaxDiscount,
+		Stock:       r.Stock,
+		PerUser:     r.PerUser,
+		BeginTime:   r.BeginTime,
+		EndTime:     r.EndTime,
+		ValidDays:   r.ValidDays,
+		UseBegin:    r.UseBegin,
+		UseEnd:      r.UseEnd,
+		Status:      model.StatusOn,
+	}
+	if err := h.service.CreateCoupon(c, data); err != nil {
+		logger.FromContext(c).Error("service.CreateCoupon error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, data)
+}
+
+func (h *Handler) CouponStatus(c *gin.Context) {
+	var r proto.SwitchStatusArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if err := h.service.UpdateCouponStatus(c, r.ID, r.Status); err != nil {
+		logger.FromContext(c).Error("service.UpdateCouponStatus error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// CouponIssue 批量发放给指定用户，逐条返回结果，库存不足的条目为409
+func (h *Handler) CouponIssue(c *gin.Context) {
+	var q proto.CouponIssueArgs
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	items, res, valid, ok := bindBatch[*proto.CouponIssueItem](c)
+	if !ok {
+		return
+	}
+	tpl, err := h.service.FindCouponTemplate(c, q.TemplateID)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindCouponTemplate error", &q, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if tpl.ID == 0 {
+		c.JSON(RespWithMsg(NotFound, "优惠券不存在"))
+		return
+	}
+	for _, i := range valid {
+		err = h.service.IssueCoupon(c, tpl, items[i].UserID)
+		if err == nil {
+			continue
+		}
+		if e, ok := err.(*model.BizError); ok {
+			res[i].Status, res[i].Msg = e.Status, e.Msg
+			continue
+		}
+		logger.FromContext(c).Error("service.IssueCoupon error", items[i], err)
+		res[i].Status, res[i].Msg = ServerError, "系统繁忙"
+	}
+	c.JSON(OK, batchResp(res))
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+)
+
+func experimentVariants(items []*proto.ExperimentVariantItem) model.ExperimentVariants {
+	res := make(model.ExperimentVariants, 0, len(items))
+	for _, v := range items {
+		res = append(res, &model.ExperimentVariant{
+			Name:   v.Name,
+			Weight: v.Weight,
+		})
+	}
+	return res
+}
+
+func (h *Handler) ExperimentList(c *gin.Context) {
+	var r proto.ListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateExperiment(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateExperiment error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.Experiment, 0)
+	}
+	c.JSON(OK, &proto.ExperimentListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+func (h *Handler) ExperimentCreate(c *gin.Context) {
+	var r proto.ExperimentCreateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	ok, err := h.service.CreateExperiment(c, &model.Experiment{
+		Key:       r.Key,
+		Name:      r.Name,
+		Traffic:   r.Traffic,
+		Variants:  experimentVariants(r.Variants),
+		BeginTime: r.BeginTime,
+		EndTime:   r.EndTime,
+		Segment:   r.Segment,
+		Status:    model.StatusOn,
+	})
+	if err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.CreateExperiment error", &r, err)
+		}
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !ok {
+		c.JSON(RespWithMsg(Conflict, "实验标识已存在"))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// ExperimentUpdate 修改分组权重或流量会导致部分用户重新分组，进行中的实验应谨慎调整
+func (h *Handler) ExperimentUpdate(c *gin.Context) {
+	var r proto.ExperimentUpdateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	err := h.service.UpdateExperiment(c, &model.Experiment{
+		ID:        r.ID,
+		Name:      r.Name,
+		Traffic:   r.Traffic,
+		Variants:  experimentVariants(r.Variants),
+		BeginTime: r.BeginTime,
+		EndTime:   r.EndTime,
+		Segment:   r.Segment,
+		Status:    r.Status,
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.UpdateExperiment error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func (h *Handler) ExperimentDelete(c *gin.Context) {
+	var r proto.IDArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if err := h.service.DeleteExperiment(c, r.ID); err != nil {
+		logger.FromContext(c).Error("service.DeleteExperiment error", r.ID, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
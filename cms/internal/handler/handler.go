@@ -6,20 +6,30 @@ import (
 	"github.com/gin-gonic/gin"
 	uuid "github.com/satori/go.uuid"
 	"io"
+	"log"
 	"net/http"
 	"project/cms/internal/acl"
 	"project/cms/internal/service"
+	"project/model"
+	"project/pkg/cdn"
 	"project/pkg/coss"
+	"project/pkg/filescan"
 	"project/pkg/logger"
+	"project/pkg/payment"
+	"project/pkg/server"
 	"project/pkg/util/captcha"
+	"project/pkg/wechat"
+	"project/pkg/wxpay"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type Config struct {
-	Cos struct {
+	Server server.Config // 监听地址和TLS
+	Cos    struct {
 		BucketURL  string
 		ServiceURL string
 		SecretID   string
@@ -31,17 +41,38 @@ type Config struct {
 	//	KeySecret  string
 	//	BucketName string
 	//}
-	Cdn     string
+	Cdn     string       // 当前环境的cdn地址，末尾带/
+	CdnAuth *cdn.Options // 私有内容的url签名和旧域名替换
 	Captcha string
+	Scan    *filescan.Config // 上传文件写入cos前的安全扫描，为空只按文件头校验类型
+	Payment payment.Config   // 未配置商户号/应用ID的渠道不可退款
+	Mp      struct {         // 公众号，appid为空不启用菜单和素材管理
+		Appid string
+	}
+	Export struct { // 流式导出
+		Max int64 // 单次导出的条数上限，默认100000
+	}
+	Download struct { // 代理下载对象存储中的文件，为空不开放
+		Prefixes []string // 允许下载的路径前缀，如report/
+	}
+	Media struct { // 视频上传，转码由script的media:transcode执行
+		MaxSize int64 `mapstructure:"maxSize"` // 单个视频的大小上限(M)，默认500，同时受server.readTimeout限制
+	}
 }
 
 type Handler struct {
 	service *service.Service
 	cos     coss.TCOS
 	//oss     coss.AliOSS
-	cdn     string
-	captcha string
-	drawer  *captcha.Drawer
+	cdn       *cdn.CDN
+	captcha   string
+	drawer    *captcha.Drawer
+	payment   payment.Registry
+	mp        wechat.ServerAPI
+	export    int64
+	downloads []string
+	scanner   filescan.Scanner
+	mediaMax  int64
 }
 
 func Initialize(cfg *Config, srv *service.Service) *gin.Engine {
@@ -49,11 +80,38 @@ func Initialize(cfg *Config, srv *service.Service) *gin.Engine {
 		service: srv,
 		cos:     coss.NewTCOS(cfg.Cos.BucketURL, cfg.Cos.ServiceURL, cfg.Cos.SecretID, cfg.Cos.SecretKey),
 		//oss:     coss.NewAliOSS(cfg.Oss.Endpoint, cfg.Oss.KeyID, cfg.Oss.KeySecret, cfg.Oss.BucketName),
-		cdn:     cfg.Cdn,
-		captcha: cfg.Captcha,
-		drawer:  captcha.NewDrawer("docs/fonts/Coloringkids.ttf", "docs/img/bg1.jpeg", ""),
+		captcha:   cfg.Captcha,
+		drawer:    captcha.NewDrawer("docs/fonts/Coloringkids.ttf", "docs/img/bg1.jpeg", ""),
+		export:    cfg.Export.Max,
+		downloads: cfg.Download.Prefixes,
+		mediaMax:  cfg.Media.MaxSize << 20,
+	}
+	if h.export <= 0 {
+		h.export = 100000
+	}
+	if h.mediaMax <= 0 {
+		h.mediaMax = 500 << 20
+	}
+	cdnURL, err := cdn.New(cfg.Cdn, cfg.CdnAuth)
+	if err != nil {
+		log.Fatal("cdn.New error: ", err)
+	}
+	h.cdn = cdnURL
+	pay, err := payment.New(&cfg.Payment, logger.NewClient("payment", 10*time.Second))
+	if err != nil {
+		log.Fatal("payment.New error: ", err)
+	}
+	h.payment = pay
+	scanner, err := filescan.New(cfg.Scan, logger.NewClient("filescan", 30*time.Second))
+	if err != nil {
+		log.Fatal("filescan.New error: ", err)
+	}
+	h.scanner = scanner
+	if cfg.Mp.Appid != "" {
+		h.mp = wechat.NewServerAPI(logger.NewClient("wechat", 30*time.Second), srv.TokenStore(), model.KeyMpToken)
 	}
 	r := gin.New()
+	r.MaxMultipartMemory = cfg.Server.MultipartMemory()
 	h.register(r)
 	return r
 }
@@ -61,10 +119,12 @@ func Initialize(cfg *Config, srv *service.Service) *gin.Engine {
 // alias short for HttpStatusCode
 const (
 	OK                 = http.StatusOK                    //200: 成功
+	NotModified        = http.StatusNotModified           //304: 内容未变更
 	InvalidParam       = http.StatusBadRequest            //400: 参数错误
 	Unauthorized       = http.StatusUnauthorized          //401: 登录失效
 	Forbidden          = http.StatusForbidden             //403: 禁止操作
 	NotFound           = http.StatusNotFound              //404: 目标不存在
+	MethodNotAllowed   = http.StatusMethodNotAllowed      //405: 请求方法错误
 	Conflict           = http.StatusConflict              //409: 数据已存在
 	OverSize           = http.StatusRequestEntityTooLarge //413: 提交内容过大
 	UnsupportedType    = http.StatusUnsupportedMediaType  //415: 错误的文件类型
@@ -78,8 +138,9 @@ const (
 )
 
 type RespErr struct {
-	Msg    string `json:"msg"`
-	Detail string `json:"detail,omitempty"`
+	Msg     string `json:"msg"`
+	Detail  string `json:"detail,omitempty"`
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 var Empty = struct{}{}
@@ -94,19 +155,39 @@ func RespWithErr(err error) (int, *RespErr) {
 	code, msg, detail := ServerError, "系统繁忙", ""
 	e := reflect.TypeOf(err).String()
 	switch e {
-	case "validator.ValidationErrors":
+	case "validator.ValidationErrors", "*listquery.Error":
 		code = InvalidParam
 		msg = "参数错误"
 		detail = err.Error()
+	case "*model.BizError":
+		e := err.(*model.BizError)
+		return e.Status, &RespErr{Msg: e.Msg, Detail: e.Code}
+	case "*wechat.APIError":
+		e := err.(*wechat.APIError)
+		if b, ok := model.WechatErrs[e.Code]; ok {
+			return b.Status, &RespErr{Msg: b.Msg, Detail: b.Code}
+		}
+		code = WrongResponse
+		detail = "WECHAT_" + strconv.Itoa(e.Code)
 	case "proto.RedisError":
 		detail = "REDIS"
 	case "nsq.ErrProtocol":
 		detail = "NSQ"
+	case "kafka.Error", "kafka.WriteErrors":
+		detail = "KAFKA"
 	case "*errors.errorString":
 		detail = "ERRORS"
 	case "*url.Error":
 		code = GatewayTimeout
 		detail = "REQUEST"
+	case "*wxpay.APIError":
+		code = WrongResponse
+		msg = err.(*wxpay.APIError).Message
+		detail = "WXPAY"
+	case "*payment.AlipayError":
+		code = WrongResponse
+		msg = err.(*payment.AlipayError).SubMsg
+		detail = "ALIPAY"
 	default:
 		if strings.HasPrefix(e, "*json.") {
 			code = WrongResponse
@@ -116,6 +197,30 @@ func RespWithErr(err error) (int, *RespErr) {
 	return code, &RespErr{Msg: msg, Detail: detail}
 }
 
+// NoRoute 未匹配的路由，info日志用于发现客户端拼错的路径
+func NoRoute(c *gin.Context) {
+	logger.FromContext(c).Info("route miss", c.Request.Method+" "+c.Request.URL.Path, c.GetHeader("Referer"))
+	msg := "接口不存在"
+	if isEnglish(c) {
+		msg = "Not Found"
+	}
+	c.AbortWithStatusJSON(NotFound, &RespErr{Msg: msg, Detail: "ROUTE", TraceID: c.GetString("trace_id")})
+}
+
+// NoMethod 路由存在但请求方法不匹配，Allow头由gin设置
+func NoMethod(c *gin.Context) {
+	logger.FromContext(c).Info("method miss", c.Request.Method+" "+c.Request.URL.Path, c.GetHeader("Referer"))
+	msg := "不支持的请求方法"
+	if isEnglish(c) {
+		msg = "Method Not Allowed"
+	}
+	c.AbortWithStatusJSON(MethodNotAllowed, &RespErr{Msg: msg, Detail: "METHOD", TraceID: c.GetString("trace_id")})
+}
+
+func isEnglish(c *gin.Context) bool {
+	return strings.HasPrefix(strings.ToLower(c.GetHeader("Accept-Language")), "en")
+}
+
 func Recover(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -142,26 +247,44 @@ func Cors(c *gin.Context) {
 	c.Next()
 }
 
+// unmatchedRoute 未匹配路由的请求统一记为该模板
+const unmatchedRoute = "/*"
+
+// SetContext 写入trace_id，日志v1为method+路由模板，原始路径记录在access日志的path
 func SetContext(c *gin.Context) {
 	tid := c.GetHeader("X-Trace-Id")
 	if tid == "" {
 		tid = base64.RawURLEncoding.EncodeToString(uuid.NewV4().Bytes())
 	}
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
 	c.Set("trace_id", tid)
-	c.Set("v1", c.Request.Method+c.Request.URL.Path)
+	c.Set("v1", c.Request.Method+route)
 	c.Next()
 }
 
 type BodyLogWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	body    *bytes.Buffer
+	discard bool // 见discardBody
 }
 
 func (w *BodyLogWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+	if !w.discard {
+		w.body.Write(b)
+	}
 	return w.ResponseWriter.Write(b)
 }
 
+// discardBody 流式响应和文件下载不缓冲响应体，access日志只记录字节数
+func discardBody(c *gin.Context) {
+	if w, ok := c.Writer.(*BodyLogWriter); ok {
+		w.discard = true
+	}
+}
+
 func AccessLog(c *gin.Context) {
 	begin := time.Now()
 	body, _ := io.ReadAll(c.Request.Body)
@@ -177,17 +300,28 @@ func AccessLog(c *gin.Context) {
 
 	c.Next()
 
+	output := gin.H{
+		"body":   logger.Compress(w.body.Bytes()),
+		"status": w.Status(),
+	}
+	if w.discard {
+		output = gin.H{
+			"bytes":  w.Size(),
+			"status": w.Status(),
+		}
+		if v, ok := c.Get(streamKey); ok {
+			output["rows"] = v.(*JSONStream).Rows()
+		}
+	}
 	logger.FromContext(c).Trace("access",
 		gin.H{
+			"path":      c.Request.URL.Path,
 			"query":     logger.SpreadMaps(c.Request.URL.Query()),
 			"headers":   logger.SpreadMaps(c.Request.Header),
 			"body":      logger.Compress(body),
 			"client_ip": c.ClientIP(),
 		},
-		gin.H{
-			"body":   logger.Compress(w.body.Bytes()),
-			"status": w.Status(),
-		}, begin)
+		output, begin)
 }
 
 func (h *Handler) AuthCheck(module string) gin.HandlerFunc {
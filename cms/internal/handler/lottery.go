@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/acl"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+)
+
+func (h *Handler) LotteryList(c *gin.Context) {
+	var r proto.ListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateLottery(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateLottery error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.Lottery, 0)
+	}
+	c.JSON(OK, &proto.LotteryListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+// LotteryDetail 活动、奖品的剩余库存和中奖数
+func (h *Handler) LotteryDetail(c *gin.Context) {
+	var r proto.IDArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	data, err := h.service.FindLottery(c, r.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindLottery error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if data == nil {
+		c.JSON(RespWithMsg(NotFound, "活动不存在"))
+		return
+	}
+	c.JSON(OK, data)
+}
+
+// LotteryCreate 创建后为下线状态，确认奖品后上线
+func (h *Handler) LotteryCreate(c *gin.Context) {
+	var r proto.LotteryArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	prizes, msg := lotteryPrizes(r.Prizes)
+	if msg != "" {
+		c.JSON(RespWithMsg(InvalidParam, msg))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	data := lotteryModel(&r)
+	data.Prizes = prizes
+	data.Operator = "admin:" + strconv.Itoa(user.ID)
+	if err := h.service.CreateLottery(c, data); err != nil {
+		logger.FromContext(c).Error("service.CreateLottery error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, data)
+}
+
+// LotteryUpdate prizes不为null时整体替换奖品，活动开始后只能修改基本信息
+func (h *Handler) LotteryUpdate(c *gin.Context) {
+	var r proto.LotteryUpdateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	var prizes []*model.LotteryPrize
+	if r.Prizes != nil {
+		var msg string
+		if prizes, msg = lotteryPrizes(r.Prizes); msg != "" {
+			c.JSON(RespWithMsg(InvalidParam, msg))
+			return
+		}
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	data := lotteryModel(&r.LotteryArgs)
+	data.ID = r.ID
+	data.Operator = "admin:" + strconv.Itoa(user.ID)
+	if err := h.service.UpdateLottery(c, data, prizes); err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.UpdateLottery error", &r, err)
+		}
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func (h *Handler) LotteryStatus(c *gin.Context) {
+	var r proto.SwitchStatusArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	if err := h.service.UpdateLotteryStatus(c, r.ID, r.Status, "admin:"+strconv.Itoa(user.ID)); err != nil {
+		logger.FromContext(c).Error("service.UpdateLotteryStatus error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// LotteryDrawList 抽奖记录，按status=1筛选待发放的实物奖品
+func (h *Handler) LotteryDrawList(c *gin.Context) {
+	var r proto.LotteryDrawListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateLotteryDraw(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateLotteryDraw error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.LotteryDraw, 0)
+	}
+	c.JSON(OK, &proto.LotteryDrawListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+// LotteryDeliver 实物奖品确认发放
+func (h *Handler) LotteryDeliver(c *gin.Context) {
+	var r proto.LotteryDeliverArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	if err := h.service.DeliverLotteryDraw(c, r.ID, "admin:"+strconv.Itoa(user.ID)); err != nil {
+		if _, ok := err.(*model.BizError); !ok {
+			logger.FromContext(c).Error("service.DeliverLotteryDraw error", &r, err)
+		}
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func lotteryModel(r *proto.LotteryArgs) *model.Lottery {
+	return &model.Lottery{
+		Name:        r.Name,
+		Description: r.Description,
+		BeginTime:   r.BeginTime,
+		EndTime:     r.EndTime,
+		DailyLimit:  r.DailyLimit,
+		TotalLimit:  r.TotalLimit,
+	}
+}
+
+// lotteryPrizes 校验中奖概率之和和积分奖品的积分数，不通过时返回提示
+func lotteryPrizes(items []*proto.LotteryPrizeItem) ([]*model.LotteryPrize, string) {
+	list := make([]*model.LotteryPrize, len(items))
+	rate := 0
+	for i, v := range items {
+		if v.Type == model.LotteryPrizePoints && v.Value <= 0 {
+			return nil, "积分奖品需设置积分数"
+		}
+		rate += v.Rate
+		list[i] = &model.LotteryPrize{Name: v.Name, Image: v.Image, Type: v.Type, Value: v.Value, Rate: v.Rate, Stock: v.Stock}
+	}
+	if rate > model.LotteryRateBase {
+		return nil, "中奖概率之和不能超过100%"
+	}
+	return list, ""
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	uuid "github.com/satori/go.uuid"
+	"io"
+	"project/cms/internal/acl"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/util/files"
+	"strconv"
+	"time"
+)
+
+// MediaUpload 上传视频并登记为待转码：原始视频不经内存缓冲直接写入cos的media/source/，
+// 由script的media:transcode转为多码率HLS，通过media查询状态和播放地址
+func (h *Handler) MediaUpload(c *gin.Context) {
+	var r proto.MediaUploadArgs
+	if err := c.ShouldBind(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	f, err := c.FormFile("video")
+	if err != nil {
+		c.JSON(RespWithMsg(InvalidParam, ""))
+		return
+	}
+	if f.Size > h.mediaMax {
+		c.JSON(RespWithMsg(OverSize, "视频大小不能超过"+strconv.FormatInt(h.mediaMax>>20, 10)+"M"))
+		return
+	}
+	file, err := f.Open()
+	if err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	defer file.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	ext, ok := files.CheckVideo(head[:n])
+	if !ok {
+		c.JSON(RespWithMsg(UnsupportedType, "无效的视频类型，仅支持mp4/mov/webm格式"))
+		return
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	remotePath := model.MediaSourcePrefix + time.Now().Format("200601") + "/" + uuid.NewV4().String() + ext
+	if err = h.cos.PutObject(c, remotePath, file); err != nil {
+		logger.FromContext(c).Error("cos.PutObject error", remotePath, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	v, _ := c.Get("user")
+	data := &model.Media{
+		Title:    r.Title,
+		Source:   remotePath,
+		Size:     f.Size,
+		Operator: "admin:" + strconv.Itoa(v.(*acl.AdminToken).ID),
+	}
+	if err = h.service.CreateMedia(c, data); err != nil {
+		logger.FromContext(c).Error("service.CreateMedia error", data, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, h.mediaResp(data))
+}
+
+func (h *Handler) MediaList(c *gin.Context) {
+	var r proto.ListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateMedia(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateMedia error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	resp := &proto.MediaListResp{Total: total, List: make([]*proto.MediaResp, len(list))}
+	for i, v := range list {
+		resp.List[i] = h.mediaResp(v)
+	}
+	c.JSON(OK, resp)
+}
+
+// MediaDetail 转码状态，完成后返回播放地址
+func (h *Handler) MediaDetail(c *gin.Context) {
+	var r proto.IDArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	data, err := h.service.FindMedia(c, r.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindMedia error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if data.ID == 0 {
+		c.JSON(RespWithMsg(NotFound, "视频不存在"))
+		return
+	}
+	c.JSON(OK, h.mediaResp(data))
+}
+
+// MediaRetry 转码失败(源文件问题或超过尝试次数)的视频重新排队
+func (h *Handler) MediaRetry(c *gin.Context) {
+	var r proto.MediaRetryArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	ok, err := h.service.RetryMedia(c, r.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.RetryMedia error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !ok {
+		c.JSON(RespWithMsg(Conflict, "只能重试转码失败的视频"))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func (h *Handler) mediaResp(m *model.Media) *proto.MediaResp {
+	resp := &proto.MediaResp{Media: m}
+	if m.Status == model.MediaReady {
+		resp.PlayURL = h.cdn.URL(m.Playlist)
+	}
+	return resp
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/wechat"
+)
+
+// 公众号菜单、素材和图文发布，替代在公众平台后台操作；access_token由script的refresh:token刷新
+
+// mpMaxSize 各类素材的大小上限
+var mpMaxSize = map[string]int64{
+	wechat.MediaImage: 10 << 20,
+	wechat.MediaVoice: 2 << 20,
+	wechat.MediaVideo: 10 << 20,
+	wechat.MediaThumb: 64 << 10,
+}
+
+// mpErr 请求失败或公众号返回错误码时写入响应并返回true，未映射为业务错误的错误码直接返回errmsg给运营
+func mpErr(c *gin.Context, action string, input any, err error) bool {
+	if err == nil {
+		return false
+	}
+	var e *wechat.APIError
+	if !errors.As(err, &e) {
+		logger.FromContext(c).Error(action+" error", input, err)
+		c.JSON(RespWithErr(err))
+		return true
+	}
+	logger.FromContext(c).Warn(action+" fail", input, err)
+	if _, ok := model.WechatErrs[e.Code]; ok {
+		c.JSON(RespWithErr(e))
+	} else {
+		c.JSON(RespWithMsg(WrongResponse, e.Msg))
+	}
+	return true
+}
+
+func (h *Handler) MpMenuGet(c *gin.Context) {
+	resp, err := h.mp.GetMenu(c)
+	if wechat.Code(err) == 46003 { // 菜单不存在
+		c.JSON(OK, &wechat.Menu{Button: []*wechat.MenuButton{}})
+		return
+	}
+	if mpErr(c, "mp.GetMenu", nil, err) {
+		return
+	}
+	c.JSON(OK, resp.Menu)
+}
+
+// MpMenuSave 整体覆盖当前菜单，约5分钟后在客户端生效
+func (h *Handler) MpMenuSave(c *gin.Context) {
+	var r proto.MpMenuArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	_, err := h.mp.CreateMenu(c, &wechat.Menu{Button: r.Button})
+	if mpErr(c, "mp.CreateMenu", &r, err) {
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func (h *Handler) MpMenuDelete(c *gin.Context) {
+	_, err := h.mp.DeleteMenu(c)
+	if mpErr(c, "mp.DeleteMenu", nil, err) {
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// MpMaterialUpload 表单字段media为文件，temporary为true时上传临时素材
+func (h *Handler) MpMaterialUpload(c *gin.Context) {
+	var r proto.MpMaterialArgs
+	if err := c.ShouldBind(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	f, err := c.FormFile("media")
+	if err != nil {
+		c.JSON(RespWithMsg(InvalidParam, ""))
+		return
+	}
+	if f.Size > mpMaxSize[r.Type] {
+		c.JSON(RespWithMsg(OverSize, "文件超出公众号素材的大小限制"))
+		return
+	}
+	file, _ := f.Open()
+	defer file.Close()
+	b, _ := io.ReadAll(file)
+	if r.Temporary {
+		resp, err := h.mp.UploadMedia(c, r.Type, f.Filename, b)
+		if mpErr(c, "mp.UploadMedia", &r, err) {
+			return
+		}
+		c.JSON(OK, &proto.MpMaterialResp{MediaID: resp.MediaID})
+		return
+	}
+	var video *wechat.VideoDesc
+	if r.Type == wechat.MediaVideo {
+		video = &wechat.VideoDesc{Title: r.Title, Introduction: r.Introduction}
+	}
+	resp, err := h.mp.AddMaterial(c, r.Type, f.Filename, b, video)
+	if mpErr(c, "mp.AddMaterial", &r, err) {
+		return
+	}
+	c.JSON(OK, &proto.MpMaterialResp{MediaID: resp.MediaID, URL: resp.URL})
+}
+
+func (h *Handler) MpMaterialList(c *gin.Context) {
+	var r proto.MpMaterialListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	resp, err := h.mp.BatchGetMaterial(c, r.Type, (r.Page-1)*r.Size, r.Size)
+	if mpErr(c, "mp.BatchGetMaterial", &r, err) {
+		return
+	}
+	if resp.Item == nil {
+		resp.Item = []*wechat.MaterialItem{}
+	}
+	c.JSON(OK, &proto.MpMaterialListResp{Total: resp.TotalCount, List: resp.Item})
+}
+
+func (h *Handler) MpMaterialDelete(c *gin.Context) {
+	var r proto.MpMediaArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	_, err := h.mp.DeleteMaterial(c, r.MediaID)
+	if mpErr(c, "mp.DeleteMaterial", &r, err) {
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// MpArticleImage 上传图文正文中的图片，表单字段image，仅支持jpg/png且小于1M
+func (h *Handler) MpArticleImage(c *gin.Context) {
+	f, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(RespWithMsg(InvalidParam, ""))
+		return
+	}
+	if f.Size > 1<<20 {
+		c.JSON(RespWithMsg(OverSize, "图文中的图片限制1M以内"))
+		return
+	}
+	file, _ := f.Open()
+	defer file.Close()
+	b, _ := io.ReadAll(file)
+	resp, err := h.mp.UploadArticleImage(c, f.Filename, b)
+	if mpErr(c, "mp.UploadArticleImage", f.Filename, err) {
+		return
+	}
+	c.JSON(OK, &proto.MpMaterialResp{URL: resp.URL})
+}
+
+// MpDraftCreate 新建图文草稿，返回草稿的media_id用于发布
+func (h *Handler) MpDraftCreate(c *gin.Context) {
+	var r proto.MpDraftArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	resp, err := h.mp.AddDraft(c, r.Articles)
+	if mpErr(c, "mp.AddDraft", &r, err) {
+		return
+	}
+	c.JSON(OK, &proto.MpMaterialResp{MediaID: resp.MediaID})
+}
+
+// MpPublish 提交发布，结果需按publish_id查询
+func (h *Handler) MpPublish(c *gin.Context) {
+	var r proto.MpMediaArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	resp, err := h.mp.SubmitPublish(c, r.MediaID)
+	if mpErr(c, "mp.SubmitPublish", &r, err) {
+		return
+	}
+	c.JSON(OK, &proto.MpPublishResp{PublishID: resp.PublishID})
+}
+
+func (h *Handler) MpPublishStatus(c *gin.Context) {
+	var r proto.MpPublishQuery
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	resp, err := h.mp.GetPublish(c, r.PublishID)
+	if mpErr(c, "mp.GetPublish", &r, err) {
+		return
+	}
+	c.JSON(OK, resp)
+}
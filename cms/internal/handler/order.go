@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/acl"
+	"project/cms/internal/proto"
+	"project/cms/internal/service"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/payment"
+	"project/pkg/wxpay"
+	"strconv"
+	"time"
+)
+
+func (h *Handler) OrderList(c *gin.Context) {
+	spec, err := proto.OrderQuery.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateOrder(c, spec)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateOrder error", c.Request.URL.RawQuery, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.Order, 0)
+	}
+	c.JSON(OK, &proto.OrderListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+// OrderExport 按订单列表的过滤和排序条件导出全部订单，逐行流式输出json数组
+func (h *Handler) OrderExport(c *gin.Context) {
+	spec, err := proto.OrderQuery.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	stream := NewJSONStream(c, "orders-"+time.Now().Format("20060102150405")+".json")
+	err = h.service.ExportOrder(c, spec, h.export, func(v *model.Order) error {
+		return stream.Write(v)
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.ExportOrder error", stream.Rows(), err)
+		if !stream.Started() {
+			c.JSON(RespWithErr(err))
+		}
+		return
+	}
+	_ = stream.Close()
+}
+
+func (h *Handler) OrderRefunds(c *gin.Context) {
+	var r proto.OrderRefundsArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	list, err := h.service.FindRefundsByOrder(c, r.OrderNo)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindRefundsByOrder error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.OrderRefund, 0)
+	}
+	c.JSON(OK, &proto.OrderRefundsResp{List: list})
+}
+
+// OrderRefund 申请退款，先占用可退金额再调用订单的支付渠道，异步渠道的结果以退款回调为准
+func (h *Handler) OrderRefund(c *gin.Context) {
+	var r proto.OrderRefundArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	order, err := h.service.FindOrderByNo(c, r.OrderNo)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindOrderByNo error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if order.ID == 0 {
+		c.JSON(RespWithMsg(NotFound, "订单不存在"))
+		return
+	}
+	p, err := h.payment.Get(order.Provider)
+	if err != nil {
+		c.JSON(RespWithMsg(ServiceUnavailable, "未配置该订单的支付渠道"))
+		return
+	}
+	refund := &model.OrderRefund{
+		RefundNo: service.GenRefundNo(order.OrderNo),
+		OrderNo:  order.OrderNo,
+		Amount:   r.Amount,
+		Reason:   r.Reason,
+		Status:   model.RefundProcessing,
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	ok, err := h.service.CreateRefund(c, order, refund, "admin:"+strconv.Itoa(user.ID))
+	if err != nil {
+		logger.FromContext(c).Error("service.CreateRefund error", refund, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !ok {
+		c.JSON(RespWithMsg(Conflict, "订单状态不可退款或超出可退金额"))
+		return
+	}
+	resp, err := p.Refund(c, &payment.RefundArgs{
+		OrderNo:  order.OrderNo,
+		TradeNo:  order.TransactionID,
+		RefundNo: refund.RefundNo,
+		Amount:   r.Amount,
+		Total:    order.Amount,
+		Reason:   r.Reason,
+	})
+	if err != nil {
+		logger.FromContext(c).Error("payment.Refund error", refund, err)
+		switch err.(type) {
+		case *wxpay.APIError, *payment.AlipayError: // 明确被拒绝时释放金额，网络错误等待回调或对账
+			if e := h.service.FailRefund(c, refund); e != nil {
+				logger.FromContext(c).Error("service.FailRefund error", refund, e)
+			}
+		}
+		c.JSON(RespWithErr(err))
+		return
+	}
+	refund.RefundID = resp.RefundID
+	if resp.State == payment.StateSuccess { // 同步退款的渠道直接完成
+		refund.Status = model.RefundSuccess
+		refund.SuccessTime = time.Now().Unix()
+		err = h.service.SucceedRefund(c, refund)
+	} else {
+		err = h.service.UpdateRefundID(c, refund.RefundNo, resp.RefundID)
+	}
+	if err != nil {
+		logger.FromContext(c).Error("service.UpdateRefund error", resp, err)
+	}
+	c.JSON(OK, refund)
+}
+
+// OrderShip 已支付订单发货
+func (h *Handler) OrderShip(c *gin.Context) {
+	var r proto.OrderShipArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	order, err := h.service.FindOrderByNo(c, r.OrderNo)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindOrderByNo error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if order.ID == 0 {
+		c.JSON(RespWithMsg(NotFound, "订单不存在"))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	ok, err := h.service.ShipOrder(c, order, "admin:"+strconv.Itoa(user.ID), r.Remark)
+	if err != nil {
+		logger.FromContext(c).Error("service.ShipOrder error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !ok {
+		c.JSON(RespWithMsg(Conflict, "订单状态不可发货"))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// OrderLogs 订单状态迁移记录
+func (h *Handler) OrderLogs(c *gin.Context) {
+	var r proto.OrderLogsArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	list, err := h.service.FindOrderLogs(c, r.OrderNo)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindOrderLogs error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.OrderLog, 0)
+	}
+	c.JSON(OK, &proto.OrderLogsResp{List: list})
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/pkg/logger"
+	"project/pkg/util/files"
+	"project/pkg/util/qrcode"
+	"strings"
+)
+
+// QRCode 生成任意内容的二维码，output=url时上传到cdn并按内容hash缓存，否则直接返回png
+func (h *Handler) QRCode(c *gin.Context) {
+	var r proto.QRCodeArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	logo, _ := base64.StdEncoding.DecodeString(r.Logo)
+	hash := qrcodeHash(&r, logo)
+	remotePath := "qrcode/" + hash + ".png"
+	if r.Output == "url" {
+		ok, err := h.service.QRCodeUploaded(c, hash)
+		if err != nil {
+			logger.FromContext(c).Warn("service.QRCodeUploaded fail", hash, err)
+		}
+		if ok {
+			c.JSON(OK, &proto.UploadResp{Host: h.cdn.Domain(), Path: remotePath})
+			return
+		}
+	} else if c.GetHeader("If-None-Match") == `"`+hash+`"` {
+		c.Status(NotModified)
+		return
+	}
+	b, err := qrcode.Encode(r.Content, &qrcode.Options{
+		Size:  r.Size,
+		Level: r.Level,
+		Logo:  logo,
+	})
+	if err == qrcode.ErrLogo {
+		c.JSON(RespWithMsg(UnsupportedType, "无效的logo图片，仅支持jpg/png格式"))
+		return
+	}
+	if err != nil {
+		c.JSON(RespWithMsg(InvalidParam, "内容超出二维码容量"))
+		return
+	}
+	if r.Output != "url" {
+		c.Header("ETag", `"`+hash+`"`)
+		c.Header("Cache-Control", "private, max-age=86400")
+		c.Data(OK, "image/png", b)
+		return
+	}
+	if err = h.cos.PutObject(c, remotePath, bytes.NewReader(b)); err != nil {
+		logger.FromContext(c).Error("cos.PutObject error", remotePath, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if err = h.service.SetQRCodeUploaded(c, hash); err != nil {
+		logger.FromContext(c).Warn("service.SetQRCodeUploaded fail", hash, err)
+	}
+	c.JSON(OK, &proto.UploadResp{Host: h.cdn.Domain(), Path: remotePath})
+}
+
+// qrcodeHash 内容和生成参数的hash，格式与上传文件路径一致
+func qrcodeHash(r *proto.QRCodeArgs, logo []byte) string {
+	hash := sha1.New()
+	hash.Write([]byte(r.Content))
+	_ = binary.Write(hash, binary.BigEndian, int32(r.Size))
+	hash.Write([]byte(r.Level))
+	hash.Write(logo)
+	return strings.Replace(files.GenFilePath(hash.Sum(nil)), "/", "", 1)
+}
@@ -10,9 +10,9 @@ func (h *Handler) register(r *gin.Engine) {
 	r.GET("ping", func(c *gin.Context) {
 		c.String(OK, "pong")
 	})
-	r.NoRoute(func(c *gin.Context) {
-		c.AbortWithStatus(NotFound)
-	})
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(NoRoute)
+	r.NoMethod(NoMethod)
 	r.Use(Recover, SetContext) // 如nginx未添加跨域头，则此处应添加Cors中间件
 
 	if gin.Mode() != gin.ReleaseMode {
@@ -47,12 +47,121 @@ func (h *Handler) register(r *gin.Engine) {
 		admin.PUT("user/password", h.AdminUserPassword)
 		admin.PUT("user/role", h.AdminUserRole)
 		admin.PUT("user/status", h.AdminUserStatus)
+		admin.POST("user/batch", h.AdminUserBatchStatus)
+		admin.GET("chaos", h.ChaosRuleList)
+		admin.PUT("chaos", h.ChaosRuleSave)
+		admin.DELETE("chaos", h.ChaosRuleDelete)
+		admin.GET("app/versions", h.AppVersionList)
+		admin.PUT("app/version", h.AppVersionSave)
+		admin.DELETE("app/version", h.AppVersionDelete)
+		admin.GET("apikeys", h.APIKeyList)
+		admin.POST("apikey", h.APIKeyAdd)
+		admin.PUT("apikey/status", h.APIKeyStatus)
+		admin.GET("apikey/usage", h.APIKeyUsage)
+		admin.GET("stats/overview", h.StatOverview)
+		admin.GET("stats/trend", h.StatTrend)
+		admin.GET("trash/tables", h.TrashTables)
+		admin.GET("trash/list", h.TrashList)
+		admin.PUT("trash/restore", h.TrashRestore)
+	}
+
+	{
+		applet := r.Group("applet", h.AuthCheck(acl.ModuleApplet), AccessLog)
+		applet.GET("experiment/list", h.ExperimentList)
+		applet.POST("experiment", h.ExperimentCreate)
+		applet.PUT("experiment", h.ExperimentUpdate)
+		applet.DELETE("experiment", h.ExperimentDelete)
+		applet.GET("coupon/list", h.CouponList)
+		applet.POST("coupon", h.CouponCreate)
+		applet.PUT("coupon/status", h.CouponStatus)
+		applet.POST("coupon/issue", h.CouponIssue)
+		applet.GET("stock/list", h.StockList)
+		applet.GET("shortlink/list", h.ShortLinkList)
+		applet.POST("shortlink", h.ShortLinkAdd)
+		applet.PUT("shortlink", h.ShortLinkUpdate)
+		applet.PUT("shortlink/status", h.ShortLinkStatus)
+		applet.POST("stock", h.StockAdd)
+		applet.GET("analysis/trend", h.AnalysisTrend)
+		applet.GET("analysis/retain", h.AnalysisRetain)
+		applet.GET("analysis/distribution", h.AnalysisDistribution)
+		applet.GET("campaign/list", h.CampaignList)
+		applet.POST("campaign", h.CampaignCreate)
+		applet.POST("campaign/audience", h.CampaignAudience)
+		applet.PUT("campaign/cancel", h.CampaignCancel)
+		applet.GET("segment/list", h.SegmentList)
+		applet.POST("segment", h.SegmentCreate)
+		applet.PUT("segment", h.SegmentUpdate)
+		applet.POST("segment/preview", h.SegmentPreview)
+		applet.GET("user/status/list", h.UserStatusList)
+		applet.PUT("user/status", h.UserStatusSet)
+		applet.GET("user/auth/events", h.AuthEventList)
+		applet.GET("content/list", h.ContentList)
+		applet.POST("content", h.ContentCreate)
+		applet.PUT("content", h.ContentUpdate)
+		applet.PUT("content/publish", h.ContentPublish)
+		applet.GET("store/list", h.StoreList)
+		applet.POST("store", h.StoreCreate)
+		applet.PUT("store", h.StoreUpdate)
+		applet.PUT("store/status", h.StoreStatus)
+		applet.GET("media/list", h.MediaList)
+		applet.GET("media", h.MediaDetail)
+		applet.PUT("media/retry", h.MediaRetry)
+		applet.GET("booking/resource/list", h.BookingResourceList)
+		applet.POST("booking/resource", h.BookingResourceCreate)
+		applet.PUT("booking/resource", h.BookingResourceUpdate)
+		applet.PUT("booking/resource/status", h.BookingResourceStatus)
+		applet.GET("booking/templates", h.BookingTemplates)
+		applet.PUT("booking/templates", h.BookingTemplateSave)
+		applet.GET("booking/list", h.BookingList)
+		applet.PUT("booking/cancel", h.BookingCancel)
+		applet.GET("lottery/list", h.LotteryList)
+		applet.GET("lottery", h.LotteryDetail)
+		applet.POST("lottery", h.LotteryCreate)
+		applet.PUT("lottery", h.LotteryUpdate)
+		applet.PUT("lottery/status", h.LotteryStatus)
+		applet.GET("lottery/draws", h.LotteryDrawList)
+		applet.PUT("lottery/draw/deliver", h.LotteryDeliver)
+		appletUpload := r.Group("applet", h.AuthCheck(acl.ModuleApplet)) // 视频不经过access日志缓冲请求体
+		appletUpload.POST("media/video", h.MediaUpload)
+	}
+
+	{
+		order := r.Group("order", h.AuthCheck(acl.ModuleOrder), AccessLog)
+		order.GET("list", h.OrderList)
+		order.GET("export", h.OrderExport)
+		order.GET("refunds", h.OrderRefunds)
+		order.POST("refund", h.OrderRefund)
+		order.POST("ship", h.OrderShip)
+		order.GET("logs", h.OrderLogs)
+		order.GET("sagas/stuck", h.SagaStuck)
+	}
+
+	if h.mp != nil {
+		mpUpload := r.Group("mp", h.AuthCheck(acl.ModuleMp)) // 上传文件不记录请求体
+		mpUpload.POST("material", h.MpMaterialUpload)
+		mpUpload.POST("article/image", h.MpArticleImage)
+		mp := r.Group("mp", h.AuthCheck(acl.ModuleMp), AccessLog)
+		mp.GET("menu", h.MpMenuGet)
+		mp.PUT("menu", h.MpMenuSave)
+		mp.DELETE("menu", h.MpMenuDelete)
+		mp.GET("material/list", h.MpMaterialList)
+		mp.DELETE("material", h.MpMaterialDelete)
+		mp.POST("draft", h.MpDraftCreate)
+		mp.PUT("publish", h.MpPublish)
+		mp.GET("publish", h.MpPublishStatus)
 	}
 
 	{
 		upload := r.Group("upload", h.AuthCheck(""))
 		upload.POST("file", h.UploadFile)
 		upload.POST("image", h.UploadImage)
+		upload.POST("qrcode", h.QRCode)
+	}
+
+	if len(h.downloads) > 0 {
+		download := r.Group("download", h.AuthCheck(""), AccessLog)
+		download.GET("*path", h.Download)
+		download.HEAD("*path", h.Download)
 	}
 
 }
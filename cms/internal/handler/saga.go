@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/pkg/logger"
+	"project/pkg/saga"
+	"time"
+)
+
+// SagaStuck 执行中或补偿中超过idle分钟未更新的业务流程
+func (h *Handler) SagaStuck(c *gin.Context) {
+	var r proto.SagaStuckArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if r.Idle == 0 {
+		r.Idle = 10
+	}
+	list, err := h.service.FindStuckSagas(c, r.Name, time.Duration(r.Idle)*time.Minute)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindStuckSagas error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*saga.Record, 0)
+	}
+	c.JSON(OK, &proto.SagaStuckResp{List: list})
+}
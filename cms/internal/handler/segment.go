@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/segment"
+)
+
+func (h *Handler) SegmentList(c *gin.Context) {
+	var r proto.ListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateSegment(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateSegment error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.Segment, 0)
+	}
+	c.JSON(OK, &proto.SegmentListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+// SegmentCreate 创建后在下次定时计算时生成成员
+func (h *Handler) SegmentCreate(c *gin.Context) {
+	var r proto.SegmentCreateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if _, _, err := model.SegmentEngine.Compile(r.Rule); err != nil {
+		c.JSON(RespWithMsg(InvalidParam, err.Error()))
+		return
+	}
+	ok, err := h.service.CreateSegment(c, &model.Segment{
+		Code:   r.Code,
+		Name:   r.Name,
+		Rule:   r.Rule,
+		Status: model.StatusOn,
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.CreateSegment error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !ok {
+		c.JSON(RespWithMsg(Conflict, "分群标识已存在"))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func (h *Handler) SegmentUpdate(c *gin.Context) {
+	var r proto.SegmentUpdateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if _, _, err := model.SegmentEngine.Compile(r.Rule); err != nil {
+		c.JSON(RespWithMsg(InvalidParam, err.Error()))
+		return
+	}
+	err := h.service.UpdateSegment(c, &model.Segment{
+		ID:     r.ID,
+		Name:   r.Name,
+		Rule:   r.Rule,
+		Status: r.Status,
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.UpdateSegment error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// SegmentPreview 保存前预估规则命中的人数
+func (h *Handler) SegmentPreview(c *gin.Context) {
+	var r proto.SegmentPreviewArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	n, err := h.service.CountSegment(c, r.Rule)
+	if errors.Is(err, segment.ErrRule) {
+		c.JSON(RespWithMsg(InvalidParam, err.Error()))
+		return
+	}
+	if err != nil {
+		logger.FromContext(c).Error("service.CountSegment error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, &proto.SegmentPreviewResp{Size: n})
+}
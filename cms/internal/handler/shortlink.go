@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/shortlink"
+	"strings"
+)
+
+func (h *Handler) ShortLinkList(c *gin.Context) {
+	var r proto.ShortLinkListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateShortLink(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateShortLink error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*shortlink.Link, 0)
+	}
+	c.JSON(OK, &proto.ShortLinkListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+func (h *Handler) ShortLinkAdd(c *gin.Context) {
+	var r proto.ShortLinkArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !validLinkTarget(r.Type, r.Target) {
+		c.JSON(RespWithMsg(InvalidParam, "网页地址需以http(s)://开头，小程序路径不能以/开头"))
+		return
+	}
+	data := &shortlink.Link{
+		Code:       r.Code,
+		Type:       r.Type,
+		Target:     r.Target,
+		Query:      r.Query,
+		ExpireTime: r.ExpireTime,
+		Status:     model.StatusOn,
+		Remark:     r.Remark,
+	}
+	err := h.service.CreateShortLink(c, data)
+	if err == shortlink.ErrCode {
+		c.JSON(RespWithMsg(Conflict, "短码已存在"))
+		return
+	}
+	if err != nil {
+		logger.FromContext(c).Error("service.CreateShortLink error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, data)
+}
+
+// ShortLinkUpdate 修改跳转目标和有效期，类型和短码不可修改
+func (h *Handler) ShortLinkUpdate(c *gin.Context) {
+	var r proto.ShortLinkUpdateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	h.updateShortLink(c, &r, r.ID, map[string]any{
+		"target":      r.Target,
+		"query":       r.Query,
+		"expire_time": r.ExpireTime,
+		"remark":      r.Remark,
+	})
+}
+
+func (h *Handler) ShortLinkStatus(c *gin.Context) {
+	var r proto.SwitchStatusArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	h.updateShortLink(c, &r, r.ID, map[string]any{"status": r.Status})
+}
+
+func (h *Handler) updateShortLink(c *gin.Context, r any, id int, values map[string]any) {
+	err := h.service.UpdateShortLink(c, id, values)
+	if err == shortlink.ErrNotFound {
+		c.JSON(RespWithMsg(NotFound, "短链不存在"))
+		return
+	}
+	if err != nil {
+		logger.FromContext(c).Error("service.UpdateShortLink error", r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func validLinkTarget(typ, target string) bool {
+	if typ == shortlink.TypeMiniapp {
+		return !strings.HasPrefix(target, "/") && !strings.Contains(target, "?")
+	}
+	return strings.HasPrefix(target, "https://") || strings.HasPrefix(target, "http://")
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+	"time"
+)
+
+// 统计看板数据由script的cronjob每10分钟汇总到stat_daily，此处只读汇总表
+const statMaxDays = 366
+
+func (h *Handler) StatOverview(c *gin.Context) {
+	now := time.Now()
+	today, _ := strconv.Atoi(now.Format("20060102"))
+	yesterday, _ := strconv.Atoi(now.AddDate(0, 0, -1).Format("20060102"))
+	list, err := h.service.FindStatDaily(c, yesterday, today)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindStatDaily error", today, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	resp := &proto.StatOverviewResp{
+		Today:     &model.StatDaily{Date: today},
+		Yesterday: &model.StatDaily{Date: yesterday},
+	}
+	for _, v := range list {
+		if v.Date == today {
+			resp.Today = v
+		} else {
+			resp.Yesterday = v
+		}
+	}
+	c.JSON(OK, resp)
+}
+
+func (h *Handler) StatTrend(c *gin.Context) {
+	var r proto.StatTrendArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	begin, err1 := time.Parse("20060102", strconv.Itoa(r.Begin))
+	end, err2 := time.Parse("20060102", strconv.Itoa(r.End))
+	if err1 != nil || err2 != nil || end.Sub(begin) > statMaxDays*24*time.Hour {
+		c.JSON(RespWithMsg(InvalidParam, "日期范围无效"))
+		return
+	}
+	list, err := h.service.FindStatDaily(c, r.Begin, r.End)
+	if err != nil {
+		logger.FromContext(c).Error("service.FindStatDaily error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.StatDaily, 0)
+	}
+	c.JSON(OK, &proto.StatTrendResp{List: list})
+}
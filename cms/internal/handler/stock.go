@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/pkg/logger"
+)
+
+func (h *Handler) StockList(c *gin.Context) {
+	var r proto.ListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateStock(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateStock error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*proto.StockItem, 0)
+	}
+	c.JSON(OK, &proto.StockListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+func (h *Handler) StockAdd(c *gin.Context) {
+	var r proto.StockAddArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	ok, err := h.service.AddStock(c, r.SkuID, r.Delta)
+	if err != nil {
+		logger.FromContext(c).Error("service.AddStock error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !ok {
+		c.JSON(RespWithMsg(Conflict, "可售库存不足以减少"))
+		return
+	}
+	c.JSON(OK, Empty)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/acl"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+)
+
+func (h *Handler) StoreList(c *gin.Context) {
+	var r proto.StoreListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateStore(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateStore error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.Store, 0)
+	}
+	c.JSON(OK, &proto.StoreListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+// StoreCreate 创建后为营业状态，立即出现在附近搜索中
+func (h *Handler) StoreCreate(c *gin.Context) {
+	var r proto.StoreArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	data := storeModel(&r)
+	data.Operator = "admin:" + strconv.Itoa(user.ID)
+	if err := h.service.CreateStore(c, data); err != nil {
+		logger.FromContext(c).Error("service.CreateStore error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, data)
+}
+
+func (h *Handler) StoreUpdate(c *gin.Context) {
+	var r proto.StoreUpdateArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	data := storeModel(&r.StoreArgs)
+	data.ID = r.ID
+	data.Operator = "admin:" + strconv.Itoa(user.ID)
+	if err := h.service.UpdateStore(c, data); err != nil {
+		logger.FromContext(c).Error("service.UpdateStore error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+// StoreStatus 停业(0)或恢复营业(1)
+func (h *Handler) StoreStatus(c *gin.Context) {
+	var r proto.StoreStatusArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	if err := h.service.UpdateStoreStatus(c, r.ID, r.Status, "admin:"+strconv.Itoa(user.ID)); err != nil {
+		logger.FromContext(c).Error("service.UpdateStoreStatus error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
+
+func storeModel(r *proto.StoreArgs) *model.Store {
+	return &model.Store{
+		Name:    r.Name,
+		City:    r.City,
+		Address: r.Address,
+		Phone:   r.Phone,
+		Hours:   r.Hours,
+		Lng:     r.Lng,
+		Lat:     r.Lat,
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/url"
+	"project/pkg/json"
+)
+
+// 大列表流式响应：逐个编码json数组元素直接写出并定期flush，不在内存中持有整个列表，用于10万行级别的导出
+// AccessLog不缓冲流式响应体，只记录行数和字节数(见discardBody)；写出第一个元素后出错时数组不闭合，客户端按json解析失败处理
+
+const (
+	streamFlushRows = 500 // 每写出多少行flush一次
+	streamKey       = "stream"
+)
+
+type JSONStream struct {
+	c        *gin.Context
+	filename string
+	rows     int
+	started  bool
+}
+
+// NewJSONStream filename不为空时按附件下载；写出第一个元素前仍可按普通响应返回错误
+func NewJSONStream(c *gin.Context, filename string) *JSONStream {
+	return &JSONStream{c: c, filename: filename}
+}
+
+// start 写出响应头，之后的响应体不再经过access日志缓冲
+func (s *JSONStream) start() {
+	s.started = true
+	discardBody(s.c)
+	s.c.Set(streamKey, s)
+	s.c.Header("Content-Type", "application/json; charset=utf-8")
+	if s.filename != "" {
+		s.c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(s.filename))
+	}
+	s.c.Status(OK)
+}
+
+// Write 写出一个元素，客户端断开时返回写入错误，调用方应停止读取游标
+func (s *JSONStream) Write(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sep := []byte{','}
+	if !s.started {
+		s.start()
+		sep[0] = '['
+	}
+	if _, err = s.c.Writer.Write(sep); err != nil {
+		return err
+	}
+	if _, err = s.c.Writer.Write(b); err != nil {
+		return err
+	}
+	s.rows++
+	if s.rows%streamFlushRows == 0 {
+		s.c.Writer.Flush()
+	}
+	return nil
+}
+
+// Rows 已写出的行数
+func (s *JSONStream) Rows() int {
+	return s.rows
+}
+
+// Started 已写出响应头，之后不能再返回错误响应
+func (s *JSONStream) Started() bool {
+	return s.started
+}
+
+// Close 闭合数组，没有元素时写出[]
+func (s *JSONStream) Close() error {
+	end := []byte("]")
+	if !s.started {
+		s.start()
+		end = []byte("[]")
+	}
+	_, err := s.c.Writer.Write(end)
+	return err
+}
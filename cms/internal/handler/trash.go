@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"sort"
+)
+
+func (h *Handler) TrashTables(c *gin.Context) {
+	list := make([]*proto.TrashTableItem, 0, len(model.SoftDeleteTables))
+	for k, v := range model.SoftDeleteTables {
+		list = append(list, &proto.TrashTableItem{Table: k, Name: v})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Table < list[j].Table
+	})
+	c.JSON(OK, &proto.TrashTableResp{List: list})
+}
+
+func (h *Handler) TrashList(c *gin.Context) {
+	var r proto.TrashListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if _, ok := model.SoftDeleteTables[r.Table]; !ok {
+		c.JSON(RespWithMsg(InvalidParam, "不支持的数据表"))
+		return
+	}
+	total, list, err := h.service.PaginateTrash(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateTrash error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]map[string]any, 0)
+	}
+	c.JSON(OK, &proto.TrashListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+func (h *Handler) TrashRestore(c *gin.Context) {
+	var r proto.TrashRestoreArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if _, ok := model.SoftDeleteTables[r.Table]; !ok {
+		c.JSON(RespWithMsg(InvalidParam, "不支持的数据表"))
+		return
+	}
+	ok, err := h.service.RestoreTrash(c, r.Table, r.ID)
+	if err != nil {
+		logger.FromContext(c).Error("service.RestoreTrash error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if !ok {
+		c.JSON(RespWithMsg(NotFound, "记录不存在或未删除"))
+		return
+	}
+	c.JSON(OK, Empty)
+}
@@ -2,12 +2,17 @@ package handler
 
 import (
 	"bytes"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"io"
+	"mime/multipart"
 	"path"
 	"project/cms/internal/proto"
+	"project/pkg/download"
+	"project/pkg/filescan"
 	"project/pkg/logger"
 	"project/pkg/util/files"
+	"strings"
 )
 
 func (h *Handler) UploadFile(c *gin.Context) {
@@ -23,7 +28,11 @@ func (h *Handler) UploadFile(c *gin.Context) {
 	file, _ := f.Open()
 	defer file.Close()
 	b, _ := io.ReadAll(file)
-	remotePath := "file/" + files.GenFilePath(b) + path.Ext(f.Filename)
+	ext, ok := h.checkUpload(c, f, b)
+	if !ok {
+		return
+	}
+	remotePath := "file/" + files.GenFilePath(b) + ext
 	err = h.cos.PutObject(c, remotePath, bytes.NewReader(b))
 	if err != nil {
 		logger.FromContext(c).Error("cos.PutObject error", nil, err)
@@ -31,7 +40,7 @@ func (h *Handler) UploadFile(c *gin.Context) {
 		return
 	}
 	c.JSON(OK, &proto.UploadResp{
-		Host: h.cdn,
+		Host: h.cdn.Domain(),
 		Path: remotePath,
 	})
 }
@@ -54,6 +63,9 @@ func (h *Handler) UploadImage(c *gin.Context) {
 		c.JSON(RespWithMsg(UnsupportedType, "无效的图片类型，仅支持jpg/png/gif格式"))
 		return
 	}
+	if _, ok = h.checkUpload(c, f, b); !ok {
+		return
+	}
 	remotePath := "img/" + files.GenFilePath(b) + "." + ext
 	err = h.cos.PutObject(c, remotePath, bytes.NewReader(b))
 	if err != nil {
@@ -62,7 +74,76 @@ func (h *Handler) UploadImage(c *gin.Context) {
 		return
 	}
 	c.JSON(OK, &proto.UploadResp{
-		Host: h.cdn,
+		Host: h.cdn.Domain(),
 		Path: remotePath,
 	})
 }
+
+// checkUpload 按文件头识别类型(不信任客户端声明的类型和扩展名)并做安全扫描，不通过时写入响应；返回保存使用的扩展名
+func (h *Handler) checkUpload(c *gin.Context, f *multipart.FileHeader, b []byte) (string, bool) {
+	ext, contentType, ok := files.Sniff(b, f.Filename, f.Header.Get("Content-Type"))
+	if !ok {
+		logger.FromContext(c).Warn("upload type mismatch", f.Filename+" "+f.Header.Get("Content-Type"), contentType)
+		c.JSON(RespWithMsg(UnsupportedType, "文件类型与内容不符或不允许上传"))
+		return "", false
+	}
+	if h.scanner == nil {
+		return ext, true
+	}
+	err := h.scanner.Scan(c, f.Filename, b)
+	var e *filescan.Infected
+	if errors.As(err, &e) {
+		logger.FromContext(c).Warn("upload infected", f.Filename, e.Signature)
+		c.JSON(RespWithMsg(Unprocessable, "文件未通过安全检查"))
+		return "", false
+	}
+	if err != nil {
+		logger.FromContext(c).Error("scanner.Scan error", f.Filename, err)
+		c.JSON(RespWithMsg(ServiceUnavailable, "文件安全检查暂不可用，请稍后再试"))
+		return "", false
+	}
+	return ext, true
+}
+
+// Download 代理下载对象存储中的报表和私有媒体，支持Range断点续传；只允许handler.download.prefixes下的路径
+// ?name=指定保存的文件名，?inline=1在浏览器中直接打开
+func (h *Handler) Download(c *gin.Context) {
+	name := path.Clean(strings.TrimPrefix(c.Param("path"), "/"))
+	if !h.downloadable(name) {
+		c.JSON(RespWithMsg(NotFound, "文件不存在"))
+		return
+	}
+	discardBody(c)
+	_, err := download.Serve(c.Writer, c.Request, h.cos, name, &download.Options{
+		Filename: c.Query("name"),
+		Inline:   c.Query("inline") == "1",
+	})
+	if err == nil {
+		return
+	}
+	if c.Writer.Written() {
+		if !download.IsClientGone(c.Request, err) {
+			logger.FromContext(c).Error("download.Serve interrupted", name, err)
+		}
+		c.Abort()
+		return
+	}
+	if errors.Is(err, download.ErrNotFound) {
+		c.JSON(RespWithMsg(NotFound, "文件不存在"))
+		return
+	}
+	logger.FromContext(c).Error("download.Serve error", name, err)
+	c.JSON(RespWithErr(err))
+}
+
+func (h *Handler) downloadable(name string) bool {
+	if name == "." || strings.HasPrefix(name, "../") {
+		return false
+	}
+	for _, p := range h.downloads {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
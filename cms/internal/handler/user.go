@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"project/cms/internal/acl"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+	"time"
+)
+
+func (h *Handler) UserStatusList(c *gin.Context) {
+	var r proto.ListArgs
+	if err := c.ShouldBindQuery(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	total, list, err := h.service.PaginateUserStatus(c, &r)
+	if err != nil {
+		logger.FromContext(c).Error("service.PaginateUserStatus error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if list == nil {
+		list = make([]*model.UserStatus, 0)
+	}
+	c.JSON(OK, &proto.UserStatusListResp{
+		Total: total,
+		List:  list,
+	})
+}
+
+// UserStatusSet 设置禁言、冻结、封禁或恢复正常，api最迟在缓存删除后的下一个请求生效
+func (h *Handler) UserStatusSet(c *gin.Context) {
+	var r proto.UserStatusArgs
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(RespWithErr(err))
+		return
+	}
+	if r.ExpireTime > 0 && r.ExpireTime <= time.Now().Unix() {
+		c.JSON(RespWithMsg(InvalidParam, "解除时间需晚于当前时间"))
+		return
+	}
+	v, _ := c.Get("user")
+	user := v.(*acl.AdminToken)
+	err := h.service.SetUserStatus(c, &model.UserStatus{
+		UserID:     r.UserID,
+		Status:     r.Status,
+		Reason:     r.Reason,
+		ExpireTime: r.ExpireTime,
+		Operator:   "admin:" + strconv.Itoa(user.ID),
+	})
+	if err != nil {
+		logger.FromContext(c).Error("service.SetUserStatus error", &r, err)
+		c.JSON(RespWithErr(err))
+		return
+	}
+	c.JSON(OK, Empty)
+}
@@ -0,0 +1,24 @@
+package proto
+
+import "project/model"
+
+type AnalysisRangeArgs struct {
+	BeginDate string `form:"begin_date" binding:"len=8,numeric"` // yyyymmdd
+	EndDate   string `form:"end_date" binding:"len=8,numeric,gtefield=BeginDate"`
+}
+
+type AnalysisTrendResp struct {
+	List []*model.WechatAnalysis `json:"list"`
+}
+
+type AnalysisRetainResp struct {
+	List []*model.WechatRetain `json:"list"`
+}
+
+type AnalysisDistributionArgs struct {
+	RefDate string `form:"ref_date" binding:"len=8,numeric"`
+}
+
+type AnalysisDistributionResp struct {
+	List []*model.WechatDistribution `json:"list"`
+}
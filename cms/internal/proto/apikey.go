@@ -0,0 +1,22 @@
+package proto
+
+import "project/model"
+
+type APIKeyListResp struct {
+	List []*model.APIKey `json:"list"`
+}
+
+type APIKeyAddArgs struct {
+	Name       string `json:"name" binding:"required,max=50"`
+	DailyQuota int64  `json:"daily_quota" binding:"min=0"`
+}
+
+type APIKeyUsageArgs struct {
+	ID    int `form:"id" binding:"min=1"`
+	Begin int `form:"begin" binding:"min=20000101,max=30000101"` // 20060102
+	End   int `form:"end" binding:"gtefield=Begin,max=30000101"`
+}
+
+type APIKeyUsageResp struct {
+	List []*model.APIUsage `json:"list"`
+}
@@ -0,0 +1,17 @@
+package proto
+
+import "project/model"
+
+type AppVersionListResp struct {
+	List []*model.AppVersion `json:"list"`
+}
+
+type AppVersionSaveArgs struct {
+	Platform string `json:"platform" binding:"oneof=ios android"`
+	Min      string `json:"min" binding:"required,max=20"`
+	URL      string `json:"url" binding:"required,url,max=300"`
+}
+
+type AppVersionDelArgs struct {
+	Platform string `form:"platform" binding:"oneof=ios android"`
+}
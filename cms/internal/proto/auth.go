@@ -0,0 +1,16 @@
+package proto
+
+import "project/model"
+
+// AuthEventArgs 按用户或IP查询登录凭证事件，before为上一页最后一条的event_time
+type AuthEventArgs struct {
+	UserID int    `form:"user_id" binding:"min=0"`
+	IP     string `form:"ip" binding:"omitempty,ip"`
+	Type   string `form:"type" binding:"max=20"`
+	Before int64  `form:"before" binding:"min=0"`
+	Size   int    `form:"size" binding:"min=10,max=100"`
+}
+
+type AuthEventResp struct {
+	List []*model.AuthEvent `json:"list"`
+}
@@ -0,0 +1,58 @@
+package proto
+
+import "project/model"
+
+type BookingResourceListResp struct {
+	Total int64                    `json:"total"`
+	List  []*model.BookingResource `json:"list"`
+}
+
+type BookingResourceArgs struct {
+	Name         string `json:"name" binding:"required,max=50"`
+	Description  string `json:"description" binding:"max=500"`
+	Days         int    `json:"days" binding:"min=1,max=90"`
+	HoldMinutes  int    `json:"hold_minutes" binding:"min=1,max=120"`
+	CancelBefore int    `json:"cancel_before" binding:"min=0,max=10080"` // 分钟
+	RemindBefore int    `json:"remind_before" binding:"min=0,max=10080"`
+}
+
+type BookingResourceUpdateArgs struct {
+	ID int `json:"id" binding:"min=1"`
+	BookingResourceArgs
+}
+
+type BookingResourceStatusArgs struct {
+	ID     int  `json:"id" binding:"min=1"`
+	Status int8 `json:"status" binding:"oneof=0 1"`
+}
+
+// BookingTemplateArgs 整体替换资源的时段模板，已有的预约不受影响
+type BookingTemplateArgs struct {
+	ResourceID int                    `json:"resource_id" binding:"min=1"`
+	Templates  []*BookingTemplateItem `json:"templates" binding:"max=500,dive"`
+}
+
+type BookingTemplateItem struct {
+	Weekday  int8   `json:"weekday" binding:"min=0,max=6"` // 0为周日
+	Start    string `json:"start" binding:"datetime=15:04"`
+	End      string `json:"end" binding:"datetime=15:04"` // 晚于start，不跨零点
+	Capacity int    `json:"capacity" binding:"min=1,max=10000"`
+}
+
+type BookingListArgs struct {
+	ListArgs
+	ResourceID int    `form:"resource_id" binding:"min=0"`
+	UserID     int    `form:"user_id" binding:"min=0"`
+	Status     *int8  `form:"status" binding:"omitempty,min=-2,max=1"`
+	Date       string `form:"date" binding:"omitempty,datetime=2006-01-02"` // 按开始时间筛选当天
+}
+
+type BookingListResp struct {
+	Total int64            `json:"total"`
+	List  []*model.Booking `json:"list"`
+}
+
+type BookingCancelArgs struct {
+	ID     int    `json:"id" binding:"min=1"`
+	Reason string `json:"reason" binding:"required,max=200"` // 推送给用户
+}
@@ -0,0 +1,30 @@
+package proto
+
+import "project/model"
+
+type CampaignCreateArgs struct {
+	Name      string                 `json:"name" binding:"required,max=50"`
+	Category  string                 `json:"category" binding:"oneof=marketing reminder"`
+	Audience  string                 `json:"audience" binding:"oneof=segment list saved"`
+	Segment   *model.CampaignSegment `json:"segment" binding:"required_if=Audience segment"`
+	SegmentID int                    `json:"segment_id" binding:"required_if=Audience saved"`
+	Title     string                 `json:"title" binding:"required,max=50"`
+	Body      string                 `json:"body" binding:"required,max=200"`
+	Data      map[string]string      `json:"data"`
+	Wechat    *model.PushWechat      `json:"wechat"`
+	SendTime  int64                  `json:"send_time" binding:"min=1"`
+	Rate      int                    `json:"rate" binding:"min=0,max=5000"` // 每秒投递数，默认100
+}
+
+type CampaignCancelArgs struct {
+	ID int `json:"id" binding:"min=1"`
+}
+
+type CampaignListResp struct {
+	Total int64             `json:"total"`
+	List  []*model.Campaign `json:"list"`
+}
+
+type CampaignAudienceResp struct {
+	Added int64 `json:"added"`
+}
@@ -0,0 +1,19 @@
+package proto
+
+import "project/model"
+
+type ChaosRuleListResp struct {
+	List []*model.ChaosRule `json:"list"`
+}
+
+type ChaosRuleSaveArgs struct {
+	Route   string `json:"route" binding:"required,max=100"`
+	Percent int    `json:"percent" binding:"min=1,max=100"`
+	Latency int    `json:"latency" binding:"min=0,max=60000"`
+	Status  int    `json:"status" binding:"omitempty,min=400,max=599"`
+	Drop    bool   `json:"drop"`
+}
+
+type ChaosRuleDelArgs struct {
+	Route string `form:"route" binding:"required"`
+}
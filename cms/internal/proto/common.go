@@ -23,3 +23,31 @@ type UploadResp struct {
 	Host string `json:"host"`
 	Path string `json:"path"`
 }
+
+type IDArgs struct {
+	ID int `form:"id" binding:"min=1"`
+}
+
+// BatchArgs 批量操作，items为同一类型的操作，逐条校验
+type BatchArgs[T any] struct {
+	Items []T `json:"items" binding:"required,min=1,max=100"`
+}
+
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Status int    `json:"status"` // 与单条接口的http状态码一致
+	Msg    string `json:"msg,omitempty"`
+}
+
+type BatchResp struct {
+	Success int            `json:"success"`
+	List    []*BatchResult `json:"list"`
+}
+
+type QRCodeArgs struct {
+	Content string `json:"content" binding:"required,max=1000"`
+	Size    int    `json:"size" binding:"omitempty,min=128,max=1024"`
+	Level   string `json:"level" binding:"omitempty,oneof=L M Q H"`
+	Logo    string `json:"logo" binding:"omitempty,base64,max=140000"` // base64编码的png/jpg，约100KB以内
+	Output  string `json:"output" binding:"omitempty,oneof=png url"`   // 默认返回png图片
+}
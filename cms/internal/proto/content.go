@@ -0,0 +1,31 @@
+package proto
+
+import (
+	"encoding/json"
+	"project/model"
+)
+
+type ContentListResp struct {
+	Total int64            `json:"total"`
+	List  []*model.Content `json:"list"`
+}
+
+type ContentCreateArgs struct {
+	Key   string          `json:"key" binding:"required,max=32"` // 字母、数字、下划线
+	Name  string          `json:"name" binding:"required,max=50"`
+	Draft json.RawMessage `json:"draft" binding:"required"`
+}
+
+type ContentUpdateArgs struct {
+	ID    int             `json:"id" binding:"min=1"`
+	Name  string          `json:"name" binding:"required,max=50"`
+	Draft json.RawMessage `json:"draft" binding:"required"`
+}
+
+type ContentPublishArgs struct {
+	ID int `json:"id" binding:"min=1"`
+}
+
+type ContentPublishResp struct {
+	Version int `json:"version"`
+}
@@ -0,0 +1,38 @@
+package proto
+
+import "project/model"
+
+type CouponCreateArgs struct {
+	Name        string `json:"name" binding:"required,max=50"`
+	Type        int8   `json:"type" binding:"oneof=1 2"`
+	Value       int64  `json:"value" binding:"min=1"`
+	Threshold   int64  `json:"threshold" binding:"min=0"`
+	MaxDiscount int64  `json:"max_discount" binding:"min=0"`
+	Stock       int    `json:"stock" binding:"min=1,max=10000000"`
+	PerUser     int    `json:"per_user" binding:"min=0"`
+	BeginTime   int64  `json:"begin_time" binding:"min=1"`
+	EndTime     int64  `json:"end_time" binding:"gtfield=BeginTime"`
+	ValidDays   int    `json:"valid_days" binding:"min=0,max=3650"`
+	UseBegin    int64  `json:"use_begin" binding:"required_without=ValidDays"`
+	UseEnd      int64  `json:"use_end" binding:"required_without=ValidDays,omitempty,gtfield=UseBegin"`
+}
+
+type CouponItem struct {
+	*model.CouponTemplate
+	Remain  int64 `json:"remain"`  // 剩余库存
+	Claimed int64 `json:"claimed"` // 已领取
+	Used    int64 `json:"used"`    // 已核销
+}
+
+type CouponListResp struct {
+	Total int64         `json:"total"`
+	List  []*CouponItem `json:"list"`
+}
+
+type CouponIssueItem struct {
+	UserID int `json:"user_id" binding:"min=1"`
+}
+
+type CouponIssueArgs struct {
+	TemplateID int `form:"template_id" binding:"min=1"`
+}
@@ -0,0 +1,34 @@
+package proto
+
+import "project/model"
+
+type ExperimentListResp struct {
+	Total int64               `json:"total"`
+	List  []*model.Experiment `json:"list"`
+}
+
+type ExperimentVariantItem struct {
+	Name   string `json:"name" binding:"required,max=20"`
+	Weight int    `json:"weight" binding:"min=1,max=10000"`
+}
+
+type ExperimentCreateArgs struct {
+	Key       string                   `json:"key" binding:"required,max=32"`
+	Name      string                   `json:"name" binding:"required,max=50"`
+	Traffic   int                      `json:"traffic" binding:"min=1,max=10000"`
+	Variants  []*ExperimentVariantItem `json:"variants" binding:"min=2,dive"`
+	BeginTime int64                    `json:"begin_time" binding:"min=0"`
+	EndTime   int64                    `json:"end_time" binding:"omitempty,gtfield=BeginTime"`
+	Segment   string                   `json:"segment" binding:"max=32"` // 只有该分群的用户进入实验
+}
+
+type ExperimentUpdateArgs struct {
+	ID        int                      `json:"id" binding:"min=1"`
+	Name      string                   `json:"name" binding:"required,max=50"`
+	Traffic   int                      `json:"traffic" binding:"min=1,max=10000"`
+	Variants  []*ExperimentVariantItem `json:"variants" binding:"min=2,dive"`
+	BeginTime int64                    `json:"begin_time" binding:"min=0"`
+	EndTime   int64                    `json:"end_time" binding:"omitempty,gtfield=BeginTime"`
+	Segment   string                   `json:"segment" binding:"max=32"` // 只有该分群的用户进入实验
+	Status    int8                     `json:"status" binding:"eq=-1|eq=1"`
+}
@@ -0,0 +1,63 @@
+package proto
+
+import "project/model"
+
+type LotteryListResp struct {
+	Total int64            `json:"total"`
+	List  []*model.Lottery `json:"list"`
+}
+
+type LotteryArgs struct {
+	Name        string              `json:"name" binding:"required,max=50"`
+	Description string              `json:"description" binding:"max=1000"`
+	BeginTime   int64               `json:"begin_time" binding:"min=1"`
+	EndTime     int64               `json:"end_time" binding:"gtfield=BeginTime"`
+	DailyLimit  int                 `json:"daily_limit" binding:"min=0,max=1000"`
+	TotalLimit  int                 `json:"total_limit" binding:"min=0,max=100000"`
+	Prizes      []*LotteryPrizeItem `json:"prizes" binding:"max=20,dive"` // 中奖概率之和不超过10000
+}
+
+// LotteryUpdateArgs prizes为null时不修改奖品，活动开始后不可修改奖品
+type LotteryUpdateArgs struct {
+	ID int `json:"id" binding:"min=1"`
+	LotteryArgs
+}
+
+type LotteryPrizeItem struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Image string `json:"image" binding:"max=255"`
+	Type  int8   `json:"type" binding:"oneof=1 2"`
+	Value int64  `json:"value" binding:"min=0"` // 积分奖品的积分数
+	Rate  int    `json:"rate" binding:"min=1,max=10000"`
+	Stock int    `json:"stock" binding:"min=0,max=10000000"`
+}
+
+// LotteryDetail 奖品的剩余库存和已中奖数
+type LotteryDetail struct {
+	*model.Lottery
+	Prizes []*LotteryPrizeStat `json:"prizes"`
+	Draws  int64               `json:"draws"` // 总抽奖次数
+	Users  int64               `json:"users"` // 参与人数
+}
+
+type LotteryPrizeStat struct {
+	*model.LotteryPrize
+	Remain int64 `json:"remain"`
+	Won    int64 `json:"won"`
+}
+
+type LotteryDrawListArgs struct {
+	ListArgs
+	LotteryID int   `form:"lottery_id" binding:"min=0"`
+	UserID    int   `form:"user_id" binding:"min=0"`
+	Status    *int8 `form:"status" binding:"omitempty,min=0,max=2"`
+}
+
+type LotteryDrawListResp struct {
+	Total int64                `json:"total"`
+	List  []*model.LotteryDraw `json:"list"`
+}
+
+type LotteryDeliverArgs struct {
+	ID int `json:"id" binding:"min=1"` // 抽奖记录ID
+}
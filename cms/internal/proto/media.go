@@ -0,0 +1,22 @@
+package proto
+
+import "project/model"
+
+type MediaUploadArgs struct {
+	Title string `form:"title" binding:"max=100"`
+}
+
+type MediaRetryArgs struct {
+	ID int `json:"id" binding:"min=1"`
+}
+
+// MediaResp 转码完成后play_url为HLS主播放列表的cdn地址
+type MediaResp struct {
+	*model.Media
+	PlayURL string `json:"play_url"`
+}
+
+type MediaListResp struct {
+	Total int64        `json:"total"`
+	List  []*MediaResp `json:"list"`
+}
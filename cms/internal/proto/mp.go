@@ -0,0 +1,46 @@
+package proto
+
+import "project/pkg/wechat"
+
+type MpMenuArgs struct {
+	Button []*wechat.MenuButton `json:"button" binding:"required,min=1,max=3"`
+}
+
+type MpMaterialArgs struct {
+	Type         string `form:"type" binding:"required,oneof=image voice video thumb"`
+	Temporary    bool   `form:"temporary"`                                     // 临时素材3天有效
+	Title        string `form:"title" binding:"required_if=Type video,max=64"` // 永久视频素材必填
+	Introduction string `form:"introduction" binding:"max=120"`
+}
+
+type MpMaterialResp struct {
+	MediaID string `json:"media_id"`
+	URL     string `json:"url,omitempty"`
+}
+
+type MpMaterialListArgs struct {
+	Type string `form:"type" binding:"required,oneof=image voice video"`
+	Page int    `form:"page" binding:"min=1"`
+	Size int    `form:"size" binding:"min=1,max=20"`
+}
+
+type MpMaterialListResp struct {
+	Total int                    `json:"total"`
+	List  []*wechat.MaterialItem `json:"list"`
+}
+
+type MpMediaArgs struct {
+	MediaID string `json:"media_id" binding:"required,max=128"`
+}
+
+type MpDraftArgs struct {
+	Articles []*wechat.Article `json:"articles" binding:"required,min=1,max=8"`
+}
+
+type MpPublishResp struct {
+	PublishID string `json:"publish_id"`
+}
+
+type MpPublishQuery struct {
+	PublishID string `form:"publish_id" binding:"required,max=64"`
+}
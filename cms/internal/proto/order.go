@@ -0,0 +1,62 @@
+package proto
+
+import (
+	"project/model"
+	"project/pkg/listquery"
+)
+
+// OrderQuery 订单列表可用的过滤和排序，如 ?filter[status][in]=paid,shipped&filter[create_time][gte]=2026-01-01&sort=-amount
+var OrderQuery = &listquery.Schema{
+	Fields: []*listquery.Field{
+		{Name: "id", Sort: true},
+		{Name: "order_no", Ops: []string{listquery.OpEq}},
+		{Name: "user_id", Type: listquery.TypeInt, Ops: []string{listquery.OpEq}},
+		{Name: "provider", Ops: []string{listquery.OpEq, listquery.OpIn}},
+		{Name: "status", Ops: []string{listquery.OpEq, listquery.OpNe, listquery.OpIn}, Values: map[string]any{
+			"closed":    model.OrderClosed,
+			"created":   model.OrderCreated,
+			"paid":      model.OrderPaid,
+			"refunding": model.OrderRefunding,
+			"refunded":  model.OrderRefunded,
+			"shipped":   model.OrderShipped,
+			"completed": model.OrderCompleted,
+			"paid_late": model.OrderPaidLate,
+		}},
+		{Name: "amount", Type: listquery.TypeInt, Sort: true, Ops: []string{listquery.OpGte, listquery.OpLte}},
+		{Name: "pay_time", Type: listquery.TypeUnix, Sort: true, Ops: []string{listquery.OpGte, listquery.OpLt}},
+		{Name: "create_time", Type: listquery.TypeTime, Sort: true, Ops: []string{listquery.OpGte, listquery.OpLt}},
+	},
+	Sort: "-id",
+}
+
+type OrderListResp struct {
+	Total int64          `json:"total"`
+	List  []*model.Order `json:"list"`
+}
+
+type OrderRefundArgs struct {
+	OrderNo string `json:"order_no" binding:"required,max=32"`
+	Amount  int64  `json:"amount" binding:"min=1"` // 单位分
+	Reason  string `json:"reason" binding:"max=80"`
+}
+
+type OrderRefundsArgs struct {
+	OrderNo string `form:"order_no" binding:"required"`
+}
+
+type OrderRefundsResp struct {
+	List []*model.OrderRefund `json:"list"`
+}
+
+type OrderShipArgs struct {
+	OrderNo string `json:"order_no" binding:"required,max=32"`
+	Remark  string `json:"remark" binding:"max=100"` // 物流公司及单号
+}
+
+type OrderLogsArgs struct {
+	OrderNo string `form:"order_no" binding:"required"`
+}
+
+type OrderLogsResp struct {
+	List []*model.OrderLog `json:"list"`
+}
@@ -0,0 +1,12 @@
+package proto
+
+import "project/pkg/saga"
+
+type SagaStuckArgs struct {
+	Name string `form:"name"`
+	Idle int    `form:"idle" binding:"min=0"` // 超过多少分钟未更新，默认10
+}
+
+type SagaStuckResp struct {
+	List []*saga.Record `json:"list"`
+}
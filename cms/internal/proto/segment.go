@@ -0,0 +1,32 @@
+package proto
+
+import (
+	"project/model"
+	"project/pkg/segment"
+)
+
+type SegmentCreateArgs struct {
+	Code string        `json:"code" binding:"required,max=32,alphanum"`
+	Name string        `json:"name" binding:"required,max=50"`
+	Rule *segment.Rule `json:"rule" binding:"required"`
+}
+
+type SegmentUpdateArgs struct {
+	ID     int           `json:"id" binding:"min=1"`
+	Name   string        `json:"name" binding:"required,max=50"`
+	Rule   *segment.Rule `json:"rule" binding:"required"`
+	Status int8          `json:"status" binding:"eq=-1|eq=1"`
+}
+
+type SegmentPreviewArgs struct {
+	Rule *segment.Rule `json:"rule" binding:"required"`
+}
+
+type SegmentPreviewResp struct {
+	Size int64 `json:"size"`
+}
+
+type SegmentListResp struct {
+	Total int64            `json:"total"`
+	List  []*model.Segment `json:"list"`
+}
@@ -0,0 +1,30 @@
+package proto
+
+import "project/pkg/shortlink"
+
+type ShortLinkListArgs struct {
+	ListArgs
+	Code string `form:"code"`
+}
+
+type ShortLinkListResp struct {
+	Total int64             `json:"total"`
+	List  []*shortlink.Link `json:"list"`
+}
+
+type ShortLinkArgs struct {
+	Code       string `json:"code" binding:"omitempty,alphanum,min=4,max=16"` // 为空随机生成
+	Type       string `json:"type" binding:"oneof=url miniapp"`
+	Target     string `json:"target" binding:"required,max=500"`
+	Query      string `json:"query" binding:"max=200"`
+	ExpireTime int64  `json:"expire_time" binding:"min=0"`
+	Remark     string `json:"remark" binding:"max=100"`
+}
+
+type ShortLinkUpdateArgs struct {
+	ID         int    `json:"id" binding:"min=1"`
+	Target     string `json:"target" binding:"required,max=500"`
+	Query      string `json:"query" binding:"max=200"`
+	ExpireTime int64  `json:"expire_time" binding:"min=0"`
+	Remark     string `json:"remark" binding:"max=100"`
+}
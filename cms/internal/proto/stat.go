@@ -0,0 +1,18 @@
+package proto
+
+import "project/model"
+
+type StatTrendArgs struct {
+	Begin int `form:"begin" binding:"min=20000101,max=30000101"` // 20060102
+	End   int `form:"end" binding:"gtefield=Begin,max=30000101"`
+}
+
+type StatTrendResp struct {
+	List []*model.StatDaily `json:"list"`
+}
+
+// StatOverviewResp 尚未汇总的日期各项为0
+type StatOverviewResp struct {
+	Today     *model.StatDaily `json:"today"`
+	Yesterday *model.StatDaily `json:"yesterday"`
+}
@@ -0,0 +1,18 @@
+package proto
+
+import "project/model"
+
+type StockItem struct {
+	*model.Stock
+	Remain int64 `json:"remain"` // redis可售库存，未初始化为-1
+}
+
+type StockListResp struct {
+	Total int64        `json:"total"`
+	List  []*StockItem `json:"list"`
+}
+
+type StockAddArgs struct {
+	SkuID int `json:"sku_id" binding:"min=1"`
+	Delta int `json:"delta" binding:"required,min=-1000000,max=1000000"` // 正数补货，负数减少
+}
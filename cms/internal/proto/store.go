@@ -0,0 +1,33 @@
+package proto
+
+import "project/model"
+
+type StoreListArgs struct {
+	ListArgs
+	City string `form:"city" binding:"max=20"`
+}
+
+type StoreListResp struct {
+	Total int64          `json:"total"`
+	List  []*model.Store `json:"list"`
+}
+
+type StoreArgs struct {
+	Name    string  `json:"name" binding:"required,max=50"`
+	City    string  `json:"city" binding:"required,max=20"`
+	Address string  `json:"address" binding:"max=150"`
+	Phone   string  `json:"phone" binding:"max=20"`
+	Hours   string  `json:"hours" binding:"max=50"`
+	Lng     float64 `json:"lng" binding:"min=-180,max=180"` // gcj02
+	Lat     float64 `json:"lat" binding:"min=-85,max=85"`
+}
+
+type StoreUpdateArgs struct {
+	ID int `json:"id" binding:"min=1"`
+	StoreArgs
+}
+
+type StoreStatusArgs struct {
+	ID     int  `json:"id" binding:"min=1"`
+	Status int8 `json:"status" binding:"oneof=0 1"`
+}
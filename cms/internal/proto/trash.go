@@ -0,0 +1,26 @@
+package proto
+
+type TrashListArgs struct {
+	Page  int    `form:"page" binding:"min=1"`
+	Size  int    `form:"size" binding:"min=10,max=100"`
+	Table string `form:"table" binding:"required"`
+}
+
+type TrashListResp struct {
+	Total int64            `json:"total"`
+	List  []map[string]any `json:"list"`
+}
+
+type TrashRestoreArgs struct {
+	Table string `json:"table" binding:"required"`
+	ID    int    `json:"id" binding:"min=1"`
+}
+
+type TrashTableResp struct {
+	List []*TrashTableItem `json:"list"`
+}
+
+type TrashTableItem struct {
+	Table string `json:"table"`
+	Name  string `json:"name"`
+}
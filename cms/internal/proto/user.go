@@ -0,0 +1,15 @@
+package proto
+
+import "project/model"
+
+type UserStatusArgs struct {
+	UserID     int    `json:"user_id" binding:"min=1"`
+	Status     int8   `json:"status" binding:"min=0,max=3"` // 0恢复正常 1禁言 2冻结 3封禁
+	Reason     string `json:"reason" binding:"max=100"`
+	ExpireTime int64  `json:"expire_time" binding:"min=0"` // 解除时间戳，0为永久
+}
+
+type UserStatusListResp struct {
+	Total int64               `json:"total"`
+	List  []*model.UserStatus `json:"list"`
+}
@@ -180,3 +180,31 @@ func (s *Service) CreateAdminUser(ctx context.Context, data *acl.AdminUser) (boo
 	opt := s.mysql.WithContext(ctx).FirstOrCreate(data, "username = ?", data.Username)
 	return opt.RowsAffected > 0, opt.Error
 }
+
+func (s *Service) FindAdminUsersByIDs(ctx context.Context, ids []int) (map[int]*acl.AdminUser, error) {
+	var list []*acl.AdminUser
+	err := s.mysql.WithContext(ctx).Where("id IN ? AND username <> ?", ids, acl.Super).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int]*acl.AdminUser, len(list))
+	for _, v := range list {
+		res[v.ID] = v
+	}
+	return res, nil
+}
+
+// BatchUpdateAdminUserStatus 同一状态的账号一次更新，禁用的账号强制退出登录
+func (s *Service) BatchUpdateAdminUserStatus(ctx context.Context, status int8, ids []int) error {
+	err := s.mysql.WithContext(ctx).Model(&acl.AdminUser{}).Where("id IN ?", ids).
+		Update("status", status).Error
+	if err != nil || status != model.StatusOff {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.LogoutAdminUser(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"project/cms/internal/proto"
+	"project/model"
+)
+
+func (s *Service) FindWechatAnalysis(ctx context.Context, p *proto.AnalysisRangeArgs) (list []*model.WechatAnalysis, err error) {
+	err = s.mysql.WithContext(ctx).Where("ref_date BETWEEN ? AND ?", p.BeginDate, p.EndDate).
+		Order("ref_date").Find(&list).Error
+	return
+}
+
+func (s *Service) FindWechatRetain(ctx context.Context, p *proto.AnalysisRangeArgs) (list []*model.WechatRetain, err error) {
+	err = s.mysql.WithContext(ctx).Where("ref_date BETWEEN ? AND ?", p.BeginDate, p.EndDate).
+		Order("ref_date, day").Find(&list).Error
+	return
+}
+
+func (s *Service) FindWechatDistribution(ctx context.Context, refDate string) (list []*model.WechatDistribution, err error) {
+	err = s.mysql.WithContext(ctx).Where("ref_date = ?", refDate).
+		Order("`index`, `key`").Find(&list).Error
+	return
+}
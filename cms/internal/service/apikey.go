@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"project/model"
+	"project/pkg/util/random"
+)
+
+func (s *Service) AllAPIKeys(ctx context.Context) (list []*model.APIKey, err error) {
+	err = s.mysql.WithContext(ctx).Order("id DESC").Find(&list).Error
+	return
+}
+
+// CreateAPIKey 生成32位随机key，api服务30秒内生效
+func (s *Service) CreateAPIKey(ctx context.Context, data *model.APIKey) error {
+	data.Key = random.Chars(32)
+	data.Status = model.StatusOn
+	return s.mysql.WithContext(ctx).Create(data).Error
+}
+
+func (s *Service) UpdateAPIKeyStatus(ctx context.Context, id int, status int8) error {
+	return s.mysql.WithContext(ctx).Model(&model.APIKey{}).Where("id = ?", id).
+		Update("status", status).Error
+}
+
+func (s *Service) FindAPIUsage(ctx context.Context, subject string, begin, end int) (list []*model.APIUsage, err error) {
+	err = s.mysql.WithContext(ctx).
+		Where("subject = ? AND date >= ? AND date <= ?", subject, begin, end).
+		Order("date DESC, route").Find(&list).Error
+	return
+}
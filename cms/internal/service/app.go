@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"project/model"
+	"sort"
+)
+
+func (s *Service) AllAppVersion(ctx context.Context) ([]*model.AppVersion, error) {
+	data, err := s.redis.HGetAll(ctx, model.KeyAppVersions).Result()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*model.AppVersion, 0, len(data))
+	for _, v := range data {
+		var r model.AppVersion
+		if json.Unmarshal([]byte(v), &r) == nil {
+			list = append(list, &r)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Platform < list[j].Platform
+	})
+	return list, nil
+}
+
+func (s *Service) SaveAppVersion(ctx context.Context, data *model.AppVersion) error {
+	b, _ := json.Marshal(data)
+	return s.redis.HSet(ctx, model.KeyAppVersions, data.Platform, b).Err()
+}
+
+func (s *Service) DelAppVersion(ctx context.Context, platform string) error {
+	return s.redis.HDel(ctx, model.KeyAppVersions, platform).Err()
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/db"
+)
+
+// FindAuthEvents 指定用户时只查询其所在分片，按IP查询时合并全部分片的结果，按event_time倒序
+func (s *Service) FindAuthEvents(ctx context.Context, p *proto.AuthEventArgs) ([]*model.AuthEvent, error) {
+	query := func(tx *gorm.DB) *gorm.DB {
+		if p.UserID > 0 {
+			tx = tx.Where("user_id = ?", p.UserID)
+		}
+		if p.IP != "" {
+			tx = tx.Where("ip = ?", p.IP)
+		}
+		if p.Type != "" {
+			tx = tx.Where("type = ?", p.Type)
+		}
+		if p.Before > 0 {
+			tx = tx.Where("event_time < ?", p.Before)
+		}
+		return tx.Order("event_time DESC").Limit(p.Size)
+	}
+	if p.UserID > 0 {
+		var list []*model.AuthEvent
+		err := query(s.shards.Read(p.UserID).WithContext(ctx)).Find(&list).Error
+		return list, err
+	}
+	return db.Gather(ctx, s.shards, query, func(a, b *model.AuthEvent) bool {
+		return a.EventTime > b.EventTime
+	}, p.Size)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"gorm.io/gorm"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"strconv"
+	"time"
+)
+
+func (s *Service) PaginateBookingResource(ctx context.Context,
+	p *proto.ListArgs) (total int64, list []*model.BookingResource, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.BookingResource{})
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+// CreateBookingResource 创建后为开放状态，配置时段模板后才有可预约的时段
+func (s *Service) CreateBookingResource(ctx context.Context, data *model.BookingResource) error {
+	data.Status = model.BookingResourceOpen
+	return s.mysql.WithContext(ctx).Create(data).Error
+}
+
+// UpdateBookingResource 修改后的保留时长、取消和提醒规则对之后的预约和操作生效
+func (s *Service) UpdateBookingResource(ctx context.Context, data *model.BookingResource) error {
+	return s.mysql.WithContext(ctx).Select("name", "description", "days", "hold_minutes", "cancel_before",
+		"remind_before", "operator").Updates(data).Error
+}
+
+func (s *Service) UpdateBookingResourceStatus(ctx context.Context, id int, status int8, operator string) error {
+	return s.mysql.WithContext(ctx).Model(&model.BookingResource{ID: id}).
+		Updates(map[string]any{"status": status, "operator": operator}).Error
+}
+
+func (s *Service) FindBookingTemplates(ctx context.Context, resourceID int) ([]*model.BookingTemplate, error) {
+	list := make([]*model.BookingTemplate, 0)
+	err := s.mysql.WithContext(ctx).Where("resource_id = ?", resourceID).Order("weekday, start").Find(&list).Error
+	return list, err
+}
+
+// SaveBookingTemplates 整体替换资源的时段模板
+func (s *Service) SaveBookingTemplates(ctx context.Context, resourceID int, list []*model.BookingTemplate) error {
+	return s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("resource_id = ?", resourceID).Delete(&model.BookingTemplate{}).Error; err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return nil
+		}
+		for _, v := range list {
+			v.ID, v.ResourceID = 0, resourceID
+		}
+		return tx.Create(list).Error
+	})
+}
+
+func (s *Service) PaginateBooking(ctx context.Context,
+	p *proto.BookingListArgs) (total int64, list []*model.Booking, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.Booking{})
+	if p.ResourceID > 0 {
+		query = query.Where("resource_id = ?", p.ResourceID)
+	}
+	if p.UserID > 0 {
+		query = query.Where("user_id = ?", p.UserID)
+	}
+	if p.Status != nil {
+		query = query.Where("status = ?", *p.Status)
+	}
+	if p.Date != "" {
+		day, _ := time.ParseInLocation("2006-01-02", p.Date, time.Local)
+		query = query.Where("slot_start >= ? AND slot_start < ?", day, day.AddDate(0, 0, 1))
+	}
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	now := time.Now()
+	for _, v := range list {
+		v.Status = v.Effective(now)
+	}
+	return
+}
+
+// CancelBooking 后台取消保留中或已确认的预约，不受取消截止时间限制；释放名额后推送通知用户
+func (s *Service) CancelBooking(ctx context.Context, id int, reason, operator string) error {
+	var b model.Booking
+	err := s.mysql.WithContext(ctx).Take(&b, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return model.ErrBookingNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if st := b.Effective(time.Now()); st != model.BookingHeld && st != model.BookingConfirmed {
+		return model.ErrBookingStatus
+	}
+	opt := s.mysql.WithContext(ctx).Model(&b).Where("status = ?", b.Status).Updates(map[string]any{
+		"status":        model.BookingCancelled,
+		"cancel_reason": reason,
+		"operator":      operator,
+	})
+	if opt.Error != nil {
+		return opt.Error
+	}
+	if opt.RowsAffected == 0 {
+		return model.ErrBookingStatus
+	}
+	key := model.BookingSlotKey(b.ResourceID, b.SlotStart.Unix())
+	if err = model.ScriptBookingRelease.Run(ctx, s.redis, []string{key}, b.UserID, b.SlotScore()).Err(); err != nil {
+		logger.FromContext(ctx).Error("redis.ScriptBookingRelease error", key, err)
+	}
+	body, _ := json.Marshal(&model.MsgPush{
+		MsgID:    model.BookingMsgID(b.ID, "cancel"),
+		UserID:   b.UserID,
+		Category: model.PushCategoryTransactional,
+		Title:    "预约已取消",
+		Body:     "您" + b.SlotStart.Format("1月2日 15:04") + "的预约已取消：" + reason,
+		Data:     map[string]string{"booking_id": strconv.Itoa(b.ID)},
+	})
+	if err = s.producer.Publish(model.TopicPush, body); err != nil {
+		logger.FromContext(ctx).Warn("producer.Publish fail", b.ID, err)
+	}
+	return nil
+}
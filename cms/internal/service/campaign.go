@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/cms/internal/proto"
+	"project/model"
+)
+
+func (s *Service) PaginateCampaign(ctx context.Context,
+	p *proto.ListArgs) (total int64, list []*model.Campaign, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.Campaign{})
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+func (s *Service) CreateCampaign(ctx context.Context, data *model.Campaign) error {
+	return s.mysql.WithContext(ctx).Create(data).Error
+}
+
+func (s *Service) FindCampaign(ctx context.Context, id int) (*model.Campaign, error) {
+	var data model.Campaign
+	err := s.mysql.WithContext(ctx).Take(&data, "id = ?", id).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// AddCampaignAudience 分批写入上传的用户ID，重复的忽略，返回新增数量
+func (s *Service) AddCampaignAudience(ctx context.Context, id int, uids []int) (int64, error) {
+	var total int64
+	for i := 0; i < len(uids); i += 1000 {
+		end := i + 1000
+		if end > len(uids) {
+			end = len(uids)
+		}
+		list := make([]*model.CampaignAudience, 0, end-i)
+		for _, uid := range uids[i:end] {
+			list = append(list, &model.CampaignAudience{CampaignID: id, UserID: uid})
+		}
+		opt := s.mysql.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&list)
+		if opt.Error != nil {
+			return total, opt.Error
+		}
+		total += opt.RowsAffected
+	}
+	return total, nil
+}
+
+// CancelCampaign 未开始或发送中的活动可取消，发送中的在当前批次结束后停止
+func (s *Service) CancelCampaign(ctx context.Context, id int) (bool, error) {
+	opt := s.mysql.WithContext(ctx).Model(&model.Campaign{}).
+		Where("id = ? AND status IN ?", id, []int8{model.CampaignScheduled, model.CampaignRunning}).
+		Update("status", model.CampaignCanceled)
+	return opt.RowsAffected > 0, opt.Error
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"project/pkg/logger"
+)
+
+// purgeURL 内容变更后异步刷新cdn缓存，失败不影响业务操作
+func (s *Service) purgeURL(ctx context.Context, urls ...string) {
+	if s.purger == nil {
+		return
+	}
+	if err := s.purger.Purge(ctx, urls...); err != nil {
+		logger.FromContext(ctx).Warn("purger.Purge fail", urls, err)
+	}
+}
+
+// purgeTag 刷新源站响应头Cache-Tag为tag的缓存
+func (s *Service) purgeTag(ctx context.Context, tag string) {
+	if s.purger == nil {
+		return
+	}
+	if err := s.purger.PurgeByTag(ctx, tag); err != nil {
+		logger.FromContext(ctx).Warn("purger.PurgeByTag fail", tag, err)
+	}
+}
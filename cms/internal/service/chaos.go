@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"project/model"
+	"sort"
+)
+
+func (s *Service) AllChaosRule(ctx context.Context) ([]*model.ChaosRule, error) {
+	data, err := s.redis.HGetAll(ctx, model.KeyChaosRules).Result()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*model.ChaosRule, 0, len(data))
+	for _, v := range data {
+		var r model.ChaosRule
+		if json.Unmarshal([]byte(v), &r) == nil {
+			list = append(list, &r)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Route < list[j].Route
+	})
+	return list, nil
+}
+
+func (s *Service) SaveChaosRule(ctx context.Context, data *model.ChaosRule) error {
+	b, _ := json.Marshal(data)
+	return s.redis.HSet(ctx, model.KeyChaosRules, data.Route, b).Err()
+}
+
+func (s *Service) DelChaosRule(ctx context.Context, route string) error {
+	return s.redis.HDel(ctx, model.KeyChaosRules, route).Err()
+}
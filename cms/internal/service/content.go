@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"gorm.io/gorm"
+	"project/cms/internal/proto"
+	"project/model"
+	"time"
+)
+
+func (s *Service) PaginateContent(ctx context.Context,
+	p *proto.ListArgs) (total int64, list []*model.Content, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.Content{})
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+func (s *Service) CreateContent(ctx context.Context, data *model.Content) (bool, error) {
+	opt := s.mysql.WithContext(ctx).FirstOrCreate(data, "`key` = ?", data.Key)
+	return opt.RowsAffected > 0, opt.Error
+}
+
+// SaveContentDraft 只修改草稿，发布后才对客户端生效
+func (s *Service) SaveContentDraft(ctx context.Context, id int, name string, draft json.RawMessage) error {
+	return s.mysql.WithContext(ctx).Model(&model.Content{ID: id}).
+		Updates(map[string]any{"name": name, "draft": draft}).Error
+}
+
+// PublishContent 草稿复制为发布内容并增加版本，删除api缓存后刷新cdn，返回新版本，0为内容不存在
+func (s *Service) PublishContent(ctx context.Context, id int, operator string) (int, error) {
+	opt := s.mysql.WithContext(ctx).Model(&model.Content{ID: id}).Updates(map[string]any{
+		"body":         gorm.Expr("draft"),
+		"version":      gorm.Expr("version + 1"),
+		"publish_time": time.Now().Unix(),
+		"operator":     operator,
+	})
+	if opt.Error != nil || opt.RowsAffected == 0 {
+		return 0, opt.Error
+	}
+	var data model.Content
+	if err := s.mysql.WithContext(ctx).Select("key", "version").Take(&data, id).Error; err != nil {
+		return 0, err
+	}
+	if err := s.redis.Del(ctx, model.ContentKey(data.Key)).Err(); err != nil {
+		return 0, err
+	}
+	if s.contentURL != "" {
+		s.purgeURL(ctx, s.contentURL+data.Key)
+	} else {
+		s.purgeTag(ctx, model.CdnTagContent(data.Key))
+	}
+	return data.Version, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/logger"
+	"time"
+)
+
+func (s *Service) PaginateCoupon(ctx context.Context,
+	p *proto.ListArgs) (total int64, list []*proto.CouponItem, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.CouponTemplate{})
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	var tpls []*model.CouponTemplate
+	if err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&tpls).Error; err != nil {
+		return
+	}
+	ids := make([]int, 0, len(tpls))
+	for _, v := range tpls {
+		ids = append(ids, v.ID)
+	}
+	var stats []struct {
+		TemplateID int
+		Claimed    int64
+		Used       int64
+	}
+	err = s.mysql.WithContext(ctx).Model(&model.Coupon{}).
+		Select("template_id, COUNT(*) AS claimed, SUM(status = ?) AS used", model.CouponUsed).
+		Where("template_id IN ?", ids).Group("template_id").Scan(&stats).Error
+	if err != nil {
+		return
+	}
+	pipe := s.redis.Pipeline()
+	cmds := make([]*redis.StringCmd, len(tpls))
+	for i, v := range tpls {
+		cmds[i] = pipe.Get(ctx, model.CouponStockKey(v.ID))
+	}
+	_, _ = pipe.Exec(ctx) // 库存key不存在时为redis.Nil，剩余按0展示
+	list = make([]*proto.CouponItem, len(tpls))
+	for i, v := range tpls {
+		list[i] = &proto.CouponItem{CouponTemplate: v}
+		list[i].Remain, _ = cmds[i].Int64()
+		for _, st := range stats {
+			if st.TemplateID == v.ID {
+				list[i].Claimed, list[i].Used = st.Claimed, st.Used
+			}
+		}
+	}
+	return
+}
+
+// CreateCoupon 入库后初始化redis库存，库存只在创建时设置
+func (s *Service) CreateCoupon(ctx context.Context, data *model.CouponTemplate) error {
+	if err := s.mysql.WithContext(ctx).Create(data).Error; err != nil {
+		return err
+	}
+	return s.redis.SetNX(ctx, model.CouponStockKey(data.ID), data.Stock, 0).Err()
+}
+
+func (s *Service) UpdateCouponStatus(ctx context.Context, id int, status int8) error {
+	return s.mysql.WithContext(ctx).Model(&model.CouponTemplate{ID: id}).Update("status", status).Error
+}
+
+func (s *Service) FindCouponTemplate(ctx context.Context, id int) (*model.CouponTemplate, error) {
+	var data model.CouponTemplate
+	err := s.mysql.WithContext(ctx).Take(&data, "id = ?", id).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// IssueCoupon 后台发放，不受每人限领约束，但占用库存
+func (s *Service) IssueCoupon(ctx context.Context, tpl *model.CouponTemplate, uid int) error {
+	keys := []string{model.CouponStockKey(tpl.ID), model.CouponUserKey(tpl.ID)}
+	ret, err := model.ScriptCouponClaim.Run(ctx, s.redis, keys, uid, 0).Int()
+	if err != nil {
+		return err
+	}
+	if ret == 0 || ret == -2 {
+		return model.ErrCouponSoldOut
+	}
+	coupon := &model.Coupon{
+		TemplateID: tpl.ID,
+		UserID:     uid,
+		Status:     model.CouponUnused,
+	}
+	coupon.BeginTime, coupon.EndTime = tpl.Validity(time.Now())
+	if err = s.mysql.WithContext(ctx).Create(coupon).Error; err != nil {
+		if e := model.ScriptCouponRollback.Run(ctx, s.redis, keys, uid).Err(); e != nil {
+			logger.FromContext(ctx).Error("redis.ScriptCouponRollback error", keys, e)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/db"
+)
+
+func (s *Service) PaginateExperiment(ctx context.Context,
+	p *proto.ListArgs) (total int64, list []*model.Experiment, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.Experiment{})
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+// CreateExperiment 实验标识已存在时返回false，被已删除的实验占用时返回ErrExperimentDeleted，需在回收站恢复
+func (s *Service) CreateExperiment(ctx context.Context, data *model.Experiment) (bool, error) {
+	var exist model.Experiment
+	err := s.mysql.WithContext(ctx).Unscoped().Select("id", "delete_time").
+		Where("`key` = ?", data.Key).Limit(1).Find(&exist).Error
+	if err != nil {
+		return false, err
+	}
+	if exist.ID > 0 {
+		if exist.DeleteTime.Valid {
+			return false, model.ErrExperimentDeleted
+		}
+		return false, nil
+	}
+	if err = s.mysql.WithContext(ctx).Create(data).Error; err != nil {
+		if db.IsDuplicateKey(err) { // 同时创建
+			return false, nil
+		}
+		return false, err
+	}
+	return true, s.redis.Del(ctx, model.KeyExperiments).Err()
+}
+
+func (s *Service) UpdateExperiment(ctx context.Context, data *model.Experiment) error {
+	opt := s.mysql.WithContext(ctx).Select("name", "traffic", "variants", "begin_time", "end_time", "status", "segment").
+		Updates(data)
+	if opt.Error != nil {
+		return opt.Error
+	}
+	if opt.RowsAffected > 0 {
+		return s.redis.Del(ctx, model.KeyExperiments).Err()
+	}
+	return nil
+}
+
+func (s *Service) DeleteExperiment(ctx context.Context, id int) error {
+	opt := s.mysql.WithContext(ctx).Delete(&model.Experiment{ID: id}) // 软删除，可在回收站恢复
+	if opt.Error != nil {
+		return opt.Error
+	}
+	if opt.RowsAffected > 0 {
+		return s.redis.Del(ctx, model.KeyExperiments).Err()
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"project/cms/internal/proto"
+	"project/model"
+	"strconv"
+	"time"
+)
+
+func (s *Service) PaginateLottery(ctx context.Context,
+	p *proto.ListArgs) (total int64, list []*model.Lottery, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.Lottery{})
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+// FindLottery 活动、奖品的剩余库存(redis)和中奖数，不存在时返回nil
+func (s *Service) FindLottery(ctx context.Context, id int) (*proto.LotteryDetail, error) {
+	var list []*model.Lottery
+	err := s.mysql.WithContext(ctx).Preload("Prizes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("id = ?", id).Limit(1).Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	data := &proto.LotteryDetail{Lottery: list[0]}
+	var rows []*struct {
+		PrizeID int
+		N       int64
+	}
+	err = s.mysql.WithContext(ctx).Model(&model.LotteryDraw{}).Select("prize_id, COUNT(*) AS n").
+		Where("lottery_id = ?", id).Group("prize_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	won := make(map[int]int64, len(rows))
+	for _, v := range rows {
+		won[v.PrizeID] = v.N
+		data.Draws += v.N
+	}
+	err = s.mysql.WithContext(ctx).Model(&model.LotteryDraw{}).Where("lottery_id = ?", id).
+		Distinct("user_id").Count(&data.Users).Error
+	if err != nil {
+		return nil, err
+	}
+	stock, err := s.redis.HGetAll(ctx, model.LotteryStockKey(id)).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	data.Prizes = make([]*proto.LotteryPrizeStat, len(data.Lottery.Prizes))
+	for i, v := range data.Lottery.Prizes {
+		data.Prizes[i] = &proto.LotteryPrizeStat{LotteryPrize: v, Won: won[v.ID]}
+		if remain, ok := stock[strconv.Itoa(v.ID)]; ok {
+			data.Prizes[i].Remain, _ = strconv.ParseInt(remain, 10, 64)
+		} else { // 未初始化时按数据库计算
+			data.Prizes[i].Remain = int64(v.Stock) - won[v.ID]
+		}
+	}
+	return data, nil
+}
+
+// CreateLottery 创建后为下线状态，同时初始化奖品库存
+func (s *Service) CreateLottery(ctx context.Context, data *model.Lottery) error {
+	data.Status = model.StatusOff
+	if err := s.mysql.WithContext(ctx).Create(data).Error; err != nil {
+		return err
+	}
+	return s.resetLotteryStock(ctx, data.ID, data.Prizes)
+}
+
+// UpdateLottery prizes不为nil时整体替换奖品，活动开始后不可替换
+func (s *Service) UpdateLottery(ctx context.Context, data *model.Lottery, prizes []*model.LotteryPrize) error {
+	err := s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var old model.Lottery
+		if err := tx.Take(&old, data.ID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return model.ErrLotteryNotFound
+			}
+			return err
+		}
+		err := tx.Select("name", "description", "begin_time", "end_time", "daily_limit", "total_limit", "operator").
+			Updates(data).Error
+		if err != nil || prizes == nil {
+			return err
+		}
+		if now := time.Now().Unix(); old.BeginTime <= now || data.BeginTime <= now {
+			return model.ErrLotteryStarted
+		}
+		if err = tx.Where("lottery_id = ?", data.ID).Delete(&model.LotteryPrize{}).Error; err != nil {
+			return err
+		}
+		if len(prizes) == 0 {
+			return nil
+		}
+		for _, v := range prizes {
+			v.LotteryID = data.ID
+		}
+		return tx.Create(prizes).Error
+	})
+	if err != nil || prizes == nil {
+		return err
+	}
+	return s.resetLotteryStock(ctx, data.ID, prizes)
+}
+
+// resetLotteryStock 按奖品的总库存重新初始化，只在活动开始前调用
+func (s *Service) resetLotteryStock(ctx context.Context, id int, prizes []*model.LotteryPrize) error {
+	key := model.LotteryStockKey(id)
+	if err := s.redis.Del(ctx, key).Err(); err != nil {
+		return err
+	}
+	args := make([]any, 0, len(prizes)*2)
+	for _, v := range prizes {
+		args = append(args, v.ID, v.Stock)
+	}
+	return model.ScriptLotteryInit.Run(ctx, s.redis, []string{key}, args...).Err()
+}
+
+func (s *Service) UpdateLotteryStatus(ctx context.Context, id int, status int8, operator string) error {
+	return s.mysql.WithContext(ctx).Model(&model.Lottery{ID: id}).
+		Updates(map[string]any{"status": status, "operator": operator}).Error
+}
+
+func (s *Service) PaginateLotteryDraw(ctx context.Context,
+	p *proto.LotteryDrawListArgs) (total int64, list []*model.LotteryDraw, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.LotteryDraw{})
+	if p.LotteryID > 0 {
+		query = query.Where("lottery_id = ?", p.LotteryID)
+	}
+	if p.UserID > 0 {
+		query = query.Where("user_id = ?", p.UserID)
+	}
+	if p.Status != nil {
+		query = query.Where("status = ?", *p.Status)
+	}
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+// DeliverLotteryDraw 实物奖品确认发放
+func (s *Service) DeliverLotteryDraw(ctx context.Context, id int, operator string) error {
+	opt := s.mysql.WithContext(ctx).Model(&model.LotteryDraw{ID: id}).Where("status = ?", model.LotteryDrawPending).
+		Updates(map[string]any{"status": model.LotteryDrawIssued, "operator": operator})
+	if opt.Error != nil {
+		return opt.Error
+	}
+	if opt.RowsAffected == 0 {
+		return model.ErrLotteryDraw
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"project/cms/internal/proto"
+	"project/model"
+)
+
+func (s *Service) PaginateMedia(ctx context.Context,
+	p *proto.ListArgs) (total int64, list []*model.Media, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.Media{})
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+// CreateMedia 登记为待转码，由script的media:transcode认领
+func (s *Service) CreateMedia(ctx context.Context, data *model.Media) error {
+	data.Status = model.MediaPending
+	return s.mysql.WithContext(ctx).Create(data).Error
+}
+
+func (s *Service) FindMedia(ctx context.Context, id int) (*model.Media, error) {
+	var data model.Media
+	err := s.mysql.WithContext(ctx).Take(&data, id).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// RetryMedia 转码失败的视频重新排队，尝试次数清零
+func (s *Service) RetryMedia(ctx context.Context, id int) (bool, error) {
+	opt := s.mysql.WithContext(ctx).Model(&model.Media{}).
+		Where("id = ? AND status = ?", id, model.MediaFailed).
+		Updates(map[string]any{"status": model.MediaPending, "attempts": 0, "task_id": "", "error": ""})
+	return opt.RowsAffected > 0, opt.Error
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"project/pkg/wechat"
+	"project/pkg/wechat/tokenstore"
+)
+
+// TokenStore 公众号access_token等凭证的存储，由script的refresh:token写入
+func (s *Service) TokenStore() wechat.TokenStore {
+	return tokenstore.NewRedis(s.redis)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"project/model"
+	"project/pkg/fsm"
+	"project/pkg/listquery"
+	"time"
+)
+
+func (s *Service) PaginateOrder(ctx context.Context,
+	spec *listquery.Spec) (total int64, list []*model.Order, err error) {
+	query := spec.Where(s.mysql.WithContext(ctx).Model(&model.Order{}))
+	err = query.Count(&total).Error
+	if err != nil || total == 0 || spec.Offset() >= int(total) {
+		return
+	}
+	err = spec.Paginate(query).Find(&list).Error
+	return
+}
+
+// ExportOrder 按游标逐行回调，不在内存中持有整个列表；超过max条时返回model.ErrExportTooLarge，max为0不限制
+// 回调期间占用一个db连接，fn返回错误时停止
+func (s *Service) ExportOrder(ctx context.Context, spec *listquery.Spec, max int64, fn func(*model.Order) error) error {
+	query := spec.Where(s.mysql.WithContext(ctx).Model(&model.Order{}))
+	if max > 0 {
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			return err
+		}
+		if total > max {
+			return model.ErrExportTooLarge
+		}
+	}
+	rows, err := spec.Order(query).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data model.Order
+		if err = s.mysql.ScanRows(rows, &data); err != nil {
+			return err
+		}
+		if err = fn(&data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *Service) FindOrderByNo(ctx context.Context, orderNo string) (*model.Order, error) {
+	var data model.Order
+	err := s.mysql.WithContext(ctx).Take(&data, "order_no = ?", orderNo).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// CreateRefund 占用可退金额并创建退款单，返回false表示订单状态或可退金额不满足
+func (s *Service) CreateRefund(ctx context.Context, order *model.Order, data *model.OrderRefund, operator string) (bool, error) {
+	err := model.TransitOrder(ctx, s.orderFSM, s.mysql, &fsm.Change[int8]{
+		Subject:  order.OrderNo,
+		Event:    model.OrderEventRefund,
+		From:     order.Status,
+		Operator: operator,
+		Remark:   data.RefundNo,
+	}, nil, func(tx *gorm.DB) error {
+		opt := tx.Model(&model.Order{}).
+			Where("order_no = ? AND amount - refund_amount >= ?", data.OrderNo, data.Amount).
+			Update("refund_amount", gorm.Expr("refund_amount + ?", data.Amount))
+		if opt.Error != nil {
+			return opt.Error
+		}
+		if opt.RowsAffected == 0 {
+			return fsm.ErrConflict
+		}
+		return tx.Create(data).Error
+	})
+	return model.TransitResult(err)
+}
+
+func (s *Service) UpdateRefundID(ctx context.Context, refundNo, refundID string) error {
+	return s.mysql.WithContext(ctx).Model(&model.OrderRefund{}).
+		Where("refund_no = ?", refundNo).Update("refund_id", refundID).Error
+}
+
+// SucceedRefund 同步退款成功，回调重复到达时按状态忽略
+func (s *Service) SucceedRefund(ctx context.Context, data *model.OrderRefund) error {
+	return s.finishRefund(ctx, data, true)
+}
+
+// FailRefund 退款申请失败，释放占用的可退金额
+func (s *Service) FailRefund(ctx context.Context, data *model.OrderRefund) error {
+	return s.finishRefund(ctx, data, false)
+}
+
+func (s *Service) finishRefund(ctx context.Context, data *model.OrderRefund, success bool) error {
+	order, err := s.FindOrderByNo(ctx, data.OrderNo)
+	if err != nil || order.ID == 0 {
+		return err
+	}
+	c := &fsm.Change[int8]{
+		Subject:  order.OrderNo,
+		Event:    model.OrderEventRefundSuccess,
+		From:     order.Status,
+		Operator: "system",
+		Remark:   data.RefundNo,
+		Payload:  order,
+	}
+	updates := map[string]any{}
+	refund := map[string]any{
+		"status":       model.RefundSuccess,
+		"refund_id":    data.RefundID,
+		"success_time": data.SuccessTime,
+	}
+	if !success {
+		c.Event = model.OrderEventRefundFail
+		order.RefundAmount -= data.Amount
+		updates["refund_amount"] = gorm.Expr("refund_amount - ?", data.Amount)
+		refund = map[string]any{"status": model.RefundFailed}
+	}
+	err = model.TransitOrder(ctx, s.orderFSM, s.mysql, c, updates, func(tx *gorm.DB) error {
+		opt := tx.Model(&model.OrderRefund{}).
+			Where("refund_no = ? AND status = ?", data.RefundNo, model.RefundProcessing).
+			Updates(refund)
+		if opt.Error == nil && opt.RowsAffected == 0 {
+			return fsm.ErrConflict
+		}
+		return opt.Error
+	})
+	_, err = model.TransitResult(err)
+	return err
+}
+
+// ShipOrder 发货，remark记录物流信息
+func (s *Service) ShipOrder(ctx context.Context, order *model.Order, operator, remark string) (bool, error) {
+	err := model.TransitOrder(ctx, s.orderFSM, s.mysql, &fsm.Change[int8]{
+		Subject:  order.OrderNo,
+		Event:    model.OrderEventShip,
+		From:     order.Status,
+		Operator: operator,
+		Remark:   remark,
+	}, nil, nil)
+	return model.TransitResult(err)
+}
+
+func (s *Service) FindOrderLogs(ctx context.Context, orderNo string) (list []*model.OrderLog, err error) {
+	err = s.mysql.WithContext(ctx).Where("order_no = ?", orderNo).Order("id DESC").Find(&list).Error
+	return
+}
+
+func (s *Service) FindRefundsByOrder(ctx context.Context, orderNo string) (list []*model.OrderRefund, err error) {
+	err = s.mysql.WithContext(ctx).Where("order_no = ?", orderNo).Order("id DESC").Find(&list).Error
+	return
+}
+
+func GenRefundNo(orderNo string) string {
+	return orderNo + "R" + time.Now().Format("060102150405")
+}
+
+func (s *Service) publishOrder(_ context.Context, c *fsm.Change[int8]) error {
+	return s.producer.Publish(model.TopicOrder, model.OrderMessage(c))
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"project/model"
+	"time"
+)
+
+// QRCodeUploaded 相同内容和参数的二维码是否已上传，cdn路径由hash确定
+func (s *Service) QRCodeUploaded(ctx context.Context, hash string) (bool, error) {
+	err := s.redis.Get(ctx, model.QRCodeKey(hash)).Err()
+	if err == redis.Nil {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *Service) SetQRCodeUploaded(ctx context.Context, hash string) error {
+	return s.redis.Set(ctx, model.QRCodeKey(hash), 1, 30*24*time.Hour).Err()
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+	"project/pkg/saga"
+	"time"
+)
+
+// FindStuckSagas 长时间未推进的saga，api每分钟自动重试，仍停留的多为补偿持续失败需人工处理
+func (s *Service) FindStuckSagas(ctx context.Context, name string, idle time.Duration) ([]*saga.Record, error) {
+	return saga.NewStore(s.mysql).FindStuck(ctx, name, time.Now().Add(-idle), 100)
+}
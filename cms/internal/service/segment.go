@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/segment"
+)
+
+func (s *Service) PaginateSegment(ctx context.Context,
+	p *proto.ListArgs) (total int64, list []*model.Segment, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.Segment{})
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+func (s *Service) CreateSegment(ctx context.Context, data *model.Segment) (bool, error) {
+	opt := s.mysql.WithContext(ctx).FirstOrCreate(data, "code = ?", data.Code)
+	return opt.RowsAffected > 0, opt.Error
+}
+
+// UpdateSegment 修改规则后成员在下次定时计算时更新
+func (s *Service) UpdateSegment(ctx context.Context, data *model.Segment) error {
+	return s.mysql.WithContext(ctx).Select("name", "rule", "status").Updates(data).Error
+}
+
+// CountSegment 预估规则命中的人数，规则复杂或用户量大时较慢
+func (s *Service) CountSegment(ctx context.Context, rule *segment.Rule) (int64, error) {
+	cond, args, err := model.SegmentEngine.Compile(rule)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	err = s.mysql.WithContext(ctx).Model(&model.User{}).Where(cond, args...).Count(&total).Error
+	return total, err
+}
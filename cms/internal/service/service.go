@@ -3,29 +3,75 @@ package service
 import (
 	"github.com/go-redis/redis/v8"
 	"gorm.io/gorm"
+	"log"
+	"project/model"
+	"project/pkg/boot"
 	"project/pkg/cache"
+	"project/pkg/cdn"
 	"project/pkg/db"
+	"project/pkg/fsm"
+	"project/pkg/geo"
+	"project/pkg/logger"
+	"project/pkg/mq"
+	"project/pkg/shortlink"
+	"time"
 )
 
 type Service struct {
-	mysql *gorm.DB
-	redis *redis.Client
-	//nsq   *nsq.Producer
+	mysql    *gorm.DB
+	redis    *redis.Client
+	producer mq.Producer
+	shards   *db.Cluster
+
+	orderFSM   *fsm.Machine[int8]
+	shortlink  *shortlink.Shortener
+	purger     *cdn.AsyncPurger
+	geo        *geo.Index
+	shortURL   string
+	contentURL string
 }
 
 type Config struct {
 	Mysql db.Mysql
+	Shard db.ShardConfig // 按用户分片的表，与api的service.shard一致
 	Redis cache.Redis
 	Nsq   struct {
 		Producer string
 	}
+	Kafka      *mq.KafkaConfig   // 配置brokers后消息投递到kafka，nsq不生效
+	Purge      *cdn.PurgeOptions // 内容变更后刷新cdn缓存，未配置driver不刷新
+	ShortURL   string            // 短链的访问地址前缀，如 https://s.domain.cn/s/ ，修改短链后刷新
+	ContentURL string            // 运营内容的访问地址前缀，如 https://api.domain.cn/content/ ，配置后发布时按url刷新，否则按标签
 }
 
 func New(cfg *Config) *Service {
 	s := &Service{
-		mysql: db.NewMysqlDB(&cfg.Mysql),
-		redis: cache.NewRedisClient(&cfg.Redis),
-		//nsq:   mq.NewNsqProducer(cfg.Nsq.Producer),
+		mysql:    db.NewMysqlDB(&cfg.Mysql),
+		redis:    cache.NewRedisClient(&cfg.Redis),
+		producer: mq.NewProducer(cfg.Nsq.Producer, cfg.Kafka),
+	}
+	s.shards = db.NewCluster(s.mysql, &cfg.Shard)
+	s.orderFSM = model.NewOrderFSM().OnTransition(s.publishOrder)
+	s.shortlink = shortlink.New(s.mysql, s.redis, 0)
+	s.geo = geo.New(s.redis, model.KeyGeo)
+	s.shortURL = cfg.ShortURL
+	s.contentURL = cfg.ContentURL
+	purger, err := cdn.NewPurger(cfg.Purge, logger.NewClient("cdn", 30*time.Second))
+	if err != nil {
+		log.Fatal("cdn.NewPurger error: ", err)
 	}
+	s.purger = purger
 	return s
 }
+
+// Close 处理完待刷新的cdn缓存
+func (s *Service) Close() {
+	if s.purger != nil {
+		s.purger.Close()
+	}
+}
+
+// Checks 启动阶段检查的依赖
+func (s *Service) Checks() []boot.Check {
+	return []boot.Check{boot.Mysql(s.mysql), boot.Redis(s.redis), boot.MQ(s.producer), {Name: "shard", Fn: s.shards.Start}}
+}
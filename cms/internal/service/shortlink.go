@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"project/cms/internal/proto"
+	"project/pkg/shortlink"
+)
+
+func (s *Service) PaginateShortLink(ctx context.Context,
+	p *proto.ShortLinkListArgs) (total int64, list []*shortlink.Link, err error) {
+	query := s.mysql.WithContext(ctx).Model(&shortlink.Link{})
+	if p.Code != "" {
+		query = query.Where("code = ?", p.Code)
+	}
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+func (s *Service) CreateShortLink(ctx context.Context, data *shortlink.Link) error {
+	return s.shortlink.Create(ctx, data)
+}
+
+// UpdateShortLink 修改后删除跳转缓存，配置了shortUrl时刷新CDN缓存，否则已缓存的跳转在shortLinkMaxAge后生效
+func (s *Service) UpdateShortLink(ctx context.Context, id int, values map[string]any) error {
+	if err := s.shortlink.Update(ctx, id, values); err != nil {
+		return err
+	}
+	if s.shortURL != "" {
+		var l shortlink.Link
+		if err := s.mysql.WithContext(ctx).Select("code").Take(&l, id).Error; err != nil {
+			return err
+		}
+		s.purgeURL(ctx, s.shortURL+l.Code)
+	}
+	return nil
+}
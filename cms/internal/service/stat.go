@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"project/model"
+)
+
+// FindStatDaily 查询script汇总的看板数据，按日期升序
+func (s *Service) FindStatDaily(ctx context.Context, begin, end int) (list []*model.StatDaily, err error) {
+	err = s.mysql.WithContext(ctx).Where("date BETWEEN ? AND ?", begin, end).Order("date").Find(&list).Error
+	for _, v := range list {
+		if v.Requests > 0 {
+			v.ErrorRate = float64(v.Errors) / float64(v.Requests)
+		}
+	}
+	return
+}
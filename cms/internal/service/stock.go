@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/cms/internal/proto"
+	"project/model"
+)
+
+func (s *Service) PaginateStock(ctx context.Context,
+	p *proto.ListArgs) (total int64, list []*proto.StockItem, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.Stock{})
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	var rows []*model.Stock
+	if err = query.Order("sku_id DESC").Limit(p.Size).Offset(offset).Find(&rows).Error; err != nil {
+		return
+	}
+	pipe := s.redis.Pipeline()
+	cmds := make([]*redis.StringCmd, len(rows))
+	for i, v := range rows {
+		cmds[i] = pipe.Get(ctx, model.StockKey(v.SkuID))
+	}
+	_, _ = pipe.Exec(ctx)
+	list = make([]*proto.StockItem, len(rows))
+	for i, v := range rows {
+		list[i] = &proto.StockItem{Stock: v, Remain: -1}
+		if n, e := cmds[i].Int64(); e == nil {
+			list[i].Remain = n
+		}
+	}
+	return
+}
+
+// AddStock 数据库与redis同时调整，redis不足以减少时回滚数据库
+func (s *Service) AddStock(ctx context.Context, sku, delta int) (bool, error) {
+	ok := false
+	err := s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if delta > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				DoUpdates: clause.Assignments(map[string]any{"stock": gorm.Expr("stock + ?", delta)}),
+			}).Create(&model.Stock{SkuID: sku, Stock: delta}).Error
+			if err != nil {
+				return err
+			}
+		} else {
+			opt := tx.Model(&model.Stock{}).Where("sku_id = ? AND stock >= ?", sku, -delta).
+				Update("stock", gorm.Expr("stock + ?", delta))
+			if opt.Error != nil || opt.RowsAffected == 0 {
+				return opt.Error
+			}
+		}
+		var data model.Stock
+		if err := tx.Take(&data, "sku_id = ?", sku).Error; err != nil {
+			return err
+		}
+		n, err := model.ScriptStockAdd.Run(ctx, s.redis, []string{model.StockKey(sku)}, delta, data.Stock).Int()
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return model.ErrStockNotEnough
+		}
+		ok = true
+		return nil
+	})
+	if err == model.ErrStockNotEnough {
+		return false, nil
+	}
+	return ok, err
+}
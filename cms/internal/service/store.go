@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"project/cms/internal/proto"
+	"project/model"
+	"project/pkg/geo"
+)
+
+func (s *Service) PaginateStore(ctx context.Context,
+	p *proto.StoreListArgs) (total int64, list []*model.Store, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.Store{})
+	if p.City != "" {
+		query = query.Where("city = ?", p.City)
+	}
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("id DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+func (s *Service) CreateStore(ctx context.Context, data *model.Store) error {
+	data.Status = model.StoreOpen
+	if err := s.mysql.WithContext(ctx).Create(data).Error; err != nil {
+		return err
+	}
+	return s.syncStoreGeo(ctx, data)
+}
+
+// UpdateStore 修改资料和坐标，不修改状态
+func (s *Service) UpdateStore(ctx context.Context, data *model.Store) error {
+	err := s.mysql.WithContext(ctx).Select("name", "city", "address", "phone", "hours", "lng", "lat", "operator").
+		Updates(data).Error
+	if err != nil {
+		return err
+	}
+	return s.syncStoreGeo(ctx, data)
+}
+
+// UpdateStoreStatus 停业的门店从附近搜索中移除
+func (s *Service) UpdateStoreStatus(ctx context.Context, id int, status int8, operator string) error {
+	data := &model.Store{ID: id}
+	err := s.mysql.WithContext(ctx).Model(data).Updates(map[string]any{"status": status, "operator": operator}).Error
+	if err != nil {
+		return err
+	}
+	return s.syncStoreGeo(ctx, data)
+}
+
+// syncStoreGeo 按数据库中的状态和坐标更新附近搜索的索引，失败时由script每小时的重建修正
+func (s *Service) syncStoreGeo(ctx context.Context, data *model.Store) error {
+	err := s.mysql.WithContext(ctx).Select("id", "lng", "lat", "status").Take(data, data.ID).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if data.Status != model.StoreOpen {
+		return s.geo.Remove(ctx, model.GeoStore, data.ID)
+	}
+	return s.geo.Set(ctx, model.GeoStore, &geo.Point{ID: data.ID, Lng: data.Lng, Lat: data.Lat})
+}
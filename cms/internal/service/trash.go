@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"project/cms/internal/proto"
+	"project/model"
+)
+
+// 回收站：软删除记录的查询和恢复，表需登记在model.SoftDeleteTables
+
+func (s *Service) PaginateTrash(ctx context.Context,
+	p *proto.TrashListArgs) (total int64, list []map[string]any, err error) {
+	query := s.mysql.WithContext(ctx).Table(p.Table).Where("delete_time IS NOT NULL")
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("delete_time DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+func (s *Service) RestoreTrash(ctx context.Context, table string, id int) (bool, error) {
+	opt := s.mysql.WithContext(ctx).Table(table).Where("id = ? AND delete_time IS NOT NULL", id).
+		Update("delete_time", nil)
+	if opt.Error != nil || opt.RowsAffected == 0 {
+		return false, opt.Error
+	}
+	return true, s.clearTableCache(ctx, table, id)
+}
+
+// clearTableCache 恢复后清理对应的缓存
+func (s *Service) clearTableCache(ctx context.Context, table string, id int) error {
+	switch table {
+	case "banner":
+		var b model.Banner
+		if err := s.mysql.WithContext(ctx).Select("city").Where("id = ?", id).Take(&b).Error; err != nil {
+			return err
+		}
+		s.purgeTag(ctx, model.CdnTagBanner)
+		return s.redis.Del(ctx, model.BannersKey(b.City)).Err()
+	case "experiment":
+		return s.redis.Del(ctx, model.KeyExperiments).Err()
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"project/cms/internal/proto"
+	"project/model"
+	"time"
+)
+
+// PaginateUserStatus 处罚中的用户，已到期的不返回
+func (s *Service) PaginateUserStatus(ctx context.Context,
+	p *proto.ListArgs) (total int64, list []*model.UserStatus, err error) {
+	query := s.mysql.WithContext(ctx).Model(&model.UserStatus{}).
+		Where("status <> ? AND (expire_time = 0 OR expire_time > ?)", model.UserActive, time.Now().Unix())
+	err = query.Count(&total).Error
+	offset := p.Size * (p.Page - 1)
+	if err != nil || total == 0 || offset >= int(total) {
+		return
+	}
+	err = query.Order("update_time DESC").Limit(p.Size).Offset(offset).Find(&list).Error
+	return
+}
+
+// SetUserStatus 覆盖用户的处罚状态并删除api的状态缓存，恢复正常时删除记录
+func (s *Service) SetUserStatus(ctx context.Context, st *model.UserStatus) error {
+	db := s.mysql.WithContext(ctx)
+	var err error
+	if st.Status == model.UserActive {
+		err = db.Delete(&model.UserStatus{}, "user_id = ?", st.UserID).Error
+	} else {
+		err = db.Save(st).Error
+	}
+	if err != nil {
+		return err
+	}
+	return s.redis.Del(ctx, model.UserStatusKey(st.UserID)).Err()
+}
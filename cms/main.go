@@ -6,17 +6,18 @@ import (
 	"github.com/spf13/viper"
 	"log"
 	"math/rand"
-	"net/http"
 	"os"
 	"os/signal"
 	"project/cms/internal/handler"
 	"project/cms/internal/service"
+	"project/pkg/boot"
 	"project/pkg/logger"
+	"project/pkg/server"
 	"syscall"
 	"time"
 )
 
-func setup() *http.Server {
+func setup() (*server.Server, *service.Service) {
 	viper.SetConfigName("conf")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
@@ -27,8 +28,11 @@ func setup() *http.Server {
 
 	var cfg struct {
 		App struct {
-			Mode   string
-			Logger string
+			Mode    string
+			Logger  string
+			Boot    boot.Options           // 启动时依赖检查的重试次数、间隔
+			Http    logger.TransportConfig // 外部调用的连接池和超时
+			Upgrade server.UpgradeOptions  // SIGHUP平滑升级
 		}
 		Handler handler.Config
 		Service service.Config
@@ -39,35 +43,50 @@ func setup() *http.Server {
 
 	gin.SetMode(cfg.App.Mode)
 	logger.SetOutput(cfg.App.Logger)
+	logger.SetTransport(&cfg.App.Http)
+	server.SetUpgrade(&cfg.App.Upgrade)
 	rand.Seed(time.Now().UnixNano())
 
 	s := service.New(&cfg.Service)
+	if err := boot.Wait(&cfg.App.Boot, s.Checks()...); err != nil {
+		log.Fatal(err)
+	}
 	h := handler.Initialize(&cfg.Handler, s)
-	server := &http.Server{
-		Addr:    ":6000",
-		Handler: h,
+	srv, err := server.New(&cfg.Handler.Server, ":6000", h)
+	if err != nil {
+		log.Fatal("server.New error: ", err)
 	}
-	return server
+	return srv, s
 }
 
 func main() {
-	server := setup()
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal(err)
-		}
-	}()
+	srv, s := setup()
+	srv.Run()
+	if err := server.Ready(); err != nil {
+		log.Println("server.Ready error: ", err)
+	}
 
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-	c := <-quit
-	log.Println("signal.Notify: ", c.String())
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
+	for {
+		c := <-quit
+		log.Println("signal.Notify: ", c.String())
+		if c != syscall.SIGHUP {
+			break
+		}
+		if err := server.Upgrade(); err != nil { // 新进程未就绪时继续服务
+			log.Println("server.Upgrade error: ", err)
+			continue
+		}
+		break
+	}
 
 	//ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	//defer cancel() // 带超时控制，等待所有协程退出，或10秒强制退出
 	ctx := context.Background() // 不带超时控制，等待所有协程退出
-	if err := server.Shutdown(ctx); err != nil {
+	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server Shutdown: ", err)
 	}
+	s.Close() // 处理完待刷新的cdn缓存
 	log.Println("Server Exit...")
 }
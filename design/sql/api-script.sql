@@ -12,8 +12,12 @@ CREATE TABLE `banner` (
     begin_time bigint NOT NULL DEFAULT 0 COMMENT '开始时间',
     end_time bigint NOT NULL DEFAULT 0 COMMENT '结束时间',
     status tinyint NOT NULL DEFAULT 1 COMMENT 'off(-1),on(1)',
+    segment varchar(32) NOT NULL DEFAULT '' COMMENT '限定的用户分群code',
     create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    delete_time datetime NULL DEFAULT NULL COMMENT '软删除时间',
+    KEY (city, update_time),
+    KEY (delete_time)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='轮播图';
 
 CREATE TABLE `user` (
@@ -25,8 +29,48 @@ CREATE TABLE `user` (
     avatar_url varchar(150) NOT NULL DEFAULT '' COMMENT '头像链接',
     create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
     update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    KEY (phone_number)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户信息';
+    KEY (unionid),
+    KEY (phone_number),
+    KEY (create_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户信息，openid为小程序的，其他渠道先注册时为该渠道的openid';
+
+CREATE TABLE `user_wechat` (
+    appid varchar(32) NOT NULL COMMENT '开放平台网站应用或移动应用',
+    openid varchar(50) NOT NULL,
+    unionid varchar(50) NOT NULL DEFAULT '',
+    user_id bigint NOT NULL,
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (appid, openid),
+    KEY (unionid),
+    KEY (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='微信其他渠道的登录身份，按unionid关联用户';
+
+CREATE TABLE `user_status` (
+    user_id bigint PRIMARY KEY,
+    status tinyint NOT NULL COMMENT 'muted(1),suspended(2),banned(3),merged(4)',
+    reason varchar(100) NOT NULL DEFAULT '',
+    expire_time bigint NOT NULL DEFAULT 0 COMMENT '解除时间，0为永久',
+    operator varchar(20) NOT NULL DEFAULT '',
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户处罚状态，正常用户无记录';
+
+CREATE TABLE `user_presence` (
+    user_id bigint PRIMARY KEY,
+    last_seen bigint NOT NULL COMMENT '最后在线时间(秒)',
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户最后在线时间，在线状态在redis';
+
+CREATE TABLE `user_merge` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    unionid varchar(50) NOT NULL,
+    from_user_id bigint NOT NULL COMMENT '被合并的用户，openid改为merged:id',
+    to_user_id bigint NOT NULL,
+    detail json NOT NULL COMMENT '各表转移的行数、资产转移的数量',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY (unionid),
+    KEY (from_user_id),
+    KEY (to_user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='同一unionid的用户合并记录';
 
 CREATE TABLE `wechat_analysis` (
     ref_date varchar(10) PRIMARY KEY,
@@ -38,3 +82,604 @@ CREATE TABLE `wechat_analysis` (
     share_uv int NOT NULL DEFAULT 0 COMMENT '转发人数',
     create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='微信小程序访问趋势';
+
+CREATE TABLE `wechat_retain` (
+    ref_date varchar(10) NOT NULL,
+    day int NOT NULL DEFAULT 0 COMMENT '第几天留存，0为当天',
+    visit_uv_new int NOT NULL DEFAULT 0 COMMENT '新增用户留存',
+    visit_uv int NOT NULL DEFAULT 0 COMMENT '活跃用户留存',
+    PRIMARY KEY (ref_date, day)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='微信小程序日留存';
+
+CREATE TABLE `wechat_distribution` (
+    ref_date varchar(10) NOT NULL,
+    `index` varchar(32) NOT NULL COMMENT 'access_source_session_cnt|access_staytime_info|access_depth_info',
+    `key` int NOT NULL DEFAULT 0 COMMENT '场景值或区间编号',
+    value int NOT NULL DEFAULT 0,
+    PRIMARY KEY (ref_date, `index`, `key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='微信小程序访问分布';
+
+CREATE TABLE `experiment` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    `key` varchar(32) NOT NULL UNIQUE COMMENT '实验标识',
+    name varchar(50) NOT NULL DEFAULT '',
+    traffic int NOT NULL DEFAULT 10000 COMMENT '进入实验的流量(万分比)',
+    variants json COMMENT '[{"name":"A","weight":50}]',
+    begin_time bigint NOT NULL DEFAULT 0 COMMENT '开始时间',
+    end_time bigint NOT NULL DEFAULT 0 COMMENT '结束时间',
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'off(-1),on(1)',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    delete_time datetime NULL DEFAULT NULL COMMENT '软删除时间',
+    KEY (delete_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='AB实验';
+
+CREATE TABLE `order_info` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    order_no varchar(32) NOT NULL UNIQUE COMMENT '商户订单号',
+    user_id int NOT NULL DEFAULT 0,
+    subject varchar(100) NOT NULL DEFAULT '' COMMENT '商品描述',
+    amount bigint NOT NULL DEFAULT 0 COMMENT '订单金额(分)，使用优惠券后为应付金额',
+    coupon_id int NOT NULL DEFAULT 0 COMMENT '使用的优惠券',
+    discount bigint NOT NULL DEFAULT 0 COMMENT '优惠券减免金额(分)',
+    refund_amount bigint NOT NULL DEFAULT 0 COMMENT '已退款金额(分)',
+    provider varchar(10) NOT NULL DEFAULT '' COMMENT '支付渠道wxpay|alipay|points(积分兑换)',
+    transaction_id varchar(64) NOT NULL DEFAULT '' COMMENT '渠道交易号',
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'closed(-1),created(1),paid(2),refunding(3),refunded(4),shipped(5),completed(6),paid_late(7)关闭后到账',
+    pay_time bigint NOT NULL DEFAULT 0 COMMENT '支付时间',
+    close_time bigint NOT NULL DEFAULT 0 COMMENT '关闭时间',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY (user_id),
+    KEY (pay_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订单';
+
+CREATE TABLE `order_refund` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    refund_no varchar(64) NOT NULL UNIQUE COMMENT '商户退款单号',
+    order_no varchar(32) NOT NULL,
+    amount bigint NOT NULL DEFAULT 0 COMMENT '退款金额(分)',
+    reason varchar(80) NOT NULL DEFAULT '',
+    refund_id varchar(64) NOT NULL DEFAULT '' COMMENT '渠道退款单号',
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'failed(-1),processing(1),success(2)',
+    success_time bigint NOT NULL DEFAULT 0 COMMENT '退款成功时间',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY (order_no),
+    KEY (success_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订单退款';
+
+CREATE TABLE `order_log` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    order_no varchar(32) NOT NULL,
+    event varchar(20) NOT NULL DEFAULT '' COMMENT '迁移事件',
+    from_status tinyint NOT NULL DEFAULT 0,
+    to_status tinyint NOT NULL DEFAULT 0,
+    operator varchar(20) NOT NULL DEFAULT '' COMMENT 'user:id|admin:id|system',
+    remark varchar(100) NOT NULL DEFAULT '',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY (order_no)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订单状态迁移记录';
+
+CREATE TABLE `coupon_template` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    name varchar(50) NOT NULL DEFAULT '',
+    type tinyint NOT NULL DEFAULT 1 COMMENT 'fixed(1),percent(2)',
+    value bigint NOT NULL DEFAULT 0 COMMENT '满减金额(分)或折后比例(1~99)',
+    threshold bigint NOT NULL DEFAULT 0 COMMENT '使用门槛(分)',
+    max_discount bigint NOT NULL DEFAULT 0 COMMENT '折扣券最高减免(分)',
+    stock int NOT NULL DEFAULT 0 COMMENT '发放总量',
+    per_user int NOT NULL DEFAULT 1 COMMENT '每人限领，0不限',
+    begin_time bigint NOT NULL DEFAULT 0 COMMENT '领取开始时间',
+    end_time bigint NOT NULL DEFAULT 0 COMMENT '领取结束时间',
+    valid_days int NOT NULL DEFAULT 0 COMMENT '领取后有效天数，0使用固定有效期',
+    use_begin bigint NOT NULL DEFAULT 0,
+    use_end bigint NOT NULL DEFAULT 0,
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'off(-1),on(1)',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='优惠券模板';
+
+CREATE TABLE `coupon` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    template_id int NOT NULL,
+    user_id int NOT NULL,
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'revoked(-1),unused(1),used(2)',
+    begin_time bigint NOT NULL DEFAULT 0 COMMENT '有效期开始',
+    end_time bigint NOT NULL DEFAULT 0 COMMENT '有效期结束',
+    order_no varchar(32) NOT NULL DEFAULT '' COMMENT '使用的订单',
+    use_time bigint NOT NULL DEFAULT 0,
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY (user_id, status),
+    KEY (template_id),
+    KEY (order_no)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户优惠券';
+
+CREATE TABLE `sku_stock` (
+    sku_id int PRIMARY KEY,
+    stock int NOT NULL DEFAULT 0 COMMENT '可售库存',
+    sold int NOT NULL DEFAULT 0 COMMENT '已售',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='商品库存';
+
+CREATE TABLE `stock_log` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    sku_id int NOT NULL,
+    order_no varchar(32) NOT NULL,
+    quantity int NOT NULL DEFAULT 0,
+    type tinyint NOT NULL DEFAULT 1 COMMENT 'deduct(1),restock(2)',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY (order_no, sku_id, type)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='库存回写流水';
+
+CREATE TABLE `ledger_account` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    user_id int NOT NULL,
+    asset varchar(10) NOT NULL COMMENT 'points|balance',
+    balance bigint NOT NULL DEFAULT 0,
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY (user_id, asset)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户资产账户';
+
+CREATE TABLE `ledger_entry` (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    ref_no varchar(64) NOT NULL COMMENT '业务幂等号',
+    user_id int NOT NULL COMMENT '0为系统账户',
+    asset varchar(10) NOT NULL,
+    amount bigint NOT NULL COMMENT '正数入账，负数出账',
+    balance bigint NOT NULL DEFAULT 0 COMMENT '记账后余额',
+    biz_type varchar(20) NOT NULL DEFAULT '',
+    remark varchar(100) NOT NULL DEFAULT '',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY (ref_no, asset, user_id),
+    KEY (user_id, asset),
+    KEY (create_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='资产记账分录';
+
+CREATE TABLE `short_link` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    code varchar(16) CHARACTER SET ascii COLLATE ascii_bin NOT NULL UNIQUE COMMENT '短码，区分大小写',
+    type varchar(10) NOT NULL DEFAULT 'url' COMMENT 'url|miniapp',
+    target varchar(500) NOT NULL DEFAULT '' COMMENT '网页地址或小程序页面路径',
+    query varchar(200) NOT NULL DEFAULT '' COMMENT '小程序页面参数',
+    expire_time bigint NOT NULL DEFAULT 0 COMMENT '过期时间，0为永久',
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'off(-1),on(1)',
+    remark varchar(100) NOT NULL DEFAULT '',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='短链';
+
+CREATE TABLE `api_key` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    name varchar(50) NOT NULL DEFAULT '' COMMENT '合作方名称',
+    `key` varchar(64) NOT NULL UNIQUE,
+    daily_quota bigint NOT NULL DEFAULT 0 COMMENT '每日调用上限，0不限',
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'off(-1),on(1)',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='合作方调用凭证';
+
+CREATE TABLE `api_usage` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    date int NOT NULL COMMENT '20060102',
+    subject varchar(30) NOT NULL COMMENT 'key:id|user:id',
+    route varchar(100) NOT NULL COMMENT 'method+path，*为全部路由',
+    count bigint NOT NULL DEFAULT 0,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY (date, subject, route),
+    KEY (subject, date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='接口调用量';
+
+CREATE TABLE `mq_dedup` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    msg_key varchar(150) CHARACTER SET ascii COLLATE ascii_bin NOT NULL UNIQUE COMMENT 'consumer:消息ID或业务键',
+    expire_time datetime NOT NULL,
+    KEY (expire_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='消息消费去重';
+
+CREATE TABLE `saga` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    saga_id varchar(64) NOT NULL COMMENT '业务唯一标识，如订单号',
+    name varchar(30) NOT NULL,
+    step int NOT NULL DEFAULT 0 COMMENT '执行中为下一个步骤，补偿中为下一个补偿步骤+1',
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'aborted(-1),running(1),compensating(2),done(3)',
+    data text NOT NULL COMMENT '步骤间共享的数据json',
+    error varchar(500) NOT NULL DEFAULT '',
+    retries int NOT NULL DEFAULT 0,
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY (saga_id, name),
+    KEY (status, update_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='业务流程编排';
+
+CREATE TABLE `auth_event` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    event_id char(36) CHARACTER SET ascii COLLATE ascii_bin NOT NULL UNIQUE,
+    type varchar(20) NOT NULL COMMENT 'token_issued|token_refreshed|token_revoked|login_failed|new_device',
+    user_id int NOT NULL DEFAULT 0,
+    token_hash varchar(16) NOT NULL DEFAULT '' COMMENT 'token的sha256前16位',
+    ip varchar(45) NOT NULL DEFAULT '',
+    user_agent varchar(255) NOT NULL DEFAULT '',
+    reason varchar(50) NOT NULL DEFAULT '',
+    trace_id varchar(64) NOT NULL DEFAULT '',
+    event_time bigint NOT NULL COMMENT '毫秒',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY (user_id, event_time),
+    KEY (token_hash),
+    KEY (ip, event_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录凭证审计';
+
+CREATE TABLE `user_device` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    user_id int NOT NULL,
+    fingerprint char(32) NOT NULL COMMENT '设备ID和平台，或User-Agent和语言的摘要',
+    device_id varchar(64) NOT NULL DEFAULT '' COMMENT '客户端生成的设备ID',
+    platform varchar(20) NOT NULL DEFAULT '',
+    region varchar(50) NOT NULL DEFAULT '' COMMENT '最近登录的国家/省份',
+    ip varchar(45) NOT NULL DEFAULT '' COMMENT '最近登录的IP',
+    user_agent varchar(255) NOT NULL DEFAULT '',
+    first_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '首次登录',
+    last_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '最近登录',
+    UNIQUE KEY (user_id, fingerprint)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户登录设备';
+
+CREATE TABLE `push_device` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    user_id int NOT NULL,
+    device_id varchar(64) NOT NULL UNIQUE COMMENT '客户端生成的设备标识',
+    platform varchar(10) NOT NULL COMMENT 'ios|android',
+    provider varchar(20) NOT NULL COMMENT 'apns|fcm|厂商通道名',
+    token varchar(255) NOT NULL,
+    app_version varchar(20) NOT NULL DEFAULT '',
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='app推送设备';
+
+CREATE TABLE `push_preference` (
+    user_id int PRIMARY KEY,
+    channel varchar(10) NOT NULL DEFAULT 'auto' COMMENT 'auto|app|wechat|off',
+    muted json NOT NULL COMMENT '关闭的分类:渠道，如["marketing:app"]',
+    quiet_start char(5) NOT NULL DEFAULT '' COMMENT '免打扰开始HH:MM',
+    quiet_end char(5) NOT NULL DEFAULT '' COMMENT '免打扰结束HH:MM，可跨零点',
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户推送偏好';
+
+CREATE TABLE `push_delivery` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    msg_id char(36) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    user_id int NOT NULL,
+    channel varchar(10) NOT NULL COMMENT 'app|wechat，跳过时为用户偏好',
+    provider varchar(20) NOT NULL DEFAULT '',
+    device_id varchar(64) NOT NULL DEFAULT '',
+    status tinyint NOT NULL COMMENT 'skipped(-3),invalid(-2),failed(-1),pending(0),sent(1),deferred(2)',
+    provider_id varchar(100) NOT NULL DEFAULT '' COMMENT '渠道返回的消息ID',
+    error varchar(255) NOT NULL DEFAULT '' COMMENT '失败原因或推迟说明',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY (msg_id),
+    KEY (user_id, create_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='推送发送结果';
+
+CREATE TABLE `store` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    name varchar(50) NOT NULL,
+    city varchar(20) NOT NULL DEFAULT '',
+    address varchar(150) NOT NULL DEFAULT '',
+    phone varchar(20) NOT NULL DEFAULT '',
+    hours varchar(50) NOT NULL DEFAULT '' COMMENT '营业时间',
+    lng decimal(10,6) NOT NULL COMMENT 'gcj02经度',
+    lat decimal(10,6) NOT NULL COMMENT 'gcj02纬度',
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'closed(0),open(1)',
+    operator varchar(20) NOT NULL DEFAULT '',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY (city)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='门店，营业中的坐标同步到redis GEO用于附近搜索';
+
+CREATE TABLE `chat_conversation` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    type tinyint NOT NULL COMMENT 'single(1),group(2)',
+    name varchar(30) NOT NULL DEFAULT '' COMMENT '群聊名称',
+    pair_key varchar(41) CHARACTER SET ascii COLLATE ascii_bin DEFAULT NULL COMMENT '单聊为较小uid:较大uid',
+    owner_id bigint NOT NULL,
+    last_seq bigint NOT NULL DEFAULT 0 COMMENT '最新消息的seq，发送时加行锁递增',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY (pair_key)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='即时消息会话';
+
+CREATE TABLE `chat_member` (
+    conversation_id int NOT NULL,
+    user_id bigint NOT NULL,
+    read_seq bigint NOT NULL DEFAULT 0 COMMENT '已读到的seq',
+    join_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (conversation_id, user_id),
+    KEY (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='会话成员和已读位置';
+
+CREATE TABLE `chat_message` (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    conversation_id int NOT NULL,
+    seq bigint NOT NULL COMMENT '会话内递增',
+    sender_id bigint NOT NULL,
+    client_msg_id varchar(40) CHARACTER SET ascii COLLATE ascii_bin NOT NULL COMMENT '客户端生成，重发去重',
+    type varchar(10) NOT NULL COMMENT 'text|image',
+    content varchar(2000) NOT NULL,
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY (conversation_id, seq),
+    UNIQUE KEY (sender_id, client_msg_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='即时消息';
+
+CREATE TABLE `campaign` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    name varchar(50) NOT NULL,
+    category varchar(20) NOT NULL COMMENT 'marketing|reminder',
+    audience varchar(10) NOT NULL COMMENT 'segment|list|saved',
+    segment json NULL COMMENT '人群条件',
+    segment_id int NOT NULL DEFAULT 0 COMMENT 'audience为saved时的用户分群',
+    title varchar(50) NOT NULL,
+    body varchar(200) NOT NULL,
+    data json NOT NULL COMMENT '客户端跳转参数',
+    wechat json NULL COMMENT '订阅消息模板',
+    send_time bigint NOT NULL,
+    rate int NOT NULL DEFAULT 100 COMMENT '每秒投递数',
+    status tinyint NOT NULL DEFAULT 0 COMMENT 'canceled(-1),scheduled(0),running(1),done(2)',
+    total bigint NOT NULL DEFAULT 0,
+    sent bigint NOT NULL DEFAULT 0,
+    `cursor` int NOT NULL DEFAULT 0 COMMENT '已投递的最大用户ID',
+    operator varchar(30) NOT NULL DEFAULT '',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY (status, send_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='运营群发';
+
+CREATE TABLE `campaign_audience` (
+    campaign_id int NOT NULL,
+    user_id int NOT NULL,
+    PRIMARY KEY (campaign_id, user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='运营群发名单';
+
+CREATE TABLE `segment` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    code varchar(32) NOT NULL UNIQUE,
+    name varchar(50) NOT NULL DEFAULT '',
+    rule json NOT NULL COMMENT '分群规则，见pkg/segment',
+    size bigint NOT NULL DEFAULT 0 COMMENT '最近一次计算的人数',
+    version bigint NOT NULL DEFAULT 0 COMMENT '最近一次计算的批次',
+    refresh_time bigint NOT NULL DEFAULT 0,
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'off(-1),on(1)',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户分群';
+
+CREATE TABLE `segment_member` (
+    segment_id int NOT NULL,
+    user_id int NOT NULL,
+    version bigint NOT NULL COMMENT '写入时的计算批次',
+    PRIMARY KEY (segment_id, user_id),
+    KEY (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户分群成员';
+
+CREATE TABLE `stat_daily` (
+    date int PRIMARY KEY COMMENT '20060102',
+    dau bigint NOT NULL DEFAULT 0 COMMENT '日活跃用户',
+    wau bigint NOT NULL DEFAULT 0 COMMENT '近7天活跃用户',
+    new_users bigint NOT NULL DEFAULT 0 COMMENT '新注册用户',
+    orders bigint NOT NULL DEFAULT 0 COMMENT '支付订单数',
+    revenue bigint NOT NULL DEFAULT 0 COMMENT '支付金额(分)',
+    requests bigint NOT NULL DEFAULT 0 COMMENT 'api请求数',
+    errors bigint NOT NULL DEFAULT 0 COMMENT '5xx响应数',
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='管理后台看板日汇总';
+
+CREATE TABLE `content` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    `key` varchar(32) NOT NULL UNIQUE COMMENT '内容标识，如home_layout、announcement',
+    name varchar(50) NOT NULL DEFAULT '',
+    draft json COMMENT '编辑中的内容',
+    body json COMMENT '已发布的内容',
+    version int NOT NULL DEFAULT 0 COMMENT '发布版本，0为未发布',
+    publish_time bigint NOT NULL DEFAULT 0,
+    operator varchar(32) NOT NULL DEFAULT '' COMMENT '最后发布人',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='运营配置内容(首页布局、公告等)';
+
+CREATE TABLE `wechat_token` (
+    name varchar(64) NOT NULL PRIMARY KEY COMMENT '凭证key，同model中的redis key',
+    token varchar(1024) NOT NULL DEFAULT '',
+    expire_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='微信凭证(access_token、jsapi_ticket)，供没有redis的服务使用tokenstore.SQL';
+
+CREATE TABLE `id_worker` (
+    worker int NOT NULL PRIMARY KEY COMMENT '0-1023',
+    owner varchar(128) NOT NULL DEFAULT '' COMMENT '持有的进程 主机:pid:启动纳秒',
+    expire_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '租约到期时间'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='发号器worker租约，供没有redis的服务使用id.SQLLease';
+
+CREATE TABLE `shard_slot` (
+    slot int NOT NULL PRIMARY KEY COMMENT 'user_id % slots',
+    shard int NOT NULL DEFAULT 0 COMMENT '分片号，service.shard.shards的下标',
+    moving tinyint(1) NOT NULL DEFAULT 0 COMMENT '迁移中，写入返回错误',
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='分片slot映射，保存在主库；未配置分片时不使用';
+
+CREATE TABLE `archive_file` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    path varchar(255) NOT NULL UNIQUE COMMENT '对象存储路径 前缀/表/日期/分片-起始id-结束id.csv.gz',
+    table_name varchar(64) NOT NULL,
+    shard int NOT NULL DEFAULT 0 COMMENT '分片号，未分片为0',
+    min_id bigint NOT NULL DEFAULT 0,
+    max_id bigint NOT NULL DEFAULT 0,
+    `rows` int NOT NULL DEFAULT 0,
+    begin_time datetime NOT NULL COMMENT '文件中最早一行的时间',
+    end_time datetime NOT NULL COMMENT '文件中最晚一行的时间',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY (table_name, end_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='冷数据归档文件，script的cronjob写入，archive:restore按时间范围查找';
+
+CREATE TABLE `media` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    title varchar(100) NOT NULL DEFAULT '',
+    source varchar(200) NOT NULL COMMENT '原始视频的对象路径',
+    size bigint NOT NULL DEFAULT 0,
+    status tinyint NOT NULL DEFAULT 0 COMMENT 'failed(-1),pending(0),processing(1),ready(2)',
+    playlist varchar(200) NOT NULL DEFAULT '' COMMENT 'HLS主播放列表的对象路径',
+    duration int NOT NULL DEFAULT 0 COMMENT '秒',
+    renditions varchar(100) NOT NULL DEFAULT '',
+    task_id varchar(100) NOT NULL DEFAULT '' COMMENT '云端转码任务ID',
+    attempts int NOT NULL DEFAULT 0,
+    error varchar(1024) NOT NULL DEFAULT '',
+    operator varchar(30) NOT NULL DEFAULT '',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY (status, update_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='视频转码';
+
+CREATE TABLE `region` (
+    code char(6) NOT NULL PRIMARY KEY COMMENT '行政区划代码',
+    name varchar(50) NOT NULL,
+    parent char(6) NOT NULL DEFAULT '' COMMENT '上级代码，省份为空',
+    level tinyint NOT NULL COMMENT 'province(1),city(2),district(3)',
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'retired(0),valid(1)',
+    version varchar(20) NOT NULL DEFAULT '' COMMENT '最后变更的版本',
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY (parent)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='行政区划，script的region:import按版本导入';
+
+CREATE TABLE `region_version` (
+    version varchar(20) NOT NULL PRIMARY KEY,
+    total int NOT NULL DEFAULT 0,
+    added int NOT NULL DEFAULT 0 COMMENT '新增和恢复的代码',
+    changed int NOT NULL DEFAULT 0 COMMENT '名称或上级变更',
+    retired int NOT NULL DEFAULT 0,
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY (create_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='行政区划数据集版本，api按最新版本重新加载';
+
+CREATE TABLE `user_address` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    user_id int NOT NULL,
+    name varchar(30) NOT NULL COMMENT '收货人',
+    phone varchar(20) NOT NULL,
+    province_code char(6) NOT NULL,
+    city_code char(6) NOT NULL,
+    district_code varchar(6) NOT NULL DEFAULT '' COMMENT '城市没有下级区县时为空',
+    province varchar(50) NOT NULL DEFAULT '' COMMENT '保存时的名称快照',
+    city varchar(50) NOT NULL DEFAULT '',
+    district varchar(50) NOT NULL DEFAULT '',
+    detail varchar(200) NOT NULL,
+    lng decimal(10,6) NOT NULL DEFAULT 0 COMMENT 'gcj02',
+    lat decimal(10,6) NOT NULL DEFAULT 0,
+    is_default tinyint(1) NOT NULL DEFAULT 0,
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户收货地址';
+
+CREATE TABLE `booking_resource` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    name varchar(50) NOT NULL,
+    description varchar(500) NOT NULL DEFAULT '',
+    days int NOT NULL DEFAULT 14 COMMENT '可预约今天起多少天内的时段',
+    hold_minutes int NOT NULL DEFAULT 10 COMMENT '预约后保留多少分钟等待确认',
+    cancel_before int NOT NULL DEFAULT 0 COMMENT '开始前多少分钟内不可取消已确认的预约',
+    remind_before int NOT NULL DEFAULT 0 COMMENT '开始前多少分钟提醒，0不提醒',
+    status tinyint NOT NULL DEFAULT 1 COMMENT 'closed(0),open(1)',
+    operator varchar(30) NOT NULL DEFAULT '',
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='预约资源';
+
+CREATE TABLE `booking_template` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    resource_id int NOT NULL,
+    weekday tinyint NOT NULL COMMENT '0为周日',
+    `start` char(5) NOT NULL COMMENT '15:04',
+    `end` char(5) NOT NULL,
+    capacity int NOT NULL COMMENT '名额',
+    KEY (resource_id, weekday)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='预约资源每周的时段模板';
+
+CREATE TABLE `booking` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    user_id int NOT NULL,
+    resource_id int NOT NULL,
+    template_id int NOT NULL,
+    slot_start datetime NOT NULL,
+    slot_end datetime NOT NULL,
+    status tinyint NOT NULL DEFAULT 0 COMMENT 'expired(-2),cancelled(-1),held(0),confirmed(1)',
+    hold_expire datetime NOT NULL COMMENT '保留到期时间',
+    remark varchar(200) NOT NULL DEFAULT '',
+    cancel_reason varchar(200) NOT NULL DEFAULT '',
+    operator varchar(30) NOT NULL DEFAULT '' COMMENT '取消的操作人',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY (resource_id, slot_start),
+    KEY (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='预约记录，名额在redis中占用';
+
+CREATE TABLE `user_checkin` (
+    user_id int PRIMARY KEY,
+    last_date char(10) NOT NULL DEFAULT '' COMMENT '上次签到日，用户当时所在时区的日期',
+    last_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    timezone varchar(40) NOT NULL DEFAULT '' COMMENT '上次签到的时区',
+    streak int NOT NULL DEFAULT 0 COMMENT '截至上次签到的连续天数',
+    total int NOT NULL DEFAULT 0,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户签到汇总';
+
+CREATE TABLE `user_checkin_log` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    user_id int NOT NULL,
+    date char(10) NOT NULL COMMENT '签到日 2006-01-02',
+    timezone varchar(40) NOT NULL DEFAULT '',
+    streak int NOT NULL COMMENT '含当天的连续天数',
+    points bigint NOT NULL DEFAULT 0 COMMENT '奖励的积分',
+    rewarded tinyint NOT NULL DEFAULT 0 COMMENT '积分已记账',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY (user_id, date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='每日签到记录';
+
+CREATE TABLE `lottery` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    name varchar(50) NOT NULL,
+    description varchar(1000) NOT NULL DEFAULT '',
+    begin_time bigint NOT NULL,
+    end_time bigint NOT NULL,
+    daily_limit int NOT NULL DEFAULT 0 COMMENT '每人每天可抽次数，0不限',
+    total_limit int NOT NULL DEFAULT 0 COMMENT '每人活动期间可抽次数，0不限',
+    status tinyint NOT NULL DEFAULT -1 COMMENT 'off(-1),on(1)',
+    operator varchar(30) NOT NULL DEFAULT '',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='抽奖活动';
+
+CREATE TABLE `lottery_prize` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    lottery_id int NOT NULL,
+    name varchar(50) NOT NULL,
+    image varchar(255) NOT NULL DEFAULT '',
+    type tinyint NOT NULL COMMENT 'points(1),goods(2)',
+    value bigint NOT NULL DEFAULT 0 COMMENT '积分奖品的积分数',
+    rate int NOT NULL COMMENT '中奖概率，万分比',
+    stock int NOT NULL COMMENT '总库存',
+    KEY (lottery_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='抽奖奖品，剩余库存在redis中扣减';
+
+CREATE TABLE `lottery_draw` (
+    id int AUTO_INCREMENT PRIMARY KEY,
+    lottery_id int NOT NULL,
+    user_id int NOT NULL,
+    prize_id int NOT NULL DEFAULT 0 COMMENT '0为未中奖',
+    prize_name varchar(50) NOT NULL DEFAULT '',
+    prize_type tinyint NOT NULL DEFAULT 0,
+    value bigint NOT NULL DEFAULT 0,
+    status tinyint NOT NULL DEFAULT 0 COMMENT 'missed(0),pending(1),issued(2)',
+    operator varchar(30) NOT NULL DEFAULT '' COMMENT '实物奖品的发放操作人',
+    create_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY (lottery_id, prize_id),
+    KEY (user_id, lottery_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='抽奖记录';
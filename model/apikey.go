@@ -0,0 +1,43 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// APIKey 合作方调用凭证，请求头X-Api-Key
+type APIKey struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Key        string    `json:"key"`
+	DailyQuota int64     `json:"daily_quota"` // 每日全部接口调用上限，0不限
+	Status     int8      `json:"status"`
+	CreateTime time.Time `json:"create_time" gorm:"->"`
+}
+
+func (*APIKey) TableName() string {
+	return "api_key"
+}
+
+// APIUsage 按日、调用方、路由汇总的调用次数，由script定时从redis回写
+type APIUsage struct {
+	ID      int    `json:"-"`
+	Date    int    `json:"date"`    // 20060102
+	Subject string `json:"subject"` // key:id|user:id
+	Route   string `json:"route"`   // method+path，*为该调用方全部路由
+	Count   int64  `json:"count"`
+}
+
+func (*APIUsage) TableName() string {
+	return "api_usage"
+}
+
+const UsageAllRoutes = "*"
+
+func KeySubject(id int) string {
+	return "key:" + strconv.Itoa(id)
+}
+
+func UserSubject(id int) string {
+	return "user:" + strconv.Itoa(id)
+}
@@ -0,0 +1,8 @@
+package model
+
+// AppVersion 客户端最低版本，低于该版本的请求返回426要求升级
+type AppVersion struct {
+	Platform string `json:"platform"` // ios|android
+	Min      string `json:"min"`      // 最低版本，如 2.3.0
+	URL      string `json:"url"`      // 应用商店下载地址
+}
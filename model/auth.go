@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// 登录凭证生命周期事件，api投递到TopicAuth，script的auth:audit写入auth_event供查询
+
+const (
+	AuthTokenIssued    = "token_issued"    // 登录签发
+	AuthTokenRefreshed = "token_refreshed" // 换发新token，旧token同时吊销
+	AuthTokenRevoked   = "token_revoked"   // 退出登录
+	AuthLoginFailed    = "login_failed"    // 登录失败，未识别用户时user_id为0
+	AuthNewDevice      = "new_device"      // 从未登录过的设备或地区登录，reason为device|region
+)
+
+type AuthEvent struct {
+	ID         int       `json:"-"`
+	EventID    string    `json:"event_id"` // 消费去重
+	Type       string    `json:"type"`
+	UserID     int       `json:"user_id"`
+	TokenHash  string    `json:"token_hash"` // token的sha256前16位，不记录原文
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	Reason     string    `json:"reason"`
+	TraceID    string    `json:"trace_id"`
+	EventTime  int64     `json:"event_time"` // 毫秒
+	CreateTime time.Time `json:"-" gorm:"->"`
+}
+
+func (*AuthEvent) TableName() string {
+	return "auth_event"
+}
+
+// UserDevice 用户登录过的设备，按指纹区分，登录时更新最近登录的IP和地区
+type UserDevice struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"-"`
+	Fingerprint string    `json:"-"`
+	DeviceID    string    `json:"device_id"`
+	Platform    string    `json:"platform"`
+	Region      string    `json:"region"` // 国家/省份，未解析时为空
+	IP          string    `json:"ip"`
+	UserAgent   string    `json:"user_agent"`
+	FirstTime   time.Time `json:"first_time" gorm:"->"`
+	LastTime    time.Time `json:"last_time"`
+}
+
+func (*UserDevice) TableName() string {
+	return "user_device"
+}
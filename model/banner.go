@@ -1,16 +1,23 @@
 package model
 
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
 type Banner struct {
-	ID        int    `json:"id"`
-	City      string `json:"city"`
-	Title     string `json:"title"`
-	Img       string `json:"img"`
-	Type      int8   `json:"type"`
-	Link      string `json:"link"`
-	Sort      int8   `json:"sort"`
-	BeginTime int64  `json:"begin_time"`
-	EndTime   int64  `json:"end_time"`
-	Status    int8   `json:"status"`
+	ID         int            `json:"id"`
+	City       string         `json:"city"`
+	Title      string         `json:"title"`
+	Img        string         `json:"img"`
+	Type       int8           `json:"type"`
+	Link       string         `json:"link"`
+	Sort       int8           `json:"sort"`
+	BeginTime  int64          `json:"begin_time"`
+	EndTime    int64          `json:"end_time"`
+	Status     int8           `json:"status"`
+	UpdateTime time.Time      `json:"update_time" gorm:"->"` // 只读
+	DeleteTime gorm.DeletedAt `json:"-"`                     // 软删除，同时作为增量同步的墓碑
 }
 
 func (*Banner) TableName() string {
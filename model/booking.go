@@ -0,0 +1,113 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+/*
+预约：资源(如门店的服务、场地)按星期配置时段模板和名额，用户选择日期和时段预约
+名额在redis中占用(BookingSlotKey，lua脚本原子判断)，数据库为准，key不存在时按数据库中有效的预约初始化
+预约后先保留holdMinutes分钟，确认后生效；保留到期由script的booking:expire关闭，到期前提醒由booking:remind发送，均为延迟消息
+*/
+
+const (
+	BookingHeld      int8 = 0  // 保留中，到期未确认释放名额
+	BookingConfirmed int8 = 1  // 已确认
+	BookingCancelled int8 = -1 // 用户或后台取消
+	BookingExpired   int8 = -2 // 保留到期未确认
+)
+
+// BookingConfirmedScore 已确认的预约和初始化标记在名额zset中的score，大于任何保留到期毫秒数
+const BookingConfirmedScore int64 = 1 << 52
+
+const (
+	BookingResourceClosed int8 = 0 // 停止预约，已有的预约不受影响
+	BookingResourceOpen   int8 = 1
+)
+
+type BookingResource struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Days         int       `json:"days"`          // 可预约今天起多少天内的时段
+	HoldMinutes  int       `json:"hold_minutes"`  // 预约后保留多少分钟等待确认
+	CancelBefore int       `json:"cancel_before"` // 开始前多少分钟内不可取消已确认的预约，0为开始前都可取消
+	RemindBefore int       `json:"remind_before"` // 开始前多少分钟提醒，0不提醒
+	Status       int8      `json:"status"`
+	Operator     string    `json:"operator"`
+	UpdateTime   time.Time `json:"update_time" gorm:"->"`
+}
+
+func (*BookingResource) TableName() string {
+	return "booking_resource"
+}
+
+// BookingTemplate 每周weekday的一个时段，修改后只影响之后的预约
+type BookingTemplate struct {
+	ID         int    `json:"id"`
+	ResourceID int    `json:"resource_id"`
+	Weekday    int8   `json:"weekday"` // 0为周日
+	Start      string `json:"start"`   // 15:04
+	End        string `json:"end"`
+	Capacity   int    `json:"capacity"`
+}
+
+func (*BookingTemplate) TableName() string {
+	return "booking_template"
+}
+
+// Slot 模板在date当天的起止时间，date需与模板的星期一致
+func (t *BookingTemplate) Slot(date time.Time) (time.Time, time.Time) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return day.Add(clockOffset(t.Start)), day.Add(clockOffset(t.End))
+}
+
+func clockOffset(s string) time.Duration {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
+
+type Booking struct {
+	ID           int       `json:"id"`
+	UserID       int       `json:"user_id"`
+	ResourceID   int       `json:"resource_id"`
+	TemplateID   int       `json:"template_id"`
+	SlotStart    time.Time `json:"slot_start"`
+	SlotEnd      time.Time `json:"slot_end"`
+	Status       int8      `json:"status"`
+	HoldExpire   time.Time `json:"hold_expire"` // 精确到秒，与名额zset中的score一致
+	Remark       string    `json:"remark"`
+	CancelReason string    `json:"cancel_reason"`
+	Operator     string    `json:"operator"` // 取消的操作人，user:uid或admin:id
+	CreateTime   time.Time `json:"create_time" gorm:"->"`
+	UpdateTime   time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+func (*Booking) TableName() string {
+	return "booking"
+}
+
+// Effective 按保留到期时间修正状态，script关闭之前到期的保留显示为已过期
+func (b *Booking) Effective(now time.Time) int8 {
+	if b.Status == BookingHeld && !b.HoldExpire.After(now) {
+		return BookingExpired
+	}
+	return b.Status
+}
+
+// SlotScore 预约在名额zset中的score，释放名额时只移除仍属于本预约的成员
+func (b *Booking) SlotScore() int64 {
+	if b.Status == BookingConfirmed {
+		return BookingConfirmedScore
+	}
+	return b.HoldExpire.UnixMilli()
+}
+
+// BookingMsgID 预约通知的推送ID，重复投递时push:send去重
+func BookingMsgID(id int, kind string) string {
+	return "b" + strconv.Itoa(id) + "-" + kind
+}
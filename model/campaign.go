@@ -0,0 +1,85 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// 运营群发：cms创建活动并指定人群，script的campaign:run到发送时间后按速率分批投递到TopicPush
+
+const (
+	CampaignAudienceSegment = "segment" // 按条件筛选用户
+	CampaignAudienceList    = "list"    // 上传的用户ID，保存在campaign_audience
+	CampaignAudienceSaved   = "saved"   // 已保存的用户分群，使用发送时最近一次计算的成员
+)
+
+const (
+	CampaignCanceled  int8 = -1
+	CampaignScheduled int8 = 0
+	CampaignRunning   int8 = 1
+	CampaignDone      int8 = 2
+)
+
+type Campaign struct {
+	ID         int              `json:"id"`
+	Name       string           `json:"name"`
+	Category   string           `json:"category"` // marketing|reminder，按用户的推送偏好过滤
+	Audience   string           `json:"audience"`
+	Segment    *CampaignSegment `json:"segment"`    // audience为segment时的筛选条件
+	SegmentID  int              `json:"segment_id"` // audience为saved时的分群
+	Title      string           `json:"title"`
+	Body       string           `json:"body"`
+	Data       JsonMapString    `json:"data"`
+	Wechat     *PushWechat      `json:"wechat"` // 订阅消息模板，为空只发app推送
+	SendTime   int64            `json:"send_time"`
+	Rate       int              `json:"rate"` // 每秒投递数
+	Status     int8             `json:"status"`
+	Total      int64            `json:"total"` // 开始发送时统计的人数
+	Sent       int64            `json:"sent"`  // 已投递到推送队列的人数
+	Cursor     int              `json:"-"`     // 已投递的最大用户ID，中断后从此继续
+	Operator   string           `json:"operator"`
+	CreateTime time.Time        `json:"create_time" gorm:"->"`
+	UpdateTime time.Time        `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+func (*Campaign) TableName() string {
+	return "campaign"
+}
+
+// CampaignSegment 人群条件，零值不限制
+type CampaignSegment struct {
+	HasPhone   bool  `json:"has_phone"`   // 已授权手机号
+	HasDevice  bool  `json:"has_device"`  // 已登记app推送设备
+	JoinAfter  int64 `json:"join_after"`  // 注册时间晚于
+	JoinBefore int64 `json:"join_before"` // 注册时间早于
+}
+
+func (v *CampaignSegment) Scan(value any) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), v)
+}
+
+func (v *CampaignSegment) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+type CampaignAudience struct {
+	CampaignID int `json:"campaign_id" gorm:"primaryKey"`
+	UserID     int `json:"user_id" gorm:"primaryKey"`
+}
+
+func (*CampaignAudience) TableName() string {
+	return "campaign_audience"
+}
+
+// CampaignMsgID 同一活动同一用户的推送ID固定，便于按msg_id查询发送结果
+func CampaignMsgID(campaignID, uid int) string {
+	return "c" + strconv.Itoa(campaignID) + "-" + strconv.Itoa(uid)
+}
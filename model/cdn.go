@@ -0,0 +1,11 @@
+package model
+
+// cdn缓存标签，源站在响应头Cache-Tag中返回，内容变更后按标签刷新
+const (
+	CdnTagBanner = "banner"
+)
+
+// CdnTagContent 单个运营内容的标签
+func CdnTagContent(key string) string {
+	return "content-" + key
+}
@@ -0,0 +1,10 @@
+package model
+
+// ChaosRule 故障注入规则，仅用于预发环境验证客户端重试和熔断
+type ChaosRule struct {
+	Route   string `json:"route"`   // method+path，如 GET/example/banners
+	Percent int    `json:"percent"` // 命中概率 0~100
+	Latency int    `json:"latency"` // 注入延迟(毫秒)
+	Status  int    `json:"status"`  // 注入错误码，0表示不注入错误
+	Drop    bool   `json:"drop"`    // 直接断开连接不返回响应
+}
@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// 即时消息：会话成员在chat_member，消息按会话内递增的seq保存在chat_message；
+// 在线成员由WebSocket网关消费chat主题投递，离线成员走消息推送，客户端重连后按seq拉取缺失的消息
+
+const (
+	ChatSingle int8 = 1 // 单聊，两人之间唯一
+	ChatGroup  int8 = 2 // 群聊
+)
+
+const ChatGroupMax = 200 // 群聊成员上限
+
+const (
+	ChatMsgText  = "text"
+	ChatMsgImage = "image" // content为图片的对象路径
+)
+
+// 网关投递的事件
+const (
+	ChatEventMessage = "message" // 新消息
+	ChatEventRead    = "read"    // 已读回执
+)
+
+type ChatConversation struct {
+	ID         int       `json:"id"`
+	Type       int8      `json:"type"`
+	Name       string    `json:"name"`     // 群聊名称
+	PairKey    *string   `json:"-"`        // 单聊为较小uid:较大uid，唯一；群聊为空
+	OwnerID    int       `json:"owner_id"` // 创建者
+	LastSeq    int64     `json:"last_seq"` // 最新消息的seq
+	CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	UpdateTime time.Time `json:"update_time" gorm:"autoUpdateTime"` // 最后一条消息的时间
+}
+
+func (*ChatConversation) TableName() string {
+	return "chat_conversation"
+}
+
+type ChatMember struct {
+	ConversationID int       `json:"conversation_id" gorm:"primaryKey;autoIncrement:false"`
+	UserID         int       `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	ReadSeq        int64     `json:"read_seq"` // 已读到的seq，只增不减
+	JoinTime       time.Time `json:"join_time" gorm:"autoCreateTime"`
+}
+
+func (*ChatMember) TableName() string {
+	return "chat_member"
+}
+
+type ChatMessage struct {
+	ID             int64     `json:"id"`
+	ConversationID int       `json:"conversation_id"`
+	Seq            int64     `json:"seq"`
+	SenderID       int       `json:"sender_id"`
+	ClientMsgID    string    `json:"client_msg_id"` // 客户端生成，同一发送者唯一，重发时返回已保存的消息
+	Type           string    `json:"type"`
+	Content        string    `json:"content"`
+	CreateTime     time.Time `json:"create_time" gorm:"autoCreateTime"`
+}
+
+func (*ChatMessage) TableName() string {
+	return "chat_message"
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+/*
+每日签到：签到日按用户所在时区计算(客户端传IANA时区名，未传使用配置的默认时区)，每个签到日一条记录
+连续签到：今天在当前时区或上次签到的时区是上次签到日的次日时连续，跨时区旅行不会中断；
+切换时区后需上次签到的时区也进入新的一天或距上次签到满CheckinMinGap，避免切换时区一天签到多次
+奖励的积分记入ledger，以用户和签到日期作为幂等号，记账失败时重复签到补发
+*/
+
+const CheckinDateLayout = "2006-01-02"
+
+// CheckinMinGap 切换时区后两次签到的最小间隔
+const CheckinMinGap = 20 * time.Hour
+
+// UserCheckin 用户签到汇总，签到时锁定此行
+type UserCheckin struct {
+	UserID     int       `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	LastDate   string    `json:"last_date"` // 上次签到日，用户当时所在时区的日期
+	LastTime   time.Time `json:"last_time"`
+	Timezone   string    `json:"timezone"` // 上次签到的时区
+	Streak     int       `json:"streak"`   // 截至上次签到的连续天数
+	Total      int       `json:"total"`
+	UpdateTime time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+func (*UserCheckin) TableName() string {
+	return "user_checkin"
+}
+
+type CheckinLog struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id"`
+	Date       string    `json:"date"`
+	Timezone   string    `json:"timezone"`
+	Streak     int       `json:"streak"`   // 含当天的连续天数
+	Points     int64     `json:"points"`   // 奖励的积分
+	Rewarded   bool      `json:"rewarded"` // 积分已记账
+	CreateTime time.Time `json:"create_time" gorm:"->"`
+}
+
+func (*CheckinLog) TableName() string {
+	return "user_checkin_log"
+}
+
+// Due 今天(date为当前时区的签到日)可以签到：晚于上次签到日，切换时区时上次签到的时区last也需进入新的一天或间隔满CheckinMinGap
+func (c *UserCheckin) Due(date string, now time.Time, last *time.Location) bool {
+	if c.LastDate == "" {
+		return true
+	}
+	if date <= c.LastDate {
+		return false
+	}
+	return CheckinDate(now, last) > c.LastDate || now.Sub(c.LastTime) >= CheckinMinGap
+}
+
+// Continued 当前时区或上次签到的时区last是上次签到日的次日时连续签到
+func (c *UserCheckin) Continued(date string, now time.Time, last *time.Location) bool {
+	next := CheckinNextDate(c.LastDate)
+	return next != "" && (date == next || CheckinDate(now, last) == next)
+}
+
+// CheckinRefNo 签到奖励的记账幂等号，系统账户的对手分录共用ref_no，需包含uid
+func CheckinRefNo(uid int, date string) string {
+	return "checkin:" + strconv.Itoa(uid) + ":" + date
+}
+
+// CheckinDate t在loc时区的签到日
+func CheckinDate(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(CheckinDateLayout)
+}
+
+// CheckinNextDate date的次日，date格式错误时返回空
+func CheckinNextDate(date string) string {
+	t, err := time.Parse(CheckinDateLayout, date)
+	if err != nil {
+		return ""
+	}
+	return t.AddDate(0, 0, 1).Format(CheckinDateLayout)
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Content 运营配置内容，cms编辑draft，发布时复制到body并增加版本，api只返回已发布的body
+type Content struct {
+	ID          int             `json:"id"`
+	Key         string          `json:"key"`
+	Name        string          `json:"name"`
+	Draft       json.RawMessage `json:"draft"`
+	Body        json.RawMessage `json:"body"`
+	Version     int             `json:"version"`
+	PublishTime int64           `json:"publish_time"`
+	Operator    string          `json:"operator"`
+	UpdateTime  time.Time       `json:"update_time" gorm:"->"` // 只读
+}
+
+func (*Content) TableName() string {
+	return "content"
+}
+
+// ETag 按标识和发布版本生成，内容不变时客户端和cdn可用If-None-Match校验
+func (c *Content) ETag() string {
+	return `"` + c.Key + "-" + strconv.Itoa(c.Version) + `"`
+}
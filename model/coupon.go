@@ -0,0 +1,87 @@
+package model
+
+import "time"
+
+const (
+	CouponFixed   int8 = 1 // 满减，value为减免金额(分)
+	CouponPercent int8 = 2 // 折扣，value为折后比例(1~99)
+)
+
+const (
+	CouponRevoked int8 = -1 // 已作废
+	CouponUnused  int8 = 1
+	CouponUsed    int8 = 2
+)
+
+type CouponTemplate struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Type        int8      `json:"type"`
+	Value       int64     `json:"value"`
+	Threshold   int64     `json:"threshold"`    // 使用门槛(分)，0无门槛
+	MaxDiscount int64     `json:"max_discount"` // 折扣券最高减免(分)，0不限
+	Stock       int       `json:"stock"`        // 发放总量
+	PerUser     int       `json:"per_user"`     // 每人限领，0不限
+	BeginTime   int64     `json:"begin_time"`   // 领取开始时间
+	EndTime     int64     `json:"end_time"`     // 领取结束时间
+	ValidDays   int       `json:"valid_days"`   // 领取后有效天数，0则使用固定有效期
+	UseBegin    int64     `json:"use_begin"`
+	UseEnd      int64     `json:"use_end"`
+	Status      int8      `json:"status"`
+	CreateTime  time.Time `json:"create_time" gorm:"->"`
+}
+
+func (*CouponTemplate) TableName() string {
+	return "coupon_template"
+}
+
+// Discount 订单金额可减免的金额，未达门槛返回0
+func (t *CouponTemplate) Discount(amount int64) int64 {
+	if amount <= 0 || amount < t.Threshold {
+		return 0
+	}
+	var d int64
+	switch t.Type {
+	case CouponFixed:
+		d = t.Value
+	case CouponPercent:
+		d = amount * (100 - t.Value) / 100
+		if t.MaxDiscount > 0 && d > t.MaxDiscount {
+			d = t.MaxDiscount
+		}
+	}
+	if d > amount {
+		d = amount
+	}
+	return d
+}
+
+// Validity 按领取时间计算有效期
+func (t *CouponTemplate) Validity(claim time.Time) (begin, end int64) {
+	if t.ValidDays > 0 {
+		return claim.Unix(), claim.AddDate(0, 0, t.ValidDays).Unix()
+	}
+	return t.UseBegin, t.UseEnd
+}
+
+// Usable 未使用且在有效期内
+func (c *Coupon) Usable(now int64) bool {
+	return c.Status == CouponUnused && now >= c.BeginTime && now < c.EndTime && c.Template != nil
+}
+
+type Coupon struct {
+	ID         int             `json:"id"`
+	TemplateID int             `json:"template_id"`
+	UserID     int             `json:"user_id"`
+	Status     int8            `json:"status"`
+	BeginTime  int64           `json:"begin_time"` // 有效期
+	EndTime    int64           `json:"end_time"`
+	OrderNo    string          `json:"order_no"`
+	UseTime    int64           `json:"use_time"`
+	CreateTime time.Time       `json:"create_time" gorm:"->"`
+	Template   *CouponTemplate `json:"template,omitempty" gorm:"foreignKey:TemplateID"`
+}
+
+func (*Coupon) TableName() string {
+	return "coupon"
+}
@@ -60,3 +60,20 @@ func (v BoolString) Value() (driver.Value, error) {
 	}
 	return s, nil // receiver不能为指针
 }
+
+type JsonMapString map[string]string
+
+func (v *JsonMapString) Scan(value any) error {
+	if value == nil {
+		return nil
+	}
+	b := value.([]byte)
+	return json.Unmarshal(b, v) // receiver必须为指针
+}
+
+func (v JsonMapString) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte{'{', '}'}, nil
+	}
+	return json.Marshal(v) // receiver不能为指针
+}
@@ -16,3 +16,13 @@ var Cities = map[string]string{
 	"440100": "广州",
 	"440300": "深圳",
 }
+
+// TombstoneDays 软删除记录至少保留的天数：增量同步靠软删除的行下发deleted_ids，
+// script按purgeDays物理删除时不少于该值，api对更早的同步游标要求客户端全量重建
+const TombstoneDays = 30
+
+// SoftDeleteTables 使用delete_time软删除的表，cms回收站和script定时清理共用
+var SoftDeleteTables = map[string]string{
+	"banner":     "轮播图",
+	"experiment": "AB实验",
+}
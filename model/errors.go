@@ -0,0 +1,87 @@
+package model
+
+import (
+	"net/http"
+	"project/pkg/wechat"
+)
+
+// BizError 业务错误，service返回后handler的RespWithErr按Status响应，Code作为detail返回给客户端判断
+type BizError struct {
+	Status int
+	Code   string
+	Msg    string
+}
+
+func (e *BizError) Error() string {
+	return e.Msg
+}
+
+var (
+	ErrCouponNotFound = &BizError{Status: http.StatusNotFound, Code: "COUPON_NOT_FOUND", Msg: "优惠券不存在"}
+	ErrCouponNotStart = &BizError{Status: http.StatusForbidden, Code: "COUPON_NOT_START", Msg: "不在领取时间内"}
+	ErrCouponSoldOut  = &BizError{Status: http.StatusConflict, Code: "COUPON_SOLD_OUT", Msg: "优惠券已领完"}
+	ErrCouponLimit    = &BizError{Status: http.StatusConflict, Code: "COUPON_LIMIT", Msg: "已达到领取上限"}
+	ErrCouponInvalid  = &BizError{Status: http.StatusUnprocessableEntity, Code: "COUPON_INVALID", Msg: "优惠券已使用或不在有效期内"}
+	ErrCouponUnmet    = &BizError{Status: http.StatusUnprocessableEntity, Code: "COUPON_UNMET", Msg: "订单金额未达到使用门槛"}
+	ErrCouponOrder    = &BizError{Status: http.StatusConflict, Code: "COUPON_ORDER", Msg: "订单已发起支付或已使用其他优惠券"}
+
+	ErrStockNotEnough = &BizError{Status: http.StatusConflict, Code: "STOCK_NOT_ENOUGH", Msg: "库存不足"}
+	ErrStockNotReady  = &BizError{Status: http.StatusServiceUnavailable, Code: "STOCK_NOT_READY", Msg: "商品暂未开售"}
+
+	ErrExchangeNotFound = &BizError{Status: http.StatusNotFound, Code: "EXCHANGE_NOT_FOUND", Msg: "该商品不支持积分兑换"}
+
+	ErrContentNotFound = &BizError{Status: http.StatusNotFound, Code: "CONTENT_NOT_FOUND", Msg: "内容不存在"}
+
+	ErrCartFull = &BizError{Status: http.StatusConflict, Code: "CART_FULL", Msg: "购物车已满"}
+
+	ErrBalanceNotEnough = &BizError{Status: http.StatusConflict, Code: "BALANCE_NOT_ENOUGH", Msg: "余额不足"}
+
+	ErrExportTooLarge = &BizError{Status: http.StatusUnprocessableEntity, Code: "EXPORT_TOO_LARGE", Msg: "导出数据过多，请缩小筛选范围"}
+
+	ErrStoreNotFound = &BizError{Status: http.StatusNotFound, Code: "STORE_NOT_FOUND", Msg: "门店不存在或已停业"}
+
+	ErrChatNotMember = &BizError{Status: http.StatusForbidden, Code: "CHAT_NOT_MEMBER", Msg: "不是会话成员"}
+	ErrChatUser      = &BizError{Status: http.StatusUnprocessableEntity, Code: "CHAT_USER_INVALID", Msg: "会话成员不存在"}
+
+	ErrAddressNotFound = &BizError{Status: http.StatusNotFound, Code: "ADDRESS_NOT_FOUND", Msg: "收货地址不存在"}
+	ErrAddressRegion   = &BizError{Status: http.StatusUnprocessableEntity, Code: "ADDRESS_REGION_INVALID", Msg: "所在地区已调整，请重新选择"}
+	ErrAddressLimit    = &BizError{Status: http.StatusConflict, Code: "ADDRESS_LIMIT", Msg: "收货地址最多20个"}
+
+	ErrBookingResource  = &BizError{Status: http.StatusNotFound, Code: "BOOKING_RESOURCE_NOT_FOUND", Msg: "预约项目不存在或已停止预约"}
+	ErrBookingSlot      = &BizError{Status: http.StatusUnprocessableEntity, Code: "BOOKING_SLOT_INVALID", Msg: "该时段不可预约"}
+	ErrBookingFull      = &BizError{Status: http.StatusConflict, Code: "BOOKING_FULL", Msg: "该时段已约满"}
+	ErrBookingDuplicate = &BizError{Status: http.StatusConflict, Code: "BOOKING_DUPLICATE", Msg: "已预约该时段"}
+	ErrBookingNotFound  = &BizError{Status: http.StatusNotFound, Code: "BOOKING_NOT_FOUND", Msg: "预约不存在"}
+	ErrBookingExpired   = &BizError{Status: http.StatusConflict, Code: "BOOKING_EXPIRED", Msg: "预约已超时，请重新预约"}
+	ErrBookingStatus    = &BizError{Status: http.StatusConflict, Code: "BOOKING_STATUS", Msg: "预约当前状态不可操作"}
+	ErrBookingCancel    = &BizError{Status: http.StatusConflict, Code: "BOOKING_CANCEL_CLOSED", Msg: "已超过可取消的时间"}
+
+	ErrLotteryNotFound = &BizError{Status: http.StatusNotFound, Code: "LOTTERY_NOT_FOUND", Msg: "活动不存在"}
+	ErrLotteryClosed   = &BizError{Status: http.StatusForbidden, Code: "LOTTERY_CLOSED", Msg: "不在活动时间内"}
+	ErrLotteryDaily    = &BizError{Status: http.StatusConflict, Code: "LOTTERY_DAILY_LIMIT", Msg: "今天的抽奖次数已用完"}
+	ErrLotteryLimit    = &BizError{Status: http.StatusConflict, Code: "LOTTERY_LIMIT", Msg: "抽奖次数已用完"}
+	ErrLotteryStarted  = &BizError{Status: http.StatusConflict, Code: "LOTTERY_STARTED", Msg: "活动已开始，不可修改奖品"}
+	ErrLotteryDraw     = &BizError{Status: http.StatusConflict, Code: "LOTTERY_DRAW_STATUS", Msg: "中奖记录不是待发放状态"}
+
+	ErrExperimentDeleted = &BizError{Status: http.StatusConflict, Code: "EXPERIMENT_DELETED", Msg: "实验标识已被删除的实验使用，请在回收站恢复"}
+
+	ErrGuestLimit = &BizError{Status: http.StatusTooManyRequests, Code: "GUEST_LIMIT", Msg: "请求过于频繁，请稍后再试"}
+
+	ErrCaptchaKind    = &BizError{Status: http.StatusBadRequest, Code: "CAPTCHA_KIND", Msg: "不支持的验证码类型"}
+	ErrCaptchaExpired = &BizError{Status: http.StatusUnprocessableEntity, Code: "CAPTCHA_EXPIRED", Msg: "验证码已失效，请刷新"}
+	ErrCaptchaWrong   = &BizError{Status: http.StatusUnprocessableEntity, Code: "CAPTCHA_WRONG", Msg: "验证未通过，请重试"}
+
+	ErrWechatCode  = &BizError{Status: http.StatusUnprocessableEntity, Code: "WECHAT_CODE_INVALID", Msg: "授权已失效，请重试"}
+	ErrWechatLimit = &BizError{Status: http.StatusServiceUnavailable, Code: "WECHAT_LIMIT", Msg: "服务繁忙，请稍后再试"}
+	ErrWechatRisky = &BizError{Status: http.StatusUnprocessableEntity, Code: "CONTENT_RISKY", Msg: "内容含有违规信息，请修改后重试"}
+)
+
+// WechatErrs 微信接口错误码对应的业务错误，RespWithErr按此响应*wechat.APIError，未列出的按上游错误处理
+var WechatErrs = map[int]*BizError{
+	wechat.ErrcodeInvalidCode:  ErrWechatCode,
+	wechat.ErrcodeCodeUsed:     ErrWechatCode,
+	wechat.ErrcodeRateLimit:    ErrWechatLimit,
+	45011:                      ErrWechatLimit, // 每分钟频率限制
+	45047:                      ErrWechatLimit, // 客服消息下发条数超过限制
+	wechat.ErrcodeRiskyContent: ErrWechatRisky,
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"gorm.io/gorm"
+)
+
+type Experiment struct {
+	ID         int                `json:"id"`
+	Key        string             `json:"key"`
+	Name       string             `json:"name"`
+	Traffic    int                `json:"traffic"` // 进入实验的流量比例，万分比
+	Variants   ExperimentVariants `json:"variants"`
+	BeginTime  int64              `json:"begin_time"`
+	EndTime    int64              `json:"end_time"`
+	Status     int8               `json:"status"`
+	Segment    string             `json:"segment"` // 分群code，为空时面向全部用户
+	DeleteTime gorm.DeletedAt     `json:"-"`
+}
+
+func (*Experiment) TableName() string {
+	return "experiment"
+}
+
+type ExperimentVariant struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"` // 组内权重
+}
+
+type ExperimentVariants []*ExperimentVariant
+
+func (v *ExperimentVariants) Scan(value any) error {
+	if value == nil {
+		return nil
+	}
+	b := value.([]byte)
+	return json.Unmarshal(b, v) // receiver必须为指针
+}
+
+func (v ExperimentVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte{'[', ']'}, nil
+	}
+	return json.Marshal(v) // receiver不能为指针
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+const (
+	AssetPoints  = "points"
+	AssetBalance = "balance" // 单位分
+)
+
+// LedgerSystemUser 系统账户(发放方/回收方)，所有分录的对手方
+// 系统账户不落余额行，避免所有记账争抢同一行锁，其余额为全部用户余额之和的相反数
+const LedgerSystemUser = 0
+
+// LedgerAccount 用户资产账户，balance为所有分录amount之和
+type LedgerAccount struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id"`
+	Asset      string    `json:"asset"`
+	Balance    int64     `json:"balance"`
+	UpdateTime time.Time `json:"update_time" gorm:"->"`
+}
+
+func (*LedgerAccount) TableName() string {
+	return "ledger_account"
+}
+
+// LedgerEntry 记账分录，同一ref_no下用户与系统账户各一条且amount之和为0
+type LedgerEntry struct {
+	ID         int       `json:"id"`
+	RefNo      string    `json:"ref_no"` // 业务幂等号，如订单号；系统账户的分录共用，不同用户的记账不能使用相同的ref_no
+	UserID     int       `json:"user_id"`
+	Asset      string    `json:"asset"`
+	Amount     int64     `json:"amount"`  // 正数入账，负数出账
+	Balance    int64     `json:"balance"` // 记账后余额快照，系统账户为0
+	BizType    string    `json:"biz_type"`
+	Remark     string    `json:"remark"`
+	CreateTime time.Time `json:"create_time" gorm:"->"`
+}
+
+func (*LedgerEntry) TableName() string {
+	return "ledger_entry"
+}
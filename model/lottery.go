@@ -0,0 +1,89 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+/*
+抽奖：cms配置活动和奖品，奖品按万分比中奖，概率之和不足100%的部分为未中奖
+抽奖在lua脚本中原子判断每人次数、按api传入的随机数选中奖品并扣减库存(LotteryStockKey)，选中的奖品已抽完时为未中奖，不改变其他奖品的概率
+库存key不存在(redis数据丢失)时按奖品库存减去已中奖的记录初始化；积分奖品与抽奖记录在同一事务内记入积分账户，实物奖品由后台确认发放
+*/
+
+// LotteryRateBase 奖品中奖概率的基数，rate为万分比
+const LotteryRateBase = 10000
+
+const (
+	LotteryPrizePoints int8 = 1 // 积分，value为积分数
+	LotteryPrizeGoods  int8 = 2 // 实物，后台确认发放
+)
+
+const (
+	LotteryDrawMissed  int8 = 0 // 未中奖
+	LotteryDrawPending int8 = 1 // 待发放
+	LotteryDrawIssued  int8 = 2 // 已发放
+)
+
+type Lottery struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	BeginTime   int64           `json:"begin_time"`
+	EndTime     int64           `json:"end_time"`
+	DailyLimit  int             `json:"daily_limit"` // 每人每天可抽次数，0不限
+	TotalLimit  int             `json:"total_limit"` // 每人活动期间可抽次数，0不限
+	Status      int8            `json:"status"`
+	Operator    string          `json:"operator"`
+	CreateTime  time.Time       `json:"create_time" gorm:"->"`
+	UpdateTime  time.Time       `json:"update_time" gorm:"->"`
+	Prizes      []*LotteryPrize `json:"prizes,omitempty" gorm:"foreignKey:LotteryID"`
+}
+
+func (*Lottery) TableName() string {
+	return "lottery"
+}
+
+// Open 活动已上线且在抽奖时间内
+func (l *Lottery) Open(now int64) bool {
+	return l.Status == StatusOn && now >= l.BeginTime && now < l.EndTime
+}
+
+type LotteryPrize struct {
+	ID        int    `json:"id"`
+	LotteryID int    `json:"lottery_id"`
+	Name      string `json:"name"`
+	Image     string `json:"image"`
+	Type      int8   `json:"type"`
+	Value     int64  `json:"value"` // 积分奖品的积分数
+	Rate      int    `json:"rate"`  // 中奖概率，万分比
+	Stock     int    `json:"stock"` // 总库存
+}
+
+func (*LotteryPrize) TableName() string {
+	return "lottery_prize"
+}
+
+// LotteryDraw 每次抽奖的记录，未中奖的prize_id为0
+type LotteryDraw struct {
+	ID         int       `json:"id"`
+	LotteryID  int       `json:"lottery_id"`
+	UserID     int       `json:"user_id"`
+	PrizeID    int       `json:"prize_id"`
+	PrizeName  string    `json:"prize_name"` // 奖品快照
+	PrizeType  int8      `json:"prize_type"`
+	Value      int64     `json:"value"`
+	Status     int8      `json:"status"`
+	Operator   string    `json:"operator"` // 实物奖品的发放操作人
+	CreateTime time.Time `json:"create_time" gorm:"->"`
+	UpdateTime time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+func (*LotteryDraw) TableName() string {
+	return "lottery_draw"
+}
+
+// LotteryRefNo 积分奖品的记账幂等号
+func LotteryRefNo(drawID int) string {
+	return "lottery:" + strconv.Itoa(drawID)
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// 视频上传和转码：cms上传原始视频到cos并登记为待转码，script的media:transcode认领后转为多码率HLS写回cos，
+// 完成后记录主播放列表；转码中的记录定期更新update_time，超过1分钟未更新说明进程已退出，由任一实例重新认领
+
+const (
+	MediaFailed     int8 = -1
+	MediaPending    int8 = 0
+	MediaProcessing int8 = 1
+	MediaReady      int8 = 2
+)
+
+const (
+	MediaSourcePrefix = "media/source/" // 原始视频，不经cdn分发
+	MediaHLSPrefix    = "media/hls/"    // 转码输出，按media_id分目录
+)
+
+type Media struct {
+	ID         int       `json:"id"`
+	Title      string    `json:"title"`
+	Source     string    `json:"source"` // 原始视频的对象路径
+	Size       int64     `json:"size"`
+	Status     int8      `json:"status"`
+	Playlist   string    `json:"playlist"`   // 主播放列表的对象路径，转码完成后写入
+	Duration   int       `json:"duration"`   // 秒
+	Renditions string    `json:"renditions"` // 实际输出的码率，逗号分隔
+	TaskID     string    `json:"-"`          // 云端转码的任务ID，重新认领后继续查询
+	Attempts   int       `json:"attempts"`
+	Error      string    `json:"error"`
+	Operator   string    `json:"operator"`
+	CreateTime time.Time `json:"create_time" gorm:"->"`
+	UpdateTime time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+func (*Media) TableName() string {
+	return "media"
+}
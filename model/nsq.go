@@ -3,10 +3,75 @@ package model
 // 定义队列的topic和数据结构
 
 const (
-	TopicExample = "example"
+	TopicExample  = "example"
+	TopicExposure = "exposure" // 实验曝光
+	TopicStock    = "stock"    // 库存回写
+	TopicOrder    = "order"    // 订单状态迁移
+	TopicClick    = "click"    // 短链点击，数据结构为shortlink.Click
+	TopicTrack    = "track"    // 业务埋点，数据结构为track.Event
+	TopicAuth     = "auth"     // 登录凭证生命周期，数据结构为AuthEvent
+	TopicPush     = "push"     // 消息推送，数据结构为MsgPush
+	TopicChat     = "chat"     // 即时消息投递，由WebSocket网关消费，数据结构为MsgChat
+
+	TopicOrderTimeout  = "order_timeout"  // 待支付订单超时关闭，延迟投递
+	TopicUserMerge     = "user_merge"     // 同一unionid存在多个用户，数据结构为MsgUserMerge
+	TopicBookingExpire = "booking_expire" // 预约保留到期，延迟投递
+	TopicBookingRemind = "booking_remind" // 预约开始前提醒，延迟投递
 )
 
 type MsgExample struct {
 	UUID   string `json:"uuid"`
 	Number int64  `json:"number"`
 }
+
+type MsgExposure struct {
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+	UserID     int    `json:"user_id"`
+	Openid     string `json:"openid"`
+	TraceID    string `json:"trace_id"`
+	Time       int64  `json:"time"`
+}
+
+type MsgStock struct {
+	SkuID    int    `json:"sku_id"`
+	OrderNo  string `json:"order_no"`
+	Quantity int    `json:"quantity"`
+	Type     int8   `json:"type"` // StockDeduct|StockRestock
+	Time     int64  `json:"time"`
+}
+
+type MsgUserMerge struct {
+	Unionid string `json:"unionid"`
+	Time    int64  `json:"time"`
+}
+
+// MsgChat 网关推送给user_ids在线的连接(含发送者的其他端)，message事件带消息，read事件带已读到的seq
+type MsgChat struct {
+	Event          string       `json:"event"`
+	UserIDs        []int        `json:"user_ids"`
+	ConversationID int          `json:"conversation_id"`
+	Message        *ChatMessage `json:"message,omitempty"`
+	ReaderID       int          `json:"reader_id,omitempty"`
+	Seq            int64        `json:"seq,omitempty"`
+	Time           int64        `json:"time"`
+}
+
+type MsgOrderTimeout struct {
+	OrderNo string `json:"order_no"`
+}
+
+// MsgBooking 延迟到达时按预约的当前状态和开始时间判断是否仍需处理
+type MsgBooking struct {
+	BookingID int   `json:"booking_id"`
+	SlotStart int64 `json:"slot_start"`
+}
+
+type MsgOrder struct {
+	OrderNo  string `json:"order_no"`
+	Event    string `json:"event"`
+	From     int8   `json:"from"`
+	To       int8   `json:"to"`
+	Operator string `json:"operator"`
+	Time     int64  `json:"time"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+const (
+	OrderClosed    int8 = -1 // 已关闭(取消)
+	OrderCreated   int8 = 1  // 待支付
+	OrderPaid      int8 = 2  // 已支付
+	OrderRefunding int8 = 3  // 退款中
+	OrderRefunded  int8 = 4  // 已退款(含部分退款)
+	OrderShipped   int8 = 5  // 已发货
+	OrderCompleted int8 = 6  // 已完成
+	OrderPaidLate  int8 = 7  // 关闭后支付到账，库存和券已退回，不发货，需退款
+)
+
+// OrderPayTimeout 待支付订单超时未支付自动关闭
+const OrderPayTimeout = 15 * time.Minute
+
+// OrderProviderPoints 积分兑换的订单，创建即为已支付，不经过支付渠道
+const OrderProviderPoints = "points"
+
+const (
+	RefundProcessing int8 = 1
+	RefundSuccess    int8 = 2
+	RefundFailed     int8 = -1
+)
+
+type Order struct {
+	ID            int       `json:"id"`
+	OrderNo       string    `json:"order_no"` // 商户订单号out_trade_no
+	UserID        int       `json:"user_id"`
+	Subject       string    `json:"subject"`
+	Amount        int64     `json:"amount"`         // 单位分，使用优惠券后为减免后的应付金额
+	CouponID      int       `json:"coupon_id"`      // 使用的优惠券
+	Discount      int64     `json:"discount"`       // 优惠券减免金额
+	RefundAmount  int64     `json:"refund_amount"`  // 已退款金额
+	Provider      string    `json:"provider"`       // 支付渠道wxpay|alipay
+	TransactionID string    `json:"transaction_id"` // 渠道交易号
+	Status        int8      `json:"status"`
+	PayTime       int64     `json:"pay_time"`
+	CloseTime     int64     `json:"close_time"` // 关闭时间，关闭后到账的订单据此在退款失败时回到OrderPaidLate
+	CreateTime    time.Time `json:"create_time" gorm:"->"`
+}
+
+func (*Order) TableName() string {
+	return "order_info"
+}
+
+type OrderRefund struct {
+	ID          int       `json:"id"`
+	RefundNo    string    `json:"refund_no"` // 商户退款单号out_refund_no
+	OrderNo     string    `json:"order_no"`
+	Amount      int64     `json:"amount"`
+	Reason      string    `json:"reason"`
+	RefundID    string    `json:"refund_id"` // 渠道退款单号
+	Status      int8      `json:"status"`
+	SuccessTime int64     `json:"success_time"`
+	CreateTime  time.Time `json:"create_time" gorm:"->"`
+}
+
+func (*OrderRefund) TableName() string {
+	return "order_refund"
+}
+
+// OrderLog 订单状态迁移审计记录，每次迁移与状态更新在同一事务中写入
+type OrderLog struct {
+	ID         int       `json:"id"`
+	OrderNo    string    `json:"order_no"`
+	Event      string    `json:"event"`
+	FromStatus int8      `json:"from_status"`
+	ToStatus   int8      `json:"to_status"`
+	Operator   string    `json:"operator"` // user:1|admin:1|system
+	Remark     string    `json:"remark"`
+	CreateTime time.Time `json:"create_time" gorm:"->"`
+}
+
+func (*OrderLog) TableName() string {
+	return "order_log"
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+	"project/pkg/fsm"
+	"project/pkg/logger"
+	"time"
+)
+
+const (
+	OrderEventPay           = "pay"
+	OrderEventCancel        = "cancel"
+	OrderEventShip          = "ship"
+	OrderEventComplete      = "complete"
+	OrderEventRefund        = "refund"
+	OrderEventRefundSuccess = "refund_success"
+	OrderEventRefundFail    = "refund_fail"
+)
+
+// NewOrderFSM 订单状态机，钩子由各服务按需注册
+// 关闭时已退回库存和券，关闭后到达的支付回调入账为OrderPaidLate，不能发货，由人工退款处理
+func NewOrderFSM() *fsm.Machine[int8] {
+	noRefund := func(_ context.Context, c *fsm.Change[int8]) error {
+		if o, _ := c.Payload.(*Order); o != nil && o.RefundAmount > 0 {
+			return errors.New("order has refunded amount")
+		}
+		return nil
+	}
+	paidLate := func(ctx context.Context, c *fsm.Change[int8]) error {
+		if o, _ := c.Payload.(*Order); o == nil || o.CloseTime == 0 {
+			return errors.New("order was not closed")
+		}
+		return noRefund(ctx, c)
+	}
+	return fsm.New(
+		&fsm.Transition[int8]{Event: OrderEventPay, From: []int8{OrderCreated}, To: OrderPaid},
+		&fsm.Transition[int8]{Event: OrderEventPay, From: []int8{OrderClosed}, To: OrderPaidLate},
+		&fsm.Transition[int8]{Event: OrderEventCancel, From: []int8{OrderCreated}, To: OrderClosed},
+		&fsm.Transition[int8]{Event: OrderEventShip, From: []int8{OrderPaid}, To: OrderShipped},
+		&fsm.Transition[int8]{Event: OrderEventComplete, From: []int8{OrderShipped}, To: OrderCompleted},
+		&fsm.Transition[int8]{Event: OrderEventRefund, From: []int8{OrderPaid, OrderRefunded, OrderPaidLate}, To: OrderRefunding},
+		&fsm.Transition[int8]{Event: OrderEventRefundSuccess, From: []int8{OrderRefunding}, To: OrderRefunded},
+		// 退款失败释放金额后(Payload为释放后的订单)，无其他已退金额时回到已支付，关闭后到账的订单回到OrderPaidLate
+		&fsm.Transition[int8]{Event: OrderEventRefundFail, From: []int8{OrderRefunding}, To: OrderPaidLate, Guard: paidLate},
+		&fsm.Transition[int8]{Event: OrderEventRefundFail, From: []int8{OrderRefunding}, To: OrderPaid, Guard: noRefund},
+		&fsm.Transition[int8]{Event: OrderEventRefundFail, From: []int8{OrderRefunding}, To: OrderRefunded},
+	)
+}
+
+// TransitOrder 按状态机m迁移订单，以当前状态为条件更新并在同一事务中执行fn和写入审计记录，关闭时记录close_time
+// 钩子(投递状态消息)失败只记录日志，不影响已提交的迁移
+func TransitOrder(ctx context.Context, m *fsm.Machine[int8], db *gorm.DB, c *fsm.Change[int8],
+	updates map[string]any, fn func(tx *gorm.DB) error) error {
+	err := m.Fire(ctx, c, func(ctx context.Context, c *fsm.Change[int8]) error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if updates == nil {
+				updates = make(map[string]any, 1)
+			}
+			updates["status"] = c.To
+			if c.To == OrderClosed {
+				updates["close_time"] = time.Now().Unix()
+			}
+			opt := tx.Model(&Order{}).
+				Where("order_no = ? AND status = ?", c.Subject, c.From).Updates(updates)
+			if opt.Error != nil {
+				return opt.Error
+			}
+			if opt.RowsAffected == 0 {
+				return fsm.ErrConflict
+			}
+			if fn != nil {
+				if err := fn(tx); err != nil {
+					return err
+				}
+			}
+			return tx.Create(&OrderLog{
+				OrderNo:    c.Subject,
+				Event:      c.Event,
+				FromStatus: c.From,
+				ToStatus:   c.To,
+				Operator:   c.Operator,
+				Remark:     c.Remark,
+			}).Error
+		})
+	})
+	if errors.Is(err, fsm.ErrHook) {
+		logger.FromContext(ctx).Error("orderFSM hook error", c, err)
+		return nil
+	}
+	return err
+}
+
+// TransitResult 状态不允许或并发变更时返回false
+func TransitResult(err error) (bool, error) {
+	if errors.Is(err, fsm.ErrTransition) || err == fsm.ErrConflict {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// OrderMessage 订单状态迁移消息，由各服务的迁移钩子投递到TopicOrder
+func OrderMessage(c *fsm.Change[int8]) []byte {
+	b, _ := json.Marshal(&MsgOrder{
+		OrderNo:  c.Subject,
+		Event:    c.Event,
+		From:     c.From,
+		To:       c.To,
+		Operator: c.Operator,
+		Time:     time.Now().Unix(),
+	})
+	return b
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"context"
+	"project/pkg/fsm"
+	"testing"
+)
+
+func TestOrderFSM(t *testing.T) {
+	m := NewOrderFSM()
+	cases := []struct {
+		name  string
+		event string
+		from  int8
+		order *Order
+		to    int8
+	}{
+		{"pay", OrderEventPay, OrderCreated, nil, OrderPaid},
+		{"pay after closed", OrderEventPay, OrderClosed, nil, OrderPaidLate},
+		{"refund paid late", OrderEventRefund, OrderPaidLate, nil, OrderRefunding},
+		{"refund fail", OrderEventRefundFail, OrderRefunding, &Order{}, OrderPaid},
+		{"refund fail paid late", OrderEventRefundFail, OrderRefunding, &Order{CloseTime: 1}, OrderPaidLate},
+		{"refund fail partial", OrderEventRefundFail, OrderRefunding, &Order{RefundAmount: 1}, OrderRefunded},
+		{"refund fail partial paid late", OrderEventRefundFail, OrderRefunding, &Order{CloseTime: 1, RefundAmount: 1}, OrderRefunded},
+	}
+	for _, tc := range cases {
+		c := &fsm.Change[int8]{Event: tc.event, From: tc.from, Payload: tc.order}
+		if err := m.Next(context.Background(), c); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if c.To != tc.to {
+			t.Fatalf("%s: to %d, want %d", tc.name, c.To, tc.to)
+		}
+	}
+	// 关闭后到账的订单不能发货
+	if m.Can(OrderPaidLate, OrderEventShip) {
+		t.Fatal("paid late order can ship")
+	}
+}
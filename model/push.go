@@ -0,0 +1,169 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// 消息推送：app设备token登记在push_device，按用户偏好选择app推送或小程序订阅消息，结果记录在push_delivery
+
+const (
+	PushPlatformIOS     = "ios"
+	PushPlatformAndroid = "android"
+)
+
+// 用户的推送偏好，auto优先app推送，没有可用设备时发送订阅消息
+const (
+	PushChannelAuto   = "auto"
+	PushChannelApp    = "app"
+	PushChannelWechat = "wechat"
+	PushChannelOff    = "off"
+)
+
+// 通知分类，用户可按分类和渠道分别关闭；交易类不受免打扰时段限制
+const (
+	PushCategoryTransactional = "transactional" // 订单、支付、退款等
+	PushCategoryReminder      = "reminder"      // 签到、券到期等提醒
+	PushCategoryMarketing     = "marketing"     // 活动营销
+)
+
+var PushCategories = []string{PushCategoryTransactional, PushCategoryReminder, PushCategoryMarketing}
+
+const (
+	PushDeferred int8 = 2  // 免打扰时段，推迟到结束后发送
+	PushPending  int8 = 0  // 待发送
+	PushSent     int8 = 1  // 渠道已接收
+	PushFailed   int8 = -1 // 渠道返回错误或网络异常
+	PushInvalid  int8 = -2 // token失效或用户未授权订阅，设备已删除
+	PushSkipped  int8 = -3 // 用户关闭推送或没有可用渠道
+)
+
+type PushDevice struct {
+	ID         int       `json:"-"`
+	UserID     int       `json:"-"`
+	DeviceID   string    `json:"device_id"`
+	Platform   string    `json:"platform"`
+	Provider   string    `json:"provider"` // apns|fcm|厂商通道名
+	Token      string    `json:"-"`
+	AppVersion string    `json:"app_version"`
+	UpdateTime time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+func (*PushDevice) TableName() string {
+	return "push_device"
+}
+
+type PushPreference struct {
+	UserID     int             `json:"-" gorm:"primaryKey"`
+	Channel    string          `json:"channel"`
+	Muted      JsonStringSlice `json:"muted"`       // 关闭的分类:渠道，如marketing:app、reminder:wechat
+	QuietStart string          `json:"quiet_start"` // 免打扰开始HH:MM，与结束相同时不启用，可跨零点
+	QuietEnd   string          `json:"quiet_end"`
+}
+
+func (*PushPreference) TableName() string {
+	return "push_preference"
+}
+
+// DefaultPushPreference 未设置偏好的用户
+func DefaultPushPreference(uid int) *PushPreference {
+	return &PushPreference{UserID: uid, Channel: PushChannelAuto, Muted: JsonStringSlice{}}
+}
+
+// Allow 用户是否接收该分类在该渠道的通知
+func (p *PushPreference) Allow(category, channel string) bool {
+	if p.Channel == PushChannelOff {
+		return false
+	}
+	for _, v := range p.Muted {
+		if v == category+":"+channel {
+			return false
+		}
+	}
+	return true
+}
+
+// QuietUntil t处于免打扰时段时返回结束时间，否则返回零值；交易类通知不受限制
+func (p *PushPreference) QuietUntil(category string, t time.Time) time.Time {
+	start, ok1 := parseClock(p.QuietStart)
+	end, ok2 := parseClock(p.QuietEnd)
+	if category == PushCategoryTransactional || !ok1 || !ok2 || start == end {
+		return time.Time{}
+	}
+	now := t.Hour()*60 + t.Minute()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch {
+	case start < end && now >= start && now < end:
+		return midnight.Add(time.Duration(end) * time.Minute)
+	case start > end && now >= start:
+		return midnight.AddDate(0, 0, 1).Add(time.Duration(end) * time.Minute)
+	case start > end && now < end:
+		return midnight.Add(time.Duration(end) * time.Minute)
+	}
+	return time.Time{}
+}
+
+// parseClock HH:MM转为当天的分钟数
+func parseClock(s string) (int, bool) {
+	if len(s) != 5 || s[2] != ':' {
+		return 0, false
+	}
+	h, err1 := strconv.Atoi(s[:2])
+	m, err2 := strconv.Atoi(s[3:])
+	if err1 != nil || err2 != nil || h > 23 || m > 59 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+type PushDelivery struct {
+	ID         int       `json:"-"`
+	MsgID      string    `json:"msg_id"`
+	UserID     int       `json:"user_id"`
+	Channel    string    `json:"channel"`  // app|wechat
+	Provider   string    `json:"provider"` // apns|fcm|wechat
+	DeviceID   string    `json:"device_id"`
+	Status     int8      `json:"status"`
+	ProviderID string    `json:"provider_id"` // 渠道返回的消息ID
+	Error      string    `json:"error"`
+	CreateTime time.Time `json:"create_time" gorm:"->"`
+}
+
+func (*PushDelivery) TableName() string {
+	return "push_delivery"
+}
+
+// MsgPush 推送任务，api投递到TopicPush，由script的push:send按偏好选择渠道
+type MsgPush struct {
+	MsgID    string            `json:"msg_id"`
+	UserID   int               `json:"user_id"`
+	Category string            `json:"category"` // 为空按交易类处理
+	Title    string            `json:"title"`
+	Body     string            `json:"body"`
+	Data     map[string]string `json:"data,omitempty"`
+	TTL      int               `json:"ttl,omitempty"`
+	Wechat   *PushWechat       `json:"wechat,omitempty"` // 为空时不发送订阅消息
+}
+
+// PushWechat 订阅消息模板，data为模板字段名到值
+type PushWechat struct {
+	TemplateID string            `json:"template_id"`
+	Page       string            `json:"page"`
+	Data       map[string]string `json:"data"`
+}
+
+func (v *PushWechat) Scan(value any) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), v)
+}
+
+func (v *PushWechat) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
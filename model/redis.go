@@ -5,11 +5,48 @@ import "strconv"
 // 定义缓存使用的key，同一个redis集群的key收敛到同一文件
 
 const (
-	KeyWechatToken = "wx:tk" // 微信access_token
+	KeyWechatToken = "wx:tk"        // 微信access_token
+	KeyMpToken     = "wx:mp:tk"     // 公众号access_token，用于换取jsapi_ticket
+	KeyJSAPITicket = "wx:mp:jt"     // 公众号jsapi_ticket，H5页面的JS-SDK签名
+	KeyWecomToken  = "wecom:tk"     // 企业微信自建应用access_token
+	KeyWechatQuota = "wx:quota:"    // +appid:日期:path 微信接口当日调用次数
+	KeyRecord      = "rec:"         // +trace_id 录制的外部调用 list
+	KeyChaosRules  = "chaos:rules"  // 故障注入规则 hash field=method+path
+	KeyExperiments = "experiments"  // 进行中的实验
+	KeyAppVersions = "app:versions" // 客户端最低版本 hash field=platform
+	KeyDelayQueue  = "mq:delay"     // 延迟消息 zset score=到期毫秒数
+	KeyDedup       = "mq:dedup:"    // +consumer:消息ID 消费去重，0处理中 1已处理
+	KeyIDWorker    = "id:worker:"   // +worker 发号器worker租约，值为持有的进程
+	KeyPresence    = "presence:"    // +online zset member=uid、+c:uid zset member=连接ID，score=最后心跳毫秒数
+	KeyGeo         = "geo:"         // +类型 GEO member=实体ID，附近搜索
 
 	keyBanners   = "banners:" // +city
+	keyContent   = "content:" // +key 已发布的运营内容
 	keyUserToken = "utk:"     // +token
+	keyGuest     = "gtk:"     // +token 游客凭证
+	keyGuestRate = "gtk:r:"   // +ip:IP|d:device_id:2006010215 每小时领取次数
+	keyOAuth     = "oauth:"   // +渠道:state 网站扫码登录的state，使用一次后删除
+	keyCart      = "cart:"    // +u:uid|g:游客ID hash field=sku_id 数量
 	keyUserInfo  = "user:"    // +uid
+	keyUserStat  = "ustat:"   // +uid 账号处罚状态，cms修改后删除
+
+	keyCouponStock = "coupon:stock:" // +template_id 剩余库存
+	keyCouponUser  = "coupon:user:"  // +template_id hash field=uid 已领取数
+
+	keyStock      = "stock:"       // +sku_id 可售库存
+	keyStockHold  = "stock:hold:"  // +sku_id hash field=order_no 订单预扣数量
+	keyStockOrder = "stock:order:" // +order_no set 订单预扣过的sku_id
+
+	keyBookingSlot = "booking:slot:" // +resource_id:开始时间戳 zset member=uid score=保留到期毫秒数或BookingConfirmedScore
+
+	keyLotteryStock = "lottery:stock:" // +lottery_id hash field=prize_id 剩余库存，"-"为初始化标记
+	keyLotteryUser  = "lottery:user:"  // +lottery_id hash field=uid 已抽次数
+	keyLotteryDaily = "lottery:day:"   // +lottery_id:20060102 hash field=uid 当天已抽次数
+
+	keyQRCode = "qrcode:" // +内容hash 已上传到cdn的二维码
+	keyUsage  = "usage:"  // +20060102 hash field=subject|route 调用次数
+
+	keyHTTPStat = "stat:http:" // +20060102 hash field=all|状态码类别 api请求数
 
 	keyAdminSSO   = "asso:" // +id
 	keyAdminToken = "atk:"  // +token
@@ -19,10 +56,18 @@ func BannersKey(city string) string {
 	return keyBanners + city
 }
 
+func ContentKey(key string) string {
+	return keyContent + key
+}
+
 func UserTokenKey(token string) string {
 	return keyUserToken + token
 }
 
+func OAuthStateKey(app, state string) string {
+	return keyOAuth + app + ":" + state
+}
+
 func UserInfoKey(id int) string {
 	return keyUserInfo + strconv.Itoa(id)
 }
@@ -34,3 +79,67 @@ func AdminSSOKey(id int) string {
 func AdminTokenKey(token string) string {
 	return keyAdminToken + token
 }
+
+func CouponStockKey(id int) string {
+	return keyCouponStock + strconv.Itoa(id)
+}
+
+func CouponUserKey(id int) string {
+	return keyCouponUser + strconv.Itoa(id)
+}
+
+func StockKey(sku int) string {
+	return keyStock + strconv.Itoa(sku)
+}
+
+func StockHoldKey(sku int) string {
+	return keyStockHold + strconv.Itoa(sku)
+}
+
+func StockOrderKey(orderNo string) string {
+	return keyStockOrder + orderNo
+}
+
+func BookingSlotKey(resourceID int, start int64) string {
+	return keyBookingSlot + strconv.Itoa(resourceID) + ":" + strconv.FormatInt(start, 10)
+}
+
+func LotteryStockKey(id int) string {
+	return keyLotteryStock + strconv.Itoa(id)
+}
+
+func LotteryUserKey(id int) string {
+	return keyLotteryUser + strconv.Itoa(id)
+}
+
+func LotteryDailyKey(id int, day string) string {
+	return keyLotteryDaily + strconv.Itoa(id) + ":" + day
+}
+
+func QRCodeKey(hash string) string {
+	return keyQRCode + hash
+}
+
+func UsageKey(date string) string {
+	return keyUsage + date
+}
+
+func HTTPStatKey(date string) string {
+	return keyHTTPStat + date
+}
+
+func GuestTokenKey(token string) string {
+	return keyGuest + token
+}
+
+func GuestRateKey(subject, hour string) string {
+	return keyGuestRate + subject + ":" + hour
+}
+
+func CartKey(owner string) string {
+	return keyCart + owner
+}
+
+func UserStatusKey(id int) string {
+	return keyUserStat + strconv.Itoa(id)
+}
@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// 行政区划：script的region:import按版本导入，撤销的代码保留并标记失效(已保存的地址仍可显示)；api每分钟检查最新版本后重新加载
+
+const (
+	RegionRetired int8 = 0 // 新版本中已撤销或合并
+	RegionValid   int8 = 1
+)
+
+const AddressMax = 20
+
+type Region struct {
+	Code       string    `json:"code" gorm:"primaryKey"`
+	Name       string    `json:"name"`
+	Parent     string    `json:"parent"`
+	Level      int8      `json:"level"`
+	Status     int8      `json:"status"`
+	Version    string    `json:"version"` // 最后变更的版本
+	UpdateTime time.Time `json:"update_time" gorm:"->"`
+}
+
+func (*Region) TableName() string {
+	return "region"
+}
+
+type RegionVersion struct {
+	Version    string    `json:"version" gorm:"primaryKey"`
+	Total      int       `json:"total"`
+	Added      int       `json:"added"`   // 新增和恢复的代码
+	Changed    int       `json:"changed"` // 名称或上级变更
+	Retired    int       `json:"retired"`
+	CreateTime time.Time `json:"create_time" gorm:"->"`
+}
+
+func (*RegionVersion) TableName() string {
+	return "region_version"
+}
+
+// UserAddress 收货地址，保存时按当前数据集校验省市区并记录名称快照；lng/lat为gcj02，客户端未传时保存后按地址解析
+type UserAddress struct {
+	ID           int       `json:"id"`
+	UserID       int       `json:"-"`
+	Name         string    `json:"name"`
+	Phone        string    `json:"phone"`
+	ProvinceCode string    `json:"province_code"`
+	CityCode     string    `json:"city_code"`
+	DistrictCode string    `json:"district_code"` // 城市没有下级区县时为空
+	Province     string    `json:"province"`
+	City         string    `json:"city"`
+	District     string    `json:"district"`
+	Detail       string    `json:"detail"`
+	Lng          float64   `json:"lng"`
+	Lat          float64   `json:"lat"`
+	IsDefault    bool      `json:"is_default"`
+	Outdated     bool      `json:"outdated" gorm:"-"` // 省市区在当前数据集中已失效，需重新选择
+	CreateTime   time.Time `json:"create_time" gorm:"->"`
+	UpdateTime   time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+func (*UserAddress) TableName() string {
+	return "user_address"
+}
+
+// FullText 省市区和详细地址，用于地址解析和订单快照
+func (a *UserAddress) FullText() string {
+	return a.Province + a.City + a.District + a.Detail
+}
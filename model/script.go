@@ -0,0 +1,157 @@
+package model
+
+import "github.com/go-redis/redis/v8"
+
+// 多个服务共用的lua脚本，使用的key定义在redis.go
+
+// ScriptCouponClaim 扣减券库存并累加用户领取数
+// KEYS: stock, user hash; ARGV: uid, 每人限领(0不限)
+// 返回: 1成功 0已领完 -1超过限领 -2库存未初始化
+var ScriptCouponClaim = redis.NewScript(`
+local stock = redis.call('GET', KEYS[1])
+if not stock then return -2 end
+if tonumber(stock) <= 0 then return 0 end
+local limit = tonumber(ARGV[2])
+if limit > 0 and tonumber(redis.call('HGET', KEYS[2], ARGV[1]) or '0') >= limit then return -1 end
+redis.call('DECR', KEYS[1])
+redis.call('HINCRBY', KEYS[2], ARGV[1], 1)
+return 1
+`)
+
+// ScriptCouponRollback 领取入库失败时归还库存
+var ScriptCouponRollback = redis.NewScript(`
+redis.call('INCR', KEYS[1])
+if tonumber(redis.call('HINCRBY', KEYS[2], ARGV[1], -1)) <= 0 then redis.call('HDEL', KEYS[2], ARGV[1]) end
+return 1
+`)
+
+// ScriptStockDeduct 多个sku一次性预扣，任一不足则全部不扣；同一订单重复调用直接返回成功
+// 预扣的sku记录到订单的set，取消时按此回补
+// KEYS: stock1, hold1, stock2, hold2 ..., order; ARGV: order_no, order过期秒数, qty1, qty2 ..., sku1, sku2 ...
+// 返回: 1成功 0库存不足 -2库存未初始化
+var ScriptStockDeduct = redis.NewScript(`
+local n = (#KEYS - 1) / 2
+if redis.call('HEXISTS', KEYS[2], ARGV[1]) == 1 then return 1 end
+for i = 1, n do
+	local stock = redis.call('GET', KEYS[2*i-1])
+	if not stock then return -2 end
+	if tonumber(stock) < tonumber(ARGV[i+2]) then return 0 end
+end
+for i = 1, n do
+	redis.call('DECRBY', KEYS[2*i-1], ARGV[i+2])
+	redis.call('HSET', KEYS[2*i], ARGV[1], ARGV[i+2])
+	redis.call('SADD', KEYS[#KEYS], ARGV[n+i+2])
+end
+redis.call('EXPIRE', KEYS[#KEYS], ARGV[2])
+return 1
+`)
+
+// ScriptStockRestock 按订单回补预扣的库存，返回回补数量，未预扣或已回补返回0
+// KEYS: stock, hold; ARGV: order_no
+var ScriptStockRestock = redis.NewScript(`
+local qty = redis.call('HGET', KEYS[2], ARGV[1])
+if not qty then return 0 end
+redis.call('INCRBY', KEYS[1], qty)
+redis.call('HDEL', KEYS[2], ARGV[1])
+return tonumber(qty)
+`)
+
+// ScriptStockAdd 后台补货或减少库存，key不存在时以数据库库存初始化；减少后不足0返回-1不修改
+// KEYS: stock; ARGV: delta, db库存(已包含delta)
+var ScriptStockAdd = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	redis.call('SET', KEYS[1], ARGV[2])
+	return tonumber(ARGV[2])
+end
+if tonumber(redis.call('GET', KEYS[1])) + tonumber(ARGV[1]) < 0 then return -1 end
+return redis.call('INCRBY', KEYS[1], ARGV[1])
+`)
+
+// ScriptBookingInit 名额key不存在时按数据库中有效的预约初始化，标记成员"-"不占名额
+// KEYS: slot; ARGV: 过期的毫秒时间戳, 确认的score, uid1, score1, uid2, score2 ...
+// 返回: 1已初始化 0已存在
+var ScriptBookingInit = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then return 0 end
+redis.call('ZADD', KEYS[1], ARGV[2], '-')
+for i = 3, #ARGV, 2 do
+	redis.call('ZADD', KEYS[1], ARGV[i+1], ARGV[i])
+end
+redis.call('PEXPIREAT', KEYS[1], ARGV[1])
+return 1
+`)
+
+// ScriptBookingReserve 移除已过期的保留后占用名额，同一用户在一个时段只能有一个有效预约
+// KEYS: slot; ARGV: uid, 名额, 当前毫秒, 保留到期毫秒
+// 返回: 1成功 0已约满 -1已预约 -2未初始化
+var ScriptBookingReserve = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then return -2 end
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[3])
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then return -1 end
+if redis.call('ZCARD', KEYS[1]) - 1 >= tonumber(ARGV[2]) then return 0 end
+redis.call('ZADD', KEYS[1], ARGV[4], ARGV[1])
+return 1
+`)
+
+// ScriptBookingConfirm 保留未到期时改为确认，score为BookingConfirmedScore
+// KEYS: slot; ARGV: uid, 当前毫秒, 确认的score, 保留到期毫秒
+// 返回: 1成功 0已过期或不是本次保留
+var ScriptBookingConfirm = redis.NewScript(`
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not score or tonumber(score) ~= tonumber(ARGV[4]) or tonumber(score) <= tonumber(ARGV[2]) then return 0 end
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+return 1
+`)
+
+// ScriptBookingRelease 取消或过期时释放名额，score不一致(已被同一用户的新预约占用)时不移除
+// KEYS: slot; ARGV: uid, score
+var ScriptBookingRelease = redis.NewScript(`
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if score and tonumber(score) == tonumber(ARGV[2]) then
+	return redis.call('ZREM', KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+// ScriptLotteryInit 库存key不存在时初始化奖品的剩余库存
+// KEYS: stock; ARGV: prize_id1, remain1, prize_id2, remain2 ...
+// 返回: 1已初始化 0已存在
+var ScriptLotteryInit = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then return 0 end
+redis.call('HSET', KEYS[1], '-', 0)
+for i = 1, #ARGV, 2 do
+	redis.call('HSET', KEYS[1], ARGV[i], ARGV[i+1])
+end
+return 1
+`)
+
+// ScriptLotteryDraw 校验每人次数后累加，随机数落在奖品的区间内且有库存时扣减库存
+// KEYS: stock, user hash, daily hash; ARGV: uid, 每天次数, 总次数(0不限), daily过期秒数, 随机数, prize_id1, 区间上界1 ...
+// 返回: 中奖的prize_id 0未中奖 -1超过当天次数 -2超过总次数 -3库存未初始化
+var ScriptLotteryDraw = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then return -3 end
+local total = tonumber(ARGV[3])
+if total > 0 and tonumber(redis.call('HGET', KEYS[2], ARGV[1]) or '0') >= total then return -2 end
+local daily = tonumber(ARGV[2])
+if daily > 0 and tonumber(redis.call('HGET', KEYS[3], ARGV[1]) or '0') >= daily then return -1 end
+redis.call('HINCRBY', KEYS[2], ARGV[1], 1)
+redis.call('HINCRBY', KEYS[3], ARGV[1], 1)
+redis.call('EXPIRE', KEYS[3], ARGV[4])
+local r = tonumber(ARGV[5])
+for i = 6, #ARGV, 2 do
+	if r < tonumber(ARGV[i+1]) then
+		if tonumber(redis.call('HGET', KEYS[1], ARGV[i]) or '0') <= 0 then return 0 end
+		redis.call('HINCRBY', KEYS[1], ARGV[i], -1)
+		return tonumber(ARGV[i])
+	end
+end
+return 0
+`)
+
+// ScriptLotteryRollback 抽奖记录入库失败时归还次数和库存
+// KEYS: stock, user hash, daily hash; ARGV: uid, prize_id(0未中奖)
+var ScriptLotteryRollback = redis.NewScript(`
+redis.call('HINCRBY', KEYS[2], ARGV[1], -1)
+redis.call('HINCRBY', KEYS[3], ARGV[1], -1)
+if tonumber(ARGV[2]) > 0 then redis.call('HINCRBY', KEYS[1], ARGV[2], 1) end
+return 1
+`)
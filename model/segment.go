@@ -0,0 +1,53 @@
+package model
+
+import (
+	"project/pkg/segment"
+	"time"
+)
+
+// 用户分群：cms定义规则，script的cronjob定时计算成员写入segment_member，供运营群发、AB实验按分群圈定用户
+
+type Segment struct {
+	ID          int           `json:"id"`
+	Code        string        `json:"code"` // 实验等按code引用
+	Name        string        `json:"name"`
+	Rule        *segment.Rule `json:"rule" gorm:"serializer:json"`
+	Size        int64         `json:"size"`         // 最近一次计算的人数
+	Version     int64         `json:"-"`            // 最近一次计算的批次，成员表中版本较旧的为已退出
+	RefreshTime int64         `json:"refresh_time"` // 最近一次计算完成时间
+	Status      int8          `json:"status"`
+	CreateTime  time.Time     `json:"create_time" gorm:"->"`
+}
+
+func (*Segment) TableName() string {
+	return "segment"
+}
+
+type SegmentMember struct {
+	SegmentID int   `gorm:"primaryKey"`
+	UserID    int   `gorm:"primaryKey"`
+	Version   int64 `json:"-"`
+}
+
+func (*SegmentMember) TableName() string {
+	return "segment_member"
+}
+
+// SegmentEngine 可用于分群规则的用户属性和行为
+var SegmentEngine = segment.New("user",
+	map[string]string{
+		"phone":     "user.phone_number",
+		"nickname":  "user.nickname",
+		"join_time": "UNIX_TIMESTAMP(user.create_time)",
+		"join_days": "DATEDIFF(NOW(), user.create_time)",
+	},
+	map[string]*segment.Event{
+		"order_paid": {Table: "order_info", UserColumn: "user_id", TimeColumn: "pay_time", TimeType: segment.TimeUnix,
+			Where: "order_info.status IN ?", Args: []any{[]int8{OrderPaid, OrderShipped, OrderCompleted, OrderRefunding, OrderRefunded}}},
+		"login": {Table: "auth_event", UserColumn: "user_id", TimeColumn: "event_time", TimeType: segment.TimeUnixMilli,
+			Where: "auth_event.type = ?", Args: []any{AuthTokenIssued}},
+		"coupon_used": {Table: "coupon", UserColumn: "user_id", TimeColumn: "use_time", TimeType: segment.TimeUnix,
+			Where: "coupon.status = ?", Args: []any{CouponUsed}},
+		"push_device": {Table: "push_device", UserColumn: "user_id", TimeColumn: "update_time"},
+	},
+)
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// StatDaily 管理后台统计看板的日汇总，由script定时从各业务表和redis计数汇总，重复执行结果一致
+type StatDaily struct {
+	Date       int       `json:"date" gorm:"primaryKey"` // 20060102
+	DAU        int64     `json:"dau"`                    // 当日调用过登录接口的用户数
+	WAU        int64     `json:"wau"`                    // 截至当日近7天的活跃用户数
+	NewUsers   int64     `json:"new_users"`
+	Orders     int64     `json:"orders"`   // 当日支付的订单数
+	Revenue    int64     `json:"revenue"`  // 当日支付金额(分)
+	Requests   int64     `json:"requests"` // api请求数
+	Errors     int64     `json:"errors"`   // 其中5xx响应数
+	ErrorRate  float64   `json:"error_rate" gorm:"-"`
+	UpdateTime time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+func (*StatDaily) TableName() string {
+	return "stat_daily"
+}
+
+// HTTPStatAll api请求计数中的总数field，其余field为状态码类别如2xx、5xx
+const HTTPStatAll = "all"
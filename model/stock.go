@@ -0,0 +1,96 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"strconv"
+	"time"
+)
+
+const (
+	StockDeduct  int8 = 1 // 下单扣减
+	StockRestock int8 = 2 // 取消回补
+)
+
+// StockOrderTTL 订单预扣的sku记录保留时长，超过后未支付的订单已超时关闭
+const StockOrderTTL = 7 * 24 * time.Hour
+
+// Stock 数据库库存，以redis预扣为准异步回写，后台补货同时更新两边
+type Stock struct {
+	SkuID      int       `json:"sku_id" gorm:"primaryKey;autoIncrement:false"`
+	Stock      int       `json:"stock"` // 可售库存
+	Sold       int       `json:"sold"`  // 已售
+	UpdateTime time.Time `json:"update_time" gorm:"->"`
+}
+
+func (*Stock) TableName() string {
+	return "sku_stock"
+}
+
+// StockLog 回写流水，(order_no, sku_id, type)唯一保证消息重复投递时只回写一次
+type StockLog struct {
+	ID       int    `json:"id"`
+	SkuID    int    `json:"sku_id"`
+	OrderNo  string `json:"order_no"`
+	Quantity int    `json:"quantity"`
+	Type     int8   `json:"type"`
+}
+
+func (*StockLog) TableName() string {
+	return "stock_log"
+}
+
+// RestockOrder 回补订单预扣的库存，skus为空时回补StockOrderKey中记录的全部sku；重复调用不会多次回补
+// 返回需投递到TopicStock的回写消息，未预扣或已回补的sku不返回(api和script共用)
+func RestockOrder(ctx context.Context, rdb redis.Cmdable, orderNo string, skus []int) ([][]byte, error) {
+	if len(skus) == 0 {
+		members, err := rdb.SMembers(ctx, StockOrderKey(orderNo)).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range members {
+			if sku, _ := strconv.Atoi(v); sku > 0 {
+				skus = append(skus, sku)
+			}
+		}
+	}
+	now := time.Now().Unix()
+	body := make([][]byte, 0, len(skus))
+	for _, sku := range skus {
+		keys := []string{StockKey(sku), StockHoldKey(sku)}
+		qty, err := ScriptStockRestock.Run(ctx, rdb, keys, orderNo).Int()
+		if err != nil {
+			return body, err
+		}
+		if qty == 0 {
+			continue
+		}
+		b, _ := json.Marshal(&MsgStock{
+			SkuID:    sku,
+			OrderNo:  orderNo,
+			Quantity: qty,
+			Type:     StockRestock,
+			Time:     now,
+		})
+		body = append(body, b)
+	}
+	return body, nil
+}
+
+// ClearStockHold 订单已支付后删除预扣记录，之后不再回补；sku的hold是所有订单共用的hash，不能设置过期时间
+func ClearStockHold(ctx context.Context, rdb redis.Cmdable, orderNo string) error {
+	members, err := rdb.SMembers(ctx, StockOrderKey(orderNo)).Result()
+	if err != nil || len(members) == 0 {
+		return err
+	}
+	pipe := rdb.TxPipeline()
+	for _, v := range members {
+		if sku, _ := strconv.Atoi(v); sku > 0 {
+			pipe.HDel(ctx, StockHoldKey(sku), orderNo)
+		}
+	}
+	pipe.Del(ctx, StockOrderKey(orderNo))
+	_, err = pipe.Exec(ctx)
+	return err
+}
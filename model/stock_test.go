@@ -0,0 +1,48 @@
+package model
+
+import (
+	"context"
+	"project/pkg/db/dbtest"
+	"testing"
+)
+
+func TestClearStockHold(t *testing.T) {
+	ctx := context.Background()
+	rdb, m := dbtest.Redis(t)
+	m.Set(StockKey(1), "10")
+	m.Set(StockKey(2), "10")
+	deduct := func(orderNo string) {
+		keys := []string{StockKey(1), StockHoldKey(1), StockKey(2), StockHoldKey(2), StockOrderKey(orderNo)}
+		if ret, err := ScriptStockDeduct.Run(ctx, rdb, keys, orderNo, 60, 2, 3, 1, 2).Int(); err != nil || ret != 1 {
+			t.Fatal(ret, err)
+		}
+	}
+	deduct("A")
+	deduct("B")
+
+	if err := ClearStockHold(ctx, rdb, "A"); err != nil {
+		t.Fatal(err)
+	}
+	for _, sku := range []int{1, 2} {
+		if m.HGet(StockHoldKey(sku), "A") != "" {
+			t.Fatalf("sku %d hold of A not cleared", sku)
+		}
+		if m.HGet(StockHoldKey(sku), "B") == "" {
+			t.Fatalf("sku %d hold of B cleared", sku)
+		}
+	}
+	if m.Exists(StockOrderKey("A")) {
+		t.Fatal("order sku set not deleted")
+	}
+	// 已支付的订单不再回补
+	body, err := RestockOrder(ctx, rdb, "A", []int{1, 2})
+	if err != nil || len(body) != 0 {
+		t.Fatal(len(body), err)
+	}
+	if v, _ := m.Get(StockKey(1)); v != "6" {
+		t.Fatalf("stock %s, want 6", v)
+	}
+	if err = ClearStockHold(ctx, rdb, "A"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// 门店：数据库为准，营业中的门店坐标同步到redis GEO(KeyGeo+GeoStore)用于附近搜索，script的cronjob每小时重建
+
+const GeoStore = "store"
+
+const (
+	StoreClosed int8 = 0 // 停业，不出现在附近搜索
+	StoreOpen   int8 = 1
+)
+
+type Store struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	City       string    `json:"city"`
+	Address    string    `json:"address"`
+	Phone      string    `json:"phone"`
+	Hours      string    `json:"hours"` // 营业时间，如 09:00-21:00
+	Lng        float64   `json:"lng"`   // gcj02坐标，与小程序wx.getLocation一致
+	Lat        float64   `json:"lat"`
+	Status     int8      `json:"status"`
+	Operator   string    `json:"operator"`
+	UpdateTime time.Time `json:"update_time" gorm:"->"`
+}
+
+func (*Store) TableName() string {
+	return "store"
+}
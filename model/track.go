@@ -0,0 +1,33 @@
+package model
+
+// 埋点事件的属性，实现track.Props；字段有不兼容变更时增加版本号，消费方按name+version解析
+
+const (
+	ShareFriend   = "friend"   // 转发给好友或群
+	ShareTimeline = "timeline" // 分享到朋友圈
+)
+
+type TrackLogin struct {
+	Openid   string `json:"openid"`
+	Platform string `json:"platform"`
+	Env      string `json:"env"`
+}
+
+func (*TrackLogin) Event() (string, int) { return "login", 1 }
+
+type TrackOrderCreated struct {
+	OrderNo  string `json:"order_no"`
+	Amount   int64  `json:"amount"`
+	Provider string `json:"provider"`
+	Scene    string `json:"scene"`
+}
+
+func (*TrackOrderCreated) Event() (string, int) { return "order_created", 1 }
+
+type TrackShare struct {
+	Page    string `json:"page"`
+	Channel string `json:"channel"`          // ShareFriend|ShareTimeline
+	Target  string `json:"target,omitempty"` // 分享内容的ID，如商品ID
+}
+
+func (*TrackShare) Event() (string, int) { return "share", 1 }
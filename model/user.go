@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type User struct {
 	ID          int    `json:"id"`
 	Openid      string `json:"openid"`
@@ -12,3 +14,72 @@ type User struct {
 func (*User) TableName() string {
 	return "user"
 }
+
+// UserWechat 开放平台网站应用、移动应用等渠道的openid，同一unionid关联到同一用户；小程序的openid在user表
+type UserWechat struct {
+	Appid      string    `json:"appid" gorm:"primaryKey"`
+	Openid     string    `json:"openid" gorm:"primaryKey"`
+	Unionid    string    `json:"unionid"`
+	UserID     int       `json:"user_id"`
+	CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+}
+
+func (*UserWechat) TableName() string {
+	return "user_wechat"
+}
+
+// 账号处罚状态，由cms设置，api的AuthCheck按状态限制访问
+const (
+	UserActive    int8 = 0 // 正常
+	UserMuted     int8 = 1 // 禁言，不能修改公开资料和分享
+	UserSuspended int8 = 2 // 冻结，只读
+	UserBanned    int8 = 3 // 封禁，不能登录和访问
+	UserMerged    int8 = 4 // 已按unionid合并到其他用户，token失效需重新登录
+)
+
+// UserStatus 未处罚的用户没有记录
+type UserStatus struct {
+	UserID     int       `json:"user_id" gorm:"primaryKey"`
+	Status     int8      `json:"status"`
+	Reason     string    `json:"reason"`
+	ExpireTime int64     `json:"expire_time"` // 到期后恢复正常，0为永久
+	Operator   string    `json:"operator"`
+	UpdateTime time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+func (*UserStatus) TableName() string {
+	return "user_status"
+}
+
+// Effective 当前生效的状态，已到期视为正常
+func (s *UserStatus) Effective(now time.Time) int8 {
+	if s == nil || s.ExpireTime > 0 && now.Unix() >= s.ExpireTime {
+		return UserActive
+	}
+	return s.Status
+}
+
+// UserMerge 同一unionid的用户合并记录，from的订单、资产等已转移到to
+type UserMerge struct {
+	ID         int       `json:"id"`
+	Unionid    string    `json:"unionid"`
+	FromUserID int       `json:"from_user_id"`
+	ToUserID   int       `json:"to_user_id"`
+	Detail     string    `json:"detail"` // json，各表转移的行数、资产转移的数量
+	CreateTime time.Time `json:"create_time" gorm:"->"`
+}
+
+func (*UserMerge) TableName() string {
+	return "user_merge"
+}
+
+// UserPresence 在线状态在redis，离线后最后在线时间保存到user_presence
+type UserPresence struct {
+	UserID   int   `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Online   bool  `json:"online" gorm:"-"`
+	LastSeen int64 `json:"last_seen"` // 秒，在线时为最后心跳时间，从未上线为0
+}
+
+func (*UserPresence) TableName() string {
+	return "user_presence"
+}
@@ -13,3 +13,25 @@ type WechatAnalysis struct {
 func (*WechatAnalysis) TableName() string {
 	return "wechat_analysis"
 }
+
+type WechatRetain struct {
+	RefDate    string `json:"ref_date" gorm:"primaryKey"`
+	Day        int    `json:"day" gorm:"primaryKey"` //第几天留存，0为当天
+	VisitUvNew int    `json:"visit_uv_new"`          //新增用户留存
+	VisitUv    int    `json:"visit_uv"`              //活跃用户留存
+}
+
+func (*WechatRetain) TableName() string {
+	return "wechat_retain"
+}
+
+type WechatDistribution struct {
+	RefDate string `json:"ref_date" gorm:"primaryKey"`
+	Index   string `json:"index" gorm:"primaryKey"` //分布类型
+	Key     int    `json:"key" gorm:"primaryKey"`   //场景值或区间编号
+	Value   int    `json:"value"`
+}
+
+func (*WechatDistribution) TableName() string {
+	return "wechat_distribution"
+}
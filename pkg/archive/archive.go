@@ -0,0 +1,267 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/pkg/coss"
+	"strconv"
+	"time"
+)
+
+/*
+冷数据归档：按策略把超过保留天数的行分批导出为gzip压缩的csv上传到对象存储，登记到archive_file后从表中删除
+每批之间休眠以限制对主库的压力；审计时按时间范围下载归档文件恢复到单独的表(默认为表名_restore)
+	a := archive.New(mysql, cos, "archive/")
+	n, err := a.Run(ctx, mysql, 0, policy)
+	n, err = a.Restore(ctx, "auth_event", begin, end, "")
+*/
+
+const (
+	TimeDatetime  = "datetime"  // 默认
+	TimeUnix      = "unix"      // 存unix秒的列
+	TimeUnixMilli = "unixmilli" // 存毫秒的列
+)
+
+// csv中的NULL，与mysql导出的格式一致
+const null = `\N`
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// Policy 单表的保留策略，表需有自增主键id
+type Policy struct {
+	Table    string
+	Column   string // 时间列，默认create_time
+	TimeType string // 时间列的类型，默认datetime
+	Days     int    // 保留天数，超过的行归档
+	Batch    int    // 每个归档文件的行数，默认5000
+	Sleep    int    // 每批之间休眠的毫秒数，默认200
+	MaxRows  int64  // 单次最多归档的行数，0不限制
+}
+
+func (p *Policy) withDefault() *Policy {
+	v := *p
+	if v.Column == "" {
+		v.Column = "create_time"
+	}
+	if v.TimeType == "" {
+		v.TimeType = TimeDatetime
+	}
+	if v.Batch <= 0 {
+		v.Batch = 5000
+	}
+	if v.Sleep <= 0 {
+		v.Sleep = 200
+	}
+	return &v
+}
+
+// cutoff 时间列的比较值
+func (p *Policy) cutoff(t time.Time) any {
+	switch p.TimeType {
+	case TimeUnix:
+		return t.Unix()
+	case TimeUnixMilli:
+		return t.UnixMilli()
+	}
+	return t
+}
+
+// File 归档文件的登记，表结构见design/sql
+type File struct {
+	ID         int       `json:"id"`
+	Path       string    `json:"path"`
+	Table      string    `json:"table_name" gorm:"column:table_name"`
+	Shard      int       `json:"shard"`
+	MinID      int64     `json:"min_id"`
+	MaxID      int64     `json:"max_id"`
+	Rows       int       `json:"rows"`
+	BeginTime  time.Time `json:"begin_time"` // 文件中最早一行的时间
+	EndTime    time.Time `json:"end_time"`
+	CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+}
+
+func (*File) TableName() string {
+	return "archive_file"
+}
+
+type Archiver struct {
+	meta   *gorm.DB // 登记归档文件，恢复的目标库
+	store  coss.Store
+	prefix string
+}
+
+// New meta为主库，prefix为对象存储的路径前缀，默认archive/
+func New(meta *gorm.DB, store coss.Store, prefix string) *Archiver {
+	if prefix == "" {
+		prefix = "archive/"
+	}
+	return &Archiver{meta: meta, store: store, prefix: prefix}
+}
+
+// Run 归档db中超过保留天数的行，shard为分片号(未分片为0)，返回归档的行数；ctx取消时在当前批结束后停止
+func (a *Archiver) Run(ctx context.Context, db *gorm.DB, shard int, policy *Policy) (int64, error) {
+	p := policy.withDefault()
+	if p.Days <= 0 {
+		return 0, nil
+	}
+	if !tableReg.MatchString(p.Table) || !tableReg.MatchString(p.Column) {
+		return 0, fmt.Errorf("archive: invalid table %q or column %q", p.Table, p.Column)
+	}
+	before := p.cutoff(time.Now().AddDate(0, 0, -p.Days))
+	day := time.Now().Format("20060102")
+	var total int64
+	for p.MaxRows <= 0 || total < p.MaxRows {
+		limit := p.Batch
+		if p.MaxRows > 0 && p.MaxRows-total < int64(limit) {
+			limit = int(p.MaxRows - total)
+		}
+		rows, err := db.WithContext(ctx).Table(p.Table).Where(p.Column+" < ?", before).Order("id").Limit(limit).Rows()
+		if err != nil {
+			return total, err
+		}
+		f, data, ids, err := a.encode(rows, p)
+		if err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		f.Path = fmt.Sprintf("%s%s/%s/%d-%d-%d.csv.gz", a.prefix, p.Table, day, shard, f.MinID, f.MaxID)
+		f.Table, f.Shard = p.Table, shard
+		if err = a.store.PutObject(ctx, f.Path, bytes.NewReader(data)); err != nil {
+			return total, err
+		}
+		// 上传后中断时重新执行会覆盖同名文件，登记按path更新
+		err = a.meta.WithContext(ctx).Clauses(clause.OnConflict{
+			DoUpdates: clause.AssignmentColumns([]string{"rows", "begin_time", "end_time"}),
+		}).Create(f).Error
+		if err != nil {
+			return total, err
+		}
+		if err = db.WithContext(ctx).Exec("DELETE FROM `"+p.Table+"` WHERE id IN ?", ids).Error; err != nil {
+			return total, err
+		}
+		total += int64(len(ids))
+		if len(ids) < limit {
+			return total, nil
+		}
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(time.Duration(p.Sleep) * time.Millisecond):
+		}
+	}
+	return total, nil
+}
+
+// encode 导出为带表头的csv并压缩，返回文件登记信息和行id
+func (a *Archiver) encode(rows *sql.Rows, p *Policy) (*File, []byte, []int64, error) {
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	idIdx, timeIdx := -1, -1
+	for i, c := range cols {
+		switch c {
+		case "id":
+			idIdx = i
+		case p.Column:
+			timeIdx = i
+		}
+	}
+	if idIdx < 0 || timeIdx < 0 {
+		return nil, nil, nil, fmt.Errorf("archive: %s has no id or %s column", p.Table, p.Column)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	w := csv.NewWriter(zw)
+	if err = w.Write(cols); err != nil {
+		return nil, nil, nil, err
+	}
+	f := &File{}
+	var ids []int64
+	values := make([]any, len(cols))
+	dest := make([]any, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(cols))
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return nil, nil, nil, err
+		}
+		for i, v := range values {
+			record[i] = format(v)
+		}
+		if err = w.Write(record); err != nil {
+			return nil, nil, nil, err
+		}
+		id, _ := strconv.ParseInt(record[idIdx], 10, 64)
+		ids = append(ids, id)
+		t := parseTime(record[timeIdx], p.TimeType)
+		if f.BeginTime.IsZero() || t.Before(f.BeginTime) {
+			f.BeginTime = t
+		}
+		if t.After(f.EndTime) {
+			f.EndTime = t
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return nil, nil, nil, err
+	}
+	if err = zw.Close(); err != nil {
+		return nil, nil, nil, err
+	}
+	if len(ids) > 0 {
+		f.MinID, f.MaxID, f.Rows = ids[0], ids[len(ids)-1], len(ids)
+	}
+	return f, buf.Bytes(), ids, nil
+}
+
+// format 时间按连接的时区(loc=Local)输出为mysql的datetime格式，恢复时原样写入
+func format(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return null
+	case []byte:
+		return string(x)
+	case string:
+		return x
+	case time.Time:
+		return x.In(time.Local).Format(timeLayout)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		if x {
+			return "1"
+		}
+		return "0"
+	}
+	return fmt.Sprint(v)
+}
+
+func parseTime(s, typ string) time.Time {
+	switch typ {
+	case TimeUnix, TimeUnixMilli:
+		n, _ := strconv.ParseInt(s, 10, 64)
+		if typ == TimeUnix {
+			return time.Unix(n, 0)
+		}
+		return time.UnixMilli(n)
+	}
+	t, _ := time.ParseInLocation(timeLayout, s, time.Local)
+	return t
+}
@@ -0,0 +1,97 @@
+package archive
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"gorm.io/gorm/clause"
+	"io"
+	"regexp"
+	"time"
+)
+
+var tableReg = regexp.MustCompile(`^\w+$`)
+
+// Restore 把时间范围内的归档文件恢复到主库的into表(默认为table_restore，按原表结构创建)，返回恢复的行数
+// 按文件整体恢复，可能包含少量范围外的行；保留原id，重复执行时忽略已恢复的行
+func (a *Archiver) Restore(ctx context.Context, table string, begin, end time.Time, into string) (int64, error) {
+	if into == "" {
+		into = table + "_restore"
+	}
+	if !tableReg.MatchString(table) || !tableReg.MatchString(into) || into == table {
+		return 0, fmt.Errorf("archive: invalid table %q or %q", table, into)
+	}
+	var files []*File
+	err := a.meta.WithContext(ctx).Where("table_name = ? AND end_time >= ? AND begin_time < ?", table, begin, end).
+		Order("id").Find(&files).Error
+	if err != nil || len(files) == 0 {
+		return 0, err
+	}
+	if err = a.meta.WithContext(ctx).Exec("CREATE TABLE IF NOT EXISTS `" + into + "` LIKE `" + table + "`").Error; err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, f := range files {
+		n, err := a.restoreFile(ctx, f.Path, into)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", f.Path, err)
+		}
+	}
+	return total, nil
+}
+
+func (a *Archiver) restoreFile(ctx context.Context, path, into string) (int64, error) {
+	body, err := a.store.GetObject(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	zr, err := gzip.NewReader(body)
+	if err != nil {
+		return 0, err
+	}
+	r := csv.NewReader(zr)
+	r.ReuseRecord = true
+	header, err := r.Read()
+	if err != nil {
+		return 0, err
+	}
+	cols := append([]string(nil), header...)
+	var total int64
+	batch := make([]map[string]any, 0, 500)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		opt := a.meta.WithContext(ctx).Table(into).Clauses(clause.Insert{Modifier: "IGNORE"}).Create(&batch)
+		total += opt.RowsAffected
+		batch = batch[:0]
+		return opt.Error
+	}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
+		row := make(map[string]any, len(cols))
+		for i, c := range cols {
+			if record[i] == null {
+				row[c] = nil
+			} else {
+				row[c] = record[i]
+			}
+		}
+		batch = append(batch, row)
+		if len(batch) == cap(batch) {
+			if err = flush(); err != nil {
+				return total, err
+			}
+		}
+	}
+	return total, flush()
+}
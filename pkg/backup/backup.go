@@ -0,0 +1,271 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"project/pkg/coss"
+	"project/pkg/db"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*
+逻辑备份：调用mysqldump在一致性快照中导出，gzip压缩后上传到对象存储，同名.json记录binlog位置、大小和sha256
+时间点恢复：Load导入备份后，Replay从备份记录的binlog位置重放到指定时间，需要服务器开启binlog(ROW格式)
+	b := backup.New(&cfg.Mysql, cos, &cfg.Backup)
+	meta, err := b.Dump(ctx)
+	err = b.Load(ctx, meta, "go_project_verify")
+	err = b.Replay(ctx, meta, "go_project_restore", until)
+依赖mysqldump、mysql、mysqlbinlog命令
+*/
+
+type Options struct {
+	Prefix string // 对象存储的路径前缀，默认backup/
+	TmpDir string // 导出、下载的临时目录，默认系统临时目录
+	Binlog bool   // 记录binlog位置用于时间点恢复，需要RELOAD和REPLICATION CLIENT权限
+}
+
+// Meta 备份的描述，与备份文件同名的.json
+type Meta struct {
+	Path       string    `json:"path"`
+	Database   string    `json:"database"`
+	Size       int64     `json:"size"`
+	Sha256     string    `json:"sha256"`
+	BinlogFile string    `json:"binlog_file,omitempty"`
+	BinlogPos  int64     `json:"binlog_pos,omitempty"`
+	Tables     []string  `json:"tables"`
+	BeginTime  time.Time `json:"begin_time"`
+	EndTime    time.Time `json:"end_time"`
+}
+
+type Backup struct {
+	mysql *db.Mysql
+	store coss.Store
+	opt   Options
+}
+
+func New(mysql *db.Mysql, store coss.Store, opt *Options) *Backup {
+	b := &Backup{mysql: mysql, store: store}
+	if opt != nil {
+		b.opt = *opt
+	}
+	if b.opt.Prefix == "" {
+		b.opt.Prefix = "backup/"
+	}
+	return b
+}
+
+// LatestKey 最近一次备份的描述，每次备份后覆盖
+func (b *Backup) LatestKey() string {
+	return b.opt.Prefix + b.mysql.Database + "/latest.json"
+}
+
+// Dump 导出并上传，成功后更新latest.json
+func (b *Backup) Dump(ctx context.Context) (*Meta, error) {
+	meta := &Meta{Database: b.mysql.Database, BeginTime: time.Now()}
+	meta.Path = fmt.Sprintf("%s%s/%s.sql.gz", b.opt.Prefix, b.mysql.Database, meta.BeginTime.Format("20060102150405"))
+	f, err := os.CreateTemp(b.opt.TmpDir, "dump-*.sql.gz")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	args := []string{"--single-transaction", "--quick", "--routines", "--triggers",
+		"--set-gtid-purged=OFF", "--default-character-set=utf8mb4"}
+	if b.opt.Binlog {
+		args = append(args, "--master-data=2")
+	}
+	cmd := b.command(ctx, "mysqldump", append(args, b.mysql.Database)...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	sum := sha256.New()
+	zw := gzip.NewWriter(io.MultiWriter(f, sum))
+	err = scanDump(io.TeeReader(stdout, zw), meta)
+	if err != nil {
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+	if werr := cmd.Wait(); werr != nil {
+		return nil, fmt.Errorf("mysqldump: %w: %s", werr, strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	if meta.Size, err = f.Seek(0, io.SeekCurrent); err != nil {
+		return nil, err
+	}
+	meta.Sha256 = hex.EncodeToString(sum.Sum(nil))
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err = b.store.PutObject(ctx, meta.Path, f); err != nil {
+		return nil, err
+	}
+	meta.EndTime = time.Now()
+	data, _ := json.MarshalIndent(meta, "", "  ")
+	for _, key := range []string{strings.TrimSuffix(meta.Path, ".sql.gz") + ".json", b.LatestKey()} {
+		if err = b.store.PutObject(ctx, key, bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	}
+	return meta, nil
+}
+
+const timeLayout = "2006-01-02 15:04:05"
+
+var (
+	identReg  = regexp.MustCompile(`^\w+$`)
+	binlogReg = regexp.MustCompile(`(?:MASTER|SOURCE)_LOG_FILE='([^']+)', (?:MASTER|SOURCE)_LOG_POS=(\d+)`) // 8.0.23起为SOURCE
+	tableReg  = regexp.MustCompile("^CREATE TABLE `([^`]+)`")
+)
+
+// scanDump 从导出内容中读取binlog位置和表名
+func scanDump(r io.Reader, meta *Meta) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 64*1024*1024) // 扩展插入的单行可能很长
+	for sc.Scan() {
+		line := sc.Bytes()
+		if meta.BinlogFile == "" && bytes.HasPrefix(line, []byte("-- CHANGE ")) {
+			if m := binlogReg.FindSubmatch(line); m != nil {
+				meta.BinlogFile = string(m[1])
+				fmt.Sscan(string(m[2]), &meta.BinlogPos)
+			}
+		} else if m := tableReg.FindSubmatch(line); m != nil {
+			meta.Tables = append(meta.Tables, string(m[1]))
+		}
+	}
+	return sc.Err()
+}
+
+// Meta 读取备份的描述，path为备份文件或.json，为空时读取最近一次
+func (b *Backup) Meta(ctx context.Context, path string) (*Meta, error) {
+	key := b.LatestKey()
+	if path != "" {
+		key = strings.TrimSuffix(strings.TrimSuffix(path, ".json"), ".sql.gz") + ".json"
+	}
+	body, err := b.store.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	meta := &Meta{}
+	return meta, json.NewDecoder(body).Decode(meta)
+}
+
+// Load 下载备份并校验sha256后导入database(不存在时创建)，database不能是备份的源库
+func (b *Backup) Load(ctx context.Context, meta *Meta, database string) error {
+	if err := b.checkTarget(database); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(b.opt.TmpDir, "load-*.sql.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	body, err := b.store.GetObject(ctx, meta.Path)
+	if err != nil {
+		return err
+	}
+	sum := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, sum), body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(sum.Sum(nil)) != meta.Sha256 {
+		return fmt.Errorf("backup: %s sha256 mismatch", meta.Path)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	if err = b.exec(ctx, strings.NewReader("CREATE DATABASE IF NOT EXISTS `"+database+"`"), "mysql"); err != nil {
+		return err
+	}
+	return b.exec(ctx, zr, "mysql", database)
+}
+
+// Replay 从备份记录的binlog位置重放源库的变更到database，直到until(不含)
+func (b *Backup) Replay(ctx context.Context, meta *Meta, database string, until time.Time) error {
+	if err := b.checkTarget(database); err != nil {
+		return err
+	}
+	if meta.BinlogFile == "" {
+		return fmt.Errorf("backup: %s has no binlog position", meta.Path)
+	}
+	if !until.After(meta.BeginTime) {
+		return fmt.Errorf("backup: until must be after %s", meta.BeginTime.Format(timeLayout))
+	}
+	binlog := b.command(ctx, "mysqlbinlog", "--read-from-remote-server", "--to-last-log",
+		"--start-position="+fmt.Sprint(meta.BinlogPos), "--stop-datetime="+until.Format(timeLayout),
+		"--database="+b.mysql.Database, "--rewrite-db="+b.mysql.Database+"->"+database, meta.BinlogFile)
+	stdout, err := binlog.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	binlog.Stderr = &stderr
+	if err = binlog.Start(); err != nil {
+		return err
+	}
+	err = b.exec(ctx, stdout, "mysql", database)
+	if werr := binlog.Wait(); werr != nil && err == nil {
+		err = fmt.Errorf("mysqlbinlog: %w: %s", werr, strings.TrimSpace(stderr.String()))
+	}
+	return err
+}
+
+func (b *Backup) checkTarget(database string) error {
+	if database == "" || database == b.mysql.Database || !identReg.MatchString(database) {
+		return fmt.Errorf("backup: invalid target database %q", database)
+	}
+	return nil
+}
+
+// command 连接参数与服务使用的mysql配置一致，密码通过环境变量传递，不出现在进程列表中
+func (b *Backup) command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	host, port, ok := strings.Cut(b.mysql.Address, ":")
+	if !ok {
+		port = "3306"
+	}
+	conn := []string{"--host=" + host, "--port=" + port, "--user=" + b.mysql.Username}
+	cmd := exec.CommandContext(ctx, name, append(conn, args...)...)
+	cmd.Env = append(os.Environ(), "MYSQL_PWD="+b.mysql.Password)
+	return cmd
+}
+
+// exec 执行mysql客户端，stdin为sql
+func (b *Backup) exec(ctx context.Context, stdin io.Reader, name string, args ...string) error {
+	cmd := b.command(ctx, name, append([]string{"--default-character-set=utf8mb4"}, args...)...)
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
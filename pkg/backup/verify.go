@@ -0,0 +1,93 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// Report 恢复验证的结果
+type Report struct {
+	Meta    *Meta
+	Tables  []*TableRows
+	Missing []string // 备份中有但恢复后不存在的表
+	Empty   []string // 恢复后为空但源库有数据的表
+	Elapsed time.Duration
+}
+
+type TableRows struct {
+	Table    string
+	Restored int64
+	Live     int64 // 源库当前行数，备份后新增的行不在备份中
+}
+
+// Failed 备份中没有表或恢复后有缺失的表时验证失败；空表可能是备份后才写入，只作为告警
+func (r *Report) Failed() bool {
+	return len(r.Missing) > 0 || len(r.Tables) == 0
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "backup %s (%s, %d bytes) restored in %s", r.Meta.Path, r.Meta.BeginTime.Format(timeLayout),
+		r.Meta.Size, r.Elapsed.Round(time.Second))
+	if len(r.Missing) > 0 {
+		fmt.Fprintf(&b, "\nmissing tables: %s", strings.Join(r.Missing, ", "))
+	}
+	if len(r.Empty) > 0 {
+		fmt.Fprintf(&b, "\nempty tables: %s", strings.Join(r.Empty, ", "))
+	}
+	for _, v := range r.Tables {
+		fmt.Fprintf(&b, "\n  %-30s %12d %12d", v.Table, v.Restored, v.Live)
+	}
+	return b.String()
+}
+
+// Verify 导入到临时库scratch(先删除已有的同名库)，核对表和行数后删除临时库；orm为连接源库的客户端
+func (b *Backup) Verify(ctx context.Context, orm *gorm.DB, meta *Meta, scratch string) (*Report, error) {
+	if err := b.checkTarget(scratch); err != nil {
+		return nil, err
+	}
+	begin := time.Now()
+	drop := func() error {
+		return orm.Exec("DROP DATABASE IF EXISTS `" + scratch + "`").Error
+	}
+	if err := drop(); err != nil {
+		return nil, err
+	}
+	defer drop()
+	if err := b.Load(ctx, meta, scratch); err != nil {
+		return nil, err
+	}
+	var restored []string
+	err := orm.WithContext(ctx).Raw("SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'",
+		scratch).Scan(&restored).Error
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(restored))
+	for _, t := range restored {
+		exists[t] = true
+	}
+	r := &Report{Meta: meta}
+	for _, t := range meta.Tables {
+		if !exists[t] {
+			r.Missing = append(r.Missing, t)
+			continue
+		}
+		v := &TableRows{Table: t}
+		if err = orm.WithContext(ctx).Raw("SELECT COUNT(*) FROM `" + scratch + "`.`" + t + "`").Scan(&v.Restored).Error; err != nil {
+			return nil, err
+		}
+		if err = orm.WithContext(ctx).Raw("SELECT COUNT(*) FROM `" + b.mysql.Database + "`.`" + t + "`").Scan(&v.Live).Error; err != nil {
+			v.Live = -1 // 源库已删除该表
+		}
+		if v.Restored == 0 && v.Live > 0 {
+			r.Empty = append(r.Empty, t)
+		}
+		r.Tables = append(r.Tables, v)
+	}
+	r.Elapsed = time.Since(begin)
+	return r, nil
+}
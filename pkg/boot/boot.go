@@ -0,0 +1,146 @@
+package boot
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"log"
+	"project/pkg/mq"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 启动阶段检查依赖连通性，失败按间隔重试，全部检查结束后汇总报告，必需的依赖未就绪时不监听端口
+
+type Check struct {
+	Name     string
+	Optional bool // 失败仅告警，不阻止启动
+	Fn       func(ctx context.Context) error
+}
+
+type Options struct {
+	Retries  int // 每项检查的最大重试次数，默认5
+	Interval int // 重试间隔秒数，默认2，每次翻倍
+	Timeout  int // 单次检查超时秒数，默认3
+	Warmup   int // 预热总超时秒数，默认30
+}
+
+type Result struct {
+	Name     string
+	Optional bool
+	Attempts int
+	Elapsed  time.Duration
+	Err      error
+}
+
+// Report 检查失败的汇总，作为error返回
+type Report []*Result
+
+func (r Report) Error() string {
+	var b strings.Builder
+	b.WriteString("boot: dependencies not ready")
+	for _, v := range r {
+		level := "FAIL"
+		if v.Optional {
+			level = "WARN"
+		}
+		fmt.Fprintf(&b, "\n  [%s] %s: %v (attempts=%d, elapsed=%s)", level, v.Name, v.Err, v.Attempts, v.Elapsed.Round(time.Millisecond))
+	}
+	return b.String()
+}
+
+func (o *Options) withDefault() *Options {
+	opt := Options{Retries: 5, Interval: 2, Timeout: 3, Warmup: 30}
+	if o != nil {
+		if o.Retries > 0 {
+			opt.Retries = o.Retries
+		}
+		if o.Interval > 0 {
+			opt.Interval = o.Interval
+		}
+		if o.Timeout > 0 {
+			opt.Timeout = o.Timeout
+		}
+		if o.Warmup > 0 {
+			opt.Warmup = o.Warmup
+		}
+	}
+	return &opt
+}
+
+// Wait 并发执行全部检查，必需项有失败时返回Report，仅可选项失败时打印告警并返回nil
+func Wait(opt *Options, checks ...Check) error {
+	opt = opt.withDefault()
+	results := make([]*Result, len(checks))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = run(opt, &checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var failed Report
+	fatal := false
+	for _, v := range results {
+		if v.Err == nil {
+			log.Printf("boot: %s ok (attempts=%d, elapsed=%s)", v.Name, v.Attempts, v.Elapsed.Round(time.Millisecond))
+			continue
+		}
+		failed = append(failed, v)
+		fatal = fatal || !v.Optional
+	}
+	if fatal {
+		return failed
+	}
+	if len(failed) > 0 {
+		log.Println(failed.Error())
+	}
+	return nil
+}
+
+func run(opt *Options, c *Check) *Result {
+	r := &Result{Name: c.Name, Optional: c.Optional}
+	begin := time.Now()
+	interval := time.Duration(opt.Interval) * time.Second
+	for r.Attempts < opt.Retries {
+		r.Attempts++
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(opt.Timeout)*time.Second)
+		r.Err = c.Fn(ctx)
+		cancel()
+		if r.Err == nil || r.Attempts == opt.Retries {
+			break
+		}
+		time.Sleep(interval)
+		interval *= 2
+	}
+	r.Elapsed = time.Since(begin)
+	return r
+}
+
+func Mysql(orm *gorm.DB) Check {
+	return Check{Name: "mysql", Fn: func(ctx context.Context) error {
+		sqlDB, err := orm.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}}
+}
+
+func Redis(cli *redis.Client) Check {
+	return Check{Name: "redis", Fn: func(ctx context.Context) error {
+		return cli.Ping(ctx).Err()
+	}}
+}
+
+// MQ 检查名称为驱动名nsq或kafka
+func MQ(producer mq.Producer) Check {
+	return Check{Name: producer.Driver(), Fn: func(context.Context) error {
+		return producer.Ping()
+	}}
+}
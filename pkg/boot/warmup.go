@@ -0,0 +1,96 @@
+package boot
+
+import (
+	"context"
+	"database/sql"
+	"gorm.io/gorm"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 预热：依赖检查通过后、开始接收流量前执行，如加载热点配置、预取凭证、填充本地缓存、请求常用路由
+// 预热失败只告警，不阻止启动；全部结束或超时后Ready返回true，readiness探针据此判断
+
+type warmup struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+var (
+	warmMu  sync.Mutex
+	warmups []*warmup
+	ready   atomic.Bool
+)
+
+// OnWarmup 注册预热任务，需在RunWarmup之前调用；任务应在ctx取消后尽快返回
+func OnWarmup(name string, fn func(ctx context.Context) error) {
+	warmMu.Lock()
+	defer warmMu.Unlock()
+	warmups = append(warmups, &warmup{name: name, fn: fn})
+}
+
+// RunWarmup 并发执行已注册的任务，总超时为opt.Warmup秒，结束后标记就绪
+func RunWarmup(opt *Options) {
+	opt = opt.withDefault()
+	warmMu.Lock()
+	list := warmups
+	warmups = nil
+	warmMu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(opt.Warmup)*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, v := range list {
+		wg.Add(1)
+		go func(w *warmup) {
+			defer wg.Done()
+			begin := time.Now()
+			if err := w.fn(ctx); err != nil {
+				log.Printf("boot: warmup %s failed: %v (elapsed=%s)", w.name, err, time.Since(begin).Round(time.Millisecond))
+				return
+			}
+			log.Printf("boot: warmup %s ok (elapsed=%s)", w.name, time.Since(begin).Round(time.Millisecond))
+		}(v)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Println("boot: warmup timeout, continue")
+	}
+	ready.Store(true)
+}
+
+// Ready 预热是否已结束
+func Ready() bool {
+	return ready.Load()
+}
+
+// WarmMysql 同时持有n个连接后归还，使连接池中有n个空闲连接，避免首批请求排队建连；n不应超过MaxIdle
+func WarmMysql(orm *gorm.DB, n int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		sqlDB, err := orm.DB()
+		if err != nil {
+			return err
+		}
+		conns := make([]*sql.Conn, 0, n)
+		defer func() {
+			for _, c := range conns {
+				c.Close() // nolint
+			}
+		}()
+		for i := 0; i < n; i++ {
+			c, err := sqlDB.Conn(ctx)
+			if err != nil {
+				return err
+			}
+			conns = append(conns, c)
+		}
+		return nil
+	}
+}
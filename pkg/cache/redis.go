@@ -1,7 +1,6 @@
 package cache
 
 import (
-	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"github.com/go-redis/redis/v8"
@@ -35,7 +34,7 @@ func NewRedisClient(cfg *Redis) *redis.Client {
 		}
 	}
 
-	cli := redis.NewClient(&redis.Options{
+	return redis.NewClient(&redis.Options{
 		Addr:         cfg.Address,
 		Username:     cfg.Username,
 		Password:     cfg.Password,
@@ -43,9 +42,5 @@ func NewRedisClient(cfg *Redis) *redis.Client {
 		PoolSize:     cfg.PoolSize,
 		MinIdleConns: cfg.MinIdle,
 		TLSConfig:    tlsConfig,
-	})
-	if err := cli.Ping(context.Background()).Err(); err != nil {
-		log.Fatal(err)
-	}
-	return cli
+	}) // 连通性在启动阶段由boot检查
 }
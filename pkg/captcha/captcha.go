@@ -0,0 +1,223 @@
+package captcha
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"math/big"
+	"project/pkg/util/captcha"
+	"project/pkg/util/random"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+自托管的滑块/图片验证码，挑战和票据存在redis，多实例共享
+	c := captcha.New(rdb, &opt)
+	ch, err := c.Issue(ctx, captcha.KindSlider)    // 图片返回给客户端
+	ticket, err := c.Verify(ctx, ch.ID, answer)    // 每个挑战只能提交一次，通过后下发票据
+	ok, err := c.Redeem(ctx, ticket)               // 受保护的接口核销票据，一次有效
+风控或限流通过Flag/Hit标记主体(用户、IP)，标记期间受保护的接口需先完成验证码
+*/
+
+const (
+	KindSlider = "slider" // 拖动拼图到缺口，答案为缺口的x坐标
+	KindImage  = "image"  // 输入图片中的字符，不区分大小写
+
+	keyChallenge = "captcha:c:"
+	keyTicket    = "captcha:t:"
+	keyFlag      = "captcha:f:"
+	keyHit       = "captcha:h:"
+)
+
+var (
+	ErrKind     = errors.New("captcha: unsupported kind")
+	ErrNotFound = errors.New("captcha: challenge not found or expired")
+	ErrMismatch = errors.New("captcha: wrong answer")
+)
+
+type Options struct {
+	Font       string // 图片验证码的ttf字体，为空不支持image
+	Background string // jpg背景，为空时随机生成
+	Length     int    // 图片验证码字符数，默认4
+	TTL        int    // 挑战有效秒数，默认120
+	TicketTTL  int    `mapstructure:"ticketTTL"` // 票据有效秒数，默认300
+	Tolerance  int    // 滑块允许的像素误差，默认4
+}
+
+// Challenge 下发给客户端的挑战，不含答案
+type Challenge struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Image  string `json:"image"`           // data URI，滑块为带缺口的背景
+	Piece  string `json:"piece,omitempty"` // 滑块拼图，与背景等高，初始在最左侧
+	Width  int    `json:"width"`           // 背景宽度，客户端缩放显示时按此换算x坐标
+	Height int    `json:"height"`
+	Expire int    `json:"expire"` // 有效秒数
+}
+
+type Captcha struct {
+	redis     *redis.Client
+	drawer    *captcha.Drawer
+	slider    *slider
+	length    int
+	ttl       time.Duration
+	ticketTTL time.Duration
+	tolerance int
+}
+
+func New(rdb *redis.Client, opt *Options) *Captcha {
+	c := &Captcha{
+		redis:     rdb,
+		slider:    newSlider(opt.Background),
+		length:    4,
+		ttl:       2 * time.Minute,
+		ticketTTL: 5 * time.Minute,
+		tolerance: 4,
+	}
+	if opt.Font != "" {
+		c.drawer = captcha.NewDrawer(opt.Font, opt.Background, "ABCDEFGHJKLMNPQRSTUVWXY3456789")
+	}
+	if opt.Length > 0 {
+		c.length = opt.Length
+	}
+	if opt.TTL > 0 {
+		c.ttl = time.Duration(opt.TTL) * time.Second
+	}
+	if opt.TicketTTL > 0 {
+		c.ticketTTL = time.Duration(opt.TicketTTL) * time.Second
+	}
+	if opt.Tolerance > 0 {
+		c.tolerance = opt.Tolerance
+	}
+	return c
+}
+
+// Issue 生成挑战，答案以kind:answer存入redis
+func (c *Captcha) Issue(ctx context.Context, kind string) (*Challenge, error) {
+	ch := &Challenge{ID: random.UUID(), Kind: kind, Expire: int(c.ttl.Seconds())}
+	var answer string
+	switch kind {
+	case KindSlider:
+		s := c.slider.generate()
+		ch.Image = "data:image/png;base64," + base64.StdEncoding.EncodeToString(s.bg)
+		ch.Piece = "data:image/png;base64," + base64.StdEncoding.EncodeToString(s.piece)
+		ch.Width, ch.Height = sliderWidth, sliderHeight
+		answer = strconv.Itoa(s.x)
+	case KindImage:
+		if c.drawer == nil {
+			return nil, ErrKind
+		}
+		code, b := c.drawer.Generate(c.length)
+		ch.Image = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(b)
+		answer = code
+	default:
+		return nil, ErrKind
+	}
+	if err := c.redis.Set(ctx, keyChallenge+ch.ID, kind+":"+answer, c.ttl).Err(); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// Verify 取出即删除，答错需重新获取挑战，防止穷举；通过后返回一次性票据
+func (c *Captcha) Verify(ctx context.Context, id, answer string) (string, error) {
+	v, err := c.take(ctx, keyChallenge+id)
+	if err != nil {
+		return "", err
+	}
+	kind, expect, _ := strings.Cut(v, ":")
+	if !c.match(kind, expect, strings.TrimSpace(answer)) {
+		return "", ErrMismatch
+	}
+	ticket := random.UUID()
+	if err = c.redis.Set(ctx, keyTicket+ticket, kind, c.ticketTTL).Err(); err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+func (c *Captcha) match(kind, expect, answer string) bool {
+	switch kind {
+	case KindSlider:
+		x, err := strconv.Atoi(expect)
+		if err != nil {
+			return false
+		}
+		got, err := strconv.ParseFloat(answer, 64)
+		if err != nil {
+			return false
+		}
+		d := got - float64(x)
+		return d <= float64(c.tolerance) && d >= -float64(c.tolerance)
+	case KindImage:
+		return answer != "" && strings.EqualFold(expect, answer)
+	}
+	return false
+}
+
+// Redeem 核销票据，票据不存在或已使用返回false
+func (c *Captcha) Redeem(ctx context.Context, ticket string) (bool, error) {
+	if ticket == "" {
+		return false, nil
+	}
+	n, err := c.redis.Del(ctx, keyTicket+ticket).Result()
+	return n > 0, err
+}
+
+// Flag 标记主体在ttl内访问受保护的接口需要验证码，供风控调用
+func (c *Captcha) Flag(ctx context.Context, subject string, ttl time.Duration) error {
+	return c.redis.Set(ctx, keyFlag+subject, 1, ttl).Err()
+}
+
+// Flagged 任一主体被标记即返回true
+func (c *Captcha) Flagged(ctx context.Context, subjects ...string) (bool, error) {
+	keys := make([]string, len(subjects))
+	for i, v := range subjects {
+		keys[i] = keyFlag + v
+	}
+	n, err := c.redis.Exists(ctx, keys...).Result()
+	return n > 0, err
+}
+
+// Hit 固定窗口计数，窗口内超过limit次返回true，用于限流触发验证码而不是直接拒绝
+func (c *Captcha) Hit(ctx context.Context, subject string, limit int64, window time.Duration) (bool, error) {
+	if window < time.Second {
+		window = time.Minute
+	}
+	key := keyHit + subject + ":" + strconv.FormatInt(time.Now().Unix()/int64(window.Seconds()), 10)
+	pipe := c.redis.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return incr.Val() > limit, nil
+}
+
+// take 原子地读取并删除，兼容不支持GETDEL的redis版本
+func (c *Captcha) take(ctx context.Context, key string) (string, error) {
+	pipe := c.redis.TxPipeline()
+	get := pipe.Get(ctx, key)
+	pipe.Del(ctx, key)
+	_, err := pipe.Exec(ctx)
+	if err == redis.Nil {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return get.Val(), nil
+}
+
+// randInt 答案位置使用crypto/rand，避免被预测
+func randInt(min, max int) int {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max-min)))
+	if err != nil {
+		return min
+	}
+	return min + int(n.Int64())
+}
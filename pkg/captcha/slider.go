@@ -0,0 +1,129 @@
+package captcha
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+)
+
+const (
+	sliderWidth  = 300
+	sliderHeight = 150
+	pieceSize    = 44 // 拼图方块边长
+	knob         = 8  // 方块右侧和上方凸起的半径
+)
+
+type slider struct {
+	bg image.Image // 配置的背景图，为nil时随机绘制
+}
+
+type sliderImage struct {
+	bg    []byte
+	piece []byte
+	x     int
+}
+
+func newSlider(background string) *slider {
+	s := &slider{}
+	if background != "" {
+		if f, err := os.Open(background); err == nil {
+			s.bg, _ = jpeg.Decode(f)
+			f.Close()
+		}
+	}
+	return s
+}
+
+// generate 随机选取缺口位置，背景上缺口变暗，拼图从背景同位置抠出并平移到最左侧
+func (s *slider) generate() *sliderImage {
+	canvas := image.NewRGBA(image.Rect(0, 0, sliderWidth, sliderHeight))
+	s.drawBackground(canvas)
+
+	size := pieceSize + knob
+	x := randInt(size+knob, sliderWidth-size)
+	y := randInt(knob*2, sliderHeight-size)
+	piece := image.NewNRGBA(image.Rect(0, 0, size+knob, sliderHeight))
+	for py := 0; py < size+knob; py++ {
+		for px := 0; px < size+knob; px++ {
+			if !inPiece(px, py) {
+				continue
+			}
+			bx, by := x+px, y-knob*2+py
+			c := canvas.RGBAAt(bx, by)
+			if onEdge(px, py) {
+				piece.Set(px, by, color.NRGBA{R: 255, G: 255, B: 255, A: 220})
+			} else {
+				piece.Set(px, by, color.NRGBA{R: c.R, G: c.G, B: c.B, A: 255})
+			}
+			canvas.SetRGBA(bx, by, color.RGBA{R: c.R / 3, G: c.G / 3, B: c.B / 3, A: 255})
+		}
+	}
+
+	r := &sliderImage{x: x}
+	buf := bytes.NewBuffer(nil)
+	_ = png.Encode(buf, canvas)
+	r.bg = buf.Bytes()
+	buf = bytes.NewBuffer(nil)
+	_ = png.Encode(buf, piece)
+	r.piece = buf.Bytes()
+	return r
+}
+
+func (s *slider) drawBackground(canvas *image.RGBA) {
+	if s.bg != nil && s.bg.Bounds().Dx() >= sliderWidth && s.bg.Bounds().Dy() >= sliderHeight {
+		b := s.bg.Bounds()
+		pt := image.Pt(b.Min.X+randInt(0, b.Dx()-sliderWidth+1), b.Min.Y+randInt(0, b.Dy()-sliderHeight+1))
+		draw.Draw(canvas, canvas.Bounds(), s.bg, pt, draw.Src)
+		return
+	}
+	// 随机渐变底色叠加色块，避免纯色背景可直接按像素差定位缺口
+	from := randRGBA()
+	to := randRGBA()
+	for x := 0; x < sliderWidth; x++ {
+		c := color.RGBA{
+			R: mix(from.R, to.R, x, sliderWidth),
+			G: mix(from.G, to.G, x, sliderWidth),
+			B: mix(from.B, to.B, x, sliderWidth),
+			A: 255,
+		}
+		for y := 0; y < sliderHeight; y++ {
+			canvas.SetRGBA(x, y, c)
+		}
+	}
+	for i := 0; i < 24; i++ {
+		w, h := randInt(10, 60), randInt(10, 60)
+		x, y := randInt(-w/2, sliderWidth), randInt(-h/2, sliderHeight)
+		draw.Draw(canvas, image.Rect(x, y, x+w, y+h), image.NewUniform(randRGBA()), image.Point{}, draw.Over)
+	}
+}
+
+// inPiece 拼图形状：方块位于(0,2*knob)起，右侧和上方各有一个半圆凸起
+func inPiece(px, py int) bool {
+	sx, sy := px, py-knob*2
+	if sx >= 0 && sx < pieceSize && sy >= 0 && sy < pieceSize {
+		return true
+	}
+	return inCircle(px, py, pieceSize/2, knob*2, knob) || inCircle(px, py, pieceSize, knob*2+pieceSize/2, knob)
+}
+
+func inCircle(px, py, cx, cy, r int) bool {
+	dx, dy := px-cx, py-cy
+	return dx*dx+dy*dy <= r*r
+}
+
+// onEdge 形状边缘描白边，便于用户辨认
+func onEdge(px, py int) bool {
+	return !inPiece(px-1, py) || !inPiece(px+1, py) || !inPiece(px, py-1) || !inPiece(px, py+1)
+}
+
+func mix(a, b uint8, i, n int) uint8 {
+	return uint8((int(a)*(n-i) + int(b)*i) / n)
+}
+
+func randRGBA() color.NRGBA {
+	return color.NRGBA{R: uint8(randInt(0, 256)), G: uint8(randInt(0, 256)), B: uint8(randInt(0, 256)), A: 160}
+}
@@ -0,0 +1,123 @@
+package cdn
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/*
+cdn地址：数据库中保存对象路径(如img/ab/xxx.png)，输出时按当前环境的域名拼接，私有内容按cdn厂商的鉴权方式签名
+	c, err := cdn.New("https://cdn.domain.cn/", &cdn.Options{Sign: cdn.SignTencentA, Key: "xxx", Private: []string{"file/"}})
+	c.URL("img/ab/xxx.png")             // https://cdn.domain.cn/img/ab/xxx.png
+	c.URL("https://bucket.cos.../a.png") // 旧域名在Aliases中时替换为当前域名
+	c.SignURL("file/ab/xxx.pdf", time.Hour)
+*/
+
+const (
+	SignAliyunA    = "aliyun-a"   // 阿里云鉴权方式A，?auth_key=timestamp-rand-uid-md5
+	SignTencentA   = "tencent-a"  // 腾讯云TypeA，?sign=timestamp-rand-uid-md5
+	SignTencentD   = "tencent-d"  // 腾讯云TypeD，?sign=md5&t=timestamp
+	SignCloudFront = "cloudfront" // CloudFront canned policy，?Expires=&Signature=&Key-Pair-Id=
+)
+
+var ErrSign = errors.New("cdn: unsupported sign type")
+
+type Options struct {
+	Aliases []string // 存量数据中的其他域名(源站、旧cdn)，如 https://bucket.cos.ap-guangzhou.myqcloud.com
+	Sign    string   // 鉴权方式，为空不签名
+	Key     string   // 鉴权密钥，cloudfront为PEM格式的RSA私钥
+	KeyID   string   // cloudfront的Key-Pair-Id
+	Param   string   // 签名参数名，默认阿里云auth_key，腾讯云sign
+	Window  int      // 控制台配置的有效时长秒数，时间戳按过期时间减去有效时长计算；0表示时间戳即过期时间
+	TTL     int      // URL自动签名的有效秒数，默认3600
+	Private []string // 需要签名的路径前缀，URL自动签名
+}
+
+type CDN struct {
+	domain string
+	opt    Options
+	signer signer
+}
+
+// New domain为当前环境的cdn地址，末尾带/；opt为空时只拼接域名
+func New(domain string, opt *Options) (*CDN, error) {
+	c := &CDN{domain: domain}
+	if opt == nil {
+		return c, nil
+	}
+	c.opt = *opt
+	if c.opt.TTL <= 0 {
+		c.opt.TTL = 3600
+	}
+	for i, v := range c.opt.Aliases {
+		c.opt.Aliases[i] = strings.TrimSuffix(v, "/") + "/"
+	}
+	if c.opt.Sign != "" {
+		s, err := newSigner(&c.opt)
+		if err != nil {
+			return nil, err
+		}
+		c.signer = s
+	}
+	return c, nil
+}
+
+// Domain 当前环境的cdn地址
+func (c *CDN) Domain() string {
+	return c.domain
+}
+
+// Key 去掉当前或别名域名，得到保存到数据库的对象路径；其他网址原样返回
+func (c *CDN) Key(raw string) string {
+	if strings.HasPrefix(raw, c.domain) {
+		return strings.TrimPrefix(raw, c.domain)
+	}
+	for _, v := range c.opt.Aliases {
+		if strings.HasPrefix(raw, v) {
+			return strings.TrimPrefix(raw, v)
+		}
+	}
+	return raw
+}
+
+// URL 对象路径或别名域名的网址转为当前域名，私有路径按TTL签名；空值和其他网址原样返回
+func (c *CDN) URL(key string) string {
+	if key == "" {
+		return ""
+	}
+	key = c.Key(key)
+	if strings.Contains(key, "://") {
+		return key
+	}
+	key = strings.TrimPrefix(key, "/")
+	if c.private(key) {
+		return c.SignURL(key, time.Duration(c.opt.TTL)*time.Second)
+	}
+	return c.domain + key
+}
+
+// SignURL 签名ttl后过期的地址，未配置鉴权时返回未签名的地址
+func (c *CDN) SignURL(key string, ttl time.Duration) string {
+	key = strings.TrimPrefix(c.Key(key), "/")
+	raw := c.domain + key
+	if c.signer == nil {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	c.signer.sign(u, time.Now().Add(ttl).Unix())
+	return u.String()
+}
+
+func (c *CDN) private(key string) bool {
+	for _, v := range c.opt.Private {
+		if strings.HasPrefix(key, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package cdn
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"project/pkg/logger"
+	"project/pkg/metrics"
+	"sync"
+	"time"
+)
+
+// 缓存刷新：内容变更后异步调用cdn厂商接口刷新url、目录(以/结尾)或标签，失败按间隔翻倍重试
+
+const (
+	DriverTencent    = "tencent"
+	DriverAliyun     = "aliyun"
+	DriverCloudflare = "cloudflare"
+)
+
+var (
+	ErrDriver         = errors.New("cdn: unsupported purge driver")
+	ErrTagUnsupported = errors.New("cdn: purge by tag unsupported")
+	ErrQueueFull      = errors.New("cdn: purge queue full")
+)
+
+var purgeTotal = metrics.NewCounter("cdn_purge_total", "cdn刷新请求数，result为ok|retry|fail|full", "result")
+
+type Purger interface {
+	// Purge 刷新url，以/结尾的按目录刷新
+	Purge(ctx context.Context, urls ...string) error
+	PurgeByTag(ctx context.Context, tag string) error
+}
+
+type PurgeOptions struct {
+	Driver    string              // tencent|aliyun|cloudflare，为空不刷新
+	SecretID  string              // 腾讯云SecretId、阿里云AccessKeyId
+	SecretKey string              // 腾讯云SecretKey、阿里云AccessKeySecret、cloudflare的API Token
+	Zone      string              // cloudflare的zone id
+	Domain    string              // 阿里云按标签刷新的加速域名
+	Tags      map[string][]string // 厂商不支持按标签刷新时，标签对应刷新的url
+	Retries   int                 // 失败重试次数，默认5，间隔从1秒开始翻倍
+	Queue     int                 // 待刷新的任务数，默认100，满时丢弃并返回ErrQueueFull
+}
+
+// NewPurger 返回异步刷新的Purger，调用立即返回；opt为空或未配置driver时返回nil
+func NewPurger(opt *PurgeOptions, client *http.Client) (*AsyncPurger, error) {
+	if opt == nil || opt.Driver == "" {
+		return nil, nil
+	}
+	var p Purger
+	switch opt.Driver {
+	case DriverTencent:
+		p = &tencentPurger{id: opt.SecretID, key: opt.SecretKey, client: client}
+	case DriverAliyun:
+		p = &aliyunPurger{id: opt.SecretID, key: opt.SecretKey, domain: opt.Domain, client: client}
+	case DriverCloudflare:
+		p = &cloudflarePurger{zone: opt.Zone, token: opt.SecretKey, client: client}
+	default:
+		return nil, ErrDriver
+	}
+	return newAsync(p, opt), nil
+}
+
+type purgeJob struct {
+	URLs []string `json:"urls,omitempty"`
+	Tag  string   `json:"tag,omitempty"`
+}
+
+// AsyncPurger 单个worker按顺序处理，重试期间后续任务排队等待
+type AsyncPurger struct {
+	p       Purger
+	tags    map[string][]string
+	retries int
+
+	mu     sync.RWMutex
+	closed bool
+	ch     chan *purgeJob
+	done   chan struct{}
+}
+
+func newAsync(p Purger, opt *PurgeOptions) *AsyncPurger {
+	a := &AsyncPurger{p: p, tags: opt.Tags, retries: 5, done: make(chan struct{})}
+	if opt.Retries > 0 {
+		a.retries = opt.Retries
+	}
+	queue := 100
+	if opt.Queue > 0 {
+		queue = opt.Queue
+	}
+	a.ch = make(chan *purgeJob, queue)
+	go a.loop()
+	return a
+}
+
+func (a *AsyncPurger) Purge(ctx context.Context, urls ...string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	return a.push(&purgeJob{URLs: urls})
+}
+
+func (a *AsyncPurger) PurgeByTag(ctx context.Context, tag string) error {
+	return a.push(&purgeJob{Tag: tag})
+}
+
+func (a *AsyncPurger) push(job *purgeJob) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return nil
+	}
+	select {
+	case a.ch <- job:
+		return nil
+	default:
+		purgeTotal.Inc("full")
+		return ErrQueueFull
+	}
+}
+
+// Close 停止接收并处理完队列中的任务，应在服务退出前调用
+func (a *AsyncPurger) Close() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.ch)
+	}
+	a.mu.Unlock()
+	<-a.done
+}
+
+func (a *AsyncPurger) loop() {
+	defer close(a.done)
+	for job := range a.ch {
+		a.run(job)
+	}
+}
+
+func (a *AsyncPurger) run(job *purgeJob) {
+	l := logger.FromContext(context.Background())
+	wait := time.Second
+	for i := 0; ; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := a.do(ctx, job)
+		cancel()
+		if err == nil {
+			purgeTotal.Inc("ok")
+			l.Info("cdn.Purge", job, nil)
+			return
+		}
+		if i >= a.retries || errors.Is(err, ErrTagUnsupported) {
+			purgeTotal.Inc("fail")
+			l.Error("cdn.Purge error", job, err)
+			return
+		}
+		purgeTotal.Inc("retry")
+		l.Warn("cdn.Purge retry", job, err)
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// do 按标签刷新不被支持时，刷新标签配置的url
+func (a *AsyncPurger) do(ctx context.Context, job *purgeJob) error {
+	if job.Tag == "" {
+		return a.p.Purge(ctx, job.URLs...)
+	}
+	err := a.p.PurgeByTag(ctx, job.Tag)
+	if errors.Is(err, ErrTagUnsupported) && len(a.tags[job.Tag]) > 0 {
+		return a.p.Purge(ctx, a.tags[job.Tag]...)
+	}
+	return err
+}
+
+// split 按目录和文件分组，每组不超过size个
+func split(urls []string, size int) (dirs, files [][]string) {
+	var d, f []string
+	for _, v := range urls {
+		if v != "" && v[len(v)-1] == '/' {
+			d = append(d, v)
+		} else {
+			f = append(f, v)
+		}
+	}
+	return chunk(d, size), chunk(f, size)
+}
+
+func chunk(list []string, size int) [][]string {
+	var res [][]string
+	for len(list) > size {
+		res = append(res, list[:size])
+		list = list[size:]
+	}
+	if len(list) > 0 {
+		res = append(res, list)
+	}
+	return res
+}
@@ -0,0 +1,238 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"project/pkg/util/random"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tencentPurger 腾讯云CDN API 3.0，TC3-HMAC-SHA256签名
+type tencentPurger struct {
+	id     string
+	key    string
+	client *http.Client
+}
+
+const tencentHost = "cdn.tencentcloudapi.com"
+
+func (p *tencentPurger) Purge(ctx context.Context, urls ...string) error {
+	dirs, files := split(urls, 1000)
+	for _, v := range dirs {
+		if err := p.call(ctx, "PurgePathCache", map[string]any{"Paths": v, "FlushType": "flush"}); err != nil {
+			return err
+		}
+	}
+	for _, v := range files {
+		if err := p.call(ctx, "PurgeUrlsCache", map[string]any{"Urls": v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *tencentPurger) PurgeByTag(ctx context.Context, tag string) error {
+	return ErrTagUnsupported
+}
+
+func (p *tencentPurger) call(ctx context.Context, action string, args any) error {
+	body, _ := json.Marshal(args)
+	now := time.Now().UTC()
+	ts := strconv.FormatInt(now.Unix(), 10)
+	date := now.Format("2006-01-02")
+	payload := sha256.Sum256(body)
+	canonical := "POST\n/\n\ncontent-type:application/json\nhost:" + tencentHost + "\n\ncontent-type;host\n" +
+		hex.EncodeToString(payload[:])
+	scope := date + "/cdn/tc3_request"
+	hashed := sha256.Sum256([]byte(canonical))
+	toSign := "TC3-HMAC-SHA256\n" + ts + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+	secret := hmacSHA256(hmacSHA256(hmacSHA256([]byte("TC3"+p.key), date), "cdn"), "tc3_request")
+	auth := fmt.Sprintf("TC3-HMAC-SHA256 Credential=%s/%s, SignedHeaders=content-type;host, Signature=%s",
+		p.id, scope, hex.EncodeToString(hmacSHA256(secret, toSign)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://"+tencentHost, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Version", "2018-06-06")
+	req.Header.Set("X-TC-Timestamp", ts)
+	var res struct {
+		Response struct {
+			Error *struct {
+				Code    string
+				Message string
+			}
+		}
+	}
+	if err = doJSON(p.client, req, &res); err != nil {
+		return err
+	}
+	if e := res.Response.Error; e != nil {
+		return fmt.Errorf("cdn: tencent %s %s: %s", action, e.Code, e.Message)
+	}
+	return nil
+}
+
+// aliyunPurger 阿里云CDN RPC接口，HMAC-SHA1签名
+type aliyunPurger struct {
+	id     string
+	key    string
+	domain string
+	client *http.Client
+}
+
+func (p *aliyunPurger) Purge(ctx context.Context, urls ...string) error {
+	dirs, files := split(urls, 100)
+	for _, v := range dirs {
+		err := p.call(ctx, map[string]string{"Action": "RefreshObjectCaches", "ObjectType": "Directory", "ObjectPath": strings.Join(v, "\n")})
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range files {
+		err := p.call(ctx, map[string]string{"Action": "RefreshObjectCaches", "ObjectType": "File", "ObjectPath": strings.Join(v, "\n")})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *aliyunPurger) PurgeByTag(ctx context.Context, tag string) error {
+	if p.domain == "" {
+		return ErrTagUnsupported
+	}
+	return p.call(ctx, map[string]string{"Action": "RefreshObjectCacheByCacheTag", "DomainName": p.domain, "Tag": tag})
+}
+
+func (p *aliyunPurger) call(ctx context.Context, args map[string]string) error {
+	args["Format"] = "JSON"
+	args["Version"] = "2018-05-10"
+	args["AccessKeyId"] = p.id
+	args["SignatureMethod"] = "HMAC-SHA1"
+	args["SignatureVersion"] = "1.0"
+	args["SignatureNonce"] = random.UUID()
+	args["Timestamp"] = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = aliyunEscape(k) + "=" + aliyunEscape(args[k])
+	}
+	query := strings.Join(pairs, "&")
+	mac := hmac.New(sha1.New, []byte(p.key+"&"))
+	mac.Write([]byte("GET&%2F&" + aliyunEscape(query)))
+	query += "&Signature=" + aliyunEscape(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://cdn.aliyuncs.com/?"+query, nil)
+	if err != nil {
+		return err
+	}
+	var res struct {
+		Code    string
+		Message string
+	}
+	if err = doJSON(p.client, req, &res); err != nil {
+		return err
+	}
+	if res.Code != "" {
+		return fmt.Errorf("cdn: aliyun %s %s: %s", args["Action"], res.Code, res.Message)
+	}
+	return nil
+}
+
+func aliyunEscape(s string) string {
+	return strings.NewReplacer("+", "%20", "*", "%2A", "%7E", "~").Replace(url.QueryEscape(s))
+}
+
+// cloudflarePurger 按url或Cache-Tag刷新，目录按前缀刷新
+type cloudflarePurger struct {
+	zone   string
+	token  string
+	client *http.Client
+}
+
+func (p *cloudflarePurger) Purge(ctx context.Context, urls ...string) error {
+	dirs, files := split(urls, 30)
+	for _, v := range dirs {
+		prefixes := make([]string, len(v))
+		for i, u := range v {
+			prefixes[i] = strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+		}
+		if err := p.call(ctx, map[string]any{"prefixes": prefixes}); err != nil {
+			return err
+		}
+	}
+	for _, v := range files {
+		if err := p.call(ctx, map[string]any{"files": v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *cloudflarePurger) PurgeByTag(ctx context.Context, tag string) error {
+	return p.call(ctx, map[string]any{"tags": []string{tag}})
+}
+
+func (p *cloudflarePurger) call(ctx context.Context, args any) error {
+	body, _ := json.Marshal(args)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		"https://api.cloudflare.com/client/v4/zones/"+p.zone+"/purge_cache", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	var res struct {
+		Success bool
+		Errors  []struct {
+			Code    int
+			Message string
+		}
+	}
+	if err = doJSON(p.client, req, &res); err != nil {
+		return err
+	}
+	if !res.Success {
+		return fmt.Errorf("cdn: cloudflare purge fail: %v", res.Errors)
+	}
+	return nil
+}
+
+// doJSON 解析响应体，厂商的业务错误在响应体中，非json的5xx直接返回错误
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("cdn: http %d: %w", resp.StatusCode, err)
+	}
+	return nil
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
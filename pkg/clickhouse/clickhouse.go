@@ -0,0 +1,99 @@
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+/*
+ClickHouse HTTP接口的精简客户端，写入使用JSONEachRow格式和服务端异步插入，无需引入原生协议驱动
+	c := clickhouse.New(&cfg, http.DefaultClient)
+	err := c.Migrate(ctx, table)
+	w := clickhouse.NewWriter(c, table.Name, &opt)
+	w.Write(&row)
+*/
+
+type Config struct {
+	URL      string // 如http://127.0.0.1:8123
+	Database string // 默认default
+	User     string
+	Password string
+}
+
+type Client struct {
+	url      string
+	database string
+	user     string
+	password string
+	http     *http.Client
+}
+
+// Error 服务端返回的错误，5xx可重试
+type Error struct {
+	Status int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("clickhouse: status %d: %s", e.Status, e.Msg)
+}
+
+func New(cfg *Config, client *http.Client) *Client {
+	db := cfg.Database
+	if db == "" {
+		db = "default"
+	}
+	return &Client{url: cfg.URL, database: db, user: cfg.User, password: cfg.Password, http: client}
+}
+
+// Exec 执行不返回数据的语句，如DDL
+func (c *Client) Exec(ctx context.Context, query string) error {
+	return c.do(ctx, url.Values{}, bytes.NewBufferString(query))
+}
+
+// Insert 写入JSONEachRow格式的多行，由服务端合并小批次(async_insert)，等待落盘后返回以便失败重试
+func (c *Client) Insert(ctx context.Context, table string, rows [][]byte) error {
+	params := url.Values{}
+	params.Set("query", "INSERT INTO "+table+" FORMAT JSONEachRow")
+	params.Set("async_insert", "1")
+	params.Set("wait_for_async_insert", "1")
+	params.Set("input_format_skip_unknown_fields", "1")
+	params.Set("date_time_input_format", "best_effort")
+	return c.do(ctx, params, bytes.NewReader(bytes.Join(rows, []byte{'\n'})))
+}
+
+func (c *Client) do(ctx context.Context, params url.Values, body io.Reader) error {
+	params.Set("database", c.database)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/?"+params.Encode(), body)
+	if err != nil {
+		return err
+	}
+	if c.user != "" {
+		req.Header.Set("X-ClickHouse-User", c.user)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode != http.StatusOK {
+		return &Error{Status: resp.StatusCode, Msg: string(bytes.TrimSpace(b))}
+	}
+	return nil
+}
+
+// retryable 网络错误和服务端5xx可重试，语法、类型等4xx错误重试无效
+func retryable(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Status >= 500
+	}
+	return !errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,61 @@
+package clickhouse
+
+import (
+	"context"
+	"strings"
+)
+
+// Table 由代码维护的表结构，Migrate创建表并补齐新增的列；修改列类型或删除列需手动执行DDL
+type Table struct {
+	Name        string
+	Columns     []*Column
+	Engine      string // 默认MergeTree
+	PartitionBy string
+	OrderBy     string
+	TTL         string // 如toDateTime(time) + INTERVAL 30 DAY，为空不过期
+}
+
+type Column struct {
+	Name    string
+	Type    string // 如String、LowCardinality(String)、DateTime64(3)
+	Comment string
+}
+
+func (c *Column) ddl() string {
+	s := "`" + c.Name + "` " + c.Type
+	if c.Comment != "" {
+		s += " COMMENT '" + strings.ReplaceAll(c.Comment, "'", "\\'") + "'"
+	}
+	return s
+}
+
+// Migrate 建表和新增列均为IF NOT EXISTS，多实例同时启动时重复执行无影响
+func (c *Client) Migrate(ctx context.Context, t *Table) error {
+	cols := make([]string, len(t.Columns))
+	for i, v := range t.Columns {
+		cols[i] = v.ddl()
+	}
+	engine := t.Engine
+	if engine == "" {
+		engine = "MergeTree"
+	}
+	var b strings.Builder
+	b.WriteString("CREATE TABLE IF NOT EXISTS " + t.Name + " (\n    " + strings.Join(cols, ",\n    ") + "\n)")
+	b.WriteString(" ENGINE = " + engine + "()")
+	if t.PartitionBy != "" {
+		b.WriteString(" PARTITION BY " + t.PartitionBy)
+	}
+	b.WriteString(" ORDER BY (" + t.OrderBy + ")")
+	if t.TTL != "" {
+		b.WriteString(" TTL " + t.TTL)
+	}
+	if err := c.Exec(ctx, b.String()); err != nil {
+		return err
+	}
+	for _, v := range t.Columns {
+		if err := c.Exec(ctx, "ALTER TABLE "+t.Name+" ADD COLUMN IF NOT EXISTS "+v.ddl()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"project/pkg/logger"
+	"project/pkg/metrics"
+	"sync"
+	"time"
+)
+
+var (
+	writtenTotal = metrics.NewCounter("clickhouse_written_total", "写入clickhouse的行数", "table")
+	droppedTotal = metrics.NewCounter("clickhouse_dropped_total",
+		"丢弃的行数，full为缓冲区已满，insert为重试后仍写入失败", "table", "reason")
+)
+
+type Options struct {
+	BatchSize int `mapstructure:"batchSize"` // 每批最多行数，默认1000
+	Interval  int // 未满一批时的写入间隔，单位毫秒，默认2000
+	Buffer    int // 待写入的缓冲行数，默认50000，满时丢弃新记录
+	Retries   int // 写入失败的重试次数，默认3，间隔从200毫秒起翻倍
+	Timeout   int // 单次写入超时秒数，默认10
+}
+
+// Writer 异步批量写入，Write不阻塞调用方；写入慢于产生时积压在缓冲区，缓冲区满后丢弃并计数
+type Writer struct {
+	client   *Client
+	table    string
+	size     int
+	interval time.Duration
+	retries  int
+	timeout  time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	ch     chan []byte
+	done   chan struct{}
+}
+
+func NewWriter(client *Client, table string, opt *Options) *Writer {
+	w := &Writer{
+		client:   client,
+		table:    table,
+		size:     1000,
+		interval: 2 * time.Second,
+		retries:  3,
+		timeout:  10 * time.Second,
+		done:     make(chan struct{}),
+	}
+	if opt.BatchSize > 0 {
+		w.size = opt.BatchSize
+	}
+	if opt.Interval > 0 {
+		w.interval = time.Duration(opt.Interval) * time.Millisecond
+	}
+	if opt.Retries > 0 {
+		w.retries = opt.Retries
+	}
+	if opt.Timeout > 0 {
+		w.timeout = time.Duration(opt.Timeout) * time.Second
+	}
+	buffer := 50000
+	if opt.Buffer > 0 {
+		buffer = opt.Buffer
+	}
+	w.ch = make(chan []byte, buffer)
+	go w.loop()
+	return w
+}
+
+// Write 序列化为一行json放入缓冲区，返回false表示已丢弃
+func (w *Writer) Write(row any) bool {
+	b, err := json.Marshal(row)
+	if err != nil {
+		logger.FromContext(context.Background()).Error("clickhouse.Write json.Marshal error", w.table, err)
+		return false
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return false
+	}
+	select {
+	case w.ch <- b:
+		return true
+	default:
+		droppedTotal.Inc(w.table, "full")
+		return false
+	}
+}
+
+// Close 停止接收并写入缓冲区中剩余的记录，应在服务退出前调用
+func (w *Writer) Close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.ch)
+	}
+	w.mu.Unlock()
+	<-w.done
+}
+
+func (w *Writer) loop() {
+	defer close(w.done)
+	tick := time.NewTicker(w.interval)
+	defer tick.Stop()
+	batch := make([][]byte, 0, w.size)
+	for {
+		select {
+		case b, ok := <-w.ch:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, b)
+			if len(batch) >= w.size {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-tick.C:
+			w.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush 同步写入，重试期间新记录积压在缓冲区，即对上游的背压
+func (w *Writer) flush(batch [][]byte) {
+	if len(batch) == 0 {
+		return
+	}
+	var err error
+	backoff := 200 * time.Millisecond
+	for i := 0; i <= w.retries; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+		err = w.client.Insert(ctx, w.table, batch)
+		cancel()
+		if err == nil {
+			writtenTotal.Add(float64(len(batch)), w.table)
+			return
+		}
+		if !retryable(err) {
+			break
+		}
+	}
+	droppedTotal.Add(float64(len(batch)), w.table, "insert")
+	logger.FromContext(context.Background()).Error("clickhouse.flush error", len(batch), err)
+}
@@ -2,24 +2,63 @@ package coss
 
 import (
 	"context"
+	"errors"
 	"github.com/tencentyun/cos-go-sdk-v5"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
 /* 腾讯云对象存储（Cloud Object Storage，COS）*/
 
-type TCOS interface {
+// Store 读写对象，备份、归档、转码等只需存取文件的包依赖该接口，TCOS满足该接口
+type Store interface {
 	PutObject(ctx context.Context, path string, reader io.Reader) error
+	// GetObject 读取对象内容，调用方需关闭
+	GetObject(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+type TCOS interface {
+	Store
+	// GetRange 读取从offset开始的length字节，length小于0时读到末尾，调用方需关闭
+	GetRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+	// Head 读取对象的大小、ETag、修改时间和类型，不存在时返回ErrNotFound
+	Head(ctx context.Context, path string) (*Object, error)
 	GetSignURL(ctx context.Context, path string, expired time.Duration) (string, error)
+	// List 按前缀分页列举对象，返回的marker为空时已列举完
+	List(ctx context.Context, prefix, marker string, limit int) ([]*Object, string, error)
+	Copy(ctx context.Context, src, dst string) error
+	Delete(ctx context.Context, path string) error
+	// PutLifecycle 覆盖存储桶的全部生命周期规则
+	PutLifecycle(ctx context.Context, rules []*LifecycleRule) error
+}
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("coss: object not found")
+
+type Object struct {
+	Path        string
+	Size        int64
+	ModTime     time.Time
+	ETag        string // 带双引号
+	ContentType string // 仅Head返回
+}
+
+// LifecycleRule 前缀下的对象上传Days天后删除，未完成的分块上传AbortDays天后清理，0不设置
+type LifecycleRule struct {
+	ID        string
+	Prefix    string
+	Days      int
+	AbortDays int
 }
 
 type tcos struct {
 	secretID  string
 	secretKey string
+	host      string
 	client    *cos.Client
 }
 
@@ -39,6 +78,7 @@ func NewTCOS(bucketURL, serviceURL, secretID, secretKey string) TCOS {
 	return &tcos{
 		secretID:  secretID,
 		secretKey: secretKey,
+		host:      bu.Host,
 		client:    client,
 	}
 }
@@ -48,8 +88,96 @@ func (s *tcos) PutObject(ctx context.Context, path string, reader io.Reader) err
 	return err
 }
 
+func (s *tcos) GetObject(ctx context.Context, path string) (io.ReadCloser, error) {
+	return s.GetRange(ctx, path, 0, -1)
+}
+
+func (s *tcos) GetRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	var opt *cos.ObjectGetOptions
+	if offset > 0 || length >= 0 {
+		end := ""
+		if length >= 0 {
+			end = strconv.FormatInt(offset+length-1, 10)
+		}
+		opt = &cos.ObjectGetOptions{Range: "bytes=" + strconv.FormatInt(offset, 10) + "-" + end}
+	}
+	resp, err := s.client.Object.Get(ctx, strings.TrimLeft(path, "/"), opt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return resp.Body, nil
+}
+
+func (s *tcos) Head(ctx context.Context, path string) (*Object, error) {
+	resp, err := s.client.Object.Head(ctx, strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	obj := &Object{
+		Path:        path,
+		Size:        resp.ContentLength,
+		ETag:        resp.Header.Get("ETag"),
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	obj.ModTime, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return obj, nil
+}
+
+func notFound(err error) error {
+	if cos.IsNotFoundError(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
 func (s *tcos) GetSignURL(ctx context.Context, path string, expired time.Duration) (string, error) {
 	u, err := s.client.Object.GetPresignedURL(ctx, http.MethodGet, strings.TrimLeft(path, "/"),
 		s.secretID, s.secretKey, expired, nil)
 	return u.String(), err
 }
+
+func (s *tcos) List(ctx context.Context, prefix, marker string, limit int) ([]*Object, string, error) {
+	res, _, err := s.client.Bucket.Get(ctx, &cos.BucketGetOptions{Prefix: prefix, Marker: marker, MaxKeys: limit})
+	if err != nil {
+		return nil, "", err
+	}
+	list := make([]*Object, len(res.Contents))
+	for i, v := range res.Contents {
+		t, _ := time.Parse(time.RFC3339, v.LastModified)
+		list[i] = &Object{Path: v.Key, Size: v.Size, ModTime: t, ETag: v.ETag}
+	}
+	if !res.IsTruncated {
+		return list, "", nil
+	}
+	next := res.NextMarker
+	if next == "" && len(list) > 0 {
+		next = list[len(list)-1].Path
+	}
+	return list, next, nil
+}
+
+func (s *tcos) Copy(ctx context.Context, src, dst string) error {
+	_, _, err := s.client.Object.Copy(ctx, strings.TrimLeft(dst, "/"), s.host+"/"+strings.TrimLeft(src, "/"), nil)
+	return err
+}
+
+func (s *tcos) Delete(ctx context.Context, path string) error {
+	_, err := s.client.Object.Delete(ctx, strings.TrimLeft(path, "/"))
+	return err
+}
+
+func (s *tcos) PutLifecycle(ctx context.Context, rules []*LifecycleRule) error {
+	opt := &cos.BucketPutLifecycleOptions{Rules: make([]cos.BucketLifecycleRule, len(rules))}
+	for i, v := range rules {
+		rule := cos.BucketLifecycleRule{ID: v.ID, Status: "Enabled", Filter: &cos.BucketLifecycleFilter{Prefix: v.Prefix}}
+		if v.Days > 0 {
+			rule.Expiration = &cos.BucketLifecycleExpiration{Days: v.Days}
+		}
+		if v.AbortDays > 0 {
+			rule.AbortIncompleteMultipartUpload = &cos.BucketLifecycleAbortIncompleteMultipartUpload{DaysAfterInitiation: v.AbortDays}
+		}
+		opt.Rules[i] = rule
+	}
+	_, err := s.client.Bucket.PutLifecycle(ctx, opt)
+	return err
+}
@@ -0,0 +1,83 @@
+package dbtest
+
+import (
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glog "gorm.io/gorm/logger"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+)
+
+/*
+service层的集成测试：
+	mysql使用环境变量TEST_MYSQL_DSN(不含参数)指向的库，未设置时跳过测试；用到的表按design/sql/api-script.sql重建，须使用专用的测试库
+		TEST_MYSQL_DSN='root:123456@tcp(127.0.0.1:3306)/go_project_test' go test ./...
+	redis使用进程内的miniredis，支持lua脚本
+*/
+
+const EnvMysqlDSN = "TEST_MYSQL_DSN"
+
+var createTable = regexp.MustCompile("(?s)CREATE TABLE `(\\w+)` \\(.*?\\n\\)[^;]*;")
+
+// Mysql 连接测试库并重建tables，DSN未设置时跳过测试
+func Mysql(t testing.TB, tables ...string) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv(EnvMysqlDSN)
+	if dsn == "" {
+		t.Skip(EnvMysqlDSN + " not set")
+	}
+	orm, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       dsn + "?charset=utf8mb4&collation=utf8mb4_unicode_ci&parseTime=true&loc=Local",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{Logger: glog.Discard.LogMode(glog.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := loadSchema(t)
+	for _, name := range tables {
+		ddl, ok := schema[name]
+		if !ok {
+			t.Fatalf("table %s not found in api-script.sql", name)
+		}
+		if err = orm.Exec("DROP TABLE IF EXISTS `" + name + "`").Error; err != nil {
+			t.Fatal(err)
+		}
+		if err = orm.Exec(ddl).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := orm.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return orm
+}
+
+// loadSchema 表名 -> 建表语句
+func loadSchema(t testing.TB) map[string]string {
+	_, file, _, _ := runtime.Caller(0)
+	b, err := os.ReadFile(filepath.Join(filepath.Dir(file), "../../../design/sql/api-script.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := make(map[string]string)
+	for _, m := range createTable.FindAllSubmatch(b, -1) {
+		schema[string(m[1])] = string(m[0])
+	}
+	return schema
+}
+
+// Redis 进程内的redis，测试结束时关闭
+func Redis(t testing.TB) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	m := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return rdb, m
+}
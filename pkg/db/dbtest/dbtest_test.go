@@ -0,0 +1,19 @@
+package dbtest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadSchema(t *testing.T) {
+	schema := loadSchema(t)
+	for _, name := range []string{"banner", "ledger_entry", "user_checkin_log"} {
+		ddl := schema[name]
+		if !strings.HasPrefix(ddl, "CREATE TABLE `"+name+"`") || !strings.HasSuffix(ddl, ";") {
+			t.Fatalf("%s: %q", name, ddl)
+		}
+		if strings.Count(ddl, "CREATE TABLE") != 1 {
+			t.Fatalf("%s contains other tables", name)
+		}
+	}
+}
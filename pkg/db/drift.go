@@ -0,0 +1,107 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 表结构漂移：对比design/sql中的建表语句与线上库的information_schema，找出未执行或未登记的变更
+
+var (
+	createReg = regexp.MustCompile("(?i)^\\s*CREATE TABLE (?:IF NOT EXISTS )?`?(\\w+)`?")
+	columnReg = regexp.MustCompile("^\\s*`?(\\w+)`?\\s+(\\w+)")
+	keywords  = map[string]bool{"PRIMARY": true, "KEY": true, "UNIQUE": true, "INDEX": true, "FULLTEXT": true,
+		"CONSTRAINT": true, "FOREIGN": true, "SPATIAL": true, "CHECK": true}
+)
+
+// ParseSchema 从建表语句中读取表的列和类型(不含长度)，只识别每列一行的格式
+func ParseSchema(r io.Reader) (map[string]map[string]string, error) {
+	tables := make(map[string]map[string]string)
+	var cur map[string]string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if m := createReg.FindStringSubmatch(line); m != nil {
+			cur = make(map[string]string)
+			tables[m[1]] = cur
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), ")") {
+			cur = nil
+			continue
+		}
+		m := columnReg.FindStringSubmatch(line)
+		if m == nil || keywords[strings.ToUpper(m[1])] && !strings.HasPrefix(strings.TrimSpace(line), "`") {
+			continue
+		}
+		cur[m[1]] = strings.ToLower(m[2])
+	}
+	return tables, sc.Err()
+}
+
+// Drift 返回定义与线上库的差异，按表名排序；线上库多出的表(如_restore、临时表)不报告
+func Drift(ctx context.Context, orm *gorm.DB, schema map[string]map[string]string) ([]string, error) {
+	var rows []struct {
+		TableName  string
+		ColumnName string
+		DataType   string
+	}
+	err := orm.WithContext(ctx).Raw("SELECT TABLE_NAME AS table_name, COLUMN_NAME AS column_name, DATA_TYPE AS data_type " +
+		"FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE()").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]map[string]string)
+	for _, v := range rows {
+		if live[v.TableName] == nil {
+			live[v.TableName] = make(map[string]string)
+		}
+		live[v.TableName][v.ColumnName] = strings.ToLower(v.DataType)
+	}
+	names := make([]string, 0, len(schema))
+	for t := range schema {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+	var diff []string
+	for _, t := range names {
+		cols, ok := live[t]
+		if !ok {
+			diff = append(diff, fmt.Sprintf("%s: table missing", t))
+			continue
+		}
+		want := schema[t]
+		for _, c := range sortedKeys(want) {
+			typ, ok := cols[c]
+			if !ok {
+				diff = append(diff, fmt.Sprintf("%s.%s: column missing", t, c))
+			} else if typ != want[c] {
+				diff = append(diff, fmt.Sprintf("%s.%s: type %s, want %s", t, c, typ, want[c]))
+			}
+		}
+		for _, c := range sortedKeys(cols) {
+			if _, ok := want[c]; !ok {
+				diff = append(diff, fmt.Sprintf("%s.%s: column not in schema", t, c))
+			}
+		}
+	}
+	return diff, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	list := make([]string, 0, len(m))
+	for k := range m {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
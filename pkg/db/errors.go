@@ -0,0 +1,12 @@
+package db
+
+import (
+	"errors"
+	"github.com/go-sql-driver/mysql"
+)
+
+// IsDuplicateKey 是否为唯一键冲突(mysql 1062)，用于按唯一键去重或判断并发创建
+func IsDuplicateKey(err error) bool {
+	var e *mysql.MySQLError
+	return errors.As(err, &e) && e.Number == 1062
+}
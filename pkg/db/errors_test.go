@@ -0,0 +1,27 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"testing"
+)
+
+func TestIsDuplicateKey(t *testing.T) {
+	dup := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'PRIMARY'"}
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{dup, true},
+		{fmt.Errorf("create: %w", dup), true},
+		{&mysql.MySQLError{Number: 1213}, false},
+		{errors.New("Duplicate entry"), false},
+	}
+	for _, tc := range cases {
+		if got := IsDuplicateKey(tc.err); got != tc.want {
+			t.Fatalf("%v: got %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -23,13 +23,14 @@ type Mysql struct {
 func NewMysqlDB(cfg *Mysql) *gorm.DB {
 	dsn := cfg.Username + ":" + cfg.Password + "@tcp(" + cfg.Address + ")/" + cfg.Database +
 		"?charset=utf8mb4&collation=utf8mb4_unicode_ci&parseTime=true&loc=Local"
-	opt := &gorm.Config{}
+	opt := &gorm.Config{DisableAutomaticPing: true}
 	if cfg.TraceLog {
 		opt.Logger = &gormLog{glog.Discard}
 	} else {
 		opt.Logger = glog.Discard.LogMode(glog.Silent)
 	}
-	orm, err := gorm.Open(mysql.Open(dsn), opt)
+	// 不在此处连接数据库，连通性在启动阶段由boot检查
+	orm, err := gorm.Open(mysql.New(mysql.Config{DSN: dsn, SkipInitializeWithVersion: true}), opt)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+// MoveOptions 迁移一个slot的参数
+type MoveOptions struct {
+	Batch  int  // 每批复制、删除的行数，默认500
+	DryRun bool // 只统计各表待迁移的行数
+}
+
+// MoveSlot 在线迁移slot到另一个分片：
+//  1. 标记迁移中，等待各进程加载后该slot的写入返回ErrSlotMoving，读取仍在旧分片
+//  2. 按id分批复制各表的行(不复制id，由目标分片重新生成)，核对行数
+//  3. 切换映射并取消标记，等待各进程加载后删除旧分片的行
+//
+// 中途失败时slot保持迁移中(只读)，修复后重新执行即可，已复制的行按唯一键忽略
+func (c *Cluster) MoveSlot(ctx context.Context, slot, to int, opt *MoveOptions) error {
+	if !c.Sharded() {
+		return fmt.Errorf("db: shards not configured")
+	}
+	if slot < 0 || slot >= c.slots || to < 0 || to >= len(c.shards) {
+		return fmt.Errorf("db: invalid slot %d or shard %d", slot, to)
+	}
+	batch := 500
+	if opt != nil && opt.Batch > 0 {
+		batch = opt.Batch
+	}
+	var cur ShardSlot
+	if err := c.meta.WithContext(ctx).Take(&cur, "slot = ?", slot).Error; err != nil {
+		return err
+	}
+	if cur.Shard == to && !cur.Moving {
+		return nil
+	}
+	from, dst := c.shards[cur.Shard], c.shards[to]
+	if opt != nil && opt.DryRun {
+		for _, t := range c.tables {
+			var n int64
+			if err := c.slotRows(from.WithContext(ctx), t, slot).Count(&n).Error; err != nil {
+				return err
+			}
+			log.Printf("slot %d: %s %d rows, shard %d -> %d", slot, t, n, cur.Shard, to)
+		}
+		return nil
+	}
+
+	if err := c.meta.WithContext(ctx).Model(&ShardSlot{}).Where("slot = ?", slot).Update("moving", true).Error; err != nil {
+		return err
+	}
+	if err := c.settle(ctx); err != nil {
+		return err
+	}
+	for _, t := range c.tables {
+		if err := c.copySlot(ctx, from, dst, t, slot, batch); err != nil {
+			return fmt.Errorf("copy %s: %w", t, err)
+		}
+	}
+	err := c.meta.WithContext(ctx).Model(&ShardSlot{}).Where("slot = ?", slot).
+		Updates(map[string]any{"shard": to, "moving": false}).Error
+	if err != nil {
+		return err
+	}
+	if err = c.settle(ctx); err != nil {
+		return err
+	}
+	for _, t := range c.tables {
+		if err = c.deleteSlot(ctx, from, t, slot, batch); err != nil {
+			return fmt.Errorf("delete %s: %w", t, err)
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) slotRows(db *gorm.DB, table string, slot int) *gorm.DB {
+	return db.Table(table).Where("MOD(user_id, ?) = ?", c.slots, slot)
+}
+
+// settle 等待两个加载周期，确保各进程使用新的映射，进行中的写入已结束
+func (c *Cluster) settle(ctx context.Context) error {
+	timer := time.NewTimer(2*c.reload + time.Second)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Cluster) copySlot(ctx context.Context, from, to *gorm.DB, table string, slot, batch int) error {
+	var lastID, copied int64
+	for {
+		var rows []map[string]any
+		err := c.slotRows(from.WithContext(ctx), table, slot).Where("id > ?", lastID).
+			Order("id").Limit(batch).Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		lastID = toInt64(rows[len(rows)-1]["id"])
+		for _, r := range rows {
+			delete(r, "id")
+		}
+		if err = to.WithContext(ctx).Table(table).Clauses(clause.Insert{Modifier: "IGNORE"}).Create(&rows).Error; err != nil {
+			return err
+		}
+		copied += int64(len(rows))
+	}
+	var src, dst int64
+	if err := c.slotRows(from.WithContext(ctx), table, slot).Count(&src).Error; err != nil {
+		return err
+	}
+	if err := c.slotRows(to.WithContext(ctx), table, slot).Count(&dst).Error; err != nil {
+		return err
+	}
+	if dst < src {
+		return fmt.Errorf("slot %d: %d rows in source, %d in target", slot, src, dst)
+	}
+	log.Printf("slot %d: %s copied %d rows", slot, table, copied)
+	return nil
+}
+
+func (c *Cluster) deleteSlot(ctx context.Context, db *gorm.DB, table string, slot, batch int) error {
+	for {
+		opt := db.WithContext(ctx).Exec("DELETE FROM `"+table+"` WHERE MOD(user_id, ?) = ? LIMIT ?", c.slots, slot, batch)
+		if opt.Error != nil || opt.RowsAffected < int64(batch) {
+			return opt.Error
+		}
+	}
+}
+
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int32:
+		return int64(n)
+	case uint64:
+		return int64(n)
+	case uint32:
+		return int64(n)
+	case int:
+		return int64(n)
+	}
+	return 0
+}
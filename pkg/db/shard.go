@@ -0,0 +1,285 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+按用户分片：user_id对slot数取模得到slot，slot到分片的映射保存在主库shard_slot表，各进程每reload秒重新加载
+未配置分片时只有主库一个分片，不读取shard_slot；首次启动时按slot%分片数写入映射，之后增加分片不影响已有slot，由MoveSlot迁移
+	db, err := cluster.Write(uid) // 迁移中的slot返回ErrSlotMoving
+	err = cluster.Read(uid).WithContext(ctx).Where("user_id = ?", uid).Find(&list).Error
+	list, err := db.Gather[*model.AuthEvent](ctx, cluster, query, less, 50) // 管理后台的跨分片查询
+*/
+
+type ShardConfig struct {
+	Shards []*Mysql // 分片库，下标为分片号；为空时不分片
+	Slots  int      // slot数，默认1024，上线后不能修改
+	Tables []string // 分片的表，需有user_id列和id以外的唯一键(迁移重试时忽略已复制的行)
+	Reload int      // 重新加载slot映射的秒数，默认10
+}
+
+var ErrSlotMoving = errors.New("db: slot is moving to another shard, retry later")
+
+// ShardSlot slot映射，保存在主库
+type ShardSlot struct {
+	Slot       int       `json:"slot" gorm:"primaryKey;autoIncrement:false"`
+	Shard      int       `json:"shard"`
+	Moving     bool      `json:"moving"`
+	UpdateTime time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+func (*ShardSlot) TableName() string {
+	return "shard_slot"
+}
+
+type Cluster struct {
+	meta   *gorm.DB
+	shards []*gorm.DB
+	slots  int
+	tables []string
+	reload time.Duration
+
+	mu      sync.RWMutex
+	route   []int // 下标为slot
+	moving  []bool
+	started bool
+}
+
+// NewCluster meta为主库，保存slot映射；未配置分片时所有用户都在主库
+func NewCluster(meta *gorm.DB, cfg *ShardConfig) *Cluster {
+	c := &Cluster{meta: meta, slots: 1024, tables: cfg.Tables, reload: 10 * time.Second}
+	if cfg.Slots > 0 {
+		c.slots = cfg.Slots
+	}
+	if cfg.Reload > 0 {
+		c.reload = time.Duration(cfg.Reload) * time.Second
+	}
+	for _, v := range cfg.Shards {
+		c.shards = append(c.shards, NewMysqlDB(v))
+	}
+	if len(c.shards) == 0 {
+		c.shards = []*gorm.DB{meta}
+	}
+	c.route = make([]int, c.slots)
+	c.moving = make([]bool, c.slots)
+	return c
+}
+
+// Sharded 是否配置了多个分片
+func (c *Cluster) Sharded() bool {
+	return c.shards[0] != c.meta
+}
+
+// Start 检查各分片连通性并加载slot映射，之后定期重新加载；可作为boot.Check，已启动时只检查连通性
+func (c *Cluster) Start(ctx context.Context) error {
+	for i, v := range c.shards {
+		sqlDB, err := v.DB()
+		if err == nil {
+			err = sqlDB.PingContext(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+	if !c.Sharded() {
+		return nil
+	}
+	c.mu.RLock()
+	started := c.started
+	c.mu.RUnlock()
+	if started {
+		return nil
+	}
+	if err := c.init(ctx); err != nil {
+		return err
+	}
+	if err := c.Load(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.started {
+		c.started = true
+		go c.loop()
+	}
+	return nil
+}
+
+// init 映射为空时按slot%分片数写入，多个进程同时启动时忽略重复
+func (c *Cluster) init(ctx context.Context) error {
+	var n int64
+	if err := c.meta.WithContext(ctx).Model(&ShardSlot{}).Count(&n).Error; err != nil || n > 0 {
+		return err
+	}
+	list := make([]*ShardSlot, c.slots)
+	for i := range list {
+		list[i] = &ShardSlot{Slot: i, Shard: i % len(c.shards)}
+	}
+	return c.meta.WithContext(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).CreateInBatches(list, 500).Error
+}
+
+// Load 重新加载slot映射，映射指向不存在的分片时返回错误并保留旧映射
+func (c *Cluster) Load(ctx context.Context) error {
+	var list []*ShardSlot
+	if err := c.meta.WithContext(ctx).Find(&list).Error; err != nil {
+		return err
+	}
+	if len(list) != c.slots {
+		return fmt.Errorf("db: shard_slot has %d slots, want %d", len(list), c.slots)
+	}
+	route, moving := make([]int, c.slots), make([]bool, c.slots)
+	for _, v := range list {
+		if v.Slot < 0 || v.Slot >= c.slots || v.Shard < 0 || v.Shard >= len(c.shards) {
+			return fmt.Errorf("db: invalid shard_slot %d -> %d", v.Slot, v.Shard)
+		}
+		route[v.Slot], moving[v.Slot] = v.Shard, v.Moving
+	}
+	c.mu.Lock()
+	c.route, c.moving = route, moving
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Cluster) loop() {
+	tick := time.NewTicker(c.reload)
+	defer tick.Stop()
+	for range tick.C {
+		ctx, cancel := context.WithTimeout(context.Background(), c.reload)
+		if err := c.Load(ctx); err != nil {
+			log.Println("db: reload shard_slot error:", err)
+		}
+		cancel()
+	}
+}
+
+func (c *Cluster) Slot(uid int) int {
+	if uid < 0 {
+		uid = -uid
+	}
+	return uid % c.slots
+}
+
+// Shard 用户所在的分片号
+func (c *Cluster) Shard(uid int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.route[c.Slot(uid)]
+}
+
+// Read 用户所在分片，迁移中仍读旧分片
+func (c *Cluster) Read(uid int) *gorm.DB {
+	return c.shards[c.Shard(uid)]
+}
+
+// Write 用户所在分片，slot迁移中返回ErrSlotMoving，调用方应返回错误由客户端或消息队列重试
+func (c *Cluster) Write(uid int) (*gorm.DB, error) {
+	slot := c.Slot(uid)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.moving[slot] {
+		return nil, ErrSlotMoving
+	}
+	return c.shards[c.route[slot]], nil
+}
+
+// Holds 表是否按用户分片
+func (c *Cluster) Holds(table string) bool {
+	if !c.Sharded() {
+		return false
+	}
+	for _, v := range c.tables {
+		if v == table {
+			return true
+		}
+	}
+	return false
+}
+
+// Shards 全部分片，下标为分片号
+func (c *Cluster) Shards() []*gorm.DB {
+	return c.shards
+}
+
+// Scatter 在每个分片上并发执行fn，任一失败时取消其余分片并返回第一个错误
+func (c *Cluster) Scatter(ctx context.Context, fn func(ctx context.Context, shard int, db *gorm.DB) error) error {
+	if len(c.shards) == 1 {
+		return fn(ctx, 0, c.shards[0])
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	for i, v := range c.shards {
+		wg.Add(1)
+		go func(i int, db *gorm.DB) {
+			defer wg.Done()
+			if err := fn(ctx, i, db); err != nil {
+				once.Do(func() {
+					first = fmt.Errorf("shard %d: %w", i, err)
+					cancel()
+				})
+			}
+		}(i, v)
+	}
+	wg.Wait()
+	return first
+}
+
+// Gather 在每个分片上执行相同的查询(需自带排序和limit)，合并后按less排序取前limit条；limit为0不截断
+func Gather[T any](ctx context.Context, c *Cluster, query func(db *gorm.DB) *gorm.DB, less func(a, b T) bool, limit int) ([]T, error) {
+	var (
+		mu   sync.Mutex
+		list []T
+	)
+	err := c.Scatter(ctx, func(ctx context.Context, _ int, db *gorm.DB) error {
+		var part []T
+		if err := query(db.WithContext(ctx)).Find(&part).Error; err != nil {
+			return err
+		}
+		mu.Lock()
+		list = append(list, part...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return less(list[i], list[j])
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// Sum 在每个分片上执行count或sum查询并求和
+func (c *Cluster) Sum(ctx context.Context, query func(db *gorm.DB) *gorm.DB) (int64, error) {
+	var (
+		mu    sync.Mutex
+		total int64
+	)
+	err := c.Scatter(ctx, func(ctx context.Context, _ int, db *gorm.DB) error {
+		var n int64
+		if err := query(db.WithContext(ctx)).Scan(&n).Error; err != nil {
+			return err
+		}
+		mu.Lock()
+		total += n
+		mu.Unlock()
+		return nil
+	})
+	return total, err
+}
@@ -0,0 +1,119 @@
+package dnscache
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// DialContext 使用缓存解析的地址拨号，同时有IPv6和IPv4地址时按Happy Eyeballs(RFC 8305)先连首选地址族，300ms未成功再并行连另一族
+func (r *Resolver) DialContext(d *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := r.LookupIP(ctx, host)
+		if err != nil {
+			return nil, &net.DNSError{Err: err.Error(), Name: host, IsNotFound: err == ErrNoRecord}
+		}
+		primary, fallback := partition(ips, network)
+		if len(primary) == 0 {
+			return nil, &net.DNSError{Err: "no suitable address", Name: host}
+		}
+		if len(fallback) == 0 {
+			return dialSerial(ctx, d, network, primary, port)
+		}
+		delay := d.FallbackDelay
+		if delay <= 0 {
+			delay = 300 * time.Millisecond
+		}
+		return dialParallel(ctx, d, network, primary, fallback, port, delay)
+	}
+}
+
+// partition 按第一个地址的地址族分为首选和回退两组，tcp4/tcp6只保留对应地址族
+func partition(ips []net.IP, network string) (primary, fallback []net.IP) {
+	for _, ip := range ips {
+		v4 := ip.To4() != nil
+		if network == "tcp4" && !v4 || network == "tcp6" && v4 {
+			continue
+		}
+		if len(primary) == 0 || (primary[0].To4() != nil) == v4 {
+			primary = append(primary, ip)
+		} else {
+			fallback = append(fallback, ip)
+		}
+	}
+	return
+}
+
+func dialSerial(ctx context.Context, d *net.Dialer, network string, ips []net.IP, port string) (net.Conn, error) {
+	var err error
+	for _, ip := range ips {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var conn net.Conn
+		if conn, err = d.DialContext(ctx, network, net.JoinHostPort(ip.String(), port)); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+func dialParallel(ctx context.Context, d *net.Dialer, network string, primary, fallback []net.IP, port string, delay time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	results := make(chan result, 2)
+	race := func(ips []net.IP, primary bool) {
+		conn, err := dialSerial(ctx, d, network, ips, port)
+		results <- result{conn, err, primary}
+	}
+	go race(primary, true)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var firstErr error
+	started, pending := false, 1
+	for {
+		select {
+		case <-timer.C:
+			if !started {
+				started = true
+				pending++
+				go race(fallback, false)
+			}
+		case v := <-results:
+			pending--
+			if v.err == nil {
+				if pending > 0 { // 另一组拨号成功时关闭多余连接
+					go func() {
+						if late := <-results; late.conn != nil {
+							late.conn.Close()
+						}
+					}()
+				}
+				return v.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = v.err
+			}
+			if !started { // 首选地址族全部失败时立即尝试回退
+				started = true
+				pending++
+				timer.Stop()
+				go race(fallback, false)
+				continue
+			}
+			if pending == 0 {
+				return nil, firstErr
+			}
+		}
+	}
+}
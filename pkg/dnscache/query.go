@@ -0,0 +1,213 @@
+package dnscache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"golang.org/x/net/dns/dnsmessage"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+var errServer = errors.New("dnscache: nameserver error")
+
+func nameservers(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var list []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			list = append(list, net.JoinHostPort(fields[1], "53"))
+		}
+	}
+	return list
+}
+
+// query 并发查询A和AAAA，返回全部地址和最小TTL，任一类型有记录即成功
+func (r *Resolver) query(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	if len(r.servers) == 0 || !strings.Contains(host, ".") {
+		return nil, 0, errServer // 单标签主机名可能依赖search域，交给标准库处理
+	}
+	type result struct {
+		ips []net.IP
+		ttl time.Duration
+		err error
+	}
+	ch := make(chan result, 2)
+	for _, t := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		go func(t dnsmessage.Type) {
+			ips, ttl, err := r.exchange(ctx, host, t)
+			ch <- result{ips, ttl, err}
+		}(t)
+	}
+	var ips []net.IP
+	var ttl time.Duration
+	var errs []error
+	for i := 0; i < 2; i++ {
+		v := <-ch
+		if v.ttl > 0 && (ttl == 0 || v.ttl < ttl) {
+			ttl = v.ttl
+		}
+		if v.err != nil {
+			errs = append(errs, v.err)
+			continue
+		}
+		ips = append(ips, v.ips...)
+	}
+	if len(ips) > 0 {
+		return ips, ttl, nil
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrNoRecord) {
+			return nil, 0, err
+		}
+	}
+	return nil, ttl, ErrNoRecord
+}
+
+// exchange 依次尝试各nameserver，NXDOMAIN或无记录返回ErrNoRecord
+func (r *Resolver) exchange(ctx context.Context, host string, t dnsmessage.Type) ([]net.IP, time.Duration, error) {
+	name, err := dnsmessage.NewName(host + ".")
+	if err != nil {
+		return nil, 0, err
+	}
+	id := uint16(rand.Uint32())
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: t, Class: dnsmessage.ClassINET}},
+	}
+	req, err := msg.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+	err = errServer
+	for _, server := range r.servers {
+		var ips []net.IP
+		var ttl time.Duration
+		ips, ttl, err = r.roundTrip(ctx, server, req, id)
+		if err == nil || errors.Is(err, ErrNoRecord) {
+			return ips, ttl, err
+		}
+	}
+	return nil, 0, err
+}
+
+func (r *Resolver) roundTrip(ctx context.Context, server string, req []byte, id uint16) ([]net.IP, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err = conn.Write(req); err != nil {
+		return nil, 0, err
+	}
+	buf := make([]byte, 1232)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, 0, err
+		}
+		var p dnsmessage.Parser
+		h, err := p.Start(buf[:n])
+		if err != nil || h.ID != id {
+			continue // 丢弃不匹配的响应
+		}
+		return parse(&p, h)
+	}
+}
+
+func parse(p *dnsmessage.Parser, h dnsmessage.Header) ([]net.IP, time.Duration, error) {
+	if h.Truncated {
+		return nil, 0, errServer // 地址记录极少超过UDP长度，截断时交给标准库
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, 0, err
+	}
+	switch h.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, negTTL(p), ErrNoRecord
+	default:
+		return nil, 0, errServer
+	}
+	var ips []net.IP
+	var ttl uint32
+	for {
+		a, err := p.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		var ip net.IP
+		switch a.Type {
+		case dnsmessage.TypeA:
+			res, err := p.AResource()
+			if err != nil {
+				return nil, 0, err
+			}
+			ip = net.IP(res.A[:])
+		case dnsmessage.TypeAAAA:
+			res, err := p.AAAAResource()
+			if err != nil {
+				return nil, 0, err
+			}
+			ip = net.IP(res.AAAA[:])
+		default: // CNAME等，TTL同样计入
+			if err := p.SkipAnswer(); err != nil {
+				return nil, 0, err
+			}
+		}
+		if ttl == 0 || a.TTL < ttl {
+			ttl = a.TTL
+		}
+		if ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, negTTL(p), ErrNoRecord
+	}
+	return ips, time.Duration(ttl) * time.Second, nil
+}
+
+// negTTL 无记录时取authority中SOA的minimum作为负缓存时间，没有时返回0使用默认值
+func negTTL(p *dnsmessage.Parser) time.Duration {
+	_ = p.SkipAllAnswers()
+	for {
+		h, err := p.AuthorityHeader()
+		if err != nil {
+			return 0
+		}
+		if h.Type != dnsmessage.TypeSOA {
+			if p.SkipAuthority() != nil {
+				return 0
+			}
+			continue
+		}
+		soa, err := p.SOAResource()
+		if err != nil {
+			return 0
+		}
+		ttl := soa.MinTTL
+		if h.TTL < ttl {
+			ttl = h.TTL
+		}
+		return time.Duration(ttl) * time.Second
+	}
+}
@@ -0,0 +1,133 @@
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/sync/singleflight"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 外部调用的DNS缓存：按记录TTL缓存，过期后先返回旧结果并在后台刷新，解析失败的域名短时间负缓存
+// 直接向/etc/resolv.conf的nameserver查询A和AAAA以获取TTL，失败时回退到标准库解析(含/etc/hosts)
+
+type Config struct {
+	MinTTL   int `mapstructure:"minTtl"`   // TTL下限秒数，默认5
+	MaxTTL   int `mapstructure:"maxTtl"`   // TTL上限秒数，默认300
+	NegTTL   int `mapstructure:"negTtl"`   // 解析失败的缓存秒数，默认10
+	StaleTTL int `mapstructure:"staleTtl"` // 过期后仍可返回旧结果的秒数，默认60
+}
+
+var ErrNoRecord = errors.New("dnscache: no such host")
+
+type entry struct {
+	ips     []net.IP
+	err     error
+	expires time.Time
+}
+
+type Resolver struct {
+	minTTL, maxTTL, negTTL, staleTTL time.Duration
+
+	mu      sync.RWMutex
+	cache   map[string]*entry
+	single  singleflight.Group
+	servers []string
+	timeout time.Duration
+}
+
+func New(cfg *Config) *Resolver {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	return &Resolver{
+		minTTL:   seconds(cfg.MinTTL, 5),
+		maxTTL:   seconds(cfg.MaxTTL, 300),
+		negTTL:   seconds(cfg.NegTTL, 10),
+		staleTTL: seconds(cfg.StaleTTL, 60),
+		cache:    make(map[string]*entry),
+		servers:  nameservers("/etc/resolv.conf"),
+		timeout:  2 * time.Second,
+	}
+}
+
+func seconds(v, def int) time.Duration {
+	if v <= 0 {
+		v = def
+	}
+	return time.Duration(v) * time.Second
+}
+
+// LookupIP 返回host的全部地址，IP直接返回
+func (r *Resolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	r.mu.RLock()
+	e, ok := r.cache[host]
+	r.mu.RUnlock()
+	now := time.Now()
+	if ok && now.Before(e.expires) {
+		return e.ips, e.err
+	}
+	if ok && e.err == nil && now.Before(e.expires.Add(r.staleTTL)) {
+		go func() { _, _ = r.refresh(host) }() // 过期不久时先用旧地址，避免请求等待解析
+		return e.ips, nil
+	}
+	e, err := r.refresh(host)
+	if err != nil {
+		return nil, err
+	}
+	return e.ips, e.err
+}
+
+func (r *Resolver) refresh(host string) (*entry, error) {
+	v, err, _ := r.single.Do(host, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*r.timeout)
+		defer cancel()
+		ips, ttl, err := r.query(ctx, host)
+		if err != nil && !errors.Is(err, ErrNoRecord) {
+			ips, err = r.fallback(ctx, host) // nameserver不可达或只在/etc/hosts中
+			ttl = r.minTTL
+		}
+		e := &entry{ips: ips, err: err}
+		switch {
+		case err != nil:
+			if ttl <= 0 || ttl > r.negTTL { // SOA的负缓存时间不超过negTtl
+				ttl = r.negTTL
+			}
+		case ttl < r.minTTL:
+			ttl = r.minTTL
+		case ttl > r.maxTTL:
+			ttl = r.maxTTL
+		}
+		e.expires = time.Now().Add(ttl)
+		r.mu.Lock()
+		r.cache[host] = e
+		r.mu.Unlock()
+		return e, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*entry), nil
+}
+
+func (r *Resolver) fallback(ctx context.Context, host string) ([]net.IP, error) {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		var e *net.DNSError
+		if errors.As(err, &e) && e.IsNotFound {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, v := range addrs {
+		ips[i] = v.IP
+	}
+	return ips, nil
+}
@@ -0,0 +1,213 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"project/pkg/coss"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+从对象存储代理下载：支持Range断点续传、If-Range、If-None-Match/If-Modified-Since和Content-Disposition，
+响应体边读边写，不在内存中缓冲整个文件，用于报表文件和cdn不能直接访问的私有媒体
+	n, err := download.Serve(c.Writer, c.Request, h.cos, "report/2026/orders.csv", &download.Options{Filename: "订单.csv"})
+只支持单个区间，多区间请求按完整文件返回200；If-Range不匹配时同样返回完整文件
+*/
+
+// Store 对象存储，coss.TCOS满足该接口
+type Store interface {
+	Head(ctx context.Context, path string) (*coss.Object, error)
+	GetRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+}
+
+type Options struct {
+	Filename     string // 保存的文件名，默认为路径中的文件名
+	Inline       bool   // 在浏览器中直接打开(图片、视频、pdf)，默认作为附件下载
+	CacheControl string // 默认private, no-cache，客户端每次按ETag验证
+}
+
+// ErrNotFound 对象不存在，handler应返回404
+var ErrNotFound = coss.ErrNotFound
+
+const bufSize = 32 << 10
+
+// Serve 写出对象，返回写出的响应体字节数；响应头写出前的错误(对象不存在、读取失败)由调用方返回错误响应，
+// 之后的错误(客户端断开、上游中断)只能中止连接，客户端可按Range续传
+func Serve(w http.ResponseWriter, r *http.Request, store Store, name string, opt *Options) (int64, error) {
+	if opt == nil {
+		opt = &Options{}
+	}
+	obj, err := store.Head(r.Context(), name)
+	if err != nil {
+		return 0, err
+	}
+	header := w.Header()
+	header.Set("Accept-Ranges", "bytes")
+	if obj.ETag != "" {
+		header.Set("ETag", obj.ETag)
+	}
+	if !obj.ModTime.IsZero() {
+		header.Set("Last-Modified", obj.ModTime.UTC().Format(http.TimeFormat))
+	}
+	cacheControl := opt.CacheControl
+	if cacheControl == "" {
+		cacheControl = "private, no-cache"
+	}
+	header.Set("Cache-Control", cacheControl)
+	if notModified(r, obj) {
+		w.WriteHeader(http.StatusNotModified)
+		return 0, nil
+	}
+
+	contentType := obj.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(name))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+	filename := opt.Filename
+	if filename == "" {
+		filename = path.Base(name)
+	}
+	header.Set("Content-Disposition", disposition(filename, opt.Inline))
+
+	offset, length, status := int64(0), obj.Size, http.StatusOK
+	if rng := r.Header.Get("Range"); rng != "" && obj.Size > 0 && ifRange(r, obj) {
+		start, end, ok := parseRange(rng, obj.Size)
+		switch {
+		case !ok:
+		case start >= obj.Size:
+			header.Set("Content-Range", "bytes */"+strconv.FormatInt(obj.Size, 10))
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return 0, nil
+		default:
+			offset, length, status = start, end-start+1, http.StatusPartialContent
+			header.Set("Content-Range", "bytes "+strconv.FormatInt(start, 10)+"-"+strconv.FormatInt(end, 10)+
+				"/"+strconv.FormatInt(obj.Size, 10))
+		}
+	}
+	header.Set("Content-Length", strconv.FormatInt(length, 10))
+	if r.Method == http.MethodHead || length == 0 {
+		w.WriteHeader(status)
+		return 0, nil
+	}
+
+	body, err := store.GetRange(r.Context(), name, offset, length)
+	if err != nil {
+		header.Del("Content-Range")
+		header.Del("Content-Length")
+		header.Del("Content-Disposition")
+		return 0, err
+	}
+	defer body.Close()
+	w.WriteHeader(status)
+	n, err := io.CopyBuffer(w, io.LimitReader(body, length), make([]byte, bufSize))
+	if err == nil && n < length {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// IsClientGone 客户端断开或取消请求导致的写出错误，不需要告警
+func IsClientGone(r *http.Request, err error) bool {
+	return errors.Is(err, context.Canceled) || r.Context().Err() != nil
+}
+
+// parseRange 解析单个区间，返回闭区间[start, end]，end已截断到文件末尾；语法错误或多区间返回false
+func parseRange(s string, size int64) (start, end int64, ok bool) {
+	if !strings.HasPrefix(s, "bytes=") || strings.Contains(s, ",") {
+		return 0, 0, false
+	}
+	spec := strings.TrimPrefix(s, "bytes=")
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false
+	}
+	if first == "" { // 最后n字节
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false
+	}
+	end = size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end, true
+}
+
+// ifRange If-Range为ETag时需强匹配，为时间时需与修改时间相同，不满足时忽略Range返回完整文件
+func ifRange(r *http.Request, obj *coss.Object) bool {
+	v := r.Header.Get("If-Range")
+	if v == "" {
+		return true
+	}
+	if strings.HasPrefix(v, `"`) || strings.HasPrefix(v, "W/") {
+		return obj.ETag != "" && !strings.HasPrefix(obj.ETag, "W/") && v == obj.ETag
+	}
+	t, err := http.ParseTime(v)
+	return err == nil && !obj.ModTime.IsZero() && t.Equal(obj.ModTime.Truncate(time.Second))
+}
+
+// notModified If-None-Match优先于If-Modified-Since
+func notModified(r *http.Request, obj *coss.Object) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if obj.ETag == "" {
+			return false
+		}
+		for _, v := range strings.Split(inm, ",") {
+			v = strings.TrimSpace(v)
+			if v == "*" || strings.TrimPrefix(v, "W/") == strings.TrimPrefix(obj.ETag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || obj.ModTime.IsZero() {
+		return false
+	}
+	t, err := http.ParseTime(ims)
+	return err == nil && !obj.ModTime.Truncate(time.Second).After(t)
+}
+
+// disposition 同时带ascii的filename和utf-8的filename*，兼容旧浏览器
+func disposition(filename string, inline bool) string {
+	kind := "attachment"
+	if inline {
+		kind = "inline"
+	}
+	ascii := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	return kind + `; filename="` + ascii + `"; filename*=UTF-8''` + url.PathEscape(filename)
+}
@@ -0,0 +1,76 @@
+package filescan
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamAV 通过clamd的INSTREAM命令扫描，文件大小不能超过clamd的StreamMaxLength(默认25M)
+type clamAV struct {
+	addr    string
+	timeout time.Duration
+}
+
+const clamChunk = 32 << 10
+
+func (s *clamAV) Scan(ctx context.Context, _ string, b []byte) error {
+	network, addr := "tcp", s.addr
+	if strings.HasPrefix(addr, "unix:") {
+		network, addr = "unix", strings.TrimPrefix(addr, "unix:")
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	if _, err = conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+	size := make([]byte, 4)
+	for len(b) > 0 {
+		n := len(b)
+		if n > clamChunk {
+			n = clamChunk
+		}
+		binary.BigEndian.PutUint32(size, uint32(n))
+		if _, err = conn.Write(size); err != nil {
+			return err
+		}
+		if _, err = conn.Write(b[:n]); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err = conn.Write(size); err != nil {
+		return err
+	}
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return err
+	}
+	return clamResult(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// clamResult 解析 stream: OK、stream: Eicar-Signature FOUND、... ERROR
+func clamResult(reply string) error {
+	switch {
+	case strings.HasSuffix(reply, " OK"):
+		return nil
+	case strings.HasSuffix(reply, " FOUND"):
+		sig := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return &Infected{Signature: sig}
+	}
+	return errors.New("clamd: " + reply)
+}
@@ -0,0 +1,88 @@
+package filescan
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"project/pkg/metrics"
+	"time"
+)
+
+// 上传文件的安全扫描：文件写入对象存储前调用clamd或云端扫描服务，发现威胁时拒绝上传，避免恶意文件经cdn分发
+
+const (
+	DriverClamAV = "clamav"
+	DriverHTTP   = "http"
+)
+
+var ErrDriver = errors.New("filescan: unsupported driver")
+
+var scanTotal = metrics.NewCounter("filescan_total", "上传文件扫描数，result为clean|infected|error", "driver", "result")
+
+// Infected 发现威胁，Signature为引擎报告的特征名
+type Infected struct {
+	Signature string
+}
+
+func (e *Infected) Error() string {
+	return "filescan: infected " + e.Signature
+}
+
+type Scanner interface {
+	// Scan 扫描文件内容，发现威胁返回*Infected，扫描服务不可用返回其他错误
+	Scan(ctx context.Context, name string, b []byte) error
+}
+
+type Config struct {
+	Driver   string // clamav|http，为空不扫描
+	Addr     string // clamd地址，如127.0.0.1:3310或unix:/run/clamav/clamd.sock
+	URL      string // http扫描服务地址
+	Token    string // http扫描服务的Bearer令牌
+	Timeout  int    // 单次扫描超时秒数，默认10
+	FailOpen bool   `mapstructure:"failOpen"` // 扫描服务不可用时放行，默认拒绝上传
+}
+
+// New cfg为空或未配置driver时返回nil
+func New(cfg *Config, client *http.Client) (Scanner, error) {
+	if cfg == nil || cfg.Driver == "" {
+		return nil, nil
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	var s Scanner
+	switch cfg.Driver {
+	case DriverClamAV:
+		s = &clamAV{addr: cfg.Addr, timeout: timeout}
+	case DriverHTTP:
+		s = &httpScanner{url: cfg.URL, token: cfg.Token, timeout: timeout, client: client}
+	default:
+		return nil, ErrDriver
+	}
+	return &counted{s: s, driver: cfg.Driver, failOpen: cfg.FailOpen}, nil
+}
+
+// counted 记录扫描结果指标，FailOpen时吞掉扫描服务的错误
+type counted struct {
+	s        Scanner
+	driver   string
+	failOpen bool
+}
+
+func (c *counted) Scan(ctx context.Context, name string, b []byte) error {
+	err := c.s.Scan(ctx, name, b)
+	var e *Infected
+	switch {
+	case err == nil:
+		scanTotal.Inc(c.driver, "clean")
+	case errors.As(err, &e):
+		scanTotal.Inc(c.driver, "infected")
+	default:
+		scanTotal.Inc(c.driver, "error")
+		if c.failOpen {
+			return nil
+		}
+	}
+	return err
+}
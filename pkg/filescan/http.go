@@ -0,0 +1,54 @@
+package filescan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"project/pkg/json"
+	"time"
+)
+
+// httpScanner 对接云端扫描服务或内部的扫描网关：POST文件内容，响应 {"infected":true,"signature":"..."}
+type httpScanner struct {
+	url     string
+	token   string
+	timeout time.Duration
+	client  *http.Client
+}
+
+type httpResult struct {
+	Infected  bool   `json:"infected"`
+	Signature string `json:"signature"`
+}
+
+func (s *httpScanner) Scan(ctx context.Context, name string, b []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-File-Name", url.PathEscape(name))
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("filescan: http status %d", resp.StatusCode)
+	}
+	var r httpResult
+	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return err
+	}
+	if r.Infected {
+		return &Infected{Signature: r.Signature}
+	}
+	return nil
+}
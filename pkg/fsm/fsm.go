@@ -0,0 +1,114 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrTransition = errors.New("fsm: transition not allowed")
+	ErrConflict   = errors.New("fsm: state changed concurrently") // 持久化时条件更新未命中
+	ErrHook       = errors.New("fsm: hook failed")                // 状态已持久化，仅钩子执行失败
+)
+
+// Transition 迁移规则，同一事件可配置多条，按注册顺序取第一条From匹配且Guard通过的规则
+type Transition[S comparable] struct {
+	Event string
+	From  []S
+	To    S
+	Guard func(ctx context.Context, c *Change[S]) error // 可选，返回error时拒绝迁移
+}
+
+// Change 一次状态迁移，To由Machine计算
+type Change[S comparable] struct {
+	Subject  string // 实体标识，如订单号
+	Event    string
+	From     S
+	To       S
+	Operator string // 操作人，如user:1、admin:2、system
+	Remark   string
+	Payload  any // 供Guard判断使用的实体数据
+}
+
+type Hook[S comparable] func(ctx context.Context, c *Change[S]) error
+
+type Machine[S comparable] struct {
+	rules map[string][]*Transition[S]
+	hooks []Hook[S]
+}
+
+func New[S comparable](rules ...*Transition[S]) *Machine[S] {
+	m := &Machine[S]{rules: make(map[string][]*Transition[S])}
+	for _, t := range rules {
+		m.rules[t.Event] = append(m.rules[t.Event], t)
+	}
+	return m
+}
+
+// OnTransition 注册迁移成功(持久化完成)后执行的钩子，按注册顺序执行
+func (m *Machine[S]) OnTransition(h Hook[S]) *Machine[S] {
+	m.hooks = append(m.hooks, h)
+	return m
+}
+
+// Can 仅判断当前状态是否存在该事件的迁移规则，不执行Guard
+func (m *Machine[S]) Can(from S, event string) bool {
+	for _, t := range m.rules[event] {
+		if t.match(from) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next 根据From和Event计算目标状态并写入c.To，无可用规则时返回包装了ErrTransition的错误
+func (m *Machine[S]) Next(ctx context.Context, c *Change[S]) error {
+	var guard error
+	for _, t := range m.rules[c.Event] {
+		if !t.match(c.From) {
+			continue
+		}
+		if t.Guard != nil {
+			if guard = t.Guard(ctx, c); guard != nil {
+				continue
+			}
+		}
+		c.To = t.To
+		return nil
+	}
+	if guard != nil {
+		return fmt.Errorf("%w: %v", ErrTransition, guard)
+	}
+	return ErrTransition
+}
+
+// Fire 计算目标状态后调用persist持久化(需以From作为更新条件，未命中返回ErrConflict)，成功后依次执行钩子
+// 钩子失败不回滚状态，返回包装了ErrHook的第一个钩子错误由调用方记录
+func (m *Machine[S]) Fire(ctx context.Context, c *Change[S], persist func(ctx context.Context, c *Change[S]) error) error {
+	if err := m.Next(ctx, c); err != nil {
+		return err
+	}
+	if err := persist(ctx, c); err != nil {
+		return err
+	}
+	var first error
+	for _, h := range m.hooks {
+		if err := h(ctx, c); err != nil && first == nil {
+			first = err
+		}
+	}
+	if first != nil {
+		return fmt.Errorf("%w: %v", ErrHook, first)
+	}
+	return nil
+}
+
+func (t *Transition[S]) match(from S) bool {
+	for _, s := range t.From {
+		if s == from {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"strconv"
+)
+
+/*
+附近搜索，按类型(如门店)把实体坐标保存在redis GEO，多实例共享；数据库为准，索引可随时重建
+	idx := geo.New(rdb, model.KeyGeo)
+	idx.Set(ctx, "store", &geo.Point{ID: 1, Lng: 113.32, Lat: 23.12}) // 新增或修改坐标
+	idx.Remove(ctx, "store", 1)                                        // 下线
+	hits, err := idx.Nearby(ctx, "store", lng, lat, 5000, 20)          // 5公里内最近的20个，按距离升序
+坐标系需与客户端一致，小程序wx.getLocation使用type: gcj02
+*/
+
+// redis GEO支持的纬度范围
+const (
+	MaxLat = 85.05112878
+	MaxLng = 180
+)
+
+var ErrCoord = errors.New("geo: invalid coordinate")
+
+type Point struct {
+	ID  int
+	Lng float64
+	Lat float64
+}
+
+type Hit struct {
+	ID       int
+	Distance float64 // 米
+}
+
+type Index struct {
+	redis  *redis.Client
+	prefix string
+}
+
+func New(rdb *redis.Client, prefix string) *Index {
+	return &Index{redis: rdb, prefix: prefix}
+}
+
+// Valid 经纬度在redis GEO支持的范围内
+func Valid(lng, lat float64) bool {
+	return lng >= -MaxLng && lng <= MaxLng && lat >= -MaxLat && lat <= MaxLat
+}
+
+func (x *Index) Set(ctx context.Context, kind string, points ...*Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	locs := make([]*redis.GeoLocation, len(points))
+	for i, p := range points {
+		if !Valid(p.Lng, p.Lat) {
+			return ErrCoord
+		}
+		locs[i] = &redis.GeoLocation{Name: strconv.Itoa(p.ID), Longitude: p.Lng, Latitude: p.Lat}
+	}
+	return x.redis.GeoAdd(ctx, x.prefix+kind, locs...).Err()
+}
+
+func (x *Index) Remove(ctx context.Context, kind string, ids ...int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	members := make([]any, len(ids))
+	for i, id := range ids {
+		members[i] = strconv.Itoa(id)
+	}
+	return x.redis.ZRem(ctx, x.prefix+kind, members...).Err()
+}
+
+// Rebuild 写入临时key后整体替换，重建期间查询不受影响；points为空时清空索引
+func (x *Index) Rebuild(ctx context.Context, kind string, points []*Point) error {
+	key := x.prefix + kind
+	if len(points) == 0 {
+		return x.redis.Del(ctx, key).Err()
+	}
+	tmp := key + ":rebuild"
+	if err := x.redis.Del(ctx, tmp).Err(); err != nil {
+		return err
+	}
+	for i := 0; i < len(points); i += 500 {
+		end := i + 500
+		if end > len(points) {
+			end = len(points)
+		}
+		if err := x.Set(ctx, kind+":rebuild", points[i:end]...); err != nil {
+			return err
+		}
+	}
+	return x.redis.Rename(ctx, tmp, key).Err()
+}
+
+// Nearby radius米内最近的limit个，按距离升序
+func (x *Index) Nearby(ctx context.Context, kind string, lng, lat, radius float64, limit int) ([]*Hit, error) {
+	if !Valid(lng, lat) {
+		return nil, ErrCoord
+	}
+	locs, err := x.redis.GeoRadius(ctx, x.prefix+kind, lng, lat, &redis.GeoRadiusQuery{
+		Radius:   radius,
+		Unit:     "m",
+		WithDist: true,
+		Count:    limit,
+		Sort:     "ASC",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]*Hit, 0, len(locs))
+	for _, v := range locs {
+		id, err := strconv.Atoi(v.Name)
+		if err != nil {
+			continue
+		}
+		hits = append(hits, &Hit{ID: id, Distance: v.Dist})
+	}
+	return hits, nil
+}
@@ -0,0 +1,100 @@
+package geoip
+
+import (
+	"github.com/oschwald/maxminddb-golang"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Location 国家为ISO代码，省份和城市为中文名，查询不到时为空
+type Location struct {
+	Country  string `json:"country"`
+	Province string `json:"province,omitempty"`
+	City     string `json:"city,omitempty"`
+}
+
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// DB GeoLite2-City/GeoIP2-City格式的本地库，整个文件读入内存，重载时直接替换
+type DB struct {
+	path   string
+	mod    time.Time
+	reader atomic.Pointer[maxminddb.Reader]
+}
+
+func Open(path string) (*DB, error) {
+	d := &DB{path: path}
+	if _, err := d.reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Watch 按interval检查文件修改时间，变更后重新加载，加载失败继续使用旧库
+func (d *DB) Watch(interval time.Duration, onError func(error)) {
+	for range time.Tick(interval) {
+		if _, err := d.reload(); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+func (d *DB) reload() (bool, error) {
+	fi, err := os.Stat(d.path)
+	if err != nil {
+		return false, err
+	}
+	if fi.ModTime().Equal(d.mod) {
+		return false, nil
+	}
+	b, err := os.ReadFile(d.path)
+	if err != nil {
+		return false, err
+	}
+	r, err := maxminddb.FromBytes(b)
+	if err != nil {
+		return false, err
+	}
+	d.reader.Store(r)
+	d.mod = fi.ModTime()
+	return true, nil
+}
+
+// Lookup 内网地址或库中不存在时返回nil
+func (d *DB) Lookup(ip string) *Location {
+	addr := net.ParseIP(ip)
+	if addr == nil || addr.IsPrivate() || addr.IsLoopback() {
+		return nil
+	}
+	var rec record
+	if err := d.reader.Load().Lookup(addr, &rec); err != nil || rec.Country.ISOCode == "" {
+		return nil
+	}
+	l := &Location{
+		Country: rec.Country.ISOCode,
+		City:    name(rec.City.Names),
+	}
+	if len(rec.Subdivisions) > 0 {
+		l.Province = name(rec.Subdivisions[0].Names)
+	}
+	return l
+}
+
+func name(names map[string]string) string {
+	if n, ok := names["zh-CN"]; ok {
+		return n
+	}
+	return names["en"]
+}
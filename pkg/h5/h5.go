@@ -0,0 +1,141 @@
+package h5
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+/*
+服务端渲染的H5页面(如微信内打开的活动页)：
+	{dir}/layouts/*.html  布局，需定义 {{define "layout"}}，其中 {{template "content" .}} 引用页面内容
+	{dir}/partials/*.html 公共片段，如 {{define "wxconfig"}}
+	{dir}/pages/*.html    每个文件一个页面，定义 {{define "title"}}、{{define "content"}} 等
+模板中 {{asset "css/app.css"}} 输出cdn地址并按本地文件内容加版本，如 https://cdn.domain.cn/h5/css/app.css?v=1a2b3c4d
+*/
+
+var ErrNotFound = errors.New("h5: page not found")
+
+type Options struct {
+	Dir    string // 模板目录，默认h5
+	Static string // 静态文件目录，按文件内容计算asset的版本，需与上传到cdn的一致，默认{dir}/static
+	Prefix string // 静态文件在cdn中的路径前缀，默认h5/
+	Reload bool   // 每次渲染重新加载模板和静态文件版本，用于开发环境
+}
+
+type Renderer struct {
+	cdn string
+	opt Options
+
+	mu     sync.RWMutex
+	pages  map[string]*template.Template
+	hashes map[string]string
+}
+
+// New cdn为静态文件的域名，末尾带/
+func New(cdn string, opt *Options) (*Renderer, error) {
+	r := &Renderer{cdn: cdn, opt: *opt}
+	if r.opt.Dir == "" {
+		r.opt.Dir = "h5"
+	}
+	if r.opt.Static == "" {
+		r.opt.Static = filepath.Join(r.opt.Dir, "static")
+	}
+	if r.opt.Prefix == "" {
+		r.opt.Prefix = "h5/"
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Render 执行页面的layout模板，页面不存在返回ErrNotFound
+func (r *Renderer) Render(w io.Writer, page string, data any) error {
+	if r.opt.Reload {
+		if err := r.load(); err != nil {
+			return err
+		}
+	}
+	r.mu.RLock()
+	t, ok := r.pages[page]
+	r.mu.RUnlock()
+	if !ok {
+		return ErrNotFound
+	}
+	return t.ExecuteTemplate(w, "layout", data)
+}
+
+func (r *Renderer) load() error {
+	hashes, err := hashFiles(r.opt.Static)
+	if err != nil {
+		return err
+	}
+	layouts, err := filepath.Glob(filepath.Join(r.opt.Dir, "layouts", "*.html"))
+	if err != nil {
+		return err
+	}
+	partials, err := filepath.Glob(filepath.Join(r.opt.Dir, "partials", "*.html"))
+	if err != nil {
+		return err
+	}
+	files, err := filepath.Glob(filepath.Join(r.opt.Dir, "pages", "*.html"))
+	if err != nil {
+		return err
+	}
+	funcs := template.FuncMap{"asset": func(p string) string {
+		p = strings.TrimPrefix(p, "/")
+		if v, ok := hashes[p]; ok {
+			return r.cdn + r.opt.Prefix + p + "?v=" + v
+		}
+		return r.cdn + r.opt.Prefix + p
+	}}
+	base, err := template.New("").Funcs(funcs).ParseFiles(append(layouts, partials...)...)
+	if err != nil {
+		return err
+	}
+	pages := make(map[string]*template.Template, len(files))
+	for _, f := range files {
+		t, err := template.Must(base.Clone()).ParseFiles(f)
+		if err != nil {
+			return err
+		}
+		pages[strings.TrimSuffix(filepath.Base(f), ".html")] = t
+	}
+	r.mu.Lock()
+	r.pages, r.hashes = pages, hashes
+	r.mu.Unlock()
+	return nil
+}
+
+// hashFiles 目录下文件内容sha1的前8位，key为相对路径；目录不存在时返回空
+func hashFiles(dir string) (map[string]string, error) {
+	hashes := make(map[string]string)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		sum := sha1.Sum(b)
+		hashes[filepath.ToSlash(rel)] = hex.EncodeToString(sum[:4])
+		return nil
+	})
+	return hashes, err
+}
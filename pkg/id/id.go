@@ -0,0 +1,236 @@
+package id
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+雪花ID：1位符号 + 41位毫秒时间戳(自2024-01-01起约69年) + 10位worker + 12位序列，单个worker每毫秒最多4096个，按时间大致递增
+worker通过Lease租约分配(redis或mysql)，定期续约；续约失败时在租约到期前停止发号，避免两个进程使用同一worker
+租约被其他owner占用时重新获取另一个worker，成功后恢复发号
+	g := id.New(id.NewRedisLease(rdb, "id:worker:"), nil)
+	err := g.Start(ctx) // 启动时获取worker，可作为boot.Check
+	v, err := g.Next()
+*/
+
+const (
+	workerBits   = 10
+	sequenceBits = 12
+	MaxWorker    = 1 << workerBits
+	maxSequence  = 1<<sequenceBits - 1
+)
+
+// epoch 2024-01-01 00:00:00 UTC的毫秒时间戳，修改会导致与已生成的ID重复
+const epoch int64 = 1704067200000
+
+var (
+	ErrNotStarted    = errors.New("id: generator not started")
+	ErrLeaseExpired  = errors.New("id: worker lease expired")
+	ErrLeaseLost     = errors.New("id: worker lease taken by another owner")
+	ErrNoWorker      = errors.New("id: no free worker")
+	ErrClockBackward = errors.New("id: clock moved backwards")
+)
+
+// Lease 分配worker，同一时刻一个worker只能被一个owner持有
+type Lease interface {
+	Acquire(ctx context.Context, owner string, ttl time.Duration) (int, error)    // 没有空闲worker返回ErrNoWorker
+	Renew(ctx context.Context, worker int, owner string, ttl time.Duration) error // 已被其他owner持有返回ErrLeaseLost
+	Release(ctx context.Context, worker int, owner string) error
+}
+
+type Options struct {
+	TTL         int // 租约秒数，默认60，每TTL/3续约一次
+	MaxBackward int // 允许等待的时钟回拨毫秒数，默认10，超过返回ErrClockBackward
+}
+
+type Generator struct {
+	lease       Lease
+	owner       string
+	ttl         time.Duration
+	maxBackward int64
+
+	mu       sync.Mutex
+	worker   int64
+	started  bool
+	validTo  time.Time // 租约有效期，留出一次续约间隔的余量
+	last     int64     // 上次发号的毫秒数(相对epoch)
+	sequence int64
+	stop     context.CancelFunc
+}
+
+func New(lease Lease, opt *Options) *Generator {
+	if opt == nil {
+		opt = &Options{}
+	}
+	g := &Generator{lease: lease, ttl: 60 * time.Second, maxBackward: 10}
+	if opt.TTL > 0 {
+		g.ttl = time.Duration(opt.TTL) * time.Second
+	}
+	if opt.MaxBackward > 0 {
+		g.maxBackward = int64(opt.MaxBackward)
+	}
+	host, _ := os.Hostname()
+	g.owner = fmt.Sprintf("%s:%d:%d", host, os.Getpid(), time.Now().UnixNano())
+	return g
+}
+
+// Start 获取worker并开始续约，已启动时直接返回
+func (g *Generator) Start(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.started {
+		return nil
+	}
+	begin := time.Now()
+	worker, err := g.lease.Acquire(ctx, g.owner, g.ttl)
+	if err != nil {
+		return err
+	}
+	g.worker = int64(worker)
+	g.validTo = begin.Add(g.ttl - g.ttl/3)
+	g.started = true
+	var renewCtx context.Context
+	renewCtx, g.stop = context.WithCancel(context.Background())
+	go g.renew(renewCtx)
+	return nil
+}
+
+// Worker 当前持有的worker
+func (g *Generator) Worker() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return int(g.worker)
+}
+
+func (g *Generator) renew(ctx context.Context) {
+	tick := time.NewTicker(g.ttl / 3)
+	defer tick.Stop()
+	lost := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+		begin := time.Now()
+		rctx, cancel := context.WithTimeout(ctx, g.ttl/3)
+		var err error
+		if lost {
+			err = g.reacquire(rctx)
+		} else {
+			err = g.lease.Renew(rctx, int(g.worker), g.owner, g.ttl)
+		}
+		cancel()
+		if err != nil {
+			log.Printf("id: renew worker %d error: %v", g.worker, err)
+			if errors.Is(err, ErrLeaseLost) {
+				lost = true
+				g.mu.Lock()
+				g.validTo = time.Time{}
+				g.mu.Unlock()
+			}
+			continue
+		}
+		lost = false
+		g.mu.Lock()
+		g.validTo = begin.Add(g.ttl - g.ttl/3)
+		g.mu.Unlock()
+	}
+}
+
+// reacquire 租约丢失后获取新的worker，不再使用原worker：原worker在此期间空闲被重新分到时，持有它再获取一个后释放
+func (g *Generator) reacquire(ctx context.Context) error {
+	old := int(g.worker)
+	worker, err := g.lease.Acquire(ctx, g.owner, g.ttl)
+	if err != nil {
+		return err
+	}
+	if worker == old {
+		worker, err = g.lease.Acquire(ctx, g.owner, g.ttl)
+		if e := g.lease.Release(ctx, old, g.owner); e != nil {
+			log.Printf("id: release worker %d error: %v", old, e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	log.Printf("id: worker %d lost, acquired %d", old, worker)
+	g.mu.Lock()
+	g.worker = int64(worker)
+	g.mu.Unlock()
+	return nil
+}
+
+// Next 时钟回拨不超过MaxBackward时等待追上，否则返回错误
+func (g *Generator) Next() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.started {
+		return 0, ErrNotStarted
+	}
+	now := time.Now()
+	if now.After(g.validTo) {
+		return 0, ErrLeaseExpired
+	}
+	ms := now.UnixMilli() - epoch
+	if ms < g.last {
+		if g.last-ms > g.maxBackward {
+			return 0, fmt.Errorf("%w: %dms", ErrClockBackward, g.last-ms)
+		}
+		time.Sleep(time.Duration(g.last-ms) * time.Millisecond)
+		ms = time.Now().UnixMilli() - epoch
+		if ms < g.last {
+			return 0, fmt.Errorf("%w: %dms", ErrClockBackward, g.last-ms)
+		}
+	}
+	if ms == g.last {
+		g.sequence = (g.sequence + 1) & maxSequence
+		if g.sequence == 0 { // 当前毫秒已用完，等到下一毫秒
+			for ms <= g.last {
+				time.Sleep(100 * time.Microsecond)
+				ms = time.Now().UnixMilli() - epoch
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.last = ms
+	return ms<<(workerBits+sequenceBits) | g.worker<<sequenceBits | g.sequence, nil
+}
+
+// NextString 带前缀的十进制ID，如订单号 E + 19位数字
+func (g *Generator) NextString(prefix string) (string, error) {
+	v, err := g.Next()
+	if err != nil {
+		return "", err
+	}
+	return prefix + strconv.FormatInt(v, 10), nil
+}
+
+// Close 停止续约并释放worker，之后Next返回ErrNotStarted
+func (g *Generator) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.started {
+		return
+	}
+	g.stop()
+	g.started = false
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := g.lease.Release(ctx, int(g.worker), g.owner); err != nil {
+		log.Printf("id: release worker %d error: %v", g.worker, err)
+	}
+}
+
+// Time ID中的生成时间
+func Time(id int64) time.Time {
+	return time.UnixMilli(id>>(workerBits+sequenceBits) + epoch)
+}
@@ -0,0 +1,73 @@
+package id
+
+import (
+	"context"
+	"errors"
+	"project/pkg/db/dbtest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLeaseLost(t *testing.T) {
+	rdb, m := dbtest.Redis(t)
+	g := New(NewRedisLease(rdb, "id:worker:"), &Options{TTL: 1})
+	if err := g.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	old := g.Worker()
+	if _, err := g.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 其他进程占用了worker，续约时停止发号并换一个worker
+	key := "id:worker:" + strconv.Itoa(old)
+	m.Set(key, "other")
+	m.SetTTL(key, time.Minute)
+	deadline := time.Now().Add(3 * time.Second)
+	for g.Worker() == old {
+		if time.Now().After(deadline) {
+			t.Fatal("worker not reacquired")
+		}
+		if _, err := g.Next(); err != nil && !errors.Is(err, ErrLeaseExpired) {
+			t.Fatal(err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if v, _ := m.Get(key); v != "other" {
+		t.Fatalf("old worker owner %s", v)
+	}
+	v, err := g.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := int(v >> sequenceBits & (MaxWorker - 1)); w != g.Worker() || w == old {
+		t.Fatalf("id worker %d, old %d", w, old)
+	}
+	if v, _ := m.Get("id:worker:" + strconv.Itoa(g.Worker())); v != g.owner {
+		t.Fatalf("new worker owner %s", v)
+	}
+}
+
+// 原worker空闲时也不会重新分到
+func TestReacquireSkipOld(t *testing.T) {
+	rdb, m := dbtest.Redis(t)
+	l := NewRedisLease(rdb, "id:worker:")
+	g := New(l, &Options{TTL: 60})
+	for i := 0; i < MaxWorker; i++ {
+		if i != 5 && i != 6 {
+			m.Set("id:worker:"+strconv.Itoa(i), "other")
+		}
+	}
+	g.worker = 5
+	if err := g.reacquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if g.worker != 6 {
+		t.Fatalf("worker %d, want 6", g.worker)
+	}
+	if m.Exists("id:worker:5") {
+		t.Fatal("old worker not released")
+	}
+}
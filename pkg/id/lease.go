@@ -0,0 +1,144 @@
+package id
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// RedisLease worker租约为prefix+worker的key，值为owner
+type RedisLease struct {
+	redis  *redis.Client
+	prefix string
+}
+
+func NewRedisLease(rdb *redis.Client, prefix string) *RedisLease {
+	return &RedisLease{redis: rdb, prefix: prefix}
+}
+
+// Acquire 从随机位置开始依次尝试，减少多个进程同时启动时的冲突
+func (l *RedisLease) Acquire(ctx context.Context, owner string, ttl time.Duration) (int, error) {
+	start := rand.Intn(MaxWorker)
+	for i := 0; i < MaxWorker; i++ {
+		w := (start + i) % MaxWorker
+		ok, err := l.redis.SetNX(ctx, l.prefix+strconv.Itoa(w), owner, ttl).Result()
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return w, nil
+		}
+	}
+	return 0, ErrNoWorker
+}
+
+// KEYS[1]=key ARGV[1]=owner ARGV[2]=ttl毫秒；key已过期时重新占用
+var scriptRenew = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if cur and cur ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+func (l *RedisLease) Renew(ctx context.Context, worker int, owner string, ttl time.Duration) error {
+	n, err := scriptRenew.Run(ctx, l.redis, []string{l.prefix + strconv.Itoa(worker)}, owner, ttl.Milliseconds()).Int()
+	if err == nil && n == 0 {
+		err = ErrLeaseLost
+	}
+	return err
+}
+
+var scriptRelease = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func (l *RedisLease) Release(ctx context.Context, worker int, owner string) error {
+	return scriptRelease.Run(ctx, l.redis, []string{l.prefix + strconv.Itoa(worker)}, owner).Err()
+}
+
+// Worker worker租约的持久化记录，表结构见design/sql；用于没有redis的服务
+type Worker struct {
+	Worker     int       `json:"worker" gorm:"primaryKey;autoIncrement:false"`
+	Owner      string    `json:"owner"`
+	ExpireTime time.Time `json:"expire_time"`
+}
+
+func (*Worker) TableName() string {
+	return "id_worker"
+}
+
+// SQLLease 以应用服务器时间判断过期，各服务器需同步时钟
+type SQLLease struct {
+	mysql *gorm.DB
+}
+
+func NewSQLLease(orm *gorm.DB) *SQLLease {
+	return &SQLLease{mysql: orm}
+}
+
+// Acquire 优先接管已过期的worker，没有时插入新的worker
+func (l *SQLLease) Acquire(ctx context.Context, owner string, ttl time.Duration) (int, error) {
+	now := time.Now()
+	var expired []int
+	err := l.mysql.WithContext(ctx).Model(&Worker{}).Where("expire_time <= ?", now).Limit(10).Pluck("worker", &expired).Error
+	if err != nil {
+		return 0, err
+	}
+	for _, w := range expired {
+		opt := l.mysql.WithContext(ctx).Model(&Worker{}).Where("worker = ? AND expire_time <= ?", w, now).
+			Updates(map[string]any{"owner": owner, "expire_time": now.Add(ttl)})
+		if opt.Error != nil {
+			return 0, opt.Error
+		}
+		if opt.RowsAffected > 0 {
+			return w, nil
+		}
+	}
+	var used []int
+	if err = l.mysql.WithContext(ctx).Model(&Worker{}).Pluck("worker", &used).Error; err != nil {
+		return 0, err
+	}
+	taken := make(map[int]bool, len(used))
+	for _, w := range used {
+		taken[w] = true
+	}
+	for w := 0; w < MaxWorker; w++ {
+		if taken[w] {
+			continue
+		}
+		opt := l.mysql.WithContext(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).
+			Create(&Worker{Worker: w, Owner: owner, ExpireTime: now.Add(ttl)})
+		if opt.Error != nil {
+			return 0, opt.Error
+		}
+		if opt.RowsAffected > 0 {
+			return w, nil
+		}
+	}
+	return 0, ErrNoWorker
+}
+
+func (l *SQLLease) Renew(ctx context.Context, worker int, owner string, ttl time.Duration) error {
+	now := time.Now()
+	opt := l.mysql.WithContext(ctx).Model(&Worker{}).
+		Where("worker = ? AND (owner = ? OR expire_time <= ?)", worker, owner, now).
+		Updates(map[string]any{"owner": owner, "expire_time": now.Add(ttl)})
+	if opt.Error == nil && opt.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return opt.Error
+}
+
+func (l *SQLLease) Release(ctx context.Context, worker int, owner string) error {
+	return l.mysql.WithContext(ctx).Model(&Worker{}).Where("worker = ? AND owner = ?", worker, owner).
+		Update("expire_time", time.Now()).Error
+}
@@ -0,0 +1,21 @@
+//go:build go_json
+
+package json
+
+import json "github.com/goccy/go-json"
+
+const Engine = "go-json"
+
+type (
+	Encoder = json.Encoder
+	Decoder = json.Decoder
+)
+
+var (
+	Marshal       = json.Marshal
+	Unmarshal     = json.Unmarshal
+	MarshalIndent = json.MarshalIndent
+	NewEncoder    = json.NewEncoder
+	NewDecoder    = json.NewDecoder
+	Valid         = json.Valid
+)
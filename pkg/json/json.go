@@ -0,0 +1,31 @@
+//go:build !jsoniter && !go_json
+
+package json
+
+import "encoding/json"
+
+/*
+json编解码，与gin使用相同的编译标签切换实现，请求绑定、响应输出和日志使用同一实现：
+	go build                # encoding/json
+	go build -tags=jsoniter # github.com/json-iterator/go，与标准库兼容的配置
+	go build -tags=go_json  # github.com/goccy/go-json
+错误类型随实现变化，需要区分错误类型(如*json.SyntaxError)的地方仍使用encoding/json
+gin v1.9之前没有sonic标签，升级gin前不提供sonic
+*/
+
+// Engine 当前使用的实现，启动时输出
+const Engine = "encoding/json"
+
+type (
+	Encoder = json.Encoder
+	Decoder = json.Decoder
+)
+
+var (
+	Marshal       = json.Marshal
+	Unmarshal     = json.Unmarshal
+	MarshalIndent = json.MarshalIndent
+	NewEncoder    = json.NewEncoder
+	NewDecoder    = json.NewDecoder
+	Valid         = json.Valid
+)
@@ -0,0 +1,106 @@
+package json
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// 按编译标签运行，子测试名为当前实现：
+//	go test -bench . ./pkg/json
+//	go test -bench . -tags=jsoniter ./pkg/json
+//	go test -bench . -tags=go_json ./pkg/json
+
+type benchOrder struct {
+	ID         int       `json:"id"`
+	OrderNo    string    `json:"order_no"`
+	UserID     int       `json:"user_id"`
+	Amount     int64     `json:"amount"`
+	Status     int8      `json:"status"`
+	Remark     string    `json:"remark,omitempty"`
+	Items      []int     `json:"items"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+// benchOrders 20条订单的列表响应
+func benchOrders() map[string]any {
+	list := make([]*benchOrder, 20)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	for i := range list {
+		list[i] = &benchOrder{
+			ID:         i + 1,
+			OrderNo:    "202405011200000000" + string(rune('a'+i)),
+			UserID:     10086,
+			Amount:     int64(990 * (i + 1)),
+			Status:     1,
+			Remark:     "请尽快发货 <fragile>",
+			Items:      []int{1001, 1002, 1003},
+			CreateTime: now.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return map[string]any{"code": 0, "msg": "", "data": map[string]any{"total": 20, "list": list}}
+}
+
+func TestRoundTrip(t *testing.T) {
+	b, err := Marshal(benchOrders())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Data struct {
+			Total int           `json:"total"`
+			List  []*benchOrder `json:"list"`
+		} `json:"data"`
+	}
+	if err = NewDecoder(bytes.NewReader(b)).Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Data.Total != 20 || len(v.Data.List) != 20 || v.Data.List[19].Amount != 990*20 {
+		t.Fatalf("%s: unexpected result %s", Engine, b)
+	}
+	if !Valid(b) {
+		t.Fatalf("%s: invalid output %s", Engine, b)
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	v := benchOrders()
+	b.Run(Engine, func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Marshal(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	data, _ := Marshal(benchOrders())
+	b.Run(Engine, func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var v map[string]any
+			if err := Unmarshal(data, &v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkEncoder 与日志相同的用法，复用缓冲和Encoder
+func BenchmarkEncoder(b *testing.B) {
+	v := benchOrders()
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	b.Run(Engine, func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf.Reset()
+			if err := enc.Encode(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,23 @@
+//go:build jsoniter
+
+package json
+
+import jsoniter "github.com/json-iterator/go"
+
+const Engine = "jsoniter"
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+type (
+	Encoder = jsoniter.Encoder
+	Decoder = jsoniter.Decoder
+)
+
+var (
+	Marshal       = json.Marshal
+	Unmarshal     = json.Unmarshal
+	MarshalIndent = json.MarshalIndent
+	NewEncoder    = json.NewEncoder
+	NewDecoder    = json.NewDecoder
+	Valid         = json.Valid
+)
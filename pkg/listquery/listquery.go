@@ -0,0 +1,341 @@
+package listquery
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+列表接口统一的分页、排序、过滤参数，只允许Schema中声明的字段和操作，列名不来自请求
+	?page=2&limit=20&sort=-create_time,id&filter[status]=paid&filter[amount][gte]=100&filter[status][in]=paid,shipped
+	spec, err := orderSchema.Parse(c.Request.URL.Query())
+	err = spec.Where(query).Count(&total).Error
+	err = spec.Paginate(spec.Where(query)).Find(&list).Error
+*/
+
+const (
+	OpEq   = "eq"
+	OpNe   = "ne"
+	OpGt   = "gt"
+	OpGte  = "gte"
+	OpLt   = "lt"
+	OpLte  = "lte"
+	OpIn   = "in"   // 逗号分隔，最多100个
+	OpLike = "like" // 前缀匹配，可使用索引
+)
+
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeTime   = "time" // datetime列，值为日期、日期时间或unix秒
+	TypeUnix   = "unix" // 存unix秒的int列，值同TypeTime
+)
+
+var sqlOps = map[string]string{
+	OpEq: "=", OpNe: "<>", OpGt: ">", OpGte: ">=", OpLt: "<", OpLte: "<=", OpIn: "IN", OpLike: "LIKE",
+}
+
+var filterReg = regexp.MustCompile(`^filter\[(\w+)](?:\[(\w+)])?$`)
+
+const maxIn = 100
+
+// Error 参数不合法，Param为出错的查询参数名
+type Error struct {
+	Param string
+	Msg   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("listquery: %s %s", e.Param, e.Msg)
+}
+
+type Field struct {
+	Name   string         // 查询参数中的名称
+	Column string         // 数据库列名，默认同Name
+	Type   string         // 默认TypeString
+	Sort   bool           // 允许排序
+	Ops    []string       // 允许的过滤操作，为空不可过滤
+	Values map[string]any // 取值别名，如paid对应2，设置后只接受别名
+}
+
+type Schema struct {
+	Fields   []*Field
+	Sort     string // 默认排序，如-id
+	Key      string // 唯一列，作为最后的排序条件使分页稳定，默认id
+	Limit    int    // 默认每页条数，默认20
+	MaxLimit int    // 每页最多条数，默认100
+
+	once   sync.Once
+	fields map[string]*Field
+}
+
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+type Filter struct {
+	Column string
+	Op     string
+	Value  any // in为[]any
+}
+
+// Spec 解析后的查询，service层按此拼接sql
+type Spec struct {
+	Page    int
+	Limit   int
+	Sorts   []*Sort
+	Filters []*Filter
+	Key     string
+}
+
+// Offset 从0开始的偏移量
+func (s *Spec) Offset() int {
+	return (s.Page - 1) * s.Limit
+}
+
+// Where 拼接过滤条件，用于count和查询
+func (s *Spec) Where(db *gorm.DB) *gorm.DB {
+	for _, f := range s.Filters {
+		switch f.Op {
+		case OpIn:
+			db = db.Where(f.Column+" IN ?", f.Value)
+		case OpLike:
+			db = db.Where(f.Column+" LIKE ?", escapeLike(f.Value.(string))+"%")
+		default:
+			db = db.Where(f.Column+" "+sqlOps[f.Op]+" ?", f.Value)
+		}
+	}
+	return db
+}
+
+// Paginate 拼接排序和分页
+func (s *Spec) Paginate(db *gorm.DB) *gorm.DB {
+	return s.Order(db).Limit(s.Limit).Offset(s.Offset())
+}
+
+// Order 只拼接排序，用于不分页的导出；排序不含Key时按最后一个排序的方向追加Key，排序值相同的行翻页时不重复、不遗漏
+func (s *Spec) Order(db *gorm.DB) *gorm.DB {
+	key, desc := s.Key != "", false
+	for _, v := range s.Sorts {
+		if v.Desc {
+			db = db.Order(v.Column + " DESC")
+		} else {
+			db = db.Order(v.Column)
+		}
+		if v.Column == s.Key {
+			key = false
+		}
+		desc = v.Desc
+	}
+	if !key {
+		return db
+	}
+	if desc {
+		return db.Order(s.Key + " DESC")
+	}
+	return db.Order(s.Key)
+}
+
+// Has 是否包含指定列的过滤条件，用于要求必须带某些条件的大表
+func (s *Spec) Has(column string) bool {
+	for _, f := range s.Filters {
+		if f.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) field(name string) *Field {
+	s.once.Do(func() {
+		s.fields = make(map[string]*Field, len(s.Fields))
+		for _, v := range s.Fields {
+			if v.Column == "" {
+				v.Column = v.Name
+			}
+			if v.Type == "" {
+				v.Type = TypeString
+			}
+			s.fields[v.Name] = v
+		}
+	})
+	return s.fields[name]
+}
+
+// Parse 解析查询参数，未知的filter字段、不允许的操作或排序字段均返回*Error；兼容旧参数size
+func (s *Schema) Parse(values url.Values) (*Spec, error) {
+	spec := &Spec{Page: 1, Limit: s.Limit, Key: s.Key}
+	if spec.Limit <= 0 {
+		spec.Limit = 20
+	}
+	if spec.Key == "" {
+		spec.Key = "id"
+	}
+	maxLimit := s.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = 100
+	}
+	if v := values.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, &Error{Param: "page", Msg: "must be a positive integer"}
+		}
+		spec.Page = n
+	}
+	for _, k := range []string{"limit", "size"} {
+		v := values.Get(k)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLimit {
+			return nil, &Error{Param: k, Msg: "must be between 1 and " + strconv.Itoa(maxLimit)}
+		}
+		spec.Limit = n
+		break
+	}
+
+	order := values.Get("sort")
+	if order == "" {
+		order = s.Sort
+	}
+	if err := s.parseSort(spec, order); err != nil {
+		return nil, err
+	}
+
+	// 按参数名排序，使生成的sql稳定
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if strings.HasPrefix(k, "filter[") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := values[k]
+		m := filterReg.FindStringSubmatch(k)
+		if m == nil {
+			return nil, &Error{Param: k, Msg: "malformed filter"}
+		}
+		f := s.field(m[1])
+		op := m[2]
+		if op == "" {
+			op = OpEq
+		}
+		if f == nil || !contains(f.Ops, op) {
+			return nil, &Error{Param: k, Msg: "filter not allowed"}
+		}
+		value, err := f.convert(op, v[len(v)-1])
+		if err != nil {
+			return nil, &Error{Param: k, Msg: err.Error()}
+		}
+		spec.Filters = append(spec.Filters, &Filter{Column: f.Column, Op: op, Value: value})
+	}
+	return spec, nil
+}
+
+func (s *Schema) parseSort(spec *Spec, order string) error {
+	if order == "" {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(order, ",") {
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+		f := s.field(name)
+		if f == nil || !f.Sort {
+			return &Error{Param: "sort", Msg: name + " not sortable"}
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		spec.Sorts = append(spec.Sorts, &Sort{Column: f.Column, Desc: desc})
+	}
+	return nil
+}
+
+func (f *Field) convert(op, raw string) (any, error) {
+	if op != OpIn {
+		return f.value(raw)
+	}
+	parts := strings.Split(raw, ",")
+	if len(parts) > maxIn {
+		return nil, fmt.Errorf("at most %d values", maxIn)
+	}
+	list := make([]any, len(parts))
+	for i, p := range parts {
+		v, err := f.value(p)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = v
+	}
+	return list, nil
+}
+
+func (f *Field) value(raw string) (any, error) {
+	raw = strings.TrimSpace(raw)
+	if f.Values != nil {
+		if v, ok := f.Values[raw]; ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("invalid value %q", raw)
+	}
+	switch f.Type {
+	case TypeInt:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", raw)
+		}
+		return n, nil
+	case TypeTime, TypeUnix:
+		t, err := parseTime(raw)
+		if err != nil {
+			return nil, err
+		}
+		if f.Type == TypeUnix {
+			return t.Unix(), nil
+		}
+		return t, nil
+	}
+	if len(raw) > 128 {
+		return nil, fmt.Errorf("value too long")
+	}
+	return raw, nil
+}
+
+var timeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05Z07:00", "2006-01-02"}
+
+func parseTime(raw string) (time.Time, error) {
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(n, 0), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, raw, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", raw)
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
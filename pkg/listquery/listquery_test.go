@@ -0,0 +1,82 @@
+package listquery
+
+import (
+	"fmt"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"net/url"
+	"project/pkg/db/dbtest"
+	"strings"
+	"testing"
+)
+
+var testSchema = &Schema{
+	Fields: []*Field{
+		{Name: "id", Sort: true},
+		{Name: "amount", Type: TypeInt, Sort: true},
+		{Name: "user_id", Type: TypeInt, Sort: true},
+	},
+	Sort:  "-id",
+	Limit: 3,
+}
+
+func TestOrder(t *testing.T) {
+	orm, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		sort string
+		want string
+	}{
+		{"", "ORDER BY id DESC"},
+		{"amount", "ORDER BY amount,id"},
+		{"-amount", "ORDER BY amount DESC,id DESC"},
+		{"amount,-user_id", "ORDER BY amount,user_id DESC,id DESC"},
+		{"-amount,id", "ORDER BY amount DESC,id"},
+	}
+	for _, tc := range cases {
+		spec, err := testSchema.Parse(url.Values{"sort": {tc.sort}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows []map[string]any
+		sql := spec.Paginate(orm.Table("order_info")).Find(&rows).Statement.SQL.String()
+		if !strings.Contains(sql, tc.want+" LIMIT") {
+			t.Fatalf("sort %q: %s", tc.sort, sql)
+		}
+	}
+}
+
+// 排序值全部相同时逐页拉取，每行出现且只出现一次
+func TestPaginateDuplicates(t *testing.T) {
+	orm := dbtest.Mysql(t, "order_info")
+	for i := 1; i <= 10; i++ {
+		err := orm.Exec("INSERT INTO order_info (order_no, amount) VALUES (?, ?)", fmt.Sprintf("T%02d", i), 100).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, sort := range []string{"amount", "-amount"} {
+		seen := make(map[int]bool)
+		for page := 1; page <= 4; page++ {
+			spec, err := testSchema.Parse(url.Values{"sort": {sort}, "page": {fmt.Sprint(page)}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			if err = spec.Paginate(orm.Table("order_info")).Pluck("id", &ids).Error; err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range ids {
+				if seen[id] {
+					t.Fatalf("sort %s: id %d repeated on page %d", sort, id, page)
+				}
+				seen[id] = true
+			}
+		}
+		if len(seen) != 10 {
+			t.Fatalf("sort %s: %d rows, want 10", sort, len(seen))
+		}
+	}
+}
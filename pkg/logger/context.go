@@ -33,6 +33,10 @@ func NewCtxLog(tid, v1, v2, v3 string) (context.Context, Logger) {
 }
 
 func FromContext(ctx context.Context) Logger {
+	return fromContext(ctx)
+}
+
+func fromContext(ctx context.Context) *logger {
 	return &logger{
 		TraceId: ctx.Value("trace_id"),
 		V1:      ctx.Value("v1"),
@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"project/pkg/metrics"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 对冲请求：对配置的host发出的GET、HEAD请求，超过延迟仍未返回时再发一次，取先返回的响应并取消另一个
+// 只用于只读且有严格耗时要求的上游，会增加上游的请求量，upstream_hedged_total{result="sent"}与upstream_requests_total之比为对冲率
+
+type HedgeConfig struct {
+	Host  string // 目标host，如 api.weixin.qq.com
+	Delay int    // 首次请求超过该毫秒数未返回时对冲，0按该host最近请求耗时的p95
+	Min   int    // 按p95对冲时的最小延迟毫秒，默认20，避免上游很快时几乎每个请求都对冲
+}
+
+const (
+	hedgeSamples    = 200 // 计算p95的最近请求数
+	hedgeMinSamples = 50  // 样本不足时不按p95对冲
+)
+
+var upstreamHedged = metrics.NewCounter("upstream_hedged_total", "对冲请求数，sent为发出，won为对冲请求先返回",
+	"host", "result")
+
+type hedger struct {
+	host  string
+	delay time.Duration
+	min   time.Duration
+
+	mu      sync.Mutex
+	samples []time.Duration // 环形缓冲
+	next    int
+}
+
+func newHedgers(list []*HedgeConfig) map[string]*hedger {
+	hedgers := make(map[string]*hedger, len(list))
+	for _, v := range list {
+		h := &hedger{
+			host:    strings.ToLower(v.Host),
+			delay:   time.Duration(v.Delay) * time.Millisecond,
+			min:     time.Duration(orDefault(v.Min, 20)) * time.Millisecond,
+			samples: make([]time.Duration, 0, hedgeSamples),
+		}
+		hedgers[h.host] = h
+	}
+	return hedgers
+}
+
+func hedgerFor(req *http.Request) *hedger {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return hedgers[strings.ToLower(req.URL.Hostname())]
+}
+
+func (h *hedger) observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.samples) < hedgeSamples {
+		h.samples = append(h.samples, d)
+		return
+	}
+	h.samples[h.next] = d
+	h.next = (h.next + 1) % hedgeSamples
+}
+
+// after 对冲延迟，0不对冲
+func (h *hedger) after() time.Duration {
+	if h.delay > 0 {
+		return h.delay
+	}
+	h.mu.Lock()
+	if len(h.samples) < hedgeMinSamples {
+		h.mu.Unlock()
+		return 0
+	}
+	list := make([]time.Duration, len(h.samples))
+	copy(list, h.samples)
+	h.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	if p95 := list[len(list)*95/100]; p95 > h.min {
+		return p95
+	}
+	return h.min
+}
+
+type hedgeResult struct {
+	resp  *http.Response
+	err   error
+	hedge bool
+}
+
+// roundTrip 请求体需为空，两次请求共用req的参数
+func (h *hedger) roundTrip(rt http.RoundTripper, req *http.Request) (*http.Response, error) {
+	delay := h.after()
+	ch := make(chan *hedgeResult, 2)
+	var cancels [2]context.CancelFunc // 0为首次请求，1为对冲请求
+	start := func(hedge bool) {
+		ctx, cancel := context.WithCancel(req.Context())
+		if hedge {
+			cancels[1] = cancel
+		} else {
+			cancels[0] = cancel
+		}
+		r := req.Clone(ctx)
+		r.Body = http.NoBody
+		go func() {
+			begin := time.Now()
+			resp, err := rt.RoundTrip(r)
+			if err == nil {
+				h.observe(time.Since(begin))
+			}
+			ch <- &hedgeResult{resp: resp, err: err, hedge: hedge}
+		}()
+	}
+	start(false)
+	inflight := 1
+	var timer <-chan time.Time
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		timer = t.C
+	}
+	for {
+		select {
+		case <-timer:
+			upstreamHedged.Inc(h.host, "sent")
+			start(true)
+			inflight++
+		case r := <-ch:
+			inflight--
+			won, lost := cancels[0], cancels[1]
+			if r.hedge {
+				won, lost = lost, won
+			}
+			if r.err != nil && inflight > 0 { // 另一个请求可能成功
+				won()
+				timer = nil
+				continue
+			}
+			if r.hedge {
+				upstreamHedged.Inc(h.host, "won")
+			}
+			if inflight > 0 {
+				lost()
+				go discard(ch)
+			}
+			if r.err != nil {
+				won()
+				return nil, r.err
+			}
+			r.resp.Body = &cancelBody{ReadCloser: r.resp.Body, cancel: won}
+			return r.resp, nil
+		}
+	}
+}
+
+// discard 关闭已取消的请求的响应
+func discard(ch chan *hedgeResult) {
+	if r := <-ch; r.resp != nil {
+		_ = r.resp.Body.Close()
+	}
+}
+
+// cancelBody 响应读完或关闭后才取消请求的context
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.cancel()
+	}
+	return n, err
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -4,13 +4,23 @@ import (
 	"bytes"
 	"io"
 	"net/http"
+	"project/pkg/metrics"
 	"strconv"
 	"strings"
 	"time"
 )
 
+var (
+	upstreamTotal = metrics.NewCounter("upstream_requests_total", "按目标host统计的外部调用数",
+		"host", "status")
+	upstreamDuration = metrics.NewHistogram("upstream_request_duration_seconds", "按目标host统计的外部调用耗时",
+		metrics.DefaultBuckets, "host")
+)
+
 type transport struct {
 	transport http.RoundTripper
+	egress    bool   // 检查出站白名单，NewTransport包装的内部转发不检查
+	name      string // app.http.clients和录制使用的client名称
 }
 
 func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -21,27 +31,63 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 		"query":   SpreadMaps(req.URL.Query()),
 		"headers": SpreadMaps(req.Header),
 	}
-	b, _ := io.ReadAll(req.Body)
-	req.Body = io.NopCloser(bytes.NewReader(b))
+	var b []byte
+	if req.Body != nil && req.Body != http.NoBody { // GET等无请求体时为nil
+		b, _ = io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
 	input["body"] = Compress(b)
+	reqBody := b
 	begin := time.Now()
-	resp, err := t.transport.RoundTrip(req)
+	var resp *http.Response
+	var err error
+	if !t.egress || allowEgress(req.URL.Hostname()) {
+		if h := hedgerFor(req); h != nil && len(b) == 0 {
+			resp, err = h.roundTrip(t.transport, req)
+		} else {
+			resp, err = t.transport.RoundTrip(req)
+		}
+	} else {
+		err = ErrEgress // 包括跳转到不在白名单的地址
+	}
+	l := fromContext(req.Context()) // 继承上游请求的trace_id和v1~v3，按trace_id可查出一次请求的全部外部调用
+	upstreamDuration.Observe(time.Since(begin).Seconds(), req.URL.Host)
 	if err == nil {
+		upstreamTotal.Inc(req.URL.Host, strconv.Itoa(resp.StatusCode))
 		b, _ = io.ReadAll(resp.Body)
 		resp.Body = io.NopCloser(bytes.NewReader(b))
-		FromContext(req.Context()).Trace("request", input, map[string]any{
+		lv := levelInfo
+		if resp.StatusCode >= http.StatusInternalServerError {
+			lv = levelWarn
+		}
+		l.stash(lv, msgUpstream, input, map[string]any{
 			"body":   Compress(b),
 			"status": resp.StatusCode,
-		}, begin)
+		}, time.Since(begin).Milliseconds())
 	} else {
-		FromContext(req.Context()).Error("request", input, err)
+		upstreamTotal.Inc(req.URL.Host, "error")
+		l.stash(levelError, msgUpstream, input, map[string]any{
+			"error":  err.Error(),
+			"status": 0,
+		}, time.Since(begin).Milliseconds())
+	}
+	if r := recorderFor(t.name); r != nil {
+		record(r, t.name, req, reqBody, resp, b, err, begin)
 	}
 	return resp, err
 }
 
+// msgUpstream 外部调用日志，与接口的access日志区分
+const msgUpstream = "upstream"
+
 func NewHttpClient(timeout time.Duration) *http.Client {
+	return NewClient("", timeout)
+}
+
+// NewClient 按名称使用app.http.clients中配置的代理，未配置时使用app.http.proxy
+func NewClient(name string, timeout time.Duration) *http.Client {
 	client := &http.Client{
-		Transport: &transport{transport: http.DefaultTransport},
+		Transport: &transport{transport: clientTransport(name), egress: true, name: name},
 		Timeout:   timeout,
 	}
 	return client
@@ -62,7 +108,8 @@ func SpreadMaps(h map[string][]string) map[string]string {
 // Compress 超过2048字节返回截断中间的内容
 func Compress(b []byte) string {
 	if l := len(b); l > 2048 {
-		buf := bytes.NewBuffer(nil)
+		var buf strings.Builder
+		buf.Grow(2048)
 		buf.Write(b[:1000])
 		buf.WriteString("***省略{")
 		buf.WriteString(strconv.Itoa(l - 2000))
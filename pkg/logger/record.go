@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 外部调用录制：调试时按client名称记录请求和响应(脱敏后)，由Recorder按trace_id保存，用于排查和在预发环境重放
+
+// Exchange 一次外部调用
+type Exchange struct {
+	TraceID  string            `json:"trace_id"`
+	Client   string            `json:"client"`
+	Time     time.Time         `json:"time"`
+	Method   string            `json:"method"`
+	URL      string            `json:"url"` // 脱敏后的完整地址
+	Header   map[string]string `json:"header"`
+	Body     string            `json:"body"`
+	Status   int               `json:"status"`
+	Response string            `json:"response"`
+	Error    string            `json:"error,omitempty"`
+	Duration int64             `json:"duration"` // 毫秒
+}
+
+type Recorder interface {
+	Record(ctx context.Context, ex *Exchange)
+}
+
+// Redacted 脱敏后的值，重放时含有该值的参数需由目标环境自行补全
+const Redacted = "***"
+
+const recordMaxBody = 64 << 10
+
+var (
+	recordMu      sync.RWMutex
+	recorder      Recorder
+	recordClients map[string]bool
+)
+
+// sensitive 需脱敏的查询参数、请求头和json字段，按小写匹配
+var sensitive = map[string]bool{
+	"access_token": true, "secret": true, "corpsecret": true, "appsecret": true, "js_code": true, "code": true,
+	"password": true, "sign": true, "signature": true, "authorization": true, "cookie": true, "set-cookie": true,
+	"id_card": true, "phone": true, "phone_number": true, "mobile": true,
+}
+
+var jsonField = regexp.MustCompile(`"(\w+)"\s*:\s*"(?:[^"\\]|\\.)*"`)
+
+// SetRecorder 录制clients中名称的client的外部调用，r为nil时停止录制
+func SetRecorder(r Recorder, clients []string) {
+	recordMu.Lock()
+	defer recordMu.Unlock()
+	recorder = r
+	recordClients = make(map[string]bool, len(clients))
+	for _, v := range clients {
+		recordClients[v] = true
+	}
+}
+
+func recorderFor(name string) Recorder {
+	recordMu.RLock()
+	defer recordMu.RUnlock()
+	if recorder == nil || !recordClients[name] {
+		return nil
+	}
+	return recorder
+}
+
+func record(r Recorder, name string, req *http.Request, body []byte, resp *http.Response, respBody []byte,
+	err error, begin time.Time) {
+	ex := &Exchange{
+		Client:   name,
+		Time:     begin,
+		Method:   req.Method,
+		URL:      redactURL(req),
+		Header:   make(map[string]string, len(req.Header)),
+		Body:     redactBody(req.Header.Get("Content-Type"), body),
+		Duration: time.Since(begin).Milliseconds(),
+	}
+	if tid, ok := req.Context().Value("trace_id").(string); ok {
+		ex.TraceID = tid
+	}
+	for k, v := range req.Header {
+		if sensitive[strings.ToLower(k)] {
+			ex.Header[k] = Redacted
+		} else {
+			ex.Header[k] = strings.Join(v, "|")
+		}
+	}
+	if err != nil {
+		ex.Error = err.Error()
+	} else {
+		ex.Status = resp.StatusCode
+		ex.Response = redactBody(resp.Header.Get("Content-Type"), respBody)
+	}
+	r.Record(req.Context(), ex)
+}
+
+func redactURL(req *http.Request) string {
+	u := *req.URL
+	q := u.Query()
+	for k := range q {
+		if sensitive[strings.ToLower(k)] {
+			q.Set(k, Redacted)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// redactBody 文件等二进制内容只记录长度，json按字段名脱敏
+func redactBody(contentType string, b []byte) string {
+	if strings.HasPrefix(contentType, "multipart/") || strings.HasPrefix(contentType, "image/") {
+		return "[binary " + contentType + "]"
+	}
+	if len(b) > recordMaxBody {
+		b = b[:recordMaxBody]
+	}
+	return jsonField.ReplaceAllStringFunc(string(b), func(s string) string {
+		m := jsonField.FindStringSubmatch(s)
+		if !sensitive[strings.ToLower(m[1])] {
+			return s
+		}
+		return `"` + m[1] + `":"` + Redacted + `"`
+	})
+}
@@ -2,9 +2,9 @@ package logger
 
 import (
 	"bytes"
-	"encoding/json"
 	"log"
 	"os"
+	"project/pkg/json"
 	"sync"
 	"time"
 )
@@ -36,23 +36,54 @@ func SetOutput(output string) {
 	})
 }
 
+// encoder 复用编码缓冲和json.Encoder，高并发时每条日志不再分配新的缓冲
+type encoder struct {
+	buf *bytes.Buffer
+	enc *json.Encoder
+}
+
+// encoderMaxCap 超过该容量的缓冲不放回，避免个别大日志长期占用内存
+const encoderMaxCap = 64 << 10
+
+var encoders = sync.Pool{New: func() any {
+	e := &encoder{buf: bytes.NewBuffer(make([]byte, 0, 4<<10))}
+	e.enc = json.NewEncoder(e.buf)
+	e.enc.SetEscapeHTML(false)
+	return e
+}}
+
+// encode 编码为一整块后写入，并发写入时不会交错
+func encode(c *columns, indent bool) {
+	e := encoders.Get().(*encoder)
+	if indent {
+		e.enc.SetIndent("", "\t")
+	}
+	if err := e.enc.Encode(c); err == nil {
+		if indent {
+			colorNum = (colorNum + 3) & 7 // 相邻日志使用不同颜色(黄青红蓝灰绿紫黑)
+			appLog.Printf("\x1b[0;%dm%s\x1b[0m", colorNum+30, e.buf)
+		} else {
+			_, _ = appLog.Writer().Write(e.buf.Bytes())
+		}
+	}
+	if e.buf.Cap() <= encoderMaxCap {
+		if indent { // 放回前还原缩进，切换输出方式后取到的编码器不会带缩进
+			e.enc.SetIndent("", "")
+		}
+		e.buf.Reset()
+		encoders.Put(e)
+	}
+}
+
 func setLogToStdout() {
 	handle = func(c *columns) {
-		enc := json.NewEncoder(appLog.Writer())
-		enc.SetEscapeHTML(false)
-		_ = enc.Encode(c)
+		encode(c, false)
 	}
 }
 
 func setLogToFormat() {
 	handle = func(c *columns) {
-		b := bytes.NewBuffer(nil)
-		enc := json.NewEncoder(b)
-		enc.SetEscapeHTML(false)
-		enc.SetIndent("", "\t")
-		_ = enc.Encode(c)
-		colorNum = (colorNum + 3) & 7 // 相邻日志使用不同颜色(黄青红蓝灰绿紫黑)
-		appLog.Printf("\x1b[0;%dm%s\x1b[0m", colorNum+30, b)
+		encode(c, true)
 	}
 }
 
@@ -65,9 +96,7 @@ func setLogToFile() {
 	lastHandle = file
 	appLog.SetOutput(file)
 	handle = func(c *columns) {
-		enc := json.NewEncoder(appLog.Writer())
-		enc.SetEscapeHTML(false)
-		_ = enc.Encode(c)
+		encode(c, false)
 	}
 
 	go func() {
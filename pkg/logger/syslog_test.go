@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testColumns() *columns {
+	return &columns{
+		logger: &logger{TraceId: "bWFpbi10cmFjZS1pZA", V1: "POST/api/v1/wx/order"},
+		Level:  levelInfo,
+		Time:   time.Now().Format("2006/01/02-15:04:05.000000"),
+		Msg:    "access",
+		Input: map[string]any{
+			"path":    "/api/v1/wx/order",
+			"headers": map[string]any{"Content-Type": "application/json", "Authorization": "token"},
+			"body":    strings.Repeat(`{"sku_id":1001,"qty":2},`, 30),
+		},
+		Output:  map[string]any{"body": strings.Repeat(`{"id":1,"name":"<b>name</b>"},`, 100), "status": 200},
+		Elapsed: 3,
+	}
+}
+
+// 格式化输出后放回的编码器不能带着缩进用于单行输出
+func TestEncodeResetIndent(t *testing.T) {
+	var buf bytes.Buffer
+	appLog.SetOutput(&buf)
+	defer appLog.SetOutput(io.Discard)
+
+	c := testColumns()
+	encode(c, true)
+	buf.Reset()
+	for i := 0; i < 10; i++ {
+		encode(c, false)
+	}
+	if strings.Contains(buf.String(), "\t") {
+		t.Fatalf("single line log contains indent: %q", buf.String())
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 10 {
+		t.Fatalf("want 10 lines, got %d", n)
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	appLog.SetOutput(io.Discard)
+	c := testColumns()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			encode(c, false)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+进程内的实时请求统计，按秒分桶保留最近60秒，不经过Prometheus抓取周期，用于发布时实时观察
+	var live = metrics.NewLive()
+	live.Observe(time.Since(begin), status >= 500)
+	stats := live.Snapshot(10 * time.Second)
+每秒最多保留liveSamples个耗时样本(蓄水池抽样)计算分位数，高QPS时为近似值
+*/
+
+const (
+	liveBuckets = 60
+	liveSamples = 256
+)
+
+type liveBucket struct {
+	sec     int64
+	count   int64
+	errors  int64
+	samples []float64 // 毫秒
+}
+
+type Live struct {
+	mu      sync.Mutex
+	buckets [liveBuckets]liveBucket
+}
+
+// LiveStats 时间窗口内的汇总，耗时单位毫秒
+type LiveStats struct {
+	Time      int64   `json:"time"`
+	Window    int     `json:"window"` // 秒
+	Requests  int64   `json:"requests"`
+	QPS       float64 `json:"qps"`
+	ErrorRate float64 `json:"error_rate"`
+	P50       float64 `json:"p50"`
+	P90       float64 `json:"p90"`
+	P99       float64 `json:"p99"`
+}
+
+func NewLive() *Live {
+	return &Live{}
+}
+
+func (l *Live) Observe(d time.Duration, failed bool) {
+	sec := time.Now().Unix()
+	ms := float64(d.Microseconds()) / 1000
+	l.mu.Lock()
+	b := &l.buckets[sec%liveBuckets]
+	if b.sec != sec {
+		b.sec, b.count, b.errors, b.samples = sec, 0, 0, b.samples[:0]
+	}
+	b.count++
+	if failed {
+		b.errors++
+	}
+	if len(b.samples) < liveSamples {
+		b.samples = append(b.samples, ms)
+	} else if i := rand.Int63n(b.count); i < liveSamples {
+		b.samples[i] = ms
+	}
+	l.mu.Unlock()
+}
+
+// Snapshot 汇总最近window内已结束的整秒，window最长60秒
+func (l *Live) Snapshot(window time.Duration) *LiveStats {
+	n := int64(window / time.Second)
+	if n < 1 {
+		n = 1
+	} else if n > liveBuckets-1 {
+		n = liveBuckets - 1
+	}
+	now := time.Now().Unix()
+	stats := &LiveStats{Time: now, Window: int(n)}
+	var errors int64
+	var samples []float64
+	l.mu.Lock()
+	for sec := now - n; sec < now; sec++ {
+		b := &l.buckets[sec%liveBuckets]
+		if b.sec != sec {
+			continue
+		}
+		stats.Requests += b.count
+		errors += b.errors
+		samples = append(samples, b.samples...)
+	}
+	l.mu.Unlock()
+	if stats.Requests == 0 {
+		return stats
+	}
+	stats.QPS = float64(stats.Requests) / float64(n)
+	stats.ErrorRate = float64(errors) / float64(stats.Requests)
+	sort.Float64s(samples)
+	stats.P50 = percentile(samples, 0.5)
+	stats.P90 = percentile(samples, 0.9)
+	stats.P99 = percentile(samples, 0.99)
+	return stats
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(p*float64(len(sorted)-1))]
+}
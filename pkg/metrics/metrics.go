@@ -0,0 +1,280 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+进程内指标，输出为Prometheus文本格式，无需引入client_golang
+	var reqTotal = metrics.NewCounter("http_requests_total", "请求总数", "route", "status")
+	reqTotal.Inc("/ping", "200")
+*/
+
+type collector interface {
+	write(buf *bytes.Buffer)
+}
+
+var (
+	mu         sync.RWMutex
+	collectors []collector
+)
+
+func register(c collector) {
+	mu.Lock()
+	collectors = append(collectors, c)
+	mu.Unlock()
+}
+
+type vec struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+	mu     sync.RWMutex
+	series map[string][]string // key => label values
+}
+
+func newVec(name, help, kind string, labels []string) vec {
+	return vec{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		series: make(map[string][]string),
+	}
+}
+
+func (v *vec) key(values []string) string {
+	if len(values) != len(v.labels) {
+		panic("metrics: label count mismatch for " + v.name)
+	}
+	k := strings.Join(values, "\xff")
+	v.mu.RLock()
+	_, ok := v.series[k]
+	v.mu.RUnlock()
+	if !ok {
+		v.mu.Lock()
+		v.series[k] = append([]string(nil), values...)
+		v.mu.Unlock()
+	}
+	return k
+}
+
+func (v *vec) header(buf *bytes.Buffer) {
+	buf.WriteString("# HELP " + v.name + " " + v.help + "\n")
+	buf.WriteString("# TYPE " + v.name + " " + v.kind + "\n")
+}
+
+func (v *vec) sortedKeys() []string {
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (v *vec) labelPairs(values []string, extra ...string) string {
+	pairs := make([]string, 0, len(values)+1)
+	for i, l := range v.labels {
+		pairs = append(pairs, l+"="+strconv.Quote(values[i]))
+	}
+	pairs = append(pairs, extra...)
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Counter 只增计数器
+type Counter struct {
+	vec
+	values sync.Map // key => *float64Value
+}
+
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{vec: newVec(name, help, "counter", labels)}
+	register(c)
+	return c
+}
+
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+func (c *Counter) Add(n float64, values ...string) {
+	k := c.key(values)
+	v, _ := c.values.LoadOrStore(k, &float64Value{})
+	v.(*float64Value).add(n)
+}
+
+// Value 返回当前值，用于进程内统计
+func (c *Counter) Value(values ...string) float64 {
+	v, ok := c.values.Load(c.key(values))
+	if !ok {
+		return 0
+	}
+	return v.(*float64Value).get()
+}
+
+func (c *Counter) write(buf *bytes.Buffer) {
+	c.header(buf)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, k := range c.sortedKeys() {
+		v, _ := c.values.Load(k)
+		if v == nil {
+			continue
+		}
+		buf.WriteString(c.name + c.labelPairs(c.series[k]) + " " + formatFloat(v.(*float64Value).get()) + "\n")
+	}
+}
+
+// Gauge 可增减的瞬时值
+type Gauge struct {
+	Counter
+}
+
+func NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{Counter{vec: newVec(name, help, "gauge", labels)}}
+	register(g)
+	return g
+}
+
+func (g *Gauge) Set(n float64, values ...string) {
+	k := g.key(values)
+	v, _ := g.values.LoadOrStore(k, &float64Value{})
+	v.(*float64Value).set(n)
+}
+
+func (g *Gauge) Dec(values ...string) {
+	g.Add(-1, values...)
+}
+
+// DefaultBuckets 接口耗时分布(秒)
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram 分布统计
+type Histogram struct {
+	vec
+	buckets []float64
+	values  sync.Map // key => *histValue
+}
+
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	h := &Histogram{vec: newVec(name, help, "histogram", labels), buckets: buckets}
+	register(h)
+	return h
+}
+
+func (h *Histogram) Observe(n float64, values ...string) {
+	k := h.key(values)
+	v, ok := h.values.Load(k)
+	if !ok {
+		v, _ = h.values.LoadOrStore(k, &histValue{counts: make([]uint64, len(h.buckets))})
+	}
+	v.(*histValue).observe(h.buckets, n)
+}
+
+func (h *Histogram) write(buf *bytes.Buffer) {
+	h.header(buf)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, k := range h.sortedKeys() {
+		v, _ := h.values.Load(k)
+		if v == nil {
+			continue
+		}
+		counts, sum, total := v.(*histValue).snapshot()
+		labels := h.series[k]
+		var acc uint64
+		for i, b := range h.buckets {
+			acc += counts[i]
+			buf.WriteString(h.name + "_bucket" + h.labelPairs(labels, `le="`+formatFloat(b)+`"`) +
+				" " + strconv.FormatUint(acc, 10) + "\n")
+		}
+		buf.WriteString(h.name + "_bucket" + h.labelPairs(labels, `le="+Inf"`) + " " +
+			strconv.FormatUint(total, 10) + "\n")
+		buf.WriteString(h.name + "_sum" + h.labelPairs(labels) + " " + formatFloat(sum) + "\n")
+		buf.WriteString(h.name + "_count" + h.labelPairs(labels) + " " + strconv.FormatUint(total, 10) + "\n")
+	}
+}
+
+type float64Value struct {
+	mu sync.Mutex
+	v  float64
+}
+
+func (f *float64Value) add(n float64) {
+	f.mu.Lock()
+	f.v += n
+	f.mu.Unlock()
+}
+
+func (f *float64Value) set(n float64) {
+	f.mu.Lock()
+	f.v = n
+	f.mu.Unlock()
+}
+
+func (f *float64Value) get() float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.v
+}
+
+type histValue struct {
+	mu     sync.Mutex
+	counts []uint64
+	sum    float64
+	total  uint64
+}
+
+func (h *histValue) observe(buckets []float64, n float64) {
+	h.mu.Lock()
+	for i, b := range buckets {
+		if n <= b {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += n
+	h.total++
+	h.mu.Unlock()
+}
+
+func (h *histValue) snapshot() ([]uint64, float64, uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]uint64(nil), h.counts...), h.sum, h.total
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Handler 输出全部指标
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		buf := bytes.NewBuffer(nil)
+		mu.RLock()
+		for _, c := range collectors {
+			c.write(buf)
+		}
+		mu.RUnlock()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write(buf.Bytes())
+	})
+}
@@ -0,0 +1,181 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/pkg/db"
+	"project/pkg/logger"
+	"project/pkg/metrics"
+	"time"
+)
+
+// 消费去重：按消息ID(或业务键)记录已处理的消息，重新投递时跳过，避免重复发放积分等副作用
+// 处理成功后才标记，标记前进程退出仍会重复处理；需要严格一次的在业务事务中调用DBDedup.MarkTx
+
+var dedupTotal = metrics.NewCounter("mq_dedup_total", "消费去重结果，new为首次处理，duplicate为已处理跳过，busy为其他消费者处理中",
+	"consumer", "result")
+
+// ErrDedupBusy 同一消息正在被处理，返回给队列稍后重新投递
+var ErrDedupBusy = errors.New("mq: message is being processed")
+
+// ErrDuplicate 业务事务中标记时已存在
+var ErrDuplicate = errors.New("mq: duplicate message")
+
+const (
+	DedupNew  = iota // 首次处理
+	DedupDone        // 已处理
+	DedupBusy        // 处理中
+)
+
+type DedupStore interface {
+	Acquire(ctx context.Context, key string) (int, error)
+	Commit(ctx context.Context, key string) error  // 处理成功
+	Release(ctx context.Context, key string) error // 处理失败，允许重新投递后再处理
+}
+
+// DedupHandler consumer区分不同的消费逻辑，keyFn为空时使用消息ID；
+// 已处理的消息直接确认，处理中的返回ErrDedupBusy重新投递，去重存储异常时仍处理(宁可重复不丢消息)
+func DedupHandler(store DedupStore, consumer string, keyFn func(*Message) string, h Handler) Handler {
+	return func(msg *Message) error {
+		key := msg.ID
+		if keyFn != nil {
+			key = keyFn(msg)
+		}
+		key = consumer + ":" + key
+		ctx := context.Background()
+		status, err := store.Acquire(ctx, key)
+		if err != nil {
+			dedupTotal.Inc(consumer, "error")
+			logger.FromContext(ctx).Error("mq.DedupStore.Acquire error", key, err)
+			return h(msg)
+		}
+		switch status {
+		case DedupDone:
+			dedupTotal.Inc(consumer, "duplicate")
+			return nil
+		case DedupBusy:
+			dedupTotal.Inc(consumer, "busy")
+			return ErrDedupBusy
+		}
+		dedupTotal.Inc(consumer, "new")
+		if err = h(msg); err != nil {
+			if e := store.Release(ctx, key); e != nil {
+				logger.FromContext(ctx).Error("mq.DedupStore.Release error", key, e)
+			}
+			return err
+		}
+		if err = store.Commit(ctx, key); err != nil {
+			logger.FromContext(ctx).Error("mq.DedupStore.Commit error", key, err)
+		}
+		return nil
+	}
+}
+
+// RedisDedup 处理中写入0并设置租约，成功后写入1并按ttl保留
+type RedisDedup struct {
+	redis  *redis.Client
+	prefix string
+	lease  time.Duration
+	ttl    time.Duration
+}
+
+// NewRedisDedup lease为单条消息最长处理时间，超过后视为消费者已退出，ttl应大于队列最长重新投递间隔
+func NewRedisDedup(rdb *redis.Client, prefix string, lease, ttl time.Duration) *RedisDedup {
+	return &RedisDedup{redis: rdb, prefix: prefix, lease: lease, ttl: ttl}
+}
+
+func (d *RedisDedup) Acquire(ctx context.Context, key string) (int, error) {
+	ok, err := d.redis.SetNX(ctx, d.prefix+key, 0, d.lease).Result()
+	if err != nil || ok {
+		return DedupNew, err
+	}
+	v, err := d.redis.Get(ctx, d.prefix+key).Result()
+	if err == redis.Nil { // 租约刚好过期
+		return d.Acquire(ctx, key)
+	}
+	if err != nil {
+		return DedupNew, err
+	}
+	if v == "1" {
+		return DedupDone, nil
+	}
+	return DedupBusy, nil
+}
+
+func (d *RedisDedup) Commit(ctx context.Context, key string) error {
+	return d.redis.Set(ctx, d.prefix+key, 1, d.ttl).Err()
+}
+
+func (d *RedisDedup) Release(ctx context.Context, key string) error {
+	return d.redis.Del(ctx, d.prefix+key).Err()
+}
+
+// Dedup 数据库去重记录，表结构见design/sql
+type Dedup struct {
+	ID         int       `json:"id"`
+	MsgKey     string    `json:"msg_key"`
+	ExpireTime time.Time `json:"expire_time"`
+}
+
+func (*Dedup) TableName() string {
+	return "mq_dedup"
+}
+
+// DBDedup 只记录已处理的消息，不区分处理中；过期记录由Purge分批清理
+type DBDedup struct {
+	mysql *gorm.DB
+	ttl   time.Duration
+}
+
+func NewDBDedup(orm *gorm.DB, ttl time.Duration) *DBDedup {
+	return &DBDedup{mysql: orm, ttl: ttl}
+}
+
+func (d *DBDedup) Acquire(ctx context.Context, key string) (int, error) {
+	var n int64
+	err := d.mysql.WithContext(ctx).Model(&Dedup{}).
+		Where("msg_key = ? AND expire_time > ?", key, time.Now()).Count(&n).Error
+	if n > 0 {
+		return DedupDone, err
+	}
+	return DedupNew, err
+}
+
+func (d *DBDedup) Commit(ctx context.Context, key string) error {
+	return d.mysql.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"expire_time"}),
+	}).Create(&Dedup{MsgKey: key, ExpireTime: time.Now().Add(d.ttl)}).Error
+}
+
+func (d *DBDedup) Release(context.Context, string) error {
+	return nil
+}
+
+// MarkTx 在业务事务中标记，已存在时返回ErrDuplicate由调用方回滚，key需带consumer前缀
+func (d *DBDedup) MarkTx(tx *gorm.DB, key string) error {
+	err := tx.Create(&Dedup{MsgKey: key, ExpireTime: time.Now().Add(d.ttl)}).Error
+	if db.IsDuplicateKey(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// Purge 分批删除过期记录
+func (d *DBDedup) Purge(ctx context.Context, batch int) (int64, error) {
+	var total int64
+	for {
+		opt := d.mysql.WithContext(ctx).
+			Exec("DELETE FROM mq_dedup WHERE expire_time < ? LIMIT ?", time.Now(), batch)
+		if opt.Error != nil {
+			return total, opt.Error
+		}
+		total += opt.RowsAffected
+		if opt.RowsAffected < int64(batch) {
+			return total, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,129 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	uuid "github.com/satori/go.uuid"
+	"math/rand"
+	"project/pkg/logger"
+	"time"
+)
+
+// 延迟投递：nsq且延迟不超过1小时时使用DeferredPublish，其余写入redis有序集合(score为到期毫秒数)，
+// 由Mover到期后投递；取出时先把score改为租约到期时间，投递成功再删除，Mover中断时租约到期后重新投递(至少一次)
+
+var ErrDeferUnsupported = errors.New("mq: deferred publish not supported by driver")
+
+const nsqMaxDefer = time.Hour // nsqd默认--max-req-timeout
+
+type DelayOptions struct {
+	Jitter   int // 到期时间随机后延的最大毫秒数，避免同一时刻大量到期
+	Lease    int // 取出后未投递成功时重新可见的秒数，默认30
+	Interval int // Mover扫描间隔毫秒数，默认500
+	Batch    int // Mover每次取出的最大条数，默认100
+}
+
+type delayed struct {
+	ID    string `json:"id"` // 相同内容的消息也作为不同成员
+	Topic string `json:"topic"`
+	Body  []byte `json:"body"`
+}
+
+type Delayer struct {
+	producer Producer
+	redis    *redis.Client
+	key      string
+	jitter   time.Duration
+	lease    time.Duration
+	interval time.Duration
+	batch    int
+}
+
+// NewDelayer key为存放延迟消息的有序集合
+func NewDelayer(producer Producer, rdb *redis.Client, key string, opt *DelayOptions) *Delayer {
+	d := &Delayer{
+		producer: producer,
+		redis:    rdb,
+		key:      key,
+		jitter:   time.Duration(opt.Jitter) * time.Millisecond,
+		lease:    30 * time.Second,
+		interval: 500 * time.Millisecond,
+		batch:    100,
+	}
+	if opt.Lease > 0 {
+		d.lease = time.Duration(opt.Lease) * time.Second
+	}
+	if opt.Interval > 0 {
+		d.interval = time.Duration(opt.Interval) * time.Millisecond
+	}
+	if opt.Batch > 0 {
+		d.batch = opt.Batch
+	}
+	return d
+}
+
+// Publish 消息在delay(加随机抖动)后可被消费，消费方需按业务状态判断是否仍需处理
+func (d *Delayer) Publish(ctx context.Context, topic string, body []byte, delay time.Duration) error {
+	if d.jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(d.jitter)))
+	}
+	if d.producer.Driver() == "nsq" && delay <= nsqMaxDefer {
+		return d.producer.DeferredPublish(topic, delay, body)
+	}
+	b, _ := json.Marshal(&delayed{ID: uuid.NewV4().String(), Topic: topic, Body: body})
+	at := time.Now().Add(delay).UnixMilli()
+	return d.redis.ZAdd(ctx, d.key, &redis.Z{Score: float64(at), Member: b}).Err()
+}
+
+// claimScript 取出到期的成员并把score改为租约到期时间，多个Mover并行时不会重复取出
+var claimScript = redis.NewScript(`
+local items = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+for _, v in ipairs(items) do
+	redis.call('ZADD', KEYS[1], ARGV[2], v)
+end
+return items`)
+
+// Run 阻塞运行Mover直到ctx取消，可多实例部署
+func (d *Delayer) Run(ctx context.Context) {
+	tick := time.NewTicker(d.interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+		for d.move(ctx) == d.batch { // 积压时连续取出
+		}
+	}
+}
+
+func (d *Delayer) move(ctx context.Context) int {
+	now := time.Now()
+	items, err := claimScript.Run(ctx, d.redis, []string{d.key},
+		now.UnixMilli(), now.Add(d.lease).UnixMilli(), d.batch).StringSlice()
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.FromContext(ctx).Error("mq.Delayer claim error", d.key, err)
+		}
+		return 0
+	}
+	for _, v := range items {
+		var m delayed
+		if err = json.Unmarshal([]byte(v), &m); err != nil {
+			logger.FromContext(ctx).Error("mq.Delayer json.Unmarshal error", v, err)
+			d.redis.ZRem(ctx, d.key, v)
+			continue
+		}
+		if err = d.producer.Publish(m.Topic, m.Body); err != nil {
+			logger.FromContext(ctx).Error("mq.Delayer publish error", m.ID, err)
+			continue
+		}
+		if err = d.redis.ZRem(ctx, d.key, v).Err(); err != nil { // 删除失败时租约到期后重复投递
+			logger.FromContext(ctx).Warn("mq.Delayer ZRem fail", m.ID, err)
+		}
+	}
+	return len(items)
+}
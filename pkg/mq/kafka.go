@@ -0,0 +1,212 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"project/pkg/logger"
+	"sync"
+	"time"
+)
+
+type KafkaConfig struct {
+	Brokers      []string
+	BatchSize    int    `mapstructure:"batchSize"`    // 单分区每批最多条数，默认100
+	BatchTimeout int    `mapstructure:"batchTimeout"` // 未满一批时的发送间隔毫秒数，默认10
+	Acks         int    // 0不等待确认，1等待leader，-1等待全部ISR(默认)
+	Compression  string // gzip|snappy|lz4|zstd，为空不压缩
+	StartOffset  string `mapstructure:"startOffset"`    // 新消费组的起始位置earliest|latest，默认latest
+	Commit       int    `mapstructure:"commitInterval"` // 批量提交offset的间隔毫秒数，0为每条处理完同步提交
+	MaxAttempts  int    `mapstructure:"maxAttempts"`    // 单条消息处理失败的最大次数，超过后记录错误并提交offset，默认5
+}
+
+var compressions = map[string]kafka.Compression{
+	"gzip":   kafka.Gzip,
+	"snappy": kafka.Snappy,
+	"lz4":    kafka.Lz4,
+	"zstd":   kafka.Zstd,
+}
+
+type kafkaProducer struct {
+	brokers []string
+	writer  *kafka.Writer
+	async   *kafka.Writer
+}
+
+// NewKafkaProducer 消息按topic写入，不指定key时在分区间轮询
+func NewKafkaProducer(cfg *KafkaConfig) Producer {
+	acks := kafka.RequireAll
+	if cfg.Acks >= 0 && cfg.Acks <= 1 {
+		acks = kafka.RequiredAcks(cfg.Acks)
+	}
+	timeout := 10 * time.Millisecond // kafka-go默认1秒，同步发送时每次调用都要等满
+	if cfg.BatchTimeout > 0 {
+		timeout = time.Duration(cfg.BatchTimeout) * time.Millisecond
+	}
+	newWriter := func(async bool) *kafka.Writer {
+		return &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			BatchSize:    cfg.BatchSize,
+			BatchTimeout: timeout,
+			RequiredAcks: acks,
+			Compression:  compressions[cfg.Compression],
+			Async:        async,
+		}
+	}
+	p := &kafkaProducer{
+		brokers: cfg.Brokers,
+		writer:  newWriter(false),
+		async:   newWriter(true),
+	}
+	p.async.Completion = func(messages []kafka.Message, err error) {
+		if err != nil {
+			logger.FromContext(context.Background()).Error("kafka.PublishAsync error", len(messages), err)
+		}
+	}
+	return p
+}
+
+func (p *kafkaProducer) Driver() string {
+	return "kafka"
+}
+
+func (p *kafkaProducer) Publish(topic string, body []byte) error {
+	return p.writer.WriteMessages(context.Background(), kafka.Message{Topic: topic, Value: body})
+}
+
+func (p *kafkaProducer) PublishMeta(topic string, meta map[string]string, body []byte) error {
+	headers := make([]kafka.Header, 0, len(meta))
+	for k, v := range meta {
+		headers = append(headers, kafka.Header{Key: k, Value: []byte(v)})
+	}
+	return p.writer.WriteMessages(context.Background(), kafka.Message{Topic: topic, Value: body, Headers: headers})
+}
+
+func (p *kafkaProducer) MultiPublish(topic string, body [][]byte) error {
+	msgs := make([]kafka.Message, len(body))
+	for i, v := range body {
+		msgs[i] = kafka.Message{Topic: topic, Value: v}
+	}
+	return p.writer.WriteMessages(context.Background(), msgs...)
+}
+
+func (p *kafkaProducer) PublishAsync(topic string, body []byte) error {
+	return p.async.WriteMessages(context.Background(), kafka.Message{Topic: topic, Value: body})
+}
+
+func (p *kafkaProducer) DeferredPublish(string, time.Duration, []byte) error {
+	return ErrDeferUnsupported
+}
+
+// Ping 任一broker可连接即可，元数据和分区leader由writer自行发现
+func (p *kafkaProducer) Ping() error {
+	err := errors.New("kafka: no brokers")
+	for _, addr := range p.brokers {
+		var conn *kafka.Conn
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		conn, err = kafka.DialContext(ctx, "tcp", addr)
+		cancel()
+		if err == nil {
+			return conn.Close()
+		}
+	}
+	return err
+}
+
+func (p *kafkaProducer) Stop() {
+	_ = p.async.Close()
+	_ = p.writer.Close()
+}
+
+type kafkaConsumer struct {
+	readers  []*kafka.Reader
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	attempts int
+	handler  Handler
+}
+
+// NewKafkaConsumer 同一消费组启动concurrent个reader，分区在reader间分配，单分区内按顺序处理；
+// 处理成功或超过重试次数后才提交offset，进程退出时未提交的消息会重新投递
+func NewKafkaConsumer(cfg *KafkaConfig, topic, group string, concurrent int, h Handler) (Consumer, error) {
+	if group == "" {
+		return nil, fmt.Errorf("kafka: consumer group required for topic %s", topic)
+	}
+	start := kafka.LastOffset
+	if cfg.StartOffset == "earliest" {
+		start = kafka.FirstOffset
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &kafkaConsumer{cancel: cancel, attempts: 5, handler: h}
+	if cfg.MaxAttempts > 0 {
+		c.attempts = cfg.MaxAttempts
+	}
+	if concurrent < 1 {
+		concurrent = 1
+	}
+	for i := 0; i < concurrent; i++ {
+		r := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:        cfg.Brokers,
+			GroupID:        group,
+			Topic:          topic,
+			StartOffset:    start,
+			CommitInterval: time.Duration(cfg.Commit) * time.Millisecond,
+		})
+		c.readers = append(c.readers, r)
+		c.wg.Add(1)
+		go c.run(ctx, r)
+	}
+	return c, nil
+}
+
+func (c *kafkaConsumer) run(ctx context.Context, r *kafka.Reader) {
+	defer c.wg.Done()
+	for {
+		m, err := r.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.FromContext(ctx).Error("kafka.FetchMessage error", r.Config().Topic, err)
+			}
+			return
+		}
+		msg := &Message{
+			ID:        fmt.Sprintf("%s-%d-%d", m.Topic, m.Partition, m.Offset),
+			Topic:     m.Topic,
+			Body:      m.Value,
+			Timestamp: m.Time.UnixNano(),
+		}
+		if len(m.Headers) > 0 {
+			msg.Meta = make(map[string]string, len(m.Headers))
+			for _, h := range m.Headers {
+				msg.Meta[h.Key] = string(h.Value)
+			}
+		}
+		for msg.Attempts = 1; ; msg.Attempts++ {
+			if err = c.handler(msg); err == nil {
+				break
+			}
+			if msg.Attempts >= c.attempts {
+				logger.FromContext(ctx).Error("kafka.Handle drop", msg.ID, err)
+				break
+			}
+			select {
+			case <-ctx.Done(): // 未提交，重启后重新投递
+				return
+			case <-time.After(time.Duration(msg.Attempts) * time.Second):
+			}
+		}
+		if err = r.CommitMessages(context.Background(), m); err != nil {
+			logger.FromContext(ctx).Error("kafka.CommitMessages error", msg.ID, err)
+		}
+	}
+}
+
+// Stop 等待处理中的消息结束，提交offset后关闭连接
+func (c *kafkaConsumer) Stop() {
+	c.cancel()
+	c.wg.Wait()
+	for _, r := range c.readers {
+		_ = r.Close()
+	}
+}
@@ -0,0 +1,96 @@
+package mq
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"log"
+	"project/pkg/reqctx"
+	"time"
+)
+
+// 消息队列抽象，按配置选择nsq或kafka，业务代码只依赖Producer和Handler
+
+// Message 与驱动无关的消息；ID在nsq为消息ID，在kafka为topic-partition-offset，重新投递时不变
+type Message struct {
+	ID        string
+	Topic     string
+	Body      []byte
+	Timestamp int64             // 纳秒
+	Attempts  int               // 第几次投递，从1开始
+	Meta      map[string]string // 生产方透传的元数据，如reqctx的trace_id和用户身份
+}
+
+// TraceID 生产方透传的trace_id，没有时为消息ID
+func (m *Message) TraceID() string {
+	if v := m.Meta[reqctx.HeaderTraceID]; v != "" {
+		return v
+	}
+	return m.ID
+}
+
+// Handler 返回error时重新投递：nsq为requeue，kafka不提交offset并原地重试
+type Handler func(msg *Message) error
+
+type Producer interface {
+	Driver() string
+	Publish(topic string, body []byte) error
+	PublishMeta(topic string, meta map[string]string, body []byte) error // kafka写入消息头，nsq编码在body前由消费端还原
+	MultiPublish(topic string, body [][]byte) error
+	PublishAsync(topic string, body []byte) error                         // 不等待确认，失败只记录日志
+	DeferredPublish(topic string, delay time.Duration, body []byte) error // 仅nsq支持，其余驱动使用Delayer
+	Ping() error
+	Stop()
+}
+
+type Consumer interface {
+	Stop()
+}
+
+// NewProducer kafka配置了brokers时使用kafka，否则连接nsqd
+func NewProducer(nsqd string, kafka *KafkaConfig) Producer {
+	if kafka != nil && len(kafka.Brokers) > 0 {
+		return NewKafkaProducer(kafka)
+	}
+	return &nsqProducer{NewNsqProducer(nsqd)}
+}
+
+// NewConsumer group在nsq为channel，在kafka为消费组ID
+func NewConsumer(lookupd string, kafka *KafkaConfig, topic, group string, concurrent int, h Handler) Consumer {
+	if kafka != nil && len(kafka.Brokers) > 0 {
+		c, err := NewKafkaConsumer(kafka, topic, group, concurrent, h)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return c
+	}
+	return NewNsqConsumer(lookupd, topic, group, concurrent, nsqHandler(topic, h))
+}
+
+// nsq没有消息头，带元数据的消息以metaMagic开头，后接2字节长度和json元数据，json和文本消息不会以\x00开头
+var metaMagic = []byte{0, 'M'}
+
+func encodeMeta(meta map[string]string, body []byte) []byte {
+	b, _ := json.Marshal(meta)
+	buf := make([]byte, 0, len(metaMagic)+2+len(b)+len(body))
+	buf = append(buf, metaMagic...)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(b)))
+	buf = append(buf, b...)
+	return append(buf, body...)
+}
+
+// decodeMeta 不带元数据或格式不符时原样返回body
+func decodeMeta(data []byte) (map[string]string, []byte) {
+	n := len(metaMagic) + 2
+	if len(data) < n || data[0] != metaMagic[0] || data[1] != metaMagic[1] {
+		return nil, data
+	}
+	size := int(binary.BigEndian.Uint16(data[len(metaMagic):n]))
+	if len(data) < n+size {
+		return nil, data
+	}
+	var meta map[string]string
+	if json.Unmarshal(data[n:n+size], &meta) != nil {
+		return nil, data
+	}
+	return meta, data[n+size:]
+}
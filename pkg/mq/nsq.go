@@ -10,11 +10,7 @@ func NewNsqProducer(addr string) *nsq.Producer {
 	if err != nil {
 		log.Fatal(err)
 	}
-	err = producer.Ping()
-	if err != nil {
-		log.Fatal(err)
-	}
-	return producer
+	return producer // 连通性在启动阶段由boot检查
 }
 
 func NewNsqConsumer(addr, topic, channel string, concurrent int, handler nsq.HandlerFunc) *nsq.Consumer {
@@ -31,3 +27,36 @@ func NewNsqConsumer(addr, topic, channel string, concurrent int, handler nsq.Han
 	}
 	return consumer
 }
+
+type nsqProducer struct {
+	*nsq.Producer
+}
+
+func (p *nsqProducer) Driver() string {
+	return "nsq"
+}
+
+func (p *nsqProducer) PublishAsync(topic string, body []byte) error {
+	return p.Producer.PublishAsync(topic, body, nil)
+}
+
+func (p *nsqProducer) PublishMeta(topic string, meta map[string]string, body []byte) error {
+	if len(meta) == 0 {
+		return p.Producer.Publish(topic, body)
+	}
+	return p.Producer.Publish(topic, encodeMeta(meta, body))
+}
+
+func nsqHandler(topic string, h Handler) nsq.HandlerFunc {
+	return func(m *nsq.Message) error {
+		meta, body := decodeMeta(m.Body)
+		return h(&Message{
+			ID:        string(m.ID[:]),
+			Topic:     topic,
+			Body:      body,
+			Timestamp: m.Timestamp,
+			Attempts:  int(m.Attempts),
+			Meta:      meta,
+		})
+	}
+}
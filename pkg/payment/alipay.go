@@ -0,0 +1,362 @@
+package payment
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+支付宝开放平台(RSA2签名，公钥模式)：
+H5使用手机网站支付，web使用电脑网站支付，均返回跳转URL；APP返回orderStr
+退款为同步结果，fund_change=Y即退款成功
+文档：https://opendocs.alipay.com/open/203/105285
+*/
+
+const (
+	alipayGateway   = "https://openapi.alipay.com/gateway.do"
+	alipayTimestamp = "2006-01-02 15:04:05"
+)
+
+type AlipayConfig struct {
+	AppID      string
+	PrivateKey string // 应用私钥(PEM，PKCS1或PKCS8)
+	PublicKey  string // 支付宝公钥(PEM)
+	NotifyURL  string
+	Gateway    string // 为空使用正式环境，沙箱为https://openapi-sandbox.dl.alipaydev.com/gateway.do
+}
+
+type alipayProvider struct {
+	appid   string
+	key     *rsa.PrivateKey
+	public  *rsa.PublicKey
+	notify  string
+	gateway string
+	client  *http.Client
+}
+
+func NewAlipay(cfg *AlipayConfig, client *http.Client) (Provider, error) {
+	block, _ := pem.Decode([]byte(cfg.PrivateKey))
+	if block == nil {
+		return nil, errors.New("alipay: invalid private key")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		k, err8 := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err8 != nil {
+			return nil, err
+		}
+		var ok bool
+		if key, ok = k.(*rsa.PrivateKey); !ok {
+			return nil, errors.New("alipay: private key is not rsa")
+		}
+	}
+	block, _ = pem.Decode([]byte(cfg.PublicKey))
+	if block == nil {
+		return nil, errors.New("alipay: invalid public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	public, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("alipay: public key is not rsa")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	p := &alipayProvider{
+		appid:   cfg.AppID,
+		key:     key,
+		public:  public,
+		notify:  cfg.NotifyURL,
+		gateway: cfg.Gateway,
+		client:  client,
+	}
+	if p.gateway == "" {
+		p.gateway = alipayGateway
+	}
+	return p, nil
+}
+
+// AlipayError 支付宝业务错误，code不为10000
+type AlipayError struct {
+	Code    string `json:"code"`
+	Msg     string `json:"msg"`
+	SubCode string `json:"sub_code"`
+	SubMsg  string `json:"sub_msg"`
+}
+
+func (e *AlipayError) Error() string {
+	return fmt.Sprintf("alipay: %s %s %s %s", e.Code, e.Msg, e.SubCode, e.SubMsg)
+}
+
+func (p *alipayProvider) Name() string {
+	return ProviderAlipay
+}
+
+// signContent 除sign外的非空参数按key排序拼接
+func signContent(v url.Values, exclude ...string) string {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		if v.Get(k) == "" || k == "sign" {
+			continue
+		}
+		skip := false
+		for _, e := range exclude {
+			skip = skip || k == e
+		}
+		if !skip {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	b := &strings.Builder{}
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(v.Get(k))
+	}
+	return b.String()
+}
+
+// params 公共参数和业务参数签名，extra为notify_url、return_url等可选公共参数
+func (p *alipayProvider) params(method string, biz any, extra map[string]string) (url.Values, error) {
+	b, _ := json.Marshal(biz)
+	v := url.Values{
+		"app_id":      {p.appid},
+		"method":      {method},
+		"format":      {"JSON"},
+		"charset":     {"utf-8"},
+		"sign_type":   {"RSA2"},
+		"timestamp":   {time.Now().Format(alipayTimestamp)},
+		"version":     {"1.0"},
+		"biz_content": {string(b)},
+	}
+	for k, val := range extra {
+		v.Set(k, val)
+	}
+	h := sha256.Sum256([]byte(signContent(v)))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, h[:])
+	if err != nil {
+		return nil, err
+	}
+	v.Set("sign", base64.StdEncoding.EncodeToString(sig))
+	return v, nil
+}
+
+func (p *alipayProvider) verify(content, sign string) error {
+	sig, err := base64.StdEncoding.DecodeString(sign)
+	if err != nil {
+		return ErrSignature
+	}
+	h := sha256.Sum256([]byte(content))
+	if rsa.VerifyPKCS1v15(p.public, crypto.SHA256, h[:], sig) != nil {
+		return ErrSignature
+	}
+	return nil
+}
+
+// call 调用接口并校验响应签名，响应节点名为method的.替换为_加上_response
+func (p *alipayProvider) call(ctx context.Context, method string, biz, result any) error {
+	v, err := p.params(method, biz, nil)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.gateway, strings.NewReader(v.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err = json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	node := raw[strings.ReplaceAll(method, ".", "_")+"_response"]
+	var sign string
+	_ = json.Unmarshal(raw["sign"], &sign)
+	var e AlipayError
+	if err = json.Unmarshal(node, &e); err != nil {
+		return err
+	}
+	if e.Code != "10000" {
+		return &e
+	}
+	if err = p.verify(string(node), sign); err != nil {
+		return err
+	}
+	return json.Unmarshal(node, result)
+}
+
+func (p *alipayProvider) CreateOrder(_ context.Context, args *OrderArgs) (*OrderResp, error) {
+	biz := map[string]string{
+		"out_trade_no": args.OrderNo,
+		"total_amount": fenToYuan(args.Amount),
+		"subject":      args.Subject,
+	}
+	var method string
+	switch args.Scene {
+	case SceneH5:
+		method = "alipay.trade.wap.pay"
+		biz["product_code"] = "QUICK_WAP_WAY"
+	case SceneWeb:
+		method = "alipay.trade.page.pay"
+		biz["product_code"] = "FAST_INSTANT_TRADE_PAY"
+	case SceneApp:
+		method = "alipay.trade.app.pay"
+		biz["product_code"] = "QUICK_MSECURITY_PAY"
+	default:
+		return nil, ErrScene
+	}
+	extra := map[string]string{"notify_url": p.notify}
+	if args.ReturnURL != "" && args.Scene != SceneApp {
+		extra["return_url"] = args.ReturnURL
+	}
+	v, err := p.params(method, biz, extra)
+	if err != nil {
+		return nil, err
+	}
+	if args.Scene == SceneApp {
+		return &OrderResp{OrderStr: v.Encode()}, nil
+	}
+	return &OrderResp{URL: p.gateway + "?" + v.Encode()}, nil
+}
+
+func (p *alipayProvider) Query(ctx context.Context, orderNo string) (*QueryResp, error) {
+	var resp struct {
+		TradeNo     string `json:"trade_no"`
+		OutTradeNo  string `json:"out_trade_no"`
+		TradeStatus string `json:"trade_status"`
+		TotalAmount string `json:"total_amount"`
+		SendPayDate string `json:"send_pay_date"`
+	}
+	err := p.call(ctx, "alipay.trade.query", map[string]string{"out_trade_no": orderNo}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	res := &QueryResp{
+		OrderNo: resp.OutTradeNo,
+		TradeNo: resp.TradeNo,
+		Amount:  yuanToFen(resp.TotalAmount),
+		State:   StatePending,
+	}
+	switch resp.TradeStatus {
+	case "TRADE_SUCCESS", "TRADE_FINISHED":
+		res.State = StateSuccess
+		res.PayTime = alipayTime(resp.SendPayDate)
+	case "TRADE_CLOSED":
+		res.State = StateClosed
+	}
+	return res, nil
+}
+
+func (p *alipayProvider) Refund(ctx context.Context, args *RefundArgs) (*RefundResp, error) {
+	var resp struct {
+		TradeNo    string `json:"trade_no"`
+		FundChange string `json:"fund_change"`
+	}
+	err := p.call(ctx, "alipay.trade.refund", map[string]string{
+		"out_trade_no":   args.OrderNo,
+		"refund_amount":  fenToYuan(args.Amount),
+		"out_request_no": args.RefundNo,
+		"refund_reason":  args.Reason,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	res := &RefundResp{RefundNo: args.RefundNo, RefundID: resp.TradeNo, State: StatePending}
+	if resp.FundChange == "Y" {
+		res.State = StateSuccess
+	}
+	return res, nil
+}
+
+// VerifyCallback 异步通知为form表单，sign和sign_type不参与验签；带out_biz_no的为退款通知
+func (p *alipayProvider) VerifyCallback(req *http.Request) (*Callback, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+	v := req.PostForm
+	if v.Get("app_id") != p.appid {
+		return nil, ErrSignature
+	}
+	if err := p.verify(signContent(v, "sign_type"), v.Get("sign")); err != nil {
+		return nil, err
+	}
+	cb := &Callback{
+		OrderNo: v.Get("out_trade_no"),
+		TradeNo: v.Get("trade_no"),
+	}
+	if no := v.Get("out_biz_no"); no != "" {
+		cb.Type = CallbackRefunded
+		cb.RefundNo = no
+		cb.RefundID = v.Get("trade_no")
+		cb.Amount = yuanToFen(v.Get("refund_fee"))
+		cb.Time = alipayTime(v.Get("gmt_refund"))
+		return cb, nil
+	}
+	switch v.Get("trade_status") {
+	case "TRADE_SUCCESS", "TRADE_FINISHED":
+		cb.Type = CallbackPaid
+		cb.Amount = yuanToFen(v.Get("total_amount"))
+		cb.Time = alipayTime(v.Get("gmt_payment"))
+		return cb, nil
+	}
+	return nil, nil
+}
+
+// Reply 支付宝要求返回纯文本success，否则按策略重试
+func (p *alipayProvider) Reply(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "text/plain")
+	if err != nil {
+		_, _ = w.Write([]byte("fail"))
+		return
+	}
+	_, _ = w.Write([]byte("success"))
+}
+
+func fenToYuan(fen int64) string {
+	return fmt.Sprintf("%d.%02d", fen/100, fen%100)
+}
+
+func yuanToFen(s string) int64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return int64(math.Round(f * 100))
+}
+
+func alipayTime(s string) int64 {
+	t, err := time.ParseInLocation(alipayTimestamp, strings.SplitN(s, ".", 2)[0], time.Local)
+	if err != nil {
+		return time.Now().Unix()
+	}
+	return t.Unix()
+}
@@ -0,0 +1,140 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+/*
+统一支付接口：
+业务只依赖Provider，按订单记录的渠道名获取实现，回调统一转换为Callback
+金额单位均为分
+*/
+
+const (
+	ProviderWxpay  = "wxpay"
+	ProviderAlipay = "alipay"
+)
+
+// 支付场景，不同渠道支持的场景不同
+const (
+	SceneMini = "mini" // 小程序(微信JSAPI)
+	SceneH5   = "h5"   // 手机浏览器
+	SceneApp  = "app"
+	SceneWeb  = "web" // 电脑网站
+)
+
+const (
+	CallbackPaid     = "paid"
+	CallbackRefunded = "refunded"
+	CallbackRefundNG = "refund_failed"
+)
+
+const (
+	StatePending = "pending" // 未支付或退款处理中
+	StateSuccess = "success"
+	StateClosed  = "closed"
+)
+
+var (
+	ErrScene     = errors.New("payment: unsupported scene")
+	ErrProvider  = errors.New("payment: unknown provider")
+	ErrSignature = errors.New("payment: invalid callback signature")
+)
+
+type OrderArgs struct {
+	OrderNo   string
+	Subject   string
+	Amount    int64
+	Scene     string
+	Openid    string // 微信小程序必填
+	ClientIP  string
+	ReturnURL string // H5/电脑网站支付完成后跳转地址
+}
+
+// OrderResp 客户端调起支付所需数据，小程序为Params，H5/电脑网站为URL，APP为Params或OrderStr
+type OrderResp struct {
+	Params   map[string]string `json:"params,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	OrderStr string            `json:"order_str,omitempty"`
+}
+
+type QueryResp struct {
+	OrderNo string
+	TradeNo string // 渠道交易号
+	State   string
+	Amount  int64
+	PayTime int64
+}
+
+type RefundArgs struct {
+	OrderNo  string
+	TradeNo  string
+	RefundNo string
+	Amount   int64
+	Total    int64
+	Reason   string
+}
+
+type RefundResp struct {
+	RefundNo string
+	RefundID string // 渠道退款单号，同步退款的渠道可能为空
+	State    string
+}
+
+type Callback struct {
+	Type     string
+	OrderNo  string
+	TradeNo  string
+	Amount   int64
+	RefundNo string
+	RefundID string
+	Time     int64
+}
+
+type Provider interface {
+	Name() string
+	CreateOrder(ctx context.Context, args *OrderArgs) (*OrderResp, error)
+	Query(ctx context.Context, orderNo string) (*QueryResp, error)
+	Refund(ctx context.Context, args *RefundArgs) (*RefundResp, error)
+	// VerifyCallback 验签并解析回调，返回nil Callback表示无需处理的通知
+	VerifyCallback(req *http.Request) (*Callback, error)
+	// Reply 按渠道要求响应回调，err不为nil时渠道会重试
+	Reply(w http.ResponseWriter, err error)
+}
+
+type Config struct {
+	Wxpay  WxpayConfig
+	Alipay AlipayConfig
+}
+
+// Registry 已启用的渠道
+type Registry map[string]Provider
+
+func (r Registry) Get(name string) (Provider, error) {
+	if p, ok := r[name]; ok {
+		return p, nil
+	}
+	return nil, ErrProvider
+}
+
+// New 初始化已配置的渠道，未配置商户号/应用ID的渠道不启用
+func New(cfg *Config, client *http.Client) (Registry, error) {
+	r := make(Registry)
+	if cfg.Wxpay.MchID != "" {
+		p, err := NewWxpay(&cfg.Wxpay, client)
+		if err != nil {
+			return nil, err
+		}
+		r[p.Name()] = p
+	}
+	if cfg.Alipay.AppID != "" {
+		p, err := NewAlipay(&cfg.Alipay, client)
+		if err != nil {
+			return nil, err
+		}
+		r[p.Name()] = p
+	}
+	return r, nil
+}
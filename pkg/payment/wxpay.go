@@ -0,0 +1,177 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"project/pkg/wxpay"
+	"time"
+)
+
+type WxpayConfig struct {
+	wxpay.Config `mapstructure:",squash"`
+	NotifyURL    string // 支付和退款回调地址
+}
+
+type wxpayProvider struct {
+	api    wxpay.API
+	notify string
+}
+
+func NewWxpay(cfg *WxpayConfig, client *http.Client) (Provider, error) {
+	api, err := wxpay.New(&cfg.Config, client)
+	if err != nil {
+		return nil, err
+	}
+	return &wxpayProvider{api: api, notify: cfg.NotifyURL}, nil
+}
+
+func (p *wxpayProvider) Name() string {
+	return ProviderWxpay
+}
+
+func (p *wxpayProvider) CreateOrder(ctx context.Context, args *OrderArgs) (*OrderResp, error) {
+	req := &wxpay.TransactionArgs{
+		Description: args.Subject,
+		OutTradeNo:  args.OrderNo,
+		NotifyURL:   p.notify,
+		Amount:      &wxpay.TransactionAmount{Total: args.Amount},
+	}
+	var trade string
+	switch args.Scene {
+	case SceneMini:
+		trade = wxpay.TradeJSAPI
+		req.Payer = &wxpay.TransactionPayer{Openid: args.Openid}
+	case SceneH5:
+		trade = wxpay.TradeH5
+		req.SceneInfo = &wxpay.TransactionScene{PayerClientIP: args.ClientIP}
+	case SceneApp:
+		trade = wxpay.TradeApp
+	default:
+		return nil, ErrScene
+	}
+	resp, err := p.api.CreateTransaction(ctx, trade, req)
+	if err != nil {
+		return nil, err
+	}
+	switch trade {
+	case wxpay.TradeJSAPI:
+		params, err := p.api.JSAPIParams(resp.PrepayID)
+		return &OrderResp{Params: params}, err
+	case wxpay.TradeH5:
+		return &OrderResp{URL: resp.H5URL}, nil
+	}
+	return &OrderResp{Params: map[string]string{"prepayid": resp.PrepayID}}, nil
+}
+
+func (p *wxpayProvider) Query(ctx context.Context, orderNo string) (*QueryResp, error) {
+	resp, err := p.api.QueryTransaction(ctx, orderNo)
+	if err != nil {
+		return nil, err
+	}
+	res := &QueryResp{
+		OrderNo: resp.OutTradeNo,
+		TradeNo: resp.TransactionID,
+		Amount:  resp.Amount.Total,
+		State:   StatePending,
+	}
+	switch resp.TradeState {
+	case "SUCCESS", "REFUND":
+		res.State = StateSuccess
+		res.PayTime = parseTime(resp.SuccessTime)
+	case "CLOSED", "REVOKED", "PAYERROR":
+		res.State = StateClosed
+	}
+	return res, nil
+}
+
+func (p *wxpayProvider) Refund(ctx context.Context, args *RefundArgs) (*RefundResp, error) {
+	resp, err := p.api.CreateRefund(ctx, &wxpay.RefundArgs{
+		OutTradeNo:  args.OrderNo,
+		OutRefundNo: args.RefundNo,
+		Reason:      args.Reason,
+		NotifyURL:   p.notify,
+		Amount: &wxpay.RefundAmount{
+			Refund: args.Amount,
+			Total:  args.Total,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &RefundResp{RefundNo: resp.OutRefundNo, RefundID: resp.RefundID, State: StatePending}
+	switch resp.Status {
+	case wxpay.RefundSuccess:
+		res.State = StateSuccess
+	case wxpay.RefundClosed, wxpay.RefundAbnormal:
+		res.State = StateClosed
+	}
+	return res, nil
+}
+
+func (p *wxpayProvider) VerifyCallback(req *http.Request) (*Callback, error) {
+	var raw json.RawMessage
+	n, err := p.api.ParseNotify(req, &raw)
+	if err != nil {
+		if err == wxpay.ErrSignature {
+			return nil, ErrSignature
+		}
+		return nil, err
+	}
+	switch n.EventType {
+	case wxpay.EventTransactionSuccess:
+		var r wxpay.TransactionNotify
+		if err = json.Unmarshal(raw, &r); err != nil || r.TradeState != "SUCCESS" {
+			return nil, err
+		}
+		return &Callback{
+			Type:    CallbackPaid,
+			OrderNo: r.OutTradeNo,
+			TradeNo: r.TransactionID,
+			Amount:  r.Amount.Total,
+			Time:    parseTime(r.SuccessTime),
+		}, nil
+	case wxpay.EventRefundSuccess, wxpay.EventRefundAbnormal, wxpay.EventRefundClosed:
+		var r wxpay.RefundNotify
+		if err = json.Unmarshal(raw, &r); err != nil {
+			return nil, err
+		}
+		cb := &Callback{
+			Type:     CallbackRefundNG,
+			OrderNo:  r.OutTradeNo,
+			TradeNo:  r.TransactionID,
+			Amount:   r.Amount.Refund,
+			RefundNo: r.OutRefundNo,
+			RefundID: r.RefundID,
+			Time:     parseTime(r.SuccessTime),
+		}
+		if r.RefundStatus == wxpay.RefundSuccess {
+			cb.Type = CallbackRefunded
+		}
+		return cb, nil
+	}
+	return nil, nil
+}
+
+// Reply 成功返回200空响应，失败返回4xx/5xx和{"code":"FAIL"}
+func (p *wxpayProvider) Reply(w http.ResponseWriter, err error) {
+	if err == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	status := http.StatusInternalServerError
+	if err == ErrSignature {
+		status = http.StatusUnauthorized
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"code": "FAIL", "message": err.Error()})
+}
+
+func parseTime(s string) int64 {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Now().Unix()
+	}
+	return t.Unix()
+}
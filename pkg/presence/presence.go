@@ -0,0 +1,145 @@
+package presence
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"strconv"
+	"time"
+)
+
+/*
+在线状态，WebSocket网关按连接上报心跳，保存在redis多实例共享；同一用户可以有多个连接(多端登录)，全部断开或超时后离线
+	p := presence.New(rdb, model.KeyPresence, &opt)
+	p.Heartbeat(ctx, uid, connID)             // 建立连接和之后的每次心跳
+	offline, err := p.Leave(ctx, uid, connID) // 连接断开，最后一个连接断开时offline为true
+	online, err := p.Online(ctx, uids)        // 批量查询，返回在线用户的最后心跳时间
+	list, err := p.Sweep(ctx, limit)          // 取出心跳超时的用户，由调用方持久化最后在线时间
+redis结构：prefix+"online" zset member=uid score=最后心跳毫秒数；prefix+"c:"+uid zset member=连接ID score=最后心跳毫秒数
+*/
+
+type Options struct {
+	TTL int // 心跳超时秒数，默认60，网关的心跳间隔应小于TTL/2
+}
+
+// Seen 离线用户的最后在线时间
+type Seen struct {
+	UserID   int
+	LastSeen time.Time
+}
+
+type Presence struct {
+	redis  *redis.Client
+	online string
+	conn   string
+	ttl    time.Duration
+}
+
+func New(rdb *redis.Client, prefix string, opt *Options) *Presence {
+	p := &Presence{
+		redis:  rdb,
+		online: prefix + "online",
+		conn:   prefix + "c:",
+		ttl:    time.Duration(opt.TTL) * time.Second,
+	}
+	if p.ttl <= 0 {
+		p.ttl = time.Minute
+	}
+	return p
+}
+
+// TTL 心跳超时时间，超过未心跳视为离线
+func (p *Presence) TTL() time.Duration {
+	return p.ttl
+}
+
+// KEYS[1]=连接 KEYS[2]=online ARGV[1]=连接ID ARGV[2]=uid ARGV[3]=当前毫秒 ARGV[4]=ttl毫秒；返回1表示由离线变为在线
+var scriptHeartbeat = redis.NewScript(`
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+local last = redis.call('ZSCORE', KEYS[2], ARGV[2])
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[2])
+if last and tonumber(last) > tonumber(ARGV[3]) - tonumber(ARGV[4]) then
+	return 0
+end
+return 1
+`)
+
+// Heartbeat 记录连接的心跳，返回用户是否刚上线
+func (p *Presence) Heartbeat(ctx context.Context, uid int, connID string) (bool, error) {
+	id := strconv.Itoa(uid)
+	n, err := scriptHeartbeat.Run(ctx, p.redis, []string{p.conn + id, p.online}, connID, id,
+		time.Now().UnixMilli(), p.ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// KEYS[1]=连接 KEYS[2]=online ARGV[1]=连接ID ARGV[2]=uid ARGV[3]=当前毫秒 ARGV[4]=ttl毫秒
+// 移除连接和已超时的连接，没有剩余连接时离线返回1，否则用剩余连接的最后心跳更新用户
+var scriptLeave = redis.NewScript(`
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. (tonumber(ARGV[3]) - tonumber(ARGV[4])))
+local last = redis.call('ZREVRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if #last == 0 then
+	redis.call('DEL', KEYS[1])
+	redis.call('ZREM', KEYS[2], ARGV[2])
+	return 1
+end
+redis.call('ZADD', KEYS[2], last[2], ARGV[2])
+return 0
+`)
+
+// Leave 连接断开，用户的最后一个连接断开时返回true，调用方记录最后在线时间
+func (p *Presence) Leave(ctx context.Context, uid int, connID string) (bool, error) {
+	id := strconv.Itoa(uid)
+	n, err := scriptLeave.Run(ctx, p.redis, []string{p.conn + id, p.online}, connID, id,
+		time.Now().UnixMilli(), p.ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// Online 批量查询，返回在线用户的最后心跳时间，不在结果中的为离线
+func (p *Presence) Online(ctx context.Context, uids []int) (map[int]time.Time, error) {
+	if len(uids) == 0 {
+		return map[int]time.Time{}, nil
+	}
+	pipe := p.redis.Pipeline()
+	cmds := make([]*redis.FloatCmd, len(uids))
+	for i, uid := range uids {
+		cmds[i] = pipe.ZScore(ctx, p.online, strconv.Itoa(uid))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	after := time.Now().Add(-p.ttl).UnixMilli()
+	res := make(map[int]time.Time, len(uids))
+	for i, cmd := range cmds {
+		ms, err := cmd.Result()
+		if err == nil && int64(ms) > after {
+			res[uids[i]] = time.UnixMilli(int64(ms))
+		}
+	}
+	return res, nil
+}
+
+// KEYS[1]=online ARGV[1]=超时的毫秒数 ARGV[2]=limit；连接的key按ttl自动过期
+var scriptSweep = redis.NewScript(`
+local list = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'WITHSCORES', 'LIMIT', 0, ARGV[2])
+for i = 1, #list, 2 do
+	redis.call('ZREM', KEYS[1], list[i])
+end
+return list
+`)
+
+// Sweep 取出并移除最多limit个心跳超时的用户，LastSeen为最后心跳时间；多实例运行时每个用户只会被取出一次
+func (p *Presence) Sweep(ctx context.Context, limit int) ([]*Seen, error) {
+	before := time.Now().Add(-p.ttl).UnixMilli()
+	list, err := scriptSweep.Run(ctx, p.redis, []string{p.online}, before, limit).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*Seen, 0, len(list)/2)
+	for i := 0; i+1 < len(list); i += 2 {
+		uid, _ := strconv.Atoi(list[i])
+		ms, _ := strconv.ParseFloat(list[i+1], 64)
+		res = append(res, &Seen{UserID: uid, LastSeen: time.UnixMilli(int64(ms))})
+	}
+	return res, nil
+}
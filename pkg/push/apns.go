@@ -0,0 +1,123 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+APNs(HTTP/2，token认证)：
+p8密钥签发ES256的JWT，有效期1小时，提前10分钟换发；410或BadDeviceToken、Unregistered视为token失效
+文档：https://developer.apple.com/documentation/usernotifications/sending_notification_requests_to_apns
+*/
+
+const (
+	apnsHost        = "https://api.push.apple.com"
+	apnsSandboxHost = "https://api.sandbox.push.apple.com"
+)
+
+type APNsConfig struct {
+	KeyID   string
+	TeamID  string
+	Key     string // p8私钥(PEM)
+	Topic   string // bundle id
+	Sandbox bool   // 开发环境证书安装的app
+}
+
+type apns struct {
+	cfg    *APNsConfig
+	key    crypto.Signer
+	host   string
+	client *http.Client
+
+	mu     sync.Mutex
+	token  string
+	issued time.Time
+}
+
+func NewAPNs(cfg *APNsConfig, client *http.Client) (Sender, error) {
+	key, err := parseKey([]byte(cfg.Key))
+	if err != nil {
+		return nil, err
+	}
+	s := &apns{cfg: cfg, key: key, host: apnsHost, client: client}
+	if cfg.Sandbox {
+		s.host = apnsSandboxHost
+	}
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+	return s, nil
+}
+
+func (s *apns) Name() string {
+	return ProviderAPNs
+}
+
+func (s *apns) bearer() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Since(s.issued) < 50*time.Minute {
+		return s.token, nil
+	}
+	now := time.Now()
+	tk, err := signJWT(s.key, s.cfg.KeyID, map[string]any{"iss": s.cfg.TeamID, "iat": now.Unix()})
+	if err != nil {
+		return "", err
+	}
+	s.token, s.issued = tk, now
+	return tk, nil
+}
+
+func (s *apns) Send(ctx context.Context, token string, n *Notification) (string, error) {
+	aps := map[string]any{
+		"alert": map[string]string{"title": n.Title, "body": n.Body},
+		"sound": "default",
+	}
+	if n.Badge != nil {
+		aps["badge"] = *n.Badge
+	}
+	payload := map[string]any{"aps": aps}
+	for k, v := range n.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+	b, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.host+"/3/device/"+token, bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	bearer, err := s.bearer()
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("authorization", "bearer "+bearer)
+	req.Header.Set("apns-topic", s.cfg.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	if n.TTL > 0 {
+		req.Header.Set("apns-expiration", strconv.FormatInt(time.Now().Unix()+int64(n.TTL), 10))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return resp.Header.Get("apns-id"), nil
+	}
+	var result struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result) // nolint
+	if resp.StatusCode == http.StatusGone || result.Reason == "BadDeviceToken" || result.Reason == "Unregistered" {
+		return "", ErrInvalidToken
+	}
+	return "", &Error{Provider: ProviderAPNs, Status: resp.StatusCode, Reason: result.Reason}
+}
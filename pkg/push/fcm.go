@@ -0,0 +1,164 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+FCM HTTP v1：
+服务账号私钥签发JWT换取OAuth2 access_token(有效期1小时，提前5分钟换发)；UNREGISTERED或token格式错误视为失效
+文档：https://firebase.google.com/docs/cloud-messaging/send-message
+*/
+
+const (
+	fcmHost  = "https://fcm.googleapis.com"
+	fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+)
+
+type FCMConfig struct {
+	ProjectID   string
+	ClientEmail string // 服务账号
+	PrivateKey  string // 服务账号私钥(PEM)
+	TokenURI    string // 为空使用https://oauth2.googleapis.com/token
+}
+
+type fcm struct {
+	cfg    *FCMConfig
+	key    crypto.Signer
+	client *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expire time.Time
+}
+
+func NewFCM(cfg *FCMConfig, client *http.Client) (Sender, error) {
+	key, err := parseKey([]byte(cfg.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TokenURI == "" {
+		cfg.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	s := &fcm{cfg: cfg, key: key, client: client}
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+	return s, nil
+}
+
+func (s *fcm) Name() string {
+	return ProviderFCM
+}
+
+func (s *fcm) accessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Before(s.expire) {
+		return s.token, nil
+	}
+	now := time.Now()
+	assertion, err := signJWT(s.key, "", map[string]any{
+		"iss":   s.cfg.ClientEmail,
+		"scope": fcmScope,
+		"aud":   s.cfg.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Error       string `json:"error"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.AccessToken == "" {
+		return "", errors.New("push: fcm oauth " + result.Error)
+	}
+	s.token = result.AccessToken
+	s.expire = now.Add(time.Duration(result.ExpiresIn)*time.Second - 5*time.Minute)
+	return s.token, nil
+}
+
+func (s *fcm) Send(ctx context.Context, token string, n *Notification) (string, error) {
+	msg := map[string]any{
+		"token":        token,
+		"notification": map[string]string{"title": n.Title, "body": n.Body},
+	}
+	if len(n.Data) > 0 {
+		msg["data"] = n.Data
+	}
+	if n.TTL > 0 {
+		msg["android"] = map[string]string{"ttl": strconv.Itoa(n.TTL) + "s"}
+	}
+	b, _ := json.Marshal(map[string]any{"message": msg})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fcmHost+"/v1/projects/"+s.cfg.ProjectID+"/messages:send", bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	tk, err := s.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+tk)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Name  string `json:"name"`
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result) // nolint
+	if resp.StatusCode == http.StatusOK {
+		return result.Name, nil
+	}
+	reason := result.Error.Status
+	for _, d := range result.Error.Details {
+		if d.ErrorCode != "" {
+			reason = d.ErrorCode
+		}
+	}
+	if reason == "UNREGISTERED" || resp.StatusCode == http.StatusNotFound ||
+		reason == "INVALID_ARGUMENT" && strings.Contains(result.Error.Message, "registration token") {
+		return "", ErrInvalidToken
+	}
+	return "", &Error{Provider: ProviderFCM, Status: resp.StatusCode, Reason: reason}
+}
@@ -0,0 +1,67 @@
+package push
+
+import (
+	"context"
+	"errors"
+)
+
+/*
+App推送统一接口：
+设备注册时记录渠道名和token，发送时按渠道名获取Sender；厂商通道(华为、小米等)实现Sender后同样按名称注册
+token失效(卸载、重装、过期)时返回ErrInvalidToken，调用方据此删除设备
+*/
+
+const (
+	ProviderAPNs = "apns"
+	ProviderFCM  = "fcm"
+)
+
+var (
+	ErrProvider     = errors.New("push: unknown provider")
+	ErrInvalidToken = errors.New("push: invalid or unregistered token")
+)
+
+type Notification struct {
+	Title string
+	Body  string
+	Data  map[string]string // 自定义字段，客户端用于跳转
+	Badge *int              // 仅apns，nil不修改角标
+	TTL   int               // 离线保留秒数，0使用渠道默认值
+}
+
+type Sender interface {
+	Name() string
+	// Send 返回渠道的消息ID
+	Send(ctx context.Context, token string, n *Notification) (string, error)
+}
+
+// Error 渠道返回的非token失效错误
+type Error struct {
+	Provider string
+	Status   int
+	Reason   string
+}
+
+func (e *Error) Error() string {
+	return "push: " + e.Provider + " " + e.Reason
+}
+
+type Gateway struct {
+	senders map[string]Sender
+}
+
+func NewGateway(senders ...Sender) *Gateway {
+	g := &Gateway{senders: make(map[string]Sender, len(senders))}
+	for _, s := range senders {
+		g.senders[s.Name()] = s
+	}
+	return g
+}
+
+func (g *Gateway) Send(ctx context.Context, provider, token string, n *Notification) (string, error) {
+	s, ok := g.senders[provider]
+	if !ok {
+		return "", ErrProvider
+	}
+	return s.Send(ctx, token, n)
+}
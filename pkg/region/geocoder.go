@@ -0,0 +1,94 @@
+package region
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+var ErrNoLocation = errors.New("region: address not located")
+
+// Geocoder 地址解析为gcj02坐标，与小程序wx.getLocation、门店坐标一致
+type Geocoder interface {
+	Geocode(ctx context.Context, address, city string) (lng, lat float64, err error)
+}
+
+type GeocoderConfig struct {
+	Key string // 腾讯位置服务key，为空不启用
+	SK  string `mapstructure:"sk"` // 开启签名校验时的secret key
+}
+
+// NewGeocoder 未配置key时返回nil
+func NewGeocoder(cfg *GeocoderConfig, client *http.Client) Geocoder {
+	if cfg == nil || cfg.Key == "" {
+		return nil
+	}
+	return &tencent{cfg: cfg, client: client}
+}
+
+const geocoderPath = "/ws/geocoder/v1/"
+
+// tencent 腾讯位置服务地址解析，可靠度低于7的结果(只精确到区县以上)视为未找到
+type tencent struct {
+	cfg    *GeocoderConfig
+	client *http.Client
+}
+
+func (t *tencent) Geocode(ctx context.Context, address, city string) (float64, float64, error) {
+	params := map[string]string{"address": address, "key": t.cfg.Key}
+	if city != "" {
+		params["region"] = city
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	raw := make([]string, len(keys))
+	query := url.Values{}
+	for i, k := range keys {
+		raw[i] = k + "=" + params[k]
+		query.Set(k, params[k])
+	}
+	if t.cfg.SK != "" { // 签名使用未编码的参数
+		sum := md5.Sum([]byte(geocoderPath + "?" + strings.Join(raw, "&") + t.cfg.SK))
+		query.Set("sig", hex.EncodeToString(sum[:]))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://apis.map.qq.com"+geocoderPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	var res struct {
+		Status  int
+		Message string
+		Result  struct {
+			Location struct {
+				Lng float64
+				Lat float64
+			}
+			Reliability int
+		}
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return 0, 0, fmt.Errorf("region: geocoder http %d: %w", resp.StatusCode, err)
+	}
+	if res.Status == 347 || res.Status == 0 && res.Result.Reliability < 7 {
+		return 0, 0, ErrNoLocation
+	}
+	if res.Status != 0 {
+		return 0, 0, fmt.Errorf("region: geocoder %d: %s", res.Status, res.Message)
+	}
+	return res.Result.Location.Lng, res.Result.Location.Lat, nil
+}
@@ -0,0 +1,153 @@
+package region
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+/*
+行政区划：省/市/区县三级，code为民政部6位区划代码；数据集按版本整体替换，创建后只读，可以并发使用
+	list, err := region.Parse(data)   // 导入的嵌套json [{"code":"440000","name":"广东省","children":[...]}]
+	ds, err := region.New("2024", list) // 校验层级和上级后建立索引
+	ds.Children("")                     // 省份，按code升序
+	chain, err := ds.Resolve("440000", "440100", "440106") // 校验省市区是上下级，城市没有下级(如东莞)时区县为空
+*/
+
+const (
+	LevelProvince int8 = 1
+	LevelCity     int8 = 2
+	LevelDistrict int8 = 3
+)
+
+var ErrCode = errors.New("region: invalid code")
+
+type Region struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Parent string `json:"parent,omitempty"` // 省份为空
+	Level  int8   `json:"level"`
+}
+
+// Node 嵌套结构，导入的文件和下发给客户端的完整数据
+type Node struct {
+	Code     string  `json:"code"`
+	Name     string  `json:"name"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+type Dataset struct {
+	Version  string
+	byCode   map[string]*Region
+	children map[string][]*Region
+	tree     []*Node
+}
+
+// Parse 解析嵌套json为扁平列表，最多三级
+func Parse(data []byte) ([]*Region, error) {
+	var nodes []*Node
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return nil, err
+	}
+	var list []*Region
+	var walk func(nodes []*Node, parent string, level int8) error
+	walk = func(nodes []*Node, parent string, level int8) error {
+		for _, v := range nodes {
+			if level > LevelDistrict {
+				return fmt.Errorf("region: %s %s deeper than district", v.Code, v.Name)
+			}
+			list = append(list, &Region{Code: v.Code, Name: v.Name, Parent: parent, Level: level})
+			if err := walk(v.Children, v.Code, level+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(nodes, "", LevelProvince); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// New 建立索引，code重复、上级不存在或层级不连续时返回错误
+func New(version string, list []*Region) (*Dataset, error) {
+	ds := &Dataset{
+		Version:  version,
+		byCode:   make(map[string]*Region, len(list)),
+		children: make(map[string][]*Region),
+	}
+	for _, v := range list {
+		if len(v.Code) != 6 || v.Name == "" {
+			return nil, fmt.Errorf("region: invalid %q %q", v.Code, v.Name)
+		}
+		if _, ok := ds.byCode[v.Code]; ok {
+			return nil, fmt.Errorf("region: duplicate code %s", v.Code)
+		}
+		ds.byCode[v.Code] = v
+	}
+	for _, v := range list {
+		if v.Parent == "" {
+			if v.Level != LevelProvince {
+				return nil, fmt.Errorf("region: %s level %d without parent", v.Code, v.Level)
+			}
+		} else if p, ok := ds.byCode[v.Parent]; !ok || p.Level != v.Level-1 {
+			return nil, fmt.Errorf("region: %s invalid parent %s", v.Code, v.Parent)
+		}
+		ds.children[v.Parent] = append(ds.children[v.Parent], v)
+	}
+	for _, v := range ds.children {
+		sort.Slice(v, func(i, j int) bool { return v[i].Code < v[j].Code })
+	}
+	ds.tree = ds.build("")
+	return ds, nil
+}
+
+func (ds *Dataset) build(parent string) []*Node {
+	list := ds.children[parent]
+	nodes := make([]*Node, len(list))
+	for i, v := range list {
+		nodes[i] = &Node{Code: v.Code, Name: v.Name, Children: ds.build(v.Code)}
+	}
+	return nodes
+}
+
+func (ds *Dataset) Len() int {
+	return len(ds.byCode)
+}
+
+func (ds *Dataset) Get(code string) *Region {
+	return ds.byCode[code]
+}
+
+// Children 下级区划，parent为空时返回省份；返回的切片不可修改
+func (ds *Dataset) Children(parent string) []*Region {
+	if list, ok := ds.children[parent]; ok {
+		return list
+	}
+	return []*Region{}
+}
+
+// Tree 完整的嵌套数据，返回的结构不可修改
+func (ds *Dataset) Tree() []*Node {
+	return ds.tree
+}
+
+// Resolve 校验省市区的上下级关系，返回[省,市,区县]，城市没有下级时区县必须为空且返回两级
+func (ds *Dataset) Resolve(province, city, district string) ([]*Region, error) {
+	p, c := ds.byCode[province], ds.byCode[city]
+	if p == nil || p.Level != LevelProvince || c == nil || c.Parent != province {
+		return nil, ErrCode
+	}
+	if _, ok := ds.children[city]; !ok {
+		if district != "" {
+			return nil, ErrCode
+		}
+		return []*Region{p, c}, nil
+	}
+	d := ds.byCode[district]
+	if d == nil || d.Parent != city {
+		return nil, ErrCode
+	}
+	return []*Region{p, c, d}, nil
+}
@@ -0,0 +1,153 @@
+package reqctx
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+请求上下文透传：鉴权后的用户身份和trace_id写入内部http调用的请求头和消息元数据，下游无需再查询token即可鉴权和串联日志
+	c.Set(reqctx.Key, &reqctx.Info{TraceID: tid, UserID: uid, Openid: openid})
+	client := &http.Client{Transport: reqctx.Transport(http.DefaultTransport)} // 仅用于内部服务，不能发往第三方
+	producer.PublishMeta(topic, reqctx.Meta(ctx), body)
+下游应只在内网或mTLS通道上信任这些请求头
+上下文带截止时间时，SetHeader同时写入剩余预算X-Request-Timeout(毫秒)，下游据此设置自己的截止时间
+*/
+
+const (
+	HeaderTraceID = "X-Trace-Id"
+	HeaderUserID  = "X-User-Id"
+	HeaderOpenid  = "X-User-Openid"
+	HeaderUnionid = "X-User-Unionid"
+	HeaderTimeout = "X-Request-Timeout" // 调用方剩余的等待时间，毫秒
+	HeaderGrpc    = "Grpc-Timeout"      // grpc格式，如100m、3S
+)
+
+// Key 上下文中Info的key，gin.Context通过c.Set写入，标准context通过NewContext写入
+const Key = "reqctx"
+
+var headers = []string{HeaderTraceID, HeaderUserID, HeaderOpenid, HeaderUnionid}
+
+type Info struct {
+	TraceID string
+	UserID  int
+	Openid  string
+	Unionid string
+}
+
+func NewContext(ctx context.Context, info *Info) context.Context {
+	return context.WithValue(ctx, Key, info) //nolint:staticcheck
+}
+
+// From 未登录时只有trace_id，取自上下文的trace_id
+func From(ctx context.Context) *Info {
+	if v, ok := ctx.Value(Key).(*Info); ok {
+		return v
+	}
+	tid, _ := ctx.Value("trace_id").(string)
+	return &Info{TraceID: tid}
+}
+
+// Meta 非空字段按请求头名称输出，用作消息元数据
+func Meta(ctx context.Context) map[string]string {
+	info := From(ctx)
+	meta := make(map[string]string, len(headers))
+	if info.TraceID != "" {
+		meta[HeaderTraceID] = info.TraceID
+	}
+	if info.UserID > 0 {
+		meta[HeaderUserID] = strconv.Itoa(info.UserID)
+	}
+	if info.Openid != "" {
+		meta[HeaderOpenid] = info.Openid
+	}
+	if info.Unionid != "" {
+		meta[HeaderUnionid] = info.Unionid
+	}
+	return meta
+}
+
+// SetHeader 覆盖请求中的同名头，未登录时删除用户相关的头，防止客户端伪造身份经转发传到下游
+func SetHeader(ctx context.Context, h http.Header) {
+	for _, k := range headers {
+		h.Del(k)
+	}
+	for k, v := range Meta(ctx) {
+		h.Set(k, v)
+	}
+	h.Del(HeaderTimeout)
+	h.Del(HeaderGrpc)
+	if d, ok := ctx.Deadline(); ok {
+		ms := time.Until(d).Milliseconds()
+		if ms < 1 {
+			ms = 1
+		}
+		h.Set(HeaderTimeout, strconv.FormatInt(ms, 10))
+	}
+}
+
+var grpcUnits = map[byte]time.Duration{
+	'H': time.Hour, 'M': time.Minute, 'S': time.Second, 'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond,
+}
+
+// ParseTimeout 读取调用方的等待时间，X-Request-Timeout为毫秒数，Grpc-Timeout为最多8位数字加单位；未传或格式错误返回false
+func ParseTimeout(get func(key string) string) (time.Duration, bool) {
+	if v := strings.TrimSpace(get(HeaderTimeout)); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms <= 0 {
+			return 0, false
+		}
+		return capTimeout(ms, time.Millisecond), true
+	}
+	v := strings.TrimSpace(get(HeaderGrpc))
+	if len(v) < 2 || len(v) > 9 {
+		return 0, false
+	}
+	unit, ok := grpcUnits[v[len(v)-1]]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return capTimeout(n, unit), true
+}
+
+// capTimeout 超过1天按1天计，避免乘法溢出，实际预算由服务端上限再截断
+func capTimeout(n int64, unit time.Duration) time.Duration {
+	const max = 24 * time.Hour
+	if n > int64(max/unit) {
+		return max
+	}
+	return time.Duration(n) * unit
+}
+
+// Parse 下游服务从请求头或消息元数据还原Info
+func Parse(get func(key string) string) *Info {
+	uid, _ := strconv.Atoi(get(HeaderUserID))
+	return &Info{
+		TraceID: get(HeaderTraceID),
+		UserID:  uid,
+		Openid:  get(HeaderOpenid),
+		Unionid: get(HeaderUnionid),
+	}
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+// Transport 按请求的context写入身份和trace_id请求头，请求需使用http.NewRequestWithContext创建
+func Transport(next http.RoundTripper) http.RoundTripper {
+	return &transport{next: next}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	SetHeader(req.Context(), req.Header)
+	return t.next.RoundTrip(req)
+}
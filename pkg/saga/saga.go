@@ -0,0 +1,214 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"project/pkg/db"
+	"project/pkg/logger"
+	"time"
+)
+
+/*
+多步骤业务流程的编排：按顺序执行步骤，某一步失败时倒序执行已完成步骤的补偿，每步结束后持久化进度
+	checkout := saga.New[Checkout](store, "checkout",
+		&saga.Step[Checkout]{Name: "stock", Do: reserve, Compensate: restock},
+		&saga.Step[Checkout]{Name: "charge", Do: debit, Compensate: credit},
+		&saga.Step[Checkout]{Name: "order", Do: createOrder},
+	)
+	err := checkout.Start(ctx, orderNo, &Checkout{...})
+
+进程在步骤中途退出时由Resume从记录的步骤重新执行，因此Do和Compensate都必须幂等(如以saga ID作为业务单号)
+*/
+
+const (
+	StatusRunning      int8 = 1  // 执行中
+	StatusCompensating int8 = 2  // 补偿中，补偿失败时停留在此状态等待重试
+	StatusDone         int8 = 3  // 全部步骤成功
+	StatusAborted      int8 = -1 // 已补偿完成
+)
+
+var ErrExists = errors.New("saga: id already exists")
+
+// Record saga实例的持久化记录，表结构见design/sql
+type Record struct {
+	ID         int       `json:"id"`
+	SagaID     string    `json:"saga_id"` // 业务唯一标识，如订单号
+	Name       string    `json:"name"`
+	Step       int       `json:"step"` // 执行中为下一个要执行的步骤，补偿中为下一个要补偿的步骤+1
+	Status     int8      `json:"status"`
+	Data       string    `json:"data"`  // json
+	Error      string    `json:"error"` // 触发补偿的错误或最近一次补偿错误
+	Retries    int       `json:"retries"`
+	CreateTime time.Time `json:"create_time" gorm:"->"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+func (*Record) TableName() string {
+	return "saga"
+}
+
+type Step[T any] struct {
+	Name       string
+	Do         func(ctx context.Context, id string, data *T) error
+	Compensate func(ctx context.Context, id string, data *T) error // 可选，无副作用或最后一步可不设置
+}
+
+type Saga[T any] struct {
+	store *Store
+	name  string
+	steps []*Step[T]
+}
+
+func New[T any](store *Store, name string, steps ...*Step[T]) *Saga[T] {
+	return &Saga[T]{store: store, name: name, steps: steps}
+}
+
+// Start 创建实例并同步执行，返回触发补偿的步骤错误；补偿失败时同样返回该错误，实例由Resume继续补偿
+func (s *Saga[T]) Start(ctx context.Context, id string, data *T) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	r := &Record{SagaID: id, Name: s.name, Status: StatusRunning, Data: string(b), UpdateTime: time.Now()}
+	if err = s.store.create(ctx, r); err != nil {
+		return err
+	}
+	return s.run(ctx, r, data)
+}
+
+// Resume 继续执行超过idle未更新的执行中、补偿中实例(进程退出或补偿失败)，多实例并行时按更新时间抢占
+func (s *Saga[T]) Resume(ctx context.Context, idle time.Duration, limit int) (int, error) {
+	list, err := s.store.FindStuck(ctx, s.name, time.Now().Add(-idle), limit)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, r := range list {
+		ok, err := s.store.claim(ctx, r)
+		if err != nil {
+			return n, err
+		}
+		if !ok {
+			continue
+		}
+		var data T
+		if err = json.Unmarshal([]byte(r.Data), &data); err != nil {
+			logger.FromContext(ctx).Error("saga.Resume json.Unmarshal error", r.SagaID, err)
+			continue
+		}
+		if r.Status == StatusRunning {
+			r.Retries++
+		}
+		if err = s.run(ctx, r, &data); err != nil {
+			logger.FromContext(ctx).Warn("saga.Resume aborted", r.SagaID, err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (s *Saga[T]) run(ctx context.Context, r *Record, data *T) error {
+	var cause error
+	if r.Status == StatusRunning {
+		for r.Step < len(s.steps) {
+			step := s.steps[r.Step]
+			if cause = step.Do(ctx, r.SagaID, data); cause != nil {
+				cause = fmt.Errorf("saga %s step %s: %w", s.name, step.Name, cause)
+				r.Status = StatusCompensating
+				r.Error = cause.Error()
+				break
+			}
+			r.Step++
+			if r.Step == len(s.steps) {
+				r.Status = StatusDone
+			}
+			if err := s.store.save(ctx, r, data); err != nil {
+				return err
+			}
+		}
+		if r.Status == StatusDone {
+			return nil
+		}
+	} else {
+		cause = errors.New(r.Error)
+	}
+	// 失败的步骤未完成，从上一步开始补偿；失败步骤本身若有部分副作用需由其Do自行回滚
+	for r.Step > 0 {
+		step := s.steps[r.Step-1]
+		if step.Compensate != nil {
+			if err := step.Compensate(ctx, r.SagaID, data); err != nil {
+				r.Retries++
+				r.Error = fmt.Sprintf("compensate %s: %v", step.Name, err)
+				if e := s.store.save(ctx, r, data); e != nil {
+					logger.FromContext(ctx).Error("saga.save error", r.SagaID, e)
+				}
+				return cause
+			}
+		}
+		r.Step--
+		if err := s.store.save(ctx, r, data); err != nil {
+			return cause
+		}
+	}
+	r.Status = StatusAborted
+	if err := s.store.save(ctx, r, data); err != nil {
+		logger.FromContext(ctx).Error("saga.save error", r.SagaID, err)
+	}
+	return cause
+}
+
+type Store struct {
+	mysql *gorm.DB
+}
+
+func NewStore(orm *gorm.DB) *Store {
+	return &Store{mysql: orm}
+}
+
+func (s *Store) create(ctx context.Context, r *Record) error {
+	err := s.mysql.WithContext(ctx).Create(r).Error
+	if db.IsDuplicateKey(err) {
+		return ErrExists
+	}
+	return err
+}
+
+func (s *Store) save(ctx context.Context, r *Record, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	r.Data = string(b)
+	r.UpdateTime = time.Now()
+	return s.mysql.WithContext(ctx).Model(&Record{}).Where("id = ?", r.ID).Updates(map[string]any{
+		"step":        r.Step,
+		"status":      r.Status,
+		"data":        r.Data,
+		"error":       r.Error,
+		"retries":     r.Retries,
+		"update_time": r.UpdateTime,
+	}).Error
+}
+
+// claim 以读取时的更新时间为条件刷新，未命中说明已被其他实例继续执行
+func (s *Store) claim(ctx context.Context, r *Record) (bool, error) {
+	now := time.Now()
+	opt := s.mysql.WithContext(ctx).Model(&Record{}).
+		Where("id = ? AND update_time = ?", r.ID, r.UpdateTime).Update("update_time", now)
+	r.UpdateTime = now
+	return opt.RowsAffected > 0, opt.Error
+}
+
+// FindStuck 查询before之前最后更新的执行中、补偿中实例，name为空时查询全部
+func (s *Store) FindStuck(ctx context.Context, name string, before time.Time, limit int) (list []*Record, err error) {
+	query := s.mysql.WithContext(ctx).
+		Where("status IN ? AND update_time < ?", []int8{StatusRunning, StatusCompensating}, before)
+	if name != "" {
+		query = query.Where("name = ?", name)
+	}
+	err = query.Order("update_time").Limit(limit).Find(&list).Error
+	return
+}
@@ -0,0 +1,170 @@
+package segment
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+/*
+人群规则：属性条件和行为条件组合成树，编译为用户表上的SQL条件
+	{"all": [
+		{"attr": "phone", "op": "ne", "value": ""},
+		{"event": "order_paid", "days": 30, "op": "gte", "value": 2},
+		{"not": {"event": "login", "days": 7}}
+	]}
+属性和行为由调用方注册为SQL片段，规则只能引用已注册的名称，值均以参数绑定，不拼接到SQL
+行为条件统计最近days天的次数，op和value为空时表示至少1次
+*/
+
+var ErrRule = errors.New("segment: invalid rule")
+
+const maxDepth = 5
+
+type Rule struct {
+	All   []*Rule `json:"all,omitempty"`
+	Any   []*Rule `json:"any,omitempty"`
+	Not   *Rule   `json:"not,omitempty"`
+	Attr  string  `json:"attr,omitempty"`
+	Event string  `json:"event,omitempty"`
+	Days  int     `json:"days,omitempty"` // 行为统计的天数，0为全部
+	Op    string  `json:"op,omitempty"`   // eq|ne|gt|gte|lt|lte|in
+	Value any     `json:"value,omitempty"`
+}
+
+// 行为的时间字段类型
+const (
+	TimeDatetime  = iota // datetime
+	TimeUnix             // 秒
+	TimeUnixMilli        // 毫秒
+)
+
+// Event 行为来源表，Where为该行为的固定条件(不含用户和时间)
+type Event struct {
+	Table      string
+	UserColumn string
+	TimeColumn string
+	TimeType   int
+	Where      string
+	Args       []any
+}
+
+type Engine struct {
+	table  string
+	attrs  map[string]string
+	events map[string]*Event
+}
+
+// New table为用户表名，主键为id；attrs为属性名到SQL表达式
+func New(table string, attrs map[string]string, events map[string]*Event) *Engine {
+	return &Engine{table: table, attrs: attrs, events: events}
+}
+
+// Compile 返回用户表上的条件和参数
+func (e *Engine) Compile(r *Rule) (string, []any, error) {
+	var args []any
+	sql, err := e.compile(r, &args, 0)
+	return sql, args, err
+}
+
+func (e *Engine) compile(r *Rule, args *[]any, depth int) (string, error) {
+	if r == nil || depth > maxDepth {
+		return "", fmt.Errorf("%w: empty or too deep", ErrRule)
+	}
+	switch {
+	case len(r.All) > 0:
+		return e.group(r.All, " AND ", args, depth)
+	case len(r.Any) > 0:
+		return e.group(r.Any, " OR ", args, depth)
+	case r.Not != nil:
+		sql, err := e.compile(r.Not, args, depth+1)
+		return "NOT (" + sql + ")", err
+	case r.Attr != "":
+		expr, ok := e.attrs[r.Attr]
+		if !ok {
+			return "", fmt.Errorf("%w: unknown attr %s", ErrRule, r.Attr)
+		}
+		return compare(expr, r.Op, r.Value, args)
+	case r.Event != "":
+		ev, ok := e.events[r.Event]
+		if !ok {
+			return "", fmt.Errorf("%w: unknown event %s", ErrRule, r.Event)
+		}
+		if r.Days < 0 {
+			return "", fmt.Errorf("%w: days must be positive", ErrRule)
+		}
+		where := fmt.Sprintf("%s.%s = %s.id", ev.Table, ev.UserColumn, e.table)
+		if ev.Where != "" {
+			where += " AND (" + ev.Where + ")"
+			*args = append(*args, ev.Args...)
+		}
+		if r.Days > 0 {
+			since := time.Now().AddDate(0, 0, -r.Days)
+			where += fmt.Sprintf(" AND %s.%s >= ?", ev.Table, ev.TimeColumn)
+			switch ev.TimeType {
+			case TimeUnix:
+				*args = append(*args, since.Unix())
+			case TimeUnixMilli:
+				*args = append(*args, since.UnixMilli())
+			default:
+				*args = append(*args, since)
+			}
+		}
+		count := fmt.Sprintf("(SELECT COUNT(*) FROM %s WHERE %s)", ev.Table, where)
+		if r.Op == "" && r.Value == nil {
+			return count + " > 0", nil
+		}
+		return compare(count, r.Op, r.Value, args)
+	}
+	return "", fmt.Errorf("%w: empty rule", ErrRule)
+}
+
+func (e *Engine) group(list []*Rule, sep string, args *[]any, depth int) (string, error) {
+	parts := make([]string, 0, len(list))
+	for _, v := range list {
+		sql, err := e.compile(v, args, depth+1)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, sql)
+	}
+	return "(" + strings.Join(parts, sep) + ")", nil
+}
+
+var ops = map[string]string{"eq": "=", "ne": "!=", "gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+
+func compare(expr, op string, value any, args *[]any) (string, error) {
+	if op == "in" {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice || rv.Len() == 0 || rv.Len() > 1000 {
+			return "", fmt.Errorf("%w: in requires 1~1000 values", ErrRule)
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if !scalar(rv.Index(i).Interface()) {
+				return "", fmt.Errorf("%w: invalid value", ErrRule)
+			}
+		}
+		*args = append(*args, value)
+		return expr + " IN ?", nil
+	}
+	sym, ok := ops[op]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown op %s", ErrRule, op)
+	}
+	if !scalar(value) {
+		return "", fmt.Errorf("%w: invalid value", ErrRule)
+	}
+	*args = append(*args, value)
+	return expr + " " + sym + " ?", nil
+}
+
+// scalar json解码后的值只允许字符串、数字和布尔
+func scalar(v any) bool {
+	switch v.(type) {
+	case string, float64, bool, int, int64:
+		return true
+	}
+	return false
+}
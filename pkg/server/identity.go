@@ -0,0 +1,60 @@
+package server
+
+import (
+	"crypto/tls"
+	"strings"
+)
+
+// Identity 按客户端证书的SAN识别调用方服务，Routes为允许访问的路由前缀，为空不限制
+type Identity struct {
+	Name   string
+	SANs   []string // DNS名(支持*.前缀通配)、URI(如spiffe://cluster/ns/default/sa/script)、邮箱或IP
+	Routes []string
+}
+
+// Identify 返回客户端证书匹配的第一个身份，未使用mTLS或不匹配时返回nil
+func Identify(state *tls.ConnectionState, ids []*Identity) *Identity {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	leaf := state.VerifiedChains[0][0]
+	sans := make([]string, 0, len(leaf.DNSNames)+len(leaf.URIs)+len(leaf.EmailAddresses)+len(leaf.IPAddresses))
+	sans = append(sans, leaf.DNSNames...)
+	for _, u := range leaf.URIs {
+		sans = append(sans, u.String())
+	}
+	sans = append(sans, leaf.EmailAddresses...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, id := range ids {
+		for _, want := range id.SANs {
+			for _, san := range sans {
+				if matchSAN(want, san) {
+					return id
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func matchSAN(want, san string) bool {
+	if strings.HasPrefix(want, "*.") {
+		return strings.HasSuffix(san, want[1:]) && !strings.Contains(strings.TrimSuffix(san, want[1:]), ".")
+	}
+	return strings.EqualFold(want, san)
+}
+
+// Allow 是否允许访问该路径
+func (id *Identity) Allow(path string) bool {
+	if len(id.Routes) == 0 {
+		return true
+	}
+	for _, v := range id.Routes {
+		if strings.HasPrefix(path, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+)
+
+type Config struct {
+	Addr string // 监听地址，为空时使用各服务的默认端口；unix:开头时监听unix socket
+	TLS  *TLS   // 未配置证书或域名时为http
+
+	// 超时秒数，0使用默认值，-1不限制
+	ReadHeaderTimeout int `mapstructure:"readHeaderTimeout"` // 读取请求头，默认5
+	ReadTimeout       int `mapstructure:"readTimeout"`       // 读取整个请求(含body)，默认30
+	WriteTimeout      int `mapstructure:"writeTimeout"`      // 读完请求头到写完响应，默认60
+	IdleTimeout       int `mapstructure:"idleTimeout"`       // keep-alive空闲连接，默认120
+
+	MaxHeaderBytes     int   `mapstructure:"maxHeaderBytes"`     // 请求头最大字节数，默认64KB
+	MaxMultipartMemory int64 `mapstructure:"maxMultipartMemory"` // 解析multipart表单时内存中保留的MB数，超出部分写临时文件，默认8
+}
+
+func seconds(v, def int) time.Duration {
+	switch {
+	case v < 0:
+		return 0
+	case v == 0:
+		return time.Duration(def) * time.Second
+	}
+	return time.Duration(v) * time.Second
+}
+
+// MultipartMemory gin.Engine.MaxMultipartMemory的字节数
+func (c *Config) MultipartMemory() int64 {
+	if c.MaxMultipartMemory > 0 {
+		return c.MaxMultipartMemory << 20
+	}
+	return 8 << 20
+}
+
+// Server 按配置以http或https运行的服务
+type Server struct {
+	*http.Server
+	tls       bool
+	challenge *http.Server
+}
+
+func New(cfg *Config, addr string, h http.Handler) (*Server, error) {
+	if cfg.Addr != "" {
+		addr = cfg.Addr
+	}
+	maxHeader := cfg.MaxHeaderBytes
+	if maxHeader <= 0 {
+		maxHeader = 64 << 10
+	}
+	s := &Server{Server: &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadHeaderTimeout: seconds(cfg.ReadHeaderTimeout, 5),
+		ReadTimeout:       seconds(cfg.ReadTimeout, 30),
+		WriteTimeout:      seconds(cfg.WriteTimeout, 60),
+		IdleTimeout:       seconds(cfg.IdleTimeout, 120),
+		MaxHeaderBytes:    maxHeader,
+	}}
+	if cfg.TLS.Enabled() {
+		challenge, err := Configure(s.Server, cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		s.tls = true
+		s.challenge = challenge
+	}
+	return s, nil
+}
+
+// Run 非阻塞启动，监听失败时退出进程
+func (s *Server) Run() {
+	ln, err := Listen(s.Addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		if s.tls {
+			err = s.ServeTLS(ln, "", "")
+		} else {
+			err = s.Serve(ln)
+		}
+		if err != nil && err != http.ErrServerClosed && !upgrading.Load() {
+			log.Fatal(err)
+		}
+	}()
+	if s.challenge != nil {
+		ln, err := Listen(s.challenge.Addr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go func() {
+			if err := s.challenge.Serve(ln); err != nil && err != http.ErrServerClosed && !upgrading.Load() {
+				log.Fatal(err)
+			}
+		}()
+	}
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.challenge != nil {
+		if err := s.challenge.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	return s.Server.Shutdown(ctx)
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"log"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// 服务端TLS：证书文件热加载或Let's Encrypt自动申请，启用HTTP/2，仅TLS1.2+和AEAD加密套件
+
+type TLS struct {
+	Cert     string   // 证书文件路径(含中间证书)，文件替换后1分钟内自动重新加载
+	Key      string   // 私钥文件路径
+	Domains  []string // 配置后通过Let's Encrypt自动申请和续期证书，cert、key不生效
+	Email    string   // Let's Encrypt账号邮箱，证书异常时接收通知
+	CacheDir string   `mapstructure:"cacheDir"` // 自动申请的证书缓存目录，默认certs
+	HTTPAddr string   `mapstructure:"httpAddr"` // 自动申请时HTTP-01验证和跳转https的监听地址，默认:80
+	ClientCA string   `mapstructure:"clientCa"` // 配置后校验客户端证书(mTLS)，文件为签发客户端证书的CA
+}
+
+var (
+	ErrCert     = errors.New("tls: cert and key are both required")
+	ErrClientCA = errors.New("tls: failed to parse client ca")
+)
+
+func (t *TLS) Enabled() bool {
+	return t != nil && (len(t.Domains) > 0 || t.Cert != "" || t.Key != "")
+}
+
+// Configure 为srv设置TLS和HTTP/2，自动申请证书时返回需另外启动的http服务
+func Configure(srv *http.Server, t *TLS) (*http.Server, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{ // TLS1.3的套件不可配置，均为AEAD
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+	}
+	var challenge *http.Server
+	if len(t.Domains) > 0 {
+		dir := t.CacheDir
+		if dir == "" {
+			dir = "certs"
+		}
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(t.Domains...),
+			Cache:      autocert.DirCache(dir),
+			Email:      t.Email,
+		}
+		cfg.GetCertificate = m.GetCertificate
+		cfg.NextProtos = []string{"acme-tls/1"} // TLS-ALPN-01验证
+		addr := t.HTTPAddr
+		if addr == "" {
+			addr = ":80"
+		}
+		challenge = &http.Server{
+			Addr:              addr,
+			Handler:           m.HTTPHandler(nil),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+	} else {
+		if t.Cert == "" || t.Key == "" {
+			return nil, ErrCert
+		}
+		r := &certReloader{cert: t.Cert, key: t.Key}
+		if _, err := r.reload(); err != nil {
+			return nil, err
+		}
+		go r.watch(time.Minute)
+		cfg.GetCertificate = r.GetCertificate
+	}
+	if t.ClientCA != "" {
+		b, err := os.ReadFile(t.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, ErrClientCA
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven // 未带证书的请求由上层按路由拒绝，便于健康检查
+	}
+	srv.TLSConfig = cfg
+	if err := http2.ConfigureServer(srv, nil); err != nil { // 追加h2到NextProtos
+		return nil, err
+	}
+	return challenge, nil
+}
+
+type certReloader struct {
+	cert, key string
+	mod       time.Time
+	current   atomic.Pointer[tls.Certificate]
+}
+
+func (r *certReloader) reload() (bool, error) {
+	c, err := os.Stat(r.cert)
+	if err != nil {
+		return false, err
+	}
+	k, err := os.Stat(r.key)
+	if err != nil {
+		return false, err
+	}
+	mod := c.ModTime()
+	if k.ModTime().After(mod) {
+		mod = k.ModTime()
+	}
+	if mod.Equal(r.mod) {
+		return false, nil
+	}
+	pair, err := tls.LoadX509KeyPair(r.cert, r.key)
+	if err != nil {
+		return false, err
+	}
+	r.current.Store(&pair)
+	r.mod = mod
+	return true, nil
+}
+
+// watch 证书和私钥任一修改后重新加载，加载失败(如只替换了其中一个)继续使用旧证书
+func (r *certReloader) watch(interval time.Duration) {
+	for range time.Tick(interval) {
+		ok, err := r.reload()
+		if err != nil {
+			log.Println("tls: reload cert error: ", err)
+		} else if ok {
+			log.Println("tls: cert reloaded")
+		}
+	}
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current.Load(), nil
+}
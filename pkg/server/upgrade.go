@@ -0,0 +1,206 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 平滑升级：替换二进制后向进程发送SIGHUP，旧进程通过fd继承把监听的socket交给新启动的进程，
+// 新进程就绪后通知旧进程，旧进程停止accept、处理完已有请求后退出；端口始终有进程在accept，微信回调不会连接失败
+// 由systemd管理时应配置PIDFile指向UpgradeOptions.PidFile，否则旧进程退出后systemd认为服务已停止并杀掉新进程
+
+type UpgradeOptions struct {
+	PidFile string `mapstructure:"pidFile"` // 就绪后写入当前pid，为空不写
+	Timeout int    // 等待新进程就绪的秒数，需大于依赖检查和预热的耗时，默认60
+}
+
+const (
+	envListeners = "SERVER_LISTENERS" // 继承的监听地址，逗号分隔，依次对应fd 3、4...
+	envReady     = "SERVER_READY_FD"  // 就绪后写入的管道fd
+)
+
+var (
+	upgradeOpt = UpgradeOptions{Timeout: 60}
+	upgrading  atomic.Bool
+
+	lnMu        sync.Mutex
+	listeners   = make(map[string]net.Listener) // 本进程监听的地址，升级时传给新进程
+	inherited   map[string]net.Listener
+	inheritOnce sync.Once
+)
+
+// SetUpgrade 设置平滑升级的参数，需在Ready之前调用
+func SetUpgrade(opt *UpgradeOptions) {
+	if opt.Timeout <= 0 {
+		opt.Timeout = 60
+	}
+	upgradeOpt = *opt
+}
+
+// Listen 优先使用从旧进程继承的socket；地址以unix:开头时监听unix socket
+func Listen(addr string) (net.Listener, error) {
+	lnMu.Lock()
+	defer lnMu.Unlock()
+	inheritOnce.Do(inherit)
+	ln, ok := inherited[addr]
+	if ok {
+		delete(inherited, addr)
+	} else {
+		var err error
+		if ln, err = listen(addr); err != nil {
+			return nil, err
+		}
+	}
+	listeners[addr] = ln
+	return ln, nil
+}
+
+func listen(addr string) (net.Listener, error) {
+	if path := strings.TrimPrefix(addr, "unix:"); path != addr {
+		_ = os.Remove(path) // 清理上次未正常退出残留的socket文件
+		return net.Listen("unix", path)
+	}
+	return net.Listen("tcp", addr)
+}
+
+func inherit() {
+	inherited = make(map[string]net.Listener)
+	v := os.Getenv(envListeners)
+	if v == "" {
+		return
+	}
+	_ = os.Unsetenv(envListeners)
+	for i, addr := range strings.Split(v, ",") {
+		f := os.NewFile(uintptr(3+i), addr)
+		ln, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			log.Printf("server: inherit %s error: %v", addr, err)
+			continue
+		}
+		inherited[addr] = ln
+	}
+}
+
+// Ready 开始接收流量后调用：写入pidFile，由平滑升级启动时通知旧进程退出，并关闭未使用的继承socket
+func Ready() error {
+	lnMu.Lock()
+	for addr, ln := range inherited {
+		_ = ln.Close()
+		delete(inherited, addr)
+	}
+	lnMu.Unlock()
+	if upgradeOpt.PidFile != "" {
+		tmp := upgradeOpt.PidFile + ".tmp"
+		if err := os.WriteFile(tmp, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, upgradeOpt.PidFile); err != nil {
+			return err
+		}
+	}
+	fd, _ := strconv.Atoi(os.Getenv(envReady))
+	if fd <= 0 {
+		return nil
+	}
+	_ = os.Unsetenv(envReady)
+	f := os.NewFile(uintptr(fd), "ready")
+	defer f.Close()
+	_, err := f.Write([]byte{1})
+	return err
+}
+
+// Upgrade 以相同参数启动新进程并传递监听的socket，新进程调用Ready后返回nil，调用方随后按正常流程关闭服务退出；
+// 新进程启动失败、提前退出或超时未就绪时返回错误，旧进程继续服务
+func Upgrade() error {
+	if !upgrading.CompareAndSwap(false, true) {
+		return errors.New("server: upgrade in progress")
+	}
+	err := upgrade()
+	if err != nil {
+		upgrading.Store(false)
+	}
+	return err
+}
+
+func upgrade() error {
+	lnMu.Lock()
+	addrs := make([]string, 0, len(listeners))
+	files := make([]*os.File, 0, len(listeners)+1)
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for addr, ln := range listeners {
+		fl, ok := ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			lnMu.Unlock()
+			return fmt.Errorf("server: listener %s can not be inherited", addr)
+		}
+		f, err := fl.File()
+		if err != nil {
+			lnMu.Unlock()
+			return err
+		}
+		addrs = append(addrs, addr)
+		files = append(files, f)
+	}
+	lnMu.Unlock()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	files = append(files, w)
+	cmd := exec.Command(os.Args[0], os.Args[1:]...) // 不用os.Executable，替换后的二进制路径不变但inode已变
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(), envListeners+"="+strings.Join(addrs, ","), envReady+"="+strconv.Itoa(3+len(addrs)))
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	_ = w.Close() // 新进程退出后读取返回EOF
+
+	done := make(chan error, 1)
+	go func() {
+		b := make([]byte, 1)
+		_, err := r.Read(b)
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		if err != nil {
+			err = fmt.Errorf("server: new process exited before ready: %w", err)
+		}
+	case <-time.After(time.Duration(upgradeOpt.Timeout) * time.Second):
+		err = errors.New("server: new process not ready in time")
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		go cmd.Wait() // nolint 回收子进程
+		return err
+	}
+	log.Printf("server: upgraded to pid %d", cmd.Process.Pid)
+	// 先停止accept，已accept的连接读到请求后再Shutdown，否则http.Server会丢弃关闭过程中才读到的请求
+	lnMu.Lock()
+	for _, ln := range listeners {
+		if ul, ok := ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false) // 新进程使用同一个socket文件，旧进程关闭时不能删除
+		}
+		_ = ln.Close()
+	}
+	lnMu.Unlock()
+	time.Sleep(500 * time.Millisecond)
+	return nil
+}
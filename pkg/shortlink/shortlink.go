@@ -0,0 +1,169 @@
+package shortlink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"net/url"
+	"project/pkg/db"
+	"project/pkg/util/random"
+	"time"
+)
+
+const (
+	TypeURL     = "url"     // 跳转网页
+	TypeMiniapp = "miniapp" // 跳转小程序页面(明文URL Scheme)
+
+	codeChars = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	codeLen   = 6
+	keyPrefix = "shortlink:"
+	emptyTTL  = time.Minute // 不存在的短码缓存时间，防止遍历穿透到db
+)
+
+var (
+	ErrNotFound = errors.New("shortlink: not found")
+	ErrDisabled = errors.New("shortlink: disabled")
+	ErrExpired  = errors.New("shortlink: expired")
+	ErrCode     = errors.New("shortlink: code already exists")
+)
+
+type Link struct {
+	ID         int       `json:"id"`
+	Code       string    `json:"code"`
+	Type       string    `json:"type"`
+	Target     string    `json:"target"`      // 网页地址或小程序页面路径
+	Query      string    `json:"query"`       // 小程序页面参数
+	ExpireTime int64     `json:"expire_time"` // 0为永久有效
+	Status     int8      `json:"status"`      // 1启用，-1停用
+	Remark     string    `json:"remark"`
+	CreateTime time.Time `json:"create_time" gorm:"->"`
+}
+
+func (*Link) TableName() string {
+	return "short_link"
+}
+
+// Click 跳转事件，由调用方投递到消息队列
+type Click struct {
+	Code      string `json:"code"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	Referer   string `json:"referer"`
+	Time      int64  `json:"time"`
+}
+
+type Shortener struct {
+	mysql *gorm.DB
+	redis *redis.Client
+	ttl   time.Duration // 跳转目标在redis中的缓存时间
+}
+
+func New(db *gorm.DB, rdb *redis.Client, ttl time.Duration) *Shortener {
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	return &Shortener{mysql: db, redis: rdb, ttl: ttl}
+}
+
+// Create 未指定Code时随机生成，冲突时重试；指定的Code已存在返回ErrCode
+func (s *Shortener) Create(ctx context.Context, l *Link) error {
+	custom := l.Code != ""
+	for i := 0; i < 3; i++ {
+		if !custom {
+			l.Code = random.GenString(codeChars, codeLen)
+		}
+		err := s.mysql.WithContext(ctx).Create(l).Error
+		if !db.IsDuplicateKey(err) {
+			if err == nil {
+				s.redis.Del(ctx, keyPrefix+l.Code) // 清除可能存在的空值缓存
+			}
+			return err
+		}
+		if custom {
+			break
+		}
+	}
+	return ErrCode
+}
+
+// Resolve 查询可跳转的短链，停用、过期返回对应错误
+func (s *Shortener) Resolve(ctx context.Context, code string) (*Link, error) {
+	l, err := s.find(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if l.Status != 1 {
+		return nil, ErrDisabled
+	}
+	if l.ExpireTime > 0 && l.ExpireTime <= time.Now().Unix() {
+		return nil, ErrExpired
+	}
+	return l, nil
+}
+
+func (s *Shortener) find(ctx context.Context, code string) (*Link, error) {
+	b, err := s.redis.Get(ctx, keyPrefix+code).Bytes()
+	if err == nil {
+		if len(b) == 0 {
+			return nil, ErrNotFound
+		}
+		var l Link
+		if err = json.Unmarshal(b, &l); err == nil {
+			return &l, nil
+		}
+	}
+	var l Link
+	err = s.mysql.WithContext(ctx).Take(&l, "code = ?", code).Error
+	if err == gorm.ErrRecordNotFound {
+		s.redis.Set(ctx, keyPrefix+code, "", emptyTTL)
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	b, _ = json.Marshal(&l)
+	s.redis.Set(ctx, keyPrefix+code, b, s.ttl)
+	return &l, nil
+}
+
+// Update 修改状态、有效期或目标，更新后删除缓存
+func (s *Shortener) Update(ctx context.Context, id int, values map[string]any) error {
+	var l Link
+	err := s.mysql.WithContext(ctx).Select("code").Take(&l, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err = s.mysql.WithContext(ctx).Model(&Link{}).Where("id = ?", id).Updates(values).Error; err != nil {
+		return err
+	}
+	return s.redis.Del(ctx, keyPrefix+l.Code).Err()
+}
+
+// Location 跳转地址，小程序页面使用明文URL Scheme
+func (l *Link) Location(appid string) string {
+	if l.Type != TypeMiniapp {
+		return l.Target
+	}
+	v := url.Values{}
+	v.Set("appid", appid)
+	v.Set("path", l.Target)
+	if l.Query != "" {
+		v.Set("query", l.Query)
+	}
+	return "weixin://dl/business/?" + v.Encode()
+}
+
+// MaxAge 可缓存的秒数，不超过maxAge且不超过剩余有效期
+func (l *Link) MaxAge(maxAge int64) int64 {
+	if l.ExpireTime > 0 {
+		if remain := l.ExpireTime - time.Now().Unix(); remain < maxAge {
+			return remain
+		}
+	}
+	return maxAge
+}
@@ -0,0 +1,163 @@
+package track
+
+import (
+	"context"
+	"encoding/json"
+	"project/pkg/logger"
+	"project/pkg/metrics"
+	"sync"
+	"time"
+)
+
+// 业务埋点：handler、service上报带类型的事件，批量投递到消息队列，供数仓消费，替代解析access日志
+
+var (
+	eventsTotal  = metrics.NewCounter("track_events_total", "按事件名统计的埋点上报数", "name")
+	droppedTotal = metrics.NewCounter("track_dropped_total", "丢弃的埋点数，full为缓冲区已满，publish为投递失败",
+		"reason")
+)
+
+// Props 事件属性，Event返回事件名和schema版本，字段有不兼容变更时应增加版本
+type Props interface {
+	Event() (name string, version int)
+}
+
+// Event 投递到队列的消息结构，消费方按name和version解析props
+type Event struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	TraceID string `json:"trace_id"`
+	UserID  int    `json:"user_id,omitempty"`
+	Time    int64  `json:"time"` // 毫秒时间戳
+	Props   Props  `json:"props"`
+}
+
+// Publisher 批量投递，如nsq.Producer.MultiPublish
+type Publisher func(topic string, body [][]byte) error
+
+type Options struct {
+	Disabled  bool
+	BatchSize int `mapstructure:"batchSize"` // 每批最多条数，默认100
+	Interval  int // 未满一批时的投递间隔，单位毫秒，默认1000
+	Buffer    int // 待投递的缓冲条数，默认10000，满时丢弃新事件
+}
+
+type Tracker struct {
+	topic    string
+	pub      Publisher
+	size     int
+	interval time.Duration
+	disabled bool
+
+	mu     sync.RWMutex
+	closed bool
+	ch     chan []byte
+	done   chan struct{}
+}
+
+func New(topic string, pub Publisher, opt *Options) *Tracker {
+	t := &Tracker{
+		topic:    topic,
+		pub:      pub,
+		size:     100,
+		interval: time.Second,
+		disabled: opt.Disabled,
+		done:     make(chan struct{}),
+	}
+	if opt.BatchSize > 0 {
+		t.size = opt.BatchSize
+	}
+	if opt.Interval > 0 {
+		t.interval = time.Duration(opt.Interval) * time.Millisecond
+	}
+	buffer := 10000
+	if opt.Buffer > 0 {
+		buffer = opt.Buffer
+	}
+	t.ch = make(chan []byte, buffer)
+	go t.loop()
+	return t
+}
+
+// Emit 异步上报，不阻塞请求；trace_id取自上下文，未登录时uid传0
+func (t *Tracker) Emit(ctx context.Context, uid int, p Props) {
+	if t.disabled {
+		return
+	}
+	name, version := p.Event()
+	tid, _ := ctx.Value("trace_id").(string)
+	b, err := json.Marshal(&Event{
+		Name:    name,
+		Version: version,
+		TraceID: tid,
+		UserID:  uid,
+		Time:    time.Now().UnixMilli(),
+		Props:   p,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("track.Emit json.Marshal error", name, err)
+		return
+	}
+	eventsTotal.Inc(name)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		return
+	}
+	select {
+	case t.ch <- b:
+	default:
+		droppedTotal.Inc("full")
+	}
+}
+
+// Close 停止接收并投递缓冲区中剩余的事件，应在服务退出前调用
+func (t *Tracker) Close() {
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.ch)
+	}
+	t.mu.Unlock()
+	<-t.done
+}
+
+func (t *Tracker) loop() {
+	defer close(t.done)
+	tick := time.NewTicker(t.interval)
+	defer tick.Stop()
+	batch := make([][]byte, 0, t.size)
+	for {
+		select {
+		case b, ok := <-t.ch:
+			if !ok {
+				t.flush(batch)
+				return
+			}
+			batch = append(batch, b)
+			if len(batch) >= t.size {
+				t.flush(batch)
+				batch = batch[:0]
+			}
+		case <-tick.C:
+			t.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush 失败时重试一次，仍失败则丢弃，埋点不保证不丢
+func (t *Tracker) flush(batch [][]byte) {
+	if len(batch) == 0 {
+		return
+	}
+	err := t.pub(t.topic, batch)
+	if err != nil {
+		time.Sleep(100 * time.Millisecond)
+		err = t.pub(t.topic, batch)
+	}
+	if err != nil {
+		droppedTotal.Add(float64(len(batch)), "publish")
+		logger.FromContext(context.Background()).Error("track.flush error", len(batch), err)
+	}
+}
@@ -0,0 +1,219 @@
+package transcode
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"project/pkg/coss"
+	"project/pkg/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ffmpeg 下载源文件到临时目录，一次解码按各码率缩放编码为H.264/AAC的HLS点播流，上传后删除临时目录
+type ffmpeg struct {
+	cfg   *Config
+	store coss.Store
+}
+
+type probeInfo struct {
+	height   int
+	duration int
+	audio    bool
+}
+
+func (f *ffmpeg) Transcode(ctx context.Context, job *Job, _ func(string)) (*Result, error) {
+	dir, err := os.MkdirTemp(f.cfg.TmpDir, "transcode-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "source"+path.Ext(job.Source))
+	if err = f.download(ctx, job.Source, src); err != nil {
+		return nil, err
+	}
+	info, err := f.probe(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	renditions := pick(f.cfg.Renditions, info.height)
+	out := filepath.Join(dir, "hls")
+	if err = os.MkdirAll(out, 0o755); err != nil {
+		return nil, err
+	}
+	var stderr tailBuffer
+	cmd := exec.CommandContext(ctx, f.cfg.FFmpeg, f.args(src, out, renditions, info.audio)...)
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &Failed{Msg: "ffmpeg " + err.Error() + ": " + stderr.String()}
+	}
+	if err = f.upload(ctx, out, job.Output); err != nil {
+		return nil, err
+	}
+	res := &Result{Playlist: job.Output + MasterPlaylist, Duration: info.duration}
+	for _, v := range renditions {
+		res.Renditions = append(res.Renditions, v.Name)
+	}
+	return res, nil
+}
+
+func (f *ffmpeg) download(ctx context.Context, key, dst string) error {
+	body, err := f.store.GetObject(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	file, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(file, body); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (f *ffmpeg) probe(ctx context.Context, src string) (*probeInfo, error) {
+	bin := "ffprobe"
+	if strings.ContainsRune(f.cfg.FFmpeg, filepath.Separator) {
+		bin = filepath.Join(filepath.Dir(f.cfg.FFmpeg), "ffprobe")
+	}
+	b, err := exec.CommandContext(ctx, bin, "-v", "error", "-show_entries", "stream=codec_type,height:format=duration",
+		"-of", "json", src).Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &Failed{Msg: "ffprobe " + err.Error()}
+	}
+	var r struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err = json.Unmarshal(b, &r); err != nil {
+		return nil, err
+	}
+	info := &probeInfo{}
+	for _, s := range r.Streams {
+		switch s.CodecType {
+		case "video":
+			if s.Height > info.height {
+				info.height = s.Height
+			}
+		case "audio":
+			info.audio = true
+		}
+	}
+	if info.height == 0 {
+		return nil, &Failed{Msg: ErrNoVideo.Error()}
+	}
+	d, _ := strconv.ParseFloat(r.Format.Duration, 64)
+	info.duration = int(d + 0.5)
+	return info, nil
+}
+
+// pick 按高度从高到低，去掉高于源视频的码率，全部高于源视频时保留最低的一路
+func pick(list []*Rendition, height int) []*Rendition {
+	sorted := append([]*Rendition(nil), list...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Height > sorted[j].Height })
+	var res []*Rendition
+	for _, v := range sorted {
+		if v.Height <= height {
+			res = append(res, v)
+		}
+	}
+	if len(res) == 0 {
+		res = sorted[len(sorted)-1:]
+	}
+	return res
+}
+
+func (f *ffmpeg) args(src, out string, list []*Rendition, audio bool) []string {
+	seg := strconv.Itoa(f.cfg.Segment)
+	args := []string{"-hide_banner", "-y", "-i", src}
+	filter := "[0:v]split=" + strconv.Itoa(len(list))
+	for i := range list {
+		filter += "[v" + strconv.Itoa(i) + "]"
+	}
+	streams := make([]string, len(list))
+	for i, v := range list {
+		n := strconv.Itoa(i)
+		filter += ";[v" + n + "]scale=-2:" + strconv.Itoa(v.Height) + "[v" + n + "o]"
+		rate := strconv.Itoa(v.Bitrate)
+		args = append(args, "-map", "[v"+n+"o]", "-c:v:"+n, "libx264", "-b:v:"+n, rate+"k",
+			"-maxrate:v:"+n, strconv.Itoa(v.Bitrate*107/100)+"k", "-bufsize:v:"+n, strconv.Itoa(v.Bitrate*3/2)+"k")
+		streams[i] = "v:" + n
+		if audio {
+			ab := v.Audio
+			if ab <= 0 {
+				ab = 128
+			}
+			args = append(args, "-map", "a:0", "-c:a:"+n, "aac", "-b:a:"+n, strconv.Itoa(ab)+"k")
+			streams[i] += ",a:" + n
+		}
+		streams[i] += ",name:" + v.Name
+	}
+	return append(args, "-filter_complex", filter,
+		"-preset", "veryfast", "-sc_threshold", "0", "-force_key_frames", "expr:gte(t,n_forced*"+seg+")",
+		"-f", "hls", "-hls_time", seg, "-hls_playlist_type", "vod", "-hls_flags", "independent_segments",
+		"-hls_segment_filename", filepath.Join(out, "%v", "seg_%03d.ts"),
+		"-master_pl_name", MasterPlaylist, "-var_stream_map", strings.Join(streams, " "),
+		filepath.Join(out, "%v", "index.m3u8"))
+}
+
+// upload 主播放列表最后上传，出现即表示全部分片可用
+func (f *ffmpeg) upload(ctx context.Context, dir, prefix string) error {
+	var files []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && info.Name() != MasterPlaylist {
+			files = append(files, p)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, p := range append(files, filepath.Join(dir, MasterPlaylist)) {
+		rel, _ := filepath.Rel(dir, p)
+		if err = f.put(ctx, p, prefix+filepath.ToSlash(rel)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *ffmpeg) put(ctx context.Context, file, key string) error {
+	r, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return f.store.PutObject(ctx, key, r)
+}
+
+// tailBuffer 只保留最后1KB，用于错误信息
+type tailBuffer struct {
+	bytes.Buffer
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	n, _ := b.Buffer.Write(p)
+	if over := b.Len() - 1024; over > 0 {
+		b.Next(over)
+	}
+	return n, nil
+}
@@ -0,0 +1,173 @@
+package transcode
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tencent 腾讯云媒体处理ProcessMedia，按自适应码流模板输出HLS到同一cos桶，DescribeTaskDetail轮询结果
+type tencent struct {
+	cfg    *Config
+	poll   time.Duration
+	client *http.Client
+}
+
+const (
+	mpsHost    = "mps.tencentcloudapi.com"
+	mpsVersion = "2019-06-12"
+)
+
+type mpsCos struct {
+	Bucket string
+	Region string
+	Object string `json:",omitempty"`
+}
+
+type mpsTask struct {
+	Status  string
+	ErrCode int
+	Message string
+	Output  *struct {
+		Path string
+	}
+}
+
+func (t *tencent) Transcode(ctx context.Context, job *Job, onTask func(taskID string)) (*Result, error) {
+	taskID := job.TaskID
+	if taskID == "" {
+		var res struct {
+			TaskId string
+		}
+		err := t.call(ctx, "ProcessMedia", map[string]any{
+			"InputInfo":     map[string]any{"Type": "COS", "CosInputInfo": &mpsCos{Bucket: t.cfg.Bucket, Region: t.cfg.Region, Object: "/" + strings.TrimPrefix(job.Source, "/")}},
+			"OutputStorage": map[string]any{"Type": "COS", "CosOutputStorage": &mpsCos{Bucket: t.cfg.Bucket, Region: t.cfg.Region}},
+			"OutputDir":     "/" + strings.TrimPrefix(job.Output, "/"),
+			"MediaProcessTask": map[string]any{
+				"AdaptiveDynamicStreamingTaskSet": []map[string]any{{"Definition": t.cfg.Definition}},
+			},
+		}, &res)
+		if err != nil {
+			return nil, err
+		}
+		taskID = res.TaskId
+		if onTask != nil {
+			onTask(taskID)
+		}
+	}
+	tick := time.NewTicker(t.poll)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-tick.C:
+		}
+		res, done, err := t.describe(ctx, taskID)
+		if err != nil || done {
+			return res, err
+		}
+	}
+}
+
+func (t *tencent) describe(ctx context.Context, taskID string) (*Result, bool, error) {
+	var res struct {
+		Status       string
+		WorkflowTask *struct {
+			ErrCode               int
+			Message               string
+			MediaProcessResultSet []struct {
+				Type                         string
+				AdaptiveDynamicStreamingTask *mpsTask
+			}
+		}
+	}
+	if err := t.call(ctx, "DescribeTaskDetail", map[string]any{"TaskId": taskID}, &res); err != nil {
+		return nil, false, err
+	}
+	if res.Status != "FINISH" {
+		return nil, false, nil
+	}
+	w := res.WorkflowTask
+	if w == nil {
+		return nil, true, &Failed{Msg: "task " + taskID + " without workflow result"}
+	}
+	if w.ErrCode != 0 {
+		return nil, true, &Failed{Msg: fmt.Sprintf("task %s %d: %s", taskID, w.ErrCode, w.Message)}
+	}
+	for _, v := range w.MediaProcessResultSet {
+		task := v.AdaptiveDynamicStreamingTask
+		if task == nil {
+			continue
+		}
+		if task.ErrCode != 0 || task.Output == nil {
+			return nil, true, &Failed{Msg: fmt.Sprintf("task %s %d: %s", taskID, task.ErrCode, task.Message)}
+		}
+		return &Result{Playlist: strings.TrimPrefix(task.Output.Path, "/")}, true, nil
+	}
+	return nil, true, &Failed{Msg: "task " + taskID + " without adaptive streaming output"}
+}
+
+// call API 3.0，TC3-HMAC-SHA256签名，业务错误在Response.Error
+func (t *tencent) call(ctx context.Context, action string, args, v any) error {
+	body, _ := json.Marshal(args)
+	now := time.Now().UTC()
+	ts := strconv.FormatInt(now.Unix(), 10)
+	date := now.Format("2006-01-02")
+	payload := sha256.Sum256(body)
+	canonical := "POST\n/\n\ncontent-type:application/json\nhost:" + mpsHost + "\n\ncontent-type;host\n" +
+		hex.EncodeToString(payload[:])
+	scope := date + "/mps/tc3_request"
+	hashed := sha256.Sum256([]byte(canonical))
+	toSign := "TC3-HMAC-SHA256\n" + ts + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+	secret := hmacSHA256(hmacSHA256(hmacSHA256([]byte("TC3"+t.cfg.SecretKey), date), "mps"), "tc3_request")
+	auth := fmt.Sprintf("TC3-HMAC-SHA256 Credential=%s/%s, SignedHeaders=content-type;host, Signature=%s",
+		t.cfg.SecretID, scope, hex.EncodeToString(hmacSHA256(secret, toSign)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://"+mpsHost, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Version", mpsVersion)
+	req.Header.Set("X-TC-Timestamp", ts)
+	req.Header.Set("X-TC-Region", t.cfg.Region)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var res struct {
+		Response json.RawMessage
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("transcode: http %d: %w", resp.StatusCode, err)
+	}
+	var e struct {
+		Error *struct {
+			Code    string
+			Message string
+		}
+	}
+	_ = json.Unmarshal(res.Response, &e)
+	if e.Error != nil {
+		return fmt.Errorf("transcode: tencent %s %s: %s", action, e.Error.Code, e.Error.Message)
+	}
+	return json.Unmarshal(res.Response, v)
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
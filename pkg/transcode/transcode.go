@@ -0,0 +1,101 @@
+package transcode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"project/pkg/coss"
+	"time"
+)
+
+// 视频转码：把对象存储中的原始视频转为多码率HLS(主播放列表+各码率的index.m3u8和ts分片)写回对象存储
+// ffmpeg在当前机器转码，tencent提交到腾讯云媒体处理(MPS)后轮询结果
+
+const (
+	DriverFFmpeg  = "ffmpeg"
+	DriverTencent = "tencent"
+)
+
+const MasterPlaylist = "master.m3u8"
+
+var (
+	ErrDriver  = errors.New("transcode: unsupported driver")
+	ErrNoVideo = errors.New("transcode: no video stream")
+)
+
+// Failed 源文件或转码参数的问题，重试不会成功
+type Failed struct {
+	Msg string
+}
+
+func (e *Failed) Error() string {
+	return "transcode: " + e.Msg
+}
+
+// Rendition 输出的一路码率，高度超过源视频的不输出(至少保留最低的一路)
+type Rendition struct {
+	Name    string // 输出目录名，如720p
+	Height  int
+	Bitrate int // 视频码率kbps
+	Audio   int // 音频码率kbps，默认128
+}
+
+var DefaultRenditions = []*Rendition{
+	{Name: "1080p", Height: 1080, Bitrate: 5000},
+	{Name: "720p", Height: 720, Bitrate: 2800},
+	{Name: "480p", Height: 480, Bitrate: 1200},
+}
+
+type Job struct {
+	Source string // 原始视频的对象路径
+	Output string // 输出目录，以/结尾，如media/hls/12/
+	TaskID string // 云端转码已提交的任务，进程重启后继续查询，不重复提交
+}
+
+type Result struct {
+	Playlist   string   // 主播放列表的对象路径
+	Duration   int      // 秒，云端转码为0
+	Renditions []string // 实际输出的码率
+}
+
+type Transcoder interface {
+	// Transcode 阻塞到转码完成或ctx取消；云端转码提交后调用onTask记录任务ID
+	Transcode(ctx context.Context, job *Job, onTask func(taskID string)) (*Result, error)
+}
+
+type Config struct {
+	Driver     string       // ffmpeg|tencent
+	Renditions []*Rendition // 默认DefaultRenditions，tencent使用模板中的码率
+	Segment    int          // 分片秒数，默认6
+	FFmpeg     string       // ffmpeg可执行文件，默认从PATH查找，ffprobe在同一目录
+	TmpDir     string       `mapstructure:"tmpDir"` // 源文件和输出的临时目录，默认系统临时目录
+	SecretID   string       // 以下为tencent
+	SecretKey  string
+	Region     string // 如ap-guangzhou
+	Bucket     string // 输入输出的cos桶，如media-1250000000
+	Definition int    // 自适应码流模板ID
+	Poll       int    // 查询任务状态的间隔秒数，默认10
+}
+
+func New(cfg *Config, store coss.Store, client *http.Client) (Transcoder, error) {
+	if len(cfg.Renditions) == 0 {
+		cfg.Renditions = DefaultRenditions
+	}
+	if cfg.Segment <= 0 {
+		cfg.Segment = 6
+	}
+	switch cfg.Driver {
+	case DriverFFmpeg:
+		if cfg.FFmpeg == "" {
+			cfg.FFmpeg = "ffmpeg"
+		}
+		return &ffmpeg{cfg: cfg, store: store}, nil
+	case DriverTencent:
+		poll := time.Duration(cfg.Poll) * time.Second
+		if poll <= 0 {
+			poll = 10 * time.Second
+		}
+		return &tencent{cfg: cfg, poll: poll, client: client}, nil
+	}
+	return nil, ErrDriver
+}
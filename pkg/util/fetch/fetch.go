@@ -0,0 +1,233 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 下载用户提交的地址(头像导入、链接预览等)，限制协议和端口，连接时校验解析后的IP，拒绝内网和云厂商元数据地址
+
+var (
+	ErrScheme      = errors.New("fetch: scheme not allowed")
+	ErrPort        = errors.New("fetch: port not allowed")
+	ErrBlocked     = errors.New("fetch: address not allowed")
+	ErrTooLarge    = errors.New("fetch: content too large")
+	ErrContentType = errors.New("fetch: content type not allowed")
+	ErrRedirect    = errors.New("fetch: too many redirects")
+)
+
+type Options struct {
+	Schemes      []string      // 默认http、https
+	Ports        []int         // 默认80、443
+	MaxSize      int64         // 响应body上限字节数，默认5MB
+	Timeout      time.Duration // 包括跳转的总耗时，默认10秒
+	MaxRedirects int           // 默认3
+	ContentTypes []string      // 允许的类型前缀，如image/，为空不限制；图片还需内容嗅探匹配
+}
+
+type Result struct {
+	URL         string // 跳转后的最终地址
+	ContentType string
+	Body        []byte
+}
+
+type Fetcher struct {
+	opt    Options
+	client *http.Client
+}
+
+// blockedNets 除IsPrivate、IsLoopback等标准库判断外额外禁止的网段
+var blockedNets = func() []*net.IPNet {
+	var list []*net.IPNet
+	for _, v := range []string{
+		"0.0.0.0/8",      // 本网络
+		"100.64.0.0/10",  // 运营商NAT，含阿里云元数据100.100.100.200
+		"192.0.0.0/24",   // IETF协议分配
+		"198.18.0.0/15",  // 基准测试
+		"240.0.0.0/4",    // 保留
+		"64:ff9b::/96",   // NAT64可映射到内网IPv4
+		"2001:db8::/32",  // 文档
+		"fec0::/10",      // 已废弃的站点本地
+		"169.254.0.0/16", // 链路本地，含AWS、腾讯云等元数据169.254.169.254、169.254.0.23
+		"fe80::/10",      // IPv6链路本地
+		"255.255.255.255/32",
+	} {
+		_, n, _ := net.ParseCIDR(v)
+		list = append(list, n)
+	}
+	return list
+}()
+
+// blockedHosts 元数据服务的域名，解析结果可能因环境不同而变化，直接按名称拒绝
+var blockedHosts = []string{"metadata.google.internal", "metadata.tencentyun.com", "metadata"}
+
+func Blocked(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func New(opt *Options) *Fetcher {
+	f := &Fetcher{}
+	if opt != nil {
+		f.opt = *opt
+	}
+	if len(f.opt.Schemes) == 0 {
+		f.opt.Schemes = []string{"http", "https"}
+	}
+	if len(f.opt.Ports) == 0 {
+		f.opt.Ports = []int{80, 443}
+	}
+	if f.opt.MaxSize <= 0 {
+		f.opt.MaxSize = 5 << 20
+	}
+	if f.opt.Timeout <= 0 {
+		f.opt.Timeout = 10 * time.Second
+	}
+	if f.opt.MaxRedirects <= 0 {
+		f.opt.MaxRedirects = 3
+	}
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		// 在建立连接前校验实际连接的IP，防止DNS重绑定绕过解析前的检查
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || Blocked(ip) {
+				return ErrBlocked
+			}
+			return nil
+		},
+	}
+	f.client = &http.Client{
+		Transport: &http.Transport{ // 不使用logger的transport，其会读完整个响应body
+			Proxy:                 nil, // 经代理访问时无法校验目标IP
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: 5 * time.Second,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		Timeout: f.opt.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > f.opt.MaxRedirects {
+				return ErrRedirect
+			}
+			return f.check(req.URL)
+		},
+	}
+	return f
+}
+
+func (f *Fetcher) check(u *url.URL) error {
+	scheme := strings.ToLower(u.Scheme)
+	ok := false
+	for _, v := range f.opt.Schemes {
+		ok = ok || v == scheme
+	}
+	if !ok {
+		return ErrScheme
+	}
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[scheme]
+	}
+	p, _ := strconv.Atoi(port)
+	ok = false
+	for _, v := range f.opt.Ports {
+		ok = ok || v == p
+	}
+	if !ok {
+		return ErrPort
+	}
+	if u.User != nil {
+		return ErrBlocked // 带账号密码的地址多用于混淆真实host
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	for _, v := range blockedHosts {
+		if host == v {
+			return ErrBlocked
+		}
+	}
+	if ip := net.ParseIP(host); ip != nil && Blocked(ip) {
+		return ErrBlocked
+	}
+	return nil
+}
+
+func (f *Fetcher) allowType(ct string) bool {
+	if len(f.opt.ContentTypes) == 0 {
+		return true
+	}
+	for _, v := range f.opt.ContentTypes {
+		if strings.HasPrefix(ct, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get 下载地址内容，超过MaxSize、类型不符或状态码非2xx时返回错误
+func (f *Fetcher) Get(ctx context.Context, rawURL string) (*Result, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err = f.check(u); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("fetch: unexpected status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > f.opt.MaxSize {
+		return nil, ErrTooLarge
+	}
+	ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !f.allowType(ct) {
+		return nil, ErrContentType
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, f.opt.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > f.opt.MaxSize {
+		return nil, ErrTooLarge
+	}
+	if strings.HasPrefix(ct, "image/") {
+		if sniff, _, _ := mime.ParseMediaType(http.DetectContentType(b)); !f.allowType(sniff) {
+			return nil, ErrContentType // 响应头声明为图片但内容不是
+		}
+	}
+	return &Result{URL: resp.Request.URL.String(), ContentType: ct, Body: b}, nil
+}
@@ -0,0 +1,73 @@
+package fields
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// 按字段路径裁剪json，路径用.分隔嵌套字段，数组自动作用于每个元素
+// 如 list.title,list.img 只保留list数组中每个元素的title和img
+
+type tree map[string]tree
+
+func parse(paths []string) tree {
+	t := make(tree)
+	for _, p := range paths {
+		node := t
+		for _, k := range strings.Split(strings.TrimSpace(p), ".") {
+			if k == "" {
+				break
+			}
+			next, ok := node[k]
+			if !ok {
+				next = make(tree)
+				node[k] = next
+			}
+			node = next
+		}
+	}
+	return t
+}
+
+// Split 解析逗号分隔的fields参数
+func Split(s string) []string {
+	res := make([]string, 0, 4)
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func Prune(b []byte, paths []string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(prune(v, parse(paths)))
+}
+
+func prune(v any, t tree) any {
+	if len(t) == 0 {
+		return v
+	}
+	switch x := v.(type) {
+	case map[string]any:
+		for k := range x {
+			if sub, ok := t[k]; ok {
+				x[k] = prune(x[k], sub)
+			} else {
+				delete(x, k)
+			}
+		}
+	case []any:
+		for i := range x {
+			x[i] = prune(x[i], t)
+		}
+	}
+	return v
+}
@@ -1,7 +1,10 @@
 package files
 
 import (
+	"mime"
 	"net/http"
+	"path"
+	"strings"
 )
 
 func CheckImage(b []byte) (ext string, ok bool) {
@@ -12,3 +15,81 @@ func CheckImage(b []byte) (ext string, ok bool) {
 	}
 	return
 }
+
+// CheckVideo 按文件头识别mp4/mov/webm，ext带.；DetectContentType不识别mov(ftyp品牌为qt)，按ftyp盒子判断
+func CheckVideo(b []byte) (ext string, ok bool) {
+	switch http.DetectContentType(b) {
+	case "video/mp4":
+		return ".mp4", true
+	case "video/webm":
+		return ".webm", true
+	}
+	if len(b) >= 12 && string(b[4:8]) == "ftyp" {
+		if string(b[8:12]) == "qt  " {
+			return ".mov", true
+		}
+		return ".mp4", true
+	}
+	return "", false
+}
+
+// sniffExts 允许上传的类型(按文件头识别)及可用的扩展名，第一个为默认扩展名；html、svg、xml等可执行脚本的类型不允许
+var sniffExts = map[string][]string{
+	"image/jpeg":      {".jpg", ".jpeg"},
+	"image/png":       {".png"},
+	"image/gif":       {".gif"},
+	"image/webp":      {".webp"},
+	"image/bmp":       {".bmp"},
+	"application/pdf": {".pdf"},
+	"application/zip": {".zip", ".docx", ".xlsx", ".pptx"},
+	"video/mp4":       {".mp4"},
+	"audio/mpeg":      {".mp3"},
+	"text/plain":      {".txt", ".csv", ".json", ".md"},
+}
+
+// declaredAlias 客户端声明的常见类型别名，归一到按文件头识别的类型
+var declaredAlias = map[string]string{
+	"image/jpg":                    "image/jpeg",
+	"image/pjpeg":                  "image/jpeg",
+	"application/x-zip-compressed": "application/zip",
+	"audio/mp3":                    "audio/mpeg",
+	"text/csv":                     "text/plain",
+	"text/markdown":                "text/plain",
+	"application/json":             "text/plain",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   "application/zip",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         "application/zip",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": "application/zip",
+}
+
+// Sniff 按文件头识别类型，忽略客户端声明的类型；文件名的扩展名或声明的类型(为空或octet-stream时不检查)与识别结果不一致、
+// 或识别出的类型不允许上传时返回false。返回的ext为保存时使用的扩展名(带.)，contentType为识别出的类型
+func Sniff(b []byte, filename, declared string) (ext, contentType string, ok bool) {
+	contentType = http.DetectContentType(b)
+	base, _, _ := mime.ParseMediaType(contentType)
+	exts, allowed := sniffExts[base]
+	if !allowed {
+		return "", contentType, false
+	}
+	if declared != "" {
+		d, _, err := mime.ParseMediaType(declared)
+		if err != nil {
+			return "", contentType, false
+		}
+		if a, ok := declaredAlias[d]; ok {
+			d = a
+		}
+		if d != base && d != "application/octet-stream" {
+			return "", contentType, false
+		}
+	}
+	ext = strings.ToLower(path.Ext(filename))
+	if ext == "" {
+		return exts[0], contentType, true
+	}
+	for _, v := range exts {
+		if v == ext {
+			return ext, contentType, true
+		}
+	}
+	return "", contentType, false
+}
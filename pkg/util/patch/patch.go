@@ -0,0 +1,342 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+/*
+RFC 6902 JSON Patch 和 RFC 7386 JSON Merge Patch
+	Content-Type: application/json-patch+json  [{"op":"replace","path":"/nickname","value":"x"}]
+	Content-Type: application/merge-patch+json {"nickname":"x"}
+*/
+
+const (
+	MediaTypeJSONPatch  = "application/json-patch+json"
+	MediaTypeMergePatch = "application/merge-patch+json"
+)
+
+var (
+	ErrInvalid   = errors.New("patch: invalid document")
+	ErrPath      = errors.New("patch: path not found")
+	ErrForbidden = errors.New("patch: path not allowed")
+	ErrTest      = errors.New("patch: test operation failed")
+)
+
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func decode(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, ErrInvalid
+	}
+	return v, nil
+}
+
+// Merge RFC 7386，null表示删除字段，对象递归合并，其他类型整体替换
+func Merge(doc, patch []byte) ([]byte, error) {
+	d, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(d, p))
+}
+
+func merge(doc, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	dm, ok := doc.(map[string]any)
+	if !ok {
+		dm = make(map[string]any, len(pm))
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(dm, k)
+		} else {
+			dm[k] = merge(dm[k], v)
+		}
+	}
+	return dm
+}
+
+// MergePaths 返回merge patch修改到的叶子路径(JSON Pointer格式)，用于校验允许修改的字段
+func MergePaths(patch []byte) ([]string, error) {
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		m, ok := v.(map[string]any)
+		if !ok || len(m) == 0 {
+			paths = append(paths, prefix)
+			return
+		}
+		for k, sub := range m {
+			walk(prefix+"/"+escape(k), sub)
+		}
+	}
+	walk("", p)
+	return paths, nil
+}
+
+// ParseOperations 解析RFC 6902请求体
+func ParseOperations(b []byte) ([]*Operation, error) {
+	var ops []*Operation
+	if err := json.Unmarshal(b, &ops); err != nil {
+		return nil, ErrInvalid
+	}
+	for _, op := range ops {
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, ErrInvalid
+			}
+		case "move", "copy":
+			if op.From == "" {
+				return nil, ErrInvalid
+			}
+		case "remove":
+		default:
+			return nil, ErrInvalid
+		}
+	}
+	return ops, nil
+}
+
+// OperationPaths 返回所有操作会修改或读取的路径
+func OperationPaths(ops []*Operation) []string {
+	paths := make([]string, 0, len(ops))
+	for _, op := range ops {
+		paths = append(paths, op.Path)
+		if op.From != "" {
+			paths = append(paths, op.From)
+		}
+	}
+	return paths
+}
+
+// CheckPaths 路径必须等于或位于allowed之下，如allowed为/nickname时允许/nickname
+func CheckPaths(paths []string, allowed ...string) error {
+	for _, p := range paths {
+		ok := false
+		for _, a := range allowed {
+			if p == a || strings.HasPrefix(p, a+"/") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+// Apply RFC 6902，任一操作失败则整体失败
+func Apply(doc []byte, ops []*Operation) ([]byte, error) {
+	d, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		if d, err = applyOne(d, op); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(d)
+}
+
+func applyOne(doc any, op *Operation) (any, error) {
+	switch op.Op {
+	case "add":
+		v, err := decode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return set(doc, op.Path, v, true)
+	case "replace":
+		v, err := decode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := get(doc, op.Path); err != nil {
+			return nil, err
+		}
+		return set(doc, op.Path, v, false)
+	case "remove":
+		return remove(doc, op.Path)
+	case "move":
+		v, err := get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = remove(doc, op.From); err != nil {
+			return nil, err
+		}
+		return set(doc, op.Path, v, true)
+	case "copy":
+		v, err := get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		b, _ := json.Marshal(v) // 深拷贝
+		v, _ = decode(b)
+		return set(doc, op.Path, v, true)
+	case "test":
+		v, err := get(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		expect, err := decode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		a, _ := json.Marshal(v)
+		b, _ := json.Marshal(expect)
+		if !bytes.Equal(a, b) {
+			return nil, ErrTest
+		}
+		return doc, nil
+	}
+	return nil, ErrInvalid
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func split(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if path[0] != '/' {
+		return nil, ErrPath
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func index(t string, n int, allowEnd bool) (int, error) {
+	if t == "-" && allowEnd {
+		return n, nil
+	}
+	i, err := strconv.Atoi(t)
+	if err != nil || i < 0 || i > n || (i == n && !allowEnd) {
+		return 0, ErrPath
+	}
+	return i, nil
+}
+
+func get(doc any, path string) (any, error) {
+	tokens, err := split(path)
+	if err != nil {
+		return nil, err
+	}
+	cur := doc
+	for _, t := range tokens {
+		switch x := cur.(type) {
+		case map[string]any:
+			v, ok := x[t]
+			if !ok {
+				return nil, ErrPath
+			}
+			cur = v
+		case []any:
+			i, err := index(t, len(x), false)
+			if err != nil {
+				return nil, err
+			}
+			cur = x[i]
+		default:
+			return nil, ErrPath
+		}
+	}
+	return cur, nil
+}
+
+// set 写入path，insert为true时数组按add语义插入，否则替换
+func set(doc any, path string, value any, insert bool) (any, error) {
+	tokens, err := split(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, parentPath(path))
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch x := parent.(type) {
+	case map[string]any:
+		x[last] = value
+		return doc, nil
+	case []any:
+		i, err := index(last, len(x), insert)
+		if err != nil {
+			return nil, err
+		}
+		if !insert {
+			x[i] = value
+			return doc, nil
+		}
+		x = append(x, nil)
+		copy(x[i+1:], x[i:])
+		x[i] = value
+		return set(doc, parentPath(path), x, false)
+	}
+	return nil, ErrPath
+}
+
+func remove(doc any, path string) (any, error) {
+	tokens, err := split(path)
+	if err != nil || len(tokens) == 0 {
+		return nil, ErrPath
+	}
+	parent, err := get(doc, parentPath(path))
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch x := parent.(type) {
+	case map[string]any:
+		if _, ok := x[last]; !ok {
+			return nil, ErrPath
+		}
+		delete(x, last)
+		return doc, nil
+	case []any:
+		i, err := index(last, len(x), false)
+		if err != nil {
+			return nil, err
+		}
+		return set(doc, parentPath(path), append(x[:i], x[i+1:]...), false)
+	}
+	return nil, ErrPath
+}
+
+func parentPath(path string) string {
+	return path[:strings.LastIndexByte(path, '/')]
+}
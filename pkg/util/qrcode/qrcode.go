@@ -0,0 +1,88 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/draw"
+	"image"
+	"image/color"
+	_ "image/jpeg" // logo支持jpg
+	"image/png"
+)
+
+const (
+	MinSize = 128
+	MaxSize = 1024
+)
+
+var ErrLogo = errors.New("qrcode: invalid logo image")
+
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+type Options struct {
+	Size  int    // 边长像素，默认256
+	Level string // 纠错等级L/M/Q/H，默认M，带logo时至少为Q
+	Logo  []byte // 可选，png/jpg格式，缩放为边长的1/5居中覆盖
+}
+
+// Encode 生成png格式的二维码
+func Encode(content string, opt *Options) ([]byte, error) {
+	size := opt.Size
+	if size == 0 {
+		size = 256
+	}
+	if size < MinSize {
+		size = MinSize
+	}
+	if size > MaxSize {
+		size = MaxSize
+	}
+	level, ok := levels[opt.Level]
+	if !ok {
+		level = qrcode.Medium
+	}
+	if len(opt.Logo) > 0 && level < qrcode.High {
+		level = qrcode.High // logo遮挡约4%面积，需更高纠错
+	}
+	q, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, err
+	}
+	img := q.Image(size)
+	if len(opt.Logo) > 0 {
+		if img, err = overlay(img, opt.Logo); err != nil {
+			return nil, err
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err = png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// overlay 在中心绘制带白边的logo，白边为logo边长的1/10
+func overlay(src image.Image, logo []byte) (image.Image, error) {
+	l, _, err := image.Decode(bytes.NewReader(logo))
+	if err != nil {
+		return nil, ErrLogo
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, src, b.Min, draw.Src)
+
+	side := b.Dx() / 5
+	pad := side / 10
+	center := image.Pt(b.Min.X+b.Dx()/2, b.Min.Y+b.Dy()/2)
+	bg := image.Rect(center.X-side/2-pad, center.Y-side/2-pad, center.X+side/2+pad, center.Y+side/2+pad)
+	draw.Draw(dst, bg, image.NewUniform(color.White), image.Point{}, draw.Src)
+	area := image.Rect(center.X-side/2, center.Y-side/2, center.X+side/2, center.Y+side/2)
+	draw.CatmullRom.Scale(dst, area, l, l.Bounds(), draw.Over, nil)
+	return dst, nil
+}
@@ -0,0 +1,103 @@
+package useragent
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWindows = "windows"
+	PlatformMac     = "mac"
+	PlatformLinux   = "linux"
+	PlatformUnknown = "unknown"
+
+	EnvMiniProgram = "miniprogram" // 小程序wx.request
+	EnvWebview     = "webview"     // 小程序web-view组件内的网页
+	EnvWechat      = "wechat"      // 微信内置浏览器
+	EnvWecom       = "wecom"       // 企业微信内置浏览器
+	EnvBrowser     = "browser"     // 其他
+)
+
+var (
+	reWechat  = regexp.MustCompile(`MicroMessenger/([\d.]+)`)
+	reIOS     = regexp.MustCompile(`OS (\d+[_\d]*) like Mac OS X`)
+	reAndroid = regexp.MustCompile(`Android ([\d.]+)`)
+)
+
+// Client 从User-Agent和Referer解析的客户端信息
+type Client struct {
+	Platform      string `json:"platform"`
+	OSVersion     string `json:"os_version,omitempty"`
+	Env           string `json:"env"`
+	WechatVersion string `json:"wechat_version,omitempty"`
+	Mobile        bool   `json:"mobile"`
+}
+
+func Parse(ua, referer string) *Client {
+	cl := &Client{Platform: PlatformUnknown, Env: EnvBrowser}
+	switch {
+	case strings.Contains(ua, "iPhone") || strings.Contains(ua, "iPad"):
+		cl.Platform, cl.Mobile = PlatformIOS, true
+		if m := reIOS.FindStringSubmatch(ua); m != nil {
+			cl.OSVersion = strings.ReplaceAll(m[1], "_", ".")
+		}
+	case strings.Contains(ua, "Android"):
+		cl.Platform, cl.Mobile = PlatformAndroid, true
+		if m := reAndroid.FindStringSubmatch(ua); m != nil {
+			cl.OSVersion = m[1]
+		}
+	case strings.Contains(ua, "Windows"):
+		cl.Platform = PlatformWindows
+	case strings.Contains(ua, "Macintosh"):
+		cl.Platform = PlatformMac
+	case strings.Contains(ua, "Linux"):
+		cl.Platform = PlatformLinux
+	}
+	if m := reWechat.FindStringSubmatch(ua); m != nil {
+		cl.WechatVersion = m[1]
+		cl.Env = EnvWechat
+	}
+	switch {
+	case strings.HasPrefix(referer, "https://servicewechat.com/") || strings.Contains(ua, "MiniProgramEnv"):
+		cl.Env = EnvMiniProgram // 小程序请求的Referer固定为servicewechat.com/{appid}/{version}/page-frame.html
+	case strings.Contains(ua, "miniProgram"):
+		cl.Env = EnvWebview
+	case strings.Contains(ua, "wxwork/"):
+		cl.Env = EnvWecom
+	}
+	return cl
+}
+
+// InWechat 是否在微信环境内(小程序、web-view、内置浏览器)
+func (cl *Client) InWechat() bool {
+	return cl.WechatVersion != ""
+}
+
+// WechatAtLeast 微信版本号不低于v，如"8.0.30"，非微信环境返回false
+func (cl *Client) WechatAtLeast(v string) bool {
+	return cl.WechatVersion != "" && Compare(cl.WechatVersion, v) >= 0
+}
+
+// Compare 按点分隔逐段比较数字版本号，a>b返回1，相等返回0，a<b返回-1
+func Compare(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
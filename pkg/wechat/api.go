@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 )
@@ -16,12 +17,36 @@ const (
 type BasicAPI interface { //基础接口，需提供appid和secret
 	JsCode2Session(ctx context.Context, code string) (*JsCode2SessionResp, error)
 	GetAccessToken(ctx context.Context) (*GetAccessTokenResp, error)
+	OAuthAccessToken(ctx context.Context, code string) (*OAuthTokenResp, error)
+	OAuthUserInfo(ctx context.Context, accessToken, openid string) (*OAuthUserInfoResp, error)
 }
 
 type ServerAPI interface { //服务端接口，需提供access_token
 	GetUserPhoneNumber(ctx context.Context, code string) (*UserPhoneNumberResp, error)
 	GetDailySummary(ctx context.Context, args *DatacubeArgs) (*DailySummaryResp, error)
 	GetDailyVisitTrend(ctx context.Context, args *DatacubeArgs) (*VisitTrendResp, error)
+	GetDailyRetain(ctx context.Context, args *DatacubeArgs) (*DailyRetainResp, error)
+	GetVisitDistribution(ctx context.Context, args *DatacubeArgs) (*VisitDistributionResp, error)
+	SendCustomMessage(ctx context.Context, msg *CustomMessage) (*CustomMessageResp, error)
+	SetTyping(ctx context.Context, openid, command string) (*CustomMessageResp, error)
+	SendSubscribeMessage(ctx context.Context, msg *SubscribeMessage) (*SubscribeMessageResp, error)
+	UploadTempMedia(ctx context.Context, filename string, bin []byte) (*UploadMediaResp, error)
+	OCRIDCard(ctx context.Context, img []byte) (*IDCardResp, error)
+	OCRBankCard(ctx context.Context, img []byte) (*BankCardResp, error)
+	OCRBizLicense(ctx context.Context, img []byte) (*BizLicenseResp, error)
+	OCRPrintedText(ctx context.Context, img []byte) (*PrintedTextResp, error)
+	GetJSAPITicket(ctx context.Context) (*GetTicketResp, error)
+	CreateMenu(ctx context.Context, menu *Menu) (*MpResp, error)
+	GetMenu(ctx context.Context) (*GetMenuResp, error)
+	DeleteMenu(ctx context.Context) (*MpResp, error)
+	UploadMedia(ctx context.Context, typ, filename string, bin []byte) (*UploadMediaResp, error)
+	AddMaterial(ctx context.Context, typ, filename string, bin []byte, video *VideoDesc) (*MaterialResp, error)
+	DeleteMaterial(ctx context.Context, mediaID string) (*MpResp, error)
+	BatchGetMaterial(ctx context.Context, typ string, offset, count int) (*MaterialListResp, error)
+	UploadArticleImage(ctx context.Context, filename string, bin []byte) (*MaterialResp, error)
+	AddDraft(ctx context.Context, articles []*Article) (*MaterialResp, error)
+	SubmitPublish(ctx context.Context, mediaID string) (*PublishResp, error)
+	GetPublish(ctx context.Context, publishID string) (*PublishStatusResp, error)
 }
 
 type FullAPI interface { //全部接口
@@ -45,6 +70,7 @@ type TokenFunc func(ctx context.Context) (string, error)
 type server struct {
 	client *http.Client
 	token  TokenFunc
+	quota  *Quota
 }
 
 func NewBasicAPI(appid, secret string, client *http.Client) BasicAPI {
@@ -60,21 +86,25 @@ func NewBasicAPI(appid, secret string, client *http.Client) BasicAPI {
 	return api
 }
 
-func NewServerAPI(client *http.Client, token TokenFunc) ServerAPI {
-	api := &server{token: token}
+// NewServerAPI access_token从store中按key读取
+func NewServerAPI(client *http.Client, store TokenStore, key string, opts ...Option) ServerAPI {
+	api := &server{token: StoreToken(store, key)}
 	if client == nil {
 		api.client = http.DefaultClient
 	} else {
 		api.client = client
 	}
+	for _, opt := range opts {
+		opt(api)
+	}
 	return api
 }
 
-func NewFullAPI(appid, secret string, client *http.Client, token TokenFunc) FullAPI {
+func NewFullAPI(appid, secret string, client *http.Client, store TokenStore, key string, opts ...Option) FullAPI {
 	if client == nil {
 		client = http.DefaultClient
 	}
-	return &full{
+	api := &full{
 		basic: &basic{
 			appid:  appid,
 			secret: secret,
@@ -82,9 +112,13 @@ func NewFullAPI(appid, secret string, client *http.Client, token TokenFunc) Full
 		},
 		server: &server{
 			client: client,
-			token:  token,
+			token:  StoreToken(store, key),
 		},
 	}
+	for _, opt := range opts {
+		opt(api.server)
+	}
+	return api
 }
 
 func (api *basic) get(ctx context.Context, path string, data url.Values, result any) error {
@@ -101,11 +135,40 @@ func (api *basic) get(ctx context.Context, path string, data url.Values, result
 	if err != nil {
 		return err
 	}
-	err = json.Unmarshal(b, result)
+	return decode(b, result)
+}
+
+func (api *server) get(ctx context.Context, path string, data url.Values, result any) error {
+	if err := api.quota.take(ctx, path); err != nil {
+		return err
+	}
+	tk, err := api.token(ctx)
+	if err != nil {
+		return err
+	}
+	data.Set("access_token", tk)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, host+path+"?"+data.Encode(), http.NoBody)
+	if err != nil {
+		return err
+	}
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	err = decode(b, result)
+	api.quota.exhausted(ctx, path, err)
 	return err
 }
 
 func (api *server) post(ctx context.Context, path string, data any, result any) error {
+	if err := api.quota.take(ctx, path); err != nil {
+		return err
+	}
 	tk, err := api.token(ctx)
 	if err != nil {
 		return err
@@ -124,7 +187,51 @@ func (api *server) post(ctx context.Context, path string, data any, result any)
 	if err != nil {
 		return err
 	}
-	err = json.Unmarshal(b, result)
+	err = decode(b, result)
+	api.quota.exhausted(ctx, path, err)
+	return err
+}
+
+// postFile 以multipart/form-data上传文件
+func (api *server) postFile(ctx context.Context, path string, query url.Values, field, filename string, bin []byte, result any) error {
+	return api.postForm(ctx, path, query, field, filename, bin, nil, result)
+}
+
+// postForm 上传文件并附带其他表单字段，如永久视频素材的description
+func (api *server) postForm(ctx context.Context, path string, query url.Values, field, filename string, bin []byte,
+	fields map[string]string, result any) error {
+	if err := api.quota.take(ctx, path); err != nil {
+		return err
+	}
+	tk, err := api.token(ctx)
+	if err != nil {
+		return err
+	}
+	buf := bytes.NewBuffer(nil)
+	w := multipart.NewWriter(buf)
+	form, _ := w.CreateFormFile(field, filename)
+	form.Write(bin) // nolint
+	for k, v := range fields {
+		w.WriteField(k, v) // nolint
+	}
+	w.Close() // nolint
+	query.Set("access_token", tk)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, host+path+"?"+query.Encode(), buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	err = decode(b, result)
+	api.quota.exhausted(ctx, path, err)
 	return err
 }
 
@@ -0,0 +1,118 @@
+package wechat
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+消息推送(明文模式，数据格式JSON)：
+Router按MsgType(事件消息按Event)分发到注册的处理函数，处理函数返回值作为被动回复，
+返回TransferCustomerService转人工客服，返回nil则回复success
+文档：https://developers.weixin.qq.com/miniprogram/dev/framework/server-ability/message-push.html
+*/
+
+const (
+	MsgText        = "text"
+	MsgImage       = "image"
+	MsgMiniPage    = "miniprogrampage"
+	MsgEvent       = "event"
+	EventEnterChat = "user_enter_tempsession"
+)
+
+type Message struct {
+	ToUserName   string `json:"ToUserName"`
+	FromUserName string `json:"FromUserName"` // 用户openid
+	CreateTime   int64  `json:"CreateTime"`
+	MsgType      string `json:"MsgType"`
+	MsgID        int64  `json:"MsgId,omitempty"`
+	Content      string `json:"Content,omitempty"`
+	PicURL       string `json:"PicUrl,omitempty"`
+	MediaID      string `json:"MediaId,omitempty"`
+	Title        string `json:"Title,omitempty"`
+	AppID        string `json:"AppId,omitempty"`
+	PagePath     string `json:"PagePath,omitempty"`
+	ThumbURL     string `json:"ThumbUrl,omitempty"`
+	ThumbMediaID string `json:"ThumbMediaId,omitempty"`
+	Event        string `json:"Event,omitempty"`
+	SessionFrom  string `json:"SessionFrom,omitempty"`
+}
+
+type MessageHandler func(ctx context.Context, msg *Message) (any, error)
+
+type Router struct {
+	token    string
+	handlers map[string]MessageHandler
+	fallback MessageHandler
+}
+
+func NewRouter(token string) *Router {
+	return &Router{
+		token:    token,
+		handlers: make(map[string]MessageHandler),
+	}
+}
+
+// Handle 注册处理函数，事件消息使用Event值注册，如 EventEnterChat
+func (r *Router) Handle(typ string, h MessageHandler) {
+	r.handlers[typ] = h
+}
+
+// Fallback 未注册类型的默认处理
+func (r *Router) Fallback(h MessageHandler) {
+	r.fallback = h
+}
+
+// CheckSignature 校验消息来自微信服务器，未配置令牌时一律不通过
+func (r *Router) CheckSignature(signature, timestamp, nonce string) bool {
+	if r.token == "" {
+		return false
+	}
+	s := []string{r.token, timestamp, nonce}
+	sort.Strings(s)
+	sum := sha1.Sum([]byte(strings.Join(s, "")))
+	return hex.EncodeToString(sum[:]) == signature
+}
+
+// Dispatch 解析消息并分发，返回值为被动回复内容
+func (r *Router) Dispatch(ctx context.Context, body []byte) (*Message, any, error) {
+	var msg Message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, nil, err
+	}
+	key := msg.MsgType
+	if key == MsgEvent {
+		key = msg.Event
+	}
+	h, ok := r.handlers[key]
+	if !ok {
+		h = r.fallback
+	}
+	if h == nil {
+		return &msg, nil, nil
+	}
+	reply, err := h(ctx, &msg)
+	return &msg, reply, err
+}
+
+type TransferReply struct {
+	ToUserName   string `json:"ToUserName"`
+	FromUserName string `json:"FromUserName"`
+	CreateTime   int64  `json:"CreateTime"`
+	MsgType      string `json:"MsgType"`
+}
+
+// TransferCustomerService 将会话转到网页版客服工具
+func TransferCustomerService(msg *Message) *TransferReply {
+	return &TransferReply{
+		ToUserName:   msg.FromUserName,
+		FromUserName: msg.ToUserName,
+		CreateTime:   time.Now().Unix(),
+		MsgType:      "transfer_customer_service",
+	}
+}
@@ -0,0 +1,84 @@
+package wechat
+
+import (
+	"context"
+	"net/url"
+)
+
+/*
+客服消息：
+SendCustomMessage：发送客服消息(文本、图片、图文链接、小程序卡片)
+SetTyping：下发客服输入状态
+UploadTempMedia：上传临时素材，用于发送图片消息
+*/
+
+const (
+	CustomText     = "text"
+	CustomImage    = "image"
+	CustomLink     = "link"
+	CustomMiniPage = "miniprogrampage"
+
+	TypingOn  = "Typing"
+	TypingOff = "CancelTyping"
+)
+
+type CustomMessage struct {
+	ToUser          string              `json:"touser"`
+	MsgType         string              `json:"msgtype"`
+	Text            *CustomTextItem     `json:"text,omitempty"`
+	Image           *CustomImageItem    `json:"image,omitempty"`
+	Link            *CustomLinkItem     `json:"link,omitempty"`
+	MiniProgramPage *CustomMiniPageItem `json:"miniprogrampage,omitempty"`
+}
+
+type CustomTextItem struct {
+	Content string `json:"content"`
+}
+
+type CustomImageItem struct {
+	MediaID string `json:"media_id"`
+}
+
+type CustomLinkItem struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	ThumbURL    string `json:"thumb_url"`
+}
+
+type CustomMiniPageItem struct {
+	Title        string `json:"title"`
+	PagePath     string `json:"pagepath"`
+	ThumbMediaID string `json:"thumb_media_id"`
+}
+
+type CustomMessageResp struct {
+	respErr
+}
+
+func (api *server) SendCustomMessage(ctx context.Context, msg *CustomMessage) (*CustomMessageResp, error) {
+	var resp CustomMessageResp
+	err := api.post(ctx, "/cgi-bin/message/custom/send", msg, &resp)
+	return &resp, err
+}
+
+func (api *server) SetTyping(ctx context.Context, openid, command string) (*CustomMessageResp, error) {
+	var resp CustomMessageResp
+	err := api.post(ctx, "/cgi-bin/message/custom/typing",
+		map[string]string{"touser": openid, "command": command}, &resp)
+	return &resp, err
+}
+
+type UploadMediaResp struct {
+	respErr
+	Type      string `json:"type,omitempty"`
+	MediaID   string `json:"media_id,omitempty"`
+	CreatedAt int64  `json:"created_at,omitempty"`
+}
+
+// UploadTempMedia 上传临时素材(3天有效)，客服消息目前仅支持image类型
+func (api *server) UploadTempMedia(ctx context.Context, filename string, bin []byte) (*UploadMediaResp, error) {
+	var resp UploadMediaResp
+	err := api.postFile(ctx, "/cgi-bin/media/upload", url.Values{"type": {"image"}}, "media", filename, bin, &resp)
+	return &resp, err
+}
@@ -6,6 +6,8 @@ import "context"
 数据分析：
 GetDailySummary：日访问概况
 GetDailyVisitTrend：日访问趋势
+GetDailyRetain：日留存
+GetVisitDistribution：访问分布
 */
 
 const DateFormat = "20060102"
@@ -52,3 +54,42 @@ func (api *server) GetDailyVisitTrend(ctx context.Context, args *DatacubeArgs) (
 	err := api.post(ctx, "/datacube/getweanalysisappiddailyvisittrend", args, &resp)
 	return &resp, err
 }
+
+type DailyRetainResp struct {
+	respErr
+	RefDate    string        `json:"ref_date,omitempty"`
+	VisitUvNew []*RetainItem `json:"visit_uv_new,omitempty"`
+	VisitUv    []*RetainItem `json:"visit_uv,omitempty"`
+}
+type RetainItem struct {
+	Key   int `json:"key"` // 0表示当天，1表示1天后，依此类推
+	Value int `json:"value"`
+}
+
+// GetDailyRetain 日留存，begin_date与end_date相同
+func (api *server) GetDailyRetain(ctx context.Context, args *DatacubeArgs) (*DailyRetainResp, error) {
+	var resp DailyRetainResp
+	err := api.post(ctx, "/datacube/getweanalysisappiddailyretaininfo", args, &resp)
+	return &resp, err
+}
+
+type VisitDistributionResp struct {
+	respErr
+	RefDate string                   `json:"ref_date,omitempty"`
+	List    []*VisitDistributionData `json:"list,omitempty"`
+}
+type VisitDistributionData struct {
+	Index    string                   `json:"index"` // access_source_session_cnt|access_staytime_info|access_depth_info
+	ItemList []*VisitDistributionItem `json:"item_list"`
+}
+type VisitDistributionItem struct {
+	Key   int `json:"key"`
+	Value int `json:"value"`
+}
+
+// GetVisitDistribution 访问分布(来源场景、停留时长、访问深度)
+func (api *server) GetVisitDistribution(ctx context.Context, args *DatacubeArgs) (*VisitDistributionResp, error) {
+	var resp VisitDistributionResp
+	err := api.post(ctx, "/datacube/getweanalysisappidvisitdistribution", args, &resp)
+	return &resp, err
+}
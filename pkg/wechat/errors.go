@@ -0,0 +1,77 @@
+package wechat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// 接口返回的errcode不为0时，get、post等统一解码为*APIError返回，响应结构中的字段仍会填充
+// 调用方用errors.Is按分类判断，或用errors.As取出Code与业务错误码对应，不要匹配errmsg
+
+const (
+	ErrcodeInvalidToken = 40001 // access_token无效或已过期
+	ErrcodeInvalidCode  = 40029 // code无效
+	ErrcodeCodeUsed     = 40163 // code已被使用
+	ErrcodeRateLimit    = 45009 // 接口调用超过限额
+	ErrcodeRiskyContent = 87014 // 内容含有违法违规信息
+)
+
+var (
+	ErrQuota        = errors.New("wechat: api quota exceeded")
+	ErrImage        = errors.New("wechat: image unrecognized")
+	ErrInvalidCode  = errors.New("wechat: code invalid or used")
+	ErrRiskyContent = errors.New("wechat: risky content")
+)
+
+// APIError 接口返回的错误码
+type APIError struct {
+	Code int
+	Msg  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("wechat: %d %s", e.Code, e.Msg)
+}
+
+// Is 按错误码分类，使errors.Is(err, ErrQuota)等成立
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrQuota:
+		return e.Code == ErrcodeRateLimit || e.Code == 45011 || e.Code == 45047 // 每分钟频率限制、客服消息条数限制
+	case ErrImage:
+		return e.Code == 101000 || e.Code == 101001 || e.Code == 101002 // 图片拉取失败、未识别到证件、图片数据无效
+	case ErrInvalidCode:
+		return e.Code == ErrcodeInvalidCode || e.Code == ErrcodeCodeUsed
+	case ErrRiskyContent:
+		return e.Code == ErrcodeRiskyContent
+	}
+	return false
+}
+
+// Code 返回err中的错误码，非*APIError返回0
+func Code(err error) int {
+	var e *APIError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return 0
+}
+
+func (e *respErr) apiError() error {
+	if e.Errcode == 0 {
+		return nil
+	}
+	return &APIError{Code: e.Errcode, Msg: e.Errmsg}
+}
+
+// decode 解析响应，errcode不为0时返回*APIError
+func decode(b []byte, result any) error {
+	if err := json.Unmarshal(b, result); err != nil {
+		return err
+	}
+	if r, ok := result.(interface{ apiError() error }); ok {
+		return r.apiError()
+	}
+	return nil
+}
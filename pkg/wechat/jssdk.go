@@ -0,0 +1,113 @@
+package wechat
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/url"
+	"project/pkg/util/random"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 公众号网页的JS-SDK：服务端用公众号access_token换取jsapi_ticket，按页面url签名后注入wx.config
+
+type GetTicketResp struct {
+	respErr
+	Ticket    string `json:"ticket,omitempty"`
+	ExpiresIn int64  `json:"expires_in,omitempty"`
+}
+
+// GetJSAPITicket 需使用公众号的access_token，有效期7200秒，应统一刷新后缓存
+func (api *server) GetJSAPITicket(ctx context.Context) (*GetTicketResp, error) {
+	data := make(url.Values)
+	data.Set("type", "jsapi")
+	var resp GetTicketResp
+	err := api.get(ctx, "/cgi-bin/ticket/getticket", data, &resp)
+	return &resp, err
+}
+
+// JSConfig wx.config的参数
+type JSConfig struct {
+	AppID     string `json:"appId"`
+	Timestamp int64  `json:"timestamp"`
+	NonceStr  string `json:"nonceStr"`
+	Signature string `json:"signature"`
+}
+
+// SignJSSDK 按当前页面的完整url(不含#及其后部分)签名
+func SignJSSDK(appid, ticket, pageURL string) *JSConfig {
+	if i := strings.IndexByte(pageURL, '#'); i >= 0 {
+		pageURL = pageURL[:i]
+	}
+	cfg := &JSConfig{
+		AppID:     appid,
+		Timestamp: time.Now().Unix(),
+		NonceStr:  random.UUID()[:16],
+	}
+	s := "jsapi_ticket=" + ticket + "&noncestr=" + cfg.NonceStr + "&timestamp=" + strconv.FormatInt(cfg.Timestamp, 10) +
+		"&url=" + pageURL
+	sum := sha1.Sum([]byte(s))
+	cfg.Signature = hex.EncodeToString(sum[:])
+	return cfg
+}
+
+// JSSDK 按页面url生成wx.config，ticket由外部统一刷新(如redis)，进程内缓存ttl减少读取
+type JSSDK struct {
+	appid  string
+	ticket TokenFunc
+	ttl    time.Duration
+
+	mu     sync.Mutex
+	value  string
+	expire time.Time
+}
+
+// NewJSSDK ttl为进程内缓存ticket的时间，需小于ticket刷新后旧ticket仍有效的时间，默认1分钟
+func NewJSSDK(appid string, ticket TokenFunc, ttl time.Duration) *JSSDK {
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	return &JSSDK{appid: appid, ticket: ticket, ttl: ttl}
+}
+
+func (j *JSSDK) AppID() string {
+	return j.appid
+}
+
+// Config 读取ticket失败时返回错误，缓存未过期时不读取
+func (j *JSSDK) Config(ctx context.Context, pageURL string) (*JSConfig, error) {
+	ticket, err := j.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return SignJSSDK(j.appid, ticket, pageURL), nil
+}
+
+// Prime 预先读取ticket到进程内缓存，用于启动预热
+func (j *JSSDK) Prime(ctx context.Context) error {
+	_, err := j.load(ctx)
+	return err
+}
+
+func (j *JSSDK) load(ctx context.Context) (string, error) {
+	j.mu.Lock()
+	ticket := j.value
+	if time.Now().After(j.expire) {
+		ticket = ""
+	}
+	j.mu.Unlock()
+	if ticket == "" {
+		v, err := j.ticket(ctx)
+		if err != nil {
+			return "", err
+		}
+		ticket = v
+		j.mu.Lock()
+		j.value, j.expire = v, time.Now().Add(j.ttl)
+		j.mu.Unlock()
+	}
+	return ticket, nil
+}
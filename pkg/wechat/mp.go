@@ -0,0 +1,198 @@
+package wechat
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+/*
+公众号菜单、素材和图文发布，需使用公众号的access_token：
+CreateMenu、GetMenu、DeleteMenu：自定义菜单，创建时整体覆盖
+UploadMedia：上传临时素材(3天有效)
+AddMaterial、DeleteMaterial、BatchGetMaterial：永久素材(图片、语音、视频、缩略图)
+UploadArticleImage：上传图文正文中的图片，返回的url只能在图文中使用
+AddDraft、SubmitPublish、GetPublish：新建图文草稿后提交发布，发布结果异步，按publish_id查询
+*/
+
+const (
+	MediaImage = "image"
+	MediaVoice = "voice"
+	MediaVideo = "video"
+	MediaThumb = "thumb"
+)
+
+// 发布状态
+const (
+	PublishSuccess  = 0
+	PublishPending  = 1
+	PublishOriginal = 2 // 原创校验失败
+	PublishFailed   = 3
+	PublishAudit    = 4 // 平台审核不通过
+	PublishDeleted  = 5 // 成功后被删除
+	PublishBanned   = 6 // 成功后被封禁
+)
+
+type MpResp struct {
+	respErr
+}
+
+// MenuButton 一级菜单最多3个，子菜单最多5个；type为click、view、miniprogram、media_id、article_id等
+type MenuButton struct {
+	Type      string        `json:"type,omitempty"`
+	Name      string        `json:"name"`
+	Key       string        `json:"key,omitempty"`
+	URL       string        `json:"url,omitempty"`
+	MediaID   string        `json:"media_id,omitempty"`
+	AppID     string        `json:"appid,omitempty"`
+	PagePath  string        `json:"pagepath,omitempty"`
+	ArticleID string        `json:"article_id,omitempty"`
+	SubButton []*MenuButton `json:"sub_button,omitempty"`
+}
+
+type Menu struct {
+	Button []*MenuButton `json:"button"`
+}
+
+type GetMenuResp struct {
+	respErr
+	Menu *Menu `json:"menu,omitempty"`
+}
+
+func (api *server) CreateMenu(ctx context.Context, menu *Menu) (*MpResp, error) {
+	var resp MpResp
+	err := api.post(ctx, "/cgi-bin/menu/create", menu, &resp)
+	return &resp, err
+}
+
+// GetMenu 通过本接口创建的菜单，未创建时返回errcode 46003
+func (api *server) GetMenu(ctx context.Context) (*GetMenuResp, error) {
+	var resp GetMenuResp
+	err := api.get(ctx, "/cgi-bin/menu/get", make(url.Values), &resp)
+	return &resp, err
+}
+
+func (api *server) DeleteMenu(ctx context.Context) (*MpResp, error) {
+	var resp MpResp
+	err := api.get(ctx, "/cgi-bin/menu/delete", make(url.Values), &resp)
+	return &resp, err
+}
+
+// UploadMedia 上传临时素材，typ为image、voice、video、thumb
+func (api *server) UploadMedia(ctx context.Context, typ, filename string, bin []byte) (*UploadMediaResp, error) {
+	var resp UploadMediaResp
+	err := api.postFile(ctx, "/cgi-bin/media/upload", url.Values{"type": {typ}}, "media", filename, bin, &resp)
+	return &resp, err
+}
+
+// VideoDesc 永久视频素材的标题和简介
+type VideoDesc struct {
+	Title        string `json:"title"`
+	Introduction string `json:"introduction"`
+}
+
+type MaterialResp struct {
+	respErr
+	MediaID string `json:"media_id,omitempty"`
+	URL     string `json:"url,omitempty"` // 图片素材和图文正文图片的地址
+}
+
+// AddMaterial 上传永久素材，视频需提供video
+func (api *server) AddMaterial(ctx context.Context, typ, filename string, bin []byte, video *VideoDesc) (*MaterialResp, error) {
+	var fields map[string]string
+	if video != nil {
+		b, _ := json.Marshal(video)
+		fields = map[string]string{"description": string(b)}
+	}
+	var resp MaterialResp
+	err := api.postForm(ctx, "/cgi-bin/material/add_material", url.Values{"type": {typ}}, "media", filename, bin, fields, &resp)
+	return &resp, err
+}
+
+func (api *server) DeleteMaterial(ctx context.Context, mediaID string) (*MpResp, error) {
+	var resp MpResp
+	err := api.post(ctx, "/cgi-bin/material/del_material", map[string]string{"media_id": mediaID}, &resp)
+	return &resp, err
+}
+
+type MaterialItem struct {
+	MediaID    string `json:"media_id"`
+	Name       string `json:"name"`
+	URL        string `json:"url,omitempty"`
+	UpdateTime int64  `json:"update_time"`
+}
+
+type MaterialListResp struct {
+	respErr
+	TotalCount int             `json:"total_count"`
+	ItemCount  int             `json:"item_count"`
+	Item       []*MaterialItem `json:"item"`
+}
+
+// BatchGetMaterial 永久素材列表，count为1-20
+func (api *server) BatchGetMaterial(ctx context.Context, typ string, offset, count int) (*MaterialListResp, error) {
+	var resp MaterialListResp
+	err := api.post(ctx, "/cgi-bin/material/batchget_material",
+		map[string]any{"type": typ, "offset": offset, "count": count}, &resp)
+	return &resp, err
+}
+
+// UploadArticleImage 图片仅支持jpg/png且小于1M，不占用素材数量
+func (api *server) UploadArticleImage(ctx context.Context, filename string, bin []byte) (*MaterialResp, error) {
+	var resp MaterialResp
+	err := api.postFile(ctx, "/cgi-bin/media/uploadimg", make(url.Values), "media", filename, bin, &resp)
+	return &resp, err
+}
+
+// Article 图文，content中的图片需使用UploadArticleImage返回的url，thumb_media_id为永久素材
+type Article struct {
+	Title              string `json:"title"`
+	Author             string `json:"author,omitempty"`
+	Digest             string `json:"digest,omitempty"`
+	Content            string `json:"content"`
+	ContentSourceURL   string `json:"content_source_url,omitempty"`
+	ThumbMediaID       string `json:"thumb_media_id"`
+	NeedOpenComment    int    `json:"need_open_comment,omitempty"`
+	OnlyFansCanComment int    `json:"only_fans_can_comment,omitempty"`
+}
+
+// AddDraft 新建草稿，返回草稿的media_id
+func (api *server) AddDraft(ctx context.Context, articles []*Article) (*MaterialResp, error) {
+	var resp MaterialResp
+	err := api.post(ctx, "/cgi-bin/draft/add", map[string]any{"articles": articles}, &resp)
+	return &resp, err
+}
+
+type PublishResp struct {
+	respErr
+	PublishID string `json:"publish_id,omitempty"`
+	MsgDataID string `json:"msg_data_id,omitempty"`
+}
+
+// SubmitPublish 提交草稿发布，结果通过GetPublish查询
+func (api *server) SubmitPublish(ctx context.Context, mediaID string) (*PublishResp, error) {
+	var resp PublishResp
+	err := api.post(ctx, "/cgi-bin/freepublish/submit", map[string]string{"media_id": mediaID}, &resp)
+	return &resp, err
+}
+
+type PublishStatusResp struct {
+	respErr
+	PublishID     string `json:"publish_id,omitempty"`
+	PublishStatus int    `json:"publish_status"`
+	ArticleID     string `json:"article_id,omitempty"`
+	ArticleDetail *struct {
+		Count int `json:"count"`
+		Item  []*struct {
+			Idx        int    `json:"idx"`
+			ArticleURL string `json:"article_url"`
+		} `json:"item"`
+	} `json:"article_detail,omitempty"`
+	FailIdx []int `json:"fail_idx,omitempty"`
+}
+
+func (api *server) GetPublish(ctx context.Context, publishID string) (*PublishStatusResp, error) {
+	var resp PublishStatusResp
+	err := api.post(ctx, "/cgi-bin/freepublish/get", map[string]string{"publish_id": publishID}, &resp)
+	return &resp, err
+}
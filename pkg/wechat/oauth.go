@@ -0,0 +1,71 @@
+package wechat
+
+import (
+	"context"
+	"net/url"
+)
+
+// 网页授权和开放平台登录：网站应用扫码(snsapi_login)、移动应用SDK、公众号网页(snsapi_base/snsapi_userinfo)均用code换取openid
+// 同一开放平台下的小程序、公众号、网站和移动应用返回相同的unionid，用于关联同一用户
+
+const (
+	ScopeLogin    = "snsapi_login"    // 网站应用扫码登录
+	ScopeBase     = "snsapi_base"     // 公众号网页静默授权，只获取openid
+	ScopeUserInfo = "snsapi_userinfo" // 公众号网页授权，可获取昵称头像
+)
+
+// QRConnectURL 网站应用的扫码登录页，授权后跳转到redirect并带上code和state
+func QRConnectURL(appid, redirect, state string) string {
+	return "https://open.weixin.qq.com/connect/qrconnect?" + authorizeQuery(appid, redirect, ScopeLogin, state) + "#wechat_redirect"
+}
+
+// AuthorizeURL 公众号网页授权页，需在微信内打开
+func AuthorizeURL(appid, redirect, scope, state string) string {
+	return "https://open.weixin.qq.com/connect/oauth2/authorize?" + authorizeQuery(appid, redirect, scope, state) + "#wechat_redirect"
+}
+
+// authorizeQuery 微信校验参数顺序，不能使用url.Values.Encode
+func authorizeQuery(appid, redirect, scope, state string) string {
+	return "appid=" + appid + "&redirect_uri=" + url.QueryEscape(redirect) + "&response_type=code&scope=" + scope +
+		"&state=" + url.QueryEscape(state)
+}
+
+type OAuthTokenResp struct {
+	respErr
+	AccessToken  string `json:"access_token,omitempty"` // 用户的授权凭证，与服务端access_token不同
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Openid       string `json:"openid,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	Unionid      string `json:"unionid,omitempty"`
+}
+
+// OAuthAccessToken code只能使用一次，5分钟内有效
+func (api *basic) OAuthAccessToken(ctx context.Context, code string) (*OAuthTokenResp, error) {
+	data := make(url.Values)
+	data.Set("appid", api.appid)
+	data.Set("secret", api.secret)
+	data.Set("code", code)
+	data.Set("grant_type", "authorization_code")
+	var resp OAuthTokenResp
+	err := api.get(ctx, "/sns/oauth2/access_token", data, &resp)
+	return &resp, err
+}
+
+type OAuthUserInfoResp struct {
+	respErr
+	Openid     string `json:"openid,omitempty"`
+	Nickname   string `json:"nickname,omitempty"`
+	HeadImgURL string `json:"headimgurl,omitempty"`
+	Unionid    string `json:"unionid,omitempty"`
+}
+
+// OAuthUserInfo snsapi_login、snsapi_userinfo授权后获取昵称头像
+func (api *basic) OAuthUserInfo(ctx context.Context, accessToken, openid string) (*OAuthUserInfoResp, error) {
+	data := make(url.Values)
+	data.Set("access_token", accessToken)
+	data.Set("openid", openid)
+	var resp OAuthUserInfoResp
+	err := api.get(ctx, "/sns/userinfo", data, &resp)
+	return &resp, err
+}
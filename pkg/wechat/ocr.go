@@ -0,0 +1,128 @@
+package wechat
+
+import (
+	"bytes"
+	"context"
+	"golang.org/x/image/draw"
+	"image"
+	"image/jpeg"
+	_ "image/png" // 注册png解码
+	"net/url"
+)
+
+/*
+OCR识别(图片不超过2M，超过时按比例压缩后上传)：
+OCRIDCard：身份证
+OCRBankCard：银行卡
+OCRBizLicense：营业执照
+OCRPrintedText：通用印刷体
+额度用完返回ErrQuota，未识别到内容返回ErrImage
+*/
+
+const (
+	ocrMaxSize = 2 << 20
+	ocrMaxSide = 2048
+)
+
+type IDCardResp struct {
+	respErr
+	Type        string `json:"type,omitempty"` // Front|Back
+	Name        string `json:"name,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Addr        string `json:"addr,omitempty"`
+	Gender      string `json:"gender,omitempty"`
+	Nationality string `json:"nationality,omitempty"`
+	ValidDate   string `json:"valid_date,omitempty"`
+}
+
+func (api *server) OCRIDCard(ctx context.Context, img []byte) (*IDCardResp, error) {
+	var resp IDCardResp
+	err := api.ocr(ctx, "/cv/ocr/idcard", img, &resp)
+	return &resp, err
+}
+
+type BankCardResp struct {
+	respErr
+	Number string `json:"number,omitempty"`
+}
+
+func (api *server) OCRBankCard(ctx context.Context, img []byte) (*BankCardResp, error) {
+	var resp BankCardResp
+	err := api.ocr(ctx, "/cv/ocr/bankcard", img, &resp)
+	return &resp, err
+}
+
+type BizLicenseResp struct {
+	respErr
+	RegNum              string `json:"reg_num,omitempty"`
+	Serial              string `json:"serial,omitempty"`
+	LegalRepresentative string `json:"legal_representative,omitempty"`
+	EnterpriseName      string `json:"enterprise_name,omitempty"`
+	TypeOfOrganization  string `json:"type_of_organization,omitempty"`
+	Address             string `json:"address,omitempty"`
+	TypeOfEnterprise    string `json:"type_of_enterprise,omitempty"`
+	BusinessScope       string `json:"business_scope,omitempty"`
+	RegisteredCapital   string `json:"registered_capital,omitempty"`
+	PaidInCapital       string `json:"paid_in_capital,omitempty"`
+	ValidPeriod         string `json:"valid_period,omitempty"`
+	RegisteredDate      string `json:"registered_date,omitempty"`
+}
+
+func (api *server) OCRBizLicense(ctx context.Context, img []byte) (*BizLicenseResp, error) {
+	var resp BizLicenseResp
+	err := api.ocr(ctx, "/cv/ocr/bizlicense", img, &resp)
+	return &resp, err
+}
+
+type PrintedTextResp struct {
+	respErr
+	Items []*PrintedTextItem `json:"items,omitempty"`
+}
+type PrintedTextItem struct {
+	Text string `json:"text"`
+}
+
+func (api *server) OCRPrintedText(ctx context.Context, img []byte) (*PrintedTextResp, error) {
+	var resp PrintedTextResp
+	err := api.ocr(ctx, "/cv/ocr/comm", img, &resp)
+	return &resp, err
+}
+
+func (api *server) ocr(ctx context.Context, path string, img []byte, result any) error {
+	img, err := shrinkImage(img)
+	if err != nil {
+		return err
+	}
+	return api.postFile(ctx, path, url.Values{"type": {"photo"}}, "img", "img.jpg", img, result)
+}
+
+// shrinkImage 图片超过2M时缩放到最长边2048并以jpeg重新编码
+func shrinkImage(b []byte) ([]byte, error) {
+	if len(b) <= ocrMaxSize {
+		return b, nil
+	}
+	src, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, ErrImage
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w > ocrMaxSide || h > ocrMaxSide {
+		if w > h {
+			w, h = ocrMaxSide, h*ocrMaxSide/w
+		} else {
+			w, h = w*ocrMaxSide/h, ocrMaxSide
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+	for q := 85; q >= 45; q -= 20 {
+		buf := bytes.NewBuffer(nil)
+		if err = jpeg.Encode(buf, dst, &jpeg.Options{Quality: q}); err != nil {
+			return nil, err
+		}
+		if buf.Len() <= ocrMaxSize {
+			return buf.Bytes(), nil
+		}
+	}
+	return nil, ErrImage
+}
@@ -0,0 +1,102 @@
+package wechat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/pkg/metrics"
+	"time"
+)
+
+// 接口每日调用额度：按path在共享计数器中累加当日调用次数，达到上限后不再请求微信，直接返回*QuotaError
+// 微信返回45009时将当日计数置满，避免活动中途持续触发限额；额度按自然日(服务器时区)重置
+
+type QuotaCounter interface {
+	// Incr 累加key的计数并返回累加后的值，首次创建时设置过期时间
+	Incr(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error)
+}
+
+type Quota struct {
+	Counter QuotaCounter
+	Prefix  string           // 计数key的前缀，区分不同的appid
+	Limits  map[string]int64 // path对应的每日上限，如 /cv/ocr/idcard: 100，未配置的接口不计数
+}
+
+// QuotaError 当日额度已用完，errors.Is(err, ErrQuota)成立
+type QuotaError struct {
+	Path  string
+	Limit int64
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("wechat: daily quota %d of %s exhausted", e.Limit, e.Path)
+}
+
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuota
+}
+
+var (
+	quotaUsed     = metrics.NewGauge("wechat_quota_used", "微信接口当日已调用次数", "path")
+	quotaRemain   = metrics.NewGauge("wechat_quota_remaining", "微信接口当日剩余额度", "path")
+	quotaRejected = metrics.NewCounter("wechat_quota_rejected_total", "额度用完未请求微信的调用数", "path")
+)
+
+type Option func(*server)
+
+// WithQuota 按每日额度计数和拒绝
+func WithQuota(q *Quota) Option {
+	return func(s *server) {
+		if q != nil && q.Counter != nil && len(q.Limits) > 0 {
+			s.quota = q
+		}
+	}
+}
+
+func (q *Quota) key(path string) string {
+	return q.Prefix + time.Now().Format("20060102") + ":" + path
+}
+
+// take 占用一次额度，计数器异常时放行
+func (q *Quota) take(ctx context.Context, path string) error {
+	if q == nil {
+		return nil
+	}
+	limit, ok := q.Limits[path]
+	if !ok {
+		return nil
+	}
+	n, err := q.Counter.Incr(ctx, q.key(path), 1, 25*time.Hour)
+	if err != nil {
+		return nil
+	}
+	q.observe(path, n, limit)
+	if n > limit {
+		quotaRejected.Inc(path)
+		return &QuotaError{Path: path, Limit: limit}
+	}
+	return nil
+}
+
+// exhausted 微信返回超过限额时将当日计数置满
+func (q *Quota) exhausted(ctx context.Context, path string, err error) {
+	if q == nil || err == nil {
+		return
+	}
+	limit, ok := q.Limits[path]
+	var e *APIError
+	if !ok || !errors.As(err, &e) || e.Code != ErrcodeRateLimit {
+		return
+	}
+	if n, err := q.Counter.Incr(ctx, q.key(path), limit, 25*time.Hour); err == nil {
+		q.observe(path, n, limit)
+	}
+}
+
+func (q *Quota) observe(path string, n, limit int64) {
+	if n > limit {
+		n = limit
+	}
+	quotaUsed.Set(float64(n), path)
+	quotaRemain.Set(float64(limit-n), path)
+}
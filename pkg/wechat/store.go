@@ -0,0 +1,34 @@
+package wechat
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/sync/singleflight"
+	"time"
+)
+
+// 凭证存储：access_token、jsapi_ticket由一个进程统一刷新写入，其他进程只读取
+// 实现见tokenstore(redis、mysql)，wechat包只依赖接口
+
+var ErrNoToken = errors.New("wechat: token not found")
+
+type TokenStore interface {
+	// Get 返回凭证和剩余有效期，不存在或已过期返回ErrNoToken
+	Get(ctx context.Context, key string) (string, time.Duration, error)
+	Set(ctx context.Context, key, token string, ttl time.Duration) error
+	// CompareAndSet 当前值为old时写入，old为空表示当前没有有效值；返回false说明已被其他进程刷新
+	CompareAndSet(ctx context.Context, key, old, token string, ttl time.Duration) (bool, error)
+}
+
+var single singleflight.Group
+
+// StoreToken 从store读取key对应的凭证，同一key的并发读取合并为一次
+func StoreToken(store TokenStore, key string) TokenFunc {
+	return func(ctx context.Context) (string, error) {
+		val, err, _ := single.Do(key, func() (any, error) {
+			tk, _, err := store.Get(ctx, key)
+			return tk, err
+		})
+		return val.(string), err
+	}
+}
@@ -0,0 +1,40 @@
+package wechat
+
+import "context"
+
+/*
+订阅消息：用户在小程序内授权模板后，服务端按模板下发一次
+SendSubscribeMessage：发送订阅消息，用户未授权或授权次数已用完返回ErrcodeSubscribeRefused
+*/
+
+const (
+	ErrcodeSubscribeRefused = 43101 // 用户拒绝接收或未授权
+
+	MiniStateDeveloper = "developer"
+	MiniStateTrial     = "trial"
+	MiniStateFormal    = "formal"
+)
+
+type SubscribeMessage struct {
+	ToUser           string                       `json:"touser"`
+	TemplateID       string                       `json:"template_id"`
+	Page             string                       `json:"page,omitempty"`
+	MiniprogramState string                       `json:"miniprogram_state,omitempty"`
+	Lang             string                       `json:"lang,omitempty"`
+	Data             map[string]*SubscribeMsgItem `json:"data"`
+}
+
+type SubscribeMsgItem struct {
+	Value string `json:"value"`
+}
+
+type SubscribeMessageResp struct {
+	respErr
+	MsgID int64 `json:"msgid,omitempty"`
+}
+
+func (api *server) SendSubscribeMessage(ctx context.Context, msg *SubscribeMessage) (*SubscribeMessageResp, error) {
+	var resp SubscribeMessageResp
+	err := api.post(ctx, "/cgi-bin/message/subscribe/send", msg, &resp)
+	return &resp, err
+}
@@ -0,0 +1,60 @@
+package tokenstore
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"project/pkg/wechat"
+	"time"
+)
+
+// Redis key即为redis的key
+type Redis struct {
+	redis *redis.Client
+}
+
+func NewRedis(rdb *redis.Client) *Redis {
+	return &Redis{redis: rdb}
+}
+
+func (s *Redis) Get(ctx context.Context, key string) (string, time.Duration, error) {
+	pipe := s.redis.Pipeline()
+	get := pipe.Get(ctx, key)
+	ttl := pipe.TTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err == redis.Nil {
+		return "", 0, wechat.ErrNoToken
+	} else if err != nil {
+		return "", 0, err
+	}
+	if get.Val() == "" {
+		return "", 0, wechat.ErrNoToken
+	}
+	return get.Val(), ttl.Val(), nil
+}
+
+func (s *Redis) Set(ctx context.Context, key, token string, ttl time.Duration) error {
+	return s.redis.Set(ctx, key, token, ttl).Err()
+}
+
+// KEYS[1]=key ARGV[1]=old ARGV[2]=token ARGV[3]=ttl毫秒
+var scriptCAS = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if (cur or '') ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+func (s *Redis) CompareAndSet(ctx context.Context, key, old, token string, ttl time.Duration) (bool, error) {
+	n, err := scriptCAS.Run(ctx, s.redis, []string{key}, old, token, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// Incr 接口额度计数，实现wechat.QuotaCounter
+func (s *Redis) Incr(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	v, err := s.redis.IncrBy(ctx, key, n).Result()
+	if err == nil && v == n {
+		err = s.redis.Expire(ctx, key, ttl).Err()
+	}
+	return v, err
+}
@@ -0,0 +1,70 @@
+package tokenstore
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/pkg/wechat"
+	"time"
+)
+
+// Token 凭证的持久化记录，表结构见design/sql；用于没有redis的服务
+type Token struct {
+	Name       string    `json:"name" gorm:"primaryKey"`
+	Token      string    `json:"token"`
+	ExpireTime time.Time `json:"expire_time"`
+}
+
+func (*Token) TableName() string {
+	return "wechat_token"
+}
+
+// SQL key即为wechat_token的name
+type SQL struct {
+	mysql *gorm.DB
+}
+
+func NewSQL(orm *gorm.DB) *SQL {
+	return &SQL{mysql: orm}
+}
+
+func (s *SQL) Get(ctx context.Context, key string) (string, time.Duration, error) {
+	var t Token
+	err := s.mysql.WithContext(ctx).Take(&t, "name = ?", key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", 0, wechat.ErrNoToken
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	ttl := time.Until(t.ExpireTime)
+	if t.Token == "" || ttl <= 0 {
+		return "", 0, wechat.ErrNoToken
+	}
+	return t.Token, ttl, nil
+}
+
+func (s *SQL) Set(ctx context.Context, key, token string, ttl time.Duration) error {
+	return s.mysql.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&Token{Name: key, Token: token, ExpireTime: time.Now().Add(ttl)}).Error
+}
+
+// CompareAndSet old为空时只在记录不存在或已过期时写入
+func (s *SQL) CompareAndSet(ctx context.Context, key, old, token string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	values := map[string]any{"token": token, "expire_time": now.Add(ttl)}
+	if old != "" {
+		opt := s.mysql.WithContext(ctx).Model(&Token{}).
+			Where("name = ? AND token = ? AND expire_time > ?", key, old, now).Updates(values)
+		return opt.RowsAffected > 0, opt.Error
+	}
+	opt := s.mysql.WithContext(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).
+		Create(&Token{Name: key, Token: token, ExpireTime: now.Add(ttl)})
+	if opt.Error != nil || opt.RowsAffected > 0 {
+		return opt.RowsAffected > 0, opt.Error
+	}
+	opt = s.mysql.WithContext(ctx).Model(&Token{}).
+		Where("name = ? AND (token = '' OR expire_time <= ?)", key, now).Updates(values)
+	return opt.RowsAffected > 0, opt.Error
+}
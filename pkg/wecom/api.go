@@ -0,0 +1,143 @@
+package wecom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+/*
+企业微信自建应用：
+	BasicAPI 需提供corpid和应用secret，用于获取access_token，应由script的refresh:token统一刷新到redis
+	ServerAPI 需提供access_token和应用agentid，发送应用消息、员工网页登录、提交审批
+群机器人不需要token，见SendRobot
+文档：https://developer.work.weixin.qq.com/document/path/90664
+*/
+
+const host = "https://qyapi.weixin.qq.com"
+
+type BasicAPI interface { //需提供corpid和secret
+	GetToken(ctx context.Context) (*GetTokenResp, error)
+}
+
+type ServerAPI interface { //需提供access_token
+	SendMessage(ctx context.Context, msg *Message) (*SendMessageResp, error)
+	GetUserInfo(ctx context.Context, code string) (*UserInfoResp, error)
+	GetUser(ctx context.Context, userid string) (*UserResp, error)
+	ApplyEvent(ctx context.Context, args *ApplyEventArgs) (*ApplyEventResp, error)
+}
+
+type FullAPI interface {
+	BasicAPI
+	ServerAPI
+}
+
+type TokenFunc func(ctx context.Context) (string, error)
+
+type basic struct {
+	corpid string
+	secret string
+	client *http.Client
+}
+
+type server struct {
+	agentID int
+	client  *http.Client
+	token   TokenFunc
+}
+
+type full struct {
+	*basic
+	*server
+}
+
+func NewBasicAPI(corpid, secret string, client *http.Client) BasicAPI {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &basic{corpid: corpid, secret: secret, client: client}
+}
+
+// NewServerAPI agentID为发送应用消息时的默认应用
+func NewServerAPI(agentID int, client *http.Client, token TokenFunc) ServerAPI {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &server{agentID: agentID, client: client, token: token}
+}
+
+func NewFullAPI(corpid, secret string, agentID int, client *http.Client, token TokenFunc) FullAPI {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &full{
+		basic:  &basic{corpid: corpid, secret: secret, client: client},
+		server: &server{agentID: agentID, client: client, token: token},
+	}
+}
+
+type respErr struct {
+	Errcode int    `json:"errcode,omitempty"`
+	Errmsg  string `json:"errmsg,omitempty"`
+}
+
+type GetTokenResp struct {
+	respErr
+	AccessToken string `json:"access_token,omitempty"`
+	ExpiresIn   int64  `json:"expires_in,omitempty"`
+}
+
+// GetToken 有效期7200秒，重复获取不会使旧token失效，但有频率限制
+func (api *basic) GetToken(ctx context.Context) (*GetTokenResp, error) {
+	data := make(url.Values)
+	data.Set("corpid", api.corpid)
+	data.Set("corpsecret", api.secret)
+	var resp GetTokenResp
+	err := do(ctx, api.client, http.MethodGet, host+"/cgi-bin/gettoken?"+data.Encode(), nil, &resp)
+	return &resp, err
+}
+
+func (api *server) get(ctx context.Context, path string, data url.Values, result any) error {
+	tk, err := api.token(ctx)
+	if err != nil {
+		return err
+	}
+	data.Set("access_token", tk)
+	return do(ctx, api.client, http.MethodGet, host+path+"?"+data.Encode(), nil, result)
+}
+
+func (api *server) post(ctx context.Context, path string, data any, result any) error {
+	tk, err := api.token(ctx)
+	if err != nil {
+		return err
+	}
+	b, _ := json.Marshal(data)
+	return do(ctx, api.client, http.MethodPost, host+path+"?access_token="+tk, b, result)
+}
+
+func do(ctx context.Context, client *http.Client, method, u string, body []byte, result any) error {
+	var r io.Reader = http.NoBody
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, result)
+}
@@ -0,0 +1,46 @@
+package wecom
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// 审批：使用企业微信审批应用中配置的模板提交申请，审批结果通过应用的审批状态变更回调获取
+
+// ApplyEventArgs 审批申请，apply_data为模板控件的值，结构见文档
+type ApplyEventArgs struct {
+	CreatorUserID       string          `json:"creator_userid"`
+	TemplateID          string          `json:"template_id"`
+	UseTemplateApprover int             `json:"use_template_approver"` // 1使用模板中的审批流程
+	Approver            []*Approver     `json:"approver,omitempty"`
+	Notifyer            []string        `json:"notifyer,omitempty"`
+	NotifyType          int             `json:"notify_type,omitempty"`
+	ApplyData           json.RawMessage `json:"apply_data"`
+	SummaryList         []*Summary      `json:"summary_list"` // 1-3行摘要
+}
+
+type Approver struct {
+	Attr   int      `json:"attr"` // 1或签 2会签
+	UserID []string `json:"userid"`
+}
+
+type Summary struct {
+	SummaryInfo []*SummaryText `json:"summary_info"`
+}
+
+type SummaryText struct {
+	Text string `json:"text"`
+	Lang string `json:"lang"`
+}
+
+type ApplyEventResp struct {
+	respErr
+	SpNo string `json:"sp_no,omitempty"` // 审批单号
+}
+
+// ApplyEvent 需使用审批应用或已授权审批权限的自建应用的access_token
+func (api *server) ApplyEvent(ctx context.Context, args *ApplyEventArgs) (*ApplyEventResp, error) {
+	var resp ApplyEventResp
+	err := api.post(ctx, "/cgi-bin/oa/applyevent", args, &resp)
+	return &resp, err
+}
@@ -0,0 +1,72 @@
+package wecom
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"project/pkg/wechatwork"
+)
+
+const (
+	MsgText         = "text"
+	MsgMarkdown     = "markdown"
+	MsgTextCard     = "textcard"
+	MsgTemplateCard = "template_card"
+)
+
+// Message 应用消息，touser、toparty、totag至少一个，多个用|分隔，touser为@all时发送给应用可见范围内的全部成员
+type Message struct {
+	ToUser       string          `json:"touser,omitempty"`
+	ToParty      string          `json:"toparty,omitempty"`
+	ToTag        string          `json:"totag,omitempty"`
+	MsgType      string          `json:"msgtype"`
+	AgentID      int             `json:"agentid"` // 为0时使用创建客户端时的agentID
+	Text         *Text           `json:"text,omitempty"`
+	Markdown     *Text           `json:"markdown,omitempty"`
+	TextCard     *TextCard       `json:"textcard,omitempty"`
+	TemplateCard json.RawMessage `json:"template_card,omitempty"` // 按钮交互、投票等模板卡片，结构见文档
+}
+
+type Text struct {
+	Content string `json:"content"`
+}
+
+type TextCard struct {
+	Title       string `json:"title"`
+	Description string `json:"description"` // 支持div标签的gray、normal、highlight样式
+	URL         string `json:"url"`
+	BtnTxt      string `json:"btntxt,omitempty"`
+}
+
+type SendMessageResp struct {
+	respErr
+	InvalidUser  string `json:"invaliduser,omitempty"`
+	InvalidParty string `json:"invalidparty,omitempty"`
+	MsgID        string `json:"msgid,omitempty"`
+	ResponseCode string `json:"response_code,omitempty"` // 模板卡片更新时使用，72小时有效
+}
+
+// SendMessage 部分接收人无效时errcode仍为0，无效的接收人在invaliduser等字段返回
+func (api *server) SendMessage(ctx context.Context, msg *Message) (*SendMessageResp, error) {
+	if msg.AgentID == 0 {
+		msg.AgentID = api.agentID
+	}
+	var resp SendMessageResp
+	err := api.post(ctx, "/cgi-bin/message/send", msg, &resp)
+	return &resp, err
+}
+
+type RobotResp struct {
+	respErr
+}
+
+// SendRobot 群机器人消息，消息结构与wechatwork一致；每个机器人每分钟最多20条
+func SendRobot(ctx context.Context, client *http.Client, webhook string, args *wechatwork.Args) (*RobotResp, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	b, _ := json.Marshal(args)
+	var resp RobotResp
+	err := do(ctx, client, http.MethodPost, webhook, b, &resp)
+	return &resp, err
+}
@@ -0,0 +1,61 @@
+package wecom
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// 员工身份：企业微信内打开的网页使用AuthorizeURL静默授权，浏览器中使用QRConnectURL扫码登录，回调的code换取userid
+
+// AuthorizeURL 企业微信内打开的网页授权，scope为snsapi_base
+func AuthorizeURL(corpid, redirect, state string, agentID int) string {
+	return "https://open.weixin.qq.com/connect/oauth2/authorize?appid=" + corpid +
+		"&redirect_uri=" + url.QueryEscape(redirect) + "&response_type=code&scope=snsapi_base&state=" +
+		url.QueryEscape(state) + "&agentid=" + strconv.Itoa(agentID) + "#wechat_redirect"
+}
+
+// QRConnectURL 浏览器中扫码登录，redirect的域名需设置为应用的可信域名
+func QRConnectURL(corpid, redirect, state string, agentID int) string {
+	data := make(url.Values)
+	data.Set("login_type", "CorpApp")
+	data.Set("appid", corpid)
+	data.Set("agentid", strconv.Itoa(agentID))
+	data.Set("redirect_uri", redirect)
+	data.Set("state", state)
+	return "https://login.work.weixin.qq.com/wwlogin/sso/login?" + data.Encode()
+}
+
+type UserInfoResp struct {
+	respErr
+	UserID     string `json:"userid,omitempty"` // 企业成员，非成员时为空并返回openid
+	OpenID     string `json:"openid,omitempty"`
+	UserTicket string `json:"user_ticket,omitempty"`
+}
+
+// GetUserInfo code只能使用一次，5分钟内有效
+func (api *server) GetUserInfo(ctx context.Context, code string) (*UserInfoResp, error) {
+	data := make(url.Values)
+	data.Set("code", code)
+	var resp UserInfoResp
+	err := api.get(ctx, "/cgi-bin/auth/getuserinfo", data, &resp)
+	return &resp, err
+}
+
+type UserResp struct {
+	respErr
+	UserID     string `json:"userid,omitempty"`
+	Name       string `json:"name,omitempty"` // 第三方应用不返回
+	Department []int  `json:"department,omitempty"`
+	Position   string `json:"position,omitempty"`
+	Status     int    `json:"status,omitempty"` // 1已激活 2已禁用 4未激活 5退出企业
+}
+
+// GetUser 成员详情，应用需有该成员的可见范围
+func (api *server) GetUser(ctx context.Context, userid string) (*UserResp, error) {
+	data := make(url.Values)
+	data.Set("userid", userid)
+	var resp UserResp
+	err := api.get(ctx, "/cgi-bin/user/get", data, &resp)
+	return &resp, err
+}
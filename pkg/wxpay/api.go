@@ -0,0 +1,169 @@
+package wxpay
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+/*
+微信支付APIv3：
+请求使用商户私钥签名(WECHATPAY2-SHA256-RSA2048)，回调使用平台证书验签、APIv3密钥解密
+文档：https://pay.weixin.qq.com/wiki/doc/apiv3/wechatpay/wechatpay-1.shtml
+*/
+
+const (
+	host = "https://api.mch.weixin.qq.com"
+)
+
+type Config struct {
+	AppID        string // 下单使用的小程序/公众号/APP的appid
+	MchID        string // 商户号
+	SerialNo     string // 商户API证书序列号
+	PrivateKey   string // 商户API私钥(PEM)
+	APIv3Key     string // APIv3密钥
+	PlatformCert string // 微信支付平台证书(PEM)，用于回调验签
+}
+
+type API interface {
+	CreateTransaction(ctx context.Context, trade string, args *TransactionArgs) (*TransactionResp, error)
+	QueryTransaction(ctx context.Context, outTradeNo string) (*QueryTransactionResp, error)
+	JSAPIParams(prepayID string) (map[string]string, error)
+	CreateRefund(ctx context.Context, args *RefundArgs) (*RefundResp, error)
+	QueryRefund(ctx context.Context, outRefundNo string) (*RefundResp, error)
+	TradeBill(ctx context.Context, date time.Time) ([]*BillRow, error)
+	ParseNotify(req *http.Request, resource any) (*Notify, error)
+}
+
+type client struct {
+	appid    string
+	mchid    string
+	serialNo string
+	key      *rsa.PrivateKey
+	apiv3Key []byte
+	platform *x509.Certificate
+	client   *http.Client
+}
+
+func New(cfg *Config, httpClient *http.Client) (API, error) {
+	block, _ := pem.Decode([]byte(cfg.PrivateKey))
+	if block == nil {
+		return nil, errors.New("wxpay: invalid private key")
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("wxpay: private key is not rsa")
+	}
+	block, _ = pem.Decode([]byte(cfg.PlatformCert))
+	if block == nil {
+		return nil, errors.New("wxpay: invalid platform cert")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.APIv3Key) != 32 {
+		return nil, errors.New("wxpay: apiv3 key must be 32 bytes")
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &client{
+		appid:    cfg.AppID,
+		mchid:    cfg.MchID,
+		serialNo: cfg.SerialNo,
+		key:      key,
+		apiv3Key: []byte(cfg.APIv3Key),
+		platform: cert,
+		client:   httpClient,
+	}, nil
+}
+
+// APIError 微信支付返回的非2xx响应
+type APIError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("wxpay: %d %s %s", e.Status, e.Code, e.Message)
+}
+
+func (api *client) sign(method, uri string, body []byte) (string, error) {
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	nonceStr := hex.EncodeToString(nonce)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	msg := method + "\n" + uri + "\n" + ts + "\n" + nonceStr + "\n" + string(body) + "\n"
+	h := sha256.Sum256([]byte(msg))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, api.key, crypto.SHA256, h[:])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`WECHATPAY2-SHA256-RSA2048 mchid="%s",nonce_str="%s",signature="%s",timestamp="%s",serial_no="%s"`,
+		api.mchid, nonceStr, base64.StdEncoding.EncodeToString(sig), ts, api.serialNo), nil
+}
+
+// do 发送签名请求，uri为包含query的路径，result为nil时返回原始响应体
+func (api *client) do(ctx context.Context, method, uri string, data any, result any) ([]byte, error) {
+	var body []byte
+	if data != nil {
+		body, _ = json.Marshal(data)
+	}
+	url := uri
+	if uri[0] == '/' {
+		url = host + uri
+	} else if req, err := http.NewRequest(method, uri, http.NoBody); err == nil {
+		uri = req.URL.RequestURI() // 账单下载地址为完整URL，签名使用路径部分
+	}
+	auth, err := api.sign(method, uri, body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		e := &APIError{Status: resp.StatusCode}
+		_ = json.Unmarshal(b, e)
+		return nil, e
+	}
+	if result != nil {
+		err = json.Unmarshal(b, result)
+	}
+	return b, err
+}
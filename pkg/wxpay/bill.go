@@ -0,0 +1,109 @@
+package wxpay
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+交易账单：
+TradeBill 申请并下载指定日期的全部交易账单(次日9点后生成)，解析为明细行，
+支付成功和退款各为一行，金额单位转换为分
+*/
+
+var ErrBillHash = errors.New("wxpay: bill hash mismatch")
+
+type BillRow struct {
+	TradeTime     string
+	TransactionID string
+	OutTradeNo    string
+	Openid        string
+	TradeState    string // SUCCESS|REFUND|REVOKED
+	Total         int64  // 应结订单金额
+	RefundID      string
+	OutRefundNo   string
+	Refund        int64 // 退款金额
+	RefundStatus  string
+}
+
+func (api *client) TradeBill(ctx context.Context, date time.Time) ([]*BillRow, error) {
+	var apply struct {
+		HashType    string `json:"hash_type"`
+		HashValue   string `json:"hash_value"`
+		DownloadURL string `json:"download_url"`
+	}
+	q := url.Values{"bill_date": {date.Format("2006-01-02")}, "bill_type": {"ALL"}}
+	_, err := api.do(ctx, "GET", "/v3/bill/tradebill?"+q.Encode(), nil, &apply)
+	if err != nil {
+		return nil, err
+	}
+	b, err := api.do(ctx, "GET", apply.DownloadURL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(apply.HashType, "SHA1") {
+		h := sha1.Sum(b)
+		if hex.EncodeToString(h[:]) != strings.ToLower(apply.HashValue) {
+			return nil, ErrBillHash
+		}
+	}
+	return parseTradeBill(string(b))
+}
+
+// parseTradeBill 首行为表头，明细字段以`开头，遇到汇总表头"总交易单数"结束
+func parseTradeBill(s string) ([]*BillRow, error) {
+	r := csv.NewReader(strings.NewReader(s))
+	r.FieldsPerRecord = -1
+	if _, err := r.Read(); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	list := make([]*BillRow, 0)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) > 0 && strings.HasPrefix(rec[0], "总交易单数") {
+			break
+		}
+		if len(rec) < 20 {
+			continue
+		}
+		for i := range rec {
+			rec[i] = strings.TrimPrefix(strings.TrimSpace(rec[i]), "`")
+		}
+		list = append(list, &BillRow{
+			TradeTime:     rec[0],
+			TransactionID: rec[5],
+			OutTradeNo:    rec[6],
+			Openid:        rec[7],
+			TradeState:    rec[9],
+			Total:         yuanToFen(rec[12]),
+			RefundID:      rec[14],
+			OutRefundNo:   rec[15],
+			Refund:        yuanToFen(rec[16]),
+			RefundStatus:  rec[19],
+		})
+	}
+	return list, nil
+}
+
+func yuanToFen(s string) int64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return int64(math.Round(f * 100))
+}
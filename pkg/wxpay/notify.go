@@ -0,0 +1,126 @@
+package wxpay
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+/*
+回调通知：
+ParseNotify校验签名和时间戳(5分钟内)，解密resource到传入的结构体，
+支付成功为TransactionNotify，退款结果为RefundNotify
+*/
+
+const (
+	EventTransactionSuccess = "TRANSACTION.SUCCESS"
+	EventRefundSuccess      = "REFUND.SUCCESS"
+	EventRefundAbnormal     = "REFUND.ABNORMAL"
+	EventRefundClosed       = "REFUND.CLOSED"
+)
+
+var ErrSignature = errors.New("wxpay: invalid notify signature")
+
+type Notify struct {
+	ID           string `json:"id"`
+	CreateTime   string `json:"create_time"`
+	EventType    string `json:"event_type"`
+	ResourceType string `json:"resource_type"`
+	Summary      string `json:"summary"`
+	Resource     struct {
+		Algorithm      string `json:"algorithm"`
+		Ciphertext     string `json:"ciphertext"`
+		AssociatedData string `json:"associated_data"`
+		Nonce          string `json:"nonce"`
+	} `json:"resource"`
+}
+
+type TransactionNotify struct {
+	TransactionID string `json:"transaction_id"`
+	OutTradeNo    string `json:"out_trade_no"`
+	TradeState    string `json:"trade_state"`
+	SuccessTime   string `json:"success_time"`
+	Amount        struct {
+		Total      int64 `json:"total"`
+		PayerTotal int64 `json:"payer_total"`
+	} `json:"amount"`
+	Payer struct {
+		Openid string `json:"openid"`
+	} `json:"payer"`
+}
+
+type RefundNotify struct {
+	RefundID      string `json:"refund_id"`
+	OutRefundNo   string `json:"out_refund_no"`
+	TransactionID string `json:"transaction_id"`
+	OutTradeNo    string `json:"out_trade_no"`
+	RefundStatus  string `json:"refund_status"`
+	SuccessTime   string `json:"success_time"`
+	Amount        struct {
+		Refund int64 `json:"refund"`
+		Total  int64 `json:"total"`
+	} `json:"amount"`
+}
+
+func (api *client) ParseNotify(req *http.Request, resource any) (*Notify, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	ts := req.Header.Get("Wechatpay-Timestamp")
+	sec, _ := strconv.ParseInt(ts, 10, 64)
+	if d := time.Since(time.Unix(sec, 0)); d > 5*time.Minute || d < -5*time.Minute {
+		return nil, ErrSignature
+	}
+	sig, err := base64.StdEncoding.DecodeString(req.Header.Get("Wechatpay-Signature"))
+	if err != nil {
+		return nil, ErrSignature
+	}
+	msg := ts + "\n" + req.Header.Get("Wechatpay-Nonce") + "\n" + string(body) + "\n"
+	h := sha256.Sum256([]byte(msg))
+	pub, ok := api.platform.PublicKey.(*rsa.PublicKey)
+	if !ok || rsa.VerifyPKCS1v15(pub, crypto.SHA256, h[:], sig) != nil {
+		return nil, ErrSignature
+	}
+
+	var n Notify
+	if err = json.Unmarshal(body, &n); err != nil {
+		return nil, err
+	}
+	plain, err := api.decrypt(n.Resource.Ciphertext, n.Resource.Nonce, n.Resource.AssociatedData)
+	if err != nil {
+		return nil, err
+	}
+	if resource != nil {
+		err = json.Unmarshal(plain, resource)
+	}
+	return &n, err
+}
+
+// decrypt AEAD_AES_256_GCM解密
+func (api *client) decrypt(ciphertext, nonce, associated string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(api.apiv3Key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, []byte(nonce), b, []byte(associated))
+}
@@ -0,0 +1,62 @@
+package wxpay
+
+import (
+	"context"
+	"net/url"
+)
+
+/*
+退款：
+CreateRefund：申请退款，结果通过回调通知或QueryRefund查询
+QueryRefund：按商户退款单号查询
+*/
+
+const (
+	RefundSuccess    = "SUCCESS"
+	RefundClosed     = "CLOSED"
+	RefundProcessing = "PROCESSING"
+	RefundAbnormal   = "ABNORMAL"
+)
+
+type RefundArgs struct {
+	TransactionID string        `json:"transaction_id,omitempty"`
+	OutTradeNo    string        `json:"out_trade_no,omitempty"`
+	OutRefundNo   string        `json:"out_refund_no"`
+	Reason        string        `json:"reason,omitempty"`
+	NotifyURL     string        `json:"notify_url,omitempty"`
+	Amount        *RefundAmount `json:"amount"`
+}
+
+type RefundAmount struct {
+	Refund   int64  `json:"refund"` // 单位分
+	Total    int64  `json:"total"`
+	Currency string `json:"currency"`
+}
+
+type RefundResp struct {
+	RefundID      string `json:"refund_id"`
+	OutRefundNo   string `json:"out_refund_no"`
+	TransactionID string `json:"transaction_id"`
+	OutTradeNo    string `json:"out_trade_no"`
+	Status        string `json:"status"`
+	SuccessTime   string `json:"success_time,omitempty"`
+	Amount        struct {
+		Refund int64 `json:"refund"`
+		Total  int64 `json:"total"`
+	} `json:"amount"`
+}
+
+func (api *client) CreateRefund(ctx context.Context, args *RefundArgs) (*RefundResp, error) {
+	if args.Amount != nil && args.Amount.Currency == "" {
+		args.Amount.Currency = "CNY"
+	}
+	var resp RefundResp
+	_, err := api.do(ctx, "POST", "/v3/refund/domestic/refunds", args, &resp)
+	return &resp, err
+}
+
+func (api *client) QueryRefund(ctx context.Context, outRefundNo string) (*RefundResp, error) {
+	var resp RefundResp
+	_, err := api.do(ctx, "GET", "/v3/refund/domestic/refunds/"+url.PathEscape(outRefundNo), nil, &resp)
+	return &resp, err
+}
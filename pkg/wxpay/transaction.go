@@ -0,0 +1,109 @@
+package wxpay
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+/*
+下单和查询：
+CreateTransaction：JSAPI(小程序、公众号)、H5、APP下单，JSAPI需传openid
+QueryTransaction：按商户订单号查询
+JSAPIParams：生成小程序wx.requestPayment所需参数
+*/
+
+const (
+	TradeJSAPI = "jsapi"
+	TradeH5    = "h5"
+	TradeApp   = "app"
+)
+
+type TransactionArgs struct {
+	AppID       string             `json:"appid"`
+	MchID       string             `json:"mchid"`
+	Description string             `json:"description"`
+	OutTradeNo  string             `json:"out_trade_no"`
+	TimeExpire  string             `json:"time_expire,omitempty"` // RFC3339
+	NotifyURL   string             `json:"notify_url"`
+	Amount      *TransactionAmount `json:"amount"`
+	Payer       *TransactionPayer  `json:"payer,omitempty"`
+	SceneInfo   *TransactionScene  `json:"scene_info,omitempty"`
+}
+
+type TransactionAmount struct {
+	Total    int64  `json:"total"` // 单位分
+	Currency string `json:"currency"`
+}
+
+type TransactionPayer struct {
+	Openid string `json:"openid"`
+}
+
+type TransactionScene struct {
+	PayerClientIP string `json:"payer_client_ip"`
+	H5Info        *struct {
+		Type string `json:"type"`
+	} `json:"h5_info,omitempty"`
+}
+
+type TransactionResp struct {
+	PrepayID string `json:"prepay_id,omitempty"` // jsapi|app
+	H5URL    string `json:"h5_url,omitempty"`    // h5
+}
+
+func (api *client) CreateTransaction(ctx context.Context, trade string, args *TransactionArgs) (*TransactionResp, error) {
+	args.AppID = api.appid
+	args.MchID = api.mchid
+	if args.Amount != nil && args.Amount.Currency == "" {
+		args.Amount.Currency = "CNY"
+	}
+	if trade == TradeH5 && args.SceneInfo != nil && args.SceneInfo.H5Info == nil {
+		args.SceneInfo.H5Info = &struct {
+			Type string `json:"type"`
+		}{Type: "Wap"}
+	}
+	var resp TransactionResp
+	_, err := api.do(ctx, "POST", "/v3/pay/transactions/"+trade, args, &resp)
+	return &resp, err
+}
+
+type QueryTransactionResp struct {
+	TransactionNotify
+	TradeStateDesc string `json:"trade_state_desc"`
+}
+
+func (api *client) QueryTransaction(ctx context.Context, outTradeNo string) (*QueryTransactionResp, error) {
+	var resp QueryTransactionResp
+	uri := "/v3/pay/transactions/out-trade-no/" + url.PathEscape(outTradeNo) + "?mchid=" + api.mchid
+	_, err := api.do(ctx, "GET", uri, nil, &resp)
+	return &resp, err
+}
+
+// JSAPIParams 小程序调起支付的参数，paySign使用商户私钥签名
+func (api *client) JSAPIParams(prepayID string) (map[string]string, error) {
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	params := map[string]string{
+		"appId":     api.appid,
+		"timeStamp": strconv.FormatInt(time.Now().Unix(), 10),
+		"nonceStr":  hex.EncodeToString(nonce),
+		"package":   "prepay_id=" + prepayID,
+		"signType":  "RSA",
+	}
+	msg := params["appId"] + "\n" + params["timeStamp"] + "\n" + params["nonceStr"] + "\n" + params["package"] + "\n"
+	h := sha256.Sum256([]byte(msg))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, api.key, crypto.SHA256, h[:])
+	if err != nil {
+		return nil, err
+	}
+	params["paySign"] = base64.StdEncoding.EncodeToString(sig)
+	return params, nil
+}
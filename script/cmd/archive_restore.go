@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"log"
+	"project/pkg/coss"
+	"project/script/internal/service"
+	"time"
+)
+
+var archiveRestoreInto string
+
+var archiveRestoreCmd = &cobra.Command{
+	Use:   "archive:restore <table> <begin> <end>",
+	Short: "恢复归档的记录",
+	Long:  "下载时间范围[begin, end)内的归档文件，恢复到主库的单独表(默认为表名_restore)供审计查询，日期格式2006-01-02",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		if cfg.Cos.BucketURL == "" {
+			log.Fatal("cos not configured")
+		}
+		begin, err := time.ParseInLocation("2006-01-02", args[1], time.Local)
+		if err != nil {
+			log.Fatal("invalid begin: ", args[1])
+		}
+		end, err := time.ParseInLocation("2006-01-02", args[2], time.Local)
+		if err != nil {
+			log.Fatal("invalid end: ", args[2])
+		}
+		srv := service.NewService(service.NewMysql(&cfg.Mysql))
+		Ready(srv)
+		cos := coss.NewTCOS(cfg.Cos.BucketURL, cfg.Cos.ServiceURL, cfg.Cos.SecretID, cfg.Cos.SecretKey)
+		n, err := srv.NewArchiver(cos, cfg.Archive.Prefix).Restore(context.Background(), args[0], begin, end, archiveRestoreInto)
+		log.Printf("restored %d rows", n)
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	archiveRestoreCmd.Flags().StringVar(&archiveRestoreInto, "into", "", "恢复到的表，默认为表名_restore")
+	rootCmd.AddCommand(archiveRestoreCmd)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"project/model"
+	"project/pkg/mq"
+	"project/script/internal/handler"
+	"project/script/internal/service"
+)
+
+var authAuditCmd = &cobra.Command{
+	Use:   "auth:audit",
+	Short: "登录凭证审计",
+	Long:  "消费api投递的token签发、换发、吊销和登录失败事件，写入auth_event",
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := service.NewService(service.NewMysql(&cfg.Mysql), service.NewShards(&cfg.Shard))
+		Ready(srv)
+		h := handler.NewAuthAudit(srv)
+		c := mq.NewConsumer(cfg.Nsq.Consumer, cfg.Kafka, model.TopicAuth, "audit", 2, h.Handle)
+		Notify()
+		c.Stop()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authAuditCmd)
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log"
+	"os/signal"
+	"project/pkg/backup"
+	"project/pkg/coss"
+	"project/script/internal/handler"
+	"project/script/internal/service"
+	"syscall"
+	"time"
+)
+
+var (
+	backupVerify bool
+	backupUntil  string
+)
+
+// newBackup 失败时返回非0退出码，便于crontab或运维平台判断
+func newBackup(needStore bool) (*handler.Backup, context.Context, context.CancelFunc) {
+	var store coss.Store
+	if cfg.Cos.BucketURL != "" {
+		store = coss.NewTCOS(cfg.Cos.BucketURL, cfg.Cos.ServiceURL, cfg.Cos.SecretID, cfg.Cos.SecretKey)
+	} else if needStore {
+		log.Fatal("cos not configured")
+	}
+	srv := service.NewService(service.NewMysql(&cfg.Mysql))
+	Ready(srv)
+	b := backup.New(&cfg.Mysql, store, &cfg.Backup.Options)
+	h := handler.NewBackup(srv, b, &cfg.Backup, cfg.Mysql.Database, cfg.Robot.DingTalk, cfg.Robot.WechatWork)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	return h, ctx, stop
+}
+
+var backupDumpCmd = &cobra.Command{
+	Use:   "backup:dump",
+	Short: "数据库逻辑备份",
+	Long:  "mysqldump导出后上传到cos，记录binlog位置；--verify导出后立即验证可恢复",
+	Run: func(cmd *cobra.Command, args []string) {
+		h, ctx, stop := newBackup(true)
+		defer stop()
+		meta, err := h.Dump(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if backupVerify {
+			r, err := h.Verify(ctx, meta.Path)
+			if r != nil {
+				fmt.Println(r)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	},
+}
+
+var backupVerifyCmd = &cobra.Command{
+	Use:   "backup:verify [path]",
+	Short: "验证备份可恢复",
+	Long:  "下载备份导入到临时库，核对表和行数后删除临时库，默认验证最近一次备份",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		h, ctx, stop := newBackup(true)
+		defer stop()
+		var path string
+		if len(args) > 0 {
+			path = args[0]
+		}
+		r, err := h.Verify(ctx, path)
+		if r != nil {
+			fmt.Println(r)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "backup:restore <path|latest> <database>",
+	Short: "恢复备份到指定库",
+	Long:  "导入备份到database(不能是源库)，--until \"2006-01-02 15:04:05\"时继续重放binlog到该时间，恢复后核对数据再切换",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var until time.Time
+		if backupUntil != "" {
+			var err error
+			if until, err = time.ParseInLocation("2006-01-02 15:04:05", backupUntil, time.Local); err != nil {
+				log.Fatal("invalid until: ", backupUntil)
+			}
+		}
+		path := args[0]
+		if path == "latest" {
+			path = ""
+		}
+		h, ctx, stop := newBackup(true)
+		defer stop()
+		if err := h.Restore(ctx, path, args[1], until); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+var schemaDriftCmd = &cobra.Command{
+	Use:   "schema:drift",
+	Short: "检查表结构漂移",
+	Long:  "对比backup.schema中的建表语句与线上库的表、列和类型，不一致时告警",
+	Run: func(cmd *cobra.Command, args []string) {
+		h, ctx, stop := newBackup(false)
+		defer stop()
+		diff, err := h.Drift(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, v := range diff {
+			fmt.Println(v)
+		}
+		if len(diff) > 0 {
+			log.Fatalf("%d differences", len(diff))
+		}
+	},
+}
+
+func init() {
+	backupDumpCmd.Flags().BoolVar(&backupVerify, "verify", false, "导出后立即验证")
+	backupRestoreCmd.Flags().StringVar(&backupUntil, "until", "", "重放binlog到该时间(不含)")
+	rootCmd.AddCommand(backupDumpCmd, backupVerifyCmd, backupRestoreCmd, schemaDriftCmd)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"project/model"
+	"project/pkg/mq"
+	"project/script/internal/handler"
+	"project/script/internal/service"
+)
+
+var bookingExpireCmd = &cobra.Command{
+	Use:   "booking:expire",
+	Short: "关闭保留到期的预约",
+	Long:  "消费api预约时延迟投递的消息，到期仍未确认则关闭预约并释放名额",
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := service.NewService(service.NewMysql(&cfg.Mysql), service.NewRedis(&cfg.Redis))
+		Ready(srv)
+		h := handler.NewBooking(srv)
+		c := mq.NewConsumer(cfg.Nsq.Consumer, cfg.Kafka, model.TopicBookingExpire, "expire", 4, h.Expire)
+		Notify()
+		c.Stop()
+	},
+}
+
+var bookingRemindCmd = &cobra.Command{
+	Use:   "booking:remind",
+	Short: "预约开始前提醒",
+	Long:  "消费api确认预约时延迟投递的消息，预约仍有效时投递推送任务，由push:send发送",
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := service.NewService(service.NewMysql(&cfg.Mysql), service.NewProducer(cfg.Nsq.Producer, cfg.Kafka))
+		Ready(srv)
+		h := handler.NewBooking(srv)
+		c := mq.NewConsumer(cfg.Nsq.Consumer, cfg.Kafka, model.TopicBookingRemind, "remind", 4, h.Remind)
+		Notify()
+		c.Stop()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bookingExpireCmd)
+	rootCmd.AddCommand(bookingRemindCmd)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"project/script/internal/handler"
+	"project/script/internal/service"
+)
+
+var campaignCmd = &cobra.Command{
+	Use:   "campaign:run",
+	Short: "运营群发",
+	Long:  "到发送时间后按人群分批投递推送任务，按活动设置的速率限流，记录进度，取消后停止",
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := service.NewService(service.NewMysql(&cfg.Mysql), service.NewProducer(cfg.Nsq.Producer, cfg.Kafka))
+		Ready(srv)
+		h := handler.NewCampaign(srv)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			h.Run(ctx)
+			close(done)
+		}()
+		Notify()
+		cancel()
+		<-done
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(campaignCmd)
+}
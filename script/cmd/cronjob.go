@@ -4,8 +4,11 @@ import (
 	"github.com/robfig/cron/v3"
 	"github.com/spf13/cobra"
 	"log"
+	"project/model"
+	"project/pkg/coss"
 	"project/pkg/logger"
 	"project/pkg/wechat"
+	"project/pkg/wxpay"
 	"project/script/internal/handler"
 	"project/script/internal/service"
 	"time"
@@ -49,12 +52,31 @@ var cronjobCmd = &cobra.Command{
 	Short: "定时任务",
 	Long:  "精确定时执行的任务",
 	Run: func(cmd *cobra.Command, args []string) {
-		srv := service.NewService(service.NewMysql(&cfg.Mysql), service.NewRedis(&cfg.Redis))
+		srv := service.NewService(service.NewMysql(&cfg.Mysql), service.NewRedis(&cfg.Redis), service.NewShards(&cfg.Shard))
+		Ready(srv)
+		var pay wxpay.API
+		if cfg.Wxpay.MchID != "" {
+			var err error
+			if pay, err = wxpay.New(&cfg.Wxpay, logger.NewClient("wxpay", 60*time.Second)); err != nil {
+				log.Fatal(err)
+			}
+		}
+		var cos coss.TCOS
+		if cfg.Cos.BucketURL != "" {
+			cos = coss.NewTCOS(cfg.Cos.BucketURL, cfg.Cos.ServiceURL, cfg.Cos.SecretID, cfg.Cos.SecretKey)
+		}
 		h := handler.NewCronjob(
 			srv,
-			wechat.NewServerAPI(logger.NewHttpClient(30*time.Second), srv.GetWechatToken),
+			wechat.NewServerAPI(logger.NewClient("wechat", 30*time.Second), srv.TokenStore(), model.KeyWechatToken,
+				wechat.WithQuota(srv.WechatQuota(cfg.Wechat.Appid, cfg.Wechat.Quota))),
+			pay,
+			cos,
+			&cfg.Storage,
+			&cfg.Archive,
+			&cfg.Presence,
 			cfg.Robot.DingTalk,
 			cfg.Robot.WechatWork,
+			cfg.PurgeDays,
 		)
 
 		c := cron.New()
@@ -65,6 +87,71 @@ var cronjobCmd = &cobra.Command{
 			log.Fatal(err)
 		}
 
+		_, err = c.AddFunc("5 0 * * *", h.LoadWechatRetain) // 每天0点5分拉取近7日留存数据
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("10 0 * * *", h.LoadWechatDistribution) // 每天0点10分拉取昨日访问分布
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("0 10 * * *", h.ReconcileWechatPay) // 每天10点下载昨日微信支付账单对账
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("*/5 * * * *", h.RollupUsage) // 每5分钟回写接口调用量
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("2-59/10 * * * *", h.RollupStats) // 每10分钟汇总管理后台看板数据，错开调用量回写
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("30 3 * * *", h.PurgeSoftDeleted) // 每天3点30分清理过期的软删除记录
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("40 3 * * *", h.PurgeDedup) // 每天3点40分清理过期的消费去重记录
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("50 3 * * *", h.Archive) // 每天3点50分归档超过保留期的记录
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("20 * * * *", h.RefreshSegments) // 每小时20分重新计算用户分群
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("* * * * *", h.SweepPresence) // 每分钟清理心跳超时的在线用户
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("15 * * * *", h.RebuildStoreGeo) // 每小时15分重建门店的附近搜索索引
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("0 4 * * *", h.CheckLedger) // 每天4点校验积分余额账本
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, err = c.AddFunc("30 4 * * *", h.CleanStorage) // 每天4点30分隔离、删除未引用的对象存储文件
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		c.Start()
 		Notify()
 		ctx := c.Stop()
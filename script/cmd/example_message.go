@@ -5,17 +5,20 @@ import (
 	"project/pkg/mq"
 	"project/script/internal/handler"
 	"project/script/internal/service"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 var exampleMessageCmd = &cobra.Command{
 	Use:   "example:message",
-	Short: "nsq消费消息示例",
+	Short: "消息队列消费示例",
 	Run: func(cmd *cobra.Command, args []string) {
-		srv := service.NewService()
+		srv := service.NewService(service.NewRedis(&cfg.Redis))
+		Ready(srv)
 		h := handler.NewExampleMessage(srv)
-		c := mq.NewNsqConsumer(cfg.Nsq.Consumer, model.TopicExample, "default", 4, h.Handle)
+		dedup := mq.DedupHandler(srv.RedisDedup(time.Minute, 24*time.Hour), "example", nil, h.Handle) // 重新投递时跳过已处理的消息
+		c := mq.NewConsumer(cfg.Nsq.Consumer, cfg.Kafka, model.TopicExample, "default", 4, dedup)
 		Notify()
 		c.Stop()
 	},
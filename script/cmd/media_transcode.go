@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"log"
+	"project/pkg/coss"
+	"project/pkg/logger"
+	"project/pkg/transcode"
+	"project/script/internal/handler"
+	"project/script/internal/service"
+	"time"
+)
+
+var mediaTranscodeCmd = &cobra.Command{
+	Use:   "media:transcode",
+	Short: "视频转码",
+	Long:  "认领cms上传的视频，按media配置用ffmpeg或腾讯云媒体处理转为多码率HLS写回cos，记录播放列表和转码状态",
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := service.NewService(service.NewMysql(&cfg.Mysql))
+		Ready(srv)
+		cos := coss.NewTCOS(cfg.Cos.BucketURL, cfg.Cos.ServiceURL, cfg.Cos.SecretID, cfg.Cos.SecretKey)
+		t, err := transcode.New(&cfg.Media.Config, cos, logger.NewClient("transcode", 30*time.Second))
+		if err != nil {
+			log.Fatal("transcode.New error: ", err)
+		}
+		h := handler.NewMediaTranscode(srv, t, &cfg.Media)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			h.Run(ctx)
+			close(done)
+		}()
+		Notify()
+		cancel()
+		<-done
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mediaTranscodeCmd)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"project/script/internal/service"
+)
+
+var mqDelayCmd = &cobra.Command{
+	Use:   "mq:delay",
+	Short: "投递到期的延迟消息",
+	Long:  "扫描redis中到期的延迟消息投递到消息队列(kafka或nsq超过1小时的延迟)，可多实例运行，至少投递一次",
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := service.NewService(service.NewRedis(&cfg.Redis), service.NewProducer(cfg.Nsq.Producer, cfg.Kafka))
+		Ready(srv)
+		d := srv.NewDelayer(&cfg.Delay)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			d.Run(ctx)
+			close(done)
+		}()
+		Notify()
+		cancel()
+		<-done
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mqDelayCmd)
+}
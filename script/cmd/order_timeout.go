@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"project/model"
+	"project/pkg/mq"
+	"project/script/internal/handler"
+	"project/script/internal/service"
+)
+
+var orderTimeoutCmd = &cobra.Command{
+	Use:   "order:timeout",
+	Short: "关闭超时未支付订单",
+	Long:  "消费api发起支付时延迟投递的消息，到期仍未支付则关闭订单，退回优惠券和预扣的库存",
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := service.NewService(service.NewMysql(&cfg.Mysql), service.NewRedis(&cfg.Redis), service.NewProducer(cfg.Nsq.Producer, cfg.Kafka))
+		Ready(srv)
+		h := handler.NewOrderTimeout(srv)
+		c := mq.NewConsumer(cfg.Nsq.Consumer, cfg.Kafka, model.TopicOrderTimeout, "close", 4, h.Handle)
+		Notify()
+		c.Stop()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(orderTimeoutCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"log"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/mq"
+	"project/pkg/push"
+	"project/pkg/wechat"
+	"project/script/internal/handler"
+	"project/script/internal/service"
+	"time"
+)
+
+var pushSendCmd = &cobra.Command{
+	Use:   "push:send",
+	Short: "消息推送",
+	Long:  "消费api投递的推送任务，按用户偏好发送app推送或小程序订阅消息，记录发送结果并删除失效的token",
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := service.NewService(service.NewMysql(&cfg.Mysql), service.NewRedis(&cfg.Redis),
+			service.NewProducer(cfg.Nsq.Producer, cfg.Kafka))
+		Ready(srv)
+		client := logger.NewClient("push", 10*time.Second)
+		var senders []push.Sender
+		if cfg.Push.APNs != nil {
+			s, err := push.NewAPNs(cfg.Push.APNs, client)
+			if err != nil {
+				log.Fatal(err)
+			}
+			senders = append(senders, s)
+		}
+		if cfg.Push.FCM != nil {
+			s, err := push.NewFCM(cfg.Push.FCM, client)
+			if err != nil {
+				log.Fatal(err)
+			}
+			senders = append(senders, s)
+		}
+		h := handler.NewPushSend(
+			srv,
+			push.NewGateway(senders...),
+			wechat.NewServerAPI(logger.NewClient("wechat", 10*time.Second), srv.TokenStore(), model.KeyWechatToken,
+				wechat.WithQuota(srv.WechatQuota(cfg.Wechat.Appid, cfg.Wechat.Quota))),
+			srv.NewDelayer(&cfg.Delay), // 免打扰时段延迟投递，超过1小时需运行mq:delay
+			cfg.Push.MiniState,
+		)
+		dedup := mq.DedupHandler(srv.RedisDedup(time.Minute, 24*time.Hour), "push", nil, h.Handle)
+		c := mq.NewConsumer(cfg.Nsq.Consumer, cfg.Kafka, model.TopicPush, "send", 4, dedup)
+		Notify()
+		c.Stop()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pushSendCmd)
+}
@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"project/model"
+	"project/pkg/boot"
 	"project/pkg/logger"
 	"project/pkg/wechat"
+	"project/pkg/wecom"
 	"project/script/internal/handler"
 	"project/script/internal/service"
 	"time"
@@ -11,13 +14,27 @@ import (
 
 var refreshTokenCmd = &cobra.Command{
 	Use:   "refresh:token",
-	Short: "刷新小程序AccessToken",
+	Short: "刷新小程序AccessToken，配置公众号时同时刷新jsapi_ticket，配置企业微信时同时刷新其AccessToken",
 	Run: func(cmd *cobra.Command, args []string) {
 		srv := service.NewService(service.NewRedis(&cfg.Redis))
-		h := handler.NewRefreshToken(
-			srv,
-			wechat.NewBasicAPI(cfg.Wechat.Appid, cfg.Wechat.Secret, logger.NewHttpClient(30*time.Second)),
-		)
+		client := logger.NewClient("wechat", 30*time.Second)
+		var mp wechat.FullAPI
+		if cfg.Mp.Appid != "" {
+			mp = wechat.NewFullAPI(cfg.Mp.Appid, cfg.Mp.Secret, client, srv.TokenStore(), model.KeyMpToken)
+		}
+		var wc wecom.BasicAPI
+		if cfg.Wecom.CorpID != "" {
+			wc = wecom.NewBasicAPI(cfg.Wecom.CorpID, cfg.Wecom.Secret, logger.NewClient("wecom", 30*time.Second))
+		}
+		h := handler.NewRefreshToken(srv, wechat.NewBasicAPI(cfg.Wechat.Appid, cfg.Wechat.Secret, client), mp, wc)
+		checks := []boot.Check{{Name: "wechat", Fn: h.Refresh}}
+		if mp != nil {
+			checks = append(checks, boot.Check{Name: "mp", Fn: h.RefreshMp})
+		}
+		if wc != nil {
+			checks = append(checks, boot.Check{Name: "wecom", Fn: h.RefreshWecom})
+		}
+		Ready(srv, checks...) // 启动时立即刷新一次，同时校验appid、secret
 		stop := make(chan struct{})
 		done := make(chan struct{})
 		go func() {
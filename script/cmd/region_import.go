@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"project/pkg/region"
+	"project/script/internal/service"
+)
+
+var regionImportDryRun bool
+
+var regionImportCmd = &cobra.Command{
+	Use:   "region:import <file> <version>",
+	Short: "导入行政区划数据集",
+	Long:  "导入省市区三级的嵌套json [{code,name,children}]，与当前数据比较后写入新增、变更和撤销的代码并记录版本，api每分钟检查版本后重新加载",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args[1]) > 20 {
+			log.Fatal("version too long: ", args[1])
+		}
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		list, err := region.Parse(data)
+		if err != nil {
+			log.Fatal(err)
+		}
+		srv := service.NewService(service.NewMysql(&cfg.Mysql))
+		Ready(srv)
+		v, err := srv.ImportRegions(context.Background(), args[1], list, regionImportDryRun)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("version %s: total %d, added %d, changed %d, retired %d", v.Version, v.Total, v.Added, v.Changed, v.Retired)
+	},
+}
+
+func init() {
+	regionImportCmd.Flags().BoolVar(&regionImportDryRun, "dry-run", false, "只统计差异不写入")
+	rootCmd.AddCommand(regionImportCmd)
+}
@@ -6,9 +6,16 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"project/pkg/boot"
 	"project/pkg/cache"
 	"project/pkg/db"
 	"project/pkg/logger"
+	"project/pkg/mq"
+	"project/pkg/presence"
+	"project/pkg/push"
+	"project/pkg/wxpay"
+	"project/script/internal/handler"
+	"project/script/internal/service"
 	"syscall"
 )
 
@@ -25,22 +32,55 @@ var cfg struct {
 	App struct {
 		IsProd bool
 		Logger string
+		Boot   boot.Options           // 启动时依赖检查的重试次数、间隔
+		Http   logger.TransportConfig // 外部调用的连接池和超时
 	}
-	Cdn    string
-	Wechat struct {
+	Cdn       string
+	PurgeDays int // 软删除记录保留天数，0不清理
+	Wechat    struct {
 		Appid  string
 		Secret string
+		Quota  map[string]int64 // 接口每日额度，key为path，与api共享计数
 	}
-	Robot struct {
+	Mp struct { // 公众号，配置后refresh:token同时刷新H5页面JS-SDK使用的jsapi_ticket
+		Appid  string
+		Secret string
+	}
+	Wecom struct { // 企业微信自建应用，配置后refresh:token同时刷新其access_token
+		CorpID  string
+		Secret  string
+		AgentID int
+	}
+	Wxpay wxpay.Config // 微信支付APIv3，mchID为空不对账
+	Cos   struct {     // 腾讯云对象存储，bucketUrl为空不清理
+		BucketURL  string
+		ServiceURL string
+		SecretID   string
+		SecretKey  string
+	}
+	Storage handler.StorageConfig // 对象存储的孤儿文件清理
+	Archive handler.ArchiveConfig // 过期记录归档到对象存储
+	Backup  handler.BackupConfig  // 数据库备份、恢复验证和表结构检查
+	Media   handler.MediaConfig   // 视频转码
+	Robot   struct {
 		DingTalk   string
 		WechatWork string
 	}
 	Mysql db.Mysql
+	Shard db.ShardConfig // 按用户分片的表，与api的service.shard一致
 	Redis cache.Redis
 	Nsq   struct {
 		Producer string
 		Consumer string
 	}
+	Kafka    *mq.KafkaConfig  // 配置brokers后生产和消费都使用kafka，nsq不生效
+	Delay    mq.DelayOptions  // 延迟消息的扫描间隔、租约
+	Presence presence.Options // 在线状态的心跳超时，与api的service.presence一致
+	Push     struct {
+		APNs      *push.APNsConfig // 为空不发送ios推送
+		FCM       *push.FCMConfig  // 为空不发送android推送
+		MiniState string           // 订阅消息跳转的小程序版本：developer|trial|formal
+	}
 }
 
 func init() {
@@ -56,9 +96,17 @@ func init() {
 			log.Fatal("viper.Unmarshal error: ", err)
 		}
 		logger.SetOutput(cfg.App.Logger)
+		logger.SetTransport(&cfg.App.Http)
 	})
 }
 
+// Ready 检查命令使用的依赖，未就绪时汇总报错退出
+func Ready(srv *service.Service, checks ...boot.Check) {
+	if err := boot.Wait(&cfg.App.Boot, append(srv.Checks(), checks...)...); err != nil {
+		log.Fatal(err)
+	}
+}
+
 // Notify 阻塞主进程，监听退出信息
 func Notify() {
 	quit := make(chan os.Signal, 1)
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"log"
+	"os/signal"
+	"project/pkg/db"
+	"project/script/internal/service"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var shardMoveDryRun bool
+
+var shardMoveCmd = &cobra.Command{
+	Use:   "shard:move <slot|begin-end> <shard>",
+	Short: "迁移分片的slot",
+	Long:  "将slot及其用户的数据在线迁移到目标分片，迁移中的slot只读；中断后重新执行即可继续",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		begin, end, err := parseSlots(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		to, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Fatal("invalid shard: ", args[1])
+		}
+		srv := service.NewService(service.NewMysql(&cfg.Mysql), service.NewShards(&cfg.Shard))
+		Ready(srv)
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		if err = srv.MoveSlots(ctx, begin, end, to, &db.MoveOptions{DryRun: shardMoveDryRun}); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func parseSlots(s string) (int, int, error) {
+	a, b, ok := strings.Cut(s, "-")
+	begin, err := strconv.Atoi(a)
+	if err != nil || !ok {
+		return begin, begin, err
+	}
+	end, err := strconv.Atoi(b)
+	return begin, end, err
+}
+
+func init() {
+	shardMoveCmd.Flags().BoolVar(&shardMoveDryRun, "dry-run", false, "只统计各表待迁移的行数")
+	rootCmd.AddCommand(shardMoveCmd)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"project/model"
+	"project/pkg/mq"
+	"project/script/internal/handler"
+	"project/script/internal/service"
+)
+
+var stockWriteBackCmd = &cobra.Command{
+	Use:   "stock:writeback",
+	Short: "库存回写",
+	Long:  "消费api预扣库存的消息，按流水去重后回写到数据库",
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := service.NewService(service.NewMysql(&cfg.Mysql))
+		Ready(srv)
+		h := handler.NewStockWriteBack(srv)
+		c := mq.NewConsumer(cfg.Nsq.Consumer, cfg.Kafka, model.TopicStock, "writeback", 4, h.Handle)
+		Notify()
+		c.Stop()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stockWriteBackCmd)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"project/model"
+	"project/pkg/mq"
+	"project/script/internal/handler"
+	"project/script/internal/service"
+)
+
+var userMergeCmd = &cobra.Command{
+	Use:   "user:merge",
+	Short: "按unionid合并用户",
+	Long:  "消费api登录时发现的同一unionid的多个用户，转移订单、优惠券、资产等到保留用户，合并记录写入user_merge",
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := service.NewService(service.NewMysql(&cfg.Mysql), service.NewRedis(&cfg.Redis), service.NewShards(&cfg.Shard))
+		Ready(srv)
+		h := handler.NewUserMerge(srv)
+		c := mq.NewConsumer(cfg.Nsq.Consumer, cfg.Kafka, model.TopicUserMerge, "merge", 1, h.Handle)
+		Notify()
+		c.Stop()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(userMergeCmd)
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"project/pkg/archive"
+	"project/pkg/logger"
+	"project/pkg/util/random"
+)
+
+// 冷数据归档：按表的保留策略把过期的行导出到对象存储后删除，恢复使用archive:restore命令
+
+type ArchiveConfig struct {
+	Prefix   string            // 对象存储的路径前缀，默认archive/
+	Policies []*archive.Policy // 为空不归档
+}
+
+// Archive 依次执行各表的归档策略，单表失败不影响其他表，未完成的部分下次继续
+func (h *Cronjob) Archive() {
+	if h.cos == nil || h.archive == nil || len(h.archive.Policies) == 0 {
+		return
+	}
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "Archive", "")
+	a := h.service.NewArchiver(h.cos, h.archive.Prefix)
+	for _, p := range h.archive.Policies {
+		n, err := h.service.Archive(ctx, a, p)
+		l.Info("service.Archive", p.Table, n)
+		if err != nil {
+			l.Error("service.Archive error", p, err)
+		}
+	}
+}
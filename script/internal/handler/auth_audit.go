@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"encoding/json"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/mq"
+	"project/pkg/util/types"
+	"project/script/internal/service"
+)
+
+type AuthAudit struct {
+	service *service.Service
+}
+
+func NewAuthAudit(srv *service.Service) *AuthAudit {
+	return &AuthAudit{
+		service: srv,
+	}
+}
+
+// Handle 写入失败时重试，重复消息由event_id唯一索引忽略
+func (h *AuthAudit) Handle(msg *mq.Message) error {
+	ctx, l := logger.NewCtxLog(msg.TraceID(), "AuthAudit", "Handle", types.Int2Str(msg.Timestamp))
+	var data model.AuthEvent
+	if err := json.Unmarshal(msg.Body, &data); err != nil {
+		l.Error("json.Unmarshal error", msg.Body, err)
+		return nil
+	}
+	if err := h.service.SaveAuthEvent(ctx, &data); err != nil {
+		l.Error("service.SaveAuthEvent error", &data, err)
+		return err
+	}
+	return nil
+}
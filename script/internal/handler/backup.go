@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/pkg/backup"
+	"project/pkg/dingtalk"
+	"project/pkg/logger"
+	"project/pkg/util/random"
+	"project/pkg/wechatwork"
+	"project/script/internal/service"
+	"strings"
+	"time"
+)
+
+// 备份：导出上传、恢复验证、时间点恢复和表结构漂移检查，失败时发送机器人告警，由外部crontab或运维平台调度
+
+type BackupConfig struct {
+	backup.Options `mapstructure:",squash"`
+	Scratch        string   // 恢复验证使用的临时库，默认为库名_verify
+	Schema         []string // 建表语句文件，用于漂移检查
+}
+
+type Backup struct {
+	service     *service.Service
+	backup      *backup.Backup
+	cfg         *BackupConfig
+	database    string
+	robotDing   string
+	robotWechat string
+}
+
+func NewBackup(srv *service.Service, b *backup.Backup, cfg *BackupConfig, database, robotDing, robotWechat string) *Backup {
+	return &Backup{service: srv, backup: b, cfg: cfg, database: database, robotDing: robotDing, robotWechat: robotWechat}
+}
+
+// Dump 导出并上传，成功后可接着验证
+func (h *Backup) Dump(ctx context.Context) (*backup.Meta, error) {
+	_, l := logger.NewCtxLog(random.UUID(), "Backup", "Dump", "")
+	meta, err := h.backup.Dump(ctx)
+	if err != nil {
+		l.Error("backup.Dump error", h.database, err)
+		h.alert("数据库备份失败", err.Error())
+		return nil, err
+	}
+	l.Info("backup.Dump", meta.Path, meta.Size)
+	return meta, nil
+}
+
+// Verify 验证指定备份，path为空时验证最近一次
+func (h *Backup) Verify(ctx context.Context, path string) (*backup.Report, error) {
+	_, l := logger.NewCtxLog(random.UUID(), "Backup", "Verify", "")
+	meta, err := h.backup.Meta(ctx, path)
+	if err == nil {
+		scratch := h.cfg.Scratch
+		if scratch == "" {
+			scratch = h.database + "_verify"
+		}
+		var r *backup.Report
+		if r, err = h.service.VerifyBackup(ctx, h.backup, meta, scratch); err == nil {
+			l.Info("service.VerifyBackup", meta.Path, r.String())
+			if r.Failed() {
+				h.alert("数据库备份验证失败", r.String())
+				return r, errors.New("backup verify failed")
+			}
+			if time.Since(meta.EndTime) > 36*time.Hour {
+				h.alert("数据库备份过旧", fmt.Sprintf("%s 完成于%s", meta.Path, meta.EndTime.Format("2006-01-02 15:04")))
+			}
+			return r, nil
+		}
+	}
+	l.Error("service.VerifyBackup error", path, err)
+	h.alert("数据库备份验证失败", err.Error())
+	return nil, err
+}
+
+// Restore 导入备份到database，until不为零时继续重放binlog到该时间
+func (h *Backup) Restore(ctx context.Context, path, database string, until time.Time) error {
+	meta, err := h.backup.Meta(ctx, path)
+	if err != nil {
+		return err
+	}
+	if err = h.backup.Load(ctx, meta, database); err != nil {
+		return err
+	}
+	if until.IsZero() {
+		return nil
+	}
+	return h.backup.Replay(ctx, meta, database, until)
+}
+
+// Drift 表结构与建表语句不一致时告警
+func (h *Backup) Drift(ctx context.Context) ([]string, error) {
+	_, l := logger.NewCtxLog(random.UUID(), "Backup", "Drift", "")
+	diff, err := h.service.SchemaDrift(ctx, h.cfg.Schema)
+	if err != nil {
+		l.Error("service.SchemaDrift error", h.cfg.Schema, err)
+		h.alert("表结构检查失败", err.Error())
+		return nil, err
+	}
+	if len(diff) > 0 {
+		l.Warn("service.SchemaDrift", h.database, diff)
+		h.alert(fmt.Sprintf("表结构与design/sql不一致(%d处)", len(diff)), strings.Join(diff, "\n"))
+	}
+	return diff, nil
+}
+
+func (h *Backup) alert(title, detail string) {
+	if len(detail) > 2000 {
+		detail = detail[:2000] + "\n..."
+	}
+	content := title + "[" + h.database + "]:\n" + detail
+	_, _ = wechatwork.SendText(h.robotWechat, &wechatwork.Text{Content: content})
+	_, _ = dingtalk.SendText(h.robotDing, &dingtalk.Text{Content: content}, nil)
+}
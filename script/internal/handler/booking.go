@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/mq"
+	"project/pkg/util/types"
+	"project/script/internal/service"
+)
+
+type Booking struct {
+	service *service.Service
+}
+
+func NewBooking(srv *service.Service) *Booking {
+	return &Booking{
+		service: srv,
+	}
+}
+
+// Expire 保留到期的预约，已确认或已取消的忽略
+func (h *Booking) Expire(msg *mq.Message) error {
+	ctx, l := logger.NewCtxLog(msg.ID, "Booking", "Expire", types.Int2Str(msg.Timestamp))
+	var data model.MsgBooking
+	if err := json.Unmarshal(msg.Body, &data); err != nil {
+		l.Error("json.Unmarshal error", msg.Body, err)
+		return nil
+	}
+	ok, err := h.service.ExpireBooking(ctx, data.BookingID)
+	if err != nil {
+		l.Error("service.ExpireBooking error", data.BookingID, err)
+		return err
+	}
+	if ok {
+		l.Info("service.ExpireBooking expired", data.BookingID, nil)
+	}
+	return nil
+}
+
+// Remind 开始前提醒，预约已取消或改期(slot_start不一致)时不发送
+func (h *Booking) Remind(msg *mq.Message) error {
+	ctx, l := logger.NewCtxLog(msg.ID, "Booking", "Remind", types.Int2Str(msg.Timestamp))
+	var data model.MsgBooking
+	if err := json.Unmarshal(msg.Body, &data); err != nil {
+		l.Error("json.Unmarshal error", msg.Body, err)
+		return nil
+	}
+	ok, err := h.service.RemindBooking(ctx, data.BookingID, data.SlotStart)
+	if err != nil {
+		l.Error("service.RemindBooking error", &data, err)
+		return err
+	}
+	if ok {
+		l.Info("service.RemindBooking sent", &data, nil)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"project/model"
+	"project/pkg/logger"
+	"project/script/internal/service"
+	"time"
+)
+
+type Campaign struct {
+	service *service.Service
+}
+
+func NewCampaign(srv *service.Service) *Campaign {
+	return &Campaign{
+		service: srv,
+	}
+}
+
+// Run 每10秒检查到期的活动，逐个发送；中断的活动超过1分钟未更新后由任一实例从cursor继续
+func (h *Campaign) Run(ctx context.Context) {
+	tick := time.NewTicker(10 * time.Second)
+	defer tick.Stop()
+	for {
+		list, err := h.service.FindDueCampaigns(ctx, time.Minute)
+		if err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Error("service.FindDueCampaigns error", nil, err)
+		}
+		for _, c := range list {
+			if ctx.Err() != nil {
+				return
+			}
+			ok, err := h.service.ClaimCampaign(ctx, c)
+			if err != nil {
+				logger.FromContext(ctx).Error("service.ClaimCampaign error", c.ID, err)
+				continue
+			}
+			if ok {
+				h.send(ctx, c)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// send 每秒投递rate条，每批后记录进度并检查是否已取消；进程在投递后、记录前退出时该批会重复投递
+func (h *Campaign) send(ctx context.Context, c *model.Campaign) {
+	l := logger.FromContext(ctx)
+	if c.Cursor == 0 {
+		total, err := h.service.CountCampaignAudience(ctx, c)
+		if err != nil {
+			l.Error("service.CountCampaignAudience error", c.ID, err)
+			return
+		}
+		c.Total = total
+		if _, err = h.service.UpdateCampaignProgress(ctx, c, map[string]any{"total": total}); err != nil {
+			l.Error("service.UpdateCampaignProgress error", c.ID, err)
+			return
+		}
+	}
+	l.Info("campaign start", c.ID, c.Total)
+	for ctx.Err() == nil {
+		start := time.Now()
+		uids, err := h.service.NextCampaignAudience(ctx, c, c.Rate)
+		if err != nil {
+			l.Error("service.NextCampaignAudience error", c.ID, err)
+			return
+		}
+		if len(uids) == 0 {
+			if _, err = h.service.UpdateCampaignProgress(ctx, c, map[string]any{"status": model.CampaignDone}); err != nil {
+				l.Error("service.UpdateCampaignProgress error", c.ID, err)
+			}
+			l.Info("campaign done", c.ID, c.Sent)
+			return
+		}
+		if err = h.service.PublishCampaign(c, uids); err != nil {
+			l.Error("service.PublishCampaign error", c.ID, err)
+			return
+		}
+		c.Cursor = uids[len(uids)-1]
+		c.Sent += int64(len(uids))
+		ok, err := h.service.UpdateCampaignProgress(ctx, c, map[string]any{"cursor": c.Cursor, "sent": c.Sent})
+		if err != nil {
+			l.Error("service.UpdateCampaignProgress error", c.ID, err)
+			return
+		}
+		if !ok {
+			l.Info("campaign canceled", c.ID, c.Sent)
+			return
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second - time.Since(start)):
+		}
+	}
+}
@@ -2,11 +2,14 @@ package handler
 
 import (
 	"project/model"
+	"project/pkg/coss"
 	"project/pkg/dingtalk"
 	"project/pkg/logger"
+	"project/pkg/presence"
 	"project/pkg/util/random"
 	"project/pkg/wechat"
 	"project/pkg/wechatwork"
+	"project/pkg/wxpay"
 	"project/script/internal/service"
 	"strconv"
 	"strings"
@@ -18,14 +21,31 @@ type Cronjob struct {
 	wechat      wechat.ServerAPI
 	robotDing   string
 	robotWechat string
+	purgeDays   int
+	wxpay       wxpay.API
+	cos         coss.TCOS
+	storage     *StorageConfig
+	archive     *ArchiveConfig
+	presence    *presence.Options
 }
 
-func NewCronjob(srv *service.Service, api wechat.ServerAPI, robotDing, robotWechat string) *Cronjob {
+// NewCronjob pay、cos为空时不执行对账、对象存储清理和归档
+func NewCronjob(srv *service.Service, api wechat.ServerAPI, pay wxpay.API, cos coss.TCOS, storage *StorageConfig,
+	archive *ArchiveConfig, presence *presence.Options, robotDing, robotWechat string, purgeDays int) *Cronjob {
+	if storage != nil {
+		storage.init()
+	}
 	return &Cronjob{
 		service:     srv,
 		wechat:      api,
+		wxpay:       pay,
 		robotDing:   robotDing,
 		robotWechat: robotWechat,
+		purgeDays:   purgeDays,
+		cos:         cos,
+		storage:     storage,
+		archive:     archive,
+		presence:    presence,
 	}
 }
 
@@ -38,27 +58,27 @@ func (h *Cronjob) LoadWechatAnalysis() {
 	}
 
 	trend, err := h.wechat.GetDailyVisitTrend(ctx, args)
-	if err != nil {
+	if err != nil && wechat.Code(err) == 0 { // 错误码不重试
 		trend, err = h.wechat.GetDailyVisitTrend(ctx, args)
 	}
 	if err != nil {
 		l.Error("wechat.GetDailyVisitTrend error", args, err)
 		return
 	}
-	if trend.Errcode != 0 || len(trend.List) == 0 {
+	if len(trend.List) == 0 {
 		l.Warn("wechat.GetDailyVisitTrend fail", args, trend)
 		return
 	}
 
 	summary, err := h.wechat.GetDailySummary(ctx, args)
-	if err != nil {
+	if err != nil && wechat.Code(err) == 0 { // 错误码不重试
 		summary, err = h.wechat.GetDailySummary(ctx, args)
 	}
 	if err != nil {
 		l.Error("wechat.GetDailySummary error", args, err)
 		return
 	}
-	if summary.Errcode != 0 || len(summary.List) == 0 {
+	if len(summary.List) == 0 {
 		l.Warn("wechat.GetDailySummary fail", args, summary)
 		return
 	}
@@ -79,6 +99,82 @@ func (h *Cronjob) LoadWechatAnalysis() {
 	h.notifyWechatAnalysis(summary.List[0].VisitTotal, data)
 }
 
+// LoadWechatRetain 拉取近7日的留存数据，留存随时间补全，已存在的日期覆盖更新
+func (h *Cronjob) LoadWechatRetain() {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "LoadWechatRetain", "")
+	for i := 1; i <= 7; i++ {
+		day := time.Now().AddDate(0, 0, -i).Format(wechat.DateFormat)
+		args := &wechat.DatacubeArgs{
+			BeginDate: day,
+			EndDate:   day,
+		}
+		resp, err := h.wechat.GetDailyRetain(ctx, args)
+		if err != nil && wechat.Code(err) == 0 { // 错误码不重试
+			resp, err = h.wechat.GetDailyRetain(ctx, args)
+		}
+		if err != nil {
+			l.Error("wechat.GetDailyRetain error", args, err)
+			return
+		}
+		retain := make(map[int]*model.WechatRetain, len(resp.VisitUv))
+		for _, v := range resp.VisitUvNew {
+			retain[v.Key] = &model.WechatRetain{RefDate: day, Day: v.Key, VisitUvNew: v.Value}
+		}
+		for _, v := range resp.VisitUv {
+			if r, ok := retain[v.Key]; ok {
+				r.VisitUv = v.Value
+			} else {
+				retain[v.Key] = &model.WechatRetain{RefDate: day, Day: v.Key, VisitUv: v.Value}
+			}
+		}
+		if len(retain) == 0 {
+			continue
+		}
+		list := make([]*model.WechatRetain, 0, len(retain))
+		for _, v := range retain {
+			list = append(list, v)
+		}
+		if err = h.service.SaveWechatRetain(ctx, list); err != nil {
+			l.Error("service.SaveWechatRetain error", day, err)
+		}
+	}
+}
+
+// LoadWechatDistribution 拉取昨日访问分布
+func (h *Cronjob) LoadWechatDistribution() {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "LoadWechatDistribution", "")
+	yesterday := time.Now().AddDate(0, 0, -1).Format(wechat.DateFormat)
+	args := &wechat.DatacubeArgs{
+		BeginDate: yesterday,
+		EndDate:   yesterday,
+	}
+	resp, err := h.wechat.GetVisitDistribution(ctx, args)
+	if err != nil && wechat.Code(err) == 0 { // 错误码不重试
+		resp, err = h.wechat.GetVisitDistribution(ctx, args)
+	}
+	if err != nil {
+		l.Error("wechat.GetVisitDistribution error", args, err)
+		return
+	}
+	list := make([]*model.WechatDistribution, 0)
+	for _, d := range resp.List {
+		for _, v := range d.ItemList {
+			list = append(list, &model.WechatDistribution{
+				RefDate: yesterday,
+				Index:   d.Index,
+				Key:     v.Key,
+				Value:   v.Value,
+			})
+		}
+	}
+	if len(list) == 0 {
+		return
+	}
+	if err = h.service.SaveWechatDistribution(ctx, list); err != nil {
+		l.Error("service.SaveWechatDistribution error", yesterday, err)
+	}
+}
+
 func (h *Cronjob) notifyWechatAnalysis(total int, data *model.WechatAnalysis) {
 	text := &strings.Builder{}
 	text.WriteString(data.RefDate)
@@ -101,3 +197,77 @@ func (h *Cronjob) notifyWechatAnalysis(total int, data *model.WechatAnalysis) {
 	_, _ = wechatwork.SendText(h.robotWechat, &wechatwork.Text{Content: content})
 	_, _ = dingtalk.SendText(h.robotDing, &dingtalk.Text{Content: content}, nil)
 }
+
+// PurgeDedup 清理过期的数据库消费去重记录
+func (h *Cronjob) PurgeDedup() {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "PurgeDedup", "")
+	n, err := h.service.PurgeDedup(ctx)
+	if err != nil {
+		l.Error("service.PurgeDedup error", nil, err)
+		return
+	}
+	l.Info("service.PurgeDedup", nil, n)
+}
+
+// SweepPresence 心跳超时的用户离线并记录最后在线时间
+func (h *Cronjob) SweepPresence() {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "SweepPresence", "")
+	n, err := h.service.SweepPresence(ctx, h.presence, 1000)
+	if err != nil {
+		l.Error("service.SweepPresence error", n, err)
+		return
+	}
+	if n > 0 {
+		l.Info("service.SweepPresence", nil, n)
+	}
+}
+
+// RebuildStoreGeo 重建门店的附近搜索索引，重建期间cms修改的坐标可能被覆盖，下次重建后一致
+func (h *Cronjob) RebuildStoreGeo() {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "RebuildStoreGeo", "")
+	n, err := h.service.RebuildStoreGeo(ctx)
+	if err != nil {
+		l.Error("service.RebuildStoreGeo error", nil, err)
+		return
+	}
+	l.Info("service.RebuildStoreGeo", nil, n)
+}
+
+// RefreshSegments 重新计算启用中的用户分群，单个失败不影响其他分群
+func (h *Cronjob) RefreshSegments() {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "RefreshSegments", "")
+	list, err := h.service.FindActiveSegments(ctx)
+	if err != nil {
+		l.Error("service.FindActiveSegments error", nil, err)
+		return
+	}
+	for _, seg := range list {
+		n, err := h.service.RefreshSegment(ctx, seg)
+		if err != nil {
+			l.Error("service.RefreshSegment error", seg.Code, err)
+			continue
+		}
+		l.Info("service.RefreshSegment", seg.Code, n)
+	}
+}
+
+// PurgeSoftDeleted 清理超过保留天数的软删除记录，保留天数不少于model.TombstoneDays
+func (h *Cronjob) PurgeSoftDeleted() {
+	if h.purgeDays <= 0 {
+		return
+	}
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "PurgeSoftDeleted", "")
+	days := h.purgeDays
+	if days < model.TombstoneDays {
+		days = model.TombstoneDays
+	}
+	before := time.Now().AddDate(0, 0, -days)
+	for table := range model.SoftDeleteTables {
+		n, err := h.service.PurgeSoftDeleted(ctx, table, before, 1000)
+		if err != nil {
+			l.Error("service.PurgeSoftDeleted error", table, err)
+			continue
+		}
+		l.Info("service.PurgeSoftDeleted", table, n)
+	}
+}
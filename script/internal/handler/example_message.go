@@ -2,9 +2,9 @@ package handler
 
 import (
 	"encoding/json"
-	"github.com/nsqio/go-nsq"
 	"project/model"
 	"project/pkg/logger"
+	"project/pkg/mq"
 	"project/pkg/util/types"
 	"project/script/internal/service"
 )
@@ -19,8 +19,8 @@ func NewExampleMessage(srv *service.Service) *ExampleMessage {
 	}
 }
 
-func (h *ExampleMessage) Handle(msg *nsq.Message) error {
-	reqid := string(msg.ID[:])
+func (h *ExampleMessage) Handle(msg *mq.Message) error {
+	reqid := msg.ID
 	var data model.MsgExample
 	_ = json.Unmarshal(msg.Body, &data)
 	_, l := logger.NewCtxLog(reqid, "Message", "Handle", types.Int2Str(msg.Timestamp))
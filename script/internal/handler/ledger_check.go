@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"fmt"
+	"project/pkg/logger"
+	"project/pkg/util/random"
+	"project/script/internal/service"
+	"time"
+)
+
+// CheckLedger 校验记账不变量：近2日分录借贷平衡、账户余额等于分录汇总和最后一条余额快照，有差异时发送告警
+func (h *Cronjob) CheckLedger() {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "CheckLedger", "")
+	diff := make([]string, 0)
+	refs, err := h.service.FindUnbalancedLedger(ctx, time.Now().AddDate(0, 0, -2))
+	if err != nil {
+		l.Error("service.FindUnbalancedLedger error", nil, err)
+		return
+	}
+	for _, v := range refs {
+		diff = append(diff, "分录不平 "+v)
+	}
+	batches, lastID := 0, 0
+	for {
+		var drift []*service.LedgerDrift
+		drift, lastID, err = h.service.FindLedgerDrift(ctx, lastID, 500)
+		if err != nil {
+			l.Error("service.FindLedgerDrift error", lastID, err)
+			return
+		}
+		if lastID == 0 {
+			break
+		}
+		batches++
+		for _, v := range drift {
+			diff = append(diff, fmt.Sprintf("用户%d %s 余额%d 分录汇总%d 快照%d",
+				v.UserID, v.Asset, v.Balance, v.Total, v.Snapshot))
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	l.Info("CheckLedger", nil, map[string]int{
+		"batches": batches,
+		"diff":    len(diff),
+	})
+	if len(diff) > 0 {
+		h.notifyDiff(time.Now().Format("2006-01-02")+" 积分余额账本校验差异", diff)
+	}
+}
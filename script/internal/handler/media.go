@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/transcode"
+	"project/pkg/util/random"
+	"project/script/internal/service"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MediaConfig struct {
+	transcode.Config `mapstructure:",squash"`
+	Workers          int // 同时转码的视频数，默认1
+	Attempts         int // 最多尝试次数，默认3，源文件问题(transcode.Failed)不重试
+}
+
+type MediaTranscode struct {
+	service    *service.Service
+	transcoder transcode.Transcoder
+	workers    int
+	attempts   int
+}
+
+func NewMediaTranscode(srv *service.Service, t transcode.Transcoder, cfg *MediaConfig) *MediaTranscode {
+	h := &MediaTranscode{
+		service:    srv,
+		transcoder: t,
+		workers:    cfg.Workers,
+		attempts:   cfg.Attempts,
+	}
+	if h.workers <= 0 {
+		h.workers = 1
+	}
+	if h.attempts <= 0 {
+		h.attempts = 3
+	}
+	return h
+}
+
+// mediaIdle 转码中的记录超过该时间未更新视为进程已退出，心跳间隔为其1/3
+const mediaIdle = time.Minute
+
+// Run 每10秒认领待转码的视频，最多同时转码workers个；退出时等待转码中的视频中止，
+// 记录保持转码中，超过mediaIdle后由任一实例重新认领(云端任务按task_id继续查询)
+func (h *MediaTranscode) Run(ctx context.Context) {
+	tick := time.NewTicker(10 * time.Second)
+	defer tick.Stop()
+	sem := make(chan struct{}, h.workers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		if free := h.workers - len(sem); free > 0 {
+			list, err := h.service.FindDueMedia(ctx, mediaIdle, free)
+			if err != nil && ctx.Err() == nil {
+				logger.FromContext(ctx).Error("service.FindDueMedia error", nil, err)
+			}
+			for _, m := range list {
+				ok, err := h.service.ClaimMedia(ctx, m)
+				if err != nil {
+					logger.FromContext(ctx).Error("service.ClaimMedia error", m.ID, err)
+					continue
+				}
+				if !ok {
+					continue
+				}
+				sem <- struct{}{}
+				wg.Add(1)
+				go func(m *model.Media) {
+					defer func() {
+						<-sem
+						wg.Done()
+					}()
+					h.transcode(ctx, m)
+				}(m)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// transcode 转码期间定期更新心跳，被其他实例重新认领时中止
+func (h *MediaTranscode) transcode(ctx context.Context, m *model.Media) {
+	_, l := logger.NewCtxLog(random.UUID(), "MediaTranscode", "Transcode", strconv.Itoa(m.ID))
+	var mu sync.Mutex
+	update := func(fields map[string]any) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		ok, err := h.service.UpdateMedia(ctx, m, fields)
+		if err != nil {
+			l.Error("service.UpdateMedia error", fields, err)
+		}
+		return ok || err != nil // db异常时继续转码
+	}
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		tick := time.NewTicker(mediaIdle / 3)
+		defer tick.Stop()
+		for {
+			select {
+			case <-jobCtx.Done():
+				return
+			case <-tick.C:
+				if !update(nil) {
+					l.Info("media reclaimed by another worker", m.ID, nil)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	l.Info("media transcode start", m.ID, m.Attempts)
+	res, err := h.transcoder.Transcode(jobCtx, &transcode.Job{
+		Source: m.Source,
+		Output: model.MediaHLSPrefix + strconv.Itoa(m.ID) + "/",
+		TaskID: m.TaskID,
+	}, func(taskID string) {
+		update(map[string]any{"task_id": taskID})
+	})
+	if err != nil && jobCtx.Err() != nil { // 进程退出或被重新认领
+		return
+	}
+	cancel()
+	if err != nil {
+		var f *transcode.Failed
+		status := model.MediaPending
+		if errors.As(err, &f) || m.Attempts >= h.attempts {
+			status = model.MediaFailed
+		}
+		msg := err.Error()
+		if len(msg) > 1024 {
+			msg = msg[:1024]
+		}
+		l.Error("transcoder.Transcode error", m.ID, err)
+		update(map[string]any{"status": status, "error": msg})
+		return
+	}
+	update(map[string]any{
+		"status":     model.MediaReady,
+		"playlist":   res.Playlist,
+		"duration":   res.Duration,
+		"renditions": strings.Join(res.Renditions, ","),
+		"error":      "",
+	})
+	l.Info("media transcode done", m.ID, res)
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"encoding/json"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/mq"
+	"project/pkg/util/types"
+	"project/script/internal/service"
+)
+
+type OrderTimeout struct {
+	service *service.Service
+}
+
+func NewOrderTimeout(srv *service.Service) *OrderTimeout {
+	return &OrderTimeout{
+		service: srv,
+	}
+}
+
+// Handle 消息可能重复或提前到达(重复发起支付)，按订单状态和创建时间判断，未关闭的不重试
+func (h *OrderTimeout) Handle(msg *mq.Message) error {
+	ctx, l := logger.NewCtxLog(msg.ID, "OrderTimeout", "Handle", types.Int2Str(msg.Timestamp))
+	var data model.MsgOrderTimeout
+	if err := json.Unmarshal(msg.Body, &data); err != nil {
+		l.Error("json.Unmarshal error", msg.Body, err)
+		return nil
+	}
+	ok, err := h.service.CloseTimeoutOrder(ctx, data.OrderNo)
+	if err != nil {
+		l.Error("service.CloseTimeoutOrder error", data.OrderNo, err)
+		return err
+	}
+	if ok {
+		l.Info("service.CloseTimeoutOrder closed", data.OrderNo, nil)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/mq"
+	"project/pkg/push"
+	"project/pkg/util/types"
+	"project/pkg/wechat"
+	"project/script/internal/service"
+	"strconv"
+	"time"
+)
+
+type PushSend struct {
+	service   *service.Service
+	gateway   *push.Gateway
+	wechat    wechat.ServerAPI
+	delayer   *mq.Delayer
+	miniState string
+}
+
+func NewPushSend(srv *service.Service, gateway *push.Gateway, api wechat.ServerAPI, delayer *mq.Delayer, miniState string) *PushSend {
+	return &PushSend{
+		service:   srv,
+		gateway:   gateway,
+		wechat:    api,
+		delayer:   delayer,
+		miniState: miniState,
+	}
+}
+
+// Handle 按偏好选择渠道：app发送到全部设备，auto在没有设备发送成功时改发订阅消息；关闭的分类和渠道跳过，
+// 免打扰时段内延迟到结束后重新投递；开始发送后不再返回错误，避免重新投递造成重复推送，失败记录在push_delivery
+func (h *PushSend) Handle(msg *mq.Message) error {
+	ctx, l := logger.NewCtxLog(msg.TraceID(), "PushSend", "Handle", types.Int2Str(msg.Timestamp))
+	var data model.MsgPush
+	if err := json.Unmarshal(msg.Body, &data); err != nil {
+		l.Error("json.Unmarshal error", msg.Body, err)
+		return nil
+	}
+	if data.Category == "" {
+		data.Category = model.PushCategoryTransactional
+	}
+	pref, devices, openid, err := h.service.PushTargets(ctx, data.UserID)
+	if err != nil {
+		l.Error("service.PushTargets error", data.UserID, err)
+		return err
+	}
+	channel := pref.Channel
+	if until := pref.QuietUntil(data.Category, time.Now()); !until.IsZero() && channel != model.PushChannelOff {
+		if err = h.delayer.Publish(ctx, model.TopicPush, msg.Body, time.Until(until)); err != nil {
+			l.Error("delayer.Publish error", data.MsgID, err)
+			return err
+		}
+		err = h.service.SavePushDeliveries(ctx, []*model.PushDelivery{{MsgID: data.MsgID, UserID: data.UserID,
+			Channel: channel, Status: model.PushDeferred, Error: "quiet until " + until.Format("15:04")}})
+		if err != nil {
+			l.Error("service.SavePushDeliveries error", data.MsgID, err)
+		}
+		return nil
+	}
+	var list []*model.PushDelivery
+	sent := false
+	if (channel == model.PushChannelApp || channel == model.PushChannelAuto) && pref.Allow(data.Category, model.PushChannelApp) {
+		n := &push.Notification{Title: data.Title, Body: data.Body, Data: data.Data, TTL: data.TTL}
+		for _, d := range devices {
+			r := h.sendApp(ctx, d, n)
+			r.MsgID, r.UserID = data.MsgID, data.UserID
+			list = append(list, r)
+			sent = sent || r.Status == model.PushSent
+		}
+	}
+	if data.Wechat != nil && openid != "" && pref.Allow(data.Category, model.PushChannelWechat) &&
+		(channel == model.PushChannelWechat || channel == model.PushChannelAuto && !sent) {
+		r := h.sendWechat(ctx, openid, data.Wechat)
+		r.MsgID, r.UserID = data.MsgID, data.UserID
+		list = append(list, r)
+	}
+	if len(list) == 0 {
+		list = append(list, &model.PushDelivery{MsgID: data.MsgID, UserID: data.UserID, Channel: channel, Status: model.PushSkipped})
+	}
+	if err = h.service.SavePushDeliveries(ctx, list); err != nil {
+		l.Error("service.SavePushDeliveries error", list, err)
+	}
+	return nil
+}
+
+func (h *PushSend) sendApp(ctx context.Context, d *model.PushDevice, n *push.Notification) *model.PushDelivery {
+	r := &model.PushDelivery{Channel: model.PushChannelApp, Provider: d.Provider, DeviceID: d.DeviceID}
+	id, err := h.gateway.Send(ctx, d.Provider, d.Token, n)
+	switch {
+	case err == nil:
+		r.Status, r.ProviderID = model.PushSent, id
+	case errors.Is(err, push.ErrInvalidToken):
+		r.Status, r.Error = model.PushInvalid, err.Error()
+		if err = h.service.DeletePushDevice(ctx, d.ID, d.Token); err != nil {
+			logger.FromContext(ctx).Error("service.DeletePushDevice error", d.DeviceID, err)
+		}
+	default:
+		r.Status, r.Error = model.PushFailed, err.Error()
+		logger.FromContext(ctx).Warn("push.Send error", d.DeviceID, err)
+	}
+	return r
+}
+
+func (h *PushSend) sendWechat(ctx context.Context, openid string, w *model.PushWechat) *model.PushDelivery {
+	r := &model.PushDelivery{Channel: model.PushChannelWechat, Provider: model.PushChannelWechat}
+	msg := &wechat.SubscribeMessage{
+		ToUser:           openid,
+		TemplateID:       w.TemplateID,
+		Page:             w.Page,
+		MiniprogramState: h.miniState,
+		Data:             make(map[string]*wechat.SubscribeMsgItem, len(w.Data)),
+	}
+	for k, v := range w.Data {
+		msg.Data[k] = &wechat.SubscribeMsgItem{Value: v}
+	}
+	resp, err := h.wechat.SendSubscribeMessage(ctx, msg)
+	switch {
+	case wechat.Code(err) == wechat.ErrcodeSubscribeRefused:
+		r.Status, r.Error = model.PushInvalid, resp.Errmsg
+	case err != nil:
+		r.Status, r.Error = model.PushFailed, err.Error()
+		logger.FromContext(ctx).Warn("wechat.SendSubscribeMessage error", msg, err)
+	default:
+		r.Status, r.ProviderID = model.PushSent, strconv.FormatInt(resp.MsgID, 10)
+	}
+	return r
+}
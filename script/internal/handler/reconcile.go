@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"fmt"
+	"project/model"
+	"project/pkg/dingtalk"
+	"project/pkg/logger"
+	"project/pkg/payment"
+	"project/pkg/util/random"
+	"project/pkg/wechatwork"
+	"project/pkg/wxpay"
+	"strings"
+	"time"
+)
+
+// 告警中最多列出的差异条数
+const reconcileMaxLines = 20
+
+// ReconcileWechatPay 下载昨日微信支付交易账单，与本地订单和退款记录对比，有差异时发送告警
+// 跨零点的退款可能出现在相邻日期的账单中，告警需人工复核
+func (h *Cronjob) ReconcileWechatPay() {
+	if h.wxpay == nil {
+		return
+	}
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "ReconcileWechatPay", "")
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	begin := end.AddDate(0, 0, -1)
+
+	rows, err := h.wxpay.TradeBill(ctx, begin)
+	if err != nil {
+		rows, err = h.wxpay.TradeBill(ctx, begin)
+	}
+	if err != nil {
+		l.Error("wxpay.TradeBill error", begin, err)
+		h.notifyDiff(begin.Format("2006-01-02")+" 微信支付对账差异", []string{"账单下载失败: " + err.Error()})
+		return
+	}
+	orders, err := h.service.FindPaidOrders(ctx, payment.ProviderWxpay, begin.Unix(), end.Unix())
+	if err != nil {
+		l.Error("service.FindPaidOrders error", begin, err)
+		return
+	}
+	refunds, err := h.service.FindSuccessRefunds(ctx, payment.ProviderWxpay, begin.Unix(), end.Unix())
+	if err != nil {
+		l.Error("service.FindSuccessRefunds error", begin, err)
+		return
+	}
+
+	diff := diffTradeBill(rows, orders, refunds)
+	l.Info("ReconcileWechatPay", begin.Format("2006-01-02"), map[string]int{
+		"bill":    len(rows),
+		"orders":  len(orders),
+		"refunds": len(refunds),
+		"diff":    len(diff),
+	})
+	if len(diff) > 0 {
+		h.notifyDiff(begin.Format("2006-01-02")+" 微信支付对账差异", diff)
+	}
+}
+
+func diffTradeBill(rows []*wxpay.BillRow, orders []*model.Order, refunds []*model.OrderRefund) []string {
+	paid := make(map[string]int64)
+	refunded := make(map[string]int64)
+	for _, v := range rows {
+		switch v.TradeState {
+		case "SUCCESS":
+			paid[v.OutTradeNo] = v.Total
+		case "REFUND":
+			if v.RefundStatus == wxpay.RefundSuccess {
+				refunded[v.OutRefundNo] = v.Refund
+			}
+		}
+	}
+
+	diff := make([]string, 0)
+	for _, o := range orders {
+		amount, ok := paid[o.OrderNo]
+		switch {
+		case !ok:
+			diff = append(diff, fmt.Sprintf("订单%s 账单中不存在", o.OrderNo))
+		case amount != o.Amount:
+			diff = append(diff, fmt.Sprintf("订单%s 金额不一致 本地%d 账单%d", o.OrderNo, o.Amount, amount))
+		}
+		delete(paid, o.OrderNo)
+	}
+	for no, amount := range paid {
+		diff = append(diff, fmt.Sprintf("订单%s 本地未支付 账单%d", no, amount))
+	}
+	for _, r := range refunds {
+		amount, ok := refunded[r.RefundNo]
+		switch {
+		case !ok:
+			diff = append(diff, fmt.Sprintf("退款%s 账单中不存在", r.RefundNo))
+		case amount != r.Amount:
+			diff = append(diff, fmt.Sprintf("退款%s 金额不一致 本地%d 账单%d", r.RefundNo, r.Amount, amount))
+		}
+		delete(refunded, r.RefundNo)
+	}
+	for no, amount := range refunded {
+		diff = append(diff, fmt.Sprintf("退款%s 本地未成功 账单%d", no, amount))
+	}
+	return diff
+}
+
+// notifyDiff 发送差异告警，超过reconcileMaxLines条时截断
+func (h *Cronjob) notifyDiff(title string, diff []string) {
+	text := &strings.Builder{}
+	text.WriteString(title)
+	fmt.Fprintf(text, "(%d条):", len(diff))
+	for i, v := range diff {
+		if i == reconcileMaxLines {
+			text.WriteString("\n...")
+			break
+		}
+		text.WriteString("\n")
+		text.WriteString(v)
+	}
+	content := text.String()
+	_, _ = wechatwork.SendText(h.robotWechat, &wechatwork.Text{Content: content})
+	_, _ = dingtalk.SendText(h.robotDing, &dingtalk.Text{Content: content}, nil)
+}
@@ -1,47 +1,129 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"project/model"
 	"project/pkg/logger"
 	"project/pkg/util/random"
 	"project/pkg/wechat"
+	"project/pkg/wecom"
 	"project/script/internal/service"
 	"time"
 )
 
 type RefreshToken struct {
-	service *service.Service
-	wechat  wechat.BasicAPI
+	store  wechat.TokenStore
+	wechat wechat.BasicAPI
+	mp     wechat.FullAPI
+	wecom  wecom.BasicAPI
 }
 
-func NewRefreshToken(srv *service.Service, api wechat.BasicAPI) *RefreshToken {
+// NewRefreshToken mp为公众号接口，为空时不刷新jsapi_ticket；wc为企业微信接口，为空时不刷新
+func NewRefreshToken(srv *service.Service, api wechat.BasicAPI, mp wechat.FullAPI, wc wecom.BasicAPI) *RefreshToken {
 	return &RefreshToken{
-		service: srv,
-		wechat:  api,
+		store:  srv.TokenStore(),
+		wechat: api,
+		mp:     mp,
+		wecom:  wc,
 	}
 
 }
 
 func (s *RefreshToken) WechatServerToken() {
 	ctx, l := logger.NewCtxLog(random.UUID(), "RefreshToken", "WechatServerToken", "")
-	ttl, err := s.service.TtlWechatToken(ctx)
-	if err != nil {
-		l.Error("service.TtlWechatToken error", nil, err)
-		return
+	if err := s.Refresh(ctx); err != nil {
+		l.Error("RefreshToken.Refresh error", nil, err)
+	}
+	if s.mp != nil {
+		if err := s.RefreshMp(ctx); err != nil {
+			l.Error("RefreshToken.RefreshMp error", nil, err)
+		}
 	}
-	if ttl > 10*time.Minute {
-		return
+	if s.wecom != nil {
+		if err := s.RefreshWecom(ctx); err != nil {
+			l.Error("RefreshToken.RefreshWecom error", nil, err)
+		}
 	}
-	resp, err := s.wechat.GetAccessToken(ctx)
+}
+
+// Refresh 有效期不足10分钟时重新获取access_token
+func (s *RefreshToken) Refresh(ctx context.Context) error {
+	_, err := s.refresh(ctx, model.KeyWechatToken, false, func(ctx context.Context) (string, int64, error) {
+		resp, err := s.wechat.GetAccessToken(ctx)
+		if err != nil {
+			return "", 0, fmt.Errorf("wechat.AccessToken: %w", err)
+		}
+		if resp.AccessToken == "" {
+			return "", 0, errors.New("wechat.AccessToken: empty access_token")
+		}
+		return resp.AccessToken, resp.ExpiresIn, nil
+	})
+	return err
+}
+
+// RefreshMp 有效期不足10分钟时重新获取公众号access_token和jsapi_ticket，换取新token后ticket一并更新
+func (s *RefreshToken) RefreshMp(ctx context.Context) error {
+	renewed, err := s.refresh(ctx, model.KeyMpToken, false, func(ctx context.Context) (string, int64, error) {
+		resp, err := s.mp.GetAccessToken(ctx)
+		if err != nil {
+			return "", 0, fmt.Errorf("mp.AccessToken: %w", err)
+		}
+		if resp.AccessToken == "" {
+			return "", 0, errors.New("mp.AccessToken: empty access_token")
+		}
+		return resp.AccessToken, resp.ExpiresIn, nil
+	})
 	if err != nil {
-		l.Error("wechat.AccessToken error", nil, err)
-		return
+		return err
 	}
-	if resp.Errcode == 0 && resp.AccessToken != "" {
-		err = s.service.SetWechatToken(ctx, resp.AccessToken, time.Duration(resp.ExpiresIn)*time.Second)
+	_, err = s.refresh(ctx, model.KeyJSAPITicket, renewed, func(ctx context.Context) (string, int64, error) {
+		resp, err := s.mp.GetJSAPITicket(ctx)
 		if err != nil {
-			l.Error("service.SetWechatToken error", nil, err)
+			return "", 0, fmt.Errorf("mp.GetJSAPITicket: %w", err)
 		}
-	} else {
-		l.Warn("wechat.AccessToken fail", nil, resp)
+		if resp.Ticket == "" {
+			return "", 0, errors.New("mp.GetJSAPITicket: empty ticket")
+		}
+		return resp.Ticket, resp.ExpiresIn, nil
+	})
+	return err
+}
+
+// RefreshWecom 有效期不足10分钟时重新获取企业微信access_token
+func (s *RefreshToken) RefreshWecom(ctx context.Context) error {
+	_, err := s.refresh(ctx, model.KeyWecomToken, false, func(ctx context.Context) (string, int64, error) {
+		resp, err := s.wecom.GetToken(ctx)
+		if err != nil {
+			return "", 0, fmt.Errorf("wecom.GetToken: %w", err)
+		}
+		if resp.Errcode != 0 || resp.AccessToken == "" {
+			return "", 0, fmt.Errorf("wecom.GetToken fail: %d %s", resp.Errcode, resp.Errmsg)
+		}
+		return resp.AccessToken, resp.ExpiresIn, nil
+	})
+	return err
+}
+
+// refresh 有效期不足10分钟或force时调用fetch获取新凭证，以读取时的值为条件写入；
+// 多个实例同时刷新时只有一个写入成功，返回是否写入了新值
+func (s *RefreshToken) refresh(ctx context.Context, key string, force bool,
+	fetch func(ctx context.Context) (string, int64, error)) (bool, error) {
+	old, ttl, err := s.store.Get(ctx, key)
+	if err != nil && !errors.Is(err, wechat.ErrNoToken) {
+		return false, fmt.Errorf("store.Get %s: %w", key, err)
+	}
+	if !force && ttl > 10*time.Minute {
+		return false, nil
+	}
+	tk, expiresIn, err := fetch(ctx)
+	if err != nil {
+		return false, err
+	}
+	ok, err := s.store.CompareAndSet(ctx, key, old, tk, time.Duration(expiresIn)*time.Second)
+	if err != nil {
+		return false, fmt.Errorf("store.CompareAndSet %s: %w", key, err)
 	}
+	return ok, nil
 }
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"encoding/json"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/mq"
+	"project/pkg/util/types"
+	"project/script/internal/service"
+)
+
+type StockWriteBack struct {
+	service *service.Service
+}
+
+func NewStockWriteBack(srv *service.Service) *StockWriteBack {
+	return &StockWriteBack{
+		service: srv,
+	}
+}
+
+// Handle 回写失败返回error由消息队列重新投递，库存不一致时记录错误并丢弃，由后台补货校正
+func (h *StockWriteBack) Handle(msg *mq.Message) error {
+	ctx, l := logger.NewCtxLog(msg.ID, "StockWriteBack", "Handle", types.Int2Str(msg.Timestamp))
+	var data model.MsgStock
+	if err := json.Unmarshal(msg.Body, &data); err != nil {
+		l.Error("json.Unmarshal error", msg.Body, err)
+		return nil
+	}
+	ok, err := h.service.WriteBackStock(ctx, &data)
+	if err == service.ErrStockDrift {
+		l.Error("service.WriteBackStock drift", &data, err)
+		return nil
+	}
+	if err != nil {
+		l.Error("service.WriteBackStock error", &data, err)
+		return err
+	}
+	if !ok {
+		l.Info("service.WriteBackStock duplicate", &data, nil)
+	}
+	return nil
+}
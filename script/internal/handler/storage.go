@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"project/pkg/coss"
+	"project/pkg/logger"
+	"project/pkg/util/random"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 对象存储清理：上传后未被数据库引用的对象先移到隔离前缀，超过保留天数再删除，隔离期间重新被引用的对象会恢复
+// 临时上传前缀按存储桶的生命周期规则过期，未完成的分块上传一并清理
+
+type StorageConfig struct {
+	Prefixes   []string // 需要对账的前缀，如 img/、file/
+	Refs       []string // 引用对象的表.列，如 banner.img、user.avatar_url
+	MinAge     int      // 上传后多少小时内不处理，避免已上传但还未保存到业务表的对象被隔离，默认24
+	Grace      int      // 隔离保留天数，默认30
+	Max        int      // 单次最多隔离的对象数，防止引用配置错误时大量误移，默认1000
+	Quarantine string   // 隔离前缀，默认quarantine/
+	TmpPrefix  string   // 临时上传前缀，为空不设置生命周期规则
+	TmpDays    int      // 临时上传保留天数，默认1
+	AbortDays  int      // 未完成的分块上传保留天数，默认3
+	DryRun     bool     // 只记录日志不移动、删除
+}
+
+const storagePageSize = 1000
+
+func (cfg *StorageConfig) init() {
+	if cfg.MinAge <= 0 {
+		cfg.MinAge = 24
+	}
+	if cfg.Grace <= 0 {
+		cfg.Grace = 30
+	}
+	if cfg.Max <= 0 {
+		cfg.Max = 1000
+	}
+	if cfg.Quarantine == "" {
+		cfg.Quarantine = "quarantine/"
+	}
+	if cfg.TmpDays <= 0 {
+		cfg.TmpDays = 1
+	}
+	if cfg.AbortDays <= 0 {
+		cfg.AbortDays = 3
+	}
+}
+
+// CleanStorage 设置临时上传的生命周期规则，隔离未引用的对象，删除隔离期满的对象
+func (h *Cronjob) CleanStorage() {
+	if h.cos == nil || h.storage == nil {
+		return
+	}
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "CleanStorage", "")
+	cfg := h.storage
+	if cfg.TmpPrefix != "" && !cfg.DryRun {
+		rules := []*coss.LifecycleRule{{ID: "tmp-upload", Prefix: cfg.TmpPrefix, Days: cfg.TmpDays, AbortDays: cfg.AbortDays}}
+		if err := h.cos.PutLifecycle(ctx, rules); err != nil {
+			l.Error("cos.PutLifecycle error", rules, err)
+		}
+	}
+	if len(cfg.Prefixes) == 0 {
+		return
+	}
+
+	quoted := make([]string, len(cfg.Prefixes))
+	for i, v := range cfg.Prefixes {
+		quoted[i] = regexp.QuoteMeta(v)
+	}
+	pathReg := regexp.MustCompile(`(?:` + strings.Join(quoted, "|") + `)[\w./-]+`)
+	refs, err := h.service.StorageRefs(ctx, cfg.Refs, pathReg)
+	if err != nil {
+		// 引用不完整时不能判断孤儿对象
+		l.Error("service.StorageRefs error", cfg.Refs, err)
+		return
+	}
+
+	stat := map[string]int64{"refs": int64(len(refs))}
+	before := time.Now().Add(-time.Duration(cfg.MinAge) * time.Hour)
+	for _, prefix := range cfg.Prefixes {
+		err = h.walkStorage(ctx, prefix, func(obj *coss.Object) bool {
+			stat["scanned"]++
+			if refs[obj.Path] || obj.ModTime.After(before) {
+				return true
+			}
+			if stat["quarantined"] >= int64(cfg.Max) {
+				return false
+			}
+			stat["quarantined"]++
+			stat["bytes"] += obj.Size
+			l.Info("quarantine", obj.Path, obj.Size)
+			if !cfg.DryRun {
+				if err := h.moveObject(ctx, obj.Path, cfg.Quarantine+obj.Path); err != nil {
+					stat["errors"]++
+					l.Error("moveObject error", obj.Path, err)
+				}
+			}
+			return true
+		})
+		if err != nil {
+			l.Error("cos.List error", prefix, err)
+		}
+	}
+
+	expired := time.Now().AddDate(0, 0, -cfg.Grace)
+	err = h.walkStorage(ctx, cfg.Quarantine, func(obj *coss.Object) bool {
+		origin := strings.TrimPrefix(obj.Path, cfg.Quarantine)
+		var err error
+		switch {
+		case refs[origin]:
+			stat["restored"]++
+			l.Info("restore", origin, obj.Size)
+			if !cfg.DryRun {
+				err = h.moveObject(ctx, obj.Path, origin)
+			}
+		case obj.ModTime.Before(expired):
+			stat["deleted"]++
+			l.Info("delete", obj.Path, obj.Size)
+			if !cfg.DryRun {
+				err = h.cos.Delete(ctx, obj.Path)
+			}
+		}
+		if err != nil {
+			stat["errors"]++
+			l.Error("storage error", obj.Path, err)
+		}
+		return true
+	})
+	if err != nil {
+		l.Error("cos.List error", cfg.Quarantine, err)
+	}
+	l.Info("CleanStorage", cfg.DryRun, stat)
+}
+
+// walkStorage 逐页列举前缀下的对象，fn返回false时停止
+func (h *Cronjob) walkStorage(ctx context.Context, prefix string, fn func(*coss.Object) bool) error {
+	marker := ""
+	for {
+		list, next, err := h.cos.List(ctx, prefix, marker, storagePageSize)
+		if err != nil {
+			return err
+		}
+		for _, v := range list {
+			if !fn(v) {
+				return nil
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		marker = next
+	}
+}
+
+// moveObject 复制成功后再删除源对象，删除失败时下次运行会重新处理
+func (h *Cronjob) moveObject(ctx context.Context, src, dst string) error {
+	if err := h.cos.Copy(ctx, src, dst); err != nil {
+		return err
+	}
+	return h.cos.Delete(ctx, src)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"project/pkg/logger"
+	"project/pkg/util/random"
+	"time"
+)
+
+// RollupUsage 回写当日和昨日的接口调用量，昨日数据在零点后补齐最后一段计数
+func (h *Cronjob) RollupUsage() {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "RollupUsage", "")
+	now := time.Now()
+	for _, d := range []time.Time{now.AddDate(0, 0, -1), now} {
+		date := d.Format("20060102")
+		n, err := h.service.RollupUsage(ctx, date)
+		if err != nil {
+			l.Error("service.RollupUsage error", date, err)
+			continue
+		}
+		l.Info("RollupUsage", date, n)
+	}
+}
+
+// RollupStats 汇总当日和昨日的看板数据，昨日数据在零点后补齐
+func (h *Cronjob) RollupStats() {
+	ctx, l := logger.NewCtxLog(random.UUID(), "Cronjob", "RollupStats", "")
+	now := time.Now()
+	for _, d := range []time.Time{now.AddDate(0, 0, -1), now} {
+		date := d.Format("20060102")
+		stat, err := h.service.RollupStat(ctx, d)
+		if err != nil {
+			l.Error("service.RollupStat error", date, err)
+			continue
+		}
+		l.Info("RollupStats", date, stat)
+	}
+}
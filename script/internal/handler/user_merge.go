@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"encoding/json"
+	"project/model"
+	"project/pkg/logger"
+	"project/pkg/mq"
+	"project/pkg/util/types"
+	"project/script/internal/service"
+)
+
+type UserMerge struct {
+	service *service.Service
+}
+
+func NewUserMerge(srv *service.Service) *UserMerge {
+	return &UserMerge{
+		service: srv,
+	}
+}
+
+// Handle 合并失败时重试，已合并的用户不再属于该unionid，重复消息不会重复转移
+func (h *UserMerge) Handle(msg *mq.Message) error {
+	ctx, l := logger.NewCtxLog(msg.TraceID(), "UserMerge", "Handle", types.Int2Str(msg.Timestamp))
+	var data model.MsgUserMerge
+	if err := json.Unmarshal(msg.Body, &data); err != nil || data.Unionid == "" {
+		l.Error("json.Unmarshal error", msg.Body, err)
+		return nil
+	}
+	list, err := h.service.MergeUnionid(ctx, data.Unionid)
+	for _, v := range list {
+		l.Info("user merged", data.Unionid, v)
+	}
+	if err != nil {
+		l.Error("service.MergeUnionid error", &data, err)
+		return err
+	}
+	return nil
+}
@@ -2,22 +2,30 @@ package service
 
 import (
 	"context"
+	"gorm.io/gorm/clause"
 	"project/model"
-	"time"
+	"project/pkg/wechat"
+	"project/pkg/wechat/tokenstore"
 )
 
-func (s *Service) GetWechatToken(ctx context.Context) (string, error) {
-	return s.redis.Get(ctx, model.KeyWechatToken).Result()
+// TokenStore 小程序、公众号、企业微信的access_token和jsapi_ticket等凭证的存储
+func (s *Service) TokenStore() wechat.TokenStore {
+	return tokenstore.NewRedis(s.redis)
 }
 
-func (s *Service) TtlWechatToken(ctx context.Context) (time.Duration, error) {
-	return s.redis.TTL(ctx, model.KeyWechatToken).Result()
-}
-
-func (s *Service) SetWechatToken(ctx context.Context, tk string, ttl time.Duration) error {
-	return s.redis.Set(ctx, model.KeyWechatToken, tk, ttl).Err()
+// WechatQuota 微信接口每日额度，计数在redis中与api共享
+func (s *Service) WechatQuota(appid string, limits map[string]int64) *wechat.Quota {
+	return &wechat.Quota{Counter: tokenstore.NewRedis(s.redis), Prefix: model.KeyWechatQuota + appid + ":", Limits: limits}
 }
 
 func (s *Service) SaveWechatAnalysis(ctx context.Context, data *model.WechatAnalysis) error {
 	return s.mysql.WithContext(ctx).FirstOrCreate(data, "ref_date = ?", data.RefDate).Error
 }
+
+func (s *Service) SaveWechatRetain(ctx context.Context, list []*model.WechatRetain) error {
+	return s.mysql.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(list).Error
+}
+
+func (s *Service) SaveWechatDistribution(ctx context.Context, list []*model.WechatDistribution) error {
+	return s.mysql.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(list).Error
+}
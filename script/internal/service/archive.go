@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"project/pkg/archive"
+	"project/pkg/coss"
+)
+
+// NewArchiver 归档文件登记在主库，恢复到主库
+func (s *Service) NewArchiver(store coss.Store, prefix string) *archive.Archiver {
+	return archive.New(s.mysql, store, prefix)
+}
+
+// Archive 按策略归档，按用户分片的表依次归档每个分片
+func (s *Service) Archive(ctx context.Context, a *archive.Archiver, p *archive.Policy) (int64, error) {
+	if s.shards == nil || !s.shards.Holds(p.Table) {
+		return a.Run(ctx, s.mysql, 0, p)
+	}
+	var total int64
+	for i, db := range s.shards.Shards() {
+		n, err := a.Run(ctx, db, i, p)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"project/pkg/db"
+)
+
+// SaveAuthEvent 写入用户所在分片，event_id唯一，重复投递时忽略；slot迁移中返回错误由消息队列重试
+func (s *Service) SaveAuthEvent(ctx context.Context, e *model.AuthEvent) error {
+	db, err := s.shards.Write(e.UserID)
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(e).Error
+}
+
+// MoveSlots 依次迁移[begin, end]的slot到目标分片
+func (s *Service) MoveSlots(ctx context.Context, begin, end, to int, opt *db.MoveOptions) error {
+	for slot := begin; slot <= end; slot++ {
+		if err := s.shards.MoveSlot(ctx, slot, to, opt); err != nil {
+			return fmt.Errorf("slot %d: %w", slot, err)
+		}
+	}
+	return nil
+}
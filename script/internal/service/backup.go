@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"os"
+	"project/pkg/backup"
+	"project/pkg/db"
+)
+
+// VerifyBackup 导入到临时库核对表和行数
+func (s *Service) VerifyBackup(ctx context.Context, b *backup.Backup, meta *backup.Meta, scratch string) (*backup.Report, error) {
+	return b.Verify(ctx, s.mysql, meta, scratch)
+}
+
+// SchemaDrift 对比建表语句文件与线上库的差异
+func (s *Service) SchemaDrift(ctx context.Context, files []string) ([]string, error) {
+	schema := make(map[string]map[string]string)
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		tables, err := db.ParseSchema(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range tables {
+			schema[k] = v
+		}
+	}
+	return db.Drift(ctx, s.mysql, schema)
+}
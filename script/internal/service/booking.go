@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"project/model"
+	"strconv"
+	"time"
+)
+
+// ExpireBooking 关闭保留到期未确认的预约并释放名额，已确认、已取消或未到期的返回false
+func (s *Service) ExpireBooking(ctx context.Context, id int) (bool, error) {
+	var list []*model.Booking
+	if err := s.mysql.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&list).Error; err != nil || len(list) == 0 {
+		return false, err
+	}
+	b := list[0]
+	if b.Status != model.BookingHeld || b.HoldExpire.After(time.Now()) {
+		return false, nil
+	}
+	opt := s.mysql.WithContext(ctx).Model(b).Where("status = ?", model.BookingHeld).
+		Updates(map[string]any{"status": model.BookingExpired, "operator": "system"})
+	if opt.Error != nil || opt.RowsAffected == 0 {
+		return false, opt.Error
+	}
+	key := model.BookingSlotKey(b.ResourceID, b.SlotStart.Unix())
+	return true, model.ScriptBookingRelease.Run(ctx, s.redis, []string{key}, b.UserID, b.HoldExpire.UnixMilli()).Err()
+}
+
+// RemindBooking 已确认且开始时间未变的预约发送提醒推送，返回false表示不需要提醒
+func (s *Service) RemindBooking(ctx context.Context, id int, slotStart int64) (bool, error) {
+	var list []*model.Booking
+	if err := s.mysql.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&list).Error; err != nil || len(list) == 0 {
+		return false, err
+	}
+	b := list[0]
+	if b.Status != model.BookingConfirmed || b.SlotStart.Unix() != slotStart || !b.SlotStart.After(time.Now()) {
+		return false, nil
+	}
+	var res model.BookingResource
+	if err := s.mysql.WithContext(ctx).Select("id", "name").Take(&res, b.ResourceID).Error; err != nil {
+		return false, err
+	}
+	body, _ := json.Marshal(&model.MsgPush{
+		MsgID:    model.BookingMsgID(b.ID, "remind"),
+		UserID:   b.UserID,
+		Category: model.PushCategoryReminder,
+		Title:    "预约提醒",
+		Body:     fmt.Sprintf("您预约的%s将于%s开始", res.Name, b.SlotStart.Format("1月2日 15:04")),
+		Data:     map[string]string{"booking_id": strconv.Itoa(b.ID)},
+	})
+	return true, s.producer.Publish(model.TopicPush, body)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"gorm.io/gorm"
+	"project/model"
+	"time"
+)
+
+// FindDueCampaigns 到发送时间的活动，以及发送中超过idle未更新(进程退出)的活动
+func (s *Service) FindDueCampaigns(ctx context.Context, idle time.Duration) (list []*model.Campaign, err error) {
+	now := time.Now()
+	err = s.mysql.WithContext(ctx).
+		Where("status = ? AND send_time <= ?", model.CampaignScheduled, now.Unix()).
+		Or("status = ? AND update_time < ?", model.CampaignRunning, now.Add(-idle)).
+		Order("send_time").Limit(10).Find(&list).Error
+	return
+}
+
+// ClaimCampaign 以读取时的状态和更新时间为条件改为发送中，未命中说明已取消或被其他实例接手
+func (s *Service) ClaimCampaign(ctx context.Context, c *model.Campaign) (bool, error) {
+	now := time.Now()
+	opt := s.mysql.WithContext(ctx).Model(&model.Campaign{}).
+		Where("id = ? AND status = ? AND update_time = ?", c.ID, c.Status, c.UpdateTime).
+		Updates(map[string]any{"status": model.CampaignRunning, "update_time": now})
+	c.Status, c.UpdateTime = model.CampaignRunning, now
+	return opt.RowsAffected > 0, opt.Error
+}
+
+// CountCampaignAudience 开始发送时统计人数，名单在开始后不再追加
+func (s *Service) CountCampaignAudience(ctx context.Context, c *model.Campaign) (int64, error) {
+	var total int64
+	err := s.audienceQuery(ctx, c, 0).Count(&total).Error
+	return total, err
+}
+
+// NextCampaignAudience 按用户ID顺序取cursor之后的一批
+func (s *Service) NextCampaignAudience(ctx context.Context, c *model.Campaign, limit int) (uids []int, err error) {
+	col := "id"
+	if c.Audience != model.CampaignAudienceSegment {
+		col = "user_id"
+	}
+	err = s.audienceQuery(ctx, c, c.Cursor).Order(col).Limit(limit).Pluck(col, &uids).Error
+	return
+}
+
+func (s *Service) audienceQuery(ctx context.Context, c *model.Campaign, cursor int) *gorm.DB {
+	switch c.Audience {
+	case model.CampaignAudienceList:
+		return s.mysql.WithContext(ctx).Model(&model.CampaignAudience{}).
+			Where("campaign_id = ? AND user_id > ?", c.ID, cursor)
+	case model.CampaignAudienceSaved:
+		return s.mysql.WithContext(ctx).Model(&model.SegmentMember{}).
+			Where("segment_id = ? AND user_id > ?", c.SegmentID, cursor)
+	}
+	query := s.mysql.WithContext(ctx).Model(&model.User{}).Where("id > ?", cursor)
+	seg := c.Segment
+	if seg == nil {
+		return query
+	}
+	if seg.HasPhone {
+		query = query.Where("phone_number != ''")
+	}
+	if seg.HasDevice {
+		query = query.Where("EXISTS (SELECT 1 FROM push_device WHERE push_device.user_id = user.id)")
+	}
+	if seg.JoinAfter > 0 {
+		query = query.Where("create_time >= ?", time.Unix(seg.JoinAfter, 0))
+	}
+	if seg.JoinBefore > 0 {
+		query = query.Where("create_time < ?", time.Unix(seg.JoinBefore, 0))
+	}
+	return query
+}
+
+// PublishCampaign 一批用户的推送任务投递到TopicPush，由push:send按用户偏好发送
+func (s *Service) PublishCampaign(c *model.Campaign, uids []int) error {
+	body := make([][]byte, 0, len(uids))
+	for _, uid := range uids {
+		b, _ := json.Marshal(&model.MsgPush{
+			MsgID:    model.CampaignMsgID(c.ID, uid),
+			UserID:   uid,
+			Category: c.Category,
+			Title:    c.Title,
+			Body:     c.Body,
+			Data:     c.Data,
+			Wechat:   c.Wechat,
+		})
+		body = append(body, b)
+	}
+	return s.producer.MultiPublish(model.TopicPush, body)
+}
+
+// UpdateCampaignProgress 记录已投递的位置，返回false表示已被取消
+func (s *Service) UpdateCampaignProgress(ctx context.Context, c *model.Campaign, fields map[string]any) (bool, error) {
+	c.UpdateTime = time.Now()
+	fields["update_time"] = c.UpdateTime
+	opt := s.mysql.WithContext(ctx).Model(&model.Campaign{}).
+		Where("id = ? AND status = ?", c.ID, model.CampaignRunning).Updates(fields)
+	return opt.RowsAffected > 0, opt.Error
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"project/model"
+	"time"
+)
+
+// LedgerDrift 账户余额与分录汇总或最后一条分录的余额快照不一致
+type LedgerDrift struct {
+	UserID   int
+	Asset    string
+	Balance  int64 // 账户余额
+	Total    int64 // 分录汇总
+	Snapshot int64 // 最后一条分录的余额快照
+}
+
+type ledgerSum struct {
+	UserID int
+	Asset  string
+	Total  int64
+	LastID int
+}
+
+// FindUnbalancedLedger 查询begin之后借贷不平(金额之和不为0或不是两条分录)的业务号
+func (s *Service) FindUnbalancedLedger(ctx context.Context, begin time.Time) (list []string, err error) {
+	err = s.mysql.WithContext(ctx).Model(&model.LedgerEntry{}).
+		Select("CONCAT(asset, ':', ref_no)").
+		Where("create_time >= ?", begin).
+		Group("ref_no, asset").
+		Having("SUM(amount) != 0 OR COUNT(*) != 2").
+		Limit(100).Find(&list).Error
+	return
+}
+
+// FindLedgerDrift 按账户id分批校验余额，返回本批最后一个账户id，为0表示已校验完
+func (s *Service) FindLedgerDrift(ctx context.Context, lastID, limit int) ([]*LedgerDrift, int, error) {
+	var accounts []*model.LedgerAccount
+	err := s.mysql.WithContext(ctx).Where("id > ?", lastID).Order("id").Limit(limit).Find(&accounts).Error
+	if err != nil || len(accounts) == 0 {
+		return nil, 0, err
+	}
+	uids := make([]int, len(accounts))
+	for i, v := range accounts {
+		uids[i] = v.UserID
+	}
+	var sums []*ledgerSum
+	err = s.mysql.WithContext(ctx).Model(&model.LedgerEntry{}).
+		Select("user_id, asset, SUM(amount) AS total, MAX(id) AS last_id").
+		Where("user_id IN ?", uids).Group("user_id, asset").Find(&sums).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	lastIDs := make([]int, len(sums))
+	for i, v := range sums {
+		lastIDs[i] = v.LastID
+	}
+	var entries []*model.LedgerEntry
+	if len(lastIDs) > 0 {
+		err = s.mysql.WithContext(ctx).Select("id, balance").Where("id IN ?", lastIDs).Find(&entries).Error
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	snapshots := make(map[int]int64, len(entries))
+	for _, v := range entries {
+		snapshots[v.ID] = v.Balance
+	}
+	type key struct {
+		uid   int
+		asset string
+	}
+	m := make(map[key]*ledgerSum, len(sums))
+	for _, v := range sums {
+		m[key{v.UserID, v.Asset}] = v
+	}
+	drift := make([]*LedgerDrift, 0)
+	for _, a := range accounts {
+		d := &LedgerDrift{UserID: a.UserID, Asset: a.Asset, Balance: a.Balance}
+		if sum := m[key{a.UserID, a.Asset}]; sum != nil {
+			d.Total, d.Snapshot = sum.Total, snapshots[sum.LastID]
+		}
+		if d.Total != d.Balance || d.Snapshot != d.Balance {
+			drift = append(drift, d)
+		}
+	}
+	return drift, accounts[len(accounts)-1].ID, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"project/model"
+	"time"
+)
+
+// FindDueMedia 待转码和转码中超过idle未更新(进程已退出)的视频
+func (s *Service) FindDueMedia(ctx context.Context, idle time.Duration, limit int) (list []*model.Media, err error) {
+	err = s.mysql.WithContext(ctx).
+		Where("status = ?", model.MediaPending).
+		Or("status = ? AND update_time < ?", model.MediaProcessing, time.Now().Add(-idle)).
+		Order("id").Limit(limit).Find(&list).Error
+	return
+}
+
+// ClaimMedia 以读取时的状态和更新时间为条件改为转码中并增加尝试次数，未命中说明已被其他实例认领
+func (s *Service) ClaimMedia(ctx context.Context, m *model.Media) (bool, error) {
+	now := time.Now().Truncate(time.Second)
+	opt := s.mysql.WithContext(ctx).Model(&model.Media{}).
+		Where("id = ? AND status = ? AND update_time = ?", m.ID, m.Status, m.UpdateTime).
+		Updates(map[string]any{"status": model.MediaProcessing, "attempts": m.Attempts + 1, "update_time": now})
+	m.Status, m.Attempts, m.UpdateTime = model.MediaProcessing, m.Attempts+1, now
+	return opt.RowsAffected > 0, opt.Error
+}
+
+// UpdateMedia 更新转码中的记录，fields为空时只更新update_time作为心跳；返回false表示已被其他实例重新认领
+// 以上次更新的时间为条件，同一记录的更新不能并发调用
+func (s *Service) UpdateMedia(ctx context.Context, m *model.Media, fields map[string]any) (bool, error) {
+	now := time.Now().Truncate(time.Second)
+	if !now.After(m.UpdateTime) { // 同一秒内的更新，update_time不变时RowsAffected可能为0
+		now = m.UpdateTime.Add(time.Second)
+	}
+	if fields == nil {
+		fields = make(map[string]any, 1)
+	}
+	fields["update_time"] = now
+	opt := s.mysql.WithContext(ctx).Model(&model.Media{}).
+		Where("id = ? AND status = ? AND update_time = ?", m.ID, model.MediaProcessing, m.UpdateTime).Updates(fields)
+	if opt.Error != nil {
+		return false, opt.Error
+	}
+	m.UpdateTime = now
+	return opt.RowsAffected > 0, nil
+}
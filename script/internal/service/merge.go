@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"strconv"
+	"time"
+)
+
+// 同一unionid的用户合并：保留小程序的用户(openid不在user_wechat中)，都不是时保留最早注册的
+// 订单、优惠券、登录渠道、推送设备转移到保留用户；积分和余额按分录转入；资料只补全空字段；处罚状态取更严重的
+// 被合并用户的openid改为merged:id、清空unionid并置为已合并，其token在下次请求时失效；登录记录等历史数据不转移
+// 按用户分片的表(如user_device)两个用户可能在不同分片，在主库事务之前逐批复制到保留用户的分片后删除，重试时按唯一键忽略已复制的行
+
+const bizUserMerge = "user_merge"
+
+// mergeTables 直接转移user_id的表
+var mergeTables = []string{"order_info", "coupon", "user_wechat", "push_device"}
+
+// mergeIgnoreTables 按唯一键冲突时保留目标用户的记录，分片的表同样按此处理
+var mergeIgnoreTables = []string{"user_device", "push_preference"}
+
+// MergeUnionid 返回本次的合并记录，只有一个用户或已被并发合并时为空
+func (s *Service) MergeUnionid(ctx context.Context, unionid string) ([]*model.UserMerge, error) {
+	db := s.mysql.WithContext(ctx)
+	var users []*model.User
+	if err := db.Where("unionid = ?", unionid).Order("id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) < 2 {
+		return nil, nil
+	}
+	var openids []string
+	err := db.Model(&model.UserWechat{}).Where("unionid = ?", unionid).Pluck("openid", &openids).Error
+	if err != nil {
+		return nil, err
+	}
+	channel := make(map[string]bool, len(openids))
+	for _, v := range openids {
+		channel[v] = true
+	}
+	to := users[0]
+	for _, u := range users {
+		if !channel[u.Openid] {
+			to = u
+			break
+		}
+	}
+	var list []*model.UserMerge
+	for _, from := range users {
+		if from.ID == to.ID {
+			continue
+		}
+		m, err := s.mergeUser(ctx, from, to)
+		if err != nil {
+			return list, fmt.Errorf("merge %d to %d: %w", from.ID, to.ID, err)
+		}
+		if m != nil {
+			list = append(list, m)
+		}
+	}
+	return list, nil
+}
+
+// mergeUser 先转移分片的表，再在一个事务中转移主库的表，被合并用户已不属于该unionid时返回nil
+func (s *Service) mergeUser(ctx context.Context, from, to *model.User) (*model.UserMerge, error) {
+	detail := make(map[string]int64)
+	for _, t := range append(mergeTables, mergeIgnoreTables...) {
+		if s.shards == nil || !s.shards.Holds(t) {
+			continue
+		}
+		n, err := s.mergeShardTable(ctx, t, from.ID, to.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t, err)
+		}
+		detail[t] = n
+	}
+	var data *model.UserMerge
+	err := s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var cur model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&cur, from.ID).Error; err != nil {
+			return err
+		}
+		if cur.Unionid != to.Unionid {
+			return nil
+		}
+		for _, t := range mergeTables {
+			if s.shards != nil && s.shards.Holds(t) {
+				continue
+			}
+			opt := tx.Exec("UPDATE `"+t+"` SET user_id = ? WHERE user_id = ?", to.ID, from.ID)
+			if opt.Error != nil {
+				return opt.Error
+			}
+			detail[t] = opt.RowsAffected
+		}
+		for _, t := range mergeIgnoreTables {
+			if s.shards != nil && s.shards.Holds(t) {
+				continue
+			}
+			opt := tx.Exec("UPDATE IGNORE `"+t+"` SET user_id = ? WHERE user_id = ?", to.ID, from.ID)
+			if opt.Error != nil {
+				return opt.Error
+			}
+			detail[t] = opt.RowsAffected
+			if err := tx.Exec("DELETE FROM `"+t+"` WHERE user_id = ?", from.ID).Error; err != nil {
+				return err
+			}
+		}
+		// 分群成员在下次计算时按合并后的数据重建
+		if err := tx.Exec("DELETE FROM segment_member WHERE user_id = ?", from.ID).Error; err != nil {
+			return err
+		}
+		if err := mergeLedger(tx, from.ID, to.ID, detail); err != nil {
+			return err
+		}
+		if err := mergeProfile(tx, &cur, to); err != nil {
+			return err
+		}
+		if err := mergeStatus(tx, from.ID, to.ID); err != nil {
+			return err
+		}
+		err := tx.Model(&model.User{}).Where("id = ?", from.ID).
+			Updates(map[string]any{"openid": "merged:" + strconv.Itoa(from.ID), "unionid": ""}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Save(&model.UserStatus{
+			UserID:   from.ID,
+			Status:   model.UserMerged,
+			Reason:   "合并到" + strconv.Itoa(to.ID),
+			Operator: bizUserMerge,
+		}).Error
+		if err != nil {
+			return err
+		}
+		b, _ := json.Marshal(detail)
+		data = &model.UserMerge{Unionid: to.Unionid, FromUserID: from.ID, ToUserID: to.ID, Detail: string(b)}
+		return tx.Create(data).Error
+	})
+	if err != nil || data == nil {
+		return nil, err
+	}
+	keys := []string{model.UserInfoKey(from.ID), model.UserInfoKey(to.ID),
+		model.UserStatusKey(from.ID), model.UserStatusKey(to.ID)}
+	if err = s.redis.Del(ctx, keys...).Err(); err != nil {
+		return data, fmt.Errorf("redis.Del: %w", err)
+	}
+	return data, nil
+}
+
+// mergeShardTable 分片的表转移到保留用户所在分片，唯一键冲突时保留目标用户的记录，返回转移的行数
+// 同一分片时直接更新；不同分片时逐批复制后删除源行，中断后重试不会重复；slot迁移中返回ErrSlotMoving由消息重试
+func (s *Service) mergeShardTable(ctx context.Context, table string, from, to int) (int64, error) {
+	src, err := s.shards.Write(from)
+	if err != nil {
+		return 0, err
+	}
+	dst, err := s.shards.Write(to)
+	if err != nil {
+		return 0, err
+	}
+	if src == dst {
+		var n int64
+		err = src.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			opt := tx.Exec("UPDATE IGNORE `"+table+"` SET user_id = ? WHERE user_id = ?", to, from)
+			if opt.Error != nil {
+				return opt.Error
+			}
+			n = opt.RowsAffected
+			return tx.Exec("DELETE FROM `"+table+"` WHERE user_id = ?", from).Error
+		})
+		return n, err
+	}
+	var total int64
+	for {
+		var rows []map[string]any
+		err = src.WithContext(ctx).Table(table).Where("user_id = ?", from).Order("id").Limit(500).Find(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return total, err
+		}
+		ids := make([]any, 0, len(rows))
+		for _, r := range rows {
+			ids = append(ids, r["id"])
+			delete(r, "id")
+			r["user_id"] = to
+		}
+		opt := dst.WithContext(ctx).Table(table).Clauses(clause.Insert{Modifier: "IGNORE"}).Create(&rows)
+		if opt.Error != nil {
+			return total, opt.Error
+		}
+		total += opt.RowsAffected
+		if err = src.WithContext(ctx).Exec("DELETE FROM `"+table+"` WHERE id IN ?", ids).Error; err != nil {
+			return total, err
+		}
+	}
+}
+
+// mergeLedger 被合并用户的余额按两组分录转出、转入，与系统账户借贷平衡
+func mergeLedger(tx *gorm.DB, from, to int, detail map[string]int64) error {
+	var accounts []*model.LedgerAccount
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ? AND balance > 0", from).
+		Find(&accounts).Error
+	if err != nil {
+		return err
+	}
+	remark := fmt.Sprintf("用户%d合并到%d", from, to)
+	for _, a := range accounts {
+		target := &model.LedgerAccount{UserID: to, Asset: a.Asset}
+		if err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(target).Error; err != nil {
+			return err
+		}
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(target, "user_id = ? AND asset = ?", to, a.Asset).Error
+		if err != nil {
+			return err
+		}
+		out, in := "merge-out:"+strconv.Itoa(from), "merge-in:"+strconv.Itoa(from)
+		err = tx.Create([]*model.LedgerEntry{
+			{RefNo: out, UserID: from, Asset: a.Asset, Amount: -a.Balance, Balance: 0, BizType: bizUserMerge, Remark: remark},
+			{RefNo: out, UserID: model.LedgerSystemUser, Asset: a.Asset, Amount: a.Balance, BizType: bizUserMerge, Remark: remark},
+			{RefNo: in, UserID: to, Asset: a.Asset, Amount: a.Balance, Balance: target.Balance + a.Balance,
+				BizType: bizUserMerge, Remark: remark},
+			{RefNo: in, UserID: model.LedgerSystemUser, Asset: a.Asset, Amount: -a.Balance, BizType: bizUserMerge, Remark: remark},
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err = tx.Model(a).Update("balance", 0).Error; err != nil {
+			return err
+		}
+		if err = tx.Model(target).Update("balance", target.Balance+a.Balance).Error; err != nil {
+			return err
+		}
+		detail["ledger_"+a.Asset] = a.Balance
+	}
+	return nil
+}
+
+// mergeProfile 保留用户的手机号、昵称、头像为空时使用被合并用户的，同时更新to以便合并下一个用户
+func mergeProfile(tx *gorm.DB, from, to *model.User) error {
+	data := make(map[string]any)
+	if to.PhoneNumber == "" && from.PhoneNumber != "" {
+		to.PhoneNumber = from.PhoneNumber
+		data["phone_number"] = from.PhoneNumber
+	}
+	if to.Nickname == "" && from.Nickname != "" {
+		to.Nickname = from.Nickname
+		data["nickname"] = from.Nickname
+	}
+	if to.AvatarURL == "" && from.AvatarURL != "" {
+		to.AvatarURL = from.AvatarURL
+		data["avatar_url"] = from.AvatarURL
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return tx.Model(&model.User{}).Where("id = ?", to.ID).Updates(data).Error
+}
+
+// mergeStatus 被合并用户当前生效的处罚比保留用户的严重时，复制到保留用户
+func mergeStatus(tx *gorm.DB, from, to int) error {
+	var list []*model.UserStatus
+	if err := tx.Where("user_id IN ?", []int{from, to}).Find(&list).Error; err != nil {
+		return err
+	}
+	var src, dst *model.UserStatus
+	for _, v := range list {
+		if v.UserID == from {
+			src = v
+		} else {
+			dst = v
+		}
+	}
+	now := time.Now()
+	if src == nil || src.Status == model.UserMerged || src.Effective(now) <= dst.Effective(now) {
+		return nil
+	}
+	return tx.Save(&model.UserStatus{
+		UserID:     to,
+		Status:     src.Status,
+		Reason:     src.Reason,
+		ExpireTime: src.ExpireTime,
+		Operator:   bizUserMerge,
+	}).Error
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"project/model"
+	"project/pkg/fsm"
+	"time"
+)
+
+// FindPaidOrders 按支付时间查询渠道已支付过的订单(含已发货、已完成、退款中、已退款、关闭后到账)
+func (s *Service) FindPaidOrders(ctx context.Context, provider string, begin, end int64) (list []*model.Order, err error) {
+	err = s.mysql.WithContext(ctx).
+		Where("provider = ? AND pay_time >= ? AND pay_time < ? AND status IN ?", provider, begin, end,
+			[]int8{model.OrderPaid, model.OrderShipped, model.OrderCompleted, model.OrderRefunding, model.OrderRefunded,
+				model.OrderPaidLate}).
+		Find(&list).Error
+	return
+}
+
+// FindSuccessRefunds 按退款成功时间查询渠道的退款
+func (s *Service) FindSuccessRefunds(ctx context.Context, provider string, begin, end int64) (list []*model.OrderRefund, err error) {
+	orders := s.mysql.Model(&model.Order{}).Select("order_no").Where("provider = ?", provider)
+	err = s.mysql.WithContext(ctx).
+		Where("success_time >= ? AND success_time < ? AND status = ? AND order_no IN (?)",
+			begin, end, model.RefundSuccess, orders).
+		Find(&list).Error
+	return
+}
+
+// CloseTimeoutOrder 关闭超时未支付的订单，退回已核销的券和预扣的库存，返回false表示订单已支付、已关闭或未到期
+// 已关闭(用户取消)的订单重新回补库存，回补是幂等的，补上取消时失败的回补
+func (s *Service) CloseTimeoutOrder(ctx context.Context, orderNo string) (bool, error) {
+	var list []*model.Order
+	err := s.mysql.WithContext(ctx).Select("status", "create_time").
+		Where("order_no = ?", orderNo).Limit(1).Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return false, err
+	}
+	order := list[0]
+	if order.Status == model.OrderClosed {
+		return false, s.restockOrder(ctx, orderNo)
+	}
+	if order.Status != model.OrderCreated || order.CreateTime.After(time.Now().Add(-model.OrderPayTimeout)) {
+		return false, nil
+	}
+	err = model.TransitOrder(ctx, s.orderFSM, s.mysql, &fsm.Change[int8]{
+		Subject:  orderNo,
+		Event:    model.OrderEventCancel,
+		From:     order.Status,
+		Operator: "system",
+		Remark:   "支付超时",
+	}, nil, func(tx *gorm.DB) error {
+		return tx.Model(&model.Coupon{}).
+			Where("order_no = ? AND status = ?", orderNo, model.CouponUsed).
+			Updates(map[string]any{
+				"status":   model.CouponUnused,
+				"order_no": "",
+				"use_time": 0,
+			}).Error
+	})
+	if ok, err := model.TransitResult(err); !ok {
+		return false, err
+	}
+	return true, s.restockOrder(ctx, orderNo)
+}
+
+func (s *Service) publishOrder(ctx context.Context, c *fsm.Change[int8]) error {
+	if s.producer == nil {
+		return nil
+	}
+	return s.producer.Publish(model.TopicOrder, model.OrderMessage(c))
+}
+
+// restockOrder 回补订单预扣的库存并投递回写消息，与api的RestockOrder一致
+func (s *Service) restockOrder(ctx context.Context, orderNo string) error {
+	body, err := model.RestockOrder(ctx, s.redis, orderNo, nil)
+	if len(body) > 0 {
+		if e := s.producer.MultiPublish(model.TopicStock, body); e != nil {
+			return e
+		}
+	}
+	return err
+}
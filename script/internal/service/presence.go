@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"project/pkg/presence"
+)
+
+// SweepPresence 心跳超时的用户离线，最后心跳时间写入user_presence，每批limit个直到取完；返回离线人数
+func (s *Service) SweepPresence(ctx context.Context, opt *presence.Options, limit int) (int, error) {
+	p := presence.New(s.redis, model.KeyPresence, opt)
+	total := 0
+	for {
+		seen, err := p.Sweep(ctx, limit)
+		if err != nil || len(seen) == 0 {
+			return total, err
+		}
+		list := make([]*model.UserPresence, len(seen))
+		for i, v := range seen {
+			list[i] = &model.UserPresence{UserID: v.UserID, LastSeen: v.LastSeen.Unix()}
+		}
+		// 与api断开连接时的写入并发，只保留较晚的时间
+		err = s.mysql.WithContext(ctx).Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{"last_seen": gorm.Expr("GREATEST(last_seen, VALUES(last_seen))")}),
+		}).Create(list).Error
+		if err != nil {
+			return total, err
+		}
+		total += len(list)
+		if len(seen) < limit {
+			return total, nil
+		}
+	}
+}
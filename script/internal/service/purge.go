@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"project/pkg/mq"
+	"time"
+)
+
+// PurgeSoftDeleted 分批物理删除超过保留期的软删除记录，避免大事务锁表
+func (s *Service) PurgeSoftDeleted(ctx context.Context, table string, before time.Time, batch int) (int64, error) {
+	var total int64
+	for {
+		opt := s.mysql.WithContext(ctx).
+			Exec("DELETE FROM `"+table+"` WHERE delete_time IS NOT NULL AND delete_time < ? LIMIT ?", before, batch)
+		if opt.Error != nil {
+			return total, opt.Error
+		}
+		total += opt.RowsAffected
+		if opt.RowsAffected < int64(batch) {
+			return total, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// PurgeDedup 清理过期的消费去重记录
+func (s *Service) PurgeDedup(ctx context.Context) (int64, error) {
+	return mq.NewDBDedup(s.mysql, 0).Purge(ctx, 1000)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"project/model"
+)
+
+// PushTargets 用户的推送偏好、app设备和openid，偏好未设置时为默认偏好
+func (s *Service) PushTargets(ctx context.Context, uid int) (pref *model.PushPreference, devices []*model.PushDevice, openid string, err error) {
+	var list []*model.PushPreference
+	if err = s.mysql.WithContext(ctx).Where("user_id = ?", uid).Limit(1).Find(&list).Error; err != nil {
+		return
+	}
+	pref = model.DefaultPushPreference(uid)
+	if len(list) > 0 {
+		pref = list[0]
+	}
+	if pref.Channel == model.PushChannelOff {
+		return
+	}
+	if pref.Channel != model.PushChannelWechat {
+		err = s.mysql.WithContext(ctx).Where("user_id = ?", uid).Order("update_time DESC").Find(&devices).Error
+		if err != nil {
+			return
+		}
+	}
+	var u model.User
+	err = s.mysql.WithContext(ctx).Select("openid").Where("id = ?", uid).Limit(1).Find(&u).Error
+	openid = u.Openid
+	return
+}
+
+// DeletePushDevice 删除失效的token，以token为条件避免误删已重新登记的设备
+func (s *Service) DeletePushDevice(ctx context.Context, id int, token string) error {
+	return s.mysql.WithContext(ctx).Where("id = ? AND token = ?", id, token).Delete(&model.PushDevice{}).Error
+}
+
+func (s *Service) SavePushDeliveries(ctx context.Context, list []*model.PushDelivery) error {
+	for _, v := range list {
+		if len(v.Error) > 255 {
+			v.Error = v.Error[:255]
+		}
+	}
+	return s.mysql.WithContext(ctx).Create(&list).Error
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"project/pkg/region"
+)
+
+// ImportRegions 导入行政区划数据集：新增和变更的代码写入，新版本中不存在的代码标记撤销(不删除，已保存的地址仍可显示)，
+// 最后写入region_version，api检查到新版本后重新加载；dryRun只统计差异不写入
+func (s *Service) ImportRegions(ctx context.Context, version string, list []*region.Region, dryRun bool) (*model.RegionVersion, error) {
+	if _, err := region.New(version, list); err != nil {
+		return nil, err
+	}
+	var rows []*model.Region
+	if err := s.mysql.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	old := make(map[string]*model.Region, len(rows))
+	for _, v := range rows {
+		old[v.Code] = v
+	}
+	res := &model.RegionVersion{Version: version, Total: len(list)}
+	var upserts []*model.Region
+	seen := make(map[string]bool, len(list))
+	for _, v := range list {
+		seen[v.Code] = true
+		o := old[v.Code]
+		switch {
+		case o == nil || o.Status == model.RegionRetired:
+			res.Added++
+		case o.Name != v.Name || o.Parent != v.Parent || o.Level != v.Level:
+			res.Changed++
+		default:
+			continue
+		}
+		upserts = append(upserts, &model.Region{Code: v.Code, Name: v.Name, Parent: v.Parent, Level: v.Level,
+			Status: model.RegionValid, Version: version})
+	}
+	var retired []string
+	for _, v := range rows {
+		if v.Status == model.RegionValid && !seen[v.Code] {
+			retired = append(retired, v.Code)
+		}
+	}
+	res.Retired = len(retired)
+	if dryRun {
+		return res, nil
+	}
+	err := s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&model.RegionVersion{}).Where("version = ?", version).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return fmt.Errorf("region version %s already imported", version)
+		}
+		if len(upserts) > 0 {
+			err := tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(upserts, 500).Error
+			if err != nil {
+				return err
+			}
+		}
+		for i := 0; i < len(retired); i += 500 {
+			end := i + 500
+			if end > len(retired) {
+				end = len(retired)
+			}
+			err := tx.Model(&model.Region{}).Where("code IN ?", retired[i:end]).
+				Updates(map[string]any{"status": model.RegionRetired, "version": version}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Create(res).Error
+	})
+	return res, err
+}
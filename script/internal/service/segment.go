@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"time"
+)
+
+func (s *Service) FindActiveSegments(ctx context.Context) (list []*model.Segment, err error) {
+	err = s.mysql.WithContext(ctx).Where("status = ?", model.StatusOn).Find(&list).Error
+	return
+}
+
+// RefreshSegment 按用户ID分批计算成员，写入新版本后删除旧版本，计算期间成员表保持上一版本可用
+func (s *Service) RefreshSegment(ctx context.Context, seg *model.Segment) (int64, error) {
+	cond, args, err := model.SegmentEngine.Compile(seg.Rule)
+	if err != nil {
+		return 0, err
+	}
+	version := time.Now().UnixMilli()
+	var total int64
+	cursor := 0
+	for {
+		var uids []int
+		err = s.mysql.WithContext(ctx).Model(&model.User{}).Where("id > ?", cursor).Where(cond, args...).
+			Order("id").Limit(1000).Pluck("id", &uids).Error
+		if err != nil {
+			return total, err
+		}
+		if len(uids) == 0 {
+			break
+		}
+		list := make([]*model.SegmentMember, 0, len(uids))
+		for _, uid := range uids {
+			list = append(list, &model.SegmentMember{SegmentID: seg.ID, UserID: uid, Version: version})
+		}
+		err = s.mysql.WithContext(ctx).Clauses(clause.OnConflict{
+			DoUpdates: clause.AssignmentColumns([]string{"version"}),
+		}).Create(&list).Error
+		if err != nil {
+			return total, err
+		}
+		total += int64(len(uids))
+		cursor = uids[len(uids)-1]
+	}
+	for {
+		opt := s.mysql.WithContext(ctx).
+			Exec("DELETE FROM segment_member WHERE segment_id = ? AND version < ? LIMIT 1000", seg.ID, version)
+		if opt.Error != nil {
+			return total, opt.Error
+		}
+		if opt.RowsAffected < 1000 {
+			break
+		}
+	}
+	return total, s.mysql.WithContext(ctx).Model(&model.Segment{}).Where("id = ?", seg.ID).Updates(map[string]any{
+		"size":         total,
+		"version":      version,
+		"refresh_time": time.Now().Unix(),
+	}).Error
+}
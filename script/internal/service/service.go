@@ -2,17 +2,22 @@ package service
 
 import (
 	"github.com/go-redis/redis/v8"
-	"github.com/nsqio/go-nsq"
 	"gorm.io/gorm"
+	"project/model"
+	"project/pkg/boot"
 	"project/pkg/cache"
 	"project/pkg/db"
+	"project/pkg/fsm"
 	"project/pkg/mq"
+	"time"
 )
 
 type Service struct {
 	mysql    *gorm.DB
 	redis    *redis.Client
-	producer *nsq.Producer
+	producer mq.Producer
+	shards   *db.Cluster
+	orderFSM *fsm.Machine[int8]
 }
 
 type Option func(*Service)
@@ -33,10 +38,19 @@ func NewRedis(cfg *cache.Redis) Option {
 	}
 }
 
-func NewProducer(addr string) Option {
+func NewProducer(addr string, kafka *mq.KafkaConfig) Option {
 	return func(s *Service) {
 		if s.producer == nil {
-			s.producer = mq.NewNsqProducer(addr)
+			s.producer = mq.NewProducer(addr, kafka)
+		}
+	}
+}
+
+// NewShards 按用户分片的表，需先初始化mysql(保存slot映射)
+func NewShards(cfg *db.ShardConfig) Option {
+	return func(s *Service) {
+		if s.shards == nil {
+			s.shards = db.NewCluster(s.mysql, cfg)
 		}
 	}
 }
@@ -46,5 +60,34 @@ func NewService(options ...Option) *Service {
 	for _, opt := range options {
 		opt(s)
 	}
+	s.orderFSM = model.NewOrderFSM().OnTransition(s.publishOrder)
 	return s
 }
+
+// Checks 启动阶段检查已初始化的依赖
+func (s *Service) Checks() []boot.Check {
+	var checks []boot.Check
+	if s.mysql != nil {
+		checks = append(checks, boot.Mysql(s.mysql))
+	}
+	if s.redis != nil {
+		checks = append(checks, boot.Redis(s.redis))
+	}
+	if s.producer != nil {
+		checks = append(checks, boot.MQ(s.producer))
+	}
+	if s.shards != nil {
+		checks = append(checks, boot.Check{Name: "shard", Fn: s.shards.Start})
+	}
+	return checks
+}
+
+// NewDelayer 延迟消息的投递，需先初始化redis和producer
+func (s *Service) NewDelayer(opt *mq.DelayOptions) *mq.Delayer {
+	return mq.NewDelayer(s.producer, s.redis, model.KeyDelayQueue, opt)
+}
+
+// RedisDedup 消费去重，lease为单条消息最长处理时间，ttl为已处理记录的保留时间
+func (s *Service) RedisDedup(lease, ttl time.Duration) *mq.RedisDedup {
+	return mq.NewRedisDedup(s.redis, model.KeyDedup, lease, ttl)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"strconv"
+	"time"
+)
+
+// RollupStat 汇总day当日的看板数据并覆盖写入stat_daily；活跃用户取自已回写的api_usage，有5分钟延迟
+func (s *Service) RollupStat(ctx context.Context, day time.Time) (*model.StatDaily, error) {
+	begin := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := begin.AddDate(0, 0, 1)
+	date, _ := strconv.Atoi(begin.Format("20060102"))
+	weekAgo, _ := strconv.Atoi(begin.AddDate(0, 0, -6).Format("20060102"))
+	stat := &model.StatDaily{Date: date}
+	db := s.mysql.WithContext(ctx)
+
+	active := func(from int, n *int64) error {
+		return db.Model(&model.APIUsage{}).
+			Where("date BETWEEN ? AND ? AND route = ? AND subject LIKE ?", from, date, model.UsageAllRoutes, "user:%").
+			Distinct("subject").Count(n).Error
+	}
+	if err := active(date, &stat.DAU); err != nil {
+		return nil, err
+	}
+	if err := active(weekAgo, &stat.WAU); err != nil {
+		return nil, err
+	}
+	err := db.Model(&model.User{}).Where("create_time >= ? AND create_time < ?", begin, end).
+		Count(&stat.NewUsers).Error
+	if err != nil {
+		return nil, err
+	}
+	var order struct {
+		Orders  int64
+		Revenue int64
+	}
+	err = db.Model(&model.Order{}).Select("COUNT(*) AS orders, COALESCE(SUM(amount), 0) AS revenue").
+		Where("pay_time >= ? AND pay_time < ?", begin.Unix(), end.Unix()).Scan(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	stat.Orders, stat.Revenue = order.Orders, order.Revenue
+
+	data, err := s.redis.HGetAll(ctx, model.HTTPStatKey(begin.Format("20060102"))).Result()
+	if err != nil {
+		return nil, err
+	}
+	stat.Requests, _ = strconv.ParseInt(data[model.HTTPStatAll], 10, 64)
+	stat.Errors, _ = strconv.ParseInt(data["5xx"], 10, 64)
+
+	err = db.Clauses(clause.OnConflict{UpdateAll: true}).Create(stat).Error
+	return stat, err
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"project/model"
+	"project/pkg/db"
+)
+
+// ErrStockDrift 数据库库存不足以扣减，说明redis与db不一致
+var ErrStockDrift = errors.New("stock drift")
+
+// WriteBackStock 流水唯一键去重后更新数据库库存，返回false表示重复消息
+func (s *Service) WriteBackStock(ctx context.Context, msg *model.MsgStock) (bool, error) {
+	ok := false
+	err := s.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&model.StockLog{
+			SkuID:    msg.SkuID,
+			OrderNo:  msg.OrderNo,
+			Quantity: msg.Quantity,
+			Type:     msg.Type,
+		}).Error
+		if db.IsDuplicateKey(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		query := tx.Model(&model.Stock{}).Where("sku_id = ?", msg.SkuID)
+		var opt *gorm.DB
+		if msg.Type == model.StockDeduct {
+			opt = query.Where("stock >= ?", msg.Quantity).Updates(map[string]any{
+				"stock": gorm.Expr("stock - ?", msg.Quantity),
+				"sold":  gorm.Expr("sold + ?", msg.Quantity),
+			})
+		} else {
+			opt = query.Updates(map[string]any{
+				"stock": gorm.Expr("stock + ?", msg.Quantity),
+				"sold":  gorm.Expr("GREATEST(sold - ?, 0)", msg.Quantity),
+			})
+		}
+		if opt.Error != nil {
+			return opt.Error
+		}
+		if opt.RowsAffected == 0 {
+			return ErrStockDrift
+		}
+		ok = true
+		return nil
+	})
+	return ok, err
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var identReg = regexp.MustCompile(`^\w+$`)
+
+// StorageRefs 分批读取表.列中引用的对象路径，列中可以是完整url或富文本，按pathReg提取
+func (s *Service) StorageRefs(ctx context.Context, refs []string, pathReg *regexp.Regexp) (map[string]bool, error) {
+	set := make(map[string]bool)
+	for _, ref := range refs {
+		table, column, _ := strings.Cut(ref, ".")
+		if !identReg.MatchString(table) || !identReg.MatchString(column) {
+			return nil, fmt.Errorf("invalid storage ref %q", ref)
+		}
+		var lastID int64
+		for {
+			var rows []struct {
+				ID    int64
+				Value string
+			}
+			err := s.mysql.WithContext(ctx).
+				Raw("SELECT id, `"+column+"` AS value FROM `"+table+"` WHERE id > ? AND `"+column+"` <> '' ORDER BY id LIMIT 1000", lastID).
+				Scan(&rows).Error
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range rows {
+				for _, p := range pathReg.FindAllString(v.Value, -1) {
+					set[p] = true
+				}
+			}
+			if len(rows) < 1000 {
+				break
+			}
+			lastID = rows[len(rows)-1].ID
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"project/model"
+	"project/pkg/geo"
+)
+
+// RebuildStoreGeo 按数据库重建营业中门店的附近搜索索引，修正cms同步失败或redis数据丢失；返回门店数
+func (s *Service) RebuildStoreGeo(ctx context.Context) (int, error) {
+	var list []*model.Store
+	err := s.mysql.WithContext(ctx).Select("id", "lng", "lat").
+		Where("status = ?", model.StoreOpen).Find(&list).Error
+	if err != nil {
+		return 0, err
+	}
+	points := make([]*geo.Point, len(list))
+	for i, v := range list {
+		points[i] = &geo.Point{ID: v.ID, Lng: v.Lng, Lat: v.Lat}
+	}
+	return len(points), geo.New(s.redis, model.KeyGeo).Rebuild(ctx, model.GeoStore, points)
+}
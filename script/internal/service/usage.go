@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm/clause"
+	"project/model"
+	"strconv"
+	"strings"
+)
+
+// RollupUsage 将redis中当日调用计数覆盖写入db，重复执行结果一致
+func (s *Service) RollupUsage(ctx context.Context, date string) (int, error) {
+	data, err := s.redis.HGetAll(ctx, model.UsageKey(date)).Result()
+	if err != nil || len(data) == 0 {
+		return 0, err
+	}
+	day, _ := strconv.Atoi(date)
+	list := make([]*model.APIUsage, 0, len(data))
+	for k, v := range data {
+		subject, route, ok := strings.Cut(k, "|")
+		if !ok {
+			continue
+		}
+		n, _ := strconv.ParseInt(v, 10, 64)
+		list = append(list, &model.APIUsage{
+			Date:    day,
+			Subject: subject,
+			Route:   route,
+			Count:   n,
+		})
+	}
+	err = s.mysql.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"count"}),
+	}).CreateInBatches(list, 500).Error
+	return len(list), err
+}